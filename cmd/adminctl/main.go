@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/client"
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/joho/godotenv"
+)
+
+const usage = `Usage:
+  adminctl users [-page N] [-role R]   List users
+  adminctl jobs [-status S] [-type T]  List background jobs
+  adminctl jobs-retry <id>             Re-queue a failed job
+  adminctl tasks                       Show scheduled task status
+  adminctl tasks-run <name>            Run a scheduled task now
+  adminctl webhooks                    List webhook subscriptions
+
+Configuration: ADMIN_API_URL (default http://localhost:8080) and either
+ADMIN_API_TOKEN or ADMIN_USERNAME/ADMIN_PASSWORD.`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// Load .env file
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: Could not load .env file, using environment variables")
+	}
+	if err := config.LoadSecrets(context.Background()); err != nil {
+		log.Fatal("Failed to load secrets: ", err)
+	}
+
+	baseURL := os.Getenv("ADMIN_API_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	opts := []client.Option{client.WithCredentials(os.Getenv("ADMIN_USERNAME"), os.Getenv("ADMIN_PASSWORD"))}
+	if token := os.Getenv("ADMIN_API_TOKEN"); token != "" {
+		opts = append(opts, client.WithToken(token))
+	}
+	api := client.New(baseURL, opts...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	args := os.Args[2:]
+	var result interface{}
+	var err error
+
+	switch os.Args[1] {
+	case "users":
+		fs := flag.NewFlagSet("users", flag.ExitOnError)
+		page := fs.Int("page", 1, "page number")
+		role := fs.String("role", "", "filter by role")
+		fs.Parse(args)
+		result, err = api.ListUsers(ctx, models.UserQueryParams{Page: *page, Limit: 50, Role: *role})
+	case "jobs":
+		fs := flag.NewFlagSet("jobs", flag.ExitOnError)
+		status := fs.String("status", "", "filter by status")
+		jobType := fs.String("type", "", "filter by job type")
+		fs.Parse(args)
+		result, err = api.ListJobs(ctx, models.JobQueryParams{Page: 1, Limit: 50, Status: *status, Type: *jobType})
+	case "jobs-retry":
+		id := requireID(args)
+		err = api.RetryJob(ctx, id)
+		result = map[string]interface{}{"retried": id}
+	case "tasks":
+		result, err = api.ListScheduledTasks(ctx)
+	case "tasks-run":
+		if len(args) != 1 {
+			log.Fatal("tasks-run requires a task name")
+		}
+		err = api.RunScheduledTask(ctx, args[0])
+		result = map[string]interface{}{"triggered": args[0]}
+	case "webhooks":
+		result, err = api.ListWebhooks(ctx)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(result)
+}
+
+func requireID(args []string) uint {
+	if len(args) != 1 {
+		log.Fatal("an ID argument is required")
+	}
+	id, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		log.Fatal("invalid ID:", err)
+	}
+	return uint(id)
+}
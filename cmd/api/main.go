@@ -1,18 +1,42 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"log"
 	"os"
 	"time"
 
+	"github.com/NgTruong624/project_backend/internal/audit"
+	"github.com/NgTruong624/project_backend/internal/buildinfo"
+	"github.com/NgTruong624/project_backend/internal/captcha"
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/database"
+	"github.com/NgTruong624/project_backend/internal/einvoice"
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/fieldcrypt"
 	"github.com/NgTruong624/project_backend/internal/handlers"
+	"github.com/NgTruong624/project_backend/internal/jobs"
 	"github.com/NgTruong624/project_backend/internal/middleware"
 	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/notify"
+	"github.com/NgTruong624/project_backend/internal/partition"
+	"github.com/NgTruong624/project_backend/internal/password"
+	"github.com/NgTruong624/project_backend/internal/payment"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/retention"
 	"github.com/NgTruong624/project_backend/internal/routes"
+	"github.com/NgTruong624/project_backend/internal/scheduler"
+	"github.com/NgTruong624/project_backend/internal/seed"
+	"github.com/NgTruong624/project_backend/internal/selfcheck"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/shadow"
+	"github.com/NgTruong624/project_backend/internal/shipping"
+	"github.com/NgTruong624/project_backend/internal/sse"
+	"github.com/NgTruong624/project_backend/internal/tax"
+	"github.com/NgTruong624/project_backend/internal/token"
+	"github.com/NgTruong624/project_backend/internal/visitor"
+	"github.com/NgTruong624/project_backend/internal/webhook"
 	"github.com/joho/godotenv"
-	"golang.org/x/crypto/bcrypt"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
@@ -23,31 +47,67 @@ func main() {
 	} else {
 		log.Println("Successfully loaded .env file")
 	}
+	if err := config.LoadSecrets(context.Background()); err != nil {
+		log.Fatal("Failed to load secrets: ", err)
+	}
+	if _, err := fieldcrypt.ConfigureFromEnv(); err != nil {
+		log.Fatal("Failed to configure field encryption: ", err)
+	}
 
-	// Kết nối database
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_PORT"),
-	)
-	fmt.Println("DSN String:", dsn)
+	// Event bus trong tiến trình; các subsystem đăng ký nhận sự kiện domain tại đây
+	bus := events.NewBus()
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// Kết nối database theo DB_DRIVER (với Postgres, DB_HOST có thể liệt kê nhiều primary để failover)
+	db, dbFailover, err := database.Connect(config.DatabaseFromEnv(), bus, 5*time.Second, config.DBPoolFromEnv(), &gorm.Config{})
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		log.Fatal("Failed to connect to database: ", err)
+	}
+	dbFailover.Start(context.Background())
+	if err := database.UseSlowQueryLog(db, config.SlowQueryFromEnv()); err != nil {
+		log.Fatal("Failed to register slow query log: ", err)
 	}
 
 	// Auto migrate models
-	if err := db.AutoMigrate(&models.User{}, &models.Product{}); err != nil {
+	migrated := []interface{}{&models.User{}, &models.Product{}, &models.ProductListing{},
+		&models.WebhookSubscription{}, &models.WebhookDelivery{}, &models.Job{}, &models.ScheduledTask{},
+		&models.DeviceToken{}, &models.NotificationPreference{},
+		&models.Invoice{}, &models.InvoiceItem{}, &models.InvoiceSequence{}, &models.DailyKPI{}, &models.Cart{}, &models.CartItem{},
+		&models.PaymentCustomer{}, &models.PaymentMethod{},
+		&models.Address{}, &models.Order{}, &models.OrderItem{}, &models.OrderStatusHistory{}, &models.Payment{}, &models.Refund{},
+		&models.TaxRate{}, &models.OrderReturn{}, &models.OrderReturnItem{}, &models.OrderReturnPhoto{},
+		&models.Promotion{}, &models.OrderDiscount{}, &models.EmailChange{}, &models.RevokedToken{},
+		&models.VisitorSession{}, &models.RecentlyViewedProduct{}, &models.ProductQuestion{}, &models.ProductAnswer{},
+		&models.ProductReview{}, &models.ReviewVote{}, &models.ReviewReport{},
+		&models.CategoryAttribute{}, &models.ScheduledPriceChange{}, &models.SearchSynonym{}, &models.Category{}, &models.Sitemap{}, &models.ProductFeed{}, &models.ProductAlert{}, &models.OrphanedUpload{}, &models.ProductMedia{}, &models.ProductRevision{}, &models.ProductSnapshot{}, &models.UserInvitation{}, &models.NewsletterSubscriber{}, &models.SupportTicket{}, &models.SupportTicketMessage{}, &models.LoginChallenge{}}
+	if err := db.AutoMigrate(migrated...); err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
-	// Seed data nếu được cấu hình
+	// Tạo các bảng phân vùng theo thời gian
+	if err := repository.MigrateAuditLogs(db); err != nil {
+		log.Fatal("Failed to migrate audit logs:", err)
+	}
+	if err := repository.MigrateLoginEvents(db); err != nil {
+		log.Fatal("Failed to migrate login events:", err)
+	}
+	if err := repository.MigrateInvoices(db); err != nil {
+		log.Fatal("Failed to migrate invoices:", err)
+	}
+	if err := repository.MigrateProductIndexes(db); err != nil {
+		log.Fatal("Failed to migrate product indexes:", err)
+	}
+
+	// Read model cho danh sách sản phẩm: backfill lần đầu và tự cập nhật khi products thay đổi
+	if err := repository.EnsureProductListings(db); err != nil {
+		log.Fatal("Failed to build product listings:", err)
+	}
+	if err := repository.RegisterProductListingProjector(db); err != nil {
+		log.Fatal("Failed to register product listing projector:", err)
+	}
+
+	// Seed data nếu được cấu hình; SEED_FIXTURES chọn bộ dữ liệu (dev, demo, test)
 	if os.Getenv("RUN_SEEDER") == "true" {
-		if err := seedData(db); err != nil {
+		if err := seed.Run(context.Background(), db, config.String("SEED_FIXTURES", seed.Dev)); err != nil {
 			log.Printf("Warning: Failed to seed data: %v", err)
 		} else {
 			log.Println("Successfully seeded database")
@@ -60,13 +120,198 @@ func main() {
 		log.Fatal("JWT_SECRET environment variable is required")
 	}
 
-	authHandler := handlers.NewAuthHandler(db, jwtSecret)
-	productHandler := handlers.NewProductHandler(db)
-	adminHandler := handlers.NewAdminHandler(db)
-	jwtMiddleware := middleware.NewJWTMiddleware(jwtSecret)
+	// Webhook dispatcher: sự kiện được lưu vào webhook_deliveries và gửi bởi worker nền
+	dispatcher := webhook.NewDispatcher(db, 5*time.Second)
+	webhook.RegisterSubscribers(bus, dispatcher)
+	dispatcher.Start(context.Background())
+
+	// Job nền được đưa vào bảng jobs và xử lý bởi cmd/worker
+	jobClient := jobs.NewClient(db)
+
+	// Thông báo tới user được đưa vào hàng đợi job và gửi bởi cmd/worker
+	notifier := notify.NewDispatcher(db, jobClient)
+	notify.RegisterAlertSubscribers(bus, notifier)
+	notify.RegisterOrderSubscribers(bus, notifier)
+	notify.RegisterProductAlertSubscribers(bus, notifier, db)
+	notify.RegisterSupportSubscribers(bus, notifier)
+	notify.RegisterLoginSubscribers(bus, notifier)
+
+	// Sự kiện order.* được phát tới webhook qua hàng đợi job
+	webhook.RegisterOrderSubscribers(bus, jobClient)
+
+	// Hoạt động của tài khoản (đăng nhập, đổi mật khẩu, ...) được ghi vào audit_logs
+	audit.RegisterSubscribers(bus, db)
+	audit.RegisterProductSubscribers(bus, db)
+
+	// Đường dẫn danh mục trong read model product_listings được cập nhật khi cây danh mục thay đổi
+	services.RegisterListingCategoryPaths(bus, db)
+
+	// Ảnh sản phẩm bị thay được xóa khỏi static/uploads ngay sau khi thay đổi được lưu
+	services.RegisterImageCleanup(bus, services.NewUploadGCService(db, "static/uploads"))
+
+	// So sánh kết quả đọc của cài đặt mới với cài đặt cũ trên một phần lưu lượng
+	shadowReads := shadow.NewVerifierFromEnv()
+
+	// Chính sách mật khẩu khi đăng ký và đổi mật khẩu (PASSWORD_*); thuật toán băm mật khẩu
+	// (PASSWORD_HASH_ALGORITHM), hash cũ được băm lại khi user đăng nhập
+	tokens := token.NewManager(token.ConfigFromEnv(jwtSecret))
+
+	// Kiểm tra các phụ thuộc trước khi nhận request: database và migration, thư mục upload, khóa JWT;
+	// read replica chỉ được cảnh báo vì thao tác ghi vẫn hoạt động khi replica lỗi
+	checks := []selfcheck.Check{
+		selfcheck.Database(db, migrated...),
+		selfcheck.Writable("uploads", "static/uploads"),
+		{Name: "jwt", Run: func(context.Context) error { return tokens.SelfTest() }},
+	}
+	for _, replica := range database.Replicas(db) {
+		check := selfcheck.Ping("replica "+replica.Address, replica.DB)
+		check.Optional = true
+		checks = append(checks, check)
+	}
+	if err := selfcheck.RunFromEnv(context.Background(), checks...); err != nil {
+		log.Fatal(err)
+	}
+	// Phiên ẩn danh của khách: gộp giỏ khi đăng nhập, sản phẩm đã xem và bucket rate limit
+	visitors := visitor.NewTracker(db, visitor.ConfigFromEnv(jwtSecret))
+	passwordPolicy := password.NewPolicy(password.ConfigFromEnv())
+	passwordHasher := password.NewHasher(config.PasswordHashFromEnv())
+	// Captcha của các form công khai (CAPTCHA_PROVIDER, CAPTCHA_SECRET, CAPTCHA_ROUTES); tắt khi GIN_MODE=test
+	captchaGuard, err := captcha.NewGuardFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !captchaGuard.Active() {
+		log.Println("Warning: captcha is disabled (CAPTCHA_SECRET is not set or GIN_MODE=test), public forms are accepted without a captcha")
+	}
+	authHandler := handlers.NewAuthHandler(db, jwtSecret, tokens, bus, notifier, passwordPolicy, passwordHasher, visitors)
+	productHandler := handlers.NewProductHandler(db, bus, jobClient, shadowReads, visitors)
+	paymentProvider := payment.ProviderFromEnv()
+	adminHandler := handlers.NewAdminHandler(db, paymentProvider, bus)
+	webhookHandler := handlers.NewWebhookHandler(db)
+	jobHandler := handlers.NewJobHandler(db)
+	deviceHandler := handlers.NewDeviceHandler(db, notifier)
+	streamHandler := handlers.NewStreamHandler(sse.NewBroker(bus))
+	jwtMiddleware := middleware.NewJWTMiddleware(tokens)
+	// Token cấp trước lần tăng token_version gần nhất (đổi email, đổi mật khẩu, đăng xuất mọi nơi)
+	// và token đã đăng xuất (jti trong revoked_tokens) bị từ chối
+	jwtMiddleware.SetTokenVersions(repository.NewUserRepository(db))
+	jwtMiddleware.SetDenylist(repository.NewRevokedTokenRepository(db))
+
+	// Tạo trước phân vùng theo tháng cho các bảng sự kiện
+	var partitioned []partition.Table
+	if database.IsPostgres(db) {
+		partitioned = append(partitioned, repository.AuditLogPartition, repository.LoginEventPartition)
+	}
+	partitionManager := partition.NewManager(db, partitioned, 24*time.Hour)
+	partitionManager.Start(context.Background())
+
+	// Khởi động job dọn dẹp dữ liệu theo chính sách lưu giữ
+	retentionInterval := time.Hour
+	if v := os.Getenv("RETENTION_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			retentionInterval = d
+		} else {
+			log.Printf("Warning: invalid RETENTION_INTERVAL=%q, using %s", v, retentionInterval)
+		}
+	}
+	pruner := retention.NewPruner(db, retention.LoadPoliciesFromEnv(retention.DefaultPolicies()), retentionInterval)
+	pruner.Start(context.Background())
+
+	// Tác vụ định kỳ; mỗi lần chạy chỉ do một replica thực hiện
+	taskScheduler := scheduler.NewScheduler(db, 30*time.Second)
+	if err := scheduler.RegisterDefaultTasks(taskScheduler, db, "static/uploads", services.NewAccountService(db, paymentProvider), visitors,
+		services.NewProductService(db, bus), services.NewPriceChangeService(db, bus)); err != nil {
+		log.Fatal("Failed to register scheduled tasks:", err)
+	}
+	taskScheduler.Start(context.Background())
+	schedulerHandler := handlers.NewSchedulerHandler(taskScheduler)
+	// Thuế suất mặc định dùng chung với hóa đơn điện tử; quy tắc theo danh mục/tỉnh do admin cấu hình
+	invoiceConfig := einvoice.ConfigFromEnv()
+	taxConfig := tax.ConfigFromEnv(invoiceConfig.TaxRate)
+	invoiceHandler := handlers.NewInvoiceHandler(db, invoiceConfig, taxConfig)
+	kpiHandler := handlers.NewKPIHandler(db)
+	reportHandler := handlers.NewReportHandler(db)
+	cartHandler := handlers.NewCartHandler(db, jwtSecret, visitors)
+	paymentMethodHandler := handlers.NewPaymentMethodHandler(db, paymentProvider, bus)
+	accountHandler := handlers.NewAccountHandler(db, paymentProvider, bus, notifier)
+	gateways := payment.GatewaysFromEnv()
+	orderHandler := handlers.NewOrderHandler(db, gateways, shipping.FromEnv(), taxConfig, bus)
+	returnHandler := handlers.NewReturnHandler(db, gateways, bus)
+	addressHandler := handlers.NewAddressHandler(db)
+	taxHandler := handlers.NewTaxHandler(db, taxConfig)
+	promotionHandler := handlers.NewPromotionHandler(db)
+	healthHandler := handlers.NewHealthHandler(db, dbFailover)
+	questionHandler := handlers.NewQuestionHandler(db)
+	reviewHandler := handlers.NewReviewHandler(db)
+	attributeHandler := handlers.NewCategoryAttributeHandler(db)
+	priceChangeHandler := handlers.NewPriceChangeHandler(db, bus)
+	searchSynonymHandler := handlers.NewSearchSynonymHandler(db)
+	categoryHandler := handlers.NewCategoryHandler(db, bus)
+	sitemapHandler := handlers.NewSitemapHandler(db)
+	integrationHandler := handlers.NewIntegrationHandler(db, bus)
+	productFeedHandler := handlers.NewProductFeedHandler(db)
+	productAlertHandler := handlers.NewProductAlertHandler(db)
+	auditHandler := handlers.NewAuditHandler(db)
+
+	var graphqlHandler *handlers.GraphQLHandler
+	if os.Getenv("GRAPHQL_ENABLED") == "true" {
+		graphqlHandler = handlers.NewGraphQLHandler(db)
+	}
 
 	// Setup router với tất cả routes
-	router := routes.SetupRouter(authHandler, productHandler, adminHandler, jwtMiddleware)
+	router := routes.SetupRouter(&routes.Dependencies{
+		Auth:          authHandler,
+		Product:       productHandler,
+		Admin:         adminHandler,
+		JWT:           jwtMiddleware,
+		Pruner:        pruner,
+		GraphQL:       graphqlHandler,
+		Partitions:    partitionManager,
+		Webhook:       webhookHandler,
+		Job:           jobHandler,
+		DBFailover:    dbFailover,
+		Scheduler:     schedulerHandler,
+		ShadowReads:   shadowReads,
+		SlowQueries:   database.SlowQueries(db),
+		Device:        deviceHandler,
+		Stream:        streamHandler,
+		Notifier:      notifier,
+		Invoice:       invoiceHandler,
+		KPI:           kpiHandler,
+		Cart:          cartHandler,
+		PaymentMethod: paymentMethodHandler,
+		Account:       accountHandler,
+		Report:        reportHandler,
+		Order:         orderHandler,
+		Address:       addressHandler,
+		Tax:           taxHandler,
+		Return:        returnHandler,
+		Promotion:     promotionHandler,
+		Health:        healthHandler,
+		Visitors:      visitors,
+		Question:      questionHandler,
+		Review:        reviewHandler,
+		Attribute:     attributeHandler,
+		PriceChange:   priceChangeHandler,
+		SearchSynonym: searchSynonymHandler,
+		Category:      categoryHandler,
+		Sitemap:       sitemapHandler,
+		Integration:   integrationHandler,
+		ProductFeed:   productFeedHandler,
+		ProductAlert:  productAlertHandler,
+		Audit:         auditHandler,
+		RequestLogs:   repository.NewAuditLogRepository(db),
+		Upload:        handlers.NewUploadHandler(db, "static/uploads"),
+		ProductMedia:  handlers.NewProductMediaHandler(db, jobClient),
+		Newsletter:    handlers.NewNewsletterHandler(db, notifier),
+		Support:       handlers.NewSupportTicketHandler(db, bus),
+
+		ProductRevision: handlers.NewProductRevisionHandler(db, bus),
+
+		UserInvitation: handlers.NewUserInvitationHandler(db, bus, notifier, passwordPolicy, passwordHasher),
+
+		Captcha: captchaGuard,
+	})
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -74,86 +319,8 @@ func main() {
 		port = "8080"
 	}
 
-	log.Printf("Starting server on port %s", port)
+	log.Printf("Starting server %s on port %s", buildinfo.Get(), port)
 	if err := router.Run(":" + port); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
 }
-
-// seedData tạo dữ liệu mẫu cho database
-func seedData(db *gorm.DB) error {
-	// Tạo password hash
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("admin123"), bcrypt.DefaultCost)
-	if err != nil {
-		return err
-	}
-
-	// Seed users
-	users := []models.User{
-		{
-			Username:  "admin",
-			Email:     "admin@example.com",
-			Password:  string(hashedPassword),
-			FullName:  "Admin User",
-			Role:      "admin",
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		},
-		{
-			Username:  "user1",
-			Email:     "user1@example.com",
-			Password:  string(hashedPassword),
-			FullName:  "Normal User",
-			Role:      "user",
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		},
-	}
-
-	// Insert users
-	for _, user := range users {
-		if err := db.FirstOrCreate(&user, models.User{Email: user.Email}).Error; err != nil {
-			return err
-		}
-	}
-
-	// Seed products
-	products := []models.Product{
-		{
-			Name:        "Laptop Gaming",
-			Description: "Laptop gaming cấu hình cao",
-			Price:       25000000,
-			Stock:       10,
-			Category:    "Electronics",
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			Name:        "Smartphone",
-			Description: "Điện thoại thông minh mới nhất",
-			Price:       15000000,
-			Stock:       20,
-			Category:    "Electronics",
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			Name:        "Headphone",
-			Description: "Tai nghe không dây",
-			Price:       2000000,
-			Stock:       50,
-			Category:    "Accessories",
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-	}
-
-	// Insert products
-	for _, product := range products {
-		if err := db.FirstOrCreate(&product, models.Product{Name: product.Name}).Error; err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
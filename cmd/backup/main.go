@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/NgTruong624/project_backend/internal/backup"
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/database"
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+)
+
+const usage = `Usage:
+  backup export  -out <file>   Export users, products and media manifest to an encrypted file
+  backup restore -in <file>    Restore users and products from an encrypted file
+
+The passphrase is read from BACKUP_PASSPHRASE.`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// Load .env file
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: Could not load .env file, using environment variables")
+	}
+	if err := config.LoadSecrets(context.Background()); err != nil {
+		log.Fatal("Failed to load secrets: ", err)
+	}
+
+	passphrase := os.Getenv("BACKUP_PASSPHRASE")
+	if passphrase == "" {
+		log.Fatal("BACKUP_PASSPHRASE environment variable is required")
+	}
+
+	switch os.Args[1] {
+	case "export":
+		fs := flag.NewFlagSet("export", flag.ExitOnError)
+		out := fs.String("out", "backup.bsbk", "output file")
+		uploadDir := fs.String("uploads", "static/uploads", "upload directory used for the media manifest")
+		fs.Parse(os.Args[2:])
+		runExport(connect(), *out, *uploadDir, passphrase)
+	case "restore":
+		fs := flag.NewFlagSet("restore", flag.ExitOnError)
+		in := fs.String("in", "backup.bsbk", "backup file")
+		uploadDir := fs.String("uploads", "static/uploads", "upload directory to verify against the media manifest")
+		fs.Parse(os.Args[2:])
+		runRestore(connect(), *in, *uploadDir, passphrase)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func connect() *gorm.DB {
+	db, _, err := database.Connect(config.DatabaseFromEnv(), nil, 0, config.DBPoolFromEnv(), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect to database: ", err)
+	}
+	return db
+}
+
+func runExport(db *gorm.DB, out, uploadDir, passphrase string) {
+	archive, err := backup.Export(db, uploadDir)
+	if err != nil {
+		log.Fatal("Failed to export data:", err)
+	}
+
+	f, err := os.OpenFile(out, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		log.Fatal("Failed to create backup file:", err)
+	}
+	if err := backup.Write(f, archive, passphrase); err != nil {
+		f.Close()
+		os.Remove(out)
+		log.Fatal("Failed to write backup:", err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal("Failed to write backup:", err)
+	}
+
+	log.Printf("Exported %d users, %d products, %d media files to %s",
+		len(archive.Users), len(archive.Products), len(archive.Media), out)
+}
+
+func runRestore(db *gorm.DB, in, uploadDir, passphrase string) {
+	f, err := os.Open(in)
+	if err != nil {
+		log.Fatal("Failed to open backup file:", err)
+	}
+	defer f.Close()
+
+	archive, err := backup.Read(f, passphrase)
+	if err != nil {
+		log.Fatal("Failed to read backup:", err)
+	}
+	if err := backup.Restore(db, archive); err != nil {
+		log.Fatal("Failed to restore data:", err)
+	}
+
+	log.Printf("Restored %d users and %d products from backup created at %s",
+		len(archive.Users), len(archive.Products), archive.CreatedAt.Format("2006-01-02 15:04:05"))
+
+	missing, mismatched := backup.VerifyMedia(uploadDir, archive.Media)
+	if len(missing) > 0 || len(mismatched) > 0 {
+		log.Printf("Warning: %d media files missing and %d with different content in %s; copy them from the original upload directory",
+			len(missing), len(mismatched), uploadDir)
+		for _, path := range missing {
+			log.Printf("  missing: %s", path)
+		}
+		for _, path := range mismatched {
+			log.Printf("  changed: %s", path)
+		}
+	}
+}
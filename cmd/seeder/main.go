@@ -1,131 +1,86 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"flag"
 	"log"
-	"os"
-	"time"
+	"strings"
 
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/database"
+	"github.com/NgTruong624/project_backend/internal/fieldcrypt"
 	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/seed"
 	"github.com/joho/godotenv"
-	"golang.org/x/crypto/bcrypt"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 func main() {
+	users := flag.Int("users", 0, "number of synthetic users to generate")
+	products := flag.Int("products", 0, "number of synthetic products to generate")
+	orders := flag.Int("orders", 0, "number of synthetic orders to generate (needs existing users and products)")
+	truncate := flag.Bool("truncate", false, "delete existing users, products and orders before seeding")
+	fixtures := flag.String("fixtures", config.String("SEED_FIXTURES", seed.Dev), "sample data set to apply: "+strings.Join(seed.Names(), ", "))
+	randomSeed := flag.Int64("seed", 0, "random seed for reproducible data (0 = random)")
+	flag.Parse()
+
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
 		log.Println("Warning: Could not load .env file, using environment variables")
 	} else {
 		log.Println("Successfully loaded .env file")
 	}
+	if err := config.LoadSecrets(context.Background()); err != nil {
+		log.Fatal("Failed to load secrets: ", err)
+	}
+	if _, err := fieldcrypt.ConfigureFromEnv(); err != nil {
+		log.Fatal("Failed to configure field encryption: ", err)
+	}
 
-	// Kết nối database
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_PORT"),
-	)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// Kết nối database; chỉ log lỗi để các lô INSERT lớn không bị báo là truy vấn chậm
+	db, _, err := database.Connect(config.DatabaseFromEnv(), nil, 0, config.DBPoolFromEnv(), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Error),
+	})
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		log.Fatal("Failed to connect to database: ", err)
 	}
 
 	// Auto migrate models
-	if err := db.AutoMigrate(&models.User{}, &models.Product{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.Product{}, &models.ProductListing{},
+		&models.Order{}, &models.OrderItem{}, &models.OrderStatusHistory{}, &models.ProductReview{}); err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
-	// Seed data
-	if err := seedData(db); err != nil {
-		log.Fatal("Failed to seed data:", err)
+	if *truncate {
+		if err := truncateTables(db); err != nil {
+			log.Fatal("Failed to truncate tables:", err)
+		}
+		log.Println("Truncated users, products and orders")
 	}
 
-	log.Println("Successfully seeded database")
-}
-
-// seedData tạo dữ liệu mẫu cho database
-func seedData(db *gorm.DB) error {
-	// Tạo password hash
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("admin123"), bcrypt.DefaultCost)
-	if err != nil {
-		return err
+	// Dữ liệu mẫu cố định (tài khoản admin/user, sản phẩm)
+	if err := seed.Run(context.Background(), db, *fixtures); err != nil {
+		log.Fatal("Failed to seed data:", err)
 	}
 
-	// Seed users
-	users := []models.User{
-		{
-			Username:  "admin",
-			Email:     "admin@example.com",
-			Password:  string(hashedPassword),
-			FullName:  "Admin User",
-			Role:      "admin",
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		},
-		{
-			Username:  "user1",
-			Email:     "user1@example.com",
-			Password:  string(hashedPassword),
-			FullName:  "Normal User",
-			Role:      "user",
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		},
+	// Dữ liệu giả lập cho kiểm thử hiệu năng
+	gen := newGenerator(db, *randomSeed)
+	if err := gen.users(*users); err != nil {
+		log.Fatal("Failed to generate users:", err)
 	}
-
-	// Insert users
-	for _, user := range users {
-		if err := db.FirstOrCreate(&user, models.User{Email: user.Email}).Error; err != nil {
-			return err
-		}
+	if err := gen.products(*products); err != nil {
+		log.Fatal("Failed to generate products:", err)
 	}
-
-	// Seed products
-	products := []models.Product{
-		{
-			Name:        "Laptop",
-			Description: "Laptop gaming",
-			Price:       25000000,
-			Stock:       10,
-			Category:    "Electronics",
-			ImageURL:    "/static/uploads/laptop.jpg",
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			Name:        "Smartphone",
-			Description: "Điện thoại thông minh",
-			Price:       15000000,
-			Stock:       20,
-			Category:    "Electronics",
-			ImageURL:    "/static/uploads/smartphone.jpg",
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			Name:        "Headphone",
-			Description: "Tai nghe không dây",
-			Price:       2000000,
-			Stock:       50,
-			Category:    "Accessories",
-			ImageURL:    "/static/uploads/headphone.jpg",
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
+	if err := gen.orders(*orders); err != nil {
+		log.Fatal("Failed to generate orders:", err)
 	}
 
-	// Insert products
-	for _, product := range products {
-		if err := db.FirstOrCreate(&product, models.Product{Name: product.Name}).Error; err != nil {
-			return err
-		}
+	// Đồng bộ read model sau khi seed
+	if err := repository.NewProductListingRepository(db).RefreshAll(context.Background()); err != nil {
+		log.Fatal("Failed to refresh product listings:", err)
 	}
 
-	return nil
+	log.Println("Successfully seeded database")
 }
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/database"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/brianvoe/gofakeit/v6"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// batchSize là số dòng mỗi câu INSERT khi sinh dữ liệu giả lập
+const batchSize = 1000
+
+// syntheticPassword là mật khẩu của mọi user giả lập; chỉ băm một lần vì bcrypt cho từng user
+// sẽ chiếm gần hết thời gian seed
+const syntheticPassword = "password123"
+
+// seededTables là các bảng seeder ghi vào, theo thứ tự xóa được khi không có CASCADE
+var seededTables = []string{"order_status_histories", "order_items", "orders", "product_listings", "products", "users"}
+
+// truncateTables xóa dữ liệu các bảng seeder ghi vào. Trên Postgres dùng TRUNCATE ... CASCADE nên
+// các bảng tham chiếu tới users/products/orders (giỏ hàng, thanh toán, ...) cũng bị xóa.
+func truncateTables(db *gorm.DB) error {
+	if database.IsPostgres(db) {
+		return db.Exec("TRUNCATE " + strings.Join(seededTables, ", ") + " RESTART IDENTITY CASCADE").Error
+	}
+	for _, table := range seededTables {
+		if err := db.Exec("DELETE FROM " + table).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generator sinh dữ liệu giả lập (gofakeit) và chèn theo lô
+type generator struct {
+	db    *gorm.DB
+	faker *gofakeit.Faker
+	// since là mốc sớm nhất của created_at; dữ liệu trải đều trong một năm gần nhất
+	since time.Time
+}
+
+func newGenerator(db *gorm.DB, seed int64) *generator {
+	return &generator{db: db, faker: gofakeit.New(seed), since: time.Now().AddDate(-1, 0, 0)}
+}
+
+// suffix trả về số bắt đầu để ghép vào các cột unique (username, email, tên sản phẩm).
+// Lấy từ ID lớn nhất hiện có nên các lần chạy liên tiếp không trùng nhau.
+func (g *generator) suffix(model interface{}) (int, error) {
+	var maxID int
+	err := g.db.Model(model).Select("COALESCE(MAX(id), 0)").Scan(&maxID).Error
+	return maxID + 1, err
+}
+
+// users sinh n user với role user và cùng mật khẩu syntheticPassword
+func (g *generator) users(n int) error {
+	if n <= 0 {
+		return nil
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(syntheticPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	start, err := g.suffix(&models.User{})
+	if err != nil {
+		return err
+	}
+
+	return insertBatches(g.db, "users", n, func(i int) models.User {
+		person := g.faker.Person()
+		createdAt := g.faker.DateRange(g.since, time.Now())
+		username := strings.ToLower(fmt.Sprintf("%s.%s%d", person.FirstName, person.LastName, start+i))
+		return models.User{
+			Username:  username,
+			Email:     username + "@example.com",
+			Password:  string(hashed),
+			FullName:  person.FirstName + " " + person.LastName,
+			Role:      "user",
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		}
+	}, nil)
+}
+
+// products sinh n sản phẩm với giá, tồn kho và danh mục ngẫu nhiên
+func (g *generator) products(n int) error {
+	if n <= 0 {
+		return nil
+	}
+	start, err := g.suffix(&models.Product{})
+	if err != nil {
+		return err
+	}
+
+	return insertBatches(g.db, "products", n, func(i int) models.Product {
+		createdAt := g.faker.DateRange(g.since, time.Now())
+		return models.Product{
+			Name:        fmt.Sprintf("%s %d", g.faker.ProductName(), start+i),
+			Description: g.faker.ProductDescription(),
+			// Giá VND, làm tròn tới nghìn đồng
+			Price:       float64(g.faker.IntRange(10, 50000)) * 1000,
+			Stock:       g.faker.IntRange(0, 500),
+			Category:    g.faker.ProductCategory(),
+			WeightGrams: g.faker.IntRange(50, 5000),
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+		}
+	}, nil)
+}
+
+// orderStatuses là phân bố trạng thái của đơn giả lập; đơn đã giao chiếm đa số như dữ liệu thật
+var orderStatuses = []string{
+	models.OrderStatusDelivered, models.OrderStatusDelivered, models.OrderStatusDelivered, models.OrderStatusDelivered,
+	models.OrderStatusFulfilled, models.OrderStatusPaid, models.OrderStatusPaid,
+	models.OrderStatusPendingPayment, models.OrderStatusCancelled, models.OrderStatusRefunded,
+}
+
+// orders sinh n đơn hàng từ user và sản phẩm đã có trong database, mỗi đơn 1-4 dòng hàng.
+// Đơn được ghi như dữ liệu lịch sử: tồn kho sản phẩm không bị trừ.
+func (g *generator) orders(n int) error {
+	if n <= 0 {
+		return nil
+	}
+	var userIDs []uint
+	if err := g.db.Model(&models.User{}).Where("role = ?", "user").Pluck("id", &userIDs).Error; err != nil {
+		return err
+	}
+	var products []models.Product
+	if err := g.db.Select("id", "name", "price").Find(&products).Error; err != nil {
+		return err
+	}
+	if len(userIDs) == 0 || len(products) == 0 {
+		return fmt.Errorf("orders need existing users and products, seed them first with -users and -products")
+	}
+
+	return insertBatches(g.db, "orders", n, func(int) models.Order {
+		createdAt := g.faker.DateRange(g.since, time.Now())
+		status := orderStatuses[g.faker.IntRange(0, len(orderStatuses)-1)]
+		order := models.Order{
+			UserID:      userIDs[g.faker.IntRange(0, len(userIDs)-1)],
+			Status:      status,
+			PaymentType: models.OrderPaymentOnline,
+			Currency:    "VND",
+			ShippingAddress: models.AddressDetails{
+				RecipientName: g.faker.Name(),
+				Phone:         g.faker.Phone(),
+				Province:      g.faker.State(),
+				District:      g.faker.City(),
+				Ward:          g.faker.Street(),
+				Street:        g.faker.Street(),
+			},
+			ShippingFee: 30000,
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+		}
+		for j := g.faker.IntRange(1, 4); j > 0; j-- {
+			product := products[g.faker.IntRange(0, len(products)-1)]
+			quantity := g.faker.IntRange(1, 3)
+			order.Items = append(order.Items, models.OrderItem{
+				ProductID: product.ID,
+				Name:      product.Name,
+				Quantity:  quantity,
+				UnitPrice: product.Price,
+				Amount:    product.Price * float64(quantity),
+			})
+			order.Subtotal += product.Price * float64(quantity)
+		}
+		order.Total = order.Subtotal + order.ShippingFee
+
+		at := createdAt.Add(time.Hour)
+		switch status {
+		case models.OrderStatusCancelled:
+			order.CancelledAt = &at
+		case models.OrderStatusPaid, models.OrderStatusRefunded:
+			order.PaidAt = &at
+		case models.OrderStatusFulfilled:
+			order.PaidAt, order.FulfilledAt = &at, &at
+		case models.OrderStatusDelivered:
+			delivered := at.Add(72 * time.Hour)
+			order.PaidAt, order.FulfilledAt, order.DeliveredAt = &at, &at, &delivered
+		}
+		return order
+	}, func(batch []models.Order) error {
+		// Một dòng lịch sử cho mỗi đơn với trạng thái hiện tại; các bước chuyển trung gian không được sinh
+		history := make([]models.OrderStatusHistory, len(batch))
+		for i, order := range batch {
+			history[i] = models.OrderStatusHistory{OrderID: order.ID, ToStatus: order.Status, CreatedAt: order.CreatedAt}
+		}
+		return g.db.CreateInBatches(history, batchSize).Error
+	})
+}
+
+// insertBatches sinh n bản ghi bằng build và chèn theo lô batchSize dòng bằng CreateInBatches.
+// after (nếu có) chạy sau mỗi lô, khi các bản ghi đã có ID.
+func insertBatches[T any](db *gorm.DB, table string, n int, build func(i int) T, after func(batch []T) error) error {
+	started := time.Now()
+	for offset := 0; offset < n; offset += batchSize {
+		size := min(batchSize, n-offset)
+		batch := make([]T, size)
+		for i := range batch {
+			batch[i] = build(offset + i)
+		}
+		if err := db.CreateInBatches(batch, batchSize).Error; err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+		if after != nil {
+			if err := after(batch); err != nil {
+				return fmt.Errorf("%s: %w", table, err)
+			}
+		}
+		log.Printf("Seeded %d/%d %s", offset+size, n, table)
+	}
+	log.Printf("Seeded %d %s in %s", n, table, time.Since(started).Round(time.Millisecond))
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/buildinfo"
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/database"
+	"github.com/NgTruong624/project_backend/internal/fieldcrypt"
+	"github.com/NgTruong624/project_backend/internal/jobs"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/notify"
+	"github.com/NgTruong624/project_backend/internal/selfcheck"
+	"github.com/NgTruong624/project_backend/internal/webhook"
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+)
+
+func main() {
+	// Load .env file
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: Could not load .env file, using environment variables")
+	}
+	if err := config.LoadSecrets(context.Background()); err != nil {
+		log.Fatal("Failed to load secrets: ", err)
+	}
+	if _, err := fieldcrypt.ConfigureFromEnv(); err != nil {
+		log.Fatal("Failed to configure field encryption: ", err)
+	}
+
+	db, _, err := database.Connect(config.DatabaseFromEnv(), nil, 0, config.DBPoolFromEnv(), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect to database: ", err)
+	}
+	if err := database.UseSlowQueryLog(db, config.SlowQueryFromEnv()); err != nil {
+		log.Fatal("Failed to register slow query log: ", err)
+	}
+	if err := db.AutoMigrate(&models.Job{}); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+
+	concurrency := 4
+	if v := os.Getenv("WORKER_CONCURRENCY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			concurrency = n
+		} else {
+			log.Printf("Warning: invalid WORKER_CONCURRENCY=%q, using %d", v, concurrency)
+		}
+	}
+	pollInterval := 2 * time.Second
+	if v := os.Getenv("WORKER_POLL_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			pollInterval = d
+		} else {
+			log.Printf("Warning: invalid WORKER_POLL_INTERVAL=%q, using %s", v, pollInterval)
+		}
+	}
+
+	worker := jobs.NewWorker(db, concurrency, pollInterval)
+	jobs.RegisterTasks(worker, db)
+
+	// Các kênh gửi thông báo tới user
+	var channels []notify.Channel
+	push, err := notify.NewPushChannelFromEnv(db)
+	if err != nil {
+		log.Fatal("Failed to configure push notifications:", err)
+	}
+	if push != nil {
+		channels = append(channels, push)
+	}
+	email, err := notify.NewEmailChannelFromEnv(db)
+	if err != nil {
+		log.Fatal("Failed to configure email notifications:", err)
+	}
+	if email != nil {
+		channels = append(channels, email)
+	}
+
+	// Kiểm tra database và máy chủ SMTP (nếu cấu hình) trước khi nhận job
+	checks := []selfcheck.Check{selfcheck.Database(db, &models.Job{})}
+	if email != nil {
+		checks = append(checks, selfcheck.Check{Name: "smtp", Run: email.Verify})
+	}
+	if err := selfcheck.RunFromEnv(context.Background(), checks...); err != nil {
+		log.Fatal(err)
+	}
+
+	notifier := notify.NewDispatcher(db, jobs.NewClient(db), channels...)
+	notifier.SetAlertSinks(notify.NewAlertSinksFromEnv()...)
+	notify.RegisterJobHandlers(worker, notifier)
+
+	// Phát sự kiện webhook; việc gửi tới endpoint do dispatcher của API server đảm nhận
+	webhook.RegisterJobHandlers(worker, webhook.NewDispatcher(db, 0))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	worker.Start(ctx)
+	log.Printf("Worker %s started (concurrency=%d, poll=%s)", buildinfo.Get(), concurrency, pollInterval)
+
+	<-ctx.Done()
+	log.Printf("Worker stopped: %+v", worker.GetStats())
+}
@@ -0,0 +1,19 @@
+// Package actor mang ID người dùng thực hiện request trong context.Context, để tầng service và repository
+// ghi được tác giả của thay đổi (ví dụ lịch sử sản phẩm) mà không phải truyền user ID qua mọi hàm.
+// JWTMiddleware gắn actor cho mọi request đã xác thực.
+package actor
+
+import "context"
+
+type key struct{}
+
+// With trả về ctx mang userID là người thực hiện thao tác
+func With(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, key{}, userID)
+}
+
+// From lấy người thực hiện thao tác; ok = false với tác vụ nền hoặc request chưa xác thực
+func From(ctx context.Context) (userID uint, ok bool) {
+	userID, ok = ctx.Value(key{}).(uint)
+	return userID, ok && userID != 0
+}
@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// RegisterSubscribers ghi các sự kiện liên quan tới tài khoản trên bus vào audit_logs.
+// Action trùng với tên sự kiện; lỗi ghi log chỉ được log, không ảnh hưởng thao tác đã thực hiện.
+func RegisterSubscribers(bus *events.Bus, db *gorm.DB) {
+	repo := repository.NewAuditLogRepository(db)
+
+	events.On(bus, func(ctx context.Context, e events.UserLoggedIn) {
+		record(ctx, repo, e, e.UserID, "user", e.UserID, e.IP, e.At, map[string]string{"user_agent": e.UserAgent})
+	})
+	events.On(bus, func(ctx context.Context, e events.SuspiciousLogin) {
+		record(ctx, repo, e, e.User.ID, "user", e.User.ID, e.IP, e.At, map[string]string{
+			"user_agent":            e.UserAgent,
+			"country":               e.Country,
+			"reasons":               strings.Join(e.Reasons, ","),
+			"confirmation_required": strconv.FormatBool(e.ConfirmationRequired),
+		})
+	})
+	events.On(bus, func(ctx context.Context, e events.LoggedOutEverywhere) {
+		record(ctx, repo, e, e.UserID, "user", e.UserID, e.IP, e.At, nil)
+	})
+	events.On(bus, func(ctx context.Context, e events.PasswordChanged) {
+		record(ctx, repo, e, e.UserID, "user", e.UserID, e.IP, e.At, nil)
+	})
+	events.On(bus, func(ctx context.Context, e events.AccountDeleted) {
+		record(ctx, repo, e, e.UserID, "user", e.UserID, e.IP, e.At, nil)
+	})
+	events.On(bus, func(ctx context.Context, e events.AccountRestored) {
+		// Ghi theo user được khôi phục để thao tác hiện trong lịch sử của họ; admin nằm trong metadata
+		record(ctx, repo, e, e.UserID, "user", e.UserID, e.IP, e.At, map[string]string{"admin_id": strconv.FormatUint(uint64(e.AdminID), 10)})
+	})
+	events.On(bus, func(ctx context.Context, e events.EmailChangeRequested) {
+		record(ctx, repo, e, e.UserID, "email_change", e.ChangeID, e.IP, e.At, nil)
+	})
+	events.On(bus, func(ctx context.Context, e events.EmailChanged) {
+		record(ctx, repo, e, e.UserID, "email_change", e.ChangeID, e.IP, e.At, nil)
+	})
+	events.On(bus, func(ctx context.Context, e events.UserInvited) {
+		// Ghi theo user được mời như khi khôi phục tài khoản; admin nằm trong metadata
+		record(ctx, repo, e, e.UserID, "user", e.UserID, e.IP, e.At, map[string]string{"admin_id": strconv.FormatUint(uint64(e.AdminID), 10)})
+	})
+	events.On(bus, func(ctx context.Context, e events.InvitationAccepted) {
+		record(ctx, repo, e, e.UserID, "user", e.UserID, e.IP, e.At, nil)
+	})
+	events.On(bus, func(ctx context.Context, e events.PaymentMethodAdded) {
+		record(ctx, repo, e, e.UserID, "payment_method", e.MethodID, e.IP, e.At, nil)
+	})
+	events.On(bus, func(ctx context.Context, e events.PaymentMethodRemoved) {
+		record(ctx, repo, e, e.UserID, "payment_method", e.MethodID, e.IP, e.At, nil)
+	})
+}
+
+// RegisterProductSubscribers ghi việc thay ảnh sản phẩm vào audit_logs (entity_type "product") cùng URL
+// ảnh cũ và mới, và việc duyệt hoặc từ chối revision sản phẩm (entity_type "product_revision") theo admin duyệt
+func RegisterProductSubscribers(bus *events.Bus, db *gorm.DB) {
+	repo := repository.NewAuditLogRepository(db)
+
+	events.On(bus, func(ctx context.Context, e events.ProductImageReplaced) {
+		raw, _ := json.Marshal(map[string]string{"old_url": e.OldURL, "new_url": e.NewURL})
+		entry := &models.AuditLog{
+			Action:     e.EventName(),
+			EntityType: "product",
+			EntityID:   strconv.FormatUint(uint64(e.ProductID), 10),
+			Metadata:   models.JSON(raw),
+			CreatedAt:  e.At,
+		}
+		if err := repo.Create(context.WithoutCancel(ctx), entry); err != nil {
+			log.Printf("Audit: failed to record %s for product %d: %v", e.EventName(), e.ProductID, err)
+		}
+	})
+	events.On(bus, func(ctx context.Context, e events.ProductRevisionApproved) {
+		recordRevision(ctx, repo, e, e.Revision, e.At)
+	})
+	events.On(bus, func(ctx context.Context, e events.ProductRevisionRejected) {
+		recordRevision(ctx, repo, e, e.Revision, e.At)
+	})
+}
+
+func recordRevision(ctx context.Context, repo *repository.AuditLogRepository, e events.Event, revision models.ProductRevision, at time.Time) {
+	if revision.ReviewedBy == nil {
+		return
+	}
+	record(ctx, repo, e, *revision.ReviewedBy, "product_revision", revision.ID, "", at, map[string]string{
+		"product_id":   strconv.FormatUint(uint64(revision.ProductID), 10),
+		"submitted_by": strconv.FormatUint(uint64(revision.SubmittedBy), 10),
+	})
+}
+
+func record(ctx context.Context, repo *repository.AuditLogRepository, e events.Event, userID uint, entityType string, entityID uint, ip string, at time.Time, metadata map[string]string) {
+	entry := &models.AuditLog{
+		UserID:     &userID,
+		Action:     e.EventName(),
+		EntityType: entityType,
+		EntityID:   strconv.FormatUint(uint64(entityID), 10),
+		IPAddress:  ip,
+		CreatedAt:  at,
+	}
+	if len(metadata) > 0 {
+		raw, _ := json.Marshal(metadata)
+		entry.Metadata = models.JSON(raw)
+	}
+	if err := repo.Create(context.WithoutCancel(ctx), entry); err != nil {
+		log.Printf("Audit: failed to record %s for user %d: %v", e.EventName(), userID, err)
+	}
+}
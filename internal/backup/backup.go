@@ -0,0 +1,282 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/database"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"golang.org/x/crypto/scrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FormatVersion là phiên bản định dạng file backup
+const FormatVersion = 1
+
+// magic đánh dấu đầu file backup đã mã hóa
+var magic = []byte("BSBK1")
+
+// UserRecord là bản ghi user trong backup, gồm cả các trường bị ẩn khỏi JSON của models.User: password hash
+// để khôi phục đăng nhập, thời điểm xóa mềm để tài khoản đã xóa không được khôi phục thành tài khoản đang
+// hoạt động, và token version để token đã thu hồi không có hiệu lực trở lại
+type UserRecord struct {
+	models.User
+	PasswordHash string     `json:"password_hash"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	TokenVersion int        `json:"token_version"`
+}
+
+// MediaEntry mô tả một file media trong thư mục upload
+type MediaEntry struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Archive là nội dung logic của một bản backup
+type Archive struct {
+	Version   int              `json:"version"`
+	CreatedAt time.Time        `json:"created_at"`
+	Users     []UserRecord     `json:"users"`
+	Products  []models.Product `json:"products"`
+	Media     []MediaEntry     `json:"media"`
+}
+
+// Export đọc toàn bộ dữ liệu trong một transaction REPEATABLE READ chỉ đọc để
+// users và products nhất quán với nhau tại cùng một thời điểm
+func Export(db *gorm.DB, uploadDir string) (*Archive, error) {
+	archive := &Archive{Version: FormatVersion, CreatedAt: time.Now().UTC()}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Unscoped để backup gồm cả tài khoản đã xóa mềm còn trong thời gian chờ khôi phục
+		var users []models.User
+		if err := tx.Unscoped().Order("id ASC").Find(&users).Error; err != nil {
+			return fmt.Errorf("export users: %w", err)
+		}
+		for _, u := range users {
+			rec := UserRecord{User: u, PasswordHash: u.Password, TokenVersion: u.TokenVersion}
+			if u.DeletedAt.Valid {
+				rec.DeletedAt = &u.DeletedAt.Time
+			}
+			archive.Users = append(archive.Users, rec)
+		}
+		if err := tx.Order("id ASC").Find(&archive.Products).Error; err != nil {
+			return fmt.Errorf("export products: %w", err)
+		}
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	media, err := BuildMediaManifest(uploadDir)
+	if err != nil {
+		return nil, err
+	}
+	archive.Media = media
+	return archive, nil
+}
+
+// BuildMediaManifest liệt kê các file trong thư mục upload kèm kích thước và checksum
+func BuildMediaManifest(uploadDir string) ([]MediaEntry, error) {
+	var entries []MediaEntry
+	err := filepath.WalkDir(uploadDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		entry, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(uploadDir, path)
+		if err != nil {
+			return err
+		}
+		entry.Path = filepath.ToSlash(rel)
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+func hashFile(path string) (MediaEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return MediaEntry{}, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return MediaEntry{}, err
+	}
+	return MediaEntry{Size: n, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// VerifyMedia so sánh manifest với thư mục upload hiện tại và trả về các file thiếu hoặc sai checksum
+func VerifyMedia(uploadDir string, manifest []MediaEntry) (missing, mismatched []string) {
+	for _, m := range manifest {
+		entry, err := hashFile(filepath.Join(uploadDir, filepath.FromSlash(m.Path)))
+		if err != nil {
+			missing = append(missing, m.Path)
+			continue
+		}
+		if entry.SHA256 != m.SHA256 {
+			mismatched = append(mismatched, m.Path)
+		}
+	}
+	return missing, mismatched
+}
+
+// Write nén (gzip) và mã hóa archive bằng AES-256-GCM với khóa dẫn xuất từ passphrase (scrypt).
+// Định dạng: magic | salt(16) | nonce(12) | ciphertext
+func Write(w io.Writer, archive *Archive, passphrase string) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(gz).Encode(archive); err != nil {
+		return fmt.Errorf("encode archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	plaintext := buf.Bytes()
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	for _, part := range [][]byte{magic, salt, nonce, gcm.Seal(nil, nonce, plaintext, magic)} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read giải mã và giải nén một file backup
+func Read(r io.Reader, passphrase string) (*Archive, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < len(magic)+16 || string(data[:len(magic)]) != string(magic) {
+		return nil, errors.New("not a backup file")
+	}
+	data = data[len(magic):]
+	salt, data := data[:16], data[16:]
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("backup file is truncated")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, magic)
+	if err != nil {
+		return nil, errors.New("cannot decrypt backup: wrong passphrase or corrupted file")
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(plaintext))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var archive Archive
+	if err := json.NewDecoder(gz).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("decode archive: %w", err)
+	}
+	if archive.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", archive.Version)
+	}
+	return &archive, nil
+}
+
+// Restore ghi đè users và products từ archive trong một transaction, giữ nguyên ID, đồng bộ lại
+// sequence để các bản ghi tạo sau không bị trùng khóa và dựng lại read model product_listings
+func Restore(db *gorm.DB, archive *Archive) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, rec := range archive.Users {
+			user := rec.User
+			user.Password = rec.PasswordHash
+			user.TokenVersion = rec.TokenVersion
+			if rec.DeletedAt != nil {
+				user.DeletedAt = gorm.DeletedAt{Time: *rec.DeletedAt, Valid: true}
+			}
+			if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&user).Error; err != nil {
+				return fmt.Errorf("restore user %d: %w", user.ID, err)
+			}
+		}
+		for _, product := range archive.Products {
+			p := product
+			if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&p).Error; err != nil {
+				return fmt.Errorf("restore product %d: %w", p.ID, err)
+			}
+		}
+		// Postgres không tự tăng sequence khi chèn ID tường minh; MySQL/SQLite tự điều chỉnh
+		if database.IsPostgres(tx) {
+			for _, table := range []string{"users", "products"} {
+				if err := tx.Exec(fmt.Sprintf(
+					"SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), COALESCE((SELECT MAX(id) FROM %[1]s), 1))", table,
+				)).Error; err != nil {
+					return fmt.Errorf("reset %s sequence: %w", table, err)
+				}
+			}
+		}
+		// CLI không đăng ký projector của read model nên product_listings được dựng lại ở đây
+		if err := repository.NewProductListingRepository(tx).RefreshAll(context.Background()); err != nil {
+			return fmt.Errorf("refresh product listings: %w", err)
+		}
+		return nil
+	})
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase is required")
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
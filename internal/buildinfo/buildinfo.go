@@ -0,0 +1,78 @@
+// Package buildinfo cho biết bản build đang chạy. Version, Commit và BuildTime được gắn lúc build bằng
+// ldflags, ví dụ:
+//
+//	go build -ldflags "-X github.com/NgTruong624/project_backend/internal/buildinfo.Version=v1.4.0 \
+//	  -X github.com/NgTruong624/project_backend/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/NgTruong624/project_backend/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/api
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Được gắn bằng -ldflags "-X ..."; để trống khi build thường
+var (
+	Version   = ""
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info là thông tin bản build trả về ở GET /api/v1/version và /readyz
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+	// Modified cho biết cây mã có thay đổi chưa commit lúc build (chỉ biết khi Go tự ghi thông tin VCS)
+	Modified bool `json:"modified,omitempty"`
+}
+
+// Get trả về thông tin bản build. Khi không có ldflags, commit và thời điểm commit được lấy từ thông tin
+// VCS mà `go build` ghi vào binary; phiên bản mặc định là "dev" và commit là "unknown".
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+	}
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	return info
+}
+
+// String là dạng ngắn để ghi log khi khởi động, ví dụ "v1.4.0 (commit 1a2b3c4, built 2024-05-01T10:00:00Z, go1.23.0)"
+func (i Info) String() string {
+	commit := i.Commit
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+	if i.Modified {
+		commit += "-dirty"
+	}
+	built := i.BuildTime
+	if built == "" {
+		built = "unknown"
+	}
+	return fmt.Sprintf("%s (commit %s, built %s, %s)", i.Version, commit, built, i.GoVersion)
+}
@@ -0,0 +1,114 @@
+// Package captcha kiểm tra token captcha mà client lấy từ widget (Cloudflare Turnstile, hCaptcha hoặc
+// Google reCAPTCHA) bằng API siteverify của nhà cung cấp, để bảo vệ các form công khai khỏi bot. Guard
+// chọn route nào cần captcha và với đăng nhập thì chỉ yêu cầu sau nhiều lần thất bại.
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+)
+
+var (
+	// ErrMissing: request không kèm token captcha
+	ErrMissing = errors.New("captcha token is required")
+	// ErrFailed: nhà cung cấp từ chối token (sai, hết hạn, đã dùng hoặc điểm reCAPTCHA quá thấp)
+	ErrFailed = errors.New("captcha verification failed")
+)
+
+// siteverifyURLs là endpoint kiểm tra token của từng nhà cung cấp; cả ba nhận cùng một form (secret,
+// response, remoteip) và trả về JSON có trường success
+var siteverifyURLs = map[string]string{
+	"turnstile": "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+	"hcaptcha":  "https://api.hcaptcha.com/siteverify",
+	"recaptcha": "https://www.google.com/recaptcha/api/siteverify",
+}
+
+// Verifier kiểm tra token captcha do client gửi; remoteIP là IP của client. Verify trả về ErrMissing khi
+// token rỗng, ErrFailed khi token bị từ chối, hoặc lỗi khác khi không kiểm tra được (nhà cung cấp lỗi).
+type Verifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// siteverify là Verifier gọi API siteverify của Turnstile, hCaptcha hoặc reCAPTCHA
+type siteverify struct {
+	provider string
+	secret   string
+	url      string
+	minScore float64
+	client   *http.Client
+}
+
+// NewVerifierFromEnv cấu hình Verifier từ CAPTCHA_PROVIDER (turnstile, hcaptcha hoặc recaptcha; mặc định
+// turnstile) và CAPTCHA_SECRET; trả về nil khi không có CAPTCHA_SECRET. CAPTCHA_MIN_SCORE (mặc định 0.5) là
+// điểm tối thiểu với reCAPTCHA v3.
+func NewVerifierFromEnv() (Verifier, error) {
+	secret := config.String("CAPTCHA_SECRET", "")
+	if secret == "" {
+		return nil, nil
+	}
+	provider := strings.ToLower(config.String("CAPTCHA_PROVIDER", "turnstile"))
+	endpoint, ok := siteverifyURLs[provider]
+	if !ok {
+		return nil, fmt.Errorf("CAPTCHA_PROVIDER: unsupported provider %q", provider)
+	}
+	return &siteverify{
+		provider: provider,
+		secret:   secret,
+		url:      endpoint,
+		minScore: config.Float("CAPTCHA_MIN_SCORE", 0.5),
+		client:   &http.Client{Timeout: 5 * time.Second},
+	}, nil
+}
+
+// siteverifyResponse là phần cần dùng trong response của API siteverify
+type siteverifyResponse struct {
+	Success    bool     `json:"success"`
+	Score      *float64 `json:"score"` // chỉ reCAPTCHA v3
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// Verify gửi token cùng remoteIP tới nhà cung cấp; remoteIP giúp phát hiện token bị dùng lại từ máy khác
+func (v *siteverify) Verify(ctx context.Context, token, remoteIP string) error {
+	if token == "" {
+		return ErrMissing
+	}
+
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("captcha %s: %w", v.provider, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("captcha %s: unexpected status %d", v.provider, resp.StatusCode)
+	}
+
+	var result siteverifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("captcha %s: %w", v.provider, err)
+	}
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrFailed, strings.Join(result.ErrorCodes, ", "))
+	}
+	// Điểm của hCaptcha Enterprise có nghĩa ngược lại (cao là rủi ro), nên chỉ so điểm với reCAPTCHA
+	if v.provider == "recaptcha" && result.Score != nil && *result.Score < v.minScore {
+		return fmt.Errorf("%w: score %.1f is below %.1f", ErrFailed, *result.Score, v.minScore)
+	}
+	return nil
+}
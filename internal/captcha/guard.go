@@ -0,0 +1,174 @@
+package captcha
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// Các route có thể bật captcha qua CAPTCHA_ROUTES
+const (
+	RouteRegister      = "register"       // POST /auth/register
+	RouteLogin         = "login"          // POST /auth/login, chỉ sau nhiều lần đăng nhập thất bại
+	RouteSupportTicket = "support_ticket" // POST /support/tickets (form liên hệ) của khách chưa đăng nhập
+	RouteNewsletter    = "newsletter"     // POST /newsletter/subscribe
+)
+
+var knownRoutes = map[string]bool{
+	RouteRegister:      true,
+	RouteLogin:         true,
+	RouteSupportTicket: true,
+	RouteNewsletter:    true,
+}
+
+// DefaultRoutes là các route cần captcha khi không đặt CAPTCHA_ROUTES
+const DefaultRoutes = RouteRegister + "," + RouteLogin + "," + RouteSupportTicket
+
+// maxTrackedLogins giới hạn số khóa đếm lần đăng nhập thất bại trước khi dọn các khóa đã hết hạn
+const maxTrackedLogins = 10000
+
+// Guard quyết định request nào phải kèm captcha: route được bật trong CAPTCHA_ROUTES và, với đăng nhập,
+// khi IP hoặc username đã đăng nhập sai ít nhất loginAfter lần trong window. Số lần sai được đếm trong bộ
+// nhớ của từng instance (giống rate limiter). Guard nil hoặc không có Verifier thì captcha bị tắt.
+type Guard struct {
+	verifier   Verifier
+	routes     map[string]bool
+	loginAfter int
+	window     time.Duration
+
+	mu       sync.Mutex
+	failures map[string]*loginFailures
+}
+
+// loginFailures là số lần đăng nhập sai của một IP hoặc username tính từ start
+type loginFailures struct {
+	count int
+	start time.Time
+}
+
+// NewGuard tạo Guard bật captcha cho các route; loginAfter bằng 0 thì đăng nhập luôn cần captcha.
+// Tên route không hợp lệ trả về lỗi.
+func NewGuard(verifier Verifier, routes []string, loginAfter int, window time.Duration) (*Guard, error) {
+	enabled := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		route = strings.ToLower(strings.TrimSpace(route))
+		if route == "" {
+			continue
+		}
+		if !knownRoutes[route] {
+			return nil, fmt.Errorf("CAPTCHA_ROUTES: unknown route %q", route)
+		}
+		enabled[route] = true
+	}
+	if loginAfter < 0 {
+		loginAfter = 0
+	}
+	return &Guard{
+		verifier:   verifier,
+		routes:     enabled,
+		loginAfter: loginAfter,
+		window:     window,
+		failures:   make(map[string]*loginFailures),
+	}, nil
+}
+
+// NewGuardFromEnv cấu hình Guard từ NewVerifierFromEnv, CAPTCHA_ROUTES (mặc định DefaultRoutes),
+// CAPTCHA_LOGIN_AFTER_FAILURES (mặc định 3) và CAPTCHA_LOGIN_FAILURE_WINDOW (mặc định 15m). Captcha bị
+// tắt khi không có CAPTCHA_SECRET hoặc khi gin chạy ở chế độ test (GIN_MODE=test).
+func NewGuardFromEnv() (*Guard, error) {
+	var verifier Verifier
+	if gin.Mode() != gin.TestMode {
+		v, err := NewVerifierFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		verifier = v
+	}
+	return NewGuard(verifier,
+		strings.Split(config.String("CAPTCHA_ROUTES", DefaultRoutes), ","),
+		config.Int("CAPTCHA_LOGIN_AFTER_FAILURES", 3),
+		config.Duration("CAPTCHA_LOGIN_FAILURE_WINDOW", 15*time.Minute),
+	)
+}
+
+// Active cho biết captcha đang bật (có Verifier)
+func (g *Guard) Active() bool {
+	return g != nil && g.verifier != nil
+}
+
+// Enabled cho biết route có yêu cầu captcha hay không
+func (g *Guard) Enabled(route string) bool {
+	return g.Active() && g.routes[route]
+}
+
+// Verify kiểm tra token bằng Verifier của Guard; luôn thành công khi captcha bị tắt
+func (g *Guard) Verify(ctx context.Context, token, remoteIP string) error {
+	if !g.Active() {
+		return nil
+	}
+	return g.verifier.Verify(ctx, token, remoteIP)
+}
+
+// LoginRequired cho biết lần đăng nhập từ ip với username có phải kèm captcha hay không
+func (g *Guard) LoginRequired(ip, username string) bool {
+	if g.loginAfter == 0 {
+		return true
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	return g.countLocked("ip:"+ip, now) >= g.loginAfter ||
+		(username != "" && g.countLocked("user:"+loginKey(username), now) >= g.loginAfter)
+}
+
+// RecordLoginFailure tăng số lần đăng nhập sai của ip và username
+func (g *Guard) RecordLoginFailure(ip, username string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	if len(g.failures) >= maxTrackedLogins {
+		for key, f := range g.failures {
+			if now.Sub(f.start) >= g.window {
+				delete(g.failures, key)
+			}
+		}
+	}
+	g.addLocked("ip:"+ip, now)
+	if username != "" {
+		g.addLocked("user:"+loginKey(username), now)
+	}
+}
+
+// ResetLoginFailures xóa số lần sai của username sau khi đăng nhập thành công. Số lần sai của IP được giữ
+// để đăng nhập đúng một tài khoản không mở khóa việc dò mật khẩu tài khoản khác từ cùng IP.
+func (g *Guard) ResetLoginFailures(username string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.failures, "user:"+loginKey(username))
+}
+
+func (g *Guard) countLocked(key string, now time.Time) int {
+	f, ok := g.failures[key]
+	if !ok || now.Sub(f.start) >= g.window {
+		return 0
+	}
+	return f.count
+}
+
+func (g *Guard) addLocked(key string, now time.Time) {
+	f, ok := g.failures[key]
+	if !ok || now.Sub(f.start) >= g.window {
+		g.failures[key] = &loginFailures{count: 1, start: now}
+		return
+	}
+	f.count++
+}
+
+func loginKey(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
@@ -0,0 +1,244 @@
+// Package client là Go SDK cho API của BackendShop, dùng bởi các công cụ CLI
+// và các service Go nội bộ khác.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/utils"
+)
+
+// APIError là lỗi do API trả về theo cấu trúc utils.Response
+type APIError struct {
+	Status  int
+	Message string
+	Detail  interface{}
+}
+
+func (e *APIError) Error() string {
+	if e.Detail != nil && e.Detail != "" {
+		return fmt.Sprintf("api error %d: %s (%v)", e.Status, e.Message, e.Detail)
+	}
+	return fmt.Sprintf("api error %d: %s", e.Status, e.Message)
+}
+
+// IsNotFound kiểm tra lỗi có phải 404 không
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+// Page là một trang kết quả của API có phân trang
+type Page[T any] struct {
+	Items      []T
+	Pagination utils.Pagination
+}
+
+// Option tùy chỉnh Client
+type Option func(*Client)
+
+// WithToken dùng JWT có sẵn cho mọi request
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithCredentials cho phép client tự đăng nhập và đăng nhập lại khi token hết hạn
+func WithCredentials(username, password string) Option {
+	return func(c *Client) { c.username, c.password = username, password }
+}
+
+// WithHTTPClient thay http.Client mặc định
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithRetries đặt số lần thử lại tối đa cho lỗi mạng, 429 và 5xx
+func WithRetries(n int) Option {
+	return func(c *Client) {
+		if n >= 0 {
+			c.maxRetries = n
+		}
+	}
+}
+
+// Client gọi API qua HTTP với xác thực JWT và thử lại có backoff
+type Client struct {
+	baseURL    string
+	http       *http.Client
+	maxRetries int
+	username   string
+	password   string
+
+	mu    sync.Mutex
+	token string
+}
+
+// New tạo client cho API tại baseURL (ví dụ http://localhost:8080)
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		http:       &http.Client{Timeout: 30 * time.Second},
+		maxRetries: 3,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Login đăng nhập và lưu token cho các request sau
+func (c *Client) Login(ctx context.Context, username, password string) error {
+	var out struct {
+		Token string `json:"token"`
+	}
+	body := map[string]string{"username": username, "password": password}
+	if err := c.send(ctx, http.MethodPost, "/api/v1/auth/login", nil, body, &out, nil, false); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.token = out.Token
+	c.mu.Unlock()
+	return nil
+}
+
+// Token trả về JWT hiện tại
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// do gửi request có xác thực; đăng nhập trước nếu chưa có token, và đăng nhập lại
+// một lần khi nhận 401 nếu client có thông tin đăng nhập
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}, meta *utils.Meta) error {
+	if c.Token() == "" && c.username != "" {
+		if err := c.Login(ctx, c.username, c.password); err != nil {
+			return err
+		}
+	}
+	err := c.send(ctx, method, path, query, body, out, meta, true)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusUnauthorized && c.username != "" {
+		if err := c.Login(ctx, c.username, c.password); err != nil {
+			return err
+		}
+		return c.send(ctx, method, path, query, body, out, meta, true)
+	}
+	return err
+}
+
+// send gửi một request và giải mã envelope; chỉ thử lại request idempotent
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body, out interface{}, meta *utils.Meta, auth bool) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	retries := 0
+	if method == http.MethodGet || method == http.MethodPut || method == http.MethodDelete {
+		retries = c.maxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if token := c.Token(); auth && token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := c.http.Do(req)
+		if err == nil && !retryable(resp.StatusCode) {
+			defer resp.Body.Close()
+			return decode(resp, out, meta)
+		}
+
+		var wait time.Duration
+		if err == nil {
+			wait = retryAfter(resp)
+			if attempt >= retries {
+				defer resp.Body.Close()
+				return decode(resp, out, meta)
+			}
+			resp.Body.Close()
+		} else if attempt >= retries {
+			return err
+		}
+		if wait == 0 {
+			wait = time.Duration(200<<uint(attempt)) * time.Millisecond
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+		return time.Duration(s) * time.Second
+	}
+	return 0
+}
+
+// decode giải mã envelope utils.Response/PaginatedResponse vào out và meta
+func decode(resp *http.Response, out interface{}, meta *utils.Meta) error {
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var envelope struct {
+		Status  int             `json:"status"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+		Error   interface{}     `json:"error"`
+		Meta    *utils.Meta     `json:"meta"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		if resp.StatusCode >= 400 {
+			return &APIError{Status: resp.StatusCode, Message: http.StatusText(resp.StatusCode), Detail: strings.TrimSpace(string(raw))}
+		}
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return &APIError{Status: resp.StatusCode, Message: envelope.Message, Detail: envelope.Error}
+	}
+
+	if meta != nil && envelope.Meta != nil {
+		*meta = *envelope.Meta
+	}
+	if out != nil && len(envelope.Data) > 0 && string(envelope.Data) != "null" {
+		return json.Unmarshal(envelope.Data, out)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/utils"
+)
+
+func pageQuery(page, limit int) url.Values {
+	q := url.Values{}
+	if page > 0 {
+		q.Set("page", strconv.Itoa(page))
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	return q
+}
+
+func setIf(q url.Values, key, value string) {
+	if value != "" {
+		q.Set(key, value)
+	}
+}
+
+func list[T any](ctx context.Context, c *Client, path string, query url.Values) (*Page[T], error) {
+	var items []T
+	var meta utils.Meta
+	if err := c.do(ctx, http.MethodGet, path, query, nil, &items, &meta); err != nil {
+		return nil, err
+	}
+	return &Page[T]{Items: items, Pagination: meta.Pagination}, nil
+}
+
+// --- Products ---
+
+// ListProducts lấy danh sách sản phẩm
+func (c *Client) ListProducts(ctx context.Context, params models.ProductQueryParams) (*Page[models.ProductResponse], error) {
+	q := pageQuery(params.Page, params.Limit)
+	setIf(q, "search", params.Search)
+	setIf(q, "category", params.Category)
+	setIf(q, "sort_by", params.SortBy)
+	setIf(q, "order", params.Order)
+	setIf(q, "count", params.Count)
+	if params.MinPrice > 0 {
+		q.Set("min_price", strconv.FormatFloat(params.MinPrice, 'f', -1, 64))
+	}
+	if params.MaxPrice > 0 {
+		q.Set("max_price", strconv.FormatFloat(params.MaxPrice, 'f', -1, 64))
+	}
+	if params.InStock {
+		q.Set("in_stock", "true")
+	}
+	return list[models.ProductResponse](ctx, c, "/api/v1/products", q)
+}
+
+// GetProduct lấy chi tiết sản phẩm
+func (c *Client) GetProduct(ctx context.Context, id uint) (*models.ProductResponse, error) {
+	var product models.ProductResponse
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/v1/products/%d", id), nil, nil, &product, nil); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// CreateProduct tạo sản phẩm mới
+func (c *Client) CreateProduct(ctx context.Context, req models.CreateProductRequest) (*models.ProductResponse, error) {
+	var product models.ProductResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/products", nil, req, &product, nil); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// UpdateProduct cập nhật sản phẩm
+func (c *Client) UpdateProduct(ctx context.Context, id uint, req models.UpdateProductRequest) (*models.ProductResponse, error) {
+	var product models.ProductResponse
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/api/v1/products/%d", id), nil, req, &product, nil); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// DeleteProduct xóa sản phẩm
+func (c *Client) DeleteProduct(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/v1/products/%d", id), nil, nil, nil, nil)
+}
+
+// --- Admin ---
+
+// ListUsers lấy danh sách user
+func (c *Client) ListUsers(ctx context.Context, params models.UserQueryParams) (*Page[models.UserResponse], error) {
+	q := pageQuery(params.Page, params.Limit)
+	setIf(q, "search", params.Search)
+	setIf(q, "role", params.Role)
+	return list[models.UserResponse](ctx, c, "/api/v1/admin/users", q)
+}
+
+// ListJobs lấy danh sách job nền
+func (c *Client) ListJobs(ctx context.Context, params models.JobQueryParams) (*Page[models.Job], error) {
+	q := pageQuery(params.Page, params.Limit)
+	setIf(q, "status", params.Status)
+	setIf(q, "type", params.Type)
+	return list[models.Job](ctx, c, "/api/v1/admin/jobs", q)
+}
+
+// GetJobStats đếm số job theo trạng thái
+func (c *Client) GetJobStats(ctx context.Context) (map[string]int64, error) {
+	var counts map[string]int64
+	err := c.do(ctx, http.MethodGet, "/api/v1/admin/jobs/stats", nil, nil, &counts, nil)
+	return counts, err
+}
+
+// RetryJob đưa job thất bại trở lại hàng đợi
+func (c *Client) RetryJob(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/api/v1/admin/jobs/%d/retry", id), nil, nil, nil, nil)
+}
+
+// ListScheduledTasks lấy trạng thái các tác vụ định kỳ
+func (c *Client) ListScheduledTasks(ctx context.Context) ([]models.ScheduledTask, error) {
+	var tasks []models.ScheduledTask
+	err := c.do(ctx, http.MethodGet, "/api/v1/admin/scheduler", nil, nil, &tasks, nil)
+	return tasks, err
+}
+
+// RunScheduledTask yêu cầu chạy tác vụ định kỳ ngay
+func (c *Client) RunScheduledTask(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/admin/scheduler/"+url.PathEscape(name)+"/run", nil, nil, nil, nil)
+}
+
+// ListWebhooks lấy danh sách webhook
+func (c *Client) ListWebhooks(ctx context.Context) ([]models.WebhookSubscriptionResponse, error) {
+	var subs []models.WebhookSubscriptionResponse
+	err := c.do(ctx, http.MethodGet, "/api/v1/admin/webhooks", nil, nil, &subs, nil)
+	return subs, err
+}
+
+// CreateWebhook đăng ký webhook mới và trả về secret (chỉ được trả một lần)
+func (c *Client) CreateWebhook(ctx context.Context, req models.CreateWebhookRequest) (*models.WebhookSubscriptionResponse, string, error) {
+	var out struct {
+		Webhook models.WebhookSubscriptionResponse `json:"webhook"`
+		Secret  string                             `json:"secret"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/admin/webhooks", nil, req, &out, nil); err != nil {
+		return nil, "", err
+	}
+	return &out.Webhook, out.Secret, nil
+}
+
+// DeleteWebhook xóa webhook
+func (c *Client) DeleteWebhook(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/v1/admin/webhooks/%d", id), nil, nil, nil, nil)
+}
@@ -0,0 +1,60 @@
+// Package config đọc cấu hình ứng dụng từ biến môi trường. Giá trị không hợp lệ được ghi log
+// cảnh báo và thay bằng giá trị mặc định thay vì làm dừng tiến trình.
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// String đọc biến môi trường key, trả về def nếu chưa đặt
+func String(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// Int đọc số nguyên không âm từ biến môi trường key
+func Int(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		log.Printf("Warning: invalid %s=%q, using %d", key, v, def)
+		return def
+	}
+	return n
+}
+
+// Duration đọc khoảng thời gian (ví dụ 30m, 1h) không âm từ biến môi trường key
+func Duration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		log.Printf("Warning: invalid %s=%q, using %s", key, v, def)
+		return def
+	}
+	return d
+}
+
+// Float đọc số thực không âm từ biến môi trường key
+func Float(key string, def float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		log.Printf("Warning: invalid %s=%q, using %g", key, v, def)
+		return def
+	}
+	return f
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// DBPool là cấu hình pool kết nối của database/sql. Mặc định của database/sql (không giới hạn
+// kết nối mở, giữ 2 kết nối rảnh, không giới hạn tuổi kết nối) dễ gây lỗi "too many connections"
+// khi tải cao và giữ kết nối tới node cũ quá lâu sau failover.
+type DBPool struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// DBPoolFromEnv đọc cấu hình pool từ DB_MAX_OPEN_CONNS (mặc định 25), DB_MAX_IDLE_CONNS (mặc định 10),
+// DB_CONN_MAX_LIFETIME (mặc định 30m) và DB_CONN_MAX_IDLE_TIME (mặc định 5m). Với số kết nối mở và
+// thời gian, 0 là không giới hạn; DB_MAX_IDLE_CONNS=0 là không giữ kết nối rảnh. Số kết nối rảnh
+// không vượt quá số kết nối mở tối đa.
+func DBPoolFromEnv() DBPool {
+	pool := DBPool{
+		MaxOpenConns:    Int("DB_MAX_OPEN_CONNS", 25),
+		MaxIdleConns:    Int("DB_MAX_IDLE_CONNS", 10),
+		ConnMaxLifetime: Duration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		ConnMaxIdleTime: Duration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+	}
+	if pool.MaxOpenConns > 0 && pool.MaxIdleConns > pool.MaxOpenConns {
+		pool.MaxIdleConns = pool.MaxOpenConns
+	}
+	return pool
+}
+
+// SlowQuery là cấu hình ghi log truy vấn chậm
+type SlowQuery struct {
+	// Threshold là thời gian tối thiểu để một truy vấn bị coi là chậm; 0 là tắt
+	Threshold time.Duration
+	// Top là số câu truy vấn chậm nhất được giữ lại cho endpoint chẩn đoán; 0 là không giữ
+	Top int
+}
+
+// SlowQueryFromEnv đọc DB_SLOW_QUERY_THRESHOLD (mặc định 200ms, 0 là tắt) và DB_SLOW_QUERY_TOP (mặc định 20)
+func SlowQueryFromEnv() SlowQuery {
+	return SlowQuery{
+		Threshold: Duration("DB_SLOW_QUERY_THRESHOLD", 200*time.Millisecond),
+		Top:       max(Int("DB_SLOW_QUERY_TOP", 20), 0),
+	}
+}
+
+// SSLModes là các giá trị sslmode hợp lệ của libpq/pgx
+var SSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Database là thông tin kết nối database. URL (DATABASE_URL) nếu có sẽ được dùng thay cho các trường rời.
+type Database struct {
+	Driver      string // postgres (mặc định), mysql hoặc sqlite
+	URL         string
+	Hosts       []string // host hoặc host:port; nhiều host là các primary dùng cho failover
+	Port        string
+	User        string
+	Password    string
+	Name        string
+	SSLMode     string
+	SSLRootCert string
+
+	// Read replica: ReplicaURL (DATABASE_REPLICA_URL) hoặc ReplicaHosts (DB_REPLICA_HOST) dùng chung
+	// user, mật khẩu, tên database và TLS với primary
+	ReplicaURL   string
+	ReplicaHosts []string
+}
+
+// DatabaseFromEnv đọc loại database từ DB_DRIVER và thông tin kết nối từ DATABASE_URL, hoặc từ DB_HOST
+// (danh sách phân tách bởi dấu phẩy), DB_PORT (mặc định 5432, với mysql là 3306), DB_USER, DB_PASSWORD, DB_NAME.
+// DB_SSLMODE (mặc định prefer) và DB_SSLROOTCERT áp dụng cho cả hai cách; với DATABASE_URL chỉ khi
+// URL chưa tự chỉ định. Với sqlite, DB_NAME là đường dẫn file. Read replica được đọc từ DATABASE_REPLICA_URL
+// hoặc DB_REPLICA_HOST (danh sách phân tách bởi dấu phẩy).
+func DatabaseFromEnv() Database {
+	db := Database{
+		Driver:      strings.ToLower(String("DB_DRIVER", "postgres")),
+		URL:         String("DATABASE_URL", ""),
+		Port:        String("DB_PORT", ""),
+		User:        String("DB_USER", ""),
+		Password:    String("DB_PASSWORD", ""),
+		Name:        String("DB_NAME", ""),
+		SSLMode:     String("DB_SSLMODE", "prefer"),
+		SSLRootCert: String("DB_SSLROOTCERT", ""),
+		ReplicaURL:  String("DATABASE_REPLICA_URL", ""),
+	}
+	db.Hosts = hostList(String("DB_HOST", ""))
+	db.ReplicaHosts = hostList(String("DB_REPLICA_HOST", ""))
+	return db
+}
+
+// Replica trả về cấu hình kết nối tới các read replica (nil nếu không cấu hình replica)
+func (db Database) Replica() *Database {
+	if db.ReplicaURL == "" && len(db.ReplicaHosts) == 0 {
+		return nil
+	}
+	replica := db
+	replica.URL, replica.Hosts = db.ReplicaURL, db.ReplicaHosts
+	replica.ReplicaURL, replica.ReplicaHosts = "", nil
+	return &replica
+}
+
+// hostList tách danh sách host phân tách bởi dấu phẩy, bỏ qua phần tử rỗng
+func hostList(value string) []string {
+	var hosts []string
+	for _, host := range strings.Split(value, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
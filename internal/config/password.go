@@ -0,0 +1,49 @@
+package config
+
+import (
+	"log"
+	"math"
+)
+
+// Thuật toán băm mật khẩu
+const (
+	PasswordHashBcrypt   = "bcrypt"
+	PasswordHashArgon2id = "argon2id"
+)
+
+// PasswordHash là cấu hình băm mật khẩu. Mật khẩu mới luôn được băm theo Algorithm; hash cũ
+// (thuật toán hoặc tham số khác) vẫn được chấp nhận và được băm lại khi user đăng nhập thành công.
+type PasswordHash struct {
+	Algorithm  string
+	BcryptCost int
+	// Argon2Memory là bộ nhớ dùng cho mỗi lần băm, tính bằng KiB
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+}
+
+// PasswordHashFromEnv đọc PASSWORD_HASH_ALGORITHM (bcrypt hoặc argon2id, mặc định bcrypt), BCRYPT_COST
+// (4-31, mặc định 10), ARGON2_MEMORY (KiB, mặc định 19456), ARGON2_ITERATIONS (mặc định 2) và
+// ARGON2_PARALLELISM (mặc định 1). Mặc định của Argon2id theo khuyến nghị của OWASP.
+func PasswordHashFromEnv() PasswordHash {
+	cfg := PasswordHash{
+		Algorithm:         String("PASSWORD_HASH_ALGORITHM", PasswordHashBcrypt),
+		BcryptCost:        Int("BCRYPT_COST", 10),
+		Argon2Memory:      uint32(min(Int("ARGON2_MEMORY", 19456), math.MaxUint32)),
+		Argon2Iterations:  uint32(min(Int("ARGON2_ITERATIONS", 2), math.MaxUint32)),
+		Argon2Parallelism: uint8(min(Int("ARGON2_PARALLELISM", 1), math.MaxUint8)),
+	}
+	if cfg.Algorithm != PasswordHashBcrypt && cfg.Algorithm != PasswordHashArgon2id {
+		log.Printf("Warning: invalid PASSWORD_HASH_ALGORITHM=%q, using %s", cfg.Algorithm, PasswordHashBcrypt)
+		cfg.Algorithm = PasswordHashBcrypt
+	}
+	if cfg.BcryptCost < 4 || cfg.BcryptCost > 31 {
+		log.Printf("Warning: invalid BCRYPT_COST=%d, using 10", cfg.BcryptCost)
+		cfg.BcryptCost = 10
+	}
+	if cfg.Argon2Memory < 8*uint32(cfg.Argon2Parallelism) || cfg.Argon2Iterations == 0 || cfg.Argon2Parallelism == 0 {
+		log.Printf("Warning: invalid ARGON2_* parameters, using the defaults")
+		cfg.Argon2Memory, cfg.Argon2Iterations, cfg.Argon2Parallelism = 19456, 2, 1
+	}
+	return cfg
+}
@@ -0,0 +1,340 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SecretKeys là các biến môi trường chứa bí mật. Mỗi biến có thể được đặt trực tiếp, qua file ở
+// <KEY>_FILE (Docker/Kubernetes secrets) hoặc lấy từ kho bí mật cấu hình bởi SECRETS_PROVIDER.
+var SecretKeys = []string{
+	"JWT_SECRET", "FIELD_ENCRYPTION_KEYS",
+	"DB_PASSWORD", "DATABASE_URL", "DATABASE_REPLICA_URL",
+	"SMTP_PASSWORD",
+	"STRIPE_SECRET_KEY", "STRIPE_WEBHOOK_SECRET", "VNPAY_HASH_SECRET", "MOMO_ACCESS_KEY", "MOMO_SECRET_KEY",
+	"GHN_TOKEN", "GHTK_TOKEN",
+	"ALERT_TELEGRAM_BOT_TOKEN", "ALERT_SLACK_WEBHOOK_URL",
+	"INTEGRATION_API_KEYS", "PRODUCT_FEED_TOKENS", "CAPTCHA_SECRET",
+	"BACKUP_PASSPHRASE",
+	"ADMIN_PASSWORD", "ADMIN_API_TOKEN",
+}
+
+// Các giá trị của SECRETS_PROVIDER
+const (
+	SecretsProviderVault = "vault"
+	SecretsProviderAWS   = "aws"
+)
+
+// secretsTimeout giới hạn thời gian gọi kho bí mật khi khởi động
+const secretsTimeout = 10 * time.Second
+
+// SecretProvider đọc toàn bộ bí mật (tên biến -> giá trị) từ một kho bí mật bên ngoài
+type SecretProvider interface {
+	Name() string
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// LoadSecrets điền các biến trong SecretKeys chưa được đặt: trước hết từ file ở <KEY>_FILE (bỏ ký tự xuống
+// dòng ở cuối), sau đó từ kho bí mật của SECRETS_PROVIDER (vault hoặc aws) nếu có. Giá trị được ghi vào
+// biến môi trường của tiến trình nên mọi nơi đọc cấu hình giữ nguyên cách đọc. Biến đã đặt trực tiếp luôn
+// được ưu tiên. Phải gọi khi khởi động, sau khi nạp .env và trước khi đọc cấu hình.
+func LoadSecrets(ctx context.Context) error {
+	var missing []string
+	for _, key := range SecretKeys {
+		if os.Getenv(key) != "" {
+			continue
+		}
+		value, ok, err := secretFromFile(key)
+		if err != nil {
+			return err
+		}
+		if ok {
+			os.Setenv(key, value)
+			continue
+		}
+		missing = append(missing, key)
+	}
+
+	provider, err := secretProviderFromEnv()
+	if err != nil || provider == nil || len(missing) == 0 {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, secretsTimeout)
+	defer cancel()
+	secrets, err := provider.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("%s secrets: %w", provider.Name(), err)
+	}
+	var loaded []string
+	for _, key := range missing {
+		if value, ok := secrets[key]; ok && value != "" {
+			os.Setenv(key, value)
+			loaded = append(loaded, key)
+		}
+	}
+	log.Printf("Loaded %d secrets from %s: %s", len(loaded), provider.Name(), strings.Join(loaded, ", "))
+	return nil
+}
+
+// secretFromFile đọc giá trị của key từ file ở <KEY>_FILE nếu biến này được đặt
+func secretFromFile(key string) (string, bool, error) {
+	path := os.Getenv(key + "_FILE")
+	if path == "" {
+		return "", false, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("%s_FILE: %w", key, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), true, nil
+}
+
+// secretOrFile đọc bí mật dùng để truy cập kho bí mật (VAULT_TOKEN, AWS_SECRET_ACCESS_KEY) từ biến môi
+// trường hoặc <KEY>_FILE
+func secretOrFile(key string) (string, error) {
+	if value := os.Getenv(key); value != "" {
+		return value, nil
+	}
+	value, _, err := secretFromFile(key)
+	return value, err
+}
+
+// secretProviderFromEnv tạo kho bí mật theo SECRETS_PROVIDER; trả về nil nếu chưa cấu hình
+func secretProviderFromEnv() (SecretProvider, error) {
+	switch provider := strings.ToLower(os.Getenv("SECRETS_PROVIDER")); provider {
+	case "":
+		return nil, nil
+	case SecretsProviderVault:
+		return vaultProviderFromEnv()
+	case SecretsProviderAWS:
+		return awsProviderFromEnv()
+	default:
+		return nil, fmt.Errorf("unsupported SECRETS_PROVIDER %q (expected vault or aws)", provider)
+	}
+}
+
+// vaultProvider đọc một secret của HashiCorp Vault (KV v1 hoặc v2) qua HTTP API bằng token
+type vaultProvider struct {
+	addr      string
+	token     string
+	path      string
+	namespace string
+	client    *http.Client
+}
+
+// vaultProviderFromEnv đọc VAULT_ADDR, VAULT_TOKEN (hoặc VAULT_TOKEN_FILE), VAULT_SECRET_PATH (ví dụ
+// secret/data/backendshop với KV v2) và VAULT_NAMESPACE (Vault Enterprise)
+func vaultProviderFromEnv() (*vaultProvider, error) {
+	token, err := secretOrFile("VAULT_TOKEN")
+	if err != nil {
+		return nil, err
+	}
+	p := &vaultProvider{
+		addr:      strings.TrimRight(os.Getenv("VAULT_ADDR"), "/"),
+		token:     token,
+		path:      strings.Trim(os.Getenv("VAULT_SECRET_PATH"), "/"),
+		namespace: os.Getenv("VAULT_NAMESPACE"),
+		client:    &http.Client{Timeout: secretsTimeout},
+	}
+	if p.addr == "" || p.token == "" || p.path == "" {
+		return nil, fmt.Errorf("SECRETS_PROVIDER=vault requires VAULT_ADDR, VAULT_TOKEN and VAULT_SECRET_PATH")
+	}
+	return p, nil
+}
+
+func (p *vaultProvider) Name() string { return SecretsProviderVault }
+
+func (p *vaultProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.addr+"/v1/"+p.path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+	body, err := doSecretRequest(p.client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	data := resp.Data
+	// KV v2 lồng giá trị trong data.data, kèm data.metadata
+	if nested, ok := data["data"]; ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nil
+			if err := json.Unmarshal(nested, &data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return secretStrings(data), nil
+}
+
+// awsProvider đọc một secret dạng JSON {"JWT_SECRET": "...", ...} của AWS Secrets Manager, ký request
+// bằng Signature Version 4 với access key tĩnh
+type awsProvider struct {
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	secretID     string
+	endpoint     string
+	client       *http.Client
+}
+
+// awsProviderFromEnv đọc AWS_REGION (hoặc AWS_DEFAULT_REGION), AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+// (hoặc AWS_SECRET_ACCESS_KEY_FILE), AWS_SESSION_TOKEN, AWS_SECRET_ID (tên hoặc ARN của secret) và
+// AWS_SECRETS_MANAGER_ENDPOINT (mặc định https://secretsmanager.<region>.amazonaws.com)
+func awsProviderFromEnv() (*awsProvider, error) {
+	secretKey, err := secretOrFile("AWS_SECRET_ACCESS_KEY")
+	if err != nil {
+		return nil, err
+	}
+	p := &awsProvider{
+		region:       String("AWS_REGION", os.Getenv("AWS_DEFAULT_REGION")),
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    secretKey,
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		secretID:     os.Getenv("AWS_SECRET_ID"),
+		client:       &http.Client{Timeout: secretsTimeout},
+	}
+	if p.region == "" || p.accessKey == "" || p.secretKey == "" || p.secretID == "" {
+		return nil, fmt.Errorf("SECRETS_PROVIDER=aws requires AWS_REGION, AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SECRET_ID")
+	}
+	p.endpoint = strings.TrimRight(String("AWS_SECRETS_MANAGER_ENDPOINT", "https://secretsmanager."+p.region+".amazonaws.com"), "/")
+	return p, nil
+}
+
+func (p *awsProvider) Name() string { return SecretsProviderAWS }
+
+func (p *awsProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": p.secretID})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	p.sign(req, payload, time.Now().UTC())
+	body, err := doSecretRequest(p.client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(resp.SecretString), &data); err != nil {
+		return nil, fmt.Errorf("secret %s is not a JSON object: %w", p.secretID, err)
+	}
+	return secretStrings(data), nil
+}
+
+// sign thêm header X-Amz-Date, X-Amz-Security-Token (nếu có) và Authorization theo AWS Signature Version 4
+func (p *awsProvider) sign(req *http.Request, payload []byte, now time.Time) {
+	const service = "secretsmanager"
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if p.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", p.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{req.Method, path, req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, sha256Hex(payload)}, "\n")
+	scope := date + "/" + p.region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+p.secretKey), date)
+	for _, part := range []string{p.region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", p.accessKey, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// doSecretRequest gửi request tới kho bí mật và trả về body; status khác 2xx là lỗi (body không được
+// đưa vào lỗi vì có thể chứa bí mật)
+func doSecretRequest(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s: status %d", req.Method, req.URL.Redacted(), resp.StatusCode)
+	}
+	return body, nil
+}
+
+// secretStrings chuyển giá trị JSON của secret thành chuỗi; giá trị không phải chuỗi giữ dạng JSON
+func secretStrings(data map[string]json.RawMessage) map[string]string {
+	secrets := make(map[string]string, len(data))
+	for key, raw := range data {
+		var value string
+		if json.Unmarshal(raw, &value) != nil {
+			value = string(raw)
+		}
+		secrets[key] = value
+	}
+	return secrets
+}
@@ -0,0 +1,230 @@
+package database
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/events"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Các driver database được hỗ trợ (DB_DRIVER). Postgres là database chính; MySQL và SQLite dùng cho
+// phát triển cục bộ và kiểm thử, các tính năng riêng của Postgres (failover, bảng phân vùng, trigger
+// hóa đơn, hàng đợi job, báo cáo) không khả dụng trên chúng.
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+)
+
+// Connect mở kết nối theo cfg.Driver, áp dụng cấu hình pool và đăng ký read replica nếu được cấu hình.
+// Failover chỉ có với Postgres (nil với driver khác); interval là chu kỳ kiểm tra sức khỏe của failover.
+func Connect(cfg config.Database, bus *events.Bus, interval time.Duration, pool config.DBPool, gormConfig *gorm.Config) (*gorm.DB, *Failover, error) {
+	db, failover, err := connect(cfg, bus, interval, pool, gormConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := useReplicas(db, cfg, pool); err != nil {
+		return nil, nil, err
+	}
+	return db, failover, nil
+}
+
+// connect mở kết nối tới primary theo cfg.Driver
+func connect(cfg config.Database, bus *events.Bus, interval time.Duration, pool config.DBPool, gormConfig *gorm.Config) (*gorm.DB, *Failover, error) {
+	var dialector gorm.Dialector
+	switch cfg.Driver {
+	case DriverPostgres, "postgresql", "":
+		dsns, err := DSNs(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		addresses := make([]string, len(dsns))
+		for i, dsn := range dsns {
+			addresses[i] = Address(dsn)
+		}
+		log.Printf("Connecting to postgres at %s", strings.Join(addresses, ", "))
+		return Open(dsns, bus, interval, pool, gormConfig)
+
+	case DriverMySQL:
+		dsn, err := MySQLDSN(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Printf("Connecting to mysql at %s", dsn.Addr)
+		dialector = mysql.New(mysql.Config{DSNConfig: dsn})
+
+	case DriverSQLite:
+		path := SQLitePath(cfg)
+		log.Printf("Using sqlite database %s", strings.SplitN(path, "?", 2)[0])
+		dialector = sqlite.Open(path)
+		// SQLite chỉ cho một writer tại một thời điểm; với ":memory:" mỗi kết nối là một database riêng,
+		// nên giữ đúng một kết nối và không bao giờ đóng nó
+		pool = config.DBPool{MaxOpenConns: 1, MaxIdleConns: 1}
+
+	default:
+		return nil, nil, fmt.Errorf("unsupported DB_DRIVER %q (expected postgres, mysql or sqlite)", cfg.Driver)
+	}
+
+	db, err := gorm.Open(dialector, gormConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, nil, err
+	}
+	ConfigurePool(sqlDB, pool)
+	return db, nil, nil
+}
+
+// MySQLDSN dựng cấu hình kết nối MySQL từ DATABASE_URL (định dạng DSN của go-sql-driver,
+// ví dụ user:pass@tcp(host:3306)/dbname) hoặc từ các biến DB_*. DB_SSLMODE được ánh xạ sang tham số tls.
+func MySQLDSN(cfg config.Database) (*mysqldriver.Config, error) {
+	var dsn *mysqldriver.Config
+	if cfg.URL != "" {
+		parsed, err := mysqldriver.ParseDSN(cfg.URL)
+		if err != nil {
+			// Không đưa DSN vào lỗi vì có thể chứa mật khẩu
+			return nil, errors.New("invalid DATABASE_URL: expected user:password@tcp(host:port)/dbname")
+		}
+		dsn = parsed
+	} else {
+		if len(cfg.Hosts) == 0 {
+			return nil, errors.New("no database configured: set DATABASE_URL or DB_HOST")
+		}
+		host, port := cfg.Hosts[0], cfg.Port
+		if port == "" {
+			port = "3306"
+		}
+		if h, p, err := net.SplitHostPort(host); err == nil {
+			host, port = h, p
+		}
+		dsn = mysqldriver.NewConfig()
+		dsn.User, dsn.Passwd, dsn.DBName = cfg.User, cfg.Password, cfg.Name
+		dsn.Net, dsn.Addr = "tcp", net.JoinHostPort(host, port)
+	}
+	// Thời gian được đọc thành time.Time theo UTC và chuỗi lưu theo utf8mb4 như trên Postgres
+	dsn.ParseTime = true
+	dsn.Loc = time.UTC
+	if dsn.Collation == "" || !strings.HasPrefix(dsn.Collation, "utf8mb4") {
+		dsn.Collation = "utf8mb4_unicode_ci"
+	}
+
+	if dsn.TLSConfig == "" {
+		switch cfg.SSLMode {
+		case "disable":
+			dsn.TLSConfig = "false"
+		case "allow", "prefer":
+			dsn.TLSConfig = "preferred"
+		case "require":
+			dsn.TLSConfig = "skip-verify"
+		case "verify-ca", "verify-full":
+			host, _, _ := net.SplitHostPort(dsn.Addr)
+			tlsConfig := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+			if cfg.SSLRootCert != "" {
+				pem, err := os.ReadFile(cfg.SSLRootCert)
+				if err != nil {
+					return nil, fmt.Errorf("DB_SSLROOTCERT: %w", err)
+				}
+				tlsConfig.RootCAs = x509.NewCertPool()
+				if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+					return nil, errors.New("DB_SSLROOTCERT: no certificates found")
+				}
+			}
+			// DSN được truyền cho driver dạng chuỗi nên cấu hình TLS phải được đăng ký theo tên
+			if err := mysqldriver.RegisterTLSConfig("db", tlsConfig); err != nil {
+				return nil, err
+			}
+			dsn.TLSConfig = "db"
+		default:
+			return nil, fmt.Errorf("invalid DB_SSLMODE %q", cfg.SSLMode)
+		}
+	}
+	return dsn, nil
+}
+
+// SQLitePath trả về DSN của file SQLite: DATABASE_URL, hoặc DB_NAME (mặc định shop.db). Khóa ngoại
+// được bật và lệnh ghi chờ tối đa 5 giây khi database đang bị khóa.
+func SQLitePath(cfg config.Database) string {
+	path := cfg.URL
+	if path == "" {
+		path = cfg.Name
+	}
+	if path == "" {
+		path = "shop.db"
+	}
+	if strings.Contains(path, "?") {
+		return path
+	}
+	return path + "?_foreign_keys=on&_busy_timeout=5000"
+}
+
+// IsPostgres cho biết db đang dùng Postgres
+func IsPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == DriverPostgres
+}
+
+// ILike trả về điều kiện so khớp không phân biệt hoa thường một trong các cột với mẫu LIKE, mỗi cột
+// một tham số: ILIKE trên Postgres, LOWER(...) LIKE LOWER(?) trên các database khác
+func ILike(db *gorm.DB, columns ...string) string {
+	conditions := make([]string, len(columns))
+	for i, column := range columns {
+		if IsPostgres(db) {
+			conditions[i] = column + " ILIKE ?"
+		} else {
+			conditions[i] = "LOWER(" + column + ") LIKE LOWER(?)"
+		}
+	}
+	return strings.Join(conditions, " OR ")
+}
+
+// JSONContains trả về điều kiện cột JSON object chứa mọi cặp khóa/giá trị chuỗi trong values cùng tham số:
+// @> trên Postgres (dùng được index GIN), JSON_CONTAINS trên MySQL, json_extract theo từng khóa trên SQLite
+func JSONContains(db *gorm.DB, column string, values map[string]string) (string, []interface{}) {
+	switch db.Dialector.Name() {
+	case DriverPostgres:
+		doc, _ := json.Marshal(values)
+		return column + " @> CAST(? AS jsonb)", []interface{}{string(doc)}
+	case DriverMySQL:
+		doc, _ := json.Marshal(values)
+		return "JSON_CONTAINS(" + column + ", ?)", []interface{}{string(doc)}
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	conditions := make([]string, len(keys))
+	args := make([]interface{}, 0, 2*len(keys))
+	for i, key := range keys {
+		conditions[i] = "json_extract(" + column + ", ?) = ?"
+		args = append(args, `$."`+key+`"`, values[key])
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// JSONField trả về biểu thức lấy giá trị chuỗi của khóa key trong cột JSON object. key được ghép thẳng vào
+// SQL nên chỉ dùng với khóa cố định trong code; trên Postgres biểu thức khớp với index biểu thức cùng dạng.
+func JSONField(db *gorm.DB, column, key string) string {
+	switch db.Dialector.Name() {
+	case DriverPostgres:
+		return "(" + column + "->>'" + key + "')"
+	case DriverMySQL:
+		return "JSON_UNQUOTE(JSON_EXTRACT(" + column + `, '$."` + key + `"'))`
+	}
+	return "json_extract(" + column + `, '$."` + key + `"')`
+}
@@ -0,0 +1,259 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/events"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const probeTimeout = 3 * time.Second
+
+// NodeStatus là trạng thái của một node database sau lần kiểm tra gần nhất
+type NodeStatus struct {
+	Address       string    `json:"address"`
+	Active        bool      `json:"active"`
+	Healthy       bool      `json:"healthy"`
+	InRecovery    bool      `json:"in_recovery"`
+	LastCheckedAt time.Time `json:"last_checked_at"`
+	LastError     string    `json:"last_error,omitempty"`
+}
+
+// FailoverStats chứa thống kê failover
+type FailoverStats struct {
+	Nodes          []NodeStatus `json:"nodes"`
+	Failovers      int64        `json:"failovers"`
+	LastFailoverAt *time.Time   `json:"last_failover_at,omitempty"`
+}
+
+// Failover quản lý nhiều DSN primary: mọi kết nối mới đi tới node đang active,
+// và node active được đổi khi không kết nối được hoặc node đó trở thành standby.
+type Failover struct {
+	driver     driver.DriverContext
+	addresses  []string
+	connectors []driver.Connector
+	probes     []*sql.DB
+	interval   time.Duration
+	bus        *events.Bus
+	sqlDB      *sql.DB
+	pool       config.DBPool
+
+	mu             sync.RWMutex
+	active         int
+	nodes          []NodeStatus
+	failovers      int64
+	lastFailoverAt *time.Time
+}
+
+// Open mở kết nối GORM qua connector có failover với pool kết nối theo pool.
+// Với một DSN duy nhất hành vi giống gorm.Open.
+func Open(dsns []string, bus *events.Bus, interval time.Duration, pool config.DBPool, gormConfig *gorm.Config) (*gorm.DB, *Failover, error) {
+	if len(dsns) == 0 {
+		return nil, nil, errors.New("no database DSN configured")
+	}
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	// Driver "pgx" được đăng ký bởi gorm.io/driver/postgres
+	probe, err := sql.Open("pgx", dsns[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	drv, ok := probe.Driver().(driver.DriverContext)
+	probe.Close()
+	if !ok {
+		return nil, nil, errors.New("pgx driver does not support connectors")
+	}
+
+	f := &Failover{driver: drv, interval: interval, bus: bus, pool: pool}
+	for _, dsn := range dsns {
+		connector, err := drv.OpenConnector(dsn)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid DSN for %s: %w", Address(dsn), err)
+		}
+		probeDB := sql.OpenDB(connector)
+		probeDB.SetMaxOpenConns(1)
+		probeDB.SetConnMaxIdleTime(time.Minute)
+
+		f.addresses = append(f.addresses, Address(dsn))
+		f.connectors = append(f.connectors, connector)
+		f.probes = append(f.probes, probeDB)
+		f.nodes = append(f.nodes, NodeStatus{Address: Address(dsn)})
+	}
+
+	// Chọn primary ban đầu; nếu chưa node nào sẵn sàng thì giữ node đầu tiên
+	if len(dsns) > 1 {
+		ctx, cancel := context.WithTimeout(context.Background(), probeTimeout*time.Duration(len(dsns)))
+		if idx := f.findPrimary(ctx, -1); idx >= 0 {
+			f.active = idx
+		}
+		cancel()
+	}
+	f.nodes[f.active].Active = true
+
+	f.sqlDB = sql.OpenDB(&failoverConnector{f: f})
+	ConfigurePool(f.sqlDB, pool)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: f.sqlDB}), gormConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, f, nil
+}
+
+// Start chạy vòng kiểm tra sức khỏe định kỳ cho tới khi ctx bị hủy. An toàn khi f là nil
+// (database không phải Postgres).
+func (f *Failover) Start(ctx context.Context) {
+	if f == nil || len(f.connectors) < 2 {
+		return
+	}
+	ticker := time.NewTicker(f.interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				f.Check(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Check kiểm tra node active và chuyển sang primary khác nếu node đó lỗi hoặc đã thành standby
+func (f *Failover) Check(ctx context.Context) {
+	active := f.Active()
+	healthy, inRecovery := f.probe(ctx, active)
+	if healthy && !inRecovery {
+		return
+	}
+	reason := "primary unreachable"
+	if healthy {
+		reason = "primary is in recovery (demoted to standby)"
+	}
+	f.failover(ctx, active, reason)
+}
+
+// Active trả về chỉ số của node đang nhận kết nối
+func (f *Failover) Active() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.active
+}
+
+// GetStats trả về trạng thái các node và số lần failover; rỗng khi f là nil
+func (f *Failover) GetStats() FailoverStats {
+	if f == nil {
+		return FailoverStats{}
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return FailoverStats{
+		Nodes:          append([]NodeStatus(nil), f.nodes...),
+		Failovers:      f.failovers,
+		LastFailoverAt: f.lastFailoverAt,
+	}
+}
+
+// probe kiểm tra một node: có kết nối được không và có đang ở chế độ recovery (standby) không
+func (f *Failover) probe(ctx context.Context, idx int) (healthy, inRecovery bool) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	err := f.probes[idx].QueryRowContext(ctx, "SELECT pg_is_in_recovery()").Scan(&inRecovery)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	node := &f.nodes[idx]
+	node.LastCheckedAt = time.Now()
+	node.Healthy = err == nil
+	node.InRecovery = inRecovery
+	node.LastError = ""
+	if err != nil {
+		node.LastError = err.Error()
+	}
+	return err == nil, inRecovery
+}
+
+// findPrimary tìm node khả dụng và không ở chế độ recovery, bỏ qua node skip
+func (f *Failover) findPrimary(ctx context.Context, skip int) int {
+	for i := range f.connectors {
+		if i == skip {
+			continue
+		}
+		if healthy, inRecovery := f.probe(ctx, i); healthy && !inRecovery {
+			return i
+		}
+	}
+	return -1
+}
+
+// failover chuyển kết nối sang primary mới (nếu tìm được) và trả về node active sau cùng
+func (f *Failover) failover(ctx context.Context, from int, reason string) int {
+	next := f.findPrimary(ctx, from)
+	if next < 0 {
+		log.Printf("Database: %s (%s), no other primary available", f.addresses[from], reason)
+		return from
+	}
+
+	f.mu.Lock()
+	if f.active != from {
+		// Một goroutine khác đã failover trước
+		current := f.active
+		f.mu.Unlock()
+		return current
+	}
+	now := time.Now()
+	f.nodes[from].Active = false
+	f.nodes[next].Active = true
+	f.active = next
+	f.failovers++
+	f.lastFailoverAt = &now
+	f.mu.Unlock()
+
+	log.Printf("Database: failover from %s to %s: %s", f.addresses[from], f.addresses[next], reason)
+
+	// Đóng các kết nối rảnh tới primary cũ; kết nối đang dùng sẽ bị loại khi trả lỗi
+	if f.sqlDB != nil {
+		f.sqlDB.SetMaxIdleConns(0)
+		f.sqlDB.SetMaxIdleConns(f.pool.MaxIdleConns)
+	}
+
+	f.bus.Publish(ctx, events.DatabaseFailover{
+		From: f.addresses[from], To: f.addresses[next], Reason: reason, At: now,
+	})
+	return next
+}
+
+// failoverConnector mở kết nối tới node active, failover ngay nếu node đó không kết nối được
+type failoverConnector struct {
+	f *Failover
+}
+
+func (c *failoverConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	active := c.f.Active()
+	conn, err := c.f.connectors[active].Connect(ctx)
+	if err == nil || len(c.f.connectors) < 2 {
+		return conn, err
+	}
+
+	next := c.f.failover(ctx, active, "connect failed: "+err.Error())
+	if next == active {
+		return nil, err
+	}
+	return c.f.connectors[next].Connect(ctx)
+}
+
+func (c *failoverConnector) Driver() driver.Driver {
+	return c.f.driver.(driver.Driver)
+}
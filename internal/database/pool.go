@@ -0,0 +1,15 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+)
+
+// ConfigurePool áp dụng cấu hình pool kết nối cho db
+func ConfigurePool(db *sql.DB, pool config.DBPool) {
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+}
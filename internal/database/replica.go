@@ -0,0 +1,127 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// ReplicaResolver là tên resolver của dbresolver dành cho read replica. Chỉ các truy vấn được đánh dấu
+// bằng ReadReplica mới đọc từ replica; mọi truy vấn khác (kể cả đọc ngay sau khi ghi) vẫn đi tới primary.
+const ReplicaResolver = "replica"
+
+// replicasPlugin giữ pool kết nối của các replica để báo cáo trạng thái trong /readyz và /metrics
+type replicasPlugin struct {
+	addresses []string
+	pools     []*sql.DB
+}
+
+func (p *replicasPlugin) Name() string {
+	return "shop:replicas"
+}
+
+func (p *replicasPlugin) Initialize(*gorm.DB) error {
+	return nil
+}
+
+// Replica là pool kết nối tới một read replica
+type Replica struct {
+	Address string
+	DB      *sql.DB
+}
+
+// Replicas trả về các read replica đã đăng ký trên db (rỗng nếu không cấu hình replica)
+func Replicas(db *gorm.DB) []Replica {
+	plugin, ok := db.Config.Plugins[(&replicasPlugin{}).Name()].(*replicasPlugin)
+	if !ok {
+		return nil
+	}
+	replicas := make([]Replica, len(plugin.pools))
+	for i, pool := range plugin.pools {
+		replicas[i] = Replica{Address: plugin.addresses[i], DB: pool}
+	}
+	return replicas
+}
+
+// ReadReplica đánh dấu truy vấn đọc nặng (danh sách, tìm kiếm, báo cáo) để chạy trên read replica.
+// Không có tác dụng khi không cấu hình replica hoặc khi db đang trong transaction.
+func ReadReplica(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Use(ReplicaResolver), dbresolver.Read)
+}
+
+// useReplicas mở pool kết nối tới các read replica theo cfg.Replica() và đăng ký chúng với dbresolver.
+// Mỗi replica có pool riêng theo cùng cấu hình pool với primary.
+func useReplicas(db *gorm.DB, cfg config.Database, pool config.DBPool) error {
+	replica := cfg.Replica()
+	if replica == nil {
+		return nil
+	}
+
+	plugin := &replicasPlugin{}
+	var dialectors []gorm.Dialector
+	switch db.Dialector.Name() {
+	case DriverPostgres:
+		dsns, err := DSNs(*replica)
+		if err != nil {
+			return fmt.Errorf("read replica: %w", err)
+		}
+		for _, dsn := range dsns {
+			// Driver "pgx" được đăng ký bởi gorm.io/driver/postgres
+			sqlDB, err := sql.Open("pgx", dsn)
+			if err != nil {
+				return fmt.Errorf("read replica %s: %w", Address(dsn), err)
+			}
+			plugin.addresses = append(plugin.addresses, Address(dsn))
+			plugin.pools = append(plugin.pools, sqlDB)
+			dialectors = append(dialectors, postgres.New(postgres.Config{Conn: sqlDB}))
+		}
+
+	case DriverMySQL:
+		hosts := replica.Hosts
+		if replica.URL != "" {
+			hosts = []string{""}
+		}
+		for _, host := range hosts {
+			one := *replica
+			if host != "" {
+				one.Hosts = []string{host}
+			}
+			dsn, err := MySQLDSN(one)
+			if err != nil {
+				return fmt.Errorf("read replica: %w", err)
+			}
+			sqlDB, err := sql.Open("mysql", dsn.FormatDSN())
+			if err != nil {
+				return fmt.Errorf("read replica %s: %w", dsn.Addr, err)
+			}
+			plugin.addresses = append(plugin.addresses, dsn.Addr)
+			plugin.pools = append(plugin.pools, sqlDB)
+			dialectors = append(dialectors, mysql.New(mysql.Config{Conn: sqlDB, DSNConfig: dsn}))
+		}
+
+	default:
+		log.Printf("Warning: read replicas are not supported with DB_DRIVER=%s, reading from the primary", db.Dialector.Name())
+		return nil
+	}
+
+	for _, sqlDB := range plugin.pools {
+		ConfigurePool(sqlDB, pool)
+	}
+	// Sources để trống: thao tác ghi qua resolver này vẫn dùng pool của primary (kể cả khi failover)
+	resolver := dbresolver.Register(dbresolver.Config{Replicas: dialectors}, ReplicaResolver)
+	if err := db.Use(resolver); err != nil {
+		return err
+	}
+	if err := db.Use(plugin); err != nil {
+		return err
+	}
+	log.Printf("Routing heavy reads to replicas at %s", strings.Join(plugin.addresses, ", "))
+	return nil
+}
@@ -0,0 +1,190 @@
+package database
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"gorm.io/gorm"
+)
+
+// slowQueryStartKey là khóa trong Statement lưu thời điểm bắt đầu của truy vấn
+const slowQueryStartKey = "shop:slow_query_start"
+
+// slowQueryRedacted thay cho tham số chuỗi trong câu truy vấn được ghi lại
+const slowQueryRedacted = "[REDACTED]"
+
+// SlowQuery là thống kê của một câu truy vấn chậm (theo câu SQL chưa gắn tham số)
+type SlowQuery struct {
+	SQL string `json:"sql"`
+	// Example là lần chậm nhất, đã gắn tham số (tham số chuỗi và nhị phân được che)
+	Example    string    `json:"example"`
+	Count      int64     `json:"count"`
+	MaxMS      float64   `json:"max_ms"`
+	TotalMS    float64   `json:"total_ms"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// SlowQueryLog là plugin GORM ghi log các truy vấn chạy lâu hơn Threshold kèm tham số (đã che các giá trị
+// chuỗi vì có thể chứa email, token, mật khẩu đã băm), đếm số truy vấn chậm cho /metrics và giữ Top câu
+// truy vấn chậm nhất cho GET /admin/ops/slow-queries
+type SlowQueryLog struct {
+	threshold time.Duration
+	top       int
+	total     atomic.Int64
+
+	mu      sync.Mutex
+	queries map[string]*SlowQuery
+}
+
+// UseSlowQueryLog đăng ký plugin ghi truy vấn chậm trên db theo cfg; không làm gì nếu ngưỡng là 0 (tắt)
+func UseSlowQueryLog(db *gorm.DB, cfg config.SlowQuery) error {
+	if cfg.Threshold <= 0 {
+		return nil
+	}
+	return db.Use(&SlowQueryLog{threshold: cfg.Threshold, top: cfg.Top, queries: make(map[string]*SlowQuery)})
+}
+
+func (p *SlowQueryLog) Name() string {
+	return "shop:slow_queries"
+}
+
+// Initialize gắn callback đo thời gian quanh mọi loại thao tác của GORM
+func (p *SlowQueryLog) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	for _, register := range []func() error{
+		func() error {
+			return callbacks.Create().Before("gorm:create").Register("shop:slow_query_start", p.start)
+		},
+		func() error { return callbacks.Create().After("gorm:create").Register("shop:slow_query_end", p.end) },
+		func() error { return callbacks.Query().Before("gorm:query").Register("shop:slow_query_start", p.start) },
+		func() error { return callbacks.Query().After("gorm:query").Register("shop:slow_query_end", p.end) },
+		func() error {
+			return callbacks.Update().Before("gorm:update").Register("shop:slow_query_start", p.start)
+		},
+		func() error { return callbacks.Update().After("gorm:update").Register("shop:slow_query_end", p.end) },
+		func() error {
+			return callbacks.Delete().Before("gorm:delete").Register("shop:slow_query_start", p.start)
+		},
+		func() error { return callbacks.Delete().After("gorm:delete").Register("shop:slow_query_end", p.end) },
+		func() error { return callbacks.Row().Before("gorm:row").Register("shop:slow_query_start", p.start) },
+		func() error { return callbacks.Row().After("gorm:row").Register("shop:slow_query_end", p.end) },
+		func() error { return callbacks.Raw().Before("gorm:raw").Register("shop:slow_query_start", p.start) },
+		func() error { return callbacks.Raw().After("gorm:raw").Register("shop:slow_query_end", p.end) },
+	} {
+		if err := register(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *SlowQueryLog) start(db *gorm.DB) {
+	db.InstanceSet(slowQueryStartKey, time.Now())
+}
+
+func (p *SlowQueryLog) end(db *gorm.DB) {
+	value, ok := db.InstanceGet(slowQueryStartKey)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(value.(time.Time))
+	if elapsed < p.threshold || db.Statement.SQL.Len() == 0 {
+		return
+	}
+	p.total.Add(1)
+
+	sql := db.Statement.SQL.String()
+	example := db.Dialector.Explain(sql, redactVars(db.Statement.Vars)...)
+	log.Printf("Slow query (%s, %d rows): %s", elapsed.Round(time.Millisecond), db.RowsAffected, example)
+	if p.top > 0 {
+		p.record(sql, example, elapsed)
+	}
+}
+
+// record cập nhật thống kê của câu truy vấn. Khi số câu vượt quá 2*top, các câu có thời gian tối đa thấp
+// nhất bị bỏ để bộ nhớ không tăng mãi.
+func (p *SlowQueryLog) record(sql, example string, elapsed time.Duration) {
+	ms := float64(elapsed.Microseconds()) / 1000
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	q, ok := p.queries[sql]
+	if !ok {
+		q = &SlowQuery{SQL: sql}
+		p.queries[sql] = q
+	}
+	q.Count++
+	q.TotalMS += ms
+	q.LastSeenAt = time.Now()
+	if ms > q.MaxMS {
+		q.MaxMS, q.Example = ms, example
+	}
+	if len(p.queries) > 2*p.top {
+		for _, slow := range p.worst()[p.top:] {
+			delete(p.queries, slow.SQL)
+		}
+	}
+}
+
+// worst trả về các câu truy vấn theo thời gian tối đa giảm dần; gọi khi đang giữ mu
+func (p *SlowQueryLog) worst() []SlowQuery {
+	queries := make([]SlowQuery, 0, len(p.queries))
+	for _, q := range p.queries {
+		queries = append(queries, *q)
+	}
+	sort.Slice(queries, func(i, j int) bool { return queries[i].MaxMS > queries[j].MaxMS })
+	return queries
+}
+
+// SlowQueryStats là thống kê truy vấn chậm cho GET /admin/ops/slow-queries
+type SlowQueryStats struct {
+	Enabled     bool        `json:"enabled"`
+	ThresholdMS int64       `json:"threshold_ms"`
+	Total       int64       `json:"total"`
+	Queries     []SlowQuery `json:"queries"`
+}
+
+// Total là số truy vấn chậm từ khi tiến trình khởi động (0 nếu tắt)
+func (p *SlowQueryLog) Total() int64 {
+	if p == nil {
+		return 0
+	}
+	return p.total.Load()
+}
+
+// GetStats trả về ngưỡng, tổng số truy vấn chậm và Top câu truy vấn chậm nhất theo thời gian tối đa
+func (p *SlowQueryLog) GetStats() SlowQueryStats {
+	if p == nil {
+		return SlowQueryStats{Queries: []SlowQuery{}}
+	}
+	p.mu.Lock()
+	worst := p.worst()
+	p.mu.Unlock()
+	if len(worst) > p.top {
+		worst = worst[:p.top]
+	}
+	return SlowQueryStats{Enabled: true, ThresholdMS: p.threshold.Milliseconds(), Total: p.Total(), Queries: worst}
+}
+
+// SlowQueries trả về plugin ghi truy vấn chậm đã đăng ký trên db (nil nếu tắt)
+func SlowQueries(db *gorm.DB) *SlowQueryLog {
+	plugin, _ := db.Config.Plugins[(&SlowQueryLog{}).Name()].(*SlowQueryLog)
+	return plugin
+}
+
+// redactVars che tham số chuỗi và nhị phân; số, bool, thời gian và NULL được giữ để đọc được điều kiện
+func redactVars(vars []interface{}) []interface{} {
+	redacted := make([]interface{}, len(vars))
+	for i, v := range vars {
+		switch v.(type) {
+		case string, []byte, *string:
+			redacted[i] = slowQueryRedacted
+		default:
+			redacted[i] = v
+		}
+	}
+	return redacted
+}
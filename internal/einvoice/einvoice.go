@@ -0,0 +1,184 @@
+package einvoice
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+)
+
+// Location là múi giờ Việt Nam (UTC+7, không có giờ mùa hè), dùng để xác định ngày lập và kỳ hóa đơn
+var Location = time.FixedZone("ICT", 7*60*60)
+
+// Seller là thông tin người bán in trên hóa đơn
+type Seller struct {
+	Name    string `json:"name"`
+	TaxCode string `json:"tax_code"`
+	Address string `json:"address"`
+}
+
+// Config là cấu hình phát hành hóa đơn
+type Config struct {
+	Seller Seller
+	// Symbol là phần ký hiệu do doanh nghiệp tự đặt (2 chữ cái cuối của ký hiệu, mặc định "AA")
+	Symbol string
+	// TaxRate là thuế suất GTGT mặc định (phần trăm)
+	TaxRate float64
+}
+
+// ConfigFromEnv đọc cấu hình từ INVOICE_SELLER_NAME, INVOICE_SELLER_TAX_CODE,
+// INVOICE_SELLER_ADDRESS, INVOICE_SYMBOL và INVOICE_TAX_RATE
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Seller: Seller{
+			Name:    os.Getenv("INVOICE_SELLER_NAME"),
+			TaxCode: os.Getenv("INVOICE_SELLER_TAX_CODE"),
+			Address: os.Getenv("INVOICE_SELLER_ADDRESS"),
+		},
+		Symbol:  strings.ToUpper(os.Getenv("INVOICE_SYMBOL")),
+		TaxRate: 10,
+	}
+	if len(cfg.Symbol) != 2 {
+		cfg.Symbol = "AA"
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("INVOICE_TAX_RATE"), 64); err == nil && v >= 0 {
+		cfg.TaxRate = v
+	}
+	return cfg
+}
+
+// Series dựng ký hiệu hóa đơn theo Thông tư 78/2021/TT-BTC cho năm phát hành:
+// 1 (hóa đơn GTGT) + C (có mã của cơ quan thuế) + 2 số cuối của năm + T (doanh nghiệp đăng ký) + symbol
+func (c Config) Series(year int) string {
+	return fmt.Sprintf("1C%02dT%s", year%100, c.Symbol)
+}
+
+// FormatNumber định dạng số hóa đơn thành 8 chữ số
+func FormatNumber(seq int) string {
+	return fmt.Sprintf("%08d", seq)
+}
+
+// RoundVND làm tròn số tiền tới đồng
+func RoundVND(amount float64) float64 {
+	return math.Round(amount)
+}
+
+// Totals tính thành tiền và tiền thuế từng dòng (theo TaxRate của dòng), tổng tiền hàng, tiền thuế
+// và tổng thanh toán của hóa đơn. Tiền thuế của hóa đơn được tính trên tổng tiền hàng của từng
+// thuế suất như trên bảng tổng hợp thuế suất. TaxRate của hóa đơn là thuế suất chung của các dòng,
+// hoặc 0 nếu các dòng có thuế suất khác nhau.
+func Totals(invoice *models.Invoice) {
+	subtotal := 0.0
+	for i := range invoice.Items {
+		item := &invoice.Items[i]
+		item.Amount = RoundVND(item.UnitPrice * float64(item.Quantity))
+		item.TaxAmount = RoundVND(item.Amount * item.TaxRate / 100)
+		subtotal += item.Amount
+	}
+	invoice.Subtotal = subtotal
+
+	invoice.TaxRate = 0
+	breakdown := TaxBreakdown(invoice)
+	if len(breakdown) == 1 {
+		invoice.TaxRate = breakdown[0].Rate
+	}
+	invoice.TaxAmount = 0
+	for _, group := range breakdown {
+		invoice.TaxAmount += group.TaxAmount
+	}
+	invoice.Total = invoice.Subtotal + invoice.TaxAmount
+}
+
+// TaxGroup là tổng tiền hàng và tiền thuế của một thuế suất trên hóa đơn
+type TaxGroup struct {
+	Rate      float64 `json:"rate"`
+	Taxable   float64 `json:"taxable"`
+	TaxAmount float64 `json:"tax_amount"`
+}
+
+// LineRate trả về thuế suất của một dòng; dòng của hóa đơn lập trước khi có thuế theo dòng
+// dùng thuế suất của hóa đơn
+func LineRate(invoice *models.Invoice, item *models.InvoiceItem) float64 {
+	if item.TaxRate == 0 && item.TaxAmount == 0 {
+		return invoice.TaxRate
+	}
+	return item.TaxRate
+}
+
+// TaxBreakdown tổng hợp tiền hàng và tiền thuế theo thuế suất, thuế suất thấp trước
+func TaxBreakdown(invoice *models.Invoice) []TaxGroup {
+	var groups []TaxGroup
+	index := make(map[float64]int)
+	for i := range invoice.Items {
+		rate := LineRate(invoice, &invoice.Items[i])
+		j, ok := index[rate]
+		if !ok {
+			j = len(groups)
+			index[rate] = j
+			groups = append(groups, TaxGroup{Rate: rate})
+		}
+		groups[j].Taxable += invoice.Items[i].Amount
+	}
+	for i := range groups {
+		groups[i].TaxAmount = RoundVND(groups[i].Taxable * groups[i].Rate / 100)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Rate < groups[j].Rate })
+	return groups
+}
+
+// hashContent là phần nội dung hóa đơn được băm; thứ tự trường cố định để mã băm ổn định
+type hashContent struct {
+	Series       string               `json:"series"`
+	Number       string               `json:"number"`
+	IssuedAt     string               `json:"issued_at"`
+	BuyerName    string               `json:"buyer_name"`
+	BuyerTaxCode string               `json:"buyer_tax_code"`
+	BuyerAddress string               `json:"buyer_address"`
+	Currency     string               `json:"currency"`
+	Items        []models.InvoiceItem `json:"items"`
+	Subtotal     float64              `json:"subtotal"`
+	TaxRate      float64              `json:"tax_rate"`
+	TaxAmount    float64              `json:"tax_amount"`
+	Total        float64              `json:"total"`
+}
+
+// ContentHash tính SHA-256 của nội dung hóa đơn đã phát hành. Mã băm được lưu khi phát hành
+// và in trong file xuất để đối chiếu rằng hóa đơn không bị thay đổi về sau.
+func ContentHash(invoice *models.Invoice) string {
+	content := hashContent{
+		Series:       invoice.Series,
+		Number:       invoice.Number,
+		BuyerName:    invoice.BuyerName,
+		BuyerTaxCode: invoice.BuyerTaxCode,
+		BuyerAddress: invoice.BuyerAddress,
+		Currency:     invoice.Currency,
+		Subtotal:     invoice.Subtotal,
+		TaxRate:      invoice.TaxRate,
+		TaxAmount:    invoice.TaxAmount,
+		Total:        invoice.Total,
+	}
+	if invoice.IssuedAt != nil {
+		content.IssuedAt = invoice.IssuedAt.UTC().Format(time.RFC3339)
+	}
+	for _, item := range invoice.Items {
+		content.Items = append(content.Items, models.InvoiceItem{
+			ProductID: item.ProductID,
+			Name:      item.Name,
+			Unit:      item.Unit,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			Amount:    item.Amount,
+		})
+	}
+	data, _ := json.Marshal(content)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
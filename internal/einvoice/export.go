@@ -0,0 +1,261 @@
+package einvoice
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+)
+
+// Các định dạng xuất hóa đơn điện tử
+const (
+	FormatXML  = "xml"
+	FormatJSON = "json"
+)
+
+// ErrNotIssued được trả về khi xuất hóa đơn chưa được phát hành
+var ErrNotIssued = errors.New("only issued or cancelled invoices can be exported")
+
+// xmlInvoice là hóa đơn theo cấu trúc dữ liệu XML của Tổng cục Thuế (Quyết định 1450/QĐ-TCT).
+// Chữ ký số và mã của cơ quan thuế được bổ sung bởi nhà cung cấp dịch vụ hóa đơn điện tử.
+type xmlInvoice struct {
+	XMLName xml.Name  `xml:"HDon"`
+	Data    xmlDLHDon `xml:"DLHDon"`
+}
+
+type xmlDLHDon struct {
+	ID      string     `xml:"Id,attr"`
+	General xmlTTChung `xml:"TTChung"`
+	Content xmlNDHDon  `xml:"NDHDon"`
+	Extra   []xmlTTin  `xml:"TTKhac>TTin,omitempty"`
+}
+
+type xmlTTChung struct {
+	Version      string `xml:"PBan"`
+	Title        string `xml:"THDon"`
+	TemplateCode string `xml:"KHMSHDon"`
+	Symbol       string `xml:"KHHDon"`
+	Number       string `xml:"SHDon"`
+	IssuedDate   string `xml:"NLap"`
+	Currency     string `xml:"DVTTe"`
+	ExchangeRate string `xml:"TGia"`
+}
+
+type xmlNDHDon struct {
+	Seller xmlParty  `xml:"NBan"`
+	Buyer  xmlParty  `xml:"NMua"`
+	Items  []xmlItem `xml:"DSHHDVu>HHDVu"`
+	Totals xmlTToan  `xml:"TToan"`
+}
+
+type xmlParty struct {
+	Name    string `xml:"Ten"`
+	TaxCode string `xml:"MST,omitempty"`
+	Address string `xml:"DChi,omitempty"`
+	Email   string `xml:"DCTDTu,omitempty"`
+}
+
+type xmlItem struct {
+	Kind      int    `xml:"TChat"` // 1: hàng hóa, dịch vụ
+	Line      int    `xml:"STT"`
+	Code      string `xml:"MHHDVu"`
+	Name      string `xml:"THHDVu"`
+	Unit      string `xml:"DVTinh,omitempty"`
+	Quantity  int    `xml:"SLuong"`
+	UnitPrice string `xml:"DGia"`
+	Amount    string `xml:"ThTien"`
+	TaxRate   string `xml:"TSuat"`
+}
+
+type xmlTToan struct {
+	Rates     []xmlLTSuat `xml:"THTTLTSuat>LTSuat"`
+	Subtotal  string      `xml:"TgTCThue"`
+	TaxAmount string      `xml:"TgTThue"`
+	Total     string      `xml:"TgTTTBSo"`
+}
+
+// xmlLTSuat là một dòng của bảng tổng hợp theo thuế suất
+type xmlLTSuat struct {
+	TaxRate   string `xml:"TSuat"`
+	Taxable   string `xml:"ThTien"`
+	TaxAmount string `xml:"TThue"`
+}
+
+type xmlTTin struct {
+	Field string `xml:"TTruong"`
+	Type  string `xml:"KDLieu"`
+	Value string `xml:"DLieu"`
+}
+
+// Document là hóa đơn điện tử dạng JSON, cùng nội dung với bản XML
+type Document struct {
+	TemplateCode string         `json:"template_code"`
+	Symbol       string         `json:"symbol"`
+	Number       string         `json:"number"`
+	IssuedDate   string         `json:"issued_date"`
+	Status       string         `json:"status"`
+	Currency     string         `json:"currency"`
+	Seller       Seller         `json:"seller"`
+	Buyer        DocumentBuyer  `json:"buyer"`
+	Items        []DocumentItem `json:"items"`
+	Subtotal     float64        `json:"subtotal"`
+	TaxRate      float64        `json:"tax_rate"`
+	TaxAmount    float64        `json:"tax_amount"`
+	Taxes        []TaxGroup     `json:"taxes"`
+	Total        float64        `json:"total"`
+	ContentHash  string         `json:"content_hash"`
+	CancelReason string         `json:"cancel_reason,omitempty"`
+}
+
+// DocumentBuyer là thông tin người mua trong Document
+type DocumentBuyer struct {
+	Name    string `json:"name"`
+	TaxCode string `json:"tax_code,omitempty"`
+	Address string `json:"address,omitempty"`
+	Email   string `json:"email,omitempty"`
+}
+
+// DocumentItem là một dòng hàng trong Document
+type DocumentItem struct {
+	Line      int     `json:"line"`
+	ProductID uint    `json:"product_id"`
+	Name      string  `json:"name"`
+	Unit      string  `json:"unit,omitempty"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	Amount    float64 `json:"amount"`
+	TaxRate   float64 `json:"tax_rate"`
+}
+
+// NewDocument dựng Document từ hóa đơn đã phát hành
+func NewDocument(invoice *models.Invoice, seller Seller) (*Document, error) {
+	if invoice.Status == models.InvoiceDraft || invoice.IssuedAt == nil {
+		return nil, ErrNotIssued
+	}
+	template, symbol := splitSeries(invoice.Series)
+	doc := &Document{
+		TemplateCode: template,
+		Symbol:       symbol,
+		Number:       invoice.Number,
+		IssuedDate:   invoice.IssuedAt.In(Location).Format("2006-01-02"),
+		Status:       invoice.Status,
+		Currency:     invoice.Currency,
+		Seller:       seller,
+		Buyer: DocumentBuyer{
+			Name:    invoice.BuyerName,
+			TaxCode: invoice.BuyerTaxCode,
+			Address: invoice.BuyerAddress,
+			Email:   invoice.BuyerEmail,
+		},
+		Subtotal:     invoice.Subtotal,
+		TaxRate:      invoice.TaxRate,
+		TaxAmount:    invoice.TaxAmount,
+		Taxes:        TaxBreakdown(invoice),
+		Total:        invoice.Total,
+		ContentHash:  invoice.ContentHash,
+		CancelReason: invoice.CancelReason,
+	}
+	for i, item := range invoice.Items {
+		doc.Items = append(doc.Items, DocumentItem{
+			Line:      i + 1,
+			ProductID: item.ProductID,
+			Name:      item.Name,
+			Unit:      item.Unit,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			Amount:    item.Amount,
+			TaxRate:   LineRate(invoice, &invoice.Items[i]),
+		})
+	}
+	return doc, nil
+}
+
+// Export xuất hóa đơn theo định dạng (xml hoặc json); trả về nội dung và content type
+func Export(invoice *models.Invoice, seller Seller, format string) ([]byte, string, error) {
+	doc, err := NewDocument(invoice, seller)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(doc, "", "  ")
+		return data, "application/json", err
+	case FormatXML:
+		data, err := xml.MarshalIndent(toXML(doc), "", "  ")
+		if err != nil {
+			return nil, "", err
+		}
+		return append([]byte(xml.Header), data...), "application/xml", nil
+	}
+	return nil, "", fmt.Errorf("unsupported export format %q", format)
+}
+
+func toXML(doc *Document) xmlInvoice {
+	out := xmlInvoice{Data: xmlDLHDon{
+		ID: "HD" + doc.Symbol + doc.Number,
+		General: xmlTTChung{
+			Version:      "2.0.0",
+			Title:        "Hóa đơn giá trị gia tăng",
+			TemplateCode: doc.TemplateCode,
+			Symbol:       doc.Symbol,
+			Number:       doc.Number,
+			IssuedDate:   doc.IssuedDate,
+			Currency:     doc.Currency,
+			ExchangeRate: "1",
+		},
+		Content: xmlNDHDon{
+			Seller: xmlParty{Name: doc.Seller.Name, TaxCode: doc.Seller.TaxCode, Address: doc.Seller.Address},
+			Buyer:  xmlParty{Name: doc.Buyer.Name, TaxCode: doc.Buyer.TaxCode, Address: doc.Buyer.Address, Email: doc.Buyer.Email},
+			Totals: xmlTToan{
+				Subtotal:  formatAmount(doc.Subtotal),
+				TaxAmount: formatAmount(doc.TaxAmount),
+				Total:     formatAmount(doc.Total),
+			},
+		},
+		Extra: []xmlTTin{{Field: "ContentHash", Type: "string", Value: doc.ContentHash}},
+	}}
+	for _, item := range doc.Items {
+		out.Data.Content.Items = append(out.Data.Content.Items, xmlItem{
+			Kind:      1,
+			Line:      item.Line,
+			Code:      strconv.FormatUint(uint64(item.ProductID), 10),
+			Name:      item.Name,
+			Unit:      item.Unit,
+			Quantity:  item.Quantity,
+			UnitPrice: formatAmount(item.UnitPrice),
+			Amount:    formatAmount(item.Amount),
+			TaxRate:   formatRate(item.TaxRate),
+		})
+	}
+	for _, group := range doc.Taxes {
+		out.Data.Content.Totals.Rates = append(out.Data.Content.Totals.Rates, xmlLTSuat{
+			TaxRate:   formatRate(group.Rate),
+			Taxable:   formatAmount(group.Taxable),
+			TaxAmount: formatAmount(group.TaxAmount),
+		})
+	}
+	if doc.Status == models.InvoiceCancelled {
+		out.Data.Extra = append(out.Data.Extra, xmlTTin{Field: "CancelReason", Type: "string", Value: doc.CancelReason})
+	}
+	return out
+}
+
+// splitSeries tách ký hiệu mẫu số (ký tự đầu) và ký hiệu hóa đơn (6 ký tự sau)
+func splitSeries(series string) (string, string) {
+	if len(series) < 2 {
+		return series, ""
+	}
+	return series[:1], series[1:]
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatRate(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64) + "%"
+}
@@ -0,0 +1,389 @@
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+)
+
+// Event là một sự kiện domain được phát ra sau khi thao tác nghiệp vụ thành công
+type Event interface {
+	EventName() string
+}
+
+// Tên các sự kiện domain
+const (
+	ProductCreatedEvent = "product.created"
+	ProductUpdatedEvent = "product.updated"
+	ProductDeletedEvent = "product.deleted"
+	StockChangedEvent   = "product.stock_changed"
+	PriceChangedEvent   = "product.price_changed"
+	UserRegisteredEvent = "user.registered"
+	DBFailoverEvent     = "database.failover"
+	LoginFailedEvent    = "auth.login_failed"
+
+	UserLoggedInEvent         = "auth.login"
+	SuspiciousLoginEvent      = "auth.suspicious_login"
+	LoggedOutEverywhereEvent  = "auth.logout_all"
+	PasswordChangedEvent      = "user.password_changed"
+	AccountDeletedEvent       = "user.deleted"
+	AccountRestoredEvent      = "user.restored"
+	EmailChangeRequestedEvent = "user.email_change_requested"
+	EmailChangedEvent         = "user.email_changed"
+	UserInvitedEvent          = "user.invited"
+	InvitationAcceptedEvent   = "user.invitation_accepted"
+	PaymentMethodAddedEvent   = "payment_method.added"
+	PaymentMethodRemovedEvent = "payment_method.removed"
+
+	OrderPlacedEvent         = "order.placed"
+	OrderStatusChangedEvent  = "order.status_changed"
+	ReturnStatusChangedEvent = "return.status_changed"
+
+	CategoryChangedEvent = "category.changed"
+
+	ProductImageReplacedEvent = "product.image_replaced"
+
+	ProductRevisionApprovedEvent = "product.revision_approved"
+	ProductRevisionRejectedEvent = "product.revision_rejected"
+
+	SupportTicketCreatedEvent       = "support.ticket_created"
+	SupportTicketRepliedEvent       = "support.ticket_replied"
+	SupportTicketStatusChangedEvent = "support.ticket_status_changed"
+)
+
+// ProductCreated được phát sau khi tạo sản phẩm
+type ProductCreated struct {
+	Product models.Product
+}
+
+func (ProductCreated) EventName() string { return ProductCreatedEvent }
+
+// ProductUpdated được phát sau khi cập nhật sản phẩm
+type ProductUpdated struct {
+	Product models.Product
+}
+
+func (ProductUpdated) EventName() string { return ProductUpdatedEvent }
+
+// ProductDeleted được phát sau khi xóa sản phẩm
+type ProductDeleted struct {
+	ProductID uint
+}
+
+func (ProductDeleted) EventName() string { return ProductDeletedEvent }
+
+// StockChanged được phát khi tồn kho của sản phẩm thay đổi
+type StockChanged struct {
+	ProductID uint
+	Name      string
+	OldStock  int
+	NewStock  int
+	ChangedAt time.Time
+}
+
+func (StockChanged) EventName() string { return StockChangedEvent }
+
+// PriceChanged được phát khi giá của sản phẩm thay đổi
+type PriceChanged struct {
+	ProductID uint
+	Name      string
+	OldPrice  float64
+	NewPrice  float64
+	ChangedAt time.Time
+}
+
+func (PriceChanged) EventName() string { return PriceChangedEvent }
+
+// ProductImageReplaced được phát sau khi ảnh của sản phẩm được thay bằng ảnh khác hoặc bị gỡ
+type ProductImageReplaced struct {
+	ProductID uint
+	OldURL    string
+	NewURL    string
+	At        time.Time
+}
+
+func (ProductImageReplaced) EventName() string { return ProductImageReplacedEvent }
+
+// ProductRevisionApproved được phát sau khi admin duyệt revision và thay đổi đã được ghi vào sản phẩm;
+// Product là sản phẩm sau khi áp dụng
+type ProductRevisionApproved struct {
+	Revision models.ProductRevision
+	Product  models.Product
+	At       time.Time
+}
+
+func (ProductRevisionApproved) EventName() string { return ProductRevisionApprovedEvent }
+
+// ProductRevisionRejected được phát sau khi admin từ chối revision
+type ProductRevisionRejected struct {
+	Revision models.ProductRevision
+	At       time.Time
+}
+
+func (ProductRevisionRejected) EventName() string { return ProductRevisionRejectedEvent }
+
+// UserRegistered được phát sau khi user đăng ký thành công
+type UserRegistered struct {
+	User models.User
+}
+
+func (UserRegistered) EventName() string { return UserRegisteredEvent }
+
+// LoginFailed được phát khi đăng nhập thất bại
+type LoginFailed struct {
+	Username string
+	IP       string
+	At       time.Time
+}
+
+func (LoginFailed) EventName() string { return LoginFailedEvent }
+
+// UserLoggedIn được phát sau khi đăng nhập thành công
+type UserLoggedIn struct {
+	UserID    uint
+	IP        string
+	UserAgent string
+	At        time.Time
+}
+
+func (UserLoggedIn) EventName() string { return UserLoggedInEvent }
+
+// SuspiciousLogin được phát khi user nhập đúng mật khẩu từ thiết bị hoặc quốc gia chưa từng đăng nhập;
+// Reasons là các models.LoginReason*. ConfirmationRequired cho biết token chỉ được phát sau khi user xác
+// nhận bằng link gửi qua email.
+type SuspiciousLogin struct {
+	User                 models.User
+	IP                   string
+	UserAgent            string
+	Country              string
+	Reasons              []string
+	ConfirmationRequired bool
+	At                   time.Time
+}
+
+func (SuspiciousLogin) EventName() string { return SuspiciousLoginEvent }
+
+// LoggedOutEverywhere được phát khi user thu hồi mọi phiên đăng nhập của mình
+type LoggedOutEverywhere struct {
+	UserID uint
+	IP     string
+	At     time.Time
+}
+
+func (LoggedOutEverywhere) EventName() string { return LoggedOutEverywhereEvent }
+
+// PasswordChanged được phát sau khi user đổi mật khẩu
+type PasswordChanged struct {
+	UserID uint
+	IP     string
+	At     time.Time
+}
+
+func (PasswordChanged) EventName() string { return PasswordChangedEvent }
+
+// AccountDeleted được phát khi user tự xóa tài khoản (xóa mềm)
+type AccountDeleted struct {
+	UserID uint
+	IP     string
+	At     time.Time
+}
+
+func (AccountDeleted) EventName() string { return AccountDeletedEvent }
+
+// AccountRestored được phát khi admin khôi phục tài khoản đã xóa
+type AccountRestored struct {
+	UserID  uint
+	AdminID uint
+	IP      string
+	At      time.Time
+}
+
+func (AccountRestored) EventName() string { return AccountRestoredEvent }
+
+// EmailChangeRequested được phát khi user yêu cầu đổi email và link xác nhận đã được gửi
+type EmailChangeRequested struct {
+	UserID   uint
+	ChangeID uint
+	IP       string
+	At       time.Time
+}
+
+func (EmailChangeRequested) EventName() string { return EmailChangeRequestedEvent }
+
+// EmailChanged được phát khi cả hai địa chỉ đã xác nhận và email của user đã được đổi
+type EmailChanged struct {
+	UserID   uint
+	ChangeID uint
+	IP       string
+	At       time.Time
+}
+
+func (EmailChanged) EventName() string { return EmailChangedEvent }
+
+// UserInvited được phát khi admin tạo tài khoản invited (nhập từ CSV) hoặc gửi lại lời mời
+type UserInvited struct {
+	UserID  uint
+	AdminID uint
+	IP      string
+	At      time.Time
+}
+
+func (UserInvited) EventName() string { return UserInvitedEvent }
+
+// InvitationAccepted được phát khi user được mời đặt mật khẩu và tài khoản chuyển sang active
+type InvitationAccepted struct {
+	UserID uint
+	IP     string
+	At     time.Time
+}
+
+func (InvitationAccepted) EventName() string { return InvitationAcceptedEvent }
+
+// PaymentMethodAdded được phát sau khi user lưu phương thức thanh toán
+type PaymentMethodAdded struct {
+	UserID   uint
+	MethodID uint
+	IP       string
+	At       time.Time
+}
+
+func (PaymentMethodAdded) EventName() string { return PaymentMethodAddedEvent }
+
+// PaymentMethodRemoved được phát sau khi user xóa phương thức thanh toán
+type PaymentMethodRemoved struct {
+	UserID   uint
+	MethodID uint
+	IP       string
+	At       time.Time
+}
+
+func (PaymentMethodRemoved) EventName() string { return PaymentMethodRemovedEvent }
+
+// OrderPlaced được phát sau khi khách đặt hàng thành công
+type OrderPlaced struct {
+	Order models.Order
+}
+
+func (OrderPlaced) EventName() string { return OrderPlacedEvent }
+
+// OrderStatusChanged được phát sau khi đơn hàng chuyển trạng thái
+type OrderStatusChanged struct {
+	Order     models.Order
+	From      string
+	To        string
+	ChangedAt time.Time
+}
+
+func (OrderStatusChanged) EventName() string { return OrderStatusChangedEvent }
+
+// ReturnStatusChanged được phát khi yêu cầu trả hàng được tạo (From rỗng) hoặc đổi trạng thái
+type ReturnStatusChanged struct {
+	Return    models.OrderReturn
+	From      string
+	To        string
+	ChangedAt time.Time
+}
+
+func (ReturnStatusChanged) EventName() string { return ReturnStatusChangedEvent }
+
+// SupportTicketCreated được phát sau khi khách gửi phiếu hỗ trợ; Ticket kèm tin nhắn đầu tiên
+type SupportTicketCreated struct {
+	Ticket models.SupportTicket
+}
+
+func (SupportTicketCreated) EventName() string { return SupportTicketCreatedEvent }
+
+// SupportTicketReplied được phát sau khi khách hoặc admin (Message.Staff) thêm tin nhắn vào phiếu; Ticket
+// là phiếu sau khi đổi trạng thái theo tin nhắn
+type SupportTicketReplied struct {
+	Ticket  models.SupportTicket
+	Message models.SupportTicketMessage
+}
+
+func (SupportTicketReplied) EventName() string { return SupportTicketRepliedEvent }
+
+// SupportTicketStatusChanged được phát khi phiếu được đánh dấu resolved, đóng hoặc mở lại (không gồm đổi
+// trạng thái do trả lời)
+type SupportTicketStatusChanged struct {
+	Ticket    models.SupportTicket
+	From      string
+	To        string
+	ByStaff   bool
+	ChangedAt time.Time
+}
+
+func (SupportTicketStatusChanged) EventName() string { return SupportTicketStatusChangedEvent }
+
+// CategoryChanged được phát sau khi admin tạo, sửa hoặc xóa danh mục
+type CategoryChanged struct {
+	CategoryID uint
+}
+
+func (CategoryChanged) EventName() string { return CategoryChangedEvent }
+
+// DatabaseFailover được phát khi lớp database chuyển sang primary khác
+type DatabaseFailover struct {
+	From   string // host:port của primary cũ
+	To     string // host:port của primary mới
+	Reason string
+	At     time.Time
+}
+
+func (DatabaseFailover) EventName() string { return DBFailoverEvent }
+
+// Handler xử lý một sự kiện
+type Handler func(ctx context.Context, event Event)
+
+// Bus là event bus trong tiến trình: các subscriber đăng ký theo tên sự kiện và
+// được gọi tuần tự, đồng bộ khi sự kiện được phát
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// NewBus tạo event bus mới
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe đăng ký handler cho một sự kiện theo tên
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+// On đăng ký handler có kiểu cho sự kiện T
+func On[T Event](b *Bus, handler func(ctx context.Context, event T)) {
+	var zero T
+	b.Subscribe(zero.EventName(), func(ctx context.Context, event Event) {
+		if e, ok := event.(T); ok {
+			handler(ctx, e)
+		}
+	})
+}
+
+// Publish phát sự kiện tới tất cả subscriber. Lỗi (panic) của một subscriber được
+// ghi log và không ảnh hưởng tới các subscriber khác hay thao tác đã thực hiện.
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	handlers := append([]Handler(nil), b.handlers[event.EventName()]...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("Events: subscriber for %s panicked: %v", event.EventName(), r)
+				}
+			}()
+			handler(ctx, event)
+		}()
+	}
+}
@@ -0,0 +1,203 @@
+// Package fieldcrypt mã hóa các cột nhạy cảm (số điện thoại, địa chỉ, secret của bên thứ ba) ở tầng ứng
+// dụng bằng AES-256-GCM. Cột được đánh dấu bằng tag `gorm:"serializer:encrypted"` sẽ được mã hóa khi ghi
+// và giải mã khi đọc mà code gọi không cần biết.
+//
+// Giá trị đã mã hóa có dạng "enc:v1:<key id>:<base64(nonce|ciphertext)>". Nhiều khóa có thể cùng được cấu
+// hình: khóa đầu tiên dùng để mã hóa, các khóa còn lại chỉ dùng để giải mã dữ liệu cũ khi xoay khóa. Giá trị
+// không có tiền tố (dữ liệu ghi trước khi bật mã hóa) được đọc như văn bản thường.
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName là tên serializer dùng trong tag gorm
+const SerializerName = "encrypted"
+
+// prefix đánh dấu giá trị đã mã hóa theo định dạng v1
+const prefix = "enc:v1:"
+
+var (
+	// ErrNoKey được trả về khi đọc giá trị mã hóa bằng khóa không có trong cấu hình
+	ErrNoKey = errors.New("fieldcrypt: encryption key not configured")
+	// ErrMalformed được trả về khi giá trị có tiền tố mã hóa nhưng sai định dạng hoặc không giải mã được
+	ErrMalformed = errors.New("fieldcrypt: malformed ciphertext")
+)
+
+// Keyring là tập khóa mã hóa; khóa đầu tiên là khóa chính
+type Keyring struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// ParseKeys đọc danh sách khóa dạng "id:base64key,id2:base64key2"; mỗi khóa là 32 byte (AES-256) mã hóa
+// base64 chuẩn, id gồm chữ, số và dấu gạch ngang. Chuỗi rỗng trả về keyring không có khóa (lưu văn bản thường).
+func ParseKeys(spec string) (*Keyring, error) {
+	k := &Keyring{aeads: make(map[string]cipher.AEAD)}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || !validKeyID(id) {
+			return nil, fmt.Errorf("fieldcrypt: key entry must be id:base64key with an alphanumeric id")
+		}
+		if _, dup := k.aeads[id]; dup {
+			return nil, fmt.Errorf("fieldcrypt: duplicate key id %q", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("fieldcrypt: key %q must be 32 bytes encoded in base64", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		if k.primary == "" {
+			k.primary = id
+		}
+		k.aeads[id] = aead
+	}
+	return k, nil
+}
+
+// validKeyID kiểm tra id khóa chỉ gồm chữ, số và '-' (không chứa ký tự đại diện của LIKE)
+func validKeyID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// Enabled cho biết keyring có khóa để mã hóa không
+func (k *Keyring) Enabled() bool {
+	return k.primary != ""
+}
+
+// PrimaryID là id của khóa đang dùng để mã hóa
+func (k *Keyring) PrimaryID() string {
+	return k.primary
+}
+
+// Encrypt mã hóa plaintext bằng khóa chính; chuỗi rỗng và keyring không có khóa giữ nguyên giá trị
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" || !k.Enabled() {
+		return plaintext, nil
+	}
+	aead := k.aeads[k.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(k.primary))
+	return prefix + k.primary + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt giải mã giá trị do Encrypt tạo; giá trị không có tiền tố mã hóa được trả về nguyên vẹn
+func (k *Keyring) Decrypt(value string) (string, error) {
+	rest, ok := strings.CutPrefix(value, prefix)
+	if !ok {
+		return value, nil
+	}
+	id, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", ErrMalformed
+	}
+	aead, ok := k.aeads[id]
+	if !ok {
+		return "", fmt.Errorf("%w: key id %q", ErrNoKey, id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return "", ErrMalformed
+	}
+	return string(plaintext), nil
+}
+
+// CurrentPrefix là tiền tố của giá trị mã hóa bằng khóa chính, dùng để tìm các dòng cần xoay khóa bằng SQL
+func (k *Keyring) CurrentPrefix() string {
+	return prefix + k.primary + ":"
+}
+
+var current atomic.Pointer[Keyring]
+
+func init() {
+	current.Store(&Keyring{aeads: map[string]cipher.AEAD{}})
+	schema.RegisterSerializer(SerializerName, serializer{})
+}
+
+// Configure đặt keyring cho serializer; phải gọi khi khởi động trước khi đọc hoặc ghi dữ liệu
+func Configure(k *Keyring) {
+	current.Store(k)
+}
+
+// Current trả về keyring đang dùng
+func Current() *Keyring {
+	return current.Load()
+}
+
+// ConfigureFromEnv đọc khóa từ FIELD_ENCRYPTION_KEYS (xem ParseKeys) và đặt làm keyring của serializer
+func ConfigureFromEnv() (*Keyring, error) {
+	k, err := ParseKeys(os.Getenv("FIELD_ENCRYPTION_KEYS"))
+	if err != nil {
+		return nil, err
+	}
+	Configure(k)
+	return k, nil
+}
+
+// serializer là serializer GORM cho các cột string được đánh dấu serializer:encrypted
+type serializer struct{}
+
+func (serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("fieldcrypt: unsupported database value %T for %s", dbValue, field.Name)
+	}
+	plaintext, err := Current().Decrypt(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", field.Name, err)
+	}
+	field.ReflectValueOf(ctx, dst).SetString(plaintext)
+	return nil
+}
+
+func (serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("fieldcrypt: %s must be a string, got %T", field.Name, fieldValue)
+	}
+	return Current().Encrypt(value)
+}
@@ -0,0 +1,141 @@
+package fieldcrypt
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const (
+	oldKey = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+	newKey = "ICEiIyQlJicoKSorLC0uLzAxMjM0NTY3ODk6Ozw9Pj8="
+)
+
+func mustKeys(t *testing.T, spec string) *Keyring {
+	t.Helper()
+	k, err := ParseKeys(spec)
+	if err != nil {
+		t.Fatalf("ParseKeys(%q): %v", spec, err)
+	}
+	return k
+}
+
+func TestRoundTrip(t *testing.T) {
+	k := mustKeys(t, "k1:"+oldKey)
+	for _, plaintext := range []string{"0901234567", "12 Nguyễn Huệ, Quận 1", strings.Repeat("x", 4096)} {
+		encrypted, err := k.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("Encrypt: %v", err)
+		}
+		if !strings.HasPrefix(encrypted, k.CurrentPrefix()) || strings.Contains(encrypted, plaintext) {
+			t.Errorf("Encrypt(%q) = %q, want prefix %q without the plaintext", plaintext, encrypted, k.CurrentPrefix())
+		}
+		decrypted, err := k.Decrypt(encrypted)
+		if err != nil || decrypted != plaintext {
+			t.Errorf("Decrypt = %q, %v; want %q", decrypted, err, plaintext)
+		}
+	}
+
+	// Nonce ngẫu nhiên: cùng plaintext cho ra ciphertext khác nhau
+	a, _ := k.Encrypt("same")
+	b, _ := k.Encrypt("same")
+	if a == b {
+		t.Error("two encryptions of the same value are identical")
+	}
+}
+
+func TestPlaintextPassthrough(t *testing.T) {
+	k := mustKeys(t, "k1:"+oldKey)
+	if got, err := k.Encrypt(""); err != nil || got != "" {
+		t.Errorf("Encrypt(\"\") = %q, %v", got, err)
+	}
+	// Dữ liệu ghi trước khi bật mã hóa
+	if got, err := k.Decrypt("0901234567"); err != nil || got != "0901234567" {
+		t.Errorf("Decrypt(plaintext) = %q, %v", got, err)
+	}
+
+	disabled := mustKeys(t, "")
+	if disabled.Enabled() {
+		t.Fatal("empty keyring is enabled")
+	}
+	if got, err := disabled.Encrypt("0901234567"); err != nil || got != "0901234567" {
+		t.Errorf("Encrypt without keys = %q, %v", got, err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	old := mustKeys(t, "old:"+oldKey)
+	encrypted, err := old.Encrypt("secret")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	rotated := mustKeys(t, "new:"+newKey+", old:"+oldKey)
+	if rotated.PrimaryID() != "new" {
+		t.Fatalf("PrimaryID = %q, want new", rotated.PrimaryID())
+	}
+	if got, err := rotated.Decrypt(encrypted); err != nil || got != "secret" {
+		t.Errorf("Decrypt with the retired key = %q, %v", got, err)
+	}
+	if strings.HasPrefix(encrypted, rotated.CurrentPrefix()) {
+		t.Error("value written with the old key matches the current prefix")
+	}
+	reencrypted, err := rotated.Encrypt("secret")
+	if err != nil || !strings.HasPrefix(reencrypted, "enc:v1:new:") {
+		t.Errorf("Encrypt after rotation = %q, %v", reencrypted, err)
+	}
+
+	// Khóa cũ đã bị gỡ khỏi cấu hình
+	newOnly := mustKeys(t, "new:"+newKey)
+	if _, err := newOnly.Decrypt(encrypted); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Decrypt without the old key: err = %v, want ErrNoKey", err)
+	}
+}
+
+func TestTamperDetection(t *testing.T) {
+	k := mustKeys(t, "a:"+oldKey+",b:"+newKey)
+	encrypted, err := k.Encrypt("0901234567")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	encoded := strings.TrimPrefix(encrypted, "enc:v1:a:")
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	flip := func(i int) string {
+		b := append([]byte(nil), sealed...)
+		b[i] ^= 1
+		return "enc:v1:a:" + base64.StdEncoding.EncodeToString(b)
+	}
+	tampered := map[string]string{
+		"nonce":      flip(0),
+		"ciphertext": flip(len(sealed) / 2),
+		"tag":        flip(len(sealed) - 1),
+		"key id":     "enc:v1:b:" + encoded, // id khóa là dữ liệu xác thực kèm theo
+		"truncated":  "enc:v1:a:" + base64.StdEncoding.EncodeToString(sealed[:8]),
+		"base64":     "enc:v1:a:not base64!",
+		"no key id":  "enc:v1:" + encoded,
+	}
+	for name, value := range tampered {
+		if got, err := k.Decrypt(value); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%s: Decrypt = %q, %v; want ErrMalformed", name, got, err)
+		}
+	}
+}
+
+func TestParseKeysErrors(t *testing.T) {
+	for _, spec := range []string{
+		"k1",                             // thiếu khóa
+		"k_1:" + oldKey,                  // ký tự không hợp lệ trong id
+		"k1:" + oldKey + ",k1:" + newKey, // trùng id
+		"k1:AAECAwQ=",                    // khóa ngắn
+		"k1:%%%",                         // không phải base64
+	} {
+		if _, err := ParseKeys(spec); err == nil {
+			t.Errorf("ParseKeys(%q) succeeded", spec)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/graph-gophers/dataloader"
+)
+
+// loaderWait là thời gian dataloader chờ gom các khóa được yêu cầu đồng thời vào một lô
+const loaderWait = 2 * time.Millisecond
+
+// loaders gom các truy vấn của trường lồng nhau trong một request thành một truy vấn cho mỗi lô, tránh N+1
+type loaders struct {
+	related *dataloader.Loader // danh mục -> tối đa MaxListLimit+1 sản phẩm mới nhất ([]models.Product)
+	ratings *dataloader.Loader // productKey -> models.ProductListing
+	reviews *dataloader.Loader // reviewKey -> []models.ProductReview
+}
+
+func (s *Server) newLoaders() *loaders {
+	return &loaders{
+		related: dataloader.NewBatchedLoader(s.loadCategoryProducts, dataloader.WithWait(loaderWait)),
+		ratings: dataloader.NewBatchedLoader(s.loadRatings, dataloader.WithWait(loaderWait)),
+		reviews: dataloader.NewBatchedLoader(s.loadReviews, dataloader.WithWait(loaderWait)),
+	}
+}
+
+// productKey là khóa dataloader theo ID sản phẩm
+type productKey uint
+
+func (k productKey) String() string   { return fmt.Sprint(uint(k)) }
+func (k productKey) Raw() interface{} { return k }
+
+// categoryKey là khóa dataloader theo tên danh mục
+type categoryKey string
+
+func (k categoryKey) String() string   { return string(k) }
+func (k categoryKey) Raw() interface{} { return k }
+
+// reviewKey là khóa dataloader của các đánh giá mới nhất của một sản phẩm với một giá trị limit
+type reviewKey struct {
+	productID uint
+	limit     int
+}
+
+func (k reviewKey) String() string   { return fmt.Sprintf("%d:%d", k.productID, k.limit) }
+func (k reviewKey) Raw() interface{} { return k }
+
+// failed trả về cùng một lỗi cho mọi khóa của lô
+func failed(keys dataloader.Keys, err error) []*dataloader.Result {
+	results := make([]*dataloader.Result, len(keys))
+	for i := range results {
+		results[i] = &dataloader.Result{Error: err}
+	}
+	return results
+}
+
+// loadCategoryProducts tải sản phẩm của các danh mục trong lô; related bỏ qua chính sản phẩm đang xem nên
+// mỗi danh mục lấy thêm một sản phẩm
+func (s *Server) loadCategoryProducts(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+	products, err := s.products.GetByCategories(ctx, keys.Keys(), MaxListLimit+1)
+	if err != nil {
+		return failed(keys, err)
+	}
+	byCategory := make(map[string][]models.Product, len(keys))
+	for _, p := range products {
+		byCategory[p.Category] = append(byCategory[p.Category], p)
+	}
+	results := make([]*dataloader.Result, len(keys))
+	for i, key := range keys {
+		results[i] = &dataloader.Result{Data: byCategory[key.String()]}
+	}
+	return results
+}
+
+// loadRatings tải rating từ read model product_listings; sản phẩm chưa có dòng read model có rating 0
+func (s *Server) loadRatings(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+	ids := make([]uint, len(keys))
+	for i, key := range keys {
+		ids[i] = uint(key.(productKey))
+	}
+	listings, err := s.listings.GetByIDs(ctx, ids)
+	if err != nil {
+		return failed(keys, err)
+	}
+	byProduct := make(map[uint]models.ProductListing, len(listings))
+	for _, listing := range listings {
+		byProduct[listing.ProductID] = listing
+	}
+	results := make([]*dataloader.Result, len(keys))
+	for i, id := range ids {
+		results[i] = &dataloader.Result{Data: byProduct[id]}
+	}
+	return results
+}
+
+// loadReviews tải đánh giá đã duyệt mới nhất của các sản phẩm trong lô, một truy vấn cho mỗi giá trị limit
+func (s *Server) loadReviews(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+	byLimit := make(map[int][]uint)
+	for _, key := range keys {
+		k := key.(reviewKey)
+		byLimit[k.limit] = append(byLimit[k.limit], k.productID)
+	}
+	loaded := make(map[reviewKey][]models.ProductReview, len(keys))
+	for limit, ids := range byLimit {
+		reviews, err := s.reviews.ListLatestApproved(ctx, ids, limit)
+		if err != nil {
+			return failed(keys, err)
+		}
+		for _, r := range reviews {
+			k := reviewKey{productID: r.ProductID, limit: limit}
+			loaded[k] = append(loaded[k], r)
+		}
+	}
+	results := make([]*dataloader.Result, len(keys))
+	for i, key := range keys {
+		results[i] = &dataloader.Result{Data: loaded[key.(reviewKey)]}
+	}
+	return results
+}
+
+// load đợi kết quả của khóa từ loader
+func load[T any](ctx context.Context, loader *dataloader.Loader, key dataloader.Key) (T, error) {
+	var zero T
+	data, err := loader.Load(ctx, key)()
+	if err != nil {
+		return zero, err
+	}
+	if data == nil {
+		return zero, nil
+	}
+	return data.(T), nil
+}
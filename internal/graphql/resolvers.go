@@ -0,0 +1,221 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"gorm.io/gorm"
+)
+
+// queryResolver resolve các trường gốc của Query
+type queryResolver struct {
+	products *repository.ProductRepository
+}
+
+// listArgs là tham số của các trường danh sách lồng nhau
+type listArgs struct {
+	Limit *int32
+}
+
+// limitOf trả về limit của trường danh sách: def nếu không truyền hoặc không dương, tối đa MaxListLimit
+func limitOf(limit *int32, def int) int {
+	if limit == nil || *limit <= 0 {
+		return def
+	}
+	return min(int(*limit), MaxListLimit)
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
+
+func (r *queryResolver) Products(ctx context.Context, args struct {
+	Search, Category, SortBy, Order *string
+	MinPrice, MaxPrice              *float64
+	InStock                         *bool
+	Page, Limit                     *int32
+}) ([]*productResolver, error) {
+	query := models.ProductQueryParams{
+		Search:   deref(args.Search),
+		Category: deref(args.Category),
+		MinPrice: deref(args.MinPrice),
+		MaxPrice: deref(args.MaxPrice),
+		InStock:  deref(args.InStock),
+		SortBy:   deref(args.SortBy),
+		Order:    deref(args.Order),
+		Page:     max(int(deref(args.Page)), 1),
+		Limit:    limitOf(args.Limit, 10),
+		Status:   models.ProductStatusPublished,
+		// Field products không trả về tổng số nên bỏ qua việc đếm
+		Count: models.CountNone,
+	}
+	if err := stateFrom(ctx).budget.charge(query.Limit); err != nil {
+		return nil, err
+	}
+	products, _, err := r.products.GetAll(ctx, &query)
+	if err != nil {
+		return nil, err
+	}
+	return productResolvers(products), nil
+}
+
+func (r *queryResolver) Product(ctx context.Context, args struct{ ID graphqlgo.ID }) (*productResolver, error) {
+	id, err := strconv.ParseUint(string(args.ID), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product id %q", args.ID)
+	}
+	product, err := r.products.GetByID(ctx, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if product.Status != models.ProductStatusPublished {
+		return nil, nil
+	}
+	return &productResolver{p: *product}, nil
+}
+
+func (r *queryResolver) Categories(ctx context.Context) ([]*categoryResolver, error) {
+	counts, err := r.products.GetCategoryCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := stateFrom(ctx).budget.charge(len(counts)); err != nil {
+		return nil, err
+	}
+	resolvers := make([]*categoryResolver, len(counts))
+	for i, cc := range counts {
+		resolvers[i] = &categoryResolver{cc: cc}
+	}
+	return resolvers, nil
+}
+
+type productResolver struct {
+	p models.Product
+}
+
+func productResolvers(products []models.Product) []*productResolver {
+	resolvers := make([]*productResolver, len(products))
+	for i := range products {
+		resolvers[i] = &productResolver{p: products[i]}
+	}
+	return resolvers
+}
+
+func (r *productResolver) ID() graphqlgo.ID {
+	return graphqlgo.ID(strconv.FormatUint(uint64(r.p.ID), 10))
+}
+
+func (r *productResolver) Name() string            { return r.p.Name }
+func (r *productResolver) Description() string     { return r.p.Description }
+func (r *productResolver) Price() float64          { return r.p.Price }
+func (r *productResolver) EffectivePrice() float64 { return r.p.EffectivePrice() }
+func (r *productResolver) Stock() int32            { return int32(r.p.Stock) }
+func (r *productResolver) ImageURL() string        { return r.p.ImageURL }
+func (r *productResolver) Category() string        { return r.p.Category }
+func (r *productResolver) CreatedAt() graphqlgo.Time {
+	return graphqlgo.Time{Time: r.p.CreatedAt}
+}
+func (r *productResolver) UpdatedAt() graphqlgo.Time {
+	return graphqlgo.Time{Time: r.p.UpdatedAt}
+}
+
+func (r *productResolver) RatingAvg(ctx context.Context) (float64, error) {
+	listing, err := load[models.ProductListing](ctx, stateFrom(ctx).loaders.ratings, productKey(r.p.ID))
+	return listing.RatingAvg, err
+}
+
+func (r *productResolver) RatingCount(ctx context.Context) (int32, error) {
+	listing, err := load[models.ProductListing](ctx, stateFrom(ctx).loaders.ratings, productKey(r.p.ID))
+	return int32(listing.RatingCount), err
+}
+
+func (r *productResolver) Reviews(ctx context.Context, args listArgs) ([]*reviewResolver, error) {
+	state := stateFrom(ctx)
+	limit := limitOf(args.Limit, 5)
+	if err := state.budget.charge(limit); err != nil {
+		return nil, err
+	}
+	reviews, err := load[[]models.ProductReview](ctx, state.loaders.reviews, reviewKey{productID: r.p.ID, limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*reviewResolver, len(reviews))
+	for i := range reviews {
+		resolvers[i] = &reviewResolver{r: reviews[i]}
+	}
+	return resolvers, nil
+}
+
+func (r *productResolver) Related(ctx context.Context, args listArgs) ([]*productResolver, error) {
+	state := stateFrom(ctx)
+	limit := limitOf(args.Limit, 4)
+	if err := state.budget.charge(limit); err != nil {
+		return nil, err
+	}
+	products, err := load[[]models.Product](ctx, state.loaders.related, categoryKey(r.p.Category))
+	if err != nil {
+		return nil, err
+	}
+	related := make([]models.Product, 0, limit)
+	for _, p := range products {
+		if len(related) >= limit {
+			break
+		}
+		if p.ID != r.p.ID {
+			related = append(related, p)
+		}
+	}
+	return productResolvers(related), nil
+}
+
+type reviewResolver struct {
+	r models.ProductReview
+}
+
+func (r *reviewResolver) ID() graphqlgo.ID {
+	return graphqlgo.ID(strconv.FormatUint(uint64(r.r.ID), 10))
+}
+
+func (r *reviewResolver) Rating() int32          { return int32(r.r.Rating) }
+func (r *reviewResolver) Title() string          { return r.r.Title }
+func (r *reviewResolver) Body() string           { return r.r.Body }
+func (r *reviewResolver) VerifiedPurchase() bool { return r.r.VerifiedPurchase }
+func (r *reviewResolver) HelpfulCount() int32    { return int32(r.r.HelpfulCount) }
+func (r *reviewResolver) CreatedAt() graphqlgo.Time {
+	return graphqlgo.Time{Time: r.r.CreatedAt}
+}
+
+type categoryResolver struct {
+	cc repository.CategoryCount
+}
+
+func (r *categoryResolver) Name() string        { return r.cc.Category }
+func (r *categoryResolver) ProductCount() int32 { return int32(r.cc.Count) }
+
+func (r *categoryResolver) Products(ctx context.Context, args listArgs) ([]*productResolver, error) {
+	state := stateFrom(ctx)
+	limit := limitOf(args.Limit, 10)
+	if err := state.budget.charge(limit); err != nil {
+		return nil, err
+	}
+	products, err := load[[]models.Product](ctx, state.loaders.related, categoryKey(r.cc.Category))
+	if err != nil {
+		return nil, err
+	}
+	if len(products) > limit {
+		products = products[:limit]
+	}
+	return productResolvers(products), nil
+}
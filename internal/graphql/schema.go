@@ -0,0 +1,112 @@
+// Package graphql cung cấp API GraphQL cho catalog. Schema được khai báo trong schema.graphql và thực thi
+// bởi graph-gophers/graphql-go (kiểm tra truy vấn theo schema, introspection); resolver dùng chung tầng
+// repository với REST API. Các trường lồng nhau (related, reviews, rating) được tải theo lô qua dataloader
+// riêng của từng request.
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/NgTruong624/project_backend/internal/repository"
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"gorm.io/gorm"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// MaxListLimit là giá trị tối đa của tham số limit trên các trường danh sách
+const MaxListLimit = 100
+
+// Limits giới hạn kích thước truy vấn để một request không buộc server tải quá nhiều dữ liệu; giá trị 0 là
+// không giới hạn
+type Limits struct {
+	MaxDepth      int // độ lồng tối đa của selection set
+	MaxComplexity int // tổng limit tối đa của các trường danh sách được thực thi trong một request
+}
+
+// Request là body của một request GraphQL
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Server thực thi truy vấn trên schema của catalog
+type Server struct {
+	schema   *graphqlgo.Schema
+	products *repository.ProductRepository
+	reviews  *repository.ReviewRepository
+	listings *repository.ProductListingRepository
+	limits   Limits
+}
+
+// NewServer dựng schema từ schema.graphql; panic nếu resolver không khớp schema
+func NewServer(db *gorm.DB, limits Limits) *Server {
+	s := &Server{
+		products: repository.NewProductRepository(db),
+		reviews:  repository.NewReviewRepository(db),
+		listings: repository.NewProductListingRepository(db),
+		limits:   limits,
+	}
+	opts := []graphqlgo.SchemaOpt{
+		graphqlgo.UseStringDescriptions(),
+		// Các phần tử của một danh sách được resolve đồng thời để dataloader gom chúng vào cùng một lô
+		graphqlgo.MaxParallelism(MaxListLimit),
+	}
+	if limits.MaxDepth > 0 {
+		opts = append(opts, graphqlgo.MaxDepth(limits.MaxDepth))
+	}
+	s.schema = graphqlgo.MustParseSchema(schemaSDL, &queryResolver{products: s.products}, opts...)
+	return s
+}
+
+// Exec thực thi request với các loader và ngân sách độ phức tạp riêng của request
+func (s *Server) Exec(ctx context.Context, req Request) *graphqlgo.Response {
+	state := &requestState{loaders: s.newLoaders()}
+	if s.limits.MaxComplexity > 0 {
+		state.budget = &budget{max: s.limits.MaxComplexity}
+		state.budget.remaining.Store(int64(s.limits.MaxComplexity))
+	}
+	ctx = context.WithValue(ctx, stateKey{}, state)
+	return s.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+}
+
+// ErrorResponse là response chỉ gồm một lỗi, dùng khi request không đọc được
+func ErrorResponse(message string) *graphqlgo.Response {
+	return &graphqlgo.Response{Errors: []*gqlerrors.QueryError{{Message: message}}}
+}
+
+// requestState là dữ liệu riêng của một request được resolver đọc từ context
+type requestState struct {
+	loaders *loaders
+	budget  *budget
+}
+
+type stateKey struct{}
+
+func stateFrom(ctx context.Context) *requestState {
+	return ctx.Value(stateKey{}).(*requestState)
+}
+
+// budget là ngân sách độ phức tạp của một request: mỗi trường danh sách trừ đi limit của nó trước khi tải
+// dữ liệu, nên truy vấn lồng related/reviews sâu với limit lớn bị dừng trước khi tải quá nhiều
+type budget struct {
+	max       int
+	remaining atomic.Int64
+}
+
+// charge trừ n khỏi ngân sách; lỗi khi ngân sách đã hết
+func (b *budget) charge(n int) error {
+	if b == nil {
+		return nil
+	}
+	if b.remaining.Add(-int64(n)) < 0 {
+		return fmt.Errorf("query complexity exceeds the maximum of %d", b.max)
+	}
+	return nil
+}
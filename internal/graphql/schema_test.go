@@ -0,0 +1,155 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestServer dựng Server trên SQLite trong bộ nhớ với 3 sản phẩm cùng danh mục, mỗi sản phẩm 2 đánh giá
+func newTestServer(t *testing.T, limits Limits) (*Server, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	// Mỗi kết nối tới file::memory: là một database riêng, còn resolver chạy đồng thời
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&models.Product{}, &models.ProductReview{}, &models.ProductListing{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		product := models.Product{Name: fmt.Sprintf("Phone %d", i), Price: float64(i * 100), Stock: i,
+			Category: "phones", Status: models.ProductStatusPublished}
+		if err := db.Create(&product).Error; err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+		for j := 1; j <= 2; j++ {
+			review := models.ProductReview{ProductID: product.ID, UserID: uint(j), Rating: j + 3,
+				Title: fmt.Sprintf("Review %d", j), Status: models.ModerationApproved}
+			if err := db.Create(&review).Error; err != nil {
+				t.Fatalf("create review: %v", err)
+			}
+		}
+	}
+	return NewServer(db, limits), db
+}
+
+func exec(t *testing.T, s *Server, query string) (map[string]interface{}, []string) {
+	t.Helper()
+	resp := s.Exec(context.Background(), Request{Query: query})
+	var messages []string
+	for _, err := range resp.Errors {
+		messages = append(messages, err.Message)
+	}
+	var data map[string]interface{}
+	if len(resp.Data) > 0 {
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+	}
+	return data, messages
+}
+
+func TestQueryProducts(t *testing.T) {
+	s, _ := newTestServer(t, Limits{})
+	data, errs := exec(t, s, `{ products(limit: 2, sortBy: "price", order: "asc") {
+		id name price reviews(limit: 1) { rating } related { name } } }`)
+	if len(errs) > 0 {
+		t.Fatalf("errors: %v", errs)
+	}
+	products := data["products"].([]interface{})
+	if len(products) != 2 {
+		t.Fatalf("products = %v", products)
+	}
+	first := products[0].(map[string]interface{})
+	if first["name"] != "Phone 1" || len(first["reviews"].([]interface{})) != 1 ||
+		len(first["related"].([]interface{})) != 2 {
+		t.Errorf("first product = %v", first)
+	}
+	for _, related := range first["related"].([]interface{}) {
+		if related.(map[string]interface{})["name"] == "Phone 1" {
+			t.Error("related contains the product itself")
+		}
+	}
+
+	data, errs = exec(t, s, `{ product(id: "999") { id } }`)
+	if len(errs) > 0 || data["product"] != nil {
+		t.Errorf("missing product = %v, %v", data, errs)
+	}
+}
+
+func TestQueryValidation(t *testing.T) {
+	s, _ := newTestServer(t, Limits{})
+	if _, errs := exec(t, s, `{ products { sku } }`); len(errs) != 1 || !strings.Contains(errs[0], `"sku"`) {
+		t.Errorf("unknown field errors = %v", errs)
+	}
+	data, errs := exec(t, s, `{ __schema { queryType { name } } __type(name: "Product") { fields { name } } }`)
+	if len(errs) > 0 {
+		t.Fatalf("introspection errors: %v", errs)
+	}
+	if name := data["__schema"].(map[string]interface{})["queryType"].(map[string]interface{})["name"]; name != "Query" {
+		t.Errorf("queryType = %v", name)
+	}
+	if fields := data["__type"].(map[string]interface{})["fields"].([]interface{}); len(fields) != 14 {
+		t.Errorf("Product has %d fields, want 14", len(fields))
+	}
+}
+
+func TestQueryLimits(t *testing.T) {
+	s, _ := newTestServer(t, Limits{MaxDepth: 3, MaxComplexity: 50})
+	if _, errs := exec(t, s, `{ products { related { related { id } } } }`); len(errs) == 0 {
+		t.Error("query deeper than MaxDepth was executed")
+	}
+	// limit bị giới hạn ở MaxListLimit nên 1000 chỉ trừ 100 khỏi ngân sách
+	_, errs := exec(t, s, `{ products(limit: 1000) { id } }`)
+	if len(errs) != 1 || !strings.Contains(errs[0], "maximum of 50") {
+		t.Errorf("complexity errors = %v", errs)
+	}
+	if _, errs := exec(t, s, `{ products(limit: 40) { id } }`); len(errs) > 0 {
+		t.Errorf("query within budget failed: %v", errs)
+	}
+}
+
+func TestQueryBatchesNestedFields(t *testing.T) {
+	s, db := newTestServer(t, Limits{})
+	var reviewQueries, listingQueries atomic.Int32
+	err := db.Callback().Query().After("gorm:query").Register("test:count", func(tx *gorm.DB) {
+		// Subquery chỉ được dựng SQL (DryRun), không chạy riêng
+		if tx.DryRun {
+			return
+		}
+		sql := tx.Statement.SQL.String()
+		if strings.Contains(sql, "FROM `product_listings`") {
+			listingQueries.Add(1)
+		}
+		if strings.Contains(sql, "FROM `product_reviews`") {
+			reviewQueries.Add(1)
+		}
+	})
+	if err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	_, errs := exec(t, s, `{ products { ratingAvg ratingCount reviews { title } } }`)
+	if len(errs) > 0 {
+		t.Fatalf("errors: %v", errs)
+	}
+	if n := reviewQueries.Load(); n != 1 {
+		t.Errorf("review queries = %d, want 1", n)
+	}
+	if n := listingQueries.Load(); n != 1 {
+		t.Errorf("listing queries = %d, want 1", n)
+	}
+}
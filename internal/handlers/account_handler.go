@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/notify"
+	"github.com/NgTruong624/project_backend/internal/payment"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AccountHandler struct {
+	service      *services.AccountService
+	emailChanges *services.EmailChangeService
+	bus          *events.Bus
+}
+
+func NewAccountHandler(db *gorm.DB, provider payment.Provider, bus *events.Bus, notifier *notify.Dispatcher) *AccountHandler {
+	return &AccountHandler{
+		service:      services.NewAccountService(db, provider),
+		emailChanges: services.NewEmailChangeService(db, notifier),
+		bus:          bus,
+	}
+}
+
+// DeleteAccount xóa tài khoản của user hiện tại (cần nhập lại mật khẩu). Tài khoản có thể được
+// admin khôi phục trong thời gian chờ, sau đó dữ liệu cá nhân bị ẩn danh hóa.
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	var req models.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	user, err := h.service.Delete(c.Request.Context(), c.GetUint("user_id"), req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "User not found", ""))
+		case errors.Is(err, services.ErrInvalidPassword):
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Password is incorrect", ""))
+		case errors.Is(err, services.ErrLastAdminAccount):
+			c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Cannot delete the last admin account", ""))
+		default:
+			c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error deleting account", err.Error()))
+		}
+		return
+	}
+	h.bus.Publish(c.Request.Context(), events.AccountDeleted{UserID: user.ID, IP: c.ClientIP(), At: time.Now()})
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Account deleted successfully", user))
+}
+
+// ExportData trả về toàn bộ dữ liệu cá nhân của user hiện tại dưới dạng file JSON
+func (h *AccountHandler) ExportData(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	export, err := h.service.Export(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "User not found", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error exporting user data", err.Error()))
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="user-%d-export.json"`, userID))
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "User data exported successfully", export))
+}
+
+// RequestEmailChange bắt đầu đổi email của user hiện tại (cần nhập lại mật khẩu): link xác nhận
+// được gửi tới cả địa chỉ hiện tại và địa chỉ mới, email chỉ đổi khi cả hai đã xác nhận
+func (h *AccountHandler) RequestEmailChange(c *gin.Context) {
+	var req models.EmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	change, err := h.emailChanges.Request(c.Request.Context(), c.GetUint("user_id"), req.NewEmail, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "User not found", ""))
+		case errors.Is(err, services.ErrInvalidPassword):
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Password is incorrect", ""))
+		case errors.Is(err, services.ErrSameEmail):
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "New email must be different from the current email", ""))
+		case errors.Is(err, services.ErrEmailTaken):
+			c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Email already exists", ""))
+		default:
+			c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error requesting email change", err.Error()))
+		}
+		return
+	}
+	h.bus.Publish(c.Request.Context(), events.EmailChangeRequested{UserID: change.UserID, ChangeID: change.ID, IP: c.ClientIP(), At: time.Now()})
+	c.JSON(http.StatusAccepted, utils.NewResponse(c, http.StatusAccepted, "Confirmation links sent to both email addresses", change))
+}
+
+// ConfirmEmailChange xác nhận đổi email bằng token trong link đã gửi tới một trong hai địa chỉ.
+// Không cần đăng nhập vì token chính là bằng chứng sở hữu địa chỉ email.
+func (h *AccountHandler) ConfirmEmailChange(c *gin.Context) {
+	var req models.ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	change, completed, err := h.emailChanges.Confirm(c.Request.Context(), req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrEmailChangeNotFound):
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Email change request not found or expired", ""))
+		case errors.Is(err, services.ErrEmailTaken):
+			c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Email already exists", ""))
+		default:
+			c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error confirming email change", err.Error()))
+		}
+		return
+	}
+	if completed {
+		h.bus.Publish(c.Request.Context(), events.EmailChanged{UserID: change.UserID, ChangeID: change.ID, IP: c.ClientIP(), At: time.Now()})
+	}
+	if change.CompletedAt != nil {
+		c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Email changed successfully, please log in again", change))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Email change confirmed, waiting for the other address to confirm", change))
+}
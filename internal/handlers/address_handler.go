@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AddressHandler struct {
+	service *services.AddressService
+}
+
+func NewAddressHandler(db *gorm.DB) *AddressHandler {
+	return &AddressHandler{
+		service: services.NewAddressService(db),
+	}
+}
+
+// ListAddresses lấy sổ địa chỉ của user hiện tại
+func (h *AddressHandler) ListAddresses(c *gin.Context) {
+	addresses, err := h.service.List(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching addresses", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Addresses retrieved successfully", addresses))
+}
+
+// GetAddress lấy một địa chỉ của user hiện tại
+func (h *AddressHandler) GetAddress(c *gin.Context) {
+	id, ok := parseAddressID(c)
+	if !ok {
+		return
+	}
+	address, err := h.service.Get(c.Request.Context(), c.GetUint("user_id"), id)
+	if err != nil {
+		h.handleError(c, err, "Error fetching address")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Address retrieved successfully", address))
+}
+
+// CreateAddress thêm địa chỉ giao hàng
+func (h *AddressHandler) CreateAddress(c *gin.Context) {
+	var req models.AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	address, err := h.service.Create(c.Request.Context(), c.GetUint("user_id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Error saving address")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Address saved successfully", address))
+}
+
+// UpdateAddress sửa địa chỉ giao hàng
+func (h *AddressHandler) UpdateAddress(c *gin.Context) {
+	id, ok := parseAddressID(c)
+	if !ok {
+		return
+	}
+	var req models.AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	address, err := h.service.Update(c.Request.Context(), c.GetUint("user_id"), id, &req)
+	if err != nil {
+		h.handleError(c, err, "Error updating address")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Address updated successfully", address))
+}
+
+// SetDefaultAddress chọn địa chỉ mặc định
+func (h *AddressHandler) SetDefaultAddress(c *gin.Context) {
+	id, ok := parseAddressID(c)
+	if !ok {
+		return
+	}
+	address, err := h.service.SetDefault(c.Request.Context(), c.GetUint("user_id"), id)
+	if err != nil {
+		h.handleError(c, err, "Error updating address")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Default address updated", address))
+}
+
+// DeleteAddress xóa địa chỉ khỏi sổ địa chỉ
+func (h *AddressHandler) DeleteAddress(c *gin.Context) {
+	id, ok := parseAddressID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), c.GetUint("user_id"), id); err != nil {
+		h.handleError(c, err, "Error deleting address")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Address deleted successfully", nil))
+}
+
+func (h *AddressHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrAddressNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Address not found", ""))
+	case errors.Is(err, services.ErrAddressLimit):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Address book is full", fmt.Sprintf("at most %d addresses", models.MaxAddressesPerUser)))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
+
+func parseAddressID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid address ID", err.Error()))
+		return 0, false
+	}
+	return uint(id), true
+}
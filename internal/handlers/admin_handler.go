@@ -1,22 +1,35 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/NgTruong624/project_backend/internal/events"
 	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/payment"
 	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/services"
 	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type AdminHandler struct {
 	userRepo *repository.UserRepository
+	activity *repository.ActivityRepository
+	accounts *services.AccountService
+	bus      *events.Bus
 }
 
-func NewAdminHandler(db *gorm.DB) *AdminHandler {
+func NewAdminHandler(db *gorm.DB, provider payment.Provider, bus *events.Bus) *AdminHandler {
 	return &AdminHandler{
 		userRepo: repository.NewUserRepository(db),
+		activity: repository.NewActivityRepository(db),
+		accounts: services.NewAccountService(db, provider),
+		bus:      bus,
 	}
 }
 
@@ -25,13 +38,13 @@ func (h *AdminHandler) GetUsersList(c *gin.Context) {
 	// Kiểm tra quyền admin
 	role := c.GetString("role")
 	if role != "admin" {
-		c.JSON(http.StatusForbidden, utils.NewErrorResponse(http.StatusForbidden, "Permission denied", "Only admin can access user list"))
+		utils.Render(c, http.StatusForbidden, utils.NewErrorResponse(c, http.StatusForbidden, "Permission denied", "Only admin can access user list"))
 		return
 	}
 
 	var query models.UserQueryParams
 	if err := c.ShouldBindQuery(&query); err != nil {
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		utils.Render(c, http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err.Error()))
 		return
 	}
 
@@ -45,25 +58,26 @@ func (h *AdminHandler) GetUsersList(c *gin.Context) {
 	if query.Limit > 100 {
 		query.Limit = 100
 	}
+	if !query.EndDate.IsZero() {
+		// end_date bao gồm cả ngày đó
+		query.EndDate = query.EndDate.Add(24*time.Hour - time.Second)
+	}
+	if !query.StartDate.IsZero() && !query.EndDate.IsZero() && query.StartDate.After(query.EndDate) {
+		utils.Render(c, http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid date range", "start_date cannot be after end_date"))
+		return
+	}
 
 	// Get users from repository
-	users, total, err := h.userRepo.GetAllUsers(&query)
+	users, total, err := h.userRepo.GetAllUsers(c.Request.Context(), &query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Error fetching users", err.Error()))
+		utils.Render(c, http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching users", err.Error()))
 		return
 	}
 
 	// Convert to response (remove password field)
-	var userResponses []models.UserResponse
-	for _, u := range users {
-		userResponses = append(userResponses, models.UserResponse{
-			ID:        u.ID,
-			Username:  u.Username,
-			Email:     u.Email,
-			FullName:  u.FullName,
-			Role:      u.Role,
-			CreatedAt: u.CreatedAt,
-		})
+	userResponses := make([]models.UserResponse, 0, len(users))
+	for i := range users {
+		userResponses = append(userResponses, h.accounts.UserResponse(&users[i]))
 	}
 
 	// Calculate pagination info
@@ -86,8 +100,28 @@ func (h *AdminHandler) GetUsersList(c *gin.Context) {
 	if query.Role != "" {
 		meta["role"] = query.Role
 	}
+	if query.Status != "" {
+		meta["status"] = query.Status
+	}
+	if query.Deleted {
+		meta["deleted"] = true
+	}
+	if !query.StartDate.IsZero() {
+		meta["start_date"] = query.StartDate.Format("2006-01-02")
+	}
+	if !query.EndDate.IsZero() {
+		meta["end_date"] = query.EndDate.Format("2006-01-02")
+	}
+	if query.IsActive != nil {
+		meta["is_active"] = *query.IsActive
+	}
+	if query.SortBy != "" {
+		meta["sort_by"] = query.SortBy
+		meta["order"] = query.Order
+	}
 
-	c.JSON(http.StatusOK, utils.NewPaginatedResponse(
+	utils.Render(c, http.StatusOK, utils.NewPaginatedResponse(
+		c,
 		http.StatusOK,
 		"Users retrieved successfully",
 		userResponses,
@@ -98,3 +132,108 @@ func (h *AdminHandler) GetUsersList(c *gin.Context) {
 		meta,
 	))
 }
+
+// GetUser lấy thông tin user kèm số đơn hàng, số tiền đã chi và lần đăng nhập gần nhất (Admin only).
+// Vẫn xem được với tài khoản đã bị xóa.
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err.Error()))
+		return
+	}
+
+	user, err := h.userRepo.GetByIDUnscoped(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "User not found", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching user", err.Error()))
+		return
+	}
+	stats, err := h.userRepo.GetStats(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching user", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "User retrieved successfully", models.AdminUserDetailResponse{
+		UserResponse: h.accounts.UserResponse(user),
+		Stats:        *stats,
+	}))
+}
+
+// RestoreUser khôi phục tài khoản đã bị xóa khi chưa hết thời gian chờ ẩn danh hóa (Admin only)
+func (h *AdminHandler) RestoreUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err.Error()))
+		return
+	}
+	user, err := h.accounts.Restore(c.Request.Context(), uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Deleted user not found", ""))
+		case errors.Is(err, services.ErrUserAnonymized):
+			c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "User data has already been anonymized", ""))
+		default:
+			c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error restoring user", err.Error()))
+		}
+		return
+	}
+	h.bus.Publish(c.Request.Context(), events.AccountRestored{UserID: user.ID, AdminID: c.GetUint("user_id"), IP: c.ClientIP(), At: time.Now()})
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "User restored successfully", user))
+}
+
+// GetUserActivity trả về lịch sử hoạt động của user (đăng nhập, hóa đơn, thay đổi tài khoản) theo
+// thứ tự mới nhất trước, có phân trang (Admin only). Vẫn xem được với tài khoản đã bị xóa.
+func (h *AdminHandler) GetUserActivity(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err.Error()))
+		return
+	}
+	var query models.ActivityQueryParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+
+	user, err := h.userRepo.GetByIDUnscoped(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "User not found", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching user activity", err.Error()))
+		return
+	}
+
+	entries, total, err := h.activity.ListByUser(c.Request.Context(), user, &query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching user activity", err.Error()))
+		return
+	}
+
+	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	meta := map[string]interface{}{
+		"total":        total,
+		"total_pages":  totalPages,
+		"current_page": query.Page,
+		"per_page":     query.Limit,
+		"has_next":     query.Page < totalPages,
+		"has_prev":     query.Page > 1,
+		"user_id":      user.ID,
+	}
+	if query.Type != "" {
+		meta["type"] = query.Type
+	}
+	c.JSON(http.StatusOK, utils.NewPaginatedResponse(c, http.StatusOK, "User activity retrieved successfully", entries, query.Page, totalPages, total, query.Limit, meta))
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AuditHandler struct {
+	repo *repository.AuditLogRepository
+}
+
+func NewAuditHandler(db *gorm.DB) *AuditHandler {
+	return &AuditHandler{
+		repo: repository.NewAuditLogRepository(db),
+	}
+}
+
+// ListAuditLogs tìm audit log theo user, action, entity và khoảng ngày, gồm cả bản ghi request/response
+// (action http.request) của request log (Admin only)
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	var query models.AuditLogQueryParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+	// end_date là cả ngày cuối
+	if !query.EndDate.IsZero() {
+		query.EndDate = query.EndDate.Add(24*time.Hour - time.Second)
+	}
+	if !query.StartDate.IsZero() && !query.EndDate.IsZero() && query.StartDate.After(query.EndDate) {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid date range", "start_date cannot be after end_date"))
+		return
+	}
+
+	logs, total, err := h.repo.GetAll(c.Request.Context(), &query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching audit logs", err.Error()))
+		return
+	}
+
+	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	filters := map[string]interface{}{}
+	if query.UserID != 0 {
+		filters["user_id"] = query.UserID
+	}
+	if query.Action != "" {
+		filters["action"] = query.Action
+	}
+	if query.EntityType != "" {
+		filters["entity_type"] = query.EntityType
+	}
+	if query.EntityID != "" {
+		filters["entity_id"] = query.EntityID
+	}
+	if !query.StartDate.IsZero() {
+		filters["start_date"] = query.StartDate.Format("2006-01-02")
+	}
+	if !query.EndDate.IsZero() {
+		filters["end_date"] = query.EndDate.Format("2006-01-02")
+	}
+	c.JSON(http.StatusOK, utils.NewPaginatedResponse(
+		c, http.StatusOK, "Audit logs retrieved successfully", logs,
+		query.Page, totalPages, total, query.Limit, filters,
+	))
+}
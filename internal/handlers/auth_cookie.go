@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/middleware"
+	"github.com/NgTruong624/project_backend/internal/token"
+	"github.com/gin-gonic/gin"
+)
+
+// setAuthCookies giao access token trong cookie HttpOnly (JS không đọc được) và token CSRF trong
+// cookie JS đọc được để gửi lại qua header X-CSRF-Token; cả hai hết hạn cùng access token.
+// Trả về token CSRF để client có thể dùng ngay từ response.
+func setAuthCookies(c *gin.Context, tokens *token.Manager, tokenString string, claims *token.Claims) string {
+	cfg := tokens.Config()
+	maxAge := int(time.Until(claims.ExpiresAt.Time).Seconds())
+	csrf := tokens.CSRFToken(claims)
+	c.SetSameSite(cfg.CookieSameSite)
+	c.SetCookie(middleware.AccessTokenCookie, tokenString, maxAge, "/", cfg.CookieDomain, cfg.CookieSecure, true)
+	c.SetCookie(middleware.CSRFCookie, csrf, maxAge, "/", cfg.CookieDomain, cfg.CookieSecure, false)
+	return csrf
+}
+
+// clearAuthCookies xóa cookie đăng nhập khi đăng xuất
+func clearAuthCookies(c *gin.Context, tokens *token.Manager) {
+	cfg := tokens.Config()
+	c.SetSameSite(cfg.CookieSameSite)
+	c.SetCookie(middleware.AccessTokenCookie, "", -1, "/", cfg.CookieDomain, cfg.CookieSecure, true)
+	c.SetCookie(middleware.CSRFCookie, "", -1, "/", cfg.CookieDomain, cfg.CookieSecure, false)
+}
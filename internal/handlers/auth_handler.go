@@ -1,28 +1,54 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
+	"log"
 	"net/http"
 	"time"
 
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/i18n"
+	"github.com/NgTruong624/project_backend/internal/middleware"
 	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/notify"
+	"github.com/NgTruong624/project_backend/internal/password"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/token"
 	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/NgTruong624/project_backend/internal/visitor"
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
-	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type AuthHandler struct {
 	db        *gorm.DB
 	jwtSecret string
+	tokens    *token.Manager
+	bus       *events.Bus
+	carts     *services.CartService
+	passwords *password.Policy
+	hasher    *password.Hasher
+	users     *repository.UserRepository
+	revoked   *repository.RevokedTokenRepository
+	visitors  *visitor.Tracker
+	logins    *services.LoginSecurityService
 }
 
-func NewAuthHandler(db *gorm.DB, jwtSecret string) *AuthHandler {
+func NewAuthHandler(db *gorm.DB, jwtSecret string, tokens *token.Manager, bus *events.Bus, notifier *notify.Dispatcher, passwords *password.Policy, hasher *password.Hasher, visitors *visitor.Tracker) *AuthHandler {
 	return &AuthHandler{
 		db:        db,
 		jwtSecret: jwtSecret,
+		tokens:    tokens,
+		bus:       bus,
+		passwords: passwords,
+		hasher:    hasher,
+		carts:     services.NewCartService(db),
+		users:     repository.NewUserRepository(db),
+		revoked:   repository.NewRevokedTokenRepository(db),
+		visitors:  visitors,
+		logins:    services.NewLoginSecurityService(db, bus, notifier),
 	}
 }
 
@@ -30,21 +56,26 @@ func NewAuthHandler(db *gorm.DB, jwtSecret string) *AuthHandler {
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req models.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid request", err.Error()))
+		validation.Respond(c, err)
 		return
 	}
 
-	// Kiểm tra email đã tồn tại
+	// Kiểm tra email đã tồn tại (kể cả tài khoản đã xóa còn trong thời gian chờ khôi phục)
 	var existingUser models.User
-	if err := h.db.Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Email already exists", ""))
+	if err := h.db.Unscoped().Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Email already exists", ""))
+		return
+	}
+
+	if violations := h.passwords.Check(c.Request.Context(), req.Password, req.Username, req.Email, req.FullName); len(violations) > 0 {
+		respondPasswordPolicy(c, "password", violations)
 		return
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := h.hasher.Hash(req.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Error hashing password", err.Error()))
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error hashing password", err.Error()))
 		return
 	}
 
@@ -52,15 +83,16 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	user := models.User{
 		Username:  req.Username,
 		Email:     req.Email,
-		Password:  string(hashedPassword),
+		Password:  hashedPassword,
 		FullName:  req.FullName,
 		Role:      "user", // Mặc định là user
+		Status:    models.UserStatusActive,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
 	if err := h.db.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Error creating user", err.Error()))
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error creating user", err.Error()))
 		return
 	}
 
@@ -71,130 +103,284 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		Email:     user.Email,
 		FullName:  user.FullName,
 		Role:      user.Role,
+		Status:    user.Status,
 		CreatedAt: user.CreatedAt,
 	}
 
-	c.JSON(http.StatusCreated, utils.NewResponse(http.StatusCreated, "User registered successfully", userResponse))
+	h.bus.Publish(c.Request.Context(), events.UserRegistered{User: user})
+	resp := utils.NewResponse(c, http.StatusCreated, "User registered successfully", userResponse)
+	if merged := h.mergeGuestCart(c, user.ID, req.CartToken); merged != nil {
+		resp.Meta = gin.H{"cart_merge": merged}
+	}
+	c.JSON(http.StatusCreated, resp)
 }
 
 // Login xử lý đăng nhập
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req models.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid request", err.Error()))
+		validation.Respond(c, err)
 		return
 	}
 
 	// Tìm user theo username
 	var user models.User
 	if err := h.db.Where("username = ?", req.Username).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, utils.NewErrorResponse(http.StatusUnauthorized, "Invalid username or password", ""))
+		h.bus.Publish(c.Request.Context(), events.LoginFailed{Username: req.Username, IP: c.ClientIP(), At: time.Now()})
+		c.JSON(http.StatusUnauthorized, utils.NewErrorResponse(c, http.StatusUnauthorized, "Invalid username or password", ""))
 		return
 	}
 
 	// Kiểm tra password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, utils.NewErrorResponse(http.StatusUnauthorized, "Invalid username or password", ""))
+	if err := password.Compare(user.Password, req.Password); err != nil {
+		h.bus.Publish(c.Request.Context(), events.LoginFailed{Username: req.Username, IP: c.ClientIP(), At: time.Now()})
+		c.JSON(http.StatusUnauthorized, utils.NewErrorResponse(c, http.StatusUnauthorized, "Invalid username or password", ""))
 		return
 	}
+	h.rehashPassword(c, &user, req.Password)
 
-	// Tạo JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id":  user.ID,
-		"username": user.Username,
-		"role":     user.Role,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(), // Token hết hạn sau 24 giờ
+	// Ghi lại thiết bị, quốc gia và phát hiện đăng nhập đáng ngờ; có thể phải xác nhận qua email trước khi phát token
+	decision, err := h.logins.Check(c.Request.Context(), &user, services.LoginAttempt{
+		DeviceFingerprint: req.DeviceFingerprint,
+		UserAgent:         c.Request.UserAgent(),
+		IP:                c.ClientIP(),
+		Country:           h.logins.Country(c.Request.Header),
 	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error checking login", err.Error()))
+		return
+	}
+	if decision.ConfirmationRequired {
+		c.JSON(http.StatusAccepted, utils.NewResponse(c, http.StatusAccepted, "Confirm this sign-in with the link sent to your email", gin.H{
+			"confirmation_required": true,
+			"expires_at":            decision.ExpiresAt,
+		}))
+		return
+	}
+
+	h.startSession(c, &user, req.AuthMode, req.CartToken)
+}
+
+// ConfirmLogin hoàn tất lần đăng nhập đáng ngờ bằng token trong link đã gửi qua email và phát token như Login
+func (h *AuthHandler) ConfirmLogin(c *gin.Context) {
+	var req models.ConfirmLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+
+	user, err := h.logins.Confirm(c.Request.Context(), req.Token)
+	if err != nil {
+		if errors.Is(err, services.ErrLoginChallengeInvalid) {
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Login confirmation link is invalid or expired", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error confirming login", err.Error()))
+		return
+	}
+	h.startSession(c, user, req.AuthMode, req.CartToken)
+}
+
+// LoginHistory lấy các lần đăng nhập gần nhất của user hiện tại cùng thiết bị, IP và quốc gia
+func (h *AuthHandler) LoginHistory(c *gin.Context) {
+	history, err := h.logins.History(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching login history", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Login history retrieved successfully", history))
+}
 
-	tokenString, err := token.SignedString([]byte(h.jwtSecret))
+// startSession phát token cho user đã xác thực (trong body hoặc cookie theo authMode), gộp giỏ hàng của
+// khách và trả về response đăng nhập thành công
+func (h *AuthHandler) startSession(c *gin.Context, user *models.User, authMode, cartToken string) {
+	// Tạo JWT token
+	tokenString, claims, err := h.tokens.Issue(user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Error generating token", err.Error()))
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error generating token", err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, utils.NewResponse(http.StatusOK, "Login successful", gin.H{
-		"token": tokenString,
+	h.bus.Publish(c.Request.Context(), events.UserLoggedIn{UserID: user.ID, IP: c.ClientIP(), UserAgent: c.Request.UserAgent(), At: time.Now()})
+
+	data := gin.H{
 		"user": models.UserResponse{
 			ID:        user.ID,
 			Username:  user.Username,
 			Email:     user.Email,
 			FullName:  user.FullName,
 			Role:      user.Role,
+			Status:    user.Status,
 			CreatedAt: user.CreatedAt,
 		},
-	}))
+	}
+	mode := authMode
+	if mode == "" {
+		mode = h.tokens.Config().Mode
+	}
+	data["auth_mode"] = mode
+	if mode == token.ModeCookie {
+		data["csrf_token"] = setAuthCookies(c, h.tokens, tokenString, claims)
+	} else {
+		data["token"] = tokenString
+	}
+	resp := utils.NewResponse(c, http.StatusOK, "Login successful", data)
+
+	if merged := h.mergeGuestCart(c, user.ID, cartToken); merged != nil {
+		resp.Meta = gin.H{"cart_merge": merged}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// rehashPassword băm lại mật khẩu vừa xác thực khi hash đang lưu dùng thuật toán hoặc tham số cũ,
+// để hash chuyển dần sang cấu hình mới mà không bắt user đặt lại mật khẩu. Lỗi chỉ được log.
+func (h *AuthHandler) rehashPassword(c *gin.Context, user *models.User, plain string) {
+	if !h.hasher.NeedsRehash(user.Password) {
+		return
+	}
+	hashed, err := h.hasher.Hash(plain)
+	if err != nil {
+		log.Printf("Auth: failed to rehash password for user %d: %v", user.ID, err)
+		return
+	}
+	// Chỉ ghi đè khi mật khẩu chưa bị đổi bởi một request khác trong lúc đó
+	err = h.db.WithContext(c.Request.Context()).Model(&models.User{}).
+		Where("id = ? AND password = ?", user.ID, user.Password).
+		Update("password", hashed).Error
+	if err != nil {
+		log.Printf("Auth: failed to rehash password for user %d: %v", user.ID, err)
+	}
+}
+
+// mergeGuestCart gộp giỏ hàng của khách (token trong body, header X-Cart-Token, cookie cart_token hoặc
+// giỏ gắn với phiên visitor_id) vào giỏ của user rồi xóa cookie; lỗi gộp giỏ chỉ được log, không làm
+// hỏng đăng nhập/đăng ký
+func (h *AuthHandler) mergeGuestCart(c *gin.Context, userID uint, token string) *models.CartMergeResult {
+	if token == "" {
+		token = guestCartToken(c, h.jwtSecret)
+	}
+	// Giỏ gắn với phiên luôn được gỡ để lần đăng nhập sau trên cùng trình duyệt không gộp lại
+	visitorID, _ := visitor.FromContext(c)
+	sessionToken, err := h.visitors.TakeCartToken(c.Request.Context(), visitorID)
+	if err != nil {
+		log.Printf("Cart: failed to read guest cart of visitor session: %v", err)
+	}
+	if token == "" {
+		token = sessionToken
+	}
+	merged, err := h.carts.Merge(c.Request.Context(), userID, token)
+	if err != nil {
+		log.Printf("Cart: failed to merge guest cart for user %d: %v", userID, err)
+		return nil
+	}
+	clearCartCookie(c)
+	return merged
 }
 
 // ChangePassword handles the password change request
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	var req models.ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		// Handle validation errors
-		if validationErrors, ok := err.(validator.ValidationErrors); ok {
-			errors := make(map[string]string)
-			for _, e := range validationErrors {
-				field := e.Field()
-				switch field {
-				case "CurrentPassword":
-					if e.Tag() == "required" {
-						errors["current_password"] = "Current password is required."
-					}
-				case "NewPassword":
-					switch e.Tag() {
-					case "required":
-						errors["new_password"] = "New password is required."
-					case "min":
-						errors["new_password"] = fmt.Sprintf("New password must be at least %s characters long.", e.Param())
-					}
-				case "ConfirmNewPassword":
-					switch e.Tag() {
-					case "required":
-						errors["confirm_new_password"] = "Confirm password is required."
-					case "eqfield":
-						errors["confirm_new_password"] = "Confirm password must match new password."
-					}
-				}
-			}
-			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Validation failed", errors))
-			return
-		}
-		// Handle non-validation errors
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid request data", err.Error()))
+		validation.Respond(c, err)
 		return
 	}
 
 	// Get user ID from context (set by JWTMiddleware)
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, utils.NewErrorResponse(http.StatusUnauthorized, "User not authenticated", ""))
+		c.JSON(http.StatusUnauthorized, utils.NewErrorResponse(c, http.StatusUnauthorized, "User not authenticated", ""))
 		return
 	}
 
 	// Get user from database
 	var user models.User
 	if err := h.db.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, utils.NewErrorResponse(http.StatusNotFound, "User not found", ""))
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "User not found", ""))
 		return
 	}
 
 	// Verify current password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Current password is incorrect", ""))
+	if err := password.Compare(user.Password, req.CurrentPassword); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Current password is incorrect", ""))
+		return
+	}
+
+	if violations := h.passwords.Check(c.Request.Context(), req.NewPassword, user.Username, user.Email, user.FullName); len(violations) > 0 {
+		respondPasswordPolicy(c, "new_password", violations)
 		return
 	}
 
 	// Hash new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	hashedPassword, err := h.hasher.Hash(req.NewPassword)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Failed to hash new password", err.Error()))
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Failed to hash new password", err.Error()))
 		return
 	}
 
-	// Update password in database
-	if err := h.db.Model(&user).Update("password", string(hashedPassword)).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Failed to update password", err.Error()))
+	// Update password in database; tăng token_version để đăng xuất mọi phiên khác
+	if err := h.db.Model(&user).Updates(map[string]interface{}{
+		"password":      hashedPassword,
+		"token_version": gorm.Expr("token_version + 1"),
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Failed to update password", err.Error()))
 		return
 	}
+	h.bus.Publish(c.Request.Context(), events.PasswordChanged{UserID: user.ID, IP: c.ClientIP(), At: time.Now()})
+
+	// Cấp token mới cho phiên hiện tại để user không phải đăng nhập lại
+	if user.TokenVersion, err = h.users.TokenVersion(c.Request.Context(), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error generating token", err.Error()))
+		return
+	}
+	tokenString, claims, err := h.tokens.Issue(&user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error generating token", err.Error()))
+		return
+	}
+	if c.GetString(middleware.AuthModeKey) == token.ModeCookie {
+		csrf := setAuthCookies(c, h.tokens, tokenString, claims)
+		c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Password changed successfully", gin.H{"csrf_token": csrf}))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Password changed successfully", gin.H{"token": tokenString}))
+}
+
+// Logout thu hồi token đang dùng (đưa jti vào denylist tới khi token hết hạn)
+func (h *AuthHandler) Logout(c *gin.Context) {
+	claims := c.MustGet(middleware.ClaimsKey).(*token.Claims)
+	err := h.revoked.Revoke(c.Request.Context(), &models.RevokedToken{
+		JTI:       claims.ID,
+		UserID:    claims.UserID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error logging out", err.Error()))
+		return
+	}
+	if c.GetString(middleware.AuthModeKey) == token.ModeCookie {
+		clearAuthCookies(c, h.tokens)
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Logged out successfully", nil))
+}
+
+// LogoutAll tăng token_version của user để mọi token đã cấp (kể cả token đang dùng) hết hiệu lực,
+// ví dụ khi nghi ngờ tài khoản bị xâm nhập
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if _, err := h.users.BumpTokenVersion(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error logging out", err.Error()))
+		return
+	}
+	h.bus.Publish(c.Request.Context(), events.LoggedOutEverywhere{UserID: userID, IP: c.ClientIP(), At: time.Now()})
+	if c.GetString(middleware.AuthModeKey) == token.ModeCookie {
+		clearAuthCookies(c, h.tokens)
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Logged out from all devices", nil))
+}
 
-	c.JSON(http.StatusOK, utils.NewResponse(http.StatusOK, "Password changed successfully", nil))
+// respondPasswordPolicy trả về 400 với danh sách điều kiện mật khẩu chưa đáp ứng, đặt dưới tên field
+func respondPasswordPolicy(c *gin.Context, field string, violations []password.Violation) {
+	details := map[string][]password.Violation{field: password.Localize(i18n.FromContext(c), violations)}
+	c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Password does not meet the requirements", details))
 }
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CartCookieName là cookie chứa token giỏ hàng của khách đã được ký, dành cho client trình duyệt
+// không tự lưu header X-Cart-Token
+const CartCookieName = "cart_token"
+
+// signCartToken ký token giỏ bằng HMAC-SHA256; giá trị cookie có dạng <token>.<chữ ký>
+func signCartToken(secret, token string) string {
+	mac := hmac.New(sha256.New, []byte("cart:"+secret))
+	mac.Write([]byte(token))
+	return token + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyCartCookie trả về token trong cookie nếu chữ ký hợp lệ
+func verifyCartCookie(secret, value string) (string, bool) {
+	i := strings.LastIndexByte(value, '.')
+	if i <= 0 {
+		return "", false
+	}
+	token := value[:i]
+	if !hmac.Equal([]byte(value), []byte(signCartToken(secret, token))) {
+		return "", false
+	}
+	return token, true
+}
+
+// guestCartToken đọc token giỏ của khách từ header X-Cart-Token, nếu không có thì từ cookie đã ký.
+// Cookie bị sửa hoặc ký bằng secret khác được bỏ qua như khi không có giỏ.
+func guestCartToken(c *gin.Context, secret string) string {
+	if token := c.GetHeader(CartTokenHeader); token != "" {
+		return token
+	}
+	value, err := c.Cookie(CartCookieName)
+	if err != nil || value == "" {
+		return ""
+	}
+	token, ok := verifyCartCookie(secret, value)
+	if !ok {
+		return ""
+	}
+	return token
+}
+
+// setCartCookie lưu token giỏ của khách vào cookie HttpOnly, hết hạn cùng giỏ (CART_EXPIRY)
+func setCartCookie(c *gin.Context, secret, token string) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(CartCookieName, signCartToken(secret, token), int(services.CartExpiry().Seconds()), "/", "", utils.IsSecureRequest(c), true)
+}
+
+// clearCartCookie xóa cookie giỏ của khách sau khi giỏ đã được gộp vào tài khoản
+func clearCartCookie(c *gin.Context) {
+	if _, err := c.Cookie(CartCookieName); err != nil {
+		return
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(CartCookieName, "", -1, "/", "", utils.IsSecureRequest(c), true)
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/NgTruong624/project_backend/internal/visitor"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CartTokenHeader là header client dùng để gửi và nhận token giỏ hàng của khách
+const CartTokenHeader = "X-Cart-Token"
+
+type CartHandler struct {
+	service *services.CartService
+	// cookieSecret dùng để ký cookie cart_token
+	cookieSecret string
+	visitors     *visitor.Tracker
+}
+
+func NewCartHandler(db *gorm.DB, cookieSecret string, visitors *visitor.Tracker) *CartHandler {
+	return &CartHandler{
+		service:      services.NewCartService(db),
+		cookieSecret: cookieSecret,
+		visitors:     visitors,
+	}
+}
+
+// cartOwner xác định giỏ từ user đã đăng nhập, nếu không thì từ header X-Cart-Token hoặc cookie cart_token
+func (h *CartHandler) cartOwner(c *gin.Context) services.CartOwner {
+	if userID := c.GetUint("user_id"); userID != 0 {
+		return services.CartOwner{UserID: userID}
+	}
+	return services.CartOwner{Token: guestCartToken(c, h.cookieSecret)}
+}
+
+// GetCart lấy giỏ hàng hiện tại
+func (h *CartHandler) GetCart(c *gin.Context) {
+	cart, err := h.service.Get(c.Request.Context(), h.cartOwner(c))
+	if err != nil {
+		h.handleError(c, err, "Error fetching cart")
+		return
+	}
+	h.respond(c, http.StatusOK, "Cart retrieved successfully", cart)
+}
+
+// AddItem thêm sản phẩm vào giỏ; khách chưa có giỏ sẽ nhận cart token mới trong response
+func (h *CartHandler) AddItem(c *gin.Context) {
+	var req models.AddCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	cart, err := h.service.AddItem(c.Request.Context(), h.cartOwner(c), &req)
+	if err != nil {
+		h.handleError(c, err, "Error updating cart")
+		return
+	}
+	h.respond(c, http.StatusOK, "Item added to cart", cart)
+}
+
+// UpdateItem đổi số lượng một sản phẩm trong giỏ
+func (h *CartHandler) UpdateItem(c *gin.Context) {
+	productID, ok := parseCartProductID(c)
+	if !ok {
+		return
+	}
+	var req models.UpdateCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	cart, err := h.service.UpdateItem(c.Request.Context(), h.cartOwner(c), productID, req.Quantity)
+	if err != nil {
+		h.handleError(c, err, "Error updating cart")
+		return
+	}
+	h.respond(c, http.StatusOK, "Cart updated successfully", cart)
+}
+
+// RemoveItem xóa một sản phẩm khỏi giỏ
+func (h *CartHandler) RemoveItem(c *gin.Context) {
+	productID, ok := parseCartProductID(c)
+	if !ok {
+		return
+	}
+	cart, err := h.service.RemoveItem(c.Request.Context(), h.cartOwner(c), productID)
+	if err != nil {
+		h.handleError(c, err, "Error updating cart")
+		return
+	}
+	h.respond(c, http.StatusOK, "Item removed from cart", cart)
+}
+
+// Revalidate cập nhật giỏ theo giá và tồn kho hiện tại, đánh dấu các dòng đã thay đổi;
+// client nên gọi trước khi hiển thị bước thanh toán
+func (h *CartHandler) Revalidate(c *gin.Context) {
+	cart, err := h.service.Revalidate(c.Request.Context(), h.cartOwner(c))
+	if err != nil {
+		h.handleError(c, err, "Error revalidating cart")
+		return
+	}
+	h.respond(c, http.StatusOK, "Cart revalidated", cart)
+}
+
+// ClearCart xóa mọi sản phẩm trong giỏ
+func (h *CartHandler) ClearCart(c *gin.Context) {
+	if err := h.service.Clear(c.Request.Context(), h.cartOwner(c)); err != nil {
+		h.handleError(c, err, "Error updating cart")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Cart cleared successfully", nil))
+}
+
+func (h *CartHandler) respond(c *gin.Context, status int, message string, cart *models.CartResponse) {
+	if cart.Token != "" {
+		// Giỏ khách mới được tạo: gắn vào phiên của khách để vẫn gộp được khi đăng nhập nếu client
+		// không gửi lại token giỏ
+		if cart.Token != guestCartToken(c, h.cookieSecret) {
+			visitorID, _ := visitor.FromContext(c)
+			if err := h.visitors.SetCartToken(c.Request.Context(), visitorID, cart.Token); err != nil {
+				log.Printf("Cart: failed to link guest cart to visitor session: %v", err)
+			}
+		}
+		c.Header(CartTokenHeader, cart.Token)
+		setCartCookie(c, h.cookieSecret, cart.Token)
+	}
+	c.JSON(status, utils.NewResponse(c, status, message, cart))
+}
+
+func (h *CartHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrCartNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Cart not found", ""))
+	case errors.Is(err, services.ErrCartItemNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product is not in the cart", ""))
+	case errors.Is(err, services.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product not found", ""))
+	case errors.Is(err, services.ErrInsufficientStock):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Insufficient stock", ""))
+	case errors.Is(err, services.ErrProductUnavailable):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Product is not available for sale", ""))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
+
+func parseCartProductID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("product_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err.Error()))
+		return 0, false
+	}
+	return uint(id), true
+}
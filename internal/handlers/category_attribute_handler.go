@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CategoryAttributeHandler struct {
+	service *services.CategoryAttributeService
+}
+
+func NewCategoryAttributeHandler(db *gorm.DB) *CategoryAttributeHandler {
+	return &CategoryAttributeHandler{
+		service: services.NewCategoryAttributeService(db),
+	}
+}
+
+// ListCategoryAttributes lấy các thuộc tính khai báo cho một danh mục (Public)
+func (h *CategoryAttributeHandler) ListCategoryAttributes(c *gin.Context) {
+	h.list(c, c.Param("category"))
+}
+
+// AdminListCategoryAttributes lấy các thuộc tính đã khai báo, lọc theo ?category= (Admin only)
+func (h *CategoryAttributeHandler) AdminListCategoryAttributes(c *gin.Context) {
+	h.list(c, c.Query("category"))
+}
+
+func (h *CategoryAttributeHandler) list(c *gin.Context, category string) {
+	attributes, err := h.service.List(c.Request.Context(), category)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching category attributes", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Category attributes retrieved successfully", attributes))
+}
+
+// CreateCategoryAttribute khai báo thuộc tính cho danh mục (Admin only)
+func (h *CategoryAttributeHandler) CreateCategoryAttribute(c *gin.Context) {
+	var req models.CategoryAttributeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	attribute, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err, "Error creating category attribute")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Category attribute created successfully", attribute))
+}
+
+// UpdateCategoryAttribute sửa thuộc tính của danh mục (Admin only)
+func (h *CategoryAttributeHandler) UpdateCategoryAttribute(c *gin.Context) {
+	id, ok := parseCategoryAttributeID(c)
+	if !ok {
+		return
+	}
+	var req models.CategoryAttributeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	attribute, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleError(c, err, "Error updating category attribute")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Category attribute updated successfully", attribute))
+}
+
+// DeleteCategoryAttribute xóa thuộc tính của danh mục (Admin only)
+func (h *CategoryAttributeHandler) DeleteCategoryAttribute(c *gin.Context) {
+	id, ok := parseCategoryAttributeID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		h.handleError(c, err, "Error deleting category attribute")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Category attribute deleted successfully", nil))
+}
+
+func (h *CategoryAttributeHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrCategoryAttributeNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Category attribute not found", ""))
+	case errors.Is(err, services.ErrCategoryAttributeExists):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Category attribute already exists", err.Error()))
+	case errors.Is(err, services.ErrInvalidCategoryAttribute):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid category attribute", err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
+
+func parseCategoryAttributeID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid category attribute ID", err.Error()))
+		return 0, false
+	}
+	return uint(id), true
+}
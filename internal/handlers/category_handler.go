@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CategoryHandler struct {
+	service *services.CategoryService
+}
+
+func NewCategoryHandler(db *gorm.DB, bus *events.Bus) *CategoryHandler {
+	return &CategoryHandler{
+		service: services.NewCategoryService(db, bus),
+	}
+}
+
+// GetCategoryTree lấy cây danh mục kèm số sản phẩm đã đăng để dựng menu điều hướng (Public)
+func (h *CategoryHandler) GetCategoryTree(c *gin.Context) {
+	tree, err := h.service.Tree(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching category tree", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Category tree retrieved successfully", tree))
+}
+
+// ListCategories lấy mọi danh mục đã khai báo (Admin only)
+func (h *CategoryHandler) ListCategories(c *gin.Context) {
+	categories, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching categories", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Categories retrieved successfully", categories))
+}
+
+// CreateCategory khai báo danh mục (Admin only)
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req models.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	category, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err, "Error creating category")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Category created successfully", category))
+}
+
+// UpdateCategory sửa tên, danh mục cha hoặc thứ tự của danh mục (Admin only)
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	id, ok := parseCategoryID(c)
+	if !ok {
+		return
+	}
+	var req models.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	category, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleError(c, err, "Error updating category")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Category updated successfully", category))
+}
+
+// DeleteCategory xóa danh mục (Admin only)
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	id, ok := parseCategoryID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		h.handleError(c, err, "Error deleting category")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Category deleted successfully", nil))
+}
+
+func (h *CategoryHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Category not found", ""))
+	case errors.Is(err, services.ErrCategoryExists):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Category already exists", err.Error()))
+	case errors.Is(err, services.ErrInvalidCategory):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid category", err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
+
+func parseCategoryID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid category ID", err.Error()))
+		return 0, false
+	}
+	return uint(id), true
+}
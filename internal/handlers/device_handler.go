@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/notify"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DeviceHandler struct {
+	repo     *repository.NotificationRepository
+	notifier *notify.Dispatcher
+}
+
+func NewDeviceHandler(db *gorm.DB, notifier *notify.Dispatcher) *DeviceHandler {
+	return &DeviceHandler{
+		repo:     repository.NewNotificationRepository(db),
+		notifier: notifier,
+	}
+}
+
+// RegisterDevice đăng ký token push của thiết bị cho user hiện tại
+func (h *DeviceHandler) RegisterDevice(c *gin.Context) {
+	var req models.RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid request", err.Error()))
+		return
+	}
+
+	provider := req.Provider
+	if provider == "" {
+		provider = models.PushProviderFCM
+		if req.Platform == "ios" {
+			provider = models.PushProviderAPNs
+		}
+	}
+	device := &models.DeviceToken{
+		UserID:   c.GetUint("user_id"),
+		Token:    req.Token,
+		Platform: req.Platform,
+		Provider: provider,
+	}
+	if err := h.repo.UpsertDevice(c.Request.Context(), device); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error registering device", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Device registered successfully", device))
+}
+
+// ListDevices lấy các thiết bị đã đăng ký của user hiện tại
+func (h *DeviceHandler) ListDevices(c *gin.Context) {
+	devices, err := h.repo.ListDevices(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching devices", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Devices retrieved successfully", devices))
+}
+
+// DeleteDevice hủy đăng ký thiết bị (ví dụ khi đăng xuất trên app)
+func (h *DeviceHandler) DeleteDevice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid device ID", err.Error()))
+		return
+	}
+	found, err := h.repo.DeleteDevice(c.Request.Context(), c.GetUint("user_id"), uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error deleting device", err.Error()))
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Device not found", ""))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Device deleted successfully", nil))
+}
+
+// GetNotificationPreferences lấy lựa chọn kênh nhận (email, push, webhook) của user hiện tại cho từng loại
+// thông báo; loại chưa từng chỉnh có mọi kênh bật
+func (h *DeviceHandler) GetNotificationPreferences(c *gin.Context) {
+	prefs, err := h.preferences(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching notification preferences", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Notification preferences retrieved successfully", prefs))
+}
+
+// UpdateNotificationPreferences bật/tắt kênh nhận cho các loại thông báo của user hiện tại
+func (h *DeviceHandler) UpdateNotificationPreferences(c *gin.Context) {
+	var req models.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	for _, update := range req.Preferences {
+		if !notify.IsPreferenceEvent(update.Event) {
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Unknown notification event", update.Event))
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetUint("user_id")
+	prefs := make([]models.NotificationPreference, 0, len(req.Preferences))
+	for _, update := range req.Preferences {
+		pref, err := h.repo.GetPreference(ctx, userID, update.Event)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error updating notification preferences", err.Error()))
+			return
+		}
+		if update.Email != nil {
+			pref.Email = *update.Email
+		}
+		if update.Push != nil {
+			pref.Push = *update.Push
+		}
+		if update.Webhook != nil {
+			pref.Webhook = *update.Webhook
+		}
+		prefs = append(prefs, *pref)
+	}
+	if err := h.repo.SavePreferences(ctx, prefs); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error updating notification preferences", err.Error()))
+		return
+	}
+
+	all, err := h.preferences(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching notification preferences", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Notification preferences updated successfully", all))
+}
+
+// preferences trả về lựa chọn kênh cho mọi loại trong notify.PreferenceEvents, điền giá trị mặc định cho
+// loại chưa có dòng nào
+func (h *DeviceHandler) preferences(ctx context.Context, userID uint) ([]models.NotificationPreference, error) {
+	saved, err := h.repo.ListPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	byEvent := make(map[string]models.NotificationPreference, len(saved))
+	for _, pref := range saved {
+		byEvent[pref.Event] = pref
+	}
+	prefs := make([]models.NotificationPreference, 0, len(notify.PreferenceEvents))
+	for _, event := range notify.PreferenceEvents {
+		pref, ok := byEvent[event]
+		if !ok {
+			pref = models.NotificationPreference{UserID: userID, Event: event, Email: true, Push: true, Webhook: true}
+		}
+		prefs = append(prefs, pref)
+	}
+	return prefs, nil
+}
+
+// SendTestNotification gửi push thử tới các thiết bị của user hiện tại
+func (h *DeviceHandler) SendTestNotification(c *gin.Context) {
+	h.notifier.Notify(c.Request.Context(), notify.Notification{
+		UserID: c.GetUint("user_id"),
+		Event:  notify.EventTest,
+		Title:  "Test notification",
+		Body:   "Push notifications are working.",
+	})
+	c.JSON(http.StatusAccepted, utils.NewResponse(c, http.StatusAccepted, "Test notification queued", nil))
+}
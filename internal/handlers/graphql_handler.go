@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/graphql"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type GraphQLHandler struct {
+	server *graphql.Server
+}
+
+func NewGraphQLHandler(db *gorm.DB) *GraphQLHandler {
+	return &GraphQLHandler{
+		// GRAPHQL_MAX_DEPTH, GRAPHQL_MAX_COMPLEXITY: giới hạn độ lồng và tổng limit các danh sách của một truy vấn
+		server: graphql.NewServer(db, graphql.Limits{
+			MaxDepth:      config.Int("GRAPHQL_MAX_DEPTH", 6),
+			MaxComplexity: config.Int("GRAPHQL_MAX_COMPLEXITY", 1000),
+		}),
+	}
+}
+
+// Query xử lý truy vấn GraphQL cho catalog (Public)
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req graphql.Request
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if vars := c.Query("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				c.JSON(http.StatusBadRequest, graphql.ErrorResponse("Invalid variables: "+err.Error()))
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, graphql.ErrorResponse("Invalid request: "+err.Error()))
+		return
+	}
+
+	if req.Query == "" {
+		c.JSON(http.StatusBadRequest, graphql.ErrorResponse("Query is required"))
+		return
+	}
+
+	c.JSON(http.StatusOK, h.server.Exec(c.Request.Context(), req))
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/buildinfo"
+	"github.com/NgTruong624/project_backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// readyTimeout là thời gian tối đa chờ database trả lời khi kiểm tra sẵn sàng
+const readyTimeout = 2 * time.Second
+
+// HealthHandler phục vụ kiểm tra sẵn sàng (/readyz) và số liệu Prometheus (/metrics)
+type HealthHandler struct {
+	db       *gorm.DB
+	failover *database.Failover
+}
+
+func NewHealthHandler(db *gorm.DB, failover *database.Failover) *HealthHandler {
+	return &HealthHandler{db: db, failover: failover}
+}
+
+// Ready trả về 200 khi database trả lời ping, 503 nếu không; kèm thống kê pool kết nối. Replica không
+// trả lời chỉ làm trạng thái thành "degraded" vì thao tác ghi vẫn hoạt động.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyTimeout)
+	defer cancel()
+
+	status, code := "ready", http.StatusOK
+	dbInfo := gin.H{"pool": poolStats(sqlDB.Stats())}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		status, code = "unavailable", http.StatusServiceUnavailable
+		dbInfo["error"] = err.Error()
+	}
+	if h.failover != nil {
+		dbInfo["failover"] = h.failover.GetStats()
+	}
+	if replicas := database.Replicas(h.db); len(replicas) > 0 {
+		replicaInfo := make([]gin.H, len(replicas))
+		for i, replica := range replicas {
+			replicaInfo[i] = gin.H{"address": replica.Address, "pool": poolStats(replica.DB.Stats())}
+			if err := replica.DB.PingContext(ctx); err != nil {
+				replicaInfo[i]["error"] = err.Error()
+				if code == http.StatusOK {
+					status = "degraded"
+				}
+			}
+		}
+		dbInfo["replicas"] = replicaInfo
+	}
+	c.JSON(code, gin.H{"status": status, "build": buildinfo.Get(), "database": dbInfo})
+}
+
+// Metrics xuất thống kê pool kết nối database (và của từng read replica) theo định dạng văn bản của Prometheus
+func (h *HealthHandler) Metrics(c *gin.Context) {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		c.String(http.StatusInternalServerError, "# %s\n", err.Error())
+		return
+	}
+	s := sqlDB.Stats()
+
+	var b strings.Builder
+	metric := func(name, kind, help string, value float64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", name, help, name, kind, name, value)
+	}
+	metric("db_pool_max_open_connections", "gauge", "Maximum number of open connections to the database.", float64(s.MaxOpenConnections))
+	metric("db_pool_open_connections", "gauge", "Number of established connections, in use and idle.", float64(s.OpenConnections))
+	metric("db_pool_in_use_connections", "gauge", "Number of connections currently in use.", float64(s.InUse))
+	metric("db_pool_idle_connections", "gauge", "Number of idle connections.", float64(s.Idle))
+	metric("db_pool_wait_count_total", "counter", "Total number of connections waited for.", float64(s.WaitCount))
+	metric("db_pool_wait_duration_seconds_total", "counter", "Total time blocked waiting for a new connection.", s.WaitDuration.Seconds())
+	metric("db_pool_max_idle_closed_total", "counter", "Total number of connections closed due to the idle connection limit.", float64(s.MaxIdleClosed))
+	metric("db_pool_max_idle_time_closed_total", "counter", "Total number of connections closed due to the idle time limit.", float64(s.MaxIdleTimeClosed))
+	metric("db_pool_max_lifetime_closed_total", "counter", "Total number of connections closed due to the lifetime limit.", float64(s.MaxLifetimeClosed))
+	if h.failover != nil {
+		metric("db_failovers_total", "counter", "Total number of database failovers.", float64(h.failover.GetStats().Failovers))
+	}
+	if slow := database.SlowQueries(h.db); slow != nil {
+		metric("db_slow_queries_total", "counter", "Total number of queries slower than DB_SLOW_QUERY_THRESHOLD.", float64(slow.Total()))
+	}
+	if replicas := database.Replicas(h.db); len(replicas) > 0 {
+		replicaMetric := func(name, kind, help string, value func(sql.DBStats) float64) {
+			fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+			for _, replica := range replicas {
+				fmt.Fprintf(&b, "%s{replica=%q} %g\n", name, replica.Address, value(replica.DB.Stats()))
+			}
+		}
+		replicaMetric("db_replica_pool_open_connections", "gauge", "Number of established connections to the read replica.",
+			func(s sql.DBStats) float64 { return float64(s.OpenConnections) })
+		replicaMetric("db_replica_pool_in_use_connections", "gauge", "Number of read replica connections currently in use.",
+			func(s sql.DBStats) float64 { return float64(s.InUse) })
+		replicaMetric("db_replica_pool_idle_connections", "gauge", "Number of idle read replica connections.",
+			func(s sql.DBStats) float64 { return float64(s.Idle) })
+		replicaMetric("db_replica_pool_wait_count_total", "counter", "Total number of read replica connections waited for.",
+			func(s sql.DBStats) float64 { return float64(s.WaitCount) })
+	}
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
+
+// poolStats chuyển sql.DBStats sang dạng JSON dễ đọc (thời gian chờ tính bằng mili giây)
+func poolStats(s sql.DBStats) gin.H {
+	return gin.H{
+		"max_open_connections": s.MaxOpenConnections,
+		"open_connections":     s.OpenConnections,
+		"in_use":               s.InUse,
+		"idle":                 s.Idle,
+		"wait_count":           s.WaitCount,
+		"wait_duration_ms":     s.WaitDuration.Milliseconds(),
+		"max_idle_closed":      s.MaxIdleClosed,
+		"max_idle_time_closed": s.MaxIdleTimeClosed,
+		"max_lifetime_closed":  s.MaxLifetimeClosed,
+	}
+}
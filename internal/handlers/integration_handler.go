@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// IntegrationHandler phục vụ các endpoint cho hệ thống kho bên ngoài, xác thực bằng API key
+type IntegrationHandler struct {
+	service *services.InventorySyncService
+}
+
+func NewIntegrationHandler(db *gorm.DB, bus *events.Bus) *IntegrationHandler {
+	return &IntegrationHandler{
+		service: services.NewInventorySyncService(db, bus),
+	}
+}
+
+// SyncInventory đặt tồn kho của nhiều sản phẩm theo SKU, trả về kết quả từng dòng
+func (h *IntegrationHandler) SyncInventory(c *gin.Context) {
+	var req models.InventorySyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	result := h.service.SyncStock(c.Request.Context(), req.Items)
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Inventory synchronized", result))
+}
+
+// SyncPrices đặt giá gốc của nhiều sản phẩm theo SKU, trả về kết quả từng dòng
+func (h *IntegrationHandler) SyncPrices(c *gin.Context) {
+	var req models.PriceSyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	result := h.service.SyncPrices(c.Request.Context(), req.Items)
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Prices synchronized", result))
+}
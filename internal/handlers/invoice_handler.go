@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/einvoice"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/tax"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type InvoiceHandler struct {
+	service *services.InvoiceService
+}
+
+func NewInvoiceHandler(db *gorm.DB, config einvoice.Config, taxConfig tax.Config) *InvoiceHandler {
+	return &InvoiceHandler{
+		service: services.NewInvoiceService(db, config, taxConfig),
+	}
+}
+
+// CreateInvoice lập hóa đơn nháp từ danh sách sản phẩm (Admin only)
+func (h *InvoiceHandler) CreateInvoice(c *gin.Context) {
+	var req models.CreateInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+
+	invoice, err := h.service.CreateDraft(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, services.ErrProductNotFound) {
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Product not found", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error creating invoice", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Invoice created successfully", invoice))
+}
+
+// ListInvoices lấy danh sách hóa đơn, lọc theo trạng thái, ký hiệu, kỳ và người mua (Admin only)
+func (h *InvoiceHandler) ListInvoices(c *gin.Context) {
+	var query models.InvoiceQueryParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+
+	invoices, total, err := h.service.List(c.Request.Context(), &query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching invoices", err.Error()))
+		return
+	}
+
+	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	filters := map[string]interface{}{}
+	if query.Status != "" {
+		filters["status"] = query.Status
+	}
+	if query.Series != "" {
+		filters["series"] = query.Series
+	}
+	if query.FiscalPeriod != 0 {
+		filters["fiscal_period"] = query.FiscalPeriod
+	}
+	if query.UserID != 0 {
+		filters["user_id"] = query.UserID
+	}
+	c.JSON(http.StatusOK, utils.NewPaginatedResponse(
+		c, http.StatusOK, "Invoices retrieved successfully", invoices,
+		query.Page, totalPages, total, query.Limit, filters,
+	))
+}
+
+// GetInvoice lấy chi tiết hóa đơn (Admin only)
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	invoice, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Error fetching invoice")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Invoice retrieved successfully", invoice))
+}
+
+// IssueInvoice phát hành hóa đơn nháp và cấp số hóa đơn (Admin only)
+func (h *InvoiceHandler) IssueInvoice(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	invoice, err := h.service.Issue(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Error issuing invoice")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Invoice issued successfully", invoice))
+}
+
+// CancelInvoice hủy hóa đơn đã phát hành (Admin only)
+func (h *InvoiceHandler) CancelInvoice(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	var req models.CancelInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	invoice, err := h.service.Cancel(c.Request.Context(), id, req.Reason)
+	if err != nil {
+		h.handleError(c, err, "Error cancelling invoice")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Invoice cancelled successfully", invoice))
+}
+
+// DeleteInvoice xóa hóa đơn nháp (Admin only)
+func (h *InvoiceHandler) DeleteInvoice(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteDraft(c.Request.Context(), id); err != nil {
+		h.handleError(c, err, "Error deleting invoice")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Invoice deleted successfully", nil))
+}
+
+// ExportInvoice xuất hóa đơn điện tử dạng XML (mặc định) hoặc JSON (?format=json) (Admin only)
+func (h *InvoiceHandler) ExportInvoice(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	invoice, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Error fetching invoice")
+		return
+	}
+
+	format := c.DefaultQuery("format", einvoice.FormatXML)
+	if format != einvoice.FormatXML && format != einvoice.FormatJSON {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Unsupported export format", "format must be xml or json"))
+		return
+	}
+	data, contentType, err := einvoice.Export(invoice, h.service.Config().Seller, format)
+	if err != nil {
+		if errors.Is(err, einvoice.ErrNotIssued) {
+			c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Invoice has not been issued", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error exporting invoice", err.Error()))
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", invoice.Series, invoice.Number, format)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, contentType+"; charset=utf-8", data)
+}
+
+func (h *InvoiceHandler) parseID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid invoice ID", err.Error()))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *InvoiceHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvoiceNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Invoice not found", ""))
+	case errors.Is(err, services.ErrInvoiceNotDraft), errors.Is(err, services.ErrInvoiceNotIssued):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Invoice status does not allow this operation", err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
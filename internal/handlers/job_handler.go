@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type JobHandler struct {
+	repo *repository.JobRepository
+}
+
+func NewJobHandler(db *gorm.DB) *JobHandler {
+	return &JobHandler{
+		repo: repository.NewJobRepository(db),
+	}
+}
+
+// ListJobs lấy danh sách job nền, lọc theo trạng thái và loại (Admin only)
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	var query models.JobQueryParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+
+	jobs, total, err := h.repo.GetAll(c.Request.Context(), &query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching jobs", err.Error()))
+		return
+	}
+
+	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	filters := map[string]interface{}{}
+	if query.Status != "" {
+		filters["status"] = query.Status
+	}
+	if query.Type != "" {
+		filters["type"] = query.Type
+	}
+	c.JSON(http.StatusOK, utils.NewPaginatedResponse(
+		c, http.StatusOK, "Jobs retrieved successfully", jobs,
+		query.Page, totalPages, total, query.Limit, filters,
+	))
+}
+
+// GetJobStats đếm số job theo trạng thái (Admin only)
+func (h *JobHandler) GetJobStats(c *gin.Context) {
+	counts, err := h.repo.CountByStatus(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching job stats", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Job stats retrieved successfully", counts))
+}
+
+// RetryJob đưa một job thất bại trở lại hàng đợi (Admin only)
+func (h *JobHandler) RetryJob(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid job ID", err.Error()))
+		return
+	}
+	job, err := h.repo.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Job not found", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching job", err.Error()))
+		return
+	}
+	if job.Status != models.JobFailed {
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Only failed jobs can be retried", "Job status is "+job.Status))
+		return
+	}
+	if err := h.repo.Retry(c.Request.Context(), job.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error retrying job", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Job queued for retry", nil))
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxKPIRangeDays giới hạn khoảng ngày của một truy vấn hoặc một lần tính lại KPI
+const maxKPIRangeDays = 366
+
+type KPIHandler struct {
+	repo *repository.KPIRepository
+}
+
+func NewKPIHandler(db *gorm.DB) *KPIHandler {
+	return &KPIHandler{
+		repo: repository.NewKPIRepository(db),
+	}
+}
+
+// GetKPIs trả về KPI theo ngày đã được tổng hợp sẵn cùng tổng của khoảng ngày (Admin only).
+// Mặc định là 30 ngày gần nhất; số liệu của hôm nay có độ trễ tối đa bằng chu kỳ của tác vụ kpi_rollup.
+func (h *KPIHandler) GetKPIs(c *gin.Context) {
+	from, to, ok := parseKPIRange(c)
+	if !ok {
+		return
+	}
+
+	days, err := h.repo.GetRange(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching KPIs", err.Error()))
+		return
+	}
+
+	summary := models.KPISummary{
+		From: from.Format("2006-01-02"),
+		To:   to.Format("2006-01-02"),
+		Days: days,
+	}
+	for _, d := range days {
+		summary.Orders += d.Orders
+		summary.Revenue += d.Revenue
+		summary.NewCustomers += d.NewCustomers
+	}
+	if summary.Orders > 0 {
+		summary.AverageOrderValue = math.Round(summary.Revenue/float64(summary.Orders)*100) / 100
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "KPIs retrieved successfully", summary))
+}
+
+// RebuildKPIs tính lại KPI cho khoảng ngày, dùng để backfill hoặc sửa số liệu (Admin only)
+func (h *KPIHandler) RebuildKPIs(c *gin.Context) {
+	from, to, ok := parseKPIRange(c)
+	if !ok {
+		return
+	}
+
+	days, err := h.repo.Rollup(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error rebuilding KPIs", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "KPIs rebuilt successfully", gin.H{
+		"from": from.Format("2006-01-02"),
+		"to":   to.Format("2006-01-02"),
+		"days": days,
+	}))
+}
+
+// parseKPIRange đọc from/to (YYYY-MM-DD) từ query; mặc định là 30 ngày gần nhất
+func parseKPIRange(c *gin.Context) (time.Time, time.Time, bool) {
+	return parseDateRange(c, 30, maxKPIRangeDays)
+}
+
+// parseDateRange đọc from/to (YYYY-MM-DD, tính cả hai đầu) từ query. Mặc định là defaultDays ngày
+// kết thúc hôm nay (theo KPILocation), hoặc kết thúc ở to nếu chỉ có to; khoảng tối đa maxDays ngày.
+func parseDateRange(c *gin.Context, defaultDays, maxDays int) (time.Time, time.Time, bool) {
+	today := time.Now().In(repository.KPILocation)
+	to := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, 1-defaultDays)
+
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid date range", "to must be in YYYY-MM-DD format"))
+			return time.Time{}, time.Time{}, false
+		}
+		to = t
+		from = to.AddDate(0, 0, 1-defaultDays)
+	}
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid date range", "from must be in YYYY-MM-DD format"))
+			return time.Time{}, time.Time{}, false
+		}
+		from = t
+	}
+
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid date range", "from cannot be after to"))
+		return time.Time{}, time.Time{}, false
+	}
+	if to.Sub(from) >= time.Duration(maxDays)*24*time.Hour {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid date range", fmt.Sprintf("range cannot exceed %d days", maxDays)))
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
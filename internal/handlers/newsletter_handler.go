@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/notify"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type NewsletterHandler struct {
+	service *services.NewsletterService
+}
+
+func NewNewsletterHandler(db *gorm.DB, notifier *notify.Dispatcher) *NewsletterHandler {
+	return &NewsletterHandler{
+		service: services.NewNewsletterService(db, notifier),
+	}
+}
+
+// Subscribe đăng ký nhận bản tin và gửi email xác nhận (public). Response giống nhau dù địa chỉ đã đăng ký hay
+// chưa để không lộ danh sách người nhận.
+func (h *NewsletterHandler) Subscribe(c *gin.Context) {
+	var req models.NewsletterSubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	if err := h.service.Subscribe(c.Request.Context(), req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error subscribing to newsletter", err.Error()))
+		return
+	}
+	c.JSON(http.StatusAccepted, utils.NewResponse(c, http.StatusAccepted, "Please check your inbox to confirm your subscription", nil))
+}
+
+// ConfirmSubscription xác nhận đăng ký bằng token trong email xác nhận (public)
+func (h *NewsletterHandler) ConfirmSubscription(c *gin.Context) {
+	var req models.NewsletterTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	subscriber, err := h.service.Confirm(c.Request.Context(), req.Token)
+	if err != nil {
+		h.handleTokenError(c, err, "Error confirming newsletter subscription")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Newsletter subscription confirmed", subscriber))
+}
+
+// Unsubscribe hủy đăng ký bằng token trong link hủy đăng ký của bản tin (public)
+func (h *NewsletterHandler) Unsubscribe(c *gin.Context) {
+	var req models.NewsletterTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	subscriber, err := h.service.Unsubscribe(c.Request.Context(), req.Token)
+	if err != nil {
+		h.handleTokenError(c, err, "Error unsubscribing from newsletter")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "You have been unsubscribed from the newsletter", subscriber))
+}
+
+// ExportSubscribers xuất các địa chỉ đã xác nhận ra CSV kèm link hủy đăng ký để đưa vào hệ thống gửi bản tin
+// (Admin only)
+func (h *NewsletterHandler) ExportSubscribers(c *gin.Context) {
+	filename := fmt.Sprintf("newsletter-subscribers-%s.csv", time.Now().Format("2006-01-02"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"email", "confirmed_at", "unsubscribe_url"})
+	err := h.service.ExportConfirmed(c.Request.Context(), func(batch []models.NewsletterSubscriber) error {
+		for i := range batch {
+			confirmedAt := ""
+			if batch[i].ConfirmedAt != nil {
+				confirmedAt = batch[i].ConfirmedAt.UTC().Format(time.RFC3339)
+			}
+			w.Write([]string{batch[i].Email, confirmedAt, h.service.UnsubscribeLink(&batch[i])})
+		}
+		w.Flush()
+		return w.Error()
+	})
+	w.Flush()
+	if err != nil {
+		// Header và một phần dữ liệu đã được gửi, chỉ còn cách ghi log và cắt ngang response
+		log.Printf("Newsletter: export failed: %v", err)
+		c.Abort()
+	}
+}
+
+func (h *NewsletterHandler) handleTokenError(c *gin.Context, err error, message string) {
+	if errors.Is(err, services.ErrNewsletterTokenInvalid) {
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Newsletter link is invalid or expired", ""))
+		return
+	}
+	c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+}
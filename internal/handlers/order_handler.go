@@ -0,0 +1,512 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/payment"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/shipping"
+	"github.com/NgTruong624/project_backend/internal/tax"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type OrderHandler struct {
+	orders   *services.OrderService
+	payments *services.PaymentService
+	carts    *services.CartService
+}
+
+func NewOrderHandler(db *gorm.DB, gateways payment.Gateways, calculator *shipping.Calculator, taxConfig tax.Config, bus *events.Bus) *OrderHandler {
+	return &OrderHandler{
+		orders:   services.NewOrderService(db, calculator, taxConfig, bus),
+		payments: services.NewPaymentService(db, gateways, bus),
+		carts:    services.NewCartService(db),
+	}
+}
+
+// Checkout đặt hàng từ giỏ của user hiện tại giao tới một địa chỉ trong sổ địa chỉ;
+// đơn ở trạng thái chờ thanh toán
+func (h *OrderHandler) Checkout(c *gin.Context) {
+	var req models.CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	order, err := h.orders.Checkout(c.Request.Context(), c.GetUint("user_id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Error placing order")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Order placed successfully", order))
+}
+
+// QuoteShipping báo giá vận chuyển cho giỏ hàng hiện tại tới một địa chỉ trong sổ địa chỉ
+func (h *OrderHandler) QuoteShipping(c *gin.Context) {
+	var req models.ShippingQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	quote, err := h.orders.QuoteShipping(c.Request.Context(), c.GetUint("user_id"), req.AddressID)
+	if err != nil {
+		h.handleError(c, err, "Error quoting shipping")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Shipping options retrieved successfully", quote))
+}
+
+// GetOrder lấy đơn hàng của user hiện tại
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	id, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+	order, err := h.orders.Get(c.Request.Context(), c.GetUint("user_id"), id)
+	if err != nil {
+		h.handleError(c, err, "Error fetching order")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Order retrieved successfully", order))
+}
+
+// ListMyOrders lấy các đơn hàng của user hiện tại, mới nhất trước, lọc tùy chọn theo trạng thái
+func (h *OrderHandler) ListMyOrders(c *gin.Context) {
+	var query models.OrderQueryParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+
+	orders, total, err := h.orders.ListByUser(c.Request.Context(), c.GetUint("user_id"), &query)
+	if err != nil {
+		h.handleError(c, err, "Error fetching orders")
+		return
+	}
+	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	filters := map[string]interface{}{}
+	if query.Status != "" {
+		filters["status"] = query.Status
+	}
+	c.JSON(http.StatusOK, utils.NewPaginatedResponse(
+		c, http.StatusOK, "Orders retrieved successfully", orders,
+		query.Page, totalPages, total, query.Limit, filters,
+	))
+}
+
+// Reorder thêm lại các sản phẩm của một đơn cũ vào giỏ theo giá và tồn kho hiện tại, báo các dòng
+// bị giảm số lượng, đổi giá hoặc được thay bằng sản phẩm khác
+func (h *OrderHandler) Reorder(c *gin.Context) {
+	id, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+	var req models.ReorderRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			validation.Respond(c, err)
+			return
+		}
+	}
+	result, err := h.carts.Reorder(c.Request.Context(), c.GetUint("user_id"), id, &req)
+	if err != nil {
+		h.handleError(c, err, "Error reordering")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Order items added to cart", result))
+}
+
+// CancelOrder hủy đơn còn chờ thanh toán của user hiện tại
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	id, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+	order, err := h.orders.Cancel(c.Request.Context(), c.GetUint("user_id"), id)
+	if err != nil {
+		h.handleError(c, err, "Error cancelling order")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Order cancelled successfully", order))
+}
+
+// GetOrderHistory lấy lịch sử trạng thái đơn của user hiện tại
+func (h *OrderHandler) GetOrderHistory(c *gin.Context) {
+	h.orderHistory(c, c.GetUint("user_id"))
+}
+
+// AdminGetOrderHistory lấy lịch sử trạng thái của một đơn bất kỳ (Admin only)
+func (h *OrderHandler) AdminGetOrderHistory(c *gin.Context) {
+	h.orderHistory(c, 0)
+}
+
+func (h *OrderHandler) orderHistory(c *gin.Context, userID uint) {
+	id, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+	history, err := h.orders.History(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err, "Error fetching order history")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Order history retrieved successfully", history))
+}
+
+// ListOrders lấy danh sách đơn hàng lọc theo trạng thái, khách, khoảng ngày đặt và tổng tiền (Admin only)
+func (h *OrderHandler) ListOrders(c *gin.Context) {
+	var query models.AdminOrderQueryParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+	// end_date là cả ngày cuối
+	if !query.EndDate.IsZero() {
+		query.EndDate = query.EndDate.Add(24*time.Hour - time.Second)
+	}
+	if !query.StartDate.IsZero() && !query.EndDate.IsZero() && query.StartDate.After(query.EndDate) {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid date range", "start_date cannot be after end_date"))
+		return
+	}
+	if query.MinTotal > 0 && query.MaxTotal > 0 && query.MinTotal > query.MaxTotal {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid total range", "min_total cannot be greater than max_total"))
+		return
+	}
+
+	orders, total, err := h.orders.AdminList(c.Request.Context(), &query)
+	if err != nil {
+		h.handleError(c, err, "Error fetching orders")
+		return
+	}
+
+	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	filters := map[string]interface{}{}
+	if query.Status != "" {
+		filters["status"] = query.Status
+	}
+	if query.UserID != 0 {
+		filters["user_id"] = query.UserID
+	}
+	if query.Customer != "" {
+		filters["customer"] = query.Customer
+	}
+	if !query.StartDate.IsZero() {
+		filters["start_date"] = query.StartDate.Format("2006-01-02")
+	}
+	if !query.EndDate.IsZero() {
+		filters["end_date"] = query.EndDate.Format("2006-01-02")
+	}
+	if query.MinTotal > 0 {
+		filters["min_total"] = query.MinTotal
+	}
+	if query.MaxTotal > 0 {
+		filters["max_total"] = query.MaxTotal
+	}
+	if query.SortBy != "" {
+		filters["sort_by"] = query.SortBy
+		filters["order"] = query.Order
+	}
+	c.JSON(http.StatusOK, utils.NewPaginatedResponse(
+		c, http.StatusOK, "Orders retrieved successfully", orders,
+		query.Page, totalPages, total, query.Limit, filters,
+	))
+}
+
+// AdminGetOrder lấy chi tiết một đơn bất kỳ kèm khách, thanh toán và giao hàng (Admin only)
+func (h *OrderHandler) AdminGetOrder(c *gin.Context) {
+	id, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+	order, err := h.orders.AdminGet(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Error fetching order")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Order retrieved successfully", order))
+}
+
+// BulkUpdateOrderStatus chuyển nhiều đơn sang fulfilled, delivered hoặc cancelled; kết quả từng đơn nằm
+// trong results, đơn lỗi không chặn các đơn khác (Admin only)
+func (h *OrderHandler) BulkUpdateOrderStatus(c *gin.Context) {
+	var req models.BulkOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	result := h.orders.BulkTransition(c.Request.Context(), c.GetUint("user_id"), &req)
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Order statuses updated", result))
+}
+
+// FulfillOrder chuyển đơn sang fulfilled khi đã giao cho đơn vị vận chuyển (Admin only)
+func (h *OrderHandler) FulfillOrder(c *gin.Context) {
+	id, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+	var req models.FulfillOrderRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			validation.Respond(c, err)
+			return
+		}
+	}
+	order, err := h.orders.Fulfill(c.Request.Context(), c.GetUint("user_id"), id, &req)
+	if err != nil {
+		h.handleError(c, err, "Error updating order status")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Order status updated successfully", order))
+}
+
+// DeliverOrder chuyển đơn sang delivered khi khách đã nhận hàng (Admin only)
+func (h *OrderHandler) DeliverOrder(c *gin.Context) {
+	id, req, ok := bindOrderTransition(c)
+	if !ok {
+		return
+	}
+	order, err := h.orders.Deliver(c.Request.Context(), c.GetUint("user_id"), id, req.Note)
+	if err != nil {
+		h.handleError(c, err, "Error updating order status")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Order status updated successfully", order))
+}
+
+// AdminCancelOrder hủy đơn còn chờ thanh toán và trả lại tồn kho (Admin only)
+func (h *OrderHandler) AdminCancelOrder(c *gin.Context) {
+	id, req, ok := bindOrderTransition(c)
+	if !ok {
+		return
+	}
+	order, err := h.orders.AdminCancel(c.Request.Context(), c.GetUint("user_id"), id, req.Note)
+	if err != nil {
+		h.handleError(c, err, "Error cancelling order")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Order cancelled successfully", order))
+}
+
+// bindOrderTransition đọc ID đơn và body tùy chọn của các thao tác chuyển trạng thái
+func bindOrderTransition(c *gin.Context) (uint, *models.OrderTransitionRequest, bool) {
+	id, ok := parseOrderID(c)
+	if !ok {
+		return 0, nil, false
+	}
+	var req models.OrderTransitionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			validation.Respond(c, err)
+			return 0, nil, false
+		}
+	}
+	return id, &req, true
+}
+
+// PayOrder tạo giao dịch thanh toán cho đơn qua cổng thanh toán đã chọn
+func (h *OrderHandler) PayOrder(c *gin.Context) {
+	id, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+	var req models.PayOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	intent, err := h.payments.Pay(c.Request.Context(), c.GetUint("user_id"), id, c.ClientIP(), &req)
+	if err != nil {
+		h.handleError(c, err, "Error creating payment")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Payment created successfully", intent))
+}
+
+// maxWebhookBodySize giới hạn body webhook thanh toán, được đọc trước khi chữ ký được kiểm tra
+const maxWebhookBodySize = 64 << 10
+
+// PaymentWebhook nhận kết quả thanh toán (webhook/IPN) từ cổng thanh toán; không cần đăng nhập,
+// xác thực bằng chữ ký của gateway. Gateway có định dạng trả lời riêng (VNPay, MoMo) được trả lời theo định dạng đó.
+func (h *OrderHandler) PaymentWebhook(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodySize)
+	body, err := c.GetRawData()
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, utils.NewErrorResponse(c, http.StatusRequestEntityTooLarge, "Webhook payload is too large",
+				"The payload must be at most 64 KB"))
+			return
+		}
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid webhook payload", err.Error()))
+		return
+	}
+	provider := c.Param("provider")
+	err = h.payments.HandleWebhook(c.Request.Context(), provider, c.Request, body)
+	if err != nil && !errors.Is(err, payment.ErrAlreadyProcessed) {
+		log.Printf("Payment: %s webhook rejected: %v", provider, err)
+	}
+	if status, resp, ok := h.payments.WebhookResponse(provider, err); ok {
+		if resp == nil {
+			c.Status(status)
+			return
+		}
+		c.JSON(status, resp)
+		return
+	}
+
+	switch {
+	case err == nil, errors.Is(err, payment.ErrAlreadyProcessed), errors.Is(err, payment.ErrPaymentNotFound):
+		// Giao dịch không do hệ thống tạo vẫn trả 200 để gateway không gửi lại
+		c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Webhook processed", nil))
+	case errors.Is(err, payment.ErrInvalidSignature):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid webhook signature", ""))
+	default:
+		h.handleError(c, err, "Error processing webhook")
+	}
+}
+
+// PaymentReturn là return URL khách được cổng thanh toán chuyển về sau khi thanh toán (VNPay, MoMo);
+// trả về trạng thái giao dịch và đơn hàng để frontend hiển thị kết quả
+func (h *OrderHandler) PaymentReturn(c *gin.Context) {
+	result, err := h.payments.HandleReturn(c.Request.Context(), c.Param("provider"), c.Request)
+	if err != nil {
+		switch {
+		case errors.Is(err, payment.ErrInvalidSignature):
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid webhook signature", ""))
+		case errors.Is(err, payment.ErrPaymentNotFound):
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Payment not found", ""))
+		case errors.Is(err, payment.ErrAmountMismatch):
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Paid amount does not match the order", err.Error()))
+		default:
+			h.handleError(c, err, "Error processing payment result")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Payment result retrieved successfully", result))
+}
+
+// ListOrderPayments lấy các giao dịch thanh toán và hoàn tiền của đơn (Admin only)
+func (h *OrderHandler) ListOrderPayments(c *gin.Context) {
+	id, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+	payments, err := h.payments.ListByOrder(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Error fetching payments")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Payments retrieved successfully", payments))
+}
+
+// RefundOrder hoàn tiền toàn bộ hoặc một phần cho đơn đã thanh toán (Admin only)
+func (h *OrderHandler) RefundOrder(c *gin.Context) {
+	id, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+	var req models.RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	refund, err := h.payments.Refund(c.Request.Context(), c.GetUint("user_id"), c.ClientIP(), id, &req)
+	if err != nil {
+		h.handleError(c, err, "Error refunding order")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Refund created successfully", refund))
+}
+
+// MarkOrderPaid xác nhận đã thu tiền của đơn COD/chuyển khoản và chuyển đơn sang paid (Admin only)
+func (h *OrderHandler) MarkOrderPaid(c *gin.Context) {
+	id, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+	var req models.MarkPaidRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			validation.Respond(c, err)
+			return
+		}
+	}
+	record, err := h.payments.MarkPaid(c.Request.Context(), c.GetUint("user_id"), id, &req)
+	if err != nil {
+		h.handleError(c, err, "Error marking order as paid")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Order marked as paid", record))
+}
+
+func (h *OrderHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Order not found", ""))
+	case errors.Is(err, services.ErrEmptyCart):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Cart is empty", ""))
+	case errors.Is(err, services.ErrAddressNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Address not found", ""))
+	case errors.Is(err, shipping.ErrUnavailable):
+		c.JSON(http.StatusUnprocessableEntity, utils.NewErrorResponse(c, http.StatusUnprocessableEntity, "No shipping option is available for this address", ""))
+	case errors.Is(err, shipping.ErrUnknownOption):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Shipping option is not available", err.Error()))
+	case errors.Is(err, services.ErrInsufficientStock):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Insufficient stock", err.Error()))
+	case errors.Is(err, services.ErrProductUnavailable):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Product is not available for sale", err.Error()))
+	case errors.Is(err, services.ErrOrderNotPending):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Order is not awaiting payment", ""))
+	case errors.Is(err, services.ErrInvalidOrderTransition):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Order status does not allow this transition", err.Error()))
+	case errors.Is(err, services.ErrOrderPaidOffline):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Order is paid offline", ""))
+	case errors.Is(err, services.ErrPaymentReferenceTaken):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Payment reference has already been recorded", ""))
+	case errors.Is(err, services.ErrOrderNotPaid):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Order has no captured payment", ""))
+	case errors.Is(err, services.ErrRefundExceedsPayment):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Refund amount exceeds the refundable amount", err.Error()))
+	case errors.Is(err, services.ErrPaymentMethodNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Payment method not found", ""))
+	case errors.Is(err, services.ErrPaymentMethodProvider):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Saved payment method belongs to another provider", ""))
+	case errors.Is(err, payment.ErrUnsupportedCurrency):
+		c.JSON(http.StatusUnprocessableEntity, utils.NewErrorResponse(c, http.StatusUnprocessableEntity, "Currency is not supported by the payment provider", err.Error()))
+	case errors.Is(err, payment.ErrDeclined):
+		c.JSON(http.StatusPaymentRequired, utils.NewErrorResponse(c, http.StatusPaymentRequired, "Payment was declined", err.Error()))
+	case errors.Is(err, payment.ErrNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, utils.NewErrorResponse(c, http.StatusServiceUnavailable, "Payments are not configured", err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
+
+func parseOrderID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid order ID", err.Error()))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/payment"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PaymentMethodHandler struct {
+	service *services.PaymentMethodService
+	bus     *events.Bus
+}
+
+func NewPaymentMethodHandler(db *gorm.DB, provider payment.Provider, bus *events.Bus) *PaymentMethodHandler {
+	return &PaymentMethodHandler{
+		service: services.NewPaymentMethodService(db, provider),
+		bus:     bus,
+	}
+}
+
+// ListPaymentMethods lấy các phương thức thanh toán đã lưu của user hiện tại
+func (h *PaymentMethodHandler) ListPaymentMethods(c *gin.Context) {
+	methods, err := h.service.List(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching payment methods", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Payment methods retrieved successfully", methods))
+}
+
+// AddPaymentMethod lưu phương thức thanh toán đã được token hóa bởi SDK phía client
+func (h *PaymentMethodHandler) AddPaymentMethod(c *gin.Context) {
+	var req models.AddPaymentMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	method, err := h.service.Add(c.Request.Context(), c.GetUint("user_id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Error saving payment method")
+		return
+	}
+	h.bus.Publish(c.Request.Context(), events.PaymentMethodAdded{UserID: method.UserID, MethodID: method.ID, IP: c.ClientIP(), At: time.Now()})
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Payment method saved successfully", method))
+}
+
+// SetDefaultPaymentMethod chọn phương thức mặc định dùng khi thanh toán và gia hạn
+func (h *PaymentMethodHandler) SetDefaultPaymentMethod(c *gin.Context) {
+	id, ok := parsePaymentMethodID(c)
+	if !ok {
+		return
+	}
+	method, err := h.service.SetDefault(c.Request.Context(), c.GetUint("user_id"), id)
+	if err != nil {
+		h.handleError(c, err, "Error updating payment method")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Default payment method updated", method))
+}
+
+// DeletePaymentMethod xóa phương thức thanh toán đã lưu
+func (h *PaymentMethodHandler) DeletePaymentMethod(c *gin.Context) {
+	id, ok := parsePaymentMethodID(c)
+	if !ok {
+		return
+	}
+	userID := c.GetUint("user_id")
+	if err := h.service.Delete(c.Request.Context(), userID, id); err != nil {
+		h.handleError(c, err, "Error deleting payment method")
+		return
+	}
+	h.bus.Publish(c.Request.Context(), events.PaymentMethodRemoved{UserID: userID, MethodID: id, IP: c.ClientIP(), At: time.Now()})
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Payment method deleted successfully", nil))
+}
+
+func (h *PaymentMethodHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrPaymentMethodNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Payment method not found", ""))
+	case errors.Is(err, services.ErrPaymentMethodExists):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Payment method is already saved", ""))
+	case errors.Is(err, payment.ErrInvalidMethod):
+		c.JSON(http.StatusUnprocessableEntity, utils.NewErrorResponse(c, http.StatusUnprocessableEntity, "Invalid payment method token", err.Error()))
+	case errors.Is(err, payment.ErrNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, utils.NewErrorResponse(c, http.StatusServiceUnavailable, "Payments are not configured", ""))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
+
+func parsePaymentMethodID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid payment method ID", err.Error()))
+		return 0, false
+	}
+	return uint(id), true
+}
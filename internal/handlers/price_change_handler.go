@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PriceChangeHandler struct {
+	service *services.PriceChangeService
+}
+
+func NewPriceChangeHandler(db *gorm.DB, bus *events.Bus) *PriceChangeHandler {
+	return &PriceChangeHandler{
+		service: services.NewPriceChangeService(db, bus),
+	}
+}
+
+// ListPriceChanges lấy các lịch đổi giá của sản phẩm (Admin only)
+func (h *PriceChangeHandler) ListPriceChanges(c *gin.Context) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	changes, err := h.service.List(c.Request.Context(), productID)
+	if err != nil {
+		h.handleError(c, err, "Error fetching price changes")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Price changes retrieved successfully", changes))
+}
+
+// CreatePriceChange lên lịch đổi giá (flash sale) cho sản phẩm (Admin only)
+func (h *PriceChangeHandler) CreatePriceChange(c *gin.Context) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	var req models.CreatePriceChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	change, err := h.service.Create(c.Request.Context(), c.GetUint("user_id"), productID, &req)
+	if err != nil {
+		h.handleError(c, err, "Error creating price change")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Price change scheduled successfully", change))
+}
+
+// CancelPriceChange hủy lịch đổi giá; lịch đang áp dụng được hoàn về giá gốc ngay (Admin only)
+func (h *PriceChangeHandler) CancelPriceChange(c *gin.Context) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	changeID, ok := parseQAParam(c, "change_id", "Invalid price change ID")
+	if !ok {
+		return
+	}
+	change, err := h.service.Cancel(c.Request.Context(), productID, changeID)
+	if err != nil {
+		h.handleError(c, err, "Error cancelling price change")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Price change cancelled successfully", change))
+}
+
+func (h *PriceChangeHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product not found", ""))
+	case errors.Is(err, services.ErrPriceChangeNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Price change not found", ""))
+	case errors.Is(err, services.ErrInvalidPriceChange):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Price change must end in the future", ""))
+	case errors.Is(err, services.ErrPriceChangeOverlap):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Product already has a price change in this period", ""))
+	case errors.Is(err, services.ErrPriceChangeClosed):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Price change has already ended", ""))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
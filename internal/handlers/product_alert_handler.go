@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ProductAlertHandler struct {
+	service *services.ProductAlertService
+}
+
+func NewProductAlertHandler(db *gorm.DB) *ProductAlertHandler {
+	return &ProductAlertHandler{
+		service: services.NewProductAlertService(db),
+	}
+}
+
+// CreateProductAlert theo dõi sản phẩm để nhận thông báo khi có hàng trở lại hoặc giảm giá
+func (h *ProductAlertHandler) CreateProductAlert(c *gin.Context) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	var req models.CreateProductAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	alert, err := h.service.Subscribe(c.Request.Context(), c.GetUint("user_id"), productID, &req)
+	if err != nil {
+		h.handleError(c, err, "Error creating product alert")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Product alert created successfully", alert))
+}
+
+// ListProductAlerts lấy các sản phẩm user hiện tại đang theo dõi
+func (h *ProductAlertHandler) ListProductAlerts(c *gin.Context) {
+	alerts, err := h.service.List(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		h.handleError(c, err, "Error fetching product alerts")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Product alerts retrieved successfully", alerts))
+}
+
+// DeleteProductAlert bỏ theo dõi sản phẩm
+func (h *ProductAlertHandler) DeleteProductAlert(c *gin.Context) {
+	id, ok := parseQAParam(c, "id", "Invalid product alert ID")
+	if !ok {
+		return
+	}
+	if err := h.service.Unsubscribe(c.Request.Context(), c.GetUint("user_id"), id); err != nil {
+		h.handleError(c, err, "Error deleting product alert")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Product alert deleted successfully", nil))
+}
+
+func (h *ProductAlertHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrProductAlertNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product alert not found", ""))
+	case errors.Is(err, services.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product not found", ""))
+	case errors.Is(err, services.ErrProductUnavailable):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Product is not available for sale", ""))
+	case errors.Is(err, services.ErrProductInStock):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Product is in stock", ""))
+	case errors.Is(err, services.ErrAlertPriceNotBelow):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Target price must be below the current price", ""))
+	case errors.Is(err, services.ErrProductAlertLimit):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Too many product alerts", fmt.Sprintf("at most %d alerts", models.MaxProductAlertsPerUser)))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
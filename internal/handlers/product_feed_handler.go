@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ProductFeedHandler struct {
+	service *services.ProductFeedService
+}
+
+func NewProductFeedHandler(db *gorm.DB) *ProductFeedHandler {
+	return &ProductFeedHandler{
+		service: services.NewProductFeedService(db),
+	}
+}
+
+// GetGoogleFeed trả về feed sản phẩm XML cho Google Merchant Center (cần access token)
+func (h *ProductFeedHandler) GetGoogleFeed(c *gin.Context) {
+	h.serve(c, models.ProductFeedGoogle)
+}
+
+// GetFacebookFeed trả về feed sản phẩm CSV cho Facebook catalog (cần access token)
+func (h *ProductFeedHandler) GetFacebookFeed(c *gin.Context) {
+	h.serve(c, models.ProductFeedFacebook)
+}
+
+func (h *ProductFeedHandler) serve(c *gin.Context, name string) {
+	feed, err := h.service.Get(c.Request.Context(), name)
+	if err != nil {
+		if errors.Is(err, services.ErrProductFeedNotFound) {
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product feed not found", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching product feed", err.Error()))
+		return
+	}
+	// Feed chỉ đổi khi tác vụ product_feed_generate chạy lại; URL chứa token nên không cho cache dùng chung
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Header("Last-Modified", feed.GeneratedAt.UTC().Format(http.TimeFormat))
+	c.Data(http.StatusOK, feed.ContentType, feed.Content)
+}
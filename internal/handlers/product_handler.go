@@ -2,37 +2,100 @@
 package handlers
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
-	"strings" // Thêm import strings
+	"strings"
 	"time"
 
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/database"
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/imageurl"
+	"github.com/NgTruong624/project_backend/internal/jobs"
 	"github.com/NgTruong624/project_backend/internal/models"
 	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/shadow"
 	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/NgTruong624/project_backend/internal/visitor"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type ProductHandler struct {
-	repo *repository.ProductRepository
+	repo       *repository.ProductRepository
+	listings   *repository.ProductListingRepository
+	service    *services.ProductService
+	attributes *services.CategoryAttributeService
+	synonyms   *services.SearchSynonymService
+	categories *services.CategoryService
+	includes   *services.ProductIncludeService
+	jobs       *jobs.Client
+	shadow     *shadow.Verifier
+	visitors   *visitor.Tracker
+	// images và fetcher kiểm tra image_url gửi trực tiếp và tải ảnh ở host ngoài về (PRODUCT_IMAGE_REHOST)
+	images  imageurl.Policy
+	fetcher *imageurl.Fetcher
+	// similarity là ngưỡng word_similarity khi tìm gần đúng; 0 tắt tìm gần đúng
+	similarity float64
+	// storefrontURL là địa chỉ gốc của frontend, dùng cho canonical URL của sản phẩm
+	storefrontURL string
 }
 
-func NewProductHandler(db *gorm.DB) *ProductHandler {
+func NewProductHandler(db *gorm.DB, bus *events.Bus, jobClient *jobs.Client, verifier *shadow.Verifier, visitors *visitor.Tracker) *ProductHandler {
+	categories := services.NewCategoryService(db, bus)
 	return &ProductHandler{
-		repo: repository.NewProductRepository(db),
+		repo:          repository.NewProductRepository(db),
+		listings:      repository.NewProductListingRepository(db),
+		service:       services.NewProductService(db, bus),
+		attributes:    services.NewCategoryAttributeService(db),
+		synonyms:      services.NewSearchSynonymService(db),
+		categories:    categories,
+		includes:      services.NewProductIncludeService(db, categories),
+		jobs:          jobClient,
+		shadow:        verifier,
+		visitors:      visitors,
+		images:        imageurl.PolicyFromEnv(),
+		fetcher:       imageurl.NewFetcher(),
+		similarity:    searchSimilarityThreshold(db),
+		storefrontURL: utils.StorefrontURL(),
 	}
 }
 
+// searchSimilarityThreshold đọc SEARCH_SIMILARITY_THRESHOLD (mặc định 0.3); tìm gần đúng cần pg_trgm
+// nên bị tắt trên database khác Postgres
+func searchSimilarityThreshold(db *gorm.DB) float64 {
+	if !database.IsPostgres(db) {
+		return 0
+	}
+	return config.Float("SEARCH_SIMILARITY_THRESHOLD", 0.3)
+}
+
 // --- GetProducts và GetProduct giữ nguyên như file bạn đã cung cấp ---
-// GetProducts lấy danh sách sản phẩm (Public)
+// GetProducts lấy danh sách sản phẩm (Public). Khách chỉ thấy sản phẩm đã đăng; admin
+// (GET /admin/products) lọc được theo status.
 func (h *ProductHandler) GetProducts(c *gin.Context) {
 	var query models.ProductQueryParams
 	if err := c.ShouldBindQuery(&query); err != nil {
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		utils.Render(c, http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+	if c.GetString("role") != "admin" {
+		query.Status = models.ProductStatusPublished
+	}
+	if ids := c.Query("ids"); ids != "" {
+		h.getProductsByIDs(c, ids, query.Status)
 		return
 	}
 
@@ -61,34 +124,76 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 		query.InStock = inStock == "true"
 	}
 	if query.MinPrice > 0 && query.MaxPrice > 0 && query.MinPrice > query.MaxPrice {
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid price range", "min_price cannot be greater than max_price"))
+		utils.Render(c, http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid price range", "min_price cannot be greater than max_price"))
 		return
 	}
 	if !query.StartDate.IsZero() && !query.EndDate.IsZero() && query.StartDate.After(query.EndDate) {
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid date range", "start_date cannot be after end_date"))
+		utils.Render(c, http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid date range", "start_date cannot be after end_date"))
 		return
 	}
+	if attrs := c.QueryMap("attr"); len(attrs) > 0 {
+		if err := h.attributes.ValidateFilter(c.Request.Context(), query.Category, attrs); err != nil {
+			if errors.Is(err, services.ErrUnknownAttributeFilter) {
+				utils.Render(c, http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid attribute filter", err.Error()))
+				return
+			}
+			utils.Render(c, http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching products", err.Error()))
+			return
+		}
+		query.Attrs = attrs
+	}
 
-	products, total, err := h.repo.GetAll(&query)
+	if query.Search != "" {
+		terms, err := h.synonyms.Expand(c.Request.Context(), query.Search)
+		if err != nil {
+			utils.Render(c, http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching products", err.Error()))
+			return
+		}
+		query.SearchTerms = terms
+	}
+
+	// Đọc từ read model product_listings thay vì join/tổng hợp trên products
+	listings, total, err := h.listings.GetAll(c.Request.Context(), &query)
+	// Không có sản phẩm nào khớp từ khóa: thử lại theo độ tương đồng trigram để chịu được lỗi gõ
+	// ("iphnoe" vẫn ra "iPhone"). Ở trang sau trang đầu chỉ biết là không khớp khi có tổng số dòng.
+	noMatches := len(listings) == 0 && (query.Page == 1 || total.Mode != models.CountNone && total.Count == 0)
+	if err == nil && noMatches && query.Search != "" && h.similarity > 0 {
+		query.SimilarityThreshold = h.similarity
+		listings, total, err = h.listings.GetAll(c.Request.Context(), &query)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Error fetching products", err.Error()))
+		utils.Render(c, http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching products", err.Error()))
 		return
 	}
+	if h.shadow.Sampled() {
+		h.compareLegacyListing(query, listings, total)
+	}
 
-	var productResponses []models.ProductResponse
-	for _, p := range products {
+	productResponses := make([]models.ProductResponse, 0, len(listings))
+	for _, l := range listings {
 		productResponses = append(productResponses, models.ProductResponse{
-			ID: p.ID, Name: p.Name, Description: p.Description, Price: p.Price,
-			Stock: p.Stock, ImageURL: p.ImageURL, Category: p.Category, CreatedAt: p.CreatedAt,
+			ID: l.ProductID, Name: l.Name, Description: l.Description, Price: l.Price,
+			Stock: l.Stock, ImageURL: l.ImageURL, Category: l.Category, Specs: l.Specs, Status: l.Status, CreatedAt: l.CreatedAt,
+			EffectivePrice: l.EffectivePrice, RatingAvg: l.RatingAvg, RatingCount: l.RatingCount,
 		})
 	}
-	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	totalPages := (int(total.Count) + query.Limit - 1) / query.Limit
 	meta := map[string]interface{}{
-		"total": total, "total_pages": totalPages, "current_page": query.Page,
-		"per_page": query.Limit, "has_next": query.Page < totalPages, "has_prev": query.Page > 1,
+		"total": total.Count, "total_pages": totalPages, "current_page": query.Page,
+		"per_page": query.Limit, "has_next": total.HasMore, "has_prev": query.Page > 1,
+	}
+	if total.Mode != models.CountExact {
+		meta["count"] = total.Mode
+		meta["estimated"] = total.Estimated
 	}
 	if query.Search != "" {
 		meta["search"] = query.Search
+		if len(query.SearchTerms) > 1 {
+			meta["search_synonyms"] = query.SearchTerms[1:]
+		}
+		if query.SimilarityThreshold > 0 {
+			meta["search_fuzzy"] = true
+		}
 	}
 	if query.Category != "" {
 		meta["category"] = query.Category
@@ -102,6 +207,12 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 	if query.InStock {
 		meta["in_stock"] = true
 	}
+	if c.GetString("role") == "admin" && query.Status != "" {
+		meta["status"] = query.Status
+	}
+	if len(query.Attrs) > 0 {
+		meta["attr"] = query.Attrs
+	}
 	if !query.StartDate.IsZero() {
 		meta["start_date"] = query.StartDate.Format("2006-01-02")
 	}
@@ -113,245 +224,703 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 		meta["order"] = query.Order
 	}
 
-	c.JSON(http.StatusOK, utils.NewPaginatedResponse(
-		http.StatusOK, "Products retrieved successfully", productResponses,
-		query.Page, totalPages, total, query.Limit, meta,
-	))
+	response := utils.NewPaginatedResponse(
+		c, http.StatusOK, "Products retrieved successfully", productResponses,
+		query.Page, totalPages, total.Count, query.Limit, meta,
+	)
+	// Khi không đếm chính xác, has_next lấy từ việc còn dòng sau trang thay vì từ tổng số trang
+	response.Meta.Pagination.HasNext = total.HasMore
+	utils.Render(c, http.StatusOK, response)
+}
+
+// maxBatchProductIDs là số sản phẩm tối đa lấy được trong một request ?ids=
+const maxBatchProductIDs = 100
+
+// getProductsByIDs lấy nhiều sản phẩm theo ?ids=1,5,9 trong một request, giữ thứ tự của ids (dùng cho trang
+// giỏ hàng, danh sách yêu thích). ID trùng chỉ lấy một lần; sản phẩm không tồn tại hoặc không khớp status
+// (khách chỉ thấy sản phẩm đã đăng) bị bỏ qua. Các tham số lọc và phân trang khác không áp dụng.
+func (h *ProductHandler) getProductsByIDs(c *gin.Context, raw, status string) {
+	var ids []uint
+	seen := make(map[uint]struct{})
+	for _, part := range strings.Split(raw, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil {
+			utils.Render(c, http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid product IDs", err.Error()))
+			return
+		}
+		if _, dup := seen[uint(id)]; dup {
+			continue
+		}
+		if len(ids) == maxBatchProductIDs {
+			utils.Render(c, http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid product IDs", fmt.Sprintf("at most %d ids are allowed", maxBatchProductIDs)))
+			return
+		}
+		seen[uint(id)] = struct{}{}
+		ids = append(ids, uint(id))
+	}
+	includes, ok := parseProductIncludes(c)
+	if !ok {
+		return
+	}
+
+	products, err := h.repo.GetByIDs(c.Request.Context(), ids)
+	if err != nil {
+		utils.Render(c, http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching products", err.Error()))
+		return
+	}
+	byID := make(map[uint]*models.Product, len(products))
+	for i := range products {
+		if status == "" || products[i].Status == status {
+			byID[products[i].ID] = &products[i]
+		}
+	}
+	ordered := make([]models.Product, 0, len(byID))
+	for _, id := range ids {
+		if product, ok := byID[id]; ok {
+			ordered = append(ordered, *product)
+		}
+	}
+	included, err := h.includes.Load(c.Request.Context(), ordered, includes)
+	if err != nil {
+		utils.Render(c, http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching products", err.Error()))
+		return
+	}
+	responses := make([]models.ProductResponse, 0, len(ordered))
+	for i := range ordered {
+		response := productResponseOf(&ordered[i])
+		if included != nil {
+			response.Included = included[i]
+		}
+		responses = append(responses, response)
+	}
+	utils.Render(c, http.StatusOK, utils.NewResponse(c, http.StatusOK, "Products retrieved successfully", responses))
+}
+
+// parseProductIncludes đọc ?include= và ?reviews_limit=; trả về false sau khi đã phản hồi 400 nếu không hợp lệ
+func parseProductIncludes(c *gin.Context) (services.IncludeOptions, bool) {
+	reviewsLimit := 0
+	if raw := c.Query("reviews_limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			utils.Render(c, http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid include", "reviews_limit must be a number"))
+			return services.IncludeOptions{}, false
+		}
+		reviewsLimit = limit
+	}
+	opts, err := services.ParseIncludes(c.Query("include"), reviewsLimit)
+	if err != nil {
+		utils.Render(c, http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid include", err.Error()))
+		return services.IncludeOptions{}, false
+	}
+	return opts, true
+}
+
+// SuggestProducts gợi ý tên sản phẩm, danh mục và thương hiệu cho ô tìm kiếm (Public)
+func (h *ProductHandler) SuggestProducts(c *gin.Context) {
+	var query models.SuggestQueryParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 5
+	}
+	suggestions, err := h.listings.Suggest(c.Request.Context(), strings.TrimSpace(query.Q), query.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching suggestions", err.Error()))
+		return
+	}
+	// Gợi ý được gọi theo từng phím gõ; cho phép cache ngắn ở trình duyệt và CDN
+	c.Header("Cache-Control", "public, max-age=60")
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Suggestions retrieved successfully", suggestions))
+}
+
+// CheckStock kiểm tra tồn kho của nhiều sản phẩm trong một request, dùng để kiểm tra giỏ trước checkout (Public)
+func (h *ProductHandler) CheckStock(c *gin.Context) {
+	var req models.StockCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	result, err := h.service.CheckStock(c.Request.Context(), req.Items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error checking stock", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Stock checked successfully", result))
+}
+
+// listingPage là phần kết quả danh sách sản phẩm được so sánh giữa read model và truy vấn cũ
+type listingPage struct {
+	IDs   []uint
+	Total repository.Total
 }
 
-// GetProduct lấy chi tiết sản phẩm (Public)
+// compareLegacyListing chạy truy vấn cũ trên bảng products ở nền và so sánh với kết quả từ read model
+func (h *ProductHandler) compareLegacyListing(query models.ProductQueryParams, listings []models.ProductListing, total repository.Total) {
+	primary := listingPage{Total: total}
+	for _, l := range listings {
+		primary.IDs = append(primary.IDs, l.ProductID)
+	}
+
+	shadow.Compare(h.shadow, "products.list", primary, func(ctx context.Context) (listingPage, error) {
+		products, total, err := h.repo.GetAll(ctx, &query)
+		if err != nil {
+			return listingPage{}, err
+		}
+		legacy := listingPage{Total: total}
+		for _, p := range products {
+			legacy.IDs = append(legacy.IDs, p.ID)
+		}
+		return legacy, nil
+	}, func(primary, legacy listingPage) string {
+		// Ước lượng lấy từ thống kê của từng bảng nên không so sánh
+		if !primary.Total.Estimated && primary.Total.Count != legacy.Total.Count || primary.Total.HasMore != legacy.Total.HasMore {
+			return fmt.Sprintf("total %+v, legacy %+v (query %+v)", primary.Total, legacy.Total, query)
+		}
+		if !slices.Equal(primary.IDs, legacy.IDs) {
+			return fmt.Sprintf("ids %v, legacy %v (query %+v)", primary.IDs, legacy.IDs, query)
+		}
+		return ""
+	})
+}
+
+// GetProduct lấy chi tiết sản phẩm (Public). Sản phẩm chưa đăng hoặc đã lưu trữ chỉ admin và biên tập viên
+// (để gửi revision) xem được.
 func (h *ProductHandler) GetProduct(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid product ID", err.Error()))
+		utils.Render(c, http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err.Error()))
+		return
+	}
+	includes, ok := parseProductIncludes(c)
+	if !ok {
 		return
 	}
-	product, err := h.repo.GetByID(uint(id))
+	product, err := h.repo.GetByID(c.Request.Context(), uint(id))
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, utils.NewErrorResponse(http.StatusNotFound, "Product not found", ""))
+			utils.Render(c, http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product not found", ""))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Error fetching product", err.Error()))
+		utils.Render(c, http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching product", err.Error()))
 		return
 	}
-	productResponse := models.ProductResponse{
-		ID: product.ID, Name: product.Name, Description: product.Description, Price: product.Price,
-		Stock: product.Stock, ImageURL: product.ImageURL, Category: product.Category, CreatedAt: product.CreatedAt,
+	if role := c.GetString("role"); product.Status != models.ProductStatusPublished && role != "admin" && role != models.RoleEditor {
+		utils.Render(c, http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product not found", ""))
+		return
+	}
+	// Chỉ ghi lịch sử xem cho phiên client đã gửi lại cookie, tránh tạo phiên cho mỗi request của bot
+	if visitorID, returning := visitor.FromContext(c); returning {
+		if err := h.visitors.RecordView(c.Request.Context(), visitorID, product.ID); err != nil {
+			log.Printf("Product: failed to record view of product %d: %v", product.ID, err)
+		}
+	}
+	response := productResponseOf(product)
+	response.Breadcrumbs, err = h.categories.Breadcrumbs(c.Request.Context(), product.Category)
+	if err != nil {
+		utils.Render(c, http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching product", err.Error()))
+		return
+	}
+	response.Slug = utils.Slugify(product.Name)
+	response.CanonicalURL = h.storefrontURL + utils.ProductPath(product.ID, product.Name)
+	included, err := h.includes.Load(c.Request.Context(), []models.Product{*product}, includes)
+	if err != nil {
+		utils.Render(c, http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching product", err.Error()))
+		return
+	}
+	if included != nil {
+		response.Included = included[0]
+	}
+	c.Header("ETag", productETag(product.Version))
+	utils.Render(c, http.StatusOK, utils.NewResponse(c, http.StatusOK, "Product retrieved successfully", response))
+}
+
+// GetRecentlyViewed lấy các sản phẩm phiên hiện tại (cookie visitor_id) đã xem, mới nhất trước (Public)
+func (h *ProductHandler) GetRecentlyViewed(c *gin.Context) {
+	visitorID, _ := visitor.FromContext(c)
+	products, err := h.visitors.RecentlyViewed(c.Request.Context(), visitorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching recently viewed products", err.Error()))
+		return
+	}
+	responses := make([]models.ProductResponse, 0, len(products))
+	for i := range products {
+		responses = append(responses, productResponseOf(&products[i]))
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Recently viewed products retrieved successfully", responses))
+}
+
+// productResponseOf dựng ProductResponse của chi tiết sản phẩm
+func productResponseOf(product *models.Product) models.ProductResponse {
+	return models.ProductResponse{
+		ID: product.ID, Name: product.Name, SKU: product.SKU, Description: product.Description, Price: product.Price,
+		Stock: product.Stock, ImageURL: product.ImageURL, Category: product.Category, Specs: product.Specs, WeightGrams: product.WeightGrams,
+		Status: product.Status, PublishAt: product.PublishAt, Version: product.Version, CreatedAt: product.CreatedAt,
+		EffectivePrice: product.EffectivePrice(),
 	}
-	c.JSON(http.StatusOK, utils.NewResponse(http.StatusOK, "Product retrieved successfully", productResponse))
 }
 
 // CreateProduct tạo sản phẩm mới (Private - Admin only)
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	role := c.GetString("role")
 	if role != "admin" {
-		c.JSON(http.StatusForbidden, utils.NewErrorResponse(http.StatusForbidden, "Permission denied", "Only admin can create products"))
+		c.JSON(http.StatusForbidden, utils.NewErrorResponse(c, http.StatusForbidden, "Permission denied", "Only admin can create products"))
 		return
 	}
 
 	var req models.CreateProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid request", err.Error()))
-		return
-	}
-
-	// Sửa: Kiểm tra tên sản phẩm đã tồn tại chưa, xử lý lỗi từ repo
-	nameExists, errDb := h.repo.CheckIfNameExists(req.Name, 0) // 0 vì đây là tạo mới, không có ID để loại trừ
-	if errDb != nil {
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Error checking product name availability", errDb.Error()))
+		validation.Respond(c, err)
 		return
 	}
-	if nameExists {
-		c.JSON(http.StatusConflict, utils.NewErrorResponse(http.StatusConflict, "Product name already exists", "")) // Sử dụng 409 Conflict
+	rehosted, ok := h.rehostImage(c, &req.ImageURL)
+	if !ok {
 		return
 	}
 
-	product := &models.Product{
-		Name:        req.Name,
-		Description: req.Description,
-		Price:       req.Price,
-		Stock:       req.Stock,
-		ImageURL:    req.ImageURL,
-		Category:    req.Category,
-	}
-
-	if err := h.repo.Create(product); err != nil {
-		// Kiểm tra lỗi từ DB nếu có ràng buộc UNIQUE ở DB
-		// (PostgreSQL thường trả về lỗi chứa "23505" hoặc "unique_violation" cho duplicate key)
-		if strings.Contains(err.Error(), "unique_violation") || strings.Contains(err.Error(), "23505") {
-			c.JSON(http.StatusConflict, utils.NewErrorResponse(http.StatusConflict, "Product name already exists (DB constraint)", err.Error()))
+	product, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		if rehosted != "" {
+			os.Remove(rehosted)
+		}
+		if errors.Is(err, services.ErrProductNameExists) {
+			c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Product name already exists", "")) // Sử dụng 409 Conflict
+			return
+		}
+		if errors.Is(err, services.ErrProductSKUExists) {
+			c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Product SKU already exists", ""))
+			return
+		}
+		if errors.Is(err, services.ErrInvalidSpecs) {
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid product specs", err.Error()))
+			return
+		}
+		if errors.Is(err, services.ErrInvalidProductSchedule) {
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid publish schedule", err.Error()))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Error creating product", err.Error()))
+		if errors.Is(err, services.ErrInvalidImageURL) {
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid image URL", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error creating product", err.Error()))
 		return
 	}
+	if rehosted != "" {
+		h.jobs.EnqueueLogged(c.Request.Context(), jobs.TypeProductThumbnail, jobs.ThumbnailPayload{ProductID: product.ID, ImagePath: rehosted})
+	}
 
 	productResponse := models.ProductResponse{
 		ID:          product.ID,
 		Name:        product.Name,
+		SKU:         product.SKU,
 		Description: product.Description,
 		Price:       product.Price,
 		Stock:       product.Stock,
 		ImageURL:    product.ImageURL,
 		Category:    product.Category,
+		Specs:       product.Specs,
+		WeightGrams: product.WeightGrams,
+		Status:      product.Status,
+		PublishAt:   product.PublishAt,
+		Version:     product.Version,
 		CreatedAt:   product.CreatedAt,
+
+		EffectivePrice: product.EffectivePrice(),
 	}
-	c.JSON(http.StatusCreated, utils.NewResponse(http.StatusCreated, "Product created successfully", productResponse))
+	c.Header("ETag", productETag(product.Version))
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Product created successfully", productResponse))
 }
 
 // UpdateProduct cập nhật sản phẩm (Private - Admin only)
 func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	role := c.GetString("role")
 	if role != "admin" {
-		c.JSON(http.StatusForbidden, utils.NewErrorResponse(http.StatusForbidden, "Permission denied", "Only admin can update products"))
+		c.JSON(http.StatusForbidden, utils.NewErrorResponse(c, http.StatusForbidden, "Permission denied", "Only admin can update products"))
 		return
 	}
 
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid product ID", err.Error()))
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err.Error()))
 		return
 	}
 
 	var req models.UpdateProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid request", err.Error()))
+		validation.Respond(c, err)
 		return
 	}
 
-	product, err := h.repo.GetByID(uint(id))
+	// Version client đã đọc: ưu tiên header If-Match (ETag từ GET), sau đó tới field version trong body
+	version, ok, err := ifMatchVersion(c)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, utils.NewErrorResponse(http.StatusNotFound, "Product not found", ""))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Error fetching product", err.Error()))
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid If-Match header", err.Error()))
+		return
+	}
+	if !ok && req.Version != nil {
+		version, ok = *req.Version, true
+	}
+	if !ok {
+		c.JSON(http.StatusPreconditionRequired, utils.NewErrorResponse(c, http.StatusPreconditionRequired, "Product version is required",
+			"Send the ETag from GET /products/:id in the If-Match header or the version field"))
 		return
 	}
 
-	// Sửa: Kiểm tra tên sản phẩm trùng (nếu cập nhật tên), xử lý lỗi từ repo
-	if req.Name != "" && req.Name != product.Name { // Chỉ kiểm tra nếu tên mới khác tên cũ
-		nameExists, errDb := h.repo.CheckIfNameExists(req.Name, product.ID)
-		if errDb != nil {
-			c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Error checking product name availability", errDb.Error()))
-			return
-		}
-		if nameExists {
-			c.JSON(http.StatusConflict, utils.NewErrorResponse(http.StatusConflict, "Another product with this name already exists", "")) // Sử dụng 409 Conflict
+	var rehosted string
+	if req.ImageURL != nil {
+		if rehosted, ok = h.rehostImage(c, req.ImageURL); !ok {
 			return
 		}
-		product.Name = req.Name
 	}
 
-	// Cập nhật các trường khác
-	if req.Description != "" {
-		product.Description = req.Description
-	}
-	// Sửa: Price và Stock nên được kiểm tra xem có được gửi lên không, thay vì chỉ > 0 hoặc >= 0
-	// Nếu bạn muốn cho phép cập nhật Price/Stock thành 0, logic hiện tại là ổn.
-	// Nếu Price/Stock là optional và chỉ cập nhật nếu được gửi, bạn cần dùng con trỏ trong UpdateProductRequest
-	// hoặc kiểm tra sự tồn tại của key trong JSON. Giả sử logic hiện tại là ý muốn.
-	if req.Price > 0 { // Hoặc bạn có thể dùng con trỏ để phân biệt 0 và không cung cấp
-		product.Price = req.Price
-	}
-	if req.Stock >= 0 { // Tương tự như Price
-		product.Stock = req.Stock
-	}
-	if req.ImageURL != "" {
-		product.ImageURL = req.ImageURL
-	}
-	if req.Category != "" {
-		product.Category = req.Category
-	}
-
-	if err := h.repo.Update(product); err != nil {
-		// Kiểm tra lỗi từ DB nếu có ràng buộc UNIQUE ở DB
-		if strings.Contains(err.Error(), "unique_violation") || strings.Contains(err.Error(), "23505") {
-			c.JSON(http.StatusConflict, utils.NewErrorResponse(http.StatusConflict, "Product name already exists (DB constraint)", err.Error()))
-			return
+	product, err := h.service.Update(c.Request.Context(), uint(id), version, &req)
+	if err != nil {
+		if rehosted != "" {
+			os.Remove(rehosted)
+		}
+		switch {
+		case errors.Is(err, services.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product not found", ""))
+		case errors.Is(err, services.ErrProductVersionConflict):
+			// Trả về version hiện tại (nếu biết) để client tải lại sản phẩm rồi áp dụng lại thay đổi
+			if product != nil {
+				c.Header("ETag", productETag(product.Version))
+			}
+			c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Product was modified by another request", ""))
+		case errors.Is(err, services.ErrProductNameExists):
+			c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Another product with this name already exists", "")) // Sử dụng 409 Conflict
+		case errors.Is(err, services.ErrProductSKUExists):
+			c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Product SKU already exists", ""))
+		case errors.Is(err, services.ErrInvalidSpecs):
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid product specs", err.Error()))
+		case errors.Is(err, services.ErrInvalidProductSchedule):
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid publish schedule", err.Error()))
+		case errors.Is(err, services.ErrInvalidImageURL):
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid image URL", err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error updating product", err.Error()))
 		}
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Error updating product", err.Error()))
 		return
 	}
+	if rehosted != "" {
+		h.jobs.EnqueueLogged(c.Request.Context(), jobs.TypeProductThumbnail, jobs.ThumbnailPayload{ProductID: product.ID, ImagePath: rehosted})
+	}
 
 	productResponse := models.ProductResponse{
 		ID:          product.ID,
 		Name:        product.Name,
+		SKU:         product.SKU,
 		Description: product.Description,
 		Price:       product.Price,
 		Stock:       product.Stock,
 		ImageURL:    product.ImageURL,
 		Category:    product.Category,
+		Specs:       product.Specs,
+		WeightGrams: product.WeightGrams,
+		Status:      product.Status,
+		PublishAt:   product.PublishAt,
+		Version:     product.Version,
 		CreatedAt:   product.CreatedAt, // Nên là UpdatedAt của product
+
+		EffectivePrice: product.EffectivePrice(),
 	}
-	c.JSON(http.StatusOK, utils.NewResponse(http.StatusOK, "Product updated successfully", productResponse))
+	c.Header("ETag", productETag(product.Version))
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Product updated successfully", productResponse))
 }
 
-// --- DeleteProduct và UploadProductImage giữ nguyên như file bạn đã cung cấp ---
 // DeleteProduct xóa sản phẩm (Private - Admin only)
 func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	role := c.GetString("role")
 	if role != "admin" {
-		c.JSON(http.StatusForbidden, utils.NewErrorResponse(http.StatusForbidden, "Permission denied", "Only admin can delete products"))
+		c.JSON(http.StatusForbidden, utils.NewErrorResponse(c, http.StatusForbidden, "Permission denied", "Only admin can delete products"))
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err.Error()))
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error deleting product", err.Error()))
 		return
 	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Product deleted successfully", nil))
+}
+
+// DuplicateProduct nhân bản sản phẩm thành một sản phẩm draft (Private - Admin only). Với copy_images, file
+// ảnh trong thư mục upload được sao chép cho bản sao thay vì dùng chung với sản phẩm gốc.
+func (h *ProductHandler) DuplicateProduct(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid product ID", err.Error()))
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err.Error()))
 		return
 	}
-	if err := h.repo.Delete(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Error deleting product", err.Error()))
+	var req models.DuplicateProductRequest
+	// Body không bắt buộc
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			validation.Respond(c, err)
+			return
+		}
+	}
+
+	product, err := h.service.Duplicate(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrProductNotFound) {
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product not found", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error duplicating product", err.Error()))
 		return
 	}
-	c.JSON(http.StatusOK, utils.NewResponse(http.StatusOK, "Product deleted successfully", nil))
+	if req.CopyImages {
+		if product, err = h.copyImage(c, product); err != nil {
+			c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error copying product image", err.Error()))
+			return
+		}
+	}
+	c.Header("ETag", productETag(product.Version))
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Product duplicated successfully", productResponseOf(product)))
+}
+
+// GetProductHistory lấy lịch sử sửa đổi của sản phẩm, mỗi bản lưu kèm nội dung sản phẩm và các trường đã
+// đổi (Admin only)
+func (h *ProductHandler) GetProductHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err.Error()))
+		return
+	}
+	var query models.ProductSnapshotQueryParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+
+	snapshots, total, err := h.service.History(c.Request.Context(), uint(id), query.Page, query.Limit)
+	if err != nil {
+		if errors.Is(err, services.ErrProductNotFound) {
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product not found", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching product history", err.Error()))
+		return
+	}
+	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	c.JSON(http.StatusOK, utils.NewPaginatedResponse(
+		c, http.StatusOK, "Product history retrieved successfully", snapshots,
+		query.Page, totalPages, total, query.Limit, map[string]interface{}{"product_id": id},
+	))
+}
+
+// RestoreProductRevision khôi phục sản phẩm về một bản lưu trong lịch sử (Admin only). Version hiện tại bắt
+// buộc như khi sửa: header If-Match hoặc field version.
+func (h *ProductHandler) RestoreProductRevision(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err.Error()))
+		return
+	}
+	rev, err := strconv.Atoi(c.Param("rev"))
+	if err != nil || rev < 1 {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid revision", "rev must be a product version from the history"))
+		return
+	}
+	var req models.RestoreProductSnapshotRequest
+	// Body không bắt buộc khi gửi header If-Match
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			validation.Respond(c, err)
+			return
+		}
+	}
+	version, ok, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid If-Match header", err.Error()))
+		return
+	}
+	if !ok && req.Version != nil {
+		version, ok = *req.Version, true
+	}
+	if !ok {
+		c.JSON(http.StatusPreconditionRequired, utils.NewErrorResponse(c, http.StatusPreconditionRequired, "Product version is required",
+			"Send the ETag from GET /products/:id in the If-Match header or the version field"))
+		return
+	}
+
+	product, err := h.service.Restore(c.Request.Context(), uint(id), version, rev)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product not found", ""))
+		case errors.Is(err, services.ErrProductSnapshotNotFound):
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product revision not found in history", ""))
+		case errors.Is(err, services.ErrProductVersionConflict):
+			if product != nil {
+				c.Header("ETag", productETag(product.Version))
+			}
+			c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Product was modified by another request", ""))
+		case errors.Is(err, services.ErrProductNameExists):
+			c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Another product with this name already exists", ""))
+		case errors.Is(err, services.ErrProductSKUExists):
+			c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Product SKU already exists", ""))
+		case errors.Is(err, services.ErrInvalidSpecs):
+			// Thuộc tính của danh mục đã đổi từ lúc lưu bản này
+			c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Revision specs are no longer valid for the category", err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error restoring product", err.Error()))
+		}
+		return
+	}
+	c.Header("ETag", productETag(product.Version))
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Product restored successfully", productResponseOf(product)))
+}
+
+// copyImage sao chép file ảnh (trong static/uploads) của bản sao sang file mới đặt tên theo ID của bản sao;
+// ảnh ở ngoài thư mục upload được giữ nguyên URL
+func (h *ProductHandler) copyImage(c *gin.Context, product *models.Product) (*models.Product, error) {
+	uploadDir := filepath.Join("static", "uploads")
+	source := filepath.Clean(filepath.FromSlash(strings.TrimPrefix(product.ImageURL, "/")))
+	if !strings.HasPrefix(source, uploadDir+string(filepath.Separator)) {
+		return product, nil
+	}
+	filename := fmt.Sprintf("%d_%d%s", product.ID, time.Now().Unix(), filepath.Ext(source))
+	uploadPath := filepath.Join(uploadDir, filename)
+	if err := copyFile(source, uploadPath); err != nil {
+		return nil, err
+	}
+	updated, err := h.service.SetImage(c.Request.Context(), product.ID, "/"+uploadPath)
+	if err != nil {
+		os.Remove(uploadPath)
+		return nil, err
+	}
+	h.jobs.EnqueueLogged(c.Request.Context(), jobs.TypeProductThumbnail, jobs.ThumbnailPayload{ProductID: updated.ID, ImagePath: uploadPath})
+	return updated, nil
+}
+
+// rehostImage tải ảnh ở host ngoài danh sách cho phép về static/uploads khi bật PRODUCT_IMAGE_REHOST và
+// thay *imageURL bằng đường dẫn nội bộ; trả về đường dẫn file đã lưu ("" nếu URL được giữ nguyên để
+// service kiểm tra). ok = false nếu đã trả lỗi cho client.
+func (h *ProductHandler) rehostImage(c *gin.Context, imageURL *string) (path string, ok bool) {
+	if *imageURL == "" {
+		return "", true
+	}
+	if kind, err := h.images.Classify(*imageURL); err != nil || kind != imageurl.Remote {
+		return "", true
+	}
+	image, err := h.fetcher.Fetch(c.Request.Context(), *imageURL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Could not fetch image", err.Error()))
+		return "", false
+	}
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error saving file", err.Error()))
+		return "", false
+	}
+	path = filepath.Join("static", "uploads", "remote_"+hex.EncodeToString(b)+image.Ext)
+	if err := os.WriteFile(path, image.Data, 0o644); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error saving file", err.Error()))
+		return "", false
+	}
+	*imageURL = "/" + filepath.ToSlash(path)
+	return path, true
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
 }
 
 // UploadProductImage xử lý upload ảnh cho sản phẩm
 func (h *ProductHandler) UploadProductImage(c *gin.Context) {
 	role := c.GetString("role")
 	if role != "admin" {
-		c.JSON(http.StatusForbidden, utils.NewErrorResponse(http.StatusForbidden, "Permission denied", "Only admin can upload product images"))
+		c.JSON(http.StatusForbidden, utils.NewErrorResponse(c, http.StatusForbidden, "Permission denied", "Only admin can upload product images"))
 		return
 	}
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid product ID", err.Error()))
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err.Error()))
 		return
 	}
-	product, err := h.repo.GetByID(uint(id))
+	product, err := h.repo.GetByID(c.Request.Context(), uint(id))
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, utils.NewErrorResponse(http.StatusNotFound, "Product not found", ""))
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product not found", ""))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Error fetching product", err.Error()))
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching product", err.Error()))
 		return
 	}
 	file, err := c.FormFile("image")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "No image file provided", err.Error()))
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "No image file provided", err.Error()))
 		return
 	}
 	if !isValidImageType(file.Header.Get("Content-Type")) {
-		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid file type", "Only JPG, PNG and GIF images are allowed"))
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid file type", "Only JPG, PNG and GIF images are allowed"))
 		return
 	}
 	ext := filepath.Ext(file.Filename)
 	filename := fmt.Sprintf("%d_%d%s", product.ID, time.Now().Unix(), ext)
 	uploadPath := filepath.Join("static", "uploads", filename)
 	if err := c.SaveUploadedFile(file, uploadPath); err != nil {
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Error saving file", err.Error()))
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error saving file", err.Error()))
 		return
 	}
-	product.ImageURL = "/" + uploadPath // Sử dụng uploadPath đã join
-	if err := h.repo.Update(product); err != nil {
+	product, err = h.service.SetImage(c.Request.Context(), product.ID, "/"+uploadPath) // Sử dụng uploadPath đã join
+	if err != nil {
 		os.Remove(uploadPath)
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Error updating product image URL", err.Error()))
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error updating product image URL", err.Error()))
 		return
 	}
-	c.JSON(http.StatusOK, utils.NewResponse(http.StatusOK, "Image uploaded successfully", gin.H{"image_url": product.ImageURL}))
+	// Thumbnail được tạo bởi worker nền
+	h.jobs.EnqueueLogged(c.Request.Context(), jobs.TypeProductThumbnail, jobs.ThumbnailPayload{ProductID: product.ID, ImagePath: uploadPath})
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Image uploaded successfully", gin.H{"image_url": product.ImageURL}))
+}
+
+// productETag dựng ETag từ version của sản phẩm
+func productETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ifMatchVersion đọc version từ header If-Match ("3" hoặc W/"3"); ok = false nếu không có header
+func ifMatchVersion(c *gin.Context) (version int, ok bool, err error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return 0, false, nil
+	}
+	value, err := strconv.Unquote(strings.TrimPrefix(header, "W/"))
+	if err != nil {
+		return 0, false, fmt.Errorf("expected a quoted ETag such as \"3\"")
+	}
+	version, err = strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, false, fmt.Errorf("ETag %s does not identify a product version", header)
+	}
+	return version, true, nil
 }
 
 func isValidImageType(contentType string) bool {
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/jobs"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// productVideoDir là thư mục lưu video sản phẩm và ảnh poster, phục vụ qua /uploads/videos
+var productVideoDir = filepath.Join("static", "uploads", "videos")
+
+// videoExtensions là định dạng video được nhận khi upload; file được chuyển mã sang MP4 bởi job product.media
+var videoExtensions = map[string]string{
+	"video/mp4":       ".mp4",
+	"video/quicktime": ".mov",
+	"video/webm":      ".webm",
+	"video/x-m4v":     ".m4v",
+}
+
+type ProductMediaHandler struct {
+	service *services.ProductMediaService
+	jobs    *jobs.Client
+	// maxVideoSize là dung lượng tối đa của một video upload (PRODUCT_VIDEO_MAX_MB, mặc định 200MB)
+	maxVideoSize int64
+}
+
+func NewProductMediaHandler(db *gorm.DB, jobClient *jobs.Client) *ProductMediaHandler {
+	return &ProductMediaHandler{
+		service:      services.NewProductMediaService(db),
+		jobs:         jobClient,
+		maxVideoSize: int64(config.Int("PRODUCT_VIDEO_MAX_MB", 200)) << 20,
+	}
+}
+
+// ListProductMedia lấy ảnh và video của sản phẩm; admin thấy cả video đang xử lý hoặc lỗi
+func (h *ProductMediaHandler) ListProductMedia(c *gin.Context) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	media, err := h.service.List(c.Request.Context(), productID, c.GetString("role") == "admin")
+	if err != nil {
+		h.handleError(c, err, "Error fetching product media")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Product media retrieved successfully", media))
+}
+
+// UploadProductVideo upload video (field "video") cho sản phẩm; video ở trạng thái processing cho tới khi
+// được chuyển mã (Admin only)
+func (h *ProductMediaHandler) UploadProductVideo(c *gin.Context) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	file, err := c.FormFile("video")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "No video file provided", err.Error()))
+		return
+	}
+	ext, ok := videoExtensions[file.Header.Get("Content-Type")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid file type", "Only MP4, MOV and WebM videos are allowed"))
+		return
+	}
+	if file.Size > h.maxVideoSize {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "File is too large", fmt.Sprintf("at most %d MB", h.maxVideoSize>>20)))
+		return
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error saving file", err.Error()))
+		return
+	}
+	uploadPath := filepath.Join(productVideoDir, fmt.Sprintf("%d_%s%s", productID, hex.EncodeToString(b), ext))
+	media, err := h.service.AddUpload(c.Request.Context(), productID, "/"+filepath.ToSlash(uploadPath), func() error {
+		if err := os.MkdirAll(productVideoDir, 0o755); err != nil {
+			return err
+		}
+		return c.SaveUploadedFile(file, uploadPath)
+	})
+	if err != nil {
+		os.Remove(uploadPath)
+		h.handleError(c, err, "Error saving file")
+		return
+	}
+	h.jobs.EnqueueLogged(c.Request.Context(), jobs.TypeProductMedia, jobs.MediaPayload{MediaID: media.ID})
+	c.JSON(http.StatusAccepted, utils.NewResponse(c, http.StatusAccepted, "Video uploaded, processing", media))
+}
+
+// AddExternalVideo gắn video YouTube/Vimeo vào sản phẩm (Admin only)
+func (h *ProductMediaHandler) AddExternalVideo(c *gin.Context) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	var req models.AddExternalVideoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	media, err := h.service.AddExternal(c.Request.Context(), productID, &req)
+	if err != nil {
+		h.handleError(c, err, "Error adding video")
+		return
+	}
+	h.jobs.EnqueueLogged(c.Request.Context(), jobs.TypeProductMedia, jobs.MediaPayload{MediaID: media.ID})
+	c.JSON(http.StatusAccepted, utils.NewResponse(c, http.StatusAccepted, "Video added, processing", media))
+}
+
+// DeleteProductMedia xóa video của sản phẩm cùng file video và poster đã upload (Admin only)
+func (h *ProductMediaHandler) DeleteProductMedia(c *gin.Context) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	mediaID, ok := parseQAParam(c, "media_id", "Invalid media ID")
+	if !ok {
+		return
+	}
+	media, err := h.service.Delete(c.Request.Context(), productID, mediaID)
+	if err != nil {
+		h.handleError(c, err, "Error deleting product media")
+		return
+	}
+	if media.Source == models.MediaSourceUpload {
+		for _, u := range []string{media.URL, media.PosterURL} {
+			path := filepath.Clean(filepath.FromSlash(strings.TrimPrefix(u, "/")))
+			if u != "" && strings.HasPrefix(path, productVideoDir+string(filepath.Separator)) {
+				os.Remove(path)
+			}
+		}
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Product media deleted successfully", nil))
+}
+
+func (h *ProductMediaHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product not found", ""))
+	case errors.Is(err, services.ErrProductMediaNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product media not found", ""))
+	case errors.Is(err, services.ErrProductMediaLimit):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Video limit reached", fmt.Sprintf("at most %d videos", models.MaxProductVideos)))
+	case errors.Is(err, services.ErrInvalidVideoURL):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid video URL", err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ProductRevisionHandler struct {
+	service *services.ProductRevisionService
+}
+
+func NewProductRevisionHandler(db *gorm.DB, bus *events.Bus) *ProductRevisionHandler {
+	return &ProductRevisionHandler{
+		service: services.NewProductRevisionService(db, bus),
+	}
+}
+
+// SubmitProductRevision gửi thay đổi sản phẩm chờ admin duyệt (biên tập viên và admin). Version bắt buộc
+// như khi sửa trực tiếp: header If-Match hoặc field version.
+func (h *ProductRevisionHandler) SubmitProductRevision(c *gin.Context) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	var req models.SubmitProductRevisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	version, ok, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid If-Match header", err.Error()))
+		return
+	}
+	if !ok && req.Version != nil {
+		version, ok = *req.Version, true
+	}
+	if !ok {
+		c.JSON(http.StatusPreconditionRequired, utils.NewErrorResponse(c, http.StatusPreconditionRequired, "Product version is required",
+			"Send the ETag from GET /products/:id in the If-Match header or the version field"))
+		return
+	}
+
+	revision, err := h.service.Submit(c.Request.Context(), c.GetUint("user_id"), productID, version, &req)
+	if err != nil {
+		h.handleError(c, err, "Error submitting product revision")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Product revision submitted for review", revision))
+}
+
+// ListProductRevisions lấy danh sách revision kèm diff; biên tập viên chỉ thấy revision của mình
+func (h *ProductRevisionHandler) ListProductRevisions(c *gin.Context) {
+	var query models.ProductRevisionQueryParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+	if c.GetString("role") != "admin" {
+		query.SubmittedBy = c.GetUint("user_id")
+	}
+
+	revisions, total, err := h.service.List(c.Request.Context(), &query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching product revisions", err.Error()))
+		return
+	}
+
+	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	filters := map[string]interface{}{}
+	if query.Status != "" {
+		filters["status"] = query.Status
+	}
+	if query.ProductID != 0 {
+		filters["product_id"] = query.ProductID
+	}
+	if query.SubmittedBy != 0 {
+		filters["submitted_by"] = query.SubmittedBy
+	}
+	c.JSON(http.StatusOK, utils.NewPaginatedResponse(
+		c, http.StatusOK, "Product revisions retrieved successfully", revisions,
+		query.Page, totalPages, total, query.Limit, filters,
+	))
+}
+
+// GetProductRevision lấy revision kèm diff; biên tập viên chỉ xem được revision của mình
+func (h *ProductRevisionHandler) GetProductRevision(c *gin.Context) {
+	id, ok := parseQAParam(c, "id", "Invalid revision ID")
+	if !ok {
+		return
+	}
+	var submittedBy uint
+	if c.GetString("role") != "admin" {
+		submittedBy = c.GetUint("user_id")
+	}
+	revision, err := h.service.Get(c.Request.Context(), id, submittedBy)
+	if err != nil {
+		h.handleError(c, err, "Error fetching product revision")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Product revision retrieved successfully", revision))
+}
+
+// CancelProductRevision cho người gửi rút lại revision còn chờ duyệt
+func (h *ProductRevisionHandler) CancelProductRevision(c *gin.Context) {
+	id, ok := parseQAParam(c, "id", "Invalid revision ID")
+	if !ok {
+		return
+	}
+	revision, err := h.service.Cancel(c.Request.Context(), c.GetUint("user_id"), id)
+	if err != nil {
+		h.handleError(c, err, "Error cancelling product revision")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Product revision cancelled", revision))
+}
+
+// ApproveProductRevision duyệt và áp dụng revision vào sản phẩm (Admin only, không phải người gửi)
+func (h *ProductRevisionHandler) ApproveProductRevision(c *gin.Context) {
+	id, ok := parseQAParam(c, "id", "Invalid revision ID")
+	if !ok {
+		return
+	}
+	var req models.ApproveProductRevisionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			validation.Respond(c, err)
+			return
+		}
+	}
+	revision, err := h.service.Approve(c.Request.Context(), c.GetUint("user_id"), id, req.Note)
+	if err != nil {
+		h.handleError(c, err, "Error approving product revision")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Product revision approved", revision))
+}
+
+// RejectProductRevision từ chối revision kèm lý do (Admin only, không phải người gửi)
+func (h *ProductRevisionHandler) RejectProductRevision(c *gin.Context) {
+	id, ok := parseQAParam(c, "id", "Invalid revision ID")
+	if !ok {
+		return
+	}
+	var req models.RejectProductRevisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	revision, err := h.service.Reject(c.Request.Context(), c.GetUint("user_id"), id, req.Note)
+	if err != nil {
+		h.handleError(c, err, "Error rejecting product revision")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Product revision rejected", revision))
+}
+
+func (h *ProductRevisionHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product not found", ""))
+	case errors.Is(err, services.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product revision not found", ""))
+	case errors.Is(err, services.ErrProductVersionConflict):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Product was modified by another request", ""))
+	case errors.Is(err, services.ErrRevisionNotPending):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Product revision has already been resolved", err.Error()))
+	case errors.Is(err, services.ErrRevisionConflict):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Product revision conflicts with later changes", err.Error()))
+	case errors.Is(err, services.ErrRevisionSelfReview):
+		c.JSON(http.StatusForbidden, utils.NewErrorResponse(c, http.StatusForbidden, "You cannot review your own revision", ""))
+	case errors.Is(err, services.ErrRevisionEmpty):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Product revision does not change anything", ""))
+	case errors.Is(err, services.ErrProductNameExists):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Another product with this name already exists", ""))
+	case errors.Is(err, services.ErrProductSKUExists):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Product SKU already exists", ""))
+	case errors.Is(err, services.ErrInvalidSpecs):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid product specs", err.Error()))
+	case errors.Is(err, services.ErrInvalidProductSchedule):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid publish schedule", err.Error()))
+	case errors.Is(err, services.ErrInvalidImageURL):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid image URL", err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
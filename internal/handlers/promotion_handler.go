@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PromotionHandler struct {
+	service *services.PromotionService
+}
+
+func NewPromotionHandler(db *gorm.DB) *PromotionHandler {
+	return &PromotionHandler{
+		service: services.NewPromotionService(db),
+	}
+}
+
+// ListActivePromotions lấy các khuyến mãi đang hiệu lực để hiển thị cho khách
+func (h *PromotionHandler) ListActivePromotions(c *gin.Context) {
+	promotions, err := h.service.ListActive(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching promotions", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Promotions retrieved successfully", promotions))
+}
+
+// ListPromotions lấy danh sách khuyến mãi, lọc theo active và type (Admin only)
+func (h *PromotionHandler) ListPromotions(c *gin.Context) {
+	var query models.PromotionQueryParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	promotions, err := h.service.List(c.Request.Context(), &query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching promotions", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Promotions retrieved successfully", promotions))
+}
+
+// GetPromotion lấy chi tiết khuyến mãi (Admin only)
+func (h *PromotionHandler) GetPromotion(c *gin.Context) {
+	id, ok := parsePromotionID(c)
+	if !ok {
+		return
+	}
+	promotion, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Error fetching promotion")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Promotion retrieved successfully", promotion))
+}
+
+// CreatePromotion tạo khuyến mãi tự động (Admin only)
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var req models.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	promotion, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err, "Error creating promotion")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Promotion created successfully", promotion))
+}
+
+// UpdatePromotion sửa khuyến mãi (Admin only)
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	id, ok := parsePromotionID(c)
+	if !ok {
+		return
+	}
+	var req models.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	promotion, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleError(c, err, "Error updating promotion")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Promotion updated successfully", promotion))
+}
+
+// DeletePromotion xóa khuyến mãi (Admin only)
+func (h *PromotionHandler) DeletePromotion(c *gin.Context) {
+	id, ok := parsePromotionID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		h.handleError(c, err, "Error deleting promotion")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Promotion deleted successfully", nil))
+}
+
+func (h *PromotionHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrPromotionNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Promotion not found", ""))
+	case errors.Is(err, services.ErrInvalidPromotion):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid promotion", err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
+
+func parsePromotionID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid promotion ID", err.Error()))
+		return 0, false
+	}
+	return uint(id), true
+}
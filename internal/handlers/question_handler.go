@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type QuestionHandler struct {
+	service *services.QuestionService
+}
+
+func NewQuestionHandler(db *gorm.DB) *QuestionHandler {
+	return &QuestionHandler{
+		service: services.NewQuestionService(db),
+	}
+}
+
+// ListQuestions lấy các câu hỏi đã duyệt của sản phẩm kèm câu trả lời đã duyệt (Public)
+func (h *QuestionHandler) ListQuestions(c *gin.Context) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	var query models.QuestionQueryParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 10
+	}
+
+	questions, total, err := h.service.ListApproved(c.Request.Context(), productID, &query)
+	if err != nil {
+		h.handleError(c, err, "Error fetching questions")
+		return
+	}
+	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	c.JSON(http.StatusOK, utils.NewPaginatedResponse(
+		c, http.StatusOK, "Questions retrieved successfully", questions,
+		query.Page, totalPages, total, query.Limit, map[string]interface{}{"product_id": productID},
+	))
+}
+
+// AskQuestion đặt câu hỏi về sản phẩm; câu hỏi chờ admin duyệt trước khi hiển thị
+func (h *QuestionHandler) AskQuestion(c *gin.Context) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	var req models.CreateQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	question, err := h.service.Ask(c.Request.Context(), c.GetUint("user_id"), c.GetString("role"), productID, req.Body)
+	if err != nil {
+		h.handleError(c, err, "Error creating question")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Question submitted successfully", question))
+}
+
+// AnswerQuestion trả lời câu hỏi; chỉ admin và user đã mua sản phẩm được trả lời
+func (h *QuestionHandler) AnswerQuestion(c *gin.Context) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	questionID, ok := parseQAParam(c, "question_id", "Invalid question ID")
+	if !ok {
+		return
+	}
+	var req models.CreateAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	answer, err := h.service.Answer(c.Request.Context(), c.GetUint("user_id"), c.GetString("role"), productID, questionID, req.Body)
+	if err != nil {
+		h.handleError(c, err, "Error creating answer")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Answer submitted successfully", answer))
+}
+
+// ModerateQuestion duyệt hoặc từ chối câu hỏi (Admin only)
+func (h *QuestionHandler) ModerateQuestion(c *gin.Context) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	questionID, ok := parseQAParam(c, "question_id", "Invalid question ID")
+	if !ok {
+		return
+	}
+	var req models.ModerateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	question, err := h.service.ModerateQuestion(c.Request.Context(), c.GetUint("user_id"), productID, questionID, req.Status)
+	if err != nil {
+		h.handleError(c, err, "Error moderating question")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Question moderated successfully", question))
+}
+
+// ModerateAnswer duyệt hoặc từ chối câu trả lời (Admin only)
+func (h *QuestionHandler) ModerateAnswer(c *gin.Context) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	questionID, ok := parseQAParam(c, "question_id", "Invalid question ID")
+	if !ok {
+		return
+	}
+	answerID, ok := parseQAParam(c, "answer_id", "Invalid answer ID")
+	if !ok {
+		return
+	}
+	var req models.ModerateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	answer, err := h.service.ModerateAnswer(c.Request.Context(), c.GetUint("user_id"), productID, questionID, answerID, req.Status)
+	if err != nil {
+		h.handleError(c, err, "Error moderating answer")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Answer moderated successfully", answer))
+}
+
+// ListModerationQuestions lấy câu hỏi theo trạng thái kiểm duyệt, cũ nhất trước (Admin only)
+func (h *QuestionHandler) ListModerationQuestions(c *gin.Context) {
+	query, ok := bindModerationQuery(c)
+	if !ok {
+		return
+	}
+	questions, total, err := h.service.ListQuestions(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching questions", err.Error()))
+		return
+	}
+	respondModeration(c, "Questions retrieved successfully", questions, total, query)
+}
+
+// ListModerationAnswers lấy câu trả lời theo trạng thái kiểm duyệt, cũ nhất trước (Admin only)
+func (h *QuestionHandler) ListModerationAnswers(c *gin.Context) {
+	query, ok := bindModerationQuery(c)
+	if !ok {
+		return
+	}
+	answers, total, err := h.service.ListAnswers(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching answers", err.Error()))
+		return
+	}
+	respondModeration(c, "Answers retrieved successfully", answers, total, query)
+}
+
+func bindModerationQuery(c *gin.Context) (*models.ModerationQueryParams, bool) {
+	var query models.ModerationQueryParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		return nil, false
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+	return &query, true
+}
+
+func respondModeration(c *gin.Context, message string, data interface{}, total int64, query *models.ModerationQueryParams) {
+	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	filters := map[string]interface{}{}
+	if query.Status != "" {
+		filters["status"] = query.Status
+	}
+	if query.ProductID != 0 {
+		filters["product_id"] = query.ProductID
+	}
+	c.JSON(http.StatusOK, utils.NewPaginatedResponse(c, http.StatusOK, message, data, query.Page, totalPages, total, query.Limit, filters))
+}
+
+func (h *QuestionHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product not found", ""))
+	case errors.Is(err, services.ErrQuestionNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Question not found", ""))
+	case errors.Is(err, services.ErrAnswerNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Answer not found", ""))
+	case errors.Is(err, services.ErrNotVerifiedBuyer):
+		c.JSON(http.StatusForbidden, utils.NewErrorResponse(c, http.StatusForbidden, "Only admins and customers who bought this product can answer", ""))
+	case errors.Is(err, services.ErrQuestionNotOpen):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Question is not open for answers", ""))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
+
+// parseQAParam đọc id trong path param name
+func parseQAParam(c *gin.Context, name, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, message, err.Error()))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxReportRangeDays giới hạn khoảng ngày của báo cáo doanh số (3 năm)
+const maxReportRangeDays = 1096
+
+// defaultReportProductLimit là số sản phẩm mặc định trong bảng doanh số theo sản phẩm
+const defaultReportProductLimit = 50
+
+type ReportHandler struct {
+	repo *repository.ReportRepository
+}
+
+func NewReportHandler(db *gorm.DB) *ReportHandler {
+	return &ReportHandler{
+		repo: repository.NewReportRepository(db),
+	}
+}
+
+// GetSalesReport trả về doanh số trong khoảng from/to nhóm theo ngày/tuần/tháng, kèm bảng theo
+// danh mục và theo sản phẩm (Admin only). format=csv xuất một bảng (section) dưới dạng CSV.
+func (h *ReportHandler) GetSalesReport(c *gin.Context) {
+	var query models.SalesReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	if query.GroupBy == "" {
+		query.GroupBy = models.ReportGroupDay
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultReportProductLimit
+	}
+	if query.Section == "" {
+		query.Section = "periods"
+	}
+	from, to, ok := parseDateRange(c, 30, maxReportRangeDays)
+	if !ok {
+		return
+	}
+
+	report := &models.SalesReport{
+		From:    from.Format("2006-01-02"),
+		To:      to.Format("2006-01-02"),
+		GroupBy: query.GroupBy,
+	}
+	csvOnly := query.Format == "csv"
+	var err error
+	if !csvOnly || query.Section == "periods" {
+		if report.Periods, err = h.repo.SalesByPeriod(c.Request.Context(), from, to, query.GroupBy); err != nil {
+			h.fail(c, err)
+			return
+		}
+	}
+	if !csvOnly || query.Section == "categories" {
+		if report.Categories, err = h.repo.SalesByCategory(c.Request.Context(), from, to); err != nil {
+			h.fail(c, err)
+			return
+		}
+	}
+	if !csvOnly || query.Section == "products" {
+		if report.Products, err = h.repo.SalesByProduct(c.Request.Context(), from, to, query.Limit); err != nil {
+			h.fail(c, err)
+			return
+		}
+	}
+
+	if csvOnly {
+		h.writeCSV(c, report, query.Section)
+		return
+	}
+
+	for _, p := range report.Periods {
+		report.Totals.Invoices += p.Invoices
+		report.Totals.Units += p.Units
+		report.Totals.NetSales += p.NetSales
+		report.Totals.Tax += p.Tax
+		report.Totals.GrossSales += p.GrossSales
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Sales report retrieved successfully", report))
+}
+
+func (h *ReportHandler) fail(c *gin.Context, err error) {
+	c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error generating sales report", err.Error()))
+}
+
+// writeCSV ghi một bảng của báo cáo ra CSV (dấu phẩy, dòng đầu là tên cột)
+func (h *ReportHandler) writeCSV(c *gin.Context, report *models.SalesReport, section string) {
+	filename := fmt.Sprintf("sales-%s-%s-%s.csv", section, report.From, report.To)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	switch section {
+	case "categories":
+		w.Write([]string{"category", "invoices", "units", "net_sales"})
+		for _, r := range report.Categories {
+			w.Write([]string{r.Category, itoa(r.Invoices), itoa(r.Units), money(r.NetSales)})
+		}
+	case "products":
+		w.Write([]string{"product_id", "name", "category", "invoices", "units", "net_sales"})
+		for _, r := range report.Products {
+			w.Write([]string{itoa(int64(r.ProductID)), r.Name, r.Category, itoa(r.Invoices), itoa(r.Units), money(r.NetSales)})
+		}
+	default:
+		w.Write([]string{"period_start", "invoices", "units", "net_sales", "tax", "gross_sales"})
+		for _, r := range report.Periods {
+			w.Write([]string{r.PeriodStart, itoa(r.Invoices), itoa(r.Units), money(r.NetSales), money(r.Tax), money(r.GrossSales)})
+		}
+	}
+	w.Flush()
+}
+
+func itoa(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+// money định dạng số tiền với tối đa 2 chữ số thập phân, không dùng ký hiệu mũ
+func money(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/payment"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxReturnPhotoSize giới hạn dung lượng một ảnh đính kèm yêu cầu trả hàng (5MB)
+const maxReturnPhotoSize = 5 << 20
+
+// returnPhotoDir là thư mục lưu ảnh trả hàng, phục vụ qua /uploads/returns
+var returnPhotoDir = filepath.Join("static", "uploads", "returns")
+
+type ReturnHandler struct {
+	service *services.ReturnService
+}
+
+func NewReturnHandler(db *gorm.DB, gateways payment.Gateways, bus *events.Bus) *ReturnHandler {
+	return &ReturnHandler{
+		service: services.NewReturnService(db, gateways, bus),
+	}
+}
+
+// CreateReturn tạo yêu cầu trả hàng cho đơn đã giao của user hiện tại
+func (h *ReturnHandler) CreateReturn(c *gin.Context) {
+	orderID, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+	var req models.CreateReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	ret, err := h.service.Create(c.Request.Context(), c.GetUint("user_id"), orderID, &req)
+	if err != nil {
+		h.handleError(c, err, "Error creating return request")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Return request created successfully", ret))
+}
+
+// ListOrderReturns lấy các yêu cầu trả hàng của một đơn của user hiện tại
+func (h *ReturnHandler) ListOrderReturns(c *gin.Context) {
+	orderID, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+	returns, err := h.service.ListByOrder(c.Request.Context(), c.GetUint("user_id"), orderID)
+	if err != nil {
+		h.handleError(c, err, "Error fetching return requests")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Return requests retrieved successfully", returns))
+}
+
+// GetReturn lấy một yêu cầu trả hàng của user hiện tại
+func (h *ReturnHandler) GetReturn(c *gin.Context) {
+	h.getReturn(c, c.GetUint("user_id"))
+}
+
+// AdminGetReturn lấy một yêu cầu trả hàng bất kỳ (Admin only)
+func (h *ReturnHandler) AdminGetReturn(c *gin.Context) {
+	h.getReturn(c, 0)
+}
+
+func (h *ReturnHandler) getReturn(c *gin.Context, userID uint) {
+	id, ok := parseReturnID(c)
+	if !ok {
+		return
+	}
+	ret, err := h.service.Get(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err, "Error fetching return request")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Return request retrieved successfully", ret))
+}
+
+// UploadReturnPhoto đính kèm một ảnh (field "photo") vào yêu cầu trả hàng còn chờ xử lý
+func (h *ReturnHandler) UploadReturnPhoto(c *gin.Context) {
+	id, ok := parseReturnID(c)
+	if !ok {
+		return
+	}
+	file, err := c.FormFile("photo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "No image file provided", err.Error()))
+		return
+	}
+	if !isValidImageType(file.Header.Get("Content-Type")) {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid file type", "Only JPG, PNG and GIF images are allowed"))
+		return
+	}
+	if file.Size > maxReturnPhotoSize {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "File is too large", fmt.Sprintf("at most %d MB", maxReturnPhotoSize>>20)))
+		return
+	}
+
+	// Tên file ngẫu nhiên để ảnh của khách không đoán được qua đường dẫn /uploads công khai
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error saving file", err.Error()))
+		return
+	}
+	filename := fmt.Sprintf("%d_%s%s", id, hex.EncodeToString(b), strings.ToLower(filepath.Ext(file.Filename)))
+	uploadPath := filepath.Join(returnPhotoDir, filename)
+	photo, err := h.service.AddPhoto(c.Request.Context(), c.GetUint("user_id"), id, "/"+filepath.ToSlash(uploadPath), func() error {
+		if err := os.MkdirAll(returnPhotoDir, 0o755); err != nil {
+			return err
+		}
+		return c.SaveUploadedFile(file, uploadPath)
+	})
+	if err != nil {
+		os.Remove(uploadPath)
+		h.handleError(c, err, "Error saving file")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Photo uploaded successfully", photo))
+}
+
+// CancelReturn rút lại yêu cầu trả hàng còn chờ xử lý của user hiện tại
+func (h *ReturnHandler) CancelReturn(c *gin.Context) {
+	id, ok := parseReturnID(c)
+	if !ok {
+		return
+	}
+	ret, err := h.service.Cancel(c.Request.Context(), c.GetUint("user_id"), id)
+	if err != nil {
+		h.handleError(c, err, "Error cancelling return request")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Return request cancelled", ret))
+}
+
+// ListReturns lấy danh sách yêu cầu trả hàng, lọc theo status và order_id (Admin only)
+func (h *ReturnHandler) ListReturns(c *gin.Context) {
+	var query models.ReturnQueryParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+
+	returns, total, err := h.service.List(c.Request.Context(), &query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching return requests", err.Error()))
+		return
+	}
+
+	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	filters := map[string]interface{}{}
+	if query.Status != "" {
+		filters["status"] = query.Status
+	}
+	if query.OrderID != 0 {
+		filters["order_id"] = query.OrderID
+	}
+	c.JSON(http.StatusOK, utils.NewPaginatedResponse(
+		c, http.StatusOK, "Return requests retrieved successfully", returns,
+		query.Page, totalPages, total, query.Limit, filters,
+	))
+}
+
+// ApproveReturn duyệt yêu cầu trả hàng: nhập lại kho và hoàn tiền cho khách (Admin only)
+func (h *ReturnHandler) ApproveReturn(c *gin.Context) {
+	id, ok := parseReturnID(c)
+	if !ok {
+		return
+	}
+	var req models.ApproveReturnRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			validation.Respond(c, err)
+			return
+		}
+	}
+	ret, err := h.service.Approve(c.Request.Context(), c.GetUint("user_id"), c.ClientIP(), id, &req)
+	if err != nil {
+		h.handleError(c, err, "Error approving return request")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Return request approved", ret))
+}
+
+// RejectReturn từ chối yêu cầu trả hàng kèm lý do (Admin only)
+func (h *ReturnHandler) RejectReturn(c *gin.Context) {
+	id, ok := parseReturnID(c)
+	if !ok {
+		return
+	}
+	var req models.RejectReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	ret, err := h.service.Reject(c.Request.Context(), c.GetUint("user_id"), id, req.Note)
+	if err != nil {
+		h.handleError(c, err, "Error rejecting return request")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Return request rejected", ret))
+}
+
+func (h *ReturnHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrReturnNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Return request not found", ""))
+	case errors.Is(err, services.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Order not found", ""))
+	case errors.Is(err, services.ErrReturnNotAllowed):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Order cannot be returned", err.Error()))
+	case errors.Is(err, services.ErrReturnItemInvalid):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Item does not belong to the order", err.Error()))
+	case errors.Is(err, services.ErrReturnQuantity):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Return quantity exceeds the quantity that can be returned", err.Error()))
+	case errors.Is(err, services.ErrReturnNotPending):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Return request has already been resolved", err.Error()))
+	case errors.Is(err, services.ErrReturnPhotoLimit):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Photo limit reached", fmt.Sprintf("at most %d photos", models.MaxReturnPhotos)))
+	case errors.Is(err, services.ErrOrderNotPaid):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Order has no captured payment", ""))
+	case errors.Is(err, services.ErrRefundExceedsPayment):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Refund amount exceeds the refundable amount", err.Error()))
+	case errors.Is(err, payment.ErrUnsupportedCurrency):
+		c.JSON(http.StatusUnprocessableEntity, utils.NewErrorResponse(c, http.StatusUnprocessableEntity, "Currency is not supported by the payment provider", err.Error()))
+	case errors.Is(err, payment.ErrDeclined):
+		c.JSON(http.StatusPaymentRequired, utils.NewErrorResponse(c, http.StatusPaymentRequired, "Payment was declined", err.Error()))
+	case errors.Is(err, payment.ErrNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, utils.NewErrorResponse(c, http.StatusServiceUnavailable, "Payments are not configured", err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
+
+func parseReturnID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid return request ID", err.Error()))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ReviewHandler struct {
+	service *services.ReviewService
+}
+
+func NewReviewHandler(db *gorm.DB) *ReviewHandler {
+	return &ReviewHandler{
+		service: services.NewReviewService(db),
+	}
+}
+
+// ListReviews lấy các đánh giá đã duyệt của sản phẩm (Public)
+func (h *ReviewHandler) ListReviews(c *gin.Context) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	var query models.ReviewQueryParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 10
+	}
+
+	reviews, total, err := h.service.ListApproved(c.Request.Context(), productID, &query)
+	if err != nil {
+		h.handleError(c, err, "Error fetching reviews")
+		return
+	}
+	filters := map[string]interface{}{"product_id": productID}
+	if query.Rating != 0 {
+		filters["rating"] = query.Rating
+	}
+	if query.Verified {
+		filters["verified"] = true
+	}
+	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	c.JSON(http.StatusOK, utils.NewPaginatedResponse(
+		c, http.StatusOK, "Reviews retrieved successfully", reviews,
+		query.Page, totalPages, total, query.Limit, filters,
+	))
+}
+
+// CreateReview đánh giá sản phẩm; đánh giá chờ admin duyệt trước khi hiển thị
+func (h *ReviewHandler) CreateReview(c *gin.Context) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	var req models.CreateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	review, err := h.service.Create(c.Request.Context(), c.GetUint("user_id"), productID, &req)
+	if err != nil {
+		h.handleError(c, err, "Error creating review")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Review submitted successfully", review))
+}
+
+// VoteReview bình chọn đánh giá hữu ích hoặc không hữu ích; bình chọn lại sẽ ghi đè phiếu cũ
+func (h *ReviewHandler) VoteReview(c *gin.Context) {
+	productID, reviewID, ok := parseReviewParams(c)
+	if !ok {
+		return
+	}
+	var req models.VoteReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	review, err := h.service.Vote(c.Request.Context(), c.GetUint("user_id"), productID, reviewID, *req.Helpful)
+	if err != nil {
+		h.handleError(c, err, "Error voting on review")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Vote recorded successfully", review))
+}
+
+// UnvoteReview gỡ phiếu bình chọn của user
+func (h *ReviewHandler) UnvoteReview(c *gin.Context) {
+	productID, reviewID, ok := parseReviewParams(c)
+	if !ok {
+		return
+	}
+	review, err := h.service.Unvote(c.Request.Context(), c.GetUint("user_id"), productID, reviewID)
+	if err != nil {
+		h.handleError(c, err, "Error removing vote")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Vote removed successfully", review))
+}
+
+// ReportReview báo cáo vi phạm một đánh giá
+func (h *ReviewHandler) ReportReview(c *gin.Context) {
+	productID, reviewID, ok := parseReviewParams(c)
+	if !ok {
+		return
+	}
+	var req models.ReportReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	report, err := h.service.Report(c.Request.Context(), c.GetUint("user_id"), productID, reviewID, req.Reason)
+	if err != nil {
+		h.handleError(c, err, "Error reporting review")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Review reported successfully", report))
+}
+
+// ModerateReview duyệt hoặc từ chối đánh giá (Admin only)
+func (h *ReviewHandler) ModerateReview(c *gin.Context) {
+	productID, reviewID, ok := parseReviewParams(c)
+	if !ok {
+		return
+	}
+	var req models.ModerateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	review, err := h.service.Moderate(c.Request.Context(), c.GetUint("user_id"), productID, reviewID, req.Status)
+	if err != nil {
+		h.handleError(c, err, "Error moderating review")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Review moderated successfully", review))
+}
+
+// ListModerationReviews lấy đánh giá theo trạng thái kiểm duyệt, cũ nhất trước (Admin only)
+func (h *ReviewHandler) ListModerationReviews(c *gin.Context) {
+	query, ok := bindModerationQuery(c)
+	if !ok {
+		return
+	}
+	reviews, total, err := h.service.List(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching reviews", err.Error()))
+		return
+	}
+	respondModeration(c, "Reviews retrieved successfully", reviews, total, query)
+}
+
+// ListReportedReviews lấy các đánh giá còn báo cáo vi phạm chưa xử lý, nhiều báo cáo nhất trước (Admin only)
+func (h *ReviewHandler) ListReportedReviews(c *gin.Context) {
+	query, ok := bindModerationQuery(c)
+	if !ok {
+		return
+	}
+	reviews, total, err := h.service.ListReported(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching reviews", err.Error()))
+		return
+	}
+	respondModeration(c, "Reviews retrieved successfully", reviews, total, query)
+}
+
+func (h *ReviewHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Product not found", ""))
+	case errors.Is(err, services.ErrReviewNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Review not found", ""))
+	case errors.Is(err, services.ErrAlreadyReviewed):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "You have already reviewed this product", ""))
+	case errors.Is(err, services.ErrOwnReview):
+		c.JSON(http.StatusForbidden, utils.NewErrorResponse(c, http.StatusForbidden, "You cannot vote on or report your own review", ""))
+	case errors.Is(err, services.ErrAlreadyReported):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "You have already reported this review", ""))
+	case errors.Is(err, services.ErrVoteNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Vote not found", ""))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
+
+// parseReviewParams đọc ID sản phẩm và ID đánh giá trong path
+func parseReviewParams(c *gin.Context) (uint, uint, bool) {
+	productID, ok := parseQAParam(c, "id", "Invalid product ID")
+	if !ok {
+		return 0, 0, false
+	}
+	reviewID, ok := parseQAParam(c, "review_id", "Invalid review ID")
+	if !ok {
+		return 0, 0, false
+	}
+	return productID, reviewID, true
+}
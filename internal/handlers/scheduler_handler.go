@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/NgTruong624/project_backend/internal/scheduler"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type SchedulerHandler struct {
+	scheduler *scheduler.Scheduler
+}
+
+func NewSchedulerHandler(s *scheduler.Scheduler) *SchedulerHandler {
+	return &SchedulerHandler{scheduler: s}
+}
+
+// ListTasks lấy danh sách tác vụ định kỳ cùng trạng thái lần chạy gần nhất (Admin only)
+func (h *SchedulerHandler) ListTasks(c *gin.Context) {
+	tasks, err := h.scheduler.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching scheduled tasks", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Scheduled tasks retrieved successfully", tasks))
+}
+
+// RunTask yêu cầu chạy tác vụ ngay ở lần kiểm tra kế tiếp (Admin only)
+func (h *SchedulerHandler) RunTask(c *gin.Context) {
+	found, err := h.scheduler.Trigger(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error triggering task", err.Error()))
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Task not found", ""))
+		return
+	}
+	c.JSON(http.StatusAccepted, utils.NewResponse(c, http.StatusAccepted, "Task scheduled to run", nil))
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SearchSynonymHandler struct {
+	service *services.SearchSynonymService
+}
+
+func NewSearchSynonymHandler(db *gorm.DB) *SearchSynonymHandler {
+	return &SearchSynonymHandler{
+		service: services.NewSearchSynonymService(db),
+	}
+}
+
+// ListSearchSynonyms lấy từ điển đồng nghĩa dùng khi tìm kiếm sản phẩm (Admin only)
+func (h *SearchSynonymHandler) ListSearchSynonyms(c *gin.Context) {
+	synonyms, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching search synonyms", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Search synonyms retrieved successfully", synonyms))
+}
+
+// CreateSearchSynonym thêm mục đồng nghĩa cho một từ khóa (Admin only)
+func (h *SearchSynonymHandler) CreateSearchSynonym(c *gin.Context) {
+	var req models.SearchSynonymRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	synonym, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err, "Error creating search synonym")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Search synonym created successfully", synonym))
+}
+
+// UpdateSearchSynonym thay thế mục đồng nghĩa (Admin only)
+func (h *SearchSynonymHandler) UpdateSearchSynonym(c *gin.Context) {
+	id, ok := parseSearchSynonymID(c)
+	if !ok {
+		return
+	}
+	var req models.SearchSynonymRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	synonym, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleError(c, err, "Error updating search synonym")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Search synonym updated successfully", synonym))
+}
+
+// DeleteSearchSynonym xóa mục đồng nghĩa (Admin only)
+func (h *SearchSynonymHandler) DeleteSearchSynonym(c *gin.Context) {
+	id, ok := parseSearchSynonymID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		h.handleError(c, err, "Error deleting search synonym")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Search synonym deleted successfully", nil))
+}
+
+func (h *SearchSynonymHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrSearchSynonymNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Search synonym not found", ""))
+	case errors.Is(err, services.ErrSearchSynonymExists):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Search synonym already exists", err.Error()))
+	case errors.Is(err, services.ErrInvalidSearchSynonym):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid search synonym", err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
+
+func parseSearchSynonymID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid search synonym ID", err.Error()))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SitemapHandler struct {
+	service *services.SitemapService
+}
+
+func NewSitemapHandler(db *gorm.DB) *SitemapHandler {
+	return &SitemapHandler{
+		service: services.NewSitemapService(db),
+	}
+}
+
+// GetSitemapIndex trả về sitemap index trỏ tới các sitemap sản phẩm và danh mục (Public)
+func (h *SitemapHandler) GetSitemapIndex(c *gin.Context) {
+	h.serve(c, services.SitemapIndex)
+}
+
+// GetSitemap trả về một sitemap con, ví dụ /sitemaps/products-1.xml (Public)
+func (h *SitemapHandler) GetSitemap(c *gin.Context) {
+	name, ok := strings.CutSuffix(c.Param("file"), ".xml")
+	if !ok || name == services.SitemapIndex {
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Sitemap not found", ""))
+		return
+	}
+	h.serve(c, name)
+}
+
+func (h *SitemapHandler) serve(c *gin.Context, name string) {
+	sitemap, err := h.service.Get(c.Request.Context(), name)
+	if err != nil {
+		if errors.Is(err, services.ErrSitemapNotFound) {
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Sitemap not found", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching sitemap", err.Error()))
+		return
+	}
+	// Sitemap chỉ đổi khi tác vụ sitemap_generate chạy lại
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Header("Last-Modified", sitemap.GeneratedAt.UTC().Format(http.TimeFormat))
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", sitemap.Content)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/sse"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const streamHeartbeat = 25 * time.Second
+
+type StreamHandler struct {
+	broker *sse.Broker
+}
+
+func NewStreamHandler(broker *sse.Broker) *StreamHandler {
+	return &StreamHandler{broker: broker}
+}
+
+// ProductStream gửi thay đổi tồn kho và giá sản phẩm qua Server-Sent Events (Public).
+// Tham số ids (ví dụ ?ids=1,2,3) giới hạn các sản phẩm cần theo dõi.
+func (h *StreamHandler) ProductStream(c *gin.Context) {
+	var filter map[uint]bool
+	if ids := c.Query("ids"); ids != "" {
+		filter = make(map[uint]bool)
+		for _, part := range strings.Split(ids, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid product IDs", err.Error()))
+				return
+			}
+			filter[uint(id)] = true
+		}
+	}
+
+	messages, unsubscribe := h.broker.Subscribe()
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // tắt buffer của nginx
+	c.Status(http.StatusOK)
+	c.Writer.WriteString("retry: 5000\n\n")
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case msg := <-messages:
+			if filter != nil && !filter[msg.ProductID] {
+				return true
+			}
+			data, err := json.Marshal(msg)
+			if err != nil {
+				return true
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Event, data)
+			return true
+		case <-heartbeat.C:
+			io.WriteString(w, ": ping\n\n")
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
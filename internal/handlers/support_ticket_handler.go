@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SupportTicketHandler struct {
+	service *services.SupportTicketService
+}
+
+func NewSupportTicketHandler(db *gorm.DB, bus *events.Bus) *SupportTicketHandler {
+	return &SupportTicketHandler{
+		service: services.NewSupportTicketService(db, bus),
+	}
+}
+
+// CreateTicket gửi phiếu hỗ trợ từ form liên hệ (public). Khách chưa đăng nhập phải gửi email (captcha được
+// kiểm tra bởi middleware.Captcha); user đã đăng nhập có thể gắn order_id của mình.
+func (h *SupportTicketHandler) CreateTicket(c *gin.Context) {
+	var req models.CreateSupportTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+
+	ticket, err := h.service.Create(c.Request.Context(), c.GetUint("user_id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Error creating support ticket")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Support ticket created successfully", ticket))
+}
+
+// ListMyTickets lấy các phiếu hỗ trợ của user hiện tại, phiếu có tin nhắn mới nhất trước
+func (h *SupportTicketHandler) ListMyTickets(c *gin.Context) {
+	h.list(c, c.GetUint("user_id"))
+}
+
+// ListTickets lấy danh sách phiếu hỗ trợ, lọc theo status, category, user_id và email (Admin only)
+func (h *SupportTicketHandler) ListTickets(c *gin.Context) {
+	h.list(c, 0)
+}
+
+func (h *SupportTicketHandler) list(c *gin.Context, userID uint) {
+	var query models.SupportTicketQueryParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+	if userID != 0 {
+		query.UserID, query.Email = userID, ""
+	}
+
+	tickets, total, err := h.service.List(c.Request.Context(), &query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching support tickets", err.Error()))
+		return
+	}
+
+	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	filters := map[string]interface{}{}
+	if query.Status != "" {
+		filters["status"] = query.Status
+	}
+	if query.Category != "" {
+		filters["category"] = query.Category
+	}
+	if userID == 0 && query.UserID != 0 {
+		filters["user_id"] = query.UserID
+	}
+	if query.Email != "" {
+		filters["email"] = query.Email
+	}
+	c.JSON(http.StatusOK, utils.NewPaginatedResponse(
+		c, http.StatusOK, "Support tickets retrieved successfully", tickets,
+		query.Page, totalPages, total, query.Limit, filters,
+	))
+}
+
+// GetMyTicket lấy phiếu hỗ trợ của user hiện tại kèm các tin nhắn
+func (h *SupportTicketHandler) GetMyTicket(c *gin.Context) {
+	h.get(c, c.GetUint("user_id"))
+}
+
+// GetTicket lấy phiếu hỗ trợ bất kỳ kèm các tin nhắn (Admin only)
+func (h *SupportTicketHandler) GetTicket(c *gin.Context) {
+	h.get(c, 0)
+}
+
+func (h *SupportTicketHandler) get(c *gin.Context, userID uint) {
+	id, ok := parseSupportTicketID(c)
+	if !ok {
+		return
+	}
+	ticket, err := h.service.Get(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err, "Error fetching support ticket")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Support ticket retrieved successfully", ticket))
+}
+
+// ReplyMyTicket thêm tin nhắn của user vào phiếu của mình; phiếu đã trả lời hoặc đã xong được mở lại
+func (h *SupportTicketHandler) ReplyMyTicket(c *gin.Context) {
+	h.reply(c, false)
+}
+
+// ReplyTicket thêm câu trả lời của shop vào phiếu và gửi email cho khách (Admin only)
+func (h *SupportTicketHandler) ReplyTicket(c *gin.Context) {
+	h.reply(c, true)
+}
+
+func (h *SupportTicketHandler) reply(c *gin.Context, staff bool) {
+	id, ok := parseSupportTicketID(c)
+	if !ok {
+		return
+	}
+	var req models.ReplySupportTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	ticket, err := h.service.Reply(c.Request.Context(), c.GetUint("user_id"), staff, id, req.Message)
+	if err != nil {
+		h.handleError(c, err, "Error replying to support ticket")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Reply added to support ticket", ticket))
+}
+
+// CloseMyTicket đóng phiếu hỗ trợ của user hiện tại
+func (h *SupportTicketHandler) CloseMyTicket(c *gin.Context) {
+	id, ok := parseSupportTicketID(c)
+	if !ok {
+		return
+	}
+	ticket, err := h.service.SetStatus(c.Request.Context(), c.GetUint("user_id"), false, id, models.SupportTicketClosed)
+	if err != nil {
+		h.handleError(c, err, "Error updating support ticket")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Support ticket updated successfully", ticket))
+}
+
+// UpdateTicketStatus đánh dấu phiếu resolved, đóng hoặc mở lại phiếu (Admin only)
+func (h *SupportTicketHandler) UpdateTicketStatus(c *gin.Context) {
+	id, ok := parseSupportTicketID(c)
+	if !ok {
+		return
+	}
+	var req models.UpdateSupportTicketStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	ticket, err := h.service.SetStatus(c.Request.Context(), c.GetUint("user_id"), true, id, req.Status)
+	if err != nil {
+		h.handleError(c, err, "Error updating support ticket")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Support ticket updated successfully", ticket))
+}
+
+func (h *SupportTicketHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrSupportTicketNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Support ticket not found", ""))
+	case errors.Is(err, services.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Order not found", ""))
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "User not found", ""))
+	case errors.Is(err, services.ErrSupportEmailRequired):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Email is required", ""))
+	case errors.Is(err, services.ErrSupportOrderNotAllowed):
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Sign in to attach an order to a support ticket", ""))
+	case errors.Is(err, services.ErrSupportTicketClosed):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Support ticket is closed", ""))
+	case errors.Is(err, services.ErrSupportTicketStatus):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Support ticket cannot move to this status", err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
+
+func parseSupportTicketID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid support ticket ID", err.Error()))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/tax"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TaxHandler struct {
+	service *services.TaxService
+}
+
+func NewTaxHandler(db *gorm.DB, config tax.Config) *TaxHandler {
+	return &TaxHandler{
+		service: services.NewTaxService(db, config),
+	}
+}
+
+// ListTaxRates lấy các quy tắc thuế suất cùng cấu hình thuế chung (Admin only)
+func (h *TaxHandler) ListTaxRates(c *gin.Context) {
+	rates, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching tax rates", err.Error()))
+		return
+	}
+	config := h.service.Config()
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Tax rates retrieved successfully", gin.H{
+		"default_rate":       config.DefaultRate,
+		"prices_include_tax": config.PricesIncludeTax,
+		"rates":              rates,
+	}))
+}
+
+// CreateTaxRate thêm quy tắc thuế suất theo danh mục và/hoặc tỉnh (Admin only)
+func (h *TaxHandler) CreateTaxRate(c *gin.Context) {
+	var req models.TaxRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	rate, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err, "Error creating tax rate")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Tax rate created successfully", rate))
+}
+
+// UpdateTaxRate sửa quy tắc thuế suất (Admin only)
+func (h *TaxHandler) UpdateTaxRate(c *gin.Context) {
+	id, ok := parseTaxRateID(c)
+	if !ok {
+		return
+	}
+	var req models.TaxRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	rate, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleError(c, err, "Error updating tax rate")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Tax rate updated successfully", rate))
+}
+
+// DeleteTaxRate xóa quy tắc thuế suất (Admin only)
+func (h *TaxHandler) DeleteTaxRate(c *gin.Context) {
+	id, ok := parseTaxRateID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		h.handleError(c, err, "Error deleting tax rate")
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Tax rate deleted successfully", nil))
+}
+
+func (h *TaxHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrTaxRateNotFound):
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Tax rate not found", ""))
+	case errors.Is(err, services.ErrTaxRateExists):
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Tax rate already exists", err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, message, err.Error()))
+	}
+}
+
+func parseTaxRateID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid tax rate ID", err.Error()))
+		return 0, false
+	}
+	return uint(id), true
+}
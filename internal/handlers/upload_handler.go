@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type UploadHandler struct {
+	gc *services.UploadGCService
+}
+
+func NewUploadHandler(db *gorm.DB, uploadDir string) *UploadHandler {
+	return &UploadHandler{
+		gc: services.NewUploadGCService(db, uploadDir),
+	}
+}
+
+// PreviewUploadGC chạy thử việc dọn upload (dry run): liệt kê file mồ côi, thời điểm chúng sẽ bị xóa và
+// số file, dung lượng sẽ được xóa nếu tác vụ stale_upload_cleanup chạy lúc này (Admin only)
+func (h *UploadHandler) PreviewUploadGC(c *gin.Context) {
+	report, err := h.gc.Run(c.Request.Context(), true, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error scanning uploads", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Upload cleanup preview retrieved successfully", report))
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/notify"
+	"github.com/NgTruong624/project_backend/internal/password"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/validation"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxUserImportSize là kích thước tối đa của file CSV nhập user
+const maxUserImportSize = 5 << 20
+
+type UserInvitationHandler struct {
+	service   *services.UserInvitationService
+	passwords *password.Policy
+	hasher    *password.Hasher
+	bus       *events.Bus
+}
+
+func NewUserInvitationHandler(db *gorm.DB, bus *events.Bus, notifier *notify.Dispatcher, passwords *password.Policy, hasher *password.Hasher) *UserInvitationHandler {
+	return &UserInvitationHandler{
+		service:   services.NewUserInvitationService(db, notifier),
+		passwords: passwords,
+		hasher:    hasher,
+		bus:       bus,
+	}
+}
+
+// ImportUsers nhập user từ file CSV (field "file") ở trạng thái invited và gửi email mời đặt mật khẩu
+// (Admin only). Trả về kết quả từng dòng; ?dry_run=true chỉ kiểm tra file mà không tạo user.
+func (h *UserInvitationHandler) ImportUsers(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "No CSV file provided", err.Error()))
+		return
+	}
+	if file.Size > maxUserImportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, utils.NewErrorResponse(c, http.StatusRequestEntityTooLarge, "Import file is too large",
+			"The file must be at most 5 MB"))
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error reading import file", err.Error()))
+		return
+	}
+	defer f.Close()
+
+	adminID := c.GetUint("user_id")
+	dryRun := c.Query("dry_run") == "true"
+	result, err := h.service.Import(c.Request.Context(), adminID, f, dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidImportFile), errors.Is(err, services.ErrImportTooLarge):
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid import file", err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error importing users", err.Error()))
+		}
+		return
+	}
+
+	for _, row := range result.Rows {
+		if row.UserID != nil {
+			h.bus.Publish(c.Request.Context(), events.UserInvited{UserID: *row.UserID, AdminID: adminID, IP: c.ClientIP(), At: time.Now()})
+		}
+	}
+	message := "Users imported"
+	if dryRun {
+		message = "Import file checked, no users were created"
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, message, result))
+}
+
+// ResendInvitation gửi lại email mời tới tài khoản chưa đặt mật khẩu; link cũ hết hiệu lực (Admin only)
+func (h *UserInvitationHandler) ResendInvitation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err.Error()))
+		return
+	}
+	adminID := c.GetUint("user_id")
+	invitation, err := h.service.Resend(c.Request.Context(), adminID, uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "User not found", ""))
+		case errors.Is(err, services.ErrUserNotInvited):
+			c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "User has already set a password", ""))
+		default:
+			c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error sending invitation", err.Error()))
+		}
+		return
+	}
+	h.bus.Publish(c.Request.Context(), events.UserInvited{UserID: invitation.UserID, AdminID: adminID, IP: c.ClientIP(), At: time.Now()})
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Invitation sent", invitation))
+}
+
+// AcceptInvitation đặt mật khẩu cho tài khoản được mời bằng token trong link; không cần đăng nhập vì token
+// chính là bằng chứng sở hữu địa chỉ email. Sau đó user đăng nhập như bình thường.
+func (h *UserInvitationHandler) AcceptInvitation(c *gin.Context) {
+	var req models.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.Respond(c, err)
+		return
+	}
+	user, err := h.service.Lookup(c.Request.Context(), req.Token)
+	if err != nil {
+		h.handleAcceptError(c, err)
+		return
+	}
+	if violations := h.passwords.Check(c.Request.Context(), req.Password, user.Username, user.Email, user.FullName); len(violations) > 0 {
+		respondPasswordPolicy(c, "password", violations)
+		return
+	}
+	hashedPassword, err := h.hasher.Hash(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error hashing password", err.Error()))
+		return
+	}
+
+	user, err = h.service.Accept(c.Request.Context(), req.Token, hashedPassword)
+	if err != nil {
+		h.handleAcceptError(c, err)
+		return
+	}
+	h.bus.Publish(c.Request.Context(), events.InvitationAccepted{UserID: user.ID, IP: c.ClientIP(), At: time.Now()})
+	h.bus.Publish(c.Request.Context(), events.UserRegistered{User: *user})
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Password set, you can now log in", models.UserResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		FullName:  user.FullName,
+		Role:      user.Role,
+		Status:    user.Status,
+		CreatedAt: user.CreatedAt,
+	}))
+}
+
+func (h *UserInvitationHandler) handleAcceptError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvitationNotFound) {
+		c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Invitation not found or expired", ""))
+		return
+	}
+	c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error accepting invitation", err.Error()))
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/NgTruong624/project_backend/internal/webhook"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler struct {
+	repo *repository.WebhookRepository
+}
+
+func NewWebhookHandler(db *gorm.DB) *WebhookHandler {
+	return &WebhookHandler{
+		repo: repository.NewWebhookRepository(db),
+	}
+}
+
+func toWebhookResponse(sub models.WebhookSubscription) models.WebhookSubscriptionResponse {
+	return models.WebhookSubscriptionResponse{
+		ID:          sub.ID,
+		URL:         sub.URL,
+		Events:      strings.Split(sub.Events, ","),
+		Description: sub.Description,
+		Active:      sub.Active,
+		CreatedAt:   sub.CreatedAt,
+	}
+}
+
+// ListWebhooks lấy danh sách webhook đã đăng ký (Admin only)
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	subs, err := h.repo.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching webhooks", err.Error()))
+		return
+	}
+
+	responses := make([]models.WebhookSubscriptionResponse, 0, len(subs))
+	for _, sub := range subs {
+		responses = append(responses, toWebhookResponse(sub))
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Webhooks retrieved successfully", responses))
+}
+
+// CreateWebhook đăng ký endpoint mới nhận sự kiện (Admin only)
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid request", err.Error()))
+		return
+	}
+
+	for _, event := range req.Events {
+		if !webhook.SupportedEvents[event] {
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Unsupported event", event))
+			return
+		}
+	}
+	if err := webhook.ValidateURL(c.Request.Context(), req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid webhook URL", err.Error()))
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := webhook.GenerateSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error generating webhook secret", err.Error()))
+			return
+		}
+		secret = generated
+	}
+
+	sub := &models.WebhookSubscription{
+		URL:         req.URL,
+		Events:      strings.Join(req.Events, ","),
+		Secret:      secret,
+		Description: req.Description,
+		Active:      true,
+	}
+	if err := h.repo.CreateSubscription(c.Request.Context(), sub); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error creating webhook", err.Error()))
+		return
+	}
+
+	// Secret chỉ được trả về một lần khi tạo
+	c.JSON(http.StatusCreated, utils.NewResponse(c, http.StatusCreated, "Webhook created successfully", gin.H{
+		"webhook": toWebhookResponse(*sub),
+		"secret":  secret,
+	}))
+}
+
+// DeleteWebhook xóa webhook (Admin only)
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid webhook ID", err.Error()))
+		return
+	}
+	if _, err := h.repo.GetSubscription(c.Request.Context(), uint(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Webhook not found", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching webhook", err.Error()))
+		return
+	}
+	if err := h.repo.DeleteSubscription(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error deleting webhook", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Webhook deleted successfully", nil))
+}
+
+// GetDeliveries lấy nhật ký gửi của một webhook (Admin only)
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid webhook ID", err.Error()))
+		return
+	}
+
+	var query models.WebhookDeliveryQueryParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+
+	deliveries, total, err := h.repo.ListDeliveries(c.Request.Context(), uint(id), &query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching webhook deliveries", err.Error()))
+		return
+	}
+
+	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	filters := map[string]interface{}{}
+	if query.Status != "" {
+		filters["status"] = query.Status
+	}
+	if query.Event != "" {
+		filters["event"] = query.Event
+	}
+	c.JSON(http.StatusOK, utils.NewPaginatedResponse(
+		c, http.StatusOK, "Webhook deliveries retrieved successfully", deliveries,
+		query.Page, totalPages, total, query.Limit, filters,
+	))
+}
+
+// RetryDelivery đưa một lần gửi thất bại trở lại hàng đợi (Admin only)
+func (h *WebhookHandler) RetryDelivery(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, "Invalid delivery ID", err.Error()))
+		return
+	}
+	delivery, err := h.repo.GetDelivery(c.Request.Context(), uint(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, utils.NewErrorResponse(c, http.StatusNotFound, "Delivery not found", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error fetching delivery", err.Error()))
+		return
+	}
+	if delivery.Status == models.WebhookDeliverySucceeded {
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(c, http.StatusConflict, "Delivery already succeeded", ""))
+		return
+	}
+	if err := h.repo.RetryDelivery(c.Request.Context(), delivery.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(c, http.StatusInternalServerError, "Error retrying delivery", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.NewResponse(c, http.StatusOK, "Delivery queued for retry", nil))
+}
@@ -0,0 +1,118 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Các ngôn ngữ được hỗ trợ
+const (
+	EN = "en"
+	VI = "vi"
+)
+
+// Default là ngôn ngữ dùng khi client không gửi Accept-Language hoặc không có ngôn ngữ phù hợp.
+// Message gốc trong code được viết bằng tiếng Anh nên catalog en để trống.
+const Default = EN
+
+// contextKey là khóa lưu ngôn ngữ của request trong gin.Context
+const contextKey = "lang"
+
+// catalogs ánh xạ message gốc (tiếng Anh) sang bản dịch theo từng ngôn ngữ
+var catalogs = map[string]map[string]string{
+	EN: {},
+	VI: vi,
+}
+
+// Supported cho biết ngôn ngữ có catalog hay không
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// T dịch message sang ngôn ngữ lang; nếu có args thì message được dùng làm format.
+// Message chưa có bản dịch được giữ nguyên.
+func T(lang, msg string, args ...interface{}) string {
+	if translated, ok := catalogs[lang][msg]; ok {
+		msg = translated
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Negotiate chọn ngôn ngữ hỗ trợ phù hợp nhất từ header Accept-Language (RFC 9110),
+// ví dụ "vi-VN,vi;q=0.9,en;q=0.8"
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		candidates = append(candidates, candidate{lang: base, q: q})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	for _, cand := range candidates {
+		if Supported(cand.lang) {
+			return cand.lang
+		}
+	}
+	return Default
+}
+
+// Middleware xác định ngôn ngữ của request (query ?lang= ưu tiên hơn Accept-Language)
+// và lưu vào context cho các handler phía sau
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := strings.ToLower(c.Query("lang"))
+		if !Supported(lang) {
+			lang = Negotiate(c.GetHeader("Accept-Language"))
+		}
+		c.Set(contextKey, lang)
+		c.Header("Content-Language", lang)
+		c.Header("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// FromContext trả về ngôn ngữ của request; nếu middleware chưa chạy thì đọc trực tiếp Accept-Language
+func FromContext(c *gin.Context) string {
+	if c == nil {
+		return Default
+	}
+	if lang := c.GetString(contextKey); lang != "" {
+		return lang
+	}
+	if c.Request == nil {
+		return Default
+	}
+	return Negotiate(c.GetHeader("Accept-Language"))
+}
+
+// Localize dịch message theo ngôn ngữ của request
+func Localize(c *gin.Context, msg string, args ...interface{}) string {
+	return T(FromContext(c), msg, args...)
+}
@@ -0,0 +1,692 @@
+package i18n
+
+// vi là catalog tiếng Việt, khóa là message gốc tiếng Anh
+var vi = map[string]string{
+	// Chung
+	"Invalid request":                              "Yêu cầu không hợp lệ",
+	"Invalid request data":                         "Dữ liệu yêu cầu không hợp lệ",
+	"Invalid query parameters":                     "Tham số truy vấn không hợp lệ",
+	"Validation failed":                            "Dữ liệu không hợp lệ",
+	"Permission denied":                            "Không có quyền truy cập",
+	"Admin access required":                        "Yêu cầu quyền quản trị viên",
+	"Too Many Requests":                            "Quá nhiều yêu cầu",
+	"Rate limit exceeded. Please try again later.": "Bạn đã gửi quá nhiều yêu cầu. Vui lòng thử lại sau.",
+	"Invalid date range":                           "Khoảng thời gian không hợp lệ",
+	"Invalid price range":                          "Khoảng giá không hợp lệ",
+
+	// Xác thực
+	"Authorization header is required":    "Thiếu header Authorization",
+	"Invalid authorization header format": "Header Authorization không đúng định dạng",
+	"Invalid token":                       "Token không hợp lệ",
+	"Invalid token claims":                "Thông tin trong token không hợp lệ",
+	"Session has been revoked":            "Phiên đăng nhập đã bị thu hồi, vui lòng đăng nhập lại",
+	"Error validating session":            "Lỗi khi kiểm tra phiên đăng nhập",
+	"Logged out successfully":             "Đăng xuất thành công",
+	"Logged out from all devices":         "Đã đăng xuất khỏi mọi thiết bị",
+	"Error logging out":                   "Lỗi khi đăng xuất",
+	"Invalid CSRF token":                  "Token CSRF không hợp lệ",
+	"API key is required":                 "Thiếu API key",
+	"Invalid API key":                     "API key không hợp lệ",
+	"Access token is required":            "Thiếu access token",
+	"Invalid access token":                "Access token không hợp lệ",
+	"User not authenticated":              "Người dùng chưa đăng nhập",
+	"Login successful":                    "Đăng nhập thành công",
+	"Invalid username or password":        "Tên đăng nhập hoặc mật khẩu không đúng",
+	"Error generating token":              "Lỗi khi tạo token",
+	"User registered successfully":        "Đăng ký thành công",
+	"Email already exists":                "Email đã tồn tại",
+	"Error creating user":                 "Lỗi khi tạo người dùng",
+	"Error hashing password":              "Lỗi khi mã hóa mật khẩu",
+	"Password changed successfully":       "Đổi mật khẩu thành công",
+	"Current password is incorrect":       "Mật khẩu hiện tại không đúng",
+	"Failed to hash new password":         "Lỗi khi mã hóa mật khẩu mới",
+	"Failed to update password":           "Lỗi khi cập nhật mật khẩu",
+
+	// Người dùng
+	"User not found":                        "Không tìm thấy người dùng",
+	"Users retrieved successfully":          "Lấy danh sách người dùng thành công",
+	"Error fetching users":                  "Lỗi khi lấy danh sách người dùng",
+	"User retrieved successfully":           "Lấy thông tin người dùng thành công",
+	"Error fetching user":                   "Lỗi khi lấy thông tin người dùng",
+	"Invalid user ID":                       "ID người dùng không hợp lệ",
+	"Password is incorrect":                 "Mật khẩu không đúng",
+	"Account deleted successfully":          "Đã xóa tài khoản; tài khoản có thể được khôi phục trước thời điểm purge_at",
+	"Cannot delete the last admin account":  "Không thể xóa tài khoản admin cuối cùng",
+	"Error deleting account":                "Lỗi khi xóa tài khoản",
+	"User data exported successfully":       "Xuất dữ liệu cá nhân thành công",
+	"Error exporting user data":             "Lỗi khi xuất dữ liệu cá nhân",
+	"Error requesting email change":         "Lỗi khi yêu cầu đổi email",
+	"Error confirming email change":         "Lỗi khi xác nhận đổi email",
+	"Deleted user not found":                "Không tìm thấy tài khoản đã xóa",
+	"User data has already been anonymized": "Dữ liệu của tài khoản đã bị ẩn danh hóa, không thể khôi phục",
+	"User restored successfully":            "Khôi phục tài khoản thành công",
+	"Error restoring user":                  "Lỗi khi khôi phục tài khoản",
+	"User activity retrieved successfully":  "Lấy lịch sử hoạt động thành công",
+	"Error fetching user activity":          "Lỗi khi lấy lịch sử hoạt động",
+	"Audit logs retrieved successfully":     "Lấy audit log thành công",
+	"Error fetching audit logs":             "Lỗi khi lấy audit log",
+
+	// Đổi email
+	"New email must be different from the current email":               "Email mới phải khác email hiện tại",
+	"Confirmation links sent to both email addresses":                  "Đã gửi link xác nhận tới cả email hiện tại và email mới",
+	"Email change request not found or expired":                        "Không tìm thấy yêu cầu đổi email hoặc link đã hết hạn",
+	"Email changed successfully, please log in again":                  "Đổi email thành công, vui lòng đăng nhập lại",
+	"Email change confirmed, waiting for the other address to confirm": "Đã xác nhận, đang chờ địa chỉ email còn lại xác nhận",
+
+	// Nhập user và lời mời
+	"No CSV file provided":                       "Chưa gửi file CSV",
+	"Import file is too large":                   "File nhập quá lớn",
+	"Error reading import file":                  "Lỗi khi đọc file nhập",
+	"Invalid import file":                        "File nhập không hợp lệ",
+	"Error importing users":                      "Lỗi khi nhập người dùng",
+	"Users imported":                             "Đã nhập người dùng",
+	"Import file checked, no users were created": "Đã kiểm tra file nhập, chưa tạo người dùng nào",
+	"User has already set a password":            "Người dùng đã đặt mật khẩu",
+	"Error sending invitation":                   "Lỗi khi gửi lời mời",
+	"Invitation sent":                            "Đã gửi lời mời",
+	"Invitation not found or expired":            "Không tìm thấy lời mời hoặc link đã hết hạn",
+	"Error accepting invitation":                 "Lỗi khi nhận lời mời",
+	"Password set, you can now log in":           "Đã đặt mật khẩu, bạn có thể đăng nhập",
+
+	// Bản tin
+	"Error subscribing to newsletter":                      "Lỗi khi đăng ký nhận bản tin",
+	"Please check your inbox to confirm your subscription": "Vui lòng kiểm tra hộp thư để xác nhận đăng ký",
+	"Newsletter subscription confirmed":                    "Đã xác nhận đăng ký nhận bản tin",
+	"Error confirming newsletter subscription":             "Lỗi khi xác nhận đăng ký nhận bản tin",
+	"You have been unsubscribed from the newsletter":       "Đã hủy đăng ký nhận bản tin",
+	"Error unsubscribing from newsletter":                  "Lỗi khi hủy đăng ký nhận bản tin",
+	"Newsletter link is invalid or expired":                "Link bản tin không hợp lệ hoặc đã hết hạn",
+
+	// Đăng nhập đáng ngờ và lịch sử đăng nhập
+	"Error checking login":                                  "Lỗi khi kiểm tra đăng nhập",
+	"Login confirmation link is invalid or expired":         "Link xác nhận đăng nhập không hợp lệ hoặc đã hết hạn",
+	"Error confirming login":                                "Lỗi khi xác nhận đăng nhập",
+	"Error fetching login history":                          "Lỗi khi lấy lịch sử đăng nhập",
+	"Login history retrieved successfully":                  "Lấy lịch sử đăng nhập thành công",
+	"Confirm this sign-in with the link sent to your email": "Vui lòng xác nhận lần đăng nhập này bằng link đã gửi tới email của bạn",
+
+	// Phiếu hỗ trợ
+	"Captcha verification required":                  "Vui lòng hoàn thành xác minh captcha",
+	"Captcha verification failed":                    "Xác minh captcha không thành công",
+	"Captcha verification is unavailable":            "Không thể xác minh captcha, vui lòng thử lại sau",
+	"Error creating support ticket":                  "Lỗi khi gửi phiếu hỗ trợ",
+	"Support ticket created successfully":            "Đã gửi phiếu hỗ trợ",
+	"Error fetching support tickets":                 "Lỗi khi lấy danh sách phiếu hỗ trợ",
+	"Support tickets retrieved successfully":         "Lấy danh sách phiếu hỗ trợ thành công",
+	"Error fetching support ticket":                  "Lỗi khi lấy phiếu hỗ trợ",
+	"Support ticket retrieved successfully":          "Lấy phiếu hỗ trợ thành công",
+	"Error replying to support ticket":               "Lỗi khi trả lời phiếu hỗ trợ",
+	"Reply added to support ticket":                  "Đã thêm trả lời vào phiếu hỗ trợ",
+	"Error updating support ticket":                  "Lỗi khi cập nhật phiếu hỗ trợ",
+	"Support ticket updated successfully":            "Cập nhật phiếu hỗ trợ thành công",
+	"Support ticket not found":                       "Không tìm thấy phiếu hỗ trợ",
+	"Invalid support ticket ID":                      "ID phiếu hỗ trợ không hợp lệ",
+	"Email is required":                              "Vui lòng nhập email",
+	"Sign in to attach an order to a support ticket": "Vui lòng đăng nhập để gắn đơn hàng vào phiếu hỗ trợ",
+	"Support ticket is closed":                       "Phiếu hỗ trợ đã đóng",
+	"Support ticket cannot move to this status":      "Không thể chuyển phiếu hỗ trợ sang trạng thái này",
+
+	// Sản phẩm
+	"Product not found":                             "Không tìm thấy sản phẩm",
+	"Invalid product ID":                            "ID sản phẩm không hợp lệ",
+	"Invalid product IDs":                           "Danh sách ID sản phẩm không hợp lệ",
+	"Product retrieved successfully":                "Lấy thông tin sản phẩm thành công",
+	"Products retrieved successfully":               "Lấy danh sách sản phẩm thành công",
+	"Product created successfully":                  "Tạo sản phẩm thành công",
+	"Product updated successfully":                  "Cập nhật sản phẩm thành công",
+	"Product deleted successfully":                  "Xóa sản phẩm thành công",
+	"Product name already exists":                   "Tên sản phẩm đã tồn tại",
+	"Another product with this name already exists": "Đã có sản phẩm khác trùng tên",
+	"Error fetching product":                        "Lỗi khi lấy thông tin sản phẩm",
+	"Error fetching products":                       "Lỗi khi lấy danh sách sản phẩm",
+	"Error creating product":                        "Lỗi khi tạo sản phẩm",
+	"Error updating product":                        "Lỗi khi cập nhật sản phẩm",
+	"Error deleting product":                        "Lỗi khi xóa sản phẩm",
+	"Image uploaded successfully":                   "Tải ảnh lên thành công",
+	"No image file provided":                        "Chưa chọn file ảnh",
+	"Invalid file type":                             "Định dạng file không hợp lệ",
+	"Error saving file":                             "Lỗi khi lưu file",
+	"Error updating product image URL":              "Lỗi khi cập nhật ảnh sản phẩm",
+	"Invalid If-Match header":                       "Header If-Match không hợp lệ",
+	"Product version is required":                   "Thiếu version của sản phẩm",
+	"Send the ETag from GET /products/:id in the If-Match header or the version field": "Gửi ETag lấy từ GET /products/:id trong header If-Match hoặc trong field version",
+	"Product was modified by another request":                                          "Sản phẩm đã được người khác cập nhật, vui lòng tải lại rồi thử lại",
+
+	// Mở rộng quan hệ của sản phẩm (?include=)
+	"Invalid include": "Tham số include không hợp lệ",
+
+	// Webhook
+	"Webhook not found":                         "Không tìm thấy webhook",
+	"Invalid webhook ID":                        "ID webhook không hợp lệ",
+	"Invalid webhook URL":                       "URL webhook không hợp lệ",
+	"Unsupported event":                         "Sự kiện không được hỗ trợ",
+	"Webhook created successfully":              "Tạo webhook thành công",
+	"Webhook deleted successfully":              "Xóa webhook thành công",
+	"Webhooks retrieved successfully":           "Lấy danh sách webhook thành công",
+	"Webhook deliveries retrieved successfully": "Lấy lịch sử gửi webhook thành công",
+	"Error creating webhook":                    "Lỗi khi tạo webhook",
+	"Error deleting webhook":                    "Lỗi khi xóa webhook",
+	"Error fetching webhook":                    "Lỗi khi lấy thông tin webhook",
+	"Error fetching webhooks":                   "Lỗi khi lấy danh sách webhook",
+	"Error fetching webhook deliveries":         "Lỗi khi lấy lịch sử gửi webhook",
+	"Error generating webhook secret":           "Lỗi khi tạo secret cho webhook",
+	"Delivery not found":                        "Không tìm thấy lượt gửi",
+	"Invalid delivery ID":                       "ID lượt gửi không hợp lệ",
+	"Delivery already succeeded":                "Lượt gửi đã thành công",
+	"Delivery queued for retry":                 "Đã đưa lượt gửi vào hàng đợi để thử lại",
+	"Error fetching delivery":                   "Lỗi khi lấy thông tin lượt gửi",
+	"Error retrying delivery":                   "Lỗi khi gửi lại",
+
+	// Job và lịch chạy
+	"Job not found":                          "Không tìm thấy job",
+	"Invalid job ID":                         "ID job không hợp lệ",
+	"Jobs retrieved successfully":            "Lấy danh sách job thành công",
+	"Job stats retrieved successfully":       "Lấy thống kê job thành công",
+	"Job queued for retry":                   "Đã đưa job vào hàng đợi để chạy lại",
+	"Only failed jobs can be retried":        "Chỉ có thể chạy lại job bị lỗi",
+	"Error fetching job":                     "Lỗi khi lấy thông tin job",
+	"Error fetching jobs":                    "Lỗi khi lấy danh sách job",
+	"Error fetching job stats":               "Lỗi khi lấy thống kê job",
+	"Error retrying job":                     "Lỗi khi chạy lại job",
+	"Task not found":                         "Không tìm thấy tác vụ",
+	"Task scheduled to run":                  "Đã lên lịch chạy tác vụ",
+	"Scheduled tasks retrieved successfully": "Lấy danh sách tác vụ định kỳ thành công",
+	"Error fetching scheduled tasks":         "Lỗi khi lấy danh sách tác vụ định kỳ",
+	"Error triggering task":                  "Lỗi khi chạy tác vụ",
+
+	// Thiết bị và thông báo
+	"Device not found":                                "Không tìm thấy thiết bị",
+	"Invalid device ID":                               "ID thiết bị không hợp lệ",
+	"Device registered successfully":                  "Đăng ký thiết bị thành công",
+	"Device deleted successfully":                     "Xóa thiết bị thành công",
+	"Devices retrieved successfully":                  "Lấy danh sách thiết bị thành công",
+	"Error registering device":                        "Lỗi khi đăng ký thiết bị",
+	"Error deleting device":                           "Lỗi khi xóa thiết bị",
+	"Error fetching devices":                          "Lỗi khi lấy danh sách thiết bị",
+	"Test notification queued":                        "Đã đưa thông báo thử vào hàng đợi",
+	"Notification preferences retrieved successfully": "Lấy lựa chọn nhận thông báo thành công",
+	"Notification preferences updated successfully":   "Cập nhật lựa chọn nhận thông báo thành công",
+	"Error fetching notification preferences":         "Lỗi khi lấy lựa chọn nhận thông báo",
+	"Error updating notification preferences":         "Lỗi khi cập nhật lựa chọn nhận thông báo",
+	"Unknown notification event":                      "Loại thông báo không hợp lệ",
+
+	// Theo dõi sản phẩm
+	"Product alert created successfully":           "Đã theo dõi sản phẩm",
+	"Product alerts retrieved successfully":        "Lấy danh sách sản phẩm đang theo dõi thành công",
+	"Product alert deleted successfully":           "Đã bỏ theo dõi sản phẩm",
+	"Product alert not found":                      "Không tìm thấy theo dõi sản phẩm",
+	"Invalid product alert ID":                     "ID theo dõi sản phẩm không hợp lệ",
+	"Product is in stock":                          "Sản phẩm đang còn hàng",
+	"Target price must be below the current price": "Giá mong muốn phải thấp hơn giá hiện tại",
+	"Too many product alerts":                      "Bạn đang theo dõi quá nhiều sản phẩm",
+	"Error creating product alert":                 "Lỗi khi theo dõi sản phẩm",
+	"Error fetching product alerts":                "Lỗi khi lấy danh sách sản phẩm đang theo dõi",
+	"Error deleting product alert":                 "Lỗi khi bỏ theo dõi sản phẩm",
+
+	// Giỏ hàng
+	"Cart not found":              "Không tìm thấy giỏ hàng",
+	"Cart retrieved successfully": "Lấy giỏ hàng thành công",
+	"Cart updated successfully":   "Cập nhật giỏ hàng thành công",
+	"Cart cleared successfully":   "Đã xóa toàn bộ giỏ hàng",
+	"Item added to cart":          "Đã thêm sản phẩm vào giỏ hàng",
+	"Item removed from cart":      "Đã xóa sản phẩm khỏi giỏ hàng",
+	"Product is not in the cart":  "Sản phẩm không có trong giỏ hàng",
+	"Insufficient stock":          "Không đủ hàng trong kho",
+	"Error fetching cart":         "Lỗi khi lấy giỏ hàng",
+	"Error updating cart":         "Lỗi khi cập nhật giỏ hàng",
+	"Cart revalidated":            "Đã cập nhật giỏ hàng theo giá và tồn kho hiện tại",
+	"Error revalidating cart":     "Lỗi khi kiểm tra lại giỏ hàng",
+
+	// Thanh toán
+	"Payment methods retrieved successfully":            "Lấy danh sách phương thức thanh toán thành công",
+	"Payment method saved successfully":                 "Lưu phương thức thanh toán thành công",
+	"Payment method deleted successfully":               "Xóa phương thức thanh toán thành công",
+	"Default payment method updated":                    "Đã đổi phương thức thanh toán mặc định",
+	"Payment method not found":                          "Không tìm thấy phương thức thanh toán",
+	"Payment method is already saved":                   "Phương thức thanh toán này đã được lưu",
+	"Invalid payment method ID":                         "ID phương thức thanh toán không hợp lệ",
+	"Invalid payment method token":                      "Token phương thức thanh toán không hợp lệ",
+	"Payments are not configured":                       "Chưa cấu hình cổng thanh toán",
+	"Error fetching payment methods":                    "Lỗi khi lấy danh sách phương thức thanh toán",
+	"Error saving payment method":                       "Lỗi khi lưu phương thức thanh toán",
+	"Error updating payment method":                     "Lỗi khi cập nhật phương thức thanh toán",
+	"Error deleting payment method":                     "Lỗi khi xóa phương thức thanh toán",
+	"Payment created successfully":                      "Tạo giao dịch thanh toán thành công",
+	"Error creating payment":                            "Lỗi khi tạo giao dịch thanh toán",
+	"Payment was declined":                              "Giao dịch thanh toán bị từ chối",
+	"Saved payment method belongs to another provider":  "Phương thức thanh toán đã lưu thuộc cổng thanh toán khác",
+	"Invalid webhook payload":                           "Dữ liệu webhook không hợp lệ",
+	"Webhook payload is too large":                      "Dữ liệu webhook quá lớn",
+	"The payload must be at most 64 KB":                 "Dữ liệu tối đa 64 KB",
+	"Invalid webhook signature":                         "Chữ ký webhook không hợp lệ",
+	"Webhook processed":                                 "Đã xử lý webhook",
+	"Error processing webhook":                          "Lỗi khi xử lý webhook",
+	"Payment result retrieved successfully":             "Lấy kết quả thanh toán thành công",
+	"Error processing payment result":                   "Lỗi khi xử lý kết quả thanh toán",
+	"Payment not found":                                 "Không tìm thấy giao dịch thanh toán",
+	"Paid amount does not match the order":              "Số tiền đã thanh toán không khớp với đơn hàng",
+	"Currency is not supported by the payment provider": "Cổng thanh toán không hỗ trợ loại tiền của đơn hàng",
+	"Payments retrieved successfully":                   "Lấy danh sách giao dịch thanh toán thành công",
+	"Error fetching payments":                           "Lỗi khi lấy danh sách giao dịch thanh toán",
+	"Refund created successfully":                       "Hoàn tiền thành công",
+	"Error refunding order":                             "Lỗi khi hoàn tiền đơn hàng",
+	"Order has no captured payment":                     "Đơn hàng chưa có giao dịch thanh toán thành công",
+	"Refund amount exceeds the refundable amount":       "Số tiền hoàn vượt quá số tiền còn có thể hoàn",
+
+	// Đơn hàng
+	"Order placed successfully":                   "Đặt hàng thành công",
+	"Error placing order":                         "Lỗi khi đặt hàng",
+	"Order retrieved successfully":                "Lấy đơn hàng thành công",
+	"Error fetching order":                        "Lỗi khi lấy đơn hàng",
+	"Order cancelled successfully":                "Hủy đơn hàng thành công",
+	"Error cancelling order":                      "Lỗi khi hủy đơn hàng",
+	"Order not found":                             "Không tìm thấy đơn hàng",
+	"Invalid order ID":                            "ID đơn hàng không hợp lệ",
+	"Cart is empty":                               "Giỏ hàng đang trống",
+	"Order is not awaiting payment":               "Đơn hàng không ở trạng thái chờ thanh toán",
+	"Order status does not allow this transition": "Trạng thái đơn hàng không cho phép chuyển sang trạng thái này",
+	"Order history retrieved successfully":        "Lấy lịch sử trạng thái đơn hàng thành công",
+	"Error fetching order history":                "Lỗi khi lấy lịch sử trạng thái đơn hàng",
+	"Order status updated successfully":           "Cập nhật trạng thái đơn hàng thành công",
+	"Error updating order status":                 "Lỗi khi cập nhật trạng thái đơn hàng",
+	"Order is paid offline":                       "Đơn hàng được thanh toán khi nhận hàng hoặc chuyển khoản",
+	"Order marked as paid":                        "Đã xác nhận đơn hàng được thanh toán",
+	"Error marking order as paid":                 "Lỗi khi xác nhận thanh toán đơn hàng",
+	"Payment reference has already been recorded": "Mã đối soát thanh toán đã được ghi nhận",
+	"Orders retrieved successfully":               "Lấy danh sách đơn hàng thành công",
+	"Error fetching orders":                       "Lỗi khi lấy danh sách đơn hàng",
+	"Invalid total range":                         "Khoảng tổng tiền không hợp lệ",
+	"Order statuses updated":                      "Đã cập nhật trạng thái các đơn hàng",
+	"Order items added to cart":                   "Đã thêm các sản phẩm của đơn hàng vào giỏ",
+	"Error reordering":                            "Lỗi khi đặt lại đơn hàng",
+
+	// Đồng bộ từ hệ thống kho
+	"Inventory synchronized": "Đã đồng bộ tồn kho",
+	"Prices synchronized":    "Đã đồng bộ giá",
+
+	// Sổ địa chỉ
+	"Addresses retrieved successfully": "Lấy sổ địa chỉ thành công",
+	"Address retrieved successfully":   "Lấy địa chỉ thành công",
+	"Address saved successfully":       "Lưu địa chỉ thành công",
+	"Address updated successfully":     "Cập nhật địa chỉ thành công",
+	"Address deleted successfully":     "Xóa địa chỉ thành công",
+	"Default address updated":          "Đã đổi địa chỉ mặc định",
+	"Address not found":                "Không tìm thấy địa chỉ",
+	"Address book is full":             "Sổ địa chỉ đã đầy",
+	"Invalid address ID":               "ID địa chỉ không hợp lệ",
+	"Error fetching addresses":         "Lỗi khi lấy sổ địa chỉ",
+	"Error fetching address":           "Lỗi khi lấy địa chỉ",
+	"Error saving address":             "Lỗi khi lưu địa chỉ",
+	"Error updating address":           "Lỗi khi cập nhật địa chỉ",
+	"Error deleting address":           "Lỗi khi xóa địa chỉ",
+
+	// Vận chuyển
+	"Shipping options retrieved successfully":          "Lấy báo giá vận chuyển thành công",
+	"Error quoting shipping":                           "Lỗi khi báo giá vận chuyển",
+	"No shipping option is available for this address": "Không có cách giao hàng nào tới địa chỉ này",
+	"Shipping option is not available":                 "Cách giao hàng đã chọn không khả dụng",
+
+	// Trả hàng
+	"Return request created successfully":                       "Gửi yêu cầu trả hàng thành công",
+	"Return requests retrieved successfully":                    "Lấy danh sách yêu cầu trả hàng thành công",
+	"Return request retrieved successfully":                     "Lấy thông tin yêu cầu trả hàng thành công",
+	"Return request cancelled":                                  "Đã hủy yêu cầu trả hàng",
+	"Return request approved":                                   "Đã duyệt yêu cầu trả hàng",
+	"Return request rejected":                                   "Đã từ chối yêu cầu trả hàng",
+	"Photo uploaded successfully":                               "Tải ảnh lên thành công",
+	"Return request not found":                                  "Không tìm thấy yêu cầu trả hàng",
+	"Invalid return request ID":                                 "ID yêu cầu trả hàng không hợp lệ",
+	"Order cannot be returned":                                  "Đơn hàng không thể trả lại",
+	"Item does not belong to the order":                         "Sản phẩm không thuộc đơn hàng",
+	"Return quantity exceeds the quantity that can be returned": "Số lượng trả vượt quá số lượng có thể trả",
+	"Return request has already been resolved":                  "Yêu cầu trả hàng đã được xử lý",
+	"Photo limit reached":                                       "Đã đạt số ảnh tối đa",
+	"File is too large":                                         "File quá lớn",
+	"Error creating return request":                             "Lỗi khi gửi yêu cầu trả hàng",
+	"Error fetching return requests":                            "Lỗi khi lấy danh sách yêu cầu trả hàng",
+	"Error fetching return request":                             "Lỗi khi lấy thông tin yêu cầu trả hàng",
+	"Error cancelling return request":                           "Lỗi khi hủy yêu cầu trả hàng",
+	"Error approving return request":                            "Lỗi khi duyệt yêu cầu trả hàng",
+	"Error rejecting return request":                            "Lỗi khi từ chối yêu cầu trả hàng",
+
+	// Thuế
+	"Tax rates retrieved successfully": "Lấy danh sách thuế suất thành công",
+	"Tax rate created successfully":    "Thêm thuế suất thành công",
+	"Tax rate updated successfully":    "Cập nhật thuế suất thành công",
+	"Tax rate deleted successfully":    "Xóa thuế suất thành công",
+	"Tax rate not found":               "Không tìm thấy thuế suất",
+	"Tax rate already exists":          "Thuế suất cho danh mục và tỉnh này đã tồn tại",
+	"Invalid tax rate ID":              "ID thuế suất không hợp lệ",
+	"Error fetching tax rates":         "Lỗi khi lấy danh sách thuế suất",
+	"Error creating tax rate":          "Lỗi khi thêm thuế suất",
+	"Error updating tax rate":          "Lỗi khi cập nhật thuế suất",
+	"Error deleting tax rate":          "Lỗi khi xóa thuế suất",
+
+	// Khuyến mãi
+	"Promotions retrieved successfully": "Lấy danh sách khuyến mãi thành công",
+	"Promotion retrieved successfully":  "Lấy thông tin khuyến mãi thành công",
+	"Promotion created successfully":    "Tạo khuyến mãi thành công",
+	"Promotion updated successfully":    "Cập nhật khuyến mãi thành công",
+	"Promotion deleted successfully":    "Xóa khuyến mãi thành công",
+	"Promotion not found":               "Không tìm thấy khuyến mãi",
+	"Invalid promotion":                 "Khuyến mãi không hợp lệ",
+	"Invalid promotion ID":              "ID khuyến mãi không hợp lệ",
+	"Error fetching promotions":         "Lỗi khi lấy danh sách khuyến mãi",
+	"Error fetching promotion":          "Lỗi khi lấy thông tin khuyến mãi",
+	"Error creating promotion":          "Lỗi khi tạo khuyến mãi",
+	"Error updating promotion":          "Lỗi khi cập nhật khuyến mãi",
+	"Error deleting promotion":          "Lỗi khi xóa khuyến mãi",
+
+	// KPI
+	"KPIs retrieved successfully":         "Lấy số liệu KPI thành công",
+	"KPIs rebuilt successfully":           "Tính lại KPI thành công",
+	"Error fetching KPIs":                 "Lỗi khi lấy số liệu KPI",
+	"Error rebuilding KPIs":               "Lỗi khi tính lại KPI",
+	"Sales report retrieved successfully": "Lấy báo cáo doanh số thành công",
+	"Error generating sales report":       "Lỗi khi lập báo cáo doanh số",
+
+	// Hóa đơn
+	"Invoice not found":                            "Không tìm thấy hóa đơn",
+	"Invalid invoice ID":                           "ID hóa đơn không hợp lệ",
+	"Invoice created successfully":                 "Lập hóa đơn nháp thành công",
+	"Invoice retrieved successfully":               "Lấy thông tin hóa đơn thành công",
+	"Invoices retrieved successfully":              "Lấy danh sách hóa đơn thành công",
+	"Invoice issued successfully":                  "Phát hành hóa đơn thành công",
+	"Invoice cancelled successfully":               "Hủy hóa đơn thành công",
+	"Invoice deleted successfully":                 "Xóa hóa đơn nháp thành công",
+	"Invoice has not been issued":                  "Hóa đơn chưa được phát hành",
+	"Invoice status does not allow this operation": "Trạng thái hóa đơn không cho phép thao tác này",
+	"Unsupported export format":                    "Định dạng xuất không được hỗ trợ",
+	"Error creating invoice":                       "Lỗi khi lập hóa đơn",
+	"Error fetching invoice":                       "Lỗi khi lấy thông tin hóa đơn",
+	"Error fetching invoices":                      "Lỗi khi lấy danh sách hóa đơn",
+	"Error issuing invoice":                        "Lỗi khi phát hành hóa đơn",
+	"Error cancelling invoice":                     "Lỗi khi hủy hóa đơn",
+	"Error deleting invoice":                       "Lỗi khi xóa hóa đơn",
+	"Error exporting invoice":                      "Lỗi khi xuất hóa đơn",
+
+	// Lỗi kiểm tra dữ liệu (internal/validation)
+	"%s is required.":                         "%s không được để trống.",
+	"%s must be a valid phone number.":        "%s phải là số điện thoại hợp lệ.",
+	"%s must be a valid email address.":       "%s phải là địa chỉ email hợp lệ.",
+	"%s must be a valid URL.":                 "%s phải là URL hợp lệ.",
+	"%s must match %s.":                       "%s phải khớp với %s.",
+	"%s must be one of: %s.":                  "%s phải là một trong các giá trị: %s.",
+	"%s must be at least %s characters long.": "%s phải có ít nhất %s ký tự.",
+	"%s must be at most %s characters long.":  "%s chỉ được có tối đa %s ký tự.",
+	"%s must be exactly %s characters long.":  "%s phải có đúng %s ký tự.",
+	"%s must contain at least %s items.":      "%s phải có ít nhất %s phần tử.",
+	"%s must contain at most %s items.":       "%s chỉ được có tối đa %s phần tử.",
+	"%s must be at least %s.":                 "%s phải lớn hơn hoặc bằng %s.",
+	"%s must be at most %s.":                  "%s phải nhỏ hơn hoặc bằng %s.",
+	"%s must be greater than %s.":             "%s phải lớn hơn %s.",
+	"%s must be less than %s.":                "%s phải nhỏ hơn %s.",
+	"%s must not contain duplicates.":         "%s không được có phần tử trùng nhau.",
+	"%s is invalid.":                          "%s không hợp lệ.",
+	"%s must be %s.":                          "%s phải là %s.",
+	"an integer":                              "số nguyên",
+	"a number":                                "số",
+	"a boolean":                               "giá trị true/false",
+	"an array":                                "mảng",
+	"an object":                               "đối tượng",
+	"a string":                                "chuỗi",
+	"Request body is not valid JSON.":         "Nội dung request không phải JSON hợp lệ.",
+	"Request body is empty.":                  "Nội dung request đang trống.",
+
+	// Chính sách mật khẩu (internal/password)
+	"Password does not meet the requirements":                            "Mật khẩu chưa đáp ứng yêu cầu",
+	"Password must be at least %d characters long.":                      "Mật khẩu phải có ít nhất %d ký tự.",
+	"Password must be at most %d bytes long.":                            "Mật khẩu chỉ được dài tối đa %d byte.",
+	"Password must contain a lowercase letter.":                          "Mật khẩu phải có chữ thường.",
+	"Password must contain an uppercase letter.":                         "Mật khẩu phải có chữ hoa.",
+	"Password must contain a letter.":                                    "Mật khẩu phải có chữ cái.",
+	"Password must contain a digit.":                                     "Mật khẩu phải có chữ số.",
+	"Password must contain a symbol.":                                    "Mật khẩu phải có ký tự đặc biệt.",
+	"Password is too common.":                                            "Mật khẩu quá phổ biến.",
+	"Password must not contain your username, email or name.":            "Mật khẩu không được chứa tên đăng nhập, email hoặc họ tên.",
+	"Password has appeared in a data breach, please choose another one.": "Mật khẩu đã xuất hiện trong một vụ lộ dữ liệu, vui lòng chọn mật khẩu khác.",
+
+	// Hỏi đáp về sản phẩm
+	"Questions retrieved successfully":                             "Lấy danh sách câu hỏi thành công",
+	"Answers retrieved successfully":                               "Lấy danh sách câu trả lời thành công",
+	"Error fetching questions":                                     "Lỗi khi lấy danh sách câu hỏi",
+	"Error fetching answers":                                       "Lỗi khi lấy danh sách câu trả lời",
+	"Question submitted successfully":                              "Đã gửi câu hỏi, câu hỏi sẽ hiển thị sau khi được duyệt",
+	"Answer submitted successfully":                                "Đã gửi câu trả lời",
+	"Error creating question":                                      "Lỗi khi gửi câu hỏi",
+	"Error creating answer":                                        "Lỗi khi gửi câu trả lời",
+	"Question moderated successfully":                              "Kiểm duyệt câu hỏi thành công",
+	"Answer moderated successfully":                                "Kiểm duyệt câu trả lời thành công",
+	"Error moderating question":                                    "Lỗi khi kiểm duyệt câu hỏi",
+	"Error moderating answer":                                      "Lỗi khi kiểm duyệt câu trả lời",
+	"Question not found":                                           "Không tìm thấy câu hỏi",
+	"Answer not found":                                             "Không tìm thấy câu trả lời",
+	"Invalid question ID":                                          "ID câu hỏi không hợp lệ",
+	"Invalid answer ID":                                            "ID câu trả lời không hợp lệ",
+	"Only admins and customers who bought this product can answer": "Chỉ quản trị viên và khách đã mua sản phẩm này mới được trả lời",
+	"Question is not open for answers":                             "Câu hỏi chưa được duyệt hoặc đã bị từ chối nên không thể trả lời",
+
+	// Đánh giá sản phẩm
+	"Reviews retrieved successfully":         "Lấy danh sách đánh giá thành công",
+	"Error fetching reviews":                 "Lỗi khi lấy danh sách đánh giá",
+	"Review submitted successfully":          "Đã gửi đánh giá, đánh giá sẽ hiển thị sau khi được duyệt",
+	"Error creating review":                  "Lỗi khi gửi đánh giá",
+	"Review moderated successfully":          "Kiểm duyệt đánh giá thành công",
+	"Error moderating review":                "Lỗi khi kiểm duyệt đánh giá",
+	"Review not found":                       "Không tìm thấy đánh giá",
+	"Invalid review ID":                      "ID đánh giá không hợp lệ",
+	"You have already reviewed this product": "Bạn đã đánh giá sản phẩm này",
+
+	// Bình chọn và báo cáo đánh giá
+	"Vote recorded successfully":                   "Đã ghi nhận bình chọn",
+	"Vote removed successfully":                    "Đã gỡ bình chọn",
+	"Review reported successfully":                 "Đã gửi báo cáo vi phạm",
+	"Error voting on review":                       "Lỗi khi bình chọn đánh giá",
+	"Error removing vote":                          "Lỗi khi gỡ bình chọn",
+	"Error reporting review":                       "Lỗi khi báo cáo đánh giá",
+	"Vote not found":                               "Bạn chưa bình chọn đánh giá này",
+	"You cannot vote on or report your own review": "Bạn không thể bình chọn hoặc báo cáo đánh giá của chính mình",
+	"You have already reported this review":        "Bạn đã báo cáo đánh giá này",
+
+	// Thông số sản phẩm và thuộc tính theo danh mục
+	"Invalid product specs":                      "Thông số sản phẩm không hợp lệ",
+	"Invalid attribute filter":                   "Bộ lọc thuộc tính không hợp lệ",
+	"Category attributes retrieved successfully": "Lấy danh sách thuộc tính của danh mục thành công",
+	"Error fetching category attributes":         "Lỗi khi lấy danh sách thuộc tính của danh mục",
+	"Category attribute created successfully":    "Tạo thuộc tính danh mục thành công",
+	"Category attribute updated successfully":    "Cập nhật thuộc tính danh mục thành công",
+	"Category attribute deleted successfully":    "Xóa thuộc tính danh mục thành công",
+	"Error creating category attribute":          "Lỗi khi tạo thuộc tính danh mục",
+	"Error updating category attribute":          "Lỗi khi cập nhật thuộc tính danh mục",
+	"Error deleting category attribute":          "Lỗi khi xóa thuộc tính danh mục",
+	"Category attribute not found":               "Không tìm thấy thuộc tính danh mục",
+	"Category attribute already exists":          "Danh mục đã có thuộc tính với khóa này",
+	"Invalid category attribute":                 "Thuộc tính danh mục không hợp lệ",
+	"Invalid category attribute ID":              "ID thuộc tính danh mục không hợp lệ",
+
+	// Trạng thái sản phẩm (draft/published/archived)
+	"Invalid publish schedule":          "Lịch đăng sản phẩm không hợp lệ",
+	"Product is not available for sale": "Sản phẩm hiện không được bán",
+
+	// SKU và nhân bản sản phẩm
+	"Product SKU already exists":      "SKU sản phẩm đã tồn tại",
+	"Product duplicated successfully": "Nhân bản sản phẩm thành công",
+	"Error duplicating product":       "Lỗi khi nhân bản sản phẩm",
+	"Error copying product image":     "Lỗi khi sao chép ảnh sản phẩm",
+
+	// Gợi ý tìm kiếm
+	"Suggestions retrieved successfully": "Lấy gợi ý tìm kiếm thành công",
+	"Error fetching suggestions":         "Lỗi khi lấy gợi ý tìm kiếm",
+
+	// Kiểm tra tồn kho
+	"Stock checked successfully": "Kiểm tra tồn kho thành công",
+	"Error checking stock":       "Lỗi khi kiểm tra tồn kho",
+
+	// Sitemap
+	"Sitemap not found":      "Không tìm thấy sitemap",
+	"Error fetching sitemap": "Lỗi khi lấy sitemap",
+
+	// Dọn file upload
+	"Upload cleanup preview retrieved successfully": "Lấy kết quả chạy thử dọn file upload thành công",
+	"Error scanning uploads":                        "Lỗi khi quét thư mục upload",
+
+	// Video sản phẩm
+	"Product media retrieved successfully": "Lấy danh sách ảnh và video của sản phẩm thành công",
+	"Error fetching product media":         "Lỗi khi lấy ảnh và video của sản phẩm",
+	"No video file provided":               "Chưa chọn file video",
+	"Video uploaded, processing":           "Đã tải video lên, đang xử lý",
+	"Video added, processing":              "Đã thêm video, đang xử lý",
+	"Error adding video":                   "Lỗi khi thêm video",
+	"Product media deleted successfully":   "Xóa video thành công",
+	"Error deleting product media":         "Lỗi khi xóa video",
+	"Product media not found":              "Không tìm thấy video",
+	"Video limit reached":                  "Đã đạt số video tối đa",
+	"Invalid video URL":                    "Đường dẫn video không hợp lệ",
+	"Invalid media ID":                     "ID video không hợp lệ",
+
+	// URL ảnh sản phẩm
+	"Invalid image URL":     "Đường dẫn ảnh không hợp lệ",
+	"Could not fetch image": "Không tải được ảnh từ đường dẫn",
+
+	// Duyệt thay đổi sản phẩm
+	"Catalog editor access required":                "Cần quyền biên tập danh mục",
+	"Invalid revision ID":                           "ID bản thay đổi không hợp lệ",
+	"Product revision submitted for review":         "Đã gửi thay đổi sản phẩm chờ duyệt",
+	"Error submitting product revision":             "Lỗi khi gửi thay đổi sản phẩm",
+	"Product revisions retrieved successfully":      "Lấy danh sách thay đổi sản phẩm thành công",
+	"Error fetching product revisions":              "Lỗi khi lấy danh sách thay đổi sản phẩm",
+	"Product revision retrieved successfully":       "Lấy thay đổi sản phẩm thành công",
+	"Error fetching product revision":               "Lỗi khi lấy thay đổi sản phẩm",
+	"Product revision cancelled":                    "Đã rút lại thay đổi sản phẩm",
+	"Error cancelling product revision":             "Lỗi khi rút lại thay đổi sản phẩm",
+	"Product revision approved":                     "Đã duyệt và áp dụng thay đổi sản phẩm",
+	"Error approving product revision":              "Lỗi khi duyệt thay đổi sản phẩm",
+	"Product revision rejected":                     "Đã từ chối thay đổi sản phẩm",
+	"Error rejecting product revision":              "Lỗi khi từ chối thay đổi sản phẩm",
+	"Product revision not found":                    "Không tìm thấy thay đổi sản phẩm",
+	"Product revision has already been resolved":    "Thay đổi sản phẩm đã được xử lý",
+	"Product revision conflicts with later changes": "Thay đổi xung đột với chỉnh sửa sau khi gửi",
+	"You cannot review your own revision":           "Không thể tự duyệt thay đổi của mình",
+	"Product revision does not change anything":     "Bản thay đổi không thay đổi gì",
+
+	// Lịch sử sản phẩm
+	"Product history retrieved successfully":              "Lấy lịch sử sản phẩm thành công",
+	"Error fetching product history":                      "Lỗi khi lấy lịch sử sản phẩm",
+	"Invalid revision":                                    "Phiên bản không hợp lệ",
+	"Product revision not found in history":               "Không tìm thấy phiên bản trong lịch sử sản phẩm",
+	"Revision specs are no longer valid for the category": "Thông số của phiên bản không còn hợp lệ với danh mục",
+	"Product restored successfully":                       "Khôi phục sản phẩm thành công",
+	"Error restoring product":                             "Lỗi khi khôi phục sản phẩm",
+
+	// Feed sản phẩm
+	"Product feed not found":      "Không tìm thấy feed sản phẩm",
+	"Error fetching product feed": "Lỗi khi lấy feed sản phẩm",
+
+	// Cây danh mục
+	"Category tree retrieved successfully": "Lấy cây danh mục thành công",
+	"Categories retrieved successfully":    "Lấy danh sách danh mục thành công",
+	"Category created successfully":        "Tạo danh mục thành công",
+	"Category updated successfully":        "Cập nhật danh mục thành công",
+	"Category deleted successfully":        "Xóa danh mục thành công",
+	"Error fetching category tree":         "Lỗi khi lấy cây danh mục",
+	"Error fetching categories":            "Lỗi khi lấy danh sách danh mục",
+	"Error creating category":              "Lỗi khi tạo danh mục",
+	"Error updating category":              "Lỗi khi cập nhật danh mục",
+	"Error deleting category":              "Lỗi khi xóa danh mục",
+	"Category not found":                   "Không tìm thấy danh mục",
+	"Category already exists":              "Danh mục đã tồn tại",
+	"Invalid category":                     "Danh mục không hợp lệ",
+	"Invalid category ID":                  "ID danh mục không hợp lệ",
+
+	// Từ điển đồng nghĩa cho tìm kiếm
+	"Search synonyms retrieved successfully": "Lấy từ điển đồng nghĩa thành công",
+	"Search synonym created successfully":    "Thêm từ đồng nghĩa thành công",
+	"Search synonym updated successfully":    "Cập nhật từ đồng nghĩa thành công",
+	"Search synonym deleted successfully":    "Xóa từ đồng nghĩa thành công",
+	"Error fetching search synonyms":         "Lỗi khi lấy từ điển đồng nghĩa",
+	"Error creating search synonym":          "Lỗi khi thêm từ đồng nghĩa",
+	"Error updating search synonym":          "Lỗi khi cập nhật từ đồng nghĩa",
+	"Error deleting search synonym":          "Lỗi khi xóa từ đồng nghĩa",
+	"Search synonym not found":               "Không tìm thấy từ đồng nghĩa",
+	"Search synonym already exists":          "Từ khóa đã có trong từ điển đồng nghĩa",
+	"Invalid search synonym":                 "Từ đồng nghĩa không hợp lệ",
+	"Invalid search synonym ID":              "ID từ đồng nghĩa không hợp lệ",
+
+	// Lịch đổi giá (flash sale)
+	"Price changes retrieved successfully":              "Lấy danh sách lịch đổi giá thành công",
+	"Price change scheduled successfully":               "Lên lịch đổi giá thành công",
+	"Price change cancelled successfully":               "Hủy lịch đổi giá thành công",
+	"Error fetching price changes":                      "Lỗi khi lấy danh sách lịch đổi giá",
+	"Error creating price change":                       "Lỗi khi lên lịch đổi giá",
+	"Error cancelling price change":                     "Lỗi khi hủy lịch đổi giá",
+	"Invalid price change ID":                           "ID lịch đổi giá không hợp lệ",
+	"Price change not found":                            "Không tìm thấy lịch đổi giá",
+	"Price change must end in the future":               "Thời điểm kết thúc lịch đổi giá phải ở tương lai",
+	"Product already has a price change in this period": "Sản phẩm đã có lịch đổi giá trong khoảng thời gian này",
+	"Price change has already ended":                    "Lịch đổi giá đã kết thúc",
+
+	// Sản phẩm đã xem (phiên của khách)
+	"Recently viewed products retrieved successfully": "Lấy danh sách sản phẩm đã xem thành công",
+	"Error fetching recently viewed products":         "Lỗi khi lấy danh sách sản phẩm đã xem",
+
+	// Tên field hiển thị trong thông báo lỗi
+	"Username":             "Tên đăng nhập",
+	"Password":             "Mật khẩu",
+	"Email":                "Email",
+	"Full name":            "Họ tên",
+	"Current password":     "Mật khẩu hiện tại",
+	"New password":         "Mật khẩu mới",
+	"Confirm new password": "Mật khẩu xác nhận",
+	"Name":                 "Tên",
+	"Description":          "Mô tả",
+	"Price":                "Giá",
+	"Stock":                "Tồn kho",
+	"Category":             "Danh mục",
+	"Image url":            "URL ảnh",
+	"Buyer name":           "Tên người mua",
+	"Buyer email":          "Email người mua",
+	"Tax rate":             "Thuế suất",
+	"Items":                "Danh sách hàng hóa",
+	"Quantity":             "Số lượng",
+	"Product id":           "ID sản phẩm",
+	"Reason":               "Lý do",
+	"Note":                 "Ghi chú",
+	"Recipient name":       "Tên người nhận",
+	"Phone":                "Số điện thoại",
+	"Province":             "Tỉnh/thành phố",
+	"District":             "Quận/huyện",
+	"Ward":                 "Phường/xã",
+	"Street":               "Địa chỉ",
+	"Address id":           "ID địa chỉ",
+	"Payment type":         "Hình thức thanh toán",
+	"Shipping option":      "Cách giao hàng",
+	"Weight grams":         "Khối lượng (gram)",
+	"Rate":                 "Thuế suất",
+	"Order item id":        "ID dòng hàng",
+	"Order ids":            "Danh sách ID đơn hàng",
+	"Preferences":          "Lựa chọn nhận thông báo",
+	"Event":                "Loại thông báo",
+	"Target price":         "Giá mong muốn",
+	"Amount":               "Số tiền",
+	"Type":                 "Loại",
+	"Priority":             "Độ ưu tiên",
+	"Buy quantity":         "Số lượng mua",
+	"Get quantity":         "Số lượng tặng",
+	"Percent":              "Phần trăm",
+	"Min subtotal":         "Giá trị đơn tối thiểu",
+	"Amount off":           "Số tiền giảm",
+	"Body":                 "Nội dung",
+	"Status":               "Trạng thái",
+	"Rating":               "Số sao",
+	"Title":                "Tiêu đề",
+	"Helpful":              "Hữu ích",
+	"Key":                  "Khóa",
+	"Label":                "Nhãn",
+	"Options":              "Giá trị cho phép",
+	"PublishAt":            "Thời điểm đăng",
+	"StartsAt":             "Thời điểm bắt đầu",
+	"EndsAt":               "Thời điểm kết thúc",
+	"SKU":                  "SKU",
+	"Term":                 "Từ khóa",
+	"Synonyms":             "Từ đồng nghĩa",
+}
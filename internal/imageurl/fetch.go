@@ -0,0 +1,136 @@
+package imageurl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // đăng ký decoder GIF
+	_ "image/jpeg" // đăng ký decoder JPEG
+	_ "image/png"  // đăng ký decoder PNG
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/netguard"
+)
+
+var (
+	// ErrBlockedAddress được trả về khi host phân giải ra địa chỉ không công khai (loopback, mạng nội bộ,
+	// link-local, metadata của cloud, ...)
+	ErrBlockedAddress = errors.New("image host resolves to a non-public address")
+	// ErrNotImage được trả về khi nội dung tải về không phải ảnh JPEG, PNG hoặc GIF hợp lệ
+	ErrNotImage = errors.New("downloaded content is not a JPEG, PNG or GIF image")
+	// ErrTooLarge được trả về khi ảnh vượt quá dung lượng hoặc kích thước cho phép
+	ErrTooLarge = errors.New("image is too large")
+)
+
+const (
+	// MaxImageSide là cạnh dài nhất (pixel) của ảnh được tải về, tránh ảnh nén cực lớn khi giải mã
+	MaxImageSide = 8000
+	fetchTimeout = 15 * time.Second
+	maxRedirects = 3
+)
+
+// Image là ảnh đã tải về và kiểm tra
+type Image struct {
+	Data []byte
+	// Ext là đuôi file theo định dạng thật của nội dung (.jpg, .png, .gif)
+	Ext    string
+	Width  int
+	Height int
+}
+
+// Fetcher tải ảnh từ URL https bên ngoài. Địa chỉ IP được kiểm tra lúc kết nối (sau khi phân giải DNS,
+// kể cả khi chuyển hướng) nên host trỏ DNS về mạng nội bộ cũng bị chặn; proxy của môi trường không
+// được dùng, chỉ theo tối đa 3 lần chuyển hướng sang https.
+type Fetcher struct {
+	client   *http.Client
+	maxBytes int64
+}
+
+// NewFetcher tạo fetcher giới hạn ảnh ở PRODUCT_IMAGE_MAX_MB (mặc định 5MB)
+func NewFetcher() *Fetcher {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: netguard.Control(ErrBlockedAddress),
+	}
+	transport := &http.Transport{
+		Proxy:                  nil,
+		DialContext:            dialer.DialContext,
+		TLSHandshakeTimeout:    5 * time.Second,
+		ResponseHeaderTimeout:  10 * time.Second,
+		MaxResponseHeaderBytes: 64 << 10,
+		DisableCompression:     true,
+	}
+	return &Fetcher{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   fetchTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("stopped after %d redirects", maxRedirects)
+				}
+				if req.URL.Scheme != "https" {
+					return fmt.Errorf("%w: redirect to a non-https URL", ErrNotAllowed)
+				}
+				return nil
+			},
+		},
+		maxBytes: int64(config.Int("PRODUCT_IMAGE_MAX_MB", 5)) << 20,
+	}
+}
+
+// Fetch tải ảnh ở rawURL (phải là https) và kiểm tra nội dung là ảnh JPEG, PNG hoặc GIF hợp lệ
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Image, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL.Scheme != "https" {
+		return nil, fmt.Errorf("%w: only https URLs are accepted", ErrNotAllowed)
+	}
+	req.Header.Set("Accept", "image/jpeg, image/png, image/gif")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image host returned %d", resp.StatusCode)
+	}
+	if resp.ContentLength > f.maxBytes {
+		return nil, fmt.Errorf("%w: at most %d MB", ErrTooLarge, f.maxBytes>>20)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > f.maxBytes {
+		return nil, fmt.Errorf("%w: at most %d MB", ErrTooLarge, f.maxBytes>>20)
+	}
+
+	// Định dạng được xác định theo nội dung, không theo Content-Type hay đuôi file của URL
+	var ext string
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		ext = ".jpg"
+	case "image/png":
+		ext = ".png"
+	case "image/gif":
+		ext = ".gif"
+	default:
+		return nil, ErrNotImage
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrNotImage
+	}
+	if cfg.Width > MaxImageSide || cfg.Height > MaxImageSide {
+		return nil, fmt.Errorf("%w: at most %dx%d pixels", ErrTooLarge, MaxImageSide, MaxImageSide)
+	}
+	return &Image{Data: data, Ext: ext, Width: cfg.Width, Height: cfg.Height}, nil
+}
@@ -0,0 +1,116 @@
+// Package imageurl kiểm tra URL ảnh do client gửi trực tiếp (ví dụ image_url của sản phẩm) và tải ảnh ở
+// host ngoài về lưu trong storage nội bộ. Chỉ chấp nhận đường dẫn trong thư mục upload hoặc URL https ở
+// các host được cấu hình, để ảnh không trỏ tới địa chỉ nội bộ và không gây mixed content trên trang https.
+// Việc tải ảnh dùng HTTP client chặn kết nối tới địa chỉ không công khai (chống SSRF).
+package imageurl
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+)
+
+// ErrNotAllowed được trả về khi URL ảnh không thỏa chính sách
+var ErrNotAllowed = errors.New("image URL not allowed")
+
+// Kind là loại URL ảnh đã được kiểm tra
+type Kind int
+
+const (
+	// Local là đường dẫn file trong thư mục upload (/static/uploads/... hoặc /uploads/...)
+	Local Kind = iota
+	// Allowed là URL https ở host trong danh sách cho phép, được dùng trực tiếp
+	Allowed
+	// Remote là URL https ở host khác; chỉ hợp lệ sau khi được tải về bằng Fetcher (khi bật Rehost)
+	Remote
+)
+
+// localPrefixes là các tiền tố đường dẫn của file trong thư mục upload
+var localPrefixes = []string{"/static/uploads/", "/uploads/"}
+
+// Policy là chính sách URL ảnh
+type Policy struct {
+	// AllowedHosts là các host (CDN, storage) được dùng trực tiếp; "*.example.com" khớp mọi subdomain
+	AllowedHosts []string
+	// Rehost cho phép URL https ở host khác: ảnh được tải về thư mục upload thay vì dùng URL gốc
+	Rehost bool
+}
+
+// PolicyFromEnv đọc PRODUCT_IMAGE_HOSTS (danh sách host phân tách bằng dấu phẩy, mặc định rỗng: chỉ
+// nhận ảnh đã upload) và PRODUCT_IMAGE_REHOST (true để tải ảnh ở host khác về)
+func PolicyFromEnv() Policy {
+	var hosts []string
+	for _, host := range strings.Split(config.String("PRODUCT_IMAGE_HOSTS", ""), ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return Policy{AllowedHosts: hosts, Rehost: config.String("PRODUCT_IMAGE_REHOST", "") == "true"}
+}
+
+// Classify kiểm tra raw và cho biết loại URL; URL không hợp lệ hoặc không được phép trả về lỗi bọc
+// ErrNotAllowed kèm lý do
+func (p Policy) Classify(raw string) (Kind, error) {
+	if strings.HasPrefix(raw, "/") && !strings.HasPrefix(raw, "//") {
+		if !isLocalPath(raw) {
+			return 0, fmt.Errorf("%w: local images must be files under /static/uploads/", ErrNotAllowed)
+		}
+		return Local, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return 0, fmt.Errorf("%w: must be an uploaded image path or an https URL", ErrNotAllowed)
+	}
+	if u.Scheme != "https" {
+		return 0, fmt.Errorf("%w: only https URLs are accepted", ErrNotAllowed)
+	}
+	if u.User != nil {
+		return 0, fmt.Errorf("%w: URLs with credentials are not accepted", ErrNotAllowed)
+	}
+	if port := u.Port(); port != "" && port != "443" {
+		return 0, fmt.Errorf("%w: only the default https port is accepted", ErrNotAllowed)
+	}
+	host := strings.ToLower(u.Hostname())
+	if net.ParseIP(host) != nil {
+		return 0, fmt.Errorf("%w: IP address hosts are not accepted", ErrNotAllowed)
+	}
+	if p.hostAllowed(host) {
+		return Allowed, nil
+	}
+	if p.Rehost {
+		return Remote, nil
+	}
+	return 0, fmt.Errorf("%w: host %s is not in PRODUCT_IMAGE_HOSTS", ErrNotAllowed, host)
+}
+
+func (p Policy) hostAllowed(host string) bool {
+	for _, allowed := range p.AllowedHosts {
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// isLocalPath kiểm tra đường dẫn đã ở dạng chuẩn (không có .., //, query) và nằm trong thư mục upload
+func isLocalPath(p string) bool {
+	if strings.ContainsAny(p, "?#\\%") || path.Clean(p) != p {
+		return false
+	}
+	for _, prefix := range localPrefixes {
+		if strings.HasPrefix(p, prefix) && len(p) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,244 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMaxAttempts = 5
+	defaultLockTimeout = 10 * time.Minute
+)
+
+// HandlerFunc xử lý một job; trả về lỗi để job được thử lại theo backoff
+type HandlerFunc func(ctx context.Context, payload json.RawMessage) error
+
+// Option tùy chỉnh job khi đưa vào hàng đợi
+type Option func(*models.Job)
+
+// Delay lùi thời điểm chạy job
+func Delay(d time.Duration) Option {
+	return func(j *models.Job) { j.RunAt = j.RunAt.Add(d) }
+}
+
+// MaxAttempts đặt số lần thử tối đa trước khi job bị đánh dấu failed
+func MaxAttempts(n int) Option {
+	return func(j *models.Job) {
+		if n > 0 {
+			j.MaxAttempts = n
+		}
+	}
+}
+
+// Client đưa job vào hàng đợi (bảng jobs); dùng trong API server
+type Client struct {
+	repo *repository.JobRepository
+}
+
+// NewClient tạo client mới
+func NewClient(db *gorm.DB) *Client {
+	return &Client{repo: repository.NewJobRepository(db)}
+}
+
+// Enqueue đưa job vào hàng đợi với payload được mã hóa JSON. Nếu ctx mang transaction của
+// repository.TxManager, job chỉ được ghi khi transaction commit (outbox).
+func (c *Client) Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...Option) (*models.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode payload: %w", err)
+	}
+	job := &models.Job{
+		Type:        jobType,
+		Payload:     models.JSON(data),
+		Status:      models.JobPending,
+		MaxAttempts: defaultMaxAttempts,
+		RunAt:       time.Now(),
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	if err := c.repo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// EnqueueLogged giống Enqueue nhưng chỉ ghi log khi lỗi, dùng sau khi thao tác
+// nghiệp vụ đã thành công nên không bị hủy theo ctx (ví dụ khi client ngắt kết nối). An toàn khi client là nil.
+func (c *Client) EnqueueLogged(ctx context.Context, jobType string, payload interface{}, opts ...Option) {
+	if c == nil {
+		return
+	}
+	if _, err := c.Enqueue(context.WithoutCancel(ctx), jobType, payload, opts...); err != nil {
+		log.Printf("Jobs: failed to enqueue %s: %v", jobType, err)
+	}
+}
+
+// WorkerStats chứa thống kê xử lý job của worker
+type WorkerStats struct {
+	Processed  int64     `json:"processed"`
+	Succeeded  int64     `json:"succeeded"`
+	Failed     int64     `json:"failed"`
+	Retried    int64     `json:"retried"`
+	LastPollAt time.Time `json:"last_poll_at"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+// Worker lấy job đã tới hạn từ hàng đợi và gọi handler tương ứng
+type Worker struct {
+	repo         *repository.JobRepository
+	concurrency  int
+	pollInterval time.Duration
+	lockTimeout  time.Duration
+	handlers     map[string]HandlerFunc
+
+	mu    sync.RWMutex
+	stats WorkerStats
+}
+
+// NewWorker tạo worker mới xử lý tối đa concurrency job cùng lúc
+func NewWorker(db *gorm.DB, concurrency int, pollInterval time.Duration) *Worker {
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	if pollInterval <= 0 {
+		pollInterval = 2 * time.Second
+	}
+	return &Worker{
+		repo:         repository.NewJobRepository(db),
+		concurrency:  concurrency,
+		pollInterval: pollInterval,
+		lockTimeout:  defaultLockTimeout,
+		handlers:     make(map[string]HandlerFunc),
+	}
+}
+
+// Handle đăng ký handler cho một loại job. Worker chỉ nhận các loại job đã đăng ký.
+func (w *Worker) Handle(jobType string, handler HandlerFunc) {
+	w.handlers[jobType] = handler
+}
+
+// Start chạy vòng lặp lấy job cho tới khi ctx bị hủy
+func (w *Worker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.pollInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := w.ProcessDue(ctx); err != nil {
+					log.Printf("Jobs: failed to process jobs: %v", err)
+					w.mu.Lock()
+					w.stats.LastError = err.Error()
+					w.mu.Unlock()
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// ProcessDue nhận một lô job đã tới hạn và chạy chúng song song, chờ tới khi cả lô hoàn tất
+func (w *Worker) ProcessDue(ctx context.Context) error {
+	types := make([]string, 0, len(w.handlers))
+	for t := range w.handlers {
+		types = append(types, t)
+	}
+	if len(types) == 0 {
+		return nil
+	}
+
+	jobs, err := w.repo.Claim(ctx, types, w.concurrency, w.lockTimeout)
+	w.mu.Lock()
+	w.stats.LastPollAt = time.Now()
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for i := range jobs {
+		wg.Add(1)
+		go func(job *models.Job) {
+			defer wg.Done()
+			w.run(ctx, job)
+		}(&jobs[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+func (w *Worker) run(ctx context.Context, job *models.Job) {
+	err := w.invoke(ctx, job)
+
+	w.mu.Lock()
+	w.stats.Processed++
+	w.mu.Unlock()
+
+	if err == nil {
+		if err := w.repo.Complete(ctx, job.ID); err != nil {
+			log.Printf("Jobs: failed to complete job %d: %v", job.ID, err)
+		}
+		w.mu.Lock()
+		w.stats.Succeeded++
+		w.mu.Unlock()
+		return
+	}
+
+	var retryAt *time.Time
+	if job.Attempts < job.MaxAttempts {
+		t := time.Now().Add(backoff(job.Attempts))
+		retryAt = &t
+	}
+	log.Printf("Jobs: job %d (%s) attempt %d failed: %v", job.ID, job.Type, job.Attempts, err)
+	if err := w.repo.Fail(ctx, job.ID, err.Error(), retryAt); err != nil {
+		log.Printf("Jobs: failed to record failure of job %d: %v", job.ID, err)
+	}
+
+	w.mu.Lock()
+	if retryAt != nil {
+		w.stats.Retried++
+	} else {
+		w.stats.Failed++
+	}
+	w.mu.Unlock()
+}
+
+// invoke gọi handler và chuyển panic thành lỗi để job được thử lại
+func (w *Worker) invoke(ctx context.Context, job *models.Job) (err error) {
+	handler, ok := w.handlers[job.Type]
+	if !ok {
+		return fmt.Errorf("no handler registered for job type %q", job.Type)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, json.RawMessage(job.Payload))
+}
+
+// GetStats trả về thống kê của worker
+func (w *Worker) GetStats() WorkerStats {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.stats
+}
+
+// backoff trả về thời gian chờ lũy thừa: 30s, 1m, 2m, ... tối đa 1 giờ
+func backoff(attempts int) time.Duration {
+	delay := 30 * time.Second << uint(attempts-1)
+	if delay > time.Hour || delay <= 0 {
+		delay = time.Hour
+	}
+	return delay
+}
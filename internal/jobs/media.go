@@ -0,0 +1,256 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// MediaPayload là payload của job kiểm tra và chuyển mã video của sản phẩm
+type MediaPayload struct {
+	MediaID uint `json:"media_id"`
+}
+
+// MaxVideoWidth là chiều rộng tối đa (pixel) của video sau khi chuyển mã
+const MaxVideoWidth = 1280
+
+// oEmbedEndpoints là API oEmbed dùng để kiểm tra video YouTube/Vimeo và lấy thumbnail làm poster
+var oEmbedEndpoints = map[string]string{
+	models.MediaSourceYouTube: "https://www.youtube.com/oembed?format=json&url=",
+	models.MediaSourceVimeo:   "https://vimeo.com/api/oembed.json?url=",
+}
+
+var oEmbedHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// errMediaRejected đánh dấu lỗi do chính video (file hỏng, quá dài, video không tồn tại hoặc bị chặn
+// nhúng); media được đánh dấu failed thay vì thử lại
+var errMediaRejected = errors.New("media rejected")
+
+// ProcessMedia kiểm tra một media đang processing và đánh dấu ready hoặc failed. Lỗi tạm thời (mạng,
+// database) được trả về để job được thử lại.
+func ProcessMedia(ctx context.Context, repo *repository.ProductMediaRepository, id uint) error {
+	media, err := repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil // đã bị xóa
+	}
+	if err != nil {
+		return err
+	}
+	if media.Status != models.MediaStatusProcessing {
+		return nil
+	}
+
+	if media.Source == models.MediaSourceUpload {
+		err = processVideoFile(ctx, media)
+	} else {
+		err = fetchOEmbed(ctx, media)
+	}
+	switch {
+	case errors.Is(err, errMediaRejected):
+		reason := strings.TrimPrefix(err.Error(), errMediaRejected.Error()+": ")
+		media.Status, media.Error = models.MediaStatusFailed, truncate(reason, 500)
+	case err != nil:
+		return err
+	default:
+		media.Status, media.Error = models.MediaStatusReady, ""
+	}
+	return repo.SaveResult(ctx, media)
+}
+
+// videoProbe là phần kết quả ffprobe được dùng
+type videoProbe struct {
+	Streams []struct {
+		CodecType string `json:"codec_type"`
+		CodecName string `json:"codec_name"`
+		Width     int    `json:"width"`
+		Height    int    `json:"height"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+	} `json:"format"`
+}
+
+// processVideoFile kiểm tra file bằng ffprobe, chuyển mã sang MP4 H.264/AAC (rộng tối đa MaxVideoWidth,
+// faststart để phát ngay khi đang tải) khi cần, và trích một khung hình làm poster. File gốc bị thay bởi
+// file đã chuyển mã. ffmpeg/ffprobe được tìm theo FFMPEG_PATH/FFPROBE_PATH (mặc định trong PATH); độ dài
+// tối đa là PRODUCT_VIDEO_MAX_DURATION (mặc định 10m).
+func processVideoFile(ctx context.Context, media *models.ProductMedia) error {
+	source := filepath.FromSlash(strings.TrimPrefix(media.URL, "/"))
+	probe, err := probeVideo(ctx, source)
+	if err != nil {
+		return err
+	}
+	var video, audio string
+	for _, stream := range probe.Streams {
+		switch {
+		case stream.CodecType == "video" && video == "":
+			video = stream.CodecName
+			media.Width, media.Height = stream.Width, stream.Height
+		case stream.CodecType == "audio" && audio == "":
+			audio = stream.CodecName
+		}
+	}
+	if video == "" {
+		return fmt.Errorf("%w: file has no video stream", errMediaRejected)
+	}
+	duration, _ := strconv.ParseFloat(probe.Format.Duration, 64)
+	maxDuration := config.Duration("PRODUCT_VIDEO_MAX_DURATION", 10*time.Minute)
+	if duration <= 0 {
+		return fmt.Errorf("%w: could not read video duration", errMediaRejected)
+	}
+	if duration > maxDuration.Seconds() {
+		return fmt.Errorf("%w: video is longer than %s", errMediaRejected, maxDuration)
+	}
+	media.DurationSeconds = math.Round(duration*10) / 10
+
+	output := source
+	web := strings.Contains(probe.Format.FormatName, "mp4") && video == "h264" && (audio == "" || audio == "aac") && media.Width <= MaxVideoWidth
+	if !web {
+		output = strings.TrimSuffix(source, filepath.Ext(source)) + "_web.mp4"
+		args := []string{"-y", "-i", source, "-map", "0:v:0", "-map", "0:a:0?",
+			"-c:v", "libx264", "-preset", "veryfast", "-crf", "23", "-pix_fmt", "yuv420p",
+			"-vf", fmt.Sprintf("scale='min(%d,iw)':-2", MaxVideoWidth),
+			"-c:a", "aac", "-b:a", "128k", "-movflags", "+faststart", output}
+		if err := runFFmpeg(ctx, args...); err != nil {
+			os.Remove(output)
+			return err
+		}
+		converted, err := probeVideo(ctx, output)
+		if err != nil {
+			os.Remove(output)
+			return err
+		}
+		for _, stream := range converted.Streams {
+			if stream.CodecType == "video" {
+				media.Width, media.Height = stream.Width, stream.Height
+				break
+			}
+		}
+	}
+
+	poster := strings.TrimSuffix(output, filepath.Ext(output)) + ".jpg"
+	at := strconv.FormatFloat(math.Min(1, duration/2), 'f', 2, 64)
+	if err := runFFmpeg(ctx, "-y", "-ss", at, "-i", output, "-frames:v", "1", "-q:v", "3", poster); err != nil {
+		if output != source {
+			os.Remove(output)
+		}
+		return err
+	}
+	if output != source {
+		os.Remove(source)
+	}
+	media.URL = "/" + filepath.ToSlash(output)
+	media.PosterURL = "/" + filepath.ToSlash(poster)
+	return nil
+}
+
+func probeVideo(ctx context.Context, path string) (*videoProbe, error) {
+	cmd := exec.CommandContext(ctx, config.String("FFPROBE_PATH", "ffprobe"),
+		"-v", "error", "-print_format", "json", "-show_format", "-show_streams", path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, toolError("ffprobe", err, stderr.String())
+	}
+	var probe videoProbe
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return nil, fmt.Errorf("%w: unreadable ffprobe output: %v", errMediaRejected, err)
+	}
+	return &probe, nil
+}
+
+func runFFmpeg(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, config.String("FFMPEG_PATH", "ffmpeg"), append([]string{"-v", "error"}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return toolError("ffmpeg", err, stderr.String())
+	}
+	return nil
+}
+
+// toolError phân loại lỗi của ffmpeg/ffprobe: thiếu công cụ hoặc bị hủy là lỗi tạm thời (thử lại khi đã
+// cài đặt), công cụ chạy nhưng thất bại nghĩa là file không xử lý được
+func toolError(tool string, err error, stderr string) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ProcessState.Exited() {
+		return fmt.Errorf("%w: %s: %s", errMediaRejected, tool, strings.TrimSpace(truncate(stderr, 300)))
+	}
+	return fmt.Errorf("%s: %w", tool, err)
+}
+
+// oEmbedResponse là phần phản hồi oEmbed được dùng
+type oEmbedResponse struct {
+	Title        string  `json:"title"`
+	ThumbnailURL string  `json:"thumbnail_url"`
+	Width        int     `json:"width"`
+	Height       int     `json:"height"`
+	Duration     float64 `json:"duration"` // chỉ Vimeo trả về
+}
+
+// fetchOEmbed kiểm tra video YouTube/Vimeo tồn tại và cho phép nhúng, lấy thumbnail làm poster
+func fetchOEmbed(ctx context.Context, media *models.ProductMedia) error {
+	endpoint, ok := oEmbedEndpoints[media.Source]
+	if !ok {
+		return fmt.Errorf("%w: unknown video source %q", errMediaRejected, media.Source)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+url.QueryEscape(media.URL), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := oEmbedHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest:
+		return fmt.Errorf("%w: video not found", errMediaRejected)
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w: video is private or embedding is disabled", errMediaRejected)
+	default:
+		return fmt.Errorf("oembed: %s returned %d", media.Source, resp.StatusCode)
+	}
+	var info oEmbedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return fmt.Errorf("oembed: %w", err)
+	}
+	media.PosterURL = info.ThumbnailURL
+	media.Width, media.Height = info.Width, info.Height
+	media.DurationSeconds = info.Duration
+	if media.Title == "" {
+		media.Title = truncate(info.Title, 255)
+	}
+	return nil
+}
+
+// truncate cắt s còn tối đa n byte mà không cắt đôi ký tự UTF-8
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,151 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif" // đăng ký decoder GIF
+	"image/jpeg"
+	_ "image/png" // đăng ký decoder PNG
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Các loại job có sẵn
+const (
+	TypeProductThumbnail = "product.thumbnail"
+	TypeRefreshListings  = "listings.refresh"
+	TypeProductMedia     = "product.media"
+)
+
+// ThumbnailSize là cạnh dài nhất (pixel) của ảnh thumbnail
+const ThumbnailSize = 320
+
+// ThumbnailPayload là payload của job tạo thumbnail cho ảnh sản phẩm
+type ThumbnailPayload struct {
+	ProductID uint   `json:"product_id"`
+	ImagePath string `json:"image_path"` // đường dẫn file trên đĩa, ví dụ static/uploads/1_123.jpg
+}
+
+// RefreshListingsPayload là payload của job dựng lại read model sản phẩm; rỗng = dựng lại toàn bộ
+type RefreshListingsPayload struct {
+	ProductIDs []uint `json:"product_ids,omitempty"`
+}
+
+// RegisterTasks đăng ký handler cho các loại job có sẵn
+func RegisterTasks(w *Worker, db *gorm.DB) {
+	listings := repository.NewProductListingRepository(db)
+	media := repository.NewProductMediaRepository(db)
+
+	w.Handle(TypeProductThumbnail, func(ctx context.Context, payload json.RawMessage) error {
+		var p ThumbnailPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return err
+		}
+		_, err := GenerateThumbnail(p.ImagePath)
+		return err
+	})
+
+	w.Handle(TypeProductMedia, func(ctx context.Context, payload json.RawMessage) error {
+		var p MediaPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return err
+		}
+		return ProcessMedia(ctx, media, p.MediaID)
+	})
+
+	w.Handle(TypeRefreshListings, func(ctx context.Context, payload json.RawMessage) error {
+		var p RefreshListingsPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return err
+		}
+		if len(p.ProductIDs) == 0 {
+			return listings.RefreshAll(ctx)
+		}
+		return listings.Refresh(ctx, p.ProductIDs...)
+	})
+}
+
+// ThumbnailPath trả về đường dẫn thumbnail tương ứng với một file ảnh
+func ThumbnailPath(imagePath string) string {
+	base := strings.TrimSuffix(filepath.Base(imagePath), filepath.Ext(imagePath))
+	return filepath.Join(filepath.Dir(imagePath), "thumbs", base+".jpg")
+}
+
+// GenerateThumbnail thu nhỏ ảnh về ThumbnailSize và lưu dạng JPEG cạnh ảnh gốc
+func GenerateThumbnail(imagePath string) (string, error) {
+	f, err := os.Open(imagePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	src, _, err := image.Decode(f)
+	if err != nil {
+		return "", fmt.Errorf("decode %s: %w", imagePath, err)
+	}
+
+	out := ThumbnailPath(imagePath)
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		return "", err
+	}
+	dst, err := os.Create(out)
+	if err != nil {
+		return "", err
+	}
+	if err := jpeg.Encode(dst, resize(src, ThumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		dst.Close()
+		os.Remove(out)
+		return "", err
+	}
+	return out, dst.Close()
+}
+
+// resize thu nhỏ ảnh giữ nguyên tỉ lệ bằng cách lấy trung bình các điểm ảnh trong mỗi ô
+func resize(src image.Image, maxSize int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSize && h <= maxSize {
+		return src
+	}
+	nw, nh := maxSize, h*maxSize/w
+	if h > w {
+		nw, nh = w*maxSize/h, maxSize
+	}
+	if nw < 1 {
+		nw = 1
+	}
+	if nh < 1 {
+		nh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, nw, nh))
+	for y := 0; y < nh; y++ {
+		y0, y1 := b.Min.Y+y*h/nh, b.Min.Y+(y+1)*h/nh
+		for x := 0; x < nw; x++ {
+			x0, x1 := b.Min.X+x*w/nw, b.Min.X+(x+1)*w/nw
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(bl / n >> 8)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+	return dst
+}
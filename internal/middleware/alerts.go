@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/notify"
+	"github.com/gin-gonic/gin"
+)
+
+// ServerErrorAlerts gửi cảnh báo cho admin khi số response 5xx trong một phút vượt ngưỡng
+func ServerErrorAlerts(notifier *notify.Dispatcher, threshold int) gin.HandlerFunc {
+	alarm := notify.NewAlarm(threshold, time.Minute, 15*time.Minute)
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Status() < 500 {
+			return
+		}
+		if count, fire := alarm.Hit(); fire {
+			notifier.Alert(c.Request.Context(), notify.Alert{
+				Kind:     notify.AlertServerErrors,
+				Severity: notify.SeverityCritical,
+				Title:    "Spike in server errors",
+				Message:  fmt.Sprintf("%d responses with status 5xx in the last minute", count),
+				Fields: map[string]string{
+					"last_path":   c.FullPath(),
+					"last_status": strconv.Itoa(c.Writer.Status()),
+				},
+			})
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader là header chứa API key của hệ thống tích hợp (kho, ERP)
+const APIKeyHeader = "X-API-Key"
+
+// APIKey chỉ cho qua request có header X-API-Key trùng một trong các key; key rỗng bị bỏ qua nên khi
+// không cấu hình key nào mọi request đều bị từ chối
+func APIKey(keys []string) gin.HandlerFunc {
+	allowed := newKeySet(keys)
+	return func(c *gin.Context) {
+		provided := c.GetHeader(APIKeyHeader)
+		if provided == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": i18n.Localize(c, "API key is required"),
+			})
+			c.Abort()
+			return
+		}
+		if !allowed.contains(provided) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": i18n.Localize(c, "Invalid API key"),
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// QueryToken chỉ cho qua request có tham số query param trùng một trong các token, dùng cho URL cố định
+// được đưa cho dịch vụ bên ngoài không gửi được header (ví dụ Google Merchant Center tải feed sản phẩm)
+func QueryToken(param string, tokens []string) gin.HandlerFunc {
+	allowed := newKeySet(tokens)
+	return func(c *gin.Context) {
+		provided := c.Query(param)
+		if provided == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": i18n.Localize(c, "Access token is required"),
+			})
+			c.Abort()
+			return
+		}
+		if !allowed.contains(provided) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": i18n.Localize(c, "Invalid access token"),
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// keySet là tập key bí mật đã băm SHA-256. Key được so sánh qua bản băm bằng so sánh thời gian hằng để
+// không lộ độ dài hay tiền tố của key.
+type keySet [][sha256.Size]byte
+
+func newKeySet(keys []string) keySet {
+	var set keySet
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			set = append(set, sha256.Sum256([]byte(key)))
+		}
+	}
+	return set
+}
+
+func (s keySet) contains(key string) bool {
+	sum := sha256.Sum256([]byte(key))
+	found := 0
+	for _, hash := range s {
+		found |= subtle.ConstantTimeCompare(sum[:], hash[:])
+	}
+	return found == 1
+}
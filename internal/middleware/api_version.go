@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersion ghi phiên bản API của group route vào context (để utils chọn định dạng response)
+// và vào header API-Version của response
+func APIVersion(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(utils.APIVersionKey, version)
+		c.Header("API-Version", version)
+		c.Next()
+	}
+}
@@ -1,73 +1,178 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/NgTruong624/project_backend/internal/actor"
+	"github.com/NgTruong624/project_backend/internal/i18n"
+	"github.com/NgTruong624/project_backend/internal/token"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
+// TokenVersions trả về token_version hiện tại của user; token có claim "ver" nhỏ hơn giá trị này
+// đã bị thu hồi (ví dụ sau khi đổi email)
+type TokenVersions interface {
+	TokenVersion(ctx context.Context, userID uint) (int, error)
+}
+
+// ClaimsKey là khóa trong gin.Context chứa *token.Claims của request đã xác thực
+const ClaimsKey = "token_claims"
+
+// Chế độ xác thực bằng cookie cho client trình duyệt: access token nằm trong cookie HttpOnly,
+// request thay đổi dữ liệu phải gửi lại token CSRF (cookie đọc được bằng JS) trong header X-CSRF-Token
+const (
+	AccessTokenCookie = "access_token"
+	CSRFCookie        = "csrf_token"
+	CSRFHeader        = "X-CSRF-Token"
+	// AuthModeKey là khóa trong gin.Context cho biết token của request đến từ header hay cookie
+	AuthModeKey = "auth_mode"
+)
+
+// Denylist cho biết token (theo jti) đã bị thu hồi khi đăng xuất
+type Denylist interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
 type JWTMiddleware struct {
-	SecretKey string
+	tokens   *token.Manager
+	versions TokenVersions
+	denylist Denylist
 }
 
-func NewJWTMiddleware(secretKey string) *JWTMiddleware {
+func NewJWTMiddleware(tokens *token.Manager) *JWTMiddleware {
 	return &JWTMiddleware{
-		SecretKey: secretKey,
+		tokens: tokens,
 	}
 }
 
 func (m *JWTMiddleware) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		mode := token.ModeToken
 		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		var tokenString string
+		if authHeader != "" {
+			// Kiểm tra format của token
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": i18n.Localize(c, "Invalid authorization header format"),
+				})
+				c.Abort()
+				return
+			}
+			tokenString = parts[1]
+		} else if cookie, err := c.Cookie(AccessTokenCookie); err == nil && cookie != "" {
+			tokenString, mode = cookie, token.ModeCookie
+		} else {
 			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authorization header is required",
+				"error": i18n.Localize(c, "Authorization header is required"),
 			})
 			c.Abort()
 			return
 		}
 
-		// Kiểm tra format của token
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		claims, err := m.tokens.Parse(tokenString)
+		if err != nil {
+			message := "Invalid token"
+			if errors.Is(err, token.ErrInvalidClaims) {
+				message = "Invalid token claims"
+			}
 			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid authorization header format",
+				"error": i18n.Localize(c, message),
 			})
 			c.Abort()
 			return
 		}
 
-		tokenString := parts[1]
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Kiểm tra signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(m.SecretKey), nil
-		})
+		// Trình duyệt tự gửi cookie kèm request từ trang khác nên request thay đổi dữ liệu phải chứng minh
+		// đến từ frontend bằng token CSRF
+		if mode == token.ModeCookie && !safeMethod(c.Request.Method) && !m.tokens.VerifyCSRF(claims, c.GetHeader(CSRFHeader)) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": i18n.Localize(c, "Invalid CSRF token"),
+			})
+			c.Abort()
+			return
+		}
 
+		revoked, err := m.revoked(c.Request.Context(), claims)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid token",
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.Localize(c, "Error validating session"),
 			})
 			c.Abort()
 			return
 		}
-
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// Lưu thông tin user vào context
-			c.Set("user_id", uint(claims["user_id"].(float64)))
-			c.Set("username", claims["username"].(string))
-			c.Set("role", claims["role"].(string))
-			c.Next()
-		} else {
+		if revoked {
 			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid token claims",
+				"error": i18n.Localize(c, "Session has been revoked"),
 			})
 			c.Abort()
 			return
 		}
+
+		// Lưu thông tin user vào context
+		c.Set("user_id", claims.UserID)
+		c.Set("username", claims.Username)
+		c.Set("role", claims.Role)
+		c.Set(ClaimsKey, claims)
+		c.Set(AuthModeKey, mode)
+		c.Request = c.Request.WithContext(actor.With(c.Request.Context(), claims.UserID))
+		c.Next()
 	}
 }
+
+// SetTokenVersions bật kiểm tra phiên đã bị thu hồi theo token_version của user
+func (m *JWTMiddleware) SetTokenVersions(versions TokenVersions) {
+	m.versions = versions
+}
+
+// SetDenylist bật kiểm tra token đã bị thu hồi theo jti
+func (m *JWTMiddleware) SetDenylist(denylist Denylist) {
+	m.denylist = denylist
+}
+
+// revoked kiểm tra jti của token với denylist và claim "ver" với token_version hiện tại của user.
+// User không còn tồn tại cũng được coi là phiên đã bị thu hồi.
+func (m *JWTMiddleware) revoked(ctx context.Context, claims *token.Claims) (bool, error) {
+	if m.denylist != nil {
+		revoked, err := m.denylist.IsRevoked(ctx, claims.ID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+	if m.versions == nil {
+		return false, nil
+	}
+	current, err := m.versions.TokenVersion(ctx, claims.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return claims.Version < current, nil
+}
+
+// OptionalAuthMiddleware giống AuthMiddleware nhưng cho phép request không có header Authorization
+// và cookie access token đi tiếp như khách vãng lai (không có user_id trong context)
+func (m *JWTMiddleware) OptionalAuthMiddleware() gin.HandlerFunc {
+	required := m.AuthMiddleware()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if cookie, err := c.Cookie(AccessTokenCookie); err != nil || cookie == "" {
+				c.Next()
+				return
+			}
+		}
+		required(c)
+	}
+}
+
+// safeMethod cho biết method không thay đổi dữ liệu nên không cần kiểm tra CSRF
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
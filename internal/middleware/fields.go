@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// SparseFields bật sparse fieldset cho route: ?fields=id,name,price chỉ giữ các field đó trong data của
+// response (utils.NewResponse và NewPaginatedResponse lọc theo danh sách trong context)
+func SparseFields() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var fields []string
+		for _, field := range strings.Split(c.Query("fields"), ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				fields = append(fields, field)
+			}
+		}
+		if len(fields) > 0 {
+			c.Set(utils.FieldsKey, fields)
+		}
+		c.Next()
+	}
+}
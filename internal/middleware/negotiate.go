@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Negotiate bật thương lượng định dạng response cho route: header Accept chọn một encoder đã đăng ký
+// (application/json, application/xml, text/csv, application/vnd.api+json...) và utils.Render ghi response
+// theo định dạng đó. Accept rỗng, */* hoặc không khớp encoder nào trả định dạng mặc định
+// (utils.DefaultMediaType: JSON, hoặc JSON:API khi RESPONSE_FORMAT=jsonapi).
+func Negotiate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Vary", "Accept")
+		offers := utils.MediaTypes()
+		format := c.NegotiateFormat(offers...)
+		if format == "" {
+			format = offers[0]
+		}
+		c.Set(utils.FormatKey, format)
+		c.Next()
+	}
+}
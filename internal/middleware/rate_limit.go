@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/NgTruong624/project_backend/internal/i18n"
+	"github.com/NgTruong624/project_backend/internal/visitor"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
 )
@@ -83,10 +86,19 @@ func (rl *RateLimiter) cleanupInactiveClients() {
 	}
 }
 
+// apiVersionPrefix khớp tiền tố phiên bản /api/vN/; mọi phiên bản API dùng chung giới hạn của endpoint
+var apiVersionPrefix = regexp.MustCompile(`^/api/v[0-9]+/`)
+
+// endpointPath chuẩn hóa path về dạng /api/v1/... không có dấu / ở cuối để so với cấu hình giới hạn
+func endpointPath(path string) string {
+	return apiVersionPrefix.ReplaceAllString(strings.TrimSuffix(path, "/"), "/api/v1/")
+}
+
 func (rl *RateLimiter) getConfigForEndpoint(path string) RateLimitConfig {
-	cleanPath := strings.TrimSuffix(path, "/")
+	cleanPath := endpointPath(path)
 
-	if cleanPath == "/api/v1/auth/login" || cleanPath == "/api/v1/auth/register" {
+	if cleanPath == "/api/v1/auth/login" || cleanPath == "/api/v1/auth/register" ||
+		cleanPath == "/api/v1/newsletter/subscribe" || cleanPath == "/api/v1/support/tickets" {
 		return rl.configs["auth"]
 	}
 
@@ -101,6 +113,9 @@ func (rl *RateLimiter) getConfigForEndpoint(path string) RateLimitConfig {
 	return rl.configs["default"]
 }
 
+// getClientKey chọn bucket giới hạn cho request: theo user nếu đã đăng nhập, theo phiên của khách nếu
+// client gửi lại cookie visitor_id hợp lệ (khách dùng chung IP như sau NAT không chia nhau một bucket),
+// còn lại theo IP. Request vừa được cấp phiên vẫn tính vào bucket của IP nên client bỏ cookie không có bucket riêng.
 func (rl *RateLimiter) getClientKey(c *gin.Context) string {
 	ip := c.ClientIP()
 	userID := c.GetString("user_id")
@@ -108,6 +123,9 @@ func (rl *RateLimiter) getClientKey(c *gin.Context) string {
 	if userID != "" {
 		return fmt.Sprintf("%s:%s", ip, userID)
 	}
+	if visitorID, returning := visitor.FromContext(c); returning {
+		return fmt.Sprintf("%s:visitor:%s", ip, visitorID)
+	}
 	return ip
 }
 
@@ -116,7 +134,7 @@ func (rl *RateLimiter) RateLimitMiddleware() gin.HandlerFunc {
 		clientKey := rl.getClientKey(c)
 		config := rl.getConfigForEndpoint(c.Request.URL.Path)
 
-		if c.Request.URL.Path == "/api/v1/products" {
+		if endpointPath(c.Request.URL.Path) == "/api/v1/products" {
 			if c.Request.Method == "POST" || c.Request.Method == "PUT" || c.Request.Method == "DELETE" {
 				config = rl.configs["product_write"]
 			} else {
@@ -161,8 +179,8 @@ func (rl *RateLimiter) RateLimitMiddleware() gin.HandlerFunc {
 			c.Header("Retry-After", fmt.Sprintf("%.0f", retry.Seconds()))
 
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
-				"error":       "Too Many Requests",
-				"message":     "Rate limit exceeded. Please try again later.",
+				"error":       i18n.Localize(c, "Too Many Requests"),
+				"message":     i18n.Localize(c, "Rate limit exceeded. Please try again later."),
 				"retry_after": int(retry.Seconds()),
 				"limit":       config.Rate,
 				"burst":       config.Burst,
@@ -0,0 +1,253 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"math/rand"
+	"mime"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogAction là action của bản ghi request/response trong audit_logs
+const RequestLogAction = "http.request"
+
+// RequestLogEntityType là entity_type của bản ghi request/response; entity_id là "<METHOD> <route>"
+const RequestLogEntityType = "http_request"
+
+// RequestLogStore lưu bản ghi request/response (AuditLogRepository)
+type RequestLogStore interface {
+	Create(ctx context.Context, entry *models.AuditLog) error
+}
+
+// RequestLogConfig cấu hình ghi log body request/response để hỗ trợ điều tra sự cố
+type RequestLogConfig struct {
+	// SampleRate là tỉ lệ request được ghi ngẫu nhiên (0..1)
+	SampleRate float64
+	// Routes là các route luôn được ghi, dạng "/api/v1/orders" hoặc "POST /api/v1/orders" (theo pattern của route, ví dụ /api/v1/orders/:id)
+	Routes []string
+	// MaxBodyBytes là số byte tối đa được giữ lại của mỗi body
+	MaxBodyBytes int
+}
+
+// RequestLogConfigFromEnv đọc REQUEST_LOG_SAMPLE_PERCENT (mặc định 0), REQUEST_LOG_ROUTES (phân tách bằng
+// dấu phẩy) và REQUEST_LOG_MAX_BODY_BYTES (mặc định 16384)
+func RequestLogConfigFromEnv() RequestLogConfig {
+	cfg := RequestLogConfig{
+		SampleRate:   config.Float("REQUEST_LOG_SAMPLE_PERCENT", 0) / 100,
+		MaxBodyBytes: config.Int("REQUEST_LOG_MAX_BODY_BYTES", 16384),
+	}
+	for _, route := range strings.Split(config.String("REQUEST_LOG_ROUTES", ""), ",") {
+		if route = strings.TrimSpace(route); route != "" {
+			cfg.Routes = append(cfg.Routes, route)
+		}
+	}
+	return cfg
+}
+
+// Enabled cho biết cấu hình có ghi request nào không
+func (cfg RequestLogConfig) Enabled() bool {
+	return cfg.SampleRate > 0 || len(cfg.Routes) > 0
+}
+
+func (cfg RequestLogConfig) matches(method, route string) bool {
+	for _, r := range cfg.Routes {
+		if r == route || r == method+" "+route {
+			return true
+		}
+	}
+	return false
+}
+
+// RequestLogger ghi method, đường dẫn, status, thời gian xử lý, user và body request/response của các
+// request được chọn (theo SampleRate hoặc Routes) vào audit_logs với action http.request. Mật khẩu, token,
+// secret và địa chỉ email trong body và query string được thay bằng "[REDACTED]" trước khi lưu; body
+// multipart (upload file) không được ghi. Bản ghi được lưu sau khi response đã ghi xong, lỗi chỉ được ghi log.
+func RequestLogger(cfg RequestLogConfig, store RequestLogStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" || !(cfg.matches(c.Request.Method, route) || rand.Float64() < cfg.SampleRate) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		requestBody, requestTruncated := captureRequestBody(c, cfg.MaxBodyBytes)
+		writer := &bodyLogWriter{ResponseWriter: c.Writer, limit: cfg.MaxBodyBytes}
+		c.Writer = writer
+		c.Next()
+
+		metadata := map[string]interface{}{
+			"method":      c.Request.Method,
+			"path":        c.Request.URL.Path,
+			"route":       route,
+			"status":      writer.Status(),
+			"duration_ms": time.Since(start).Milliseconds(),
+		}
+		if c.Request.URL.RawQuery != "" {
+			metadata["query"] = redactQuery(c.Request.URL.RawQuery)
+		}
+		if userID := c.GetUint("user_id"); userID != 0 {
+			metadata["user_id"] = userID
+		}
+		if requestBody != nil {
+			metadata["request_body"] = redactBody(requestBody, c.ContentType())
+			metadata["request_body_truncated"] = requestTruncated
+		}
+		if writer.body.Len() > 0 {
+			metadata["response_body"] = redactBody(writer.body.Bytes(), responseContentType(writer))
+			metadata["response_body_truncated"] = writer.truncated
+		}
+		raw, err := json.Marshal(metadata)
+		if err != nil {
+			log.Printf("Request log: failed to encode %s %s: %v", c.Request.Method, route, err)
+			return
+		}
+
+		entry := &models.AuditLog{
+			Action:     RequestLogAction,
+			EntityType: RequestLogEntityType,
+			EntityID:   c.Request.Method + " " + route,
+			Metadata:   models.JSON(raw),
+			IPAddress:  c.ClientIP(),
+			CreatedAt:  start,
+		}
+		if err := store.Create(context.WithoutCancel(c.Request.Context()), entry); err != nil {
+			log.Printf("Request log: failed to record %s %s: %v", c.Request.Method, route, err)
+		}
+	}
+}
+
+// captureRequestBody đọc tối đa limit byte đầu của body rồi trả lại nguyên body cho handler; body
+// multipart trả về nil
+func captureRequestBody(c *gin.Context, limit int) ([]byte, bool) {
+	if c.Request.Body == nil || c.Request.ContentLength == 0 || strings.HasPrefix(c.ContentType(), "multipart/") {
+		return nil, false
+	}
+	head, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(limit)+1))
+	c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(head), c.Request.Body), c.Request.Body}
+	if err != nil || len(head) == 0 {
+		return nil, false
+	}
+	if len(head) > limit {
+		return head[:limit], true
+	}
+	return head, false
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyLogWriter giữ lại tối đa limit byte đầu của response
+type bodyLogWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (w *bodyLogWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyLogWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyLogWriter) capture(b []byte) {
+	remaining := w.limit - w.body.Len()
+	if len(b) > remaining {
+		b = b[:max(remaining, 0)]
+		w.truncated = true
+	}
+	w.body.Write(b)
+}
+
+func responseContentType(w gin.ResponseWriter) string {
+	contentType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	return contentType
+}
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys là các phần của tên trường chứa bí mật; so khớp không phân biệt hoa thường
+var sensitiveKeys = []string{"password", "passwd", "token", "secret", "authorization", "api_key", "apikey", "cvv", "card_number", "otp"}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactBody trả về body đã che: JSON được giữ dạng JSON với giá trị của trường nhạy cảm và email được
+// che, form được che theo tên tham số, nội dung khác được che email và lưu dạng chuỗi
+func redactBody(body []byte, contentType string) interface{} {
+	var value interface{}
+	if json.Unmarshal(body, &value) == nil {
+		return redactValue(value)
+	}
+	if contentType == "application/x-www-form-urlencoded" {
+		return redactQuery(string(body))
+	}
+	return emailPattern.ReplaceAllString(string(body), redacted)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if isSensitiveKey(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(item)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+		return v
+	case string:
+		return emailPattern.ReplaceAllString(v, redacted)
+	}
+	return value
+}
+
+// redactQuery che giá trị của tham số nhạy cảm và email trong query string hoặc form
+func redactQuery(raw string) string {
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return emailPattern.ReplaceAllString(raw, redacted)
+	}
+	for key, items := range values {
+		for i, item := range items {
+			if isSensitiveKey(key) {
+				items[i] = redacted
+			} else {
+				items[i] = emailPattern.ReplaceAllString(item, redacted)
+			}
+		}
+	}
+	query, _ := url.QueryUnescape(values.Encode())
+	return query
+}
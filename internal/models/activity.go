@@ -0,0 +1,33 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Nhóm của một mục trong lịch sử hoạt động của user
+const (
+	ActivityAuth          = "auth"           // đăng nhập
+	ActivityUser          = "user"           // đăng ký, lời mời, đổi mật khẩu, xóa/khôi phục tài khoản
+	ActivityPaymentMethod = "payment_method" // thêm/xóa phương thức thanh toán
+	ActivityOrder         = "order"          // hóa đơn được phát hành hoặc bị hủy
+)
+
+// ActivityEntry là một mục trong lịch sử hoạt động của user, gộp từ audit_logs, invoices và users
+type ActivityEntry struct {
+	Category   string          `json:"category"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Details    json.RawMessage `json:"details" gorm:"-"`
+	RawDetails string          `json:"-" gorm:"column:details"`
+	IPAddress  string          `json:"ip_address,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// ActivityQueryParams là các tham số của GET /admin/users/:id/activity
+type ActivityQueryParams struct {
+	Type  string `form:"type" binding:"omitempty,oneof=auth user payment_method order"`
+	Page  int    `form:"page"`
+	Limit int    `form:"limit" binding:"omitempty,max=100"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	// Đăng ký serializer "encrypted" cho các cột được mã hóa
+	_ "github.com/NgTruong624/project_backend/internal/fieldcrypt"
+)
+
+// MaxAddressesPerUser giới hạn số địa chỉ trong sổ địa chỉ của một user
+const MaxAddressesPerUser = 20
+
+// AddressDetails là thông tin giao hàng của một địa chỉ; được dùng cho sổ địa chỉ và được
+// chụp lại trên đơn hàng lúc đặt để việc sửa/xóa địa chỉ sau đó không đổi lịch sử đơn.
+// Tên người nhận, số điện thoại và số nhà được mã hóa trong database (fieldcrypt) nên không thể lọc
+// hay sắp xếp theo các cột này; tỉnh/huyện/xã giữ dạng thường cho thuế, phí giao hàng và thống kê.
+type AddressDetails struct {
+	RecipientName string `json:"recipient_name" gorm:"type:text;serializer:encrypted"`
+	Phone         string `json:"phone" gorm:"type:text;serializer:encrypted"`
+	Province      string `json:"province" gorm:"size:100"`                     // tỉnh/thành phố
+	District      string `json:"district" gorm:"size:100"`                     // quận/huyện
+	Ward          string `json:"ward" gorm:"size:100"`                         // phường/xã
+	Street        string `json:"street" gorm:"type:text;serializer:encrypted"` // số nhà, tên đường
+}
+
+// Address là một địa chỉ giao hàng trong sổ địa chỉ của user.
+// Mỗi user có tối đa một địa chỉ mặc định.
+type Address struct {
+	ID     uint `json:"id" gorm:"primaryKey"`
+	UserID uint `json:"-" gorm:"not null;index;uniqueIndex:idx_addresses_default,where:is_default"`
+	AddressDetails
+	IsDefault bool      `json:"is_default" gorm:"not null;default:false"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AddressRequest là cấu trúc request khi thêm hoặc sửa địa chỉ
+type AddressRequest struct {
+	RecipientName string `json:"recipient_name" binding:"required,max=100"`
+	Phone         string `json:"phone" binding:"required,phone"`
+	Province      string `json:"province" binding:"required,max=100"`
+	District      string `json:"district" binding:"required,max=100"`
+	Ward          string `json:"ward" binding:"required,max=100"`
+	Street        string `json:"street" binding:"required,max=255"`
+	IsDefault     bool   `json:"is_default"`
+}
+
+// Details trả về thông tin địa chỉ của request
+func (r *AddressRequest) Details() AddressDetails {
+	return AddressDetails{
+		RecipientName: r.RecipientName,
+		Phone:         r.Phone,
+		Province:      r.Province,
+		District:      r.District,
+		Ward:          r.Ward,
+		Street:        r.Street,
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditLog ghi lại một hành động quan trọng trong hệ thống.
+// Bảng audit_logs được phân vùng theo tháng trên cột created_at.
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     *uint     `json:"user_id"`
+	Action     string    `json:"action" gorm:"not null"`
+	EntityType string    `json:"entity_type"`
+	EntityID   string    `json:"entity_id"`
+	Metadata   JSON      `json:"metadata"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at" gorm:"primaryKey"`
+}
+
+// AuditLogQueryParams là cấu trúc cho các tham số tìm kiếm audit log; khoảng ngày (YYYY-MM-DD, bao gồm
+// cả hai đầu) mặc định là 30 ngày gần nhất
+type AuditLogQueryParams struct {
+	UserID     uint      `form:"user_id"`
+	Action     string    `form:"action"`
+	EntityType string    `form:"entity_type"`
+	EntityID   string    `form:"entity_id"`
+	StartDate  time.Time `form:"start_date" time_format:"2006-01-02"`
+	EndDate    time.Time `form:"end_date" time_format:"2006-01-02"`
+	Page       int       `form:"page"`
+	Limit      int       `form:"limit" binding:"max=100"`
+}
@@ -0,0 +1,175 @@
+package models
+
+import (
+	"time"
+)
+
+// Cách gộp số lượng khi cùng một sản phẩm có trong cả giỏ khách và giỏ của user
+const (
+	CartMergeSum = "sum" // cộng dồn số lượng
+	CartMergeMax = "max" // giữ số lượng lớn hơn
+)
+
+// Cart là giỏ hàng. Giỏ của user đã đăng nhập gắn với UserID; giỏ của khách vãng lai
+// được nhận diện bằng Token (gửi qua header X-Cart-Token).
+type Cart struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    *uint      `json:"user_id" gorm:"uniqueIndex"`
+	Token     *string    `json:"-" gorm:"uniqueIndex;size:64"`
+	Items     []CartItem `json:"items" gorm:"constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"index"`
+}
+
+// CartItem là một sản phẩm trong giỏ
+type CartItem struct {
+	ID        uint    `json:"id" gorm:"primaryKey"`
+	CartID    uint    `json:"cart_id" gorm:"not null;uniqueIndex:idx_cart_items_product,priority:1"`
+	ProductID uint    `json:"product_id" gorm:"not null;uniqueIndex:idx_cart_items_product,priority:2"`
+	Product   Product `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Quantity  int     `json:"quantity" gorm:"not null"`
+	// Price là giá sản phẩm lúc khách thêm vào giỏ (hoặc lần revalidate gần nhất), dùng để phát hiện đổi giá
+	Price     float64   `json:"price" gorm:"not null;default:0"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AddCartItemRequest là cấu trúc request khi thêm sản phẩm vào giỏ
+type AddCartItemRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"required,min=1"`
+}
+
+// UpdateCartItemRequest là cấu trúc request khi đổi số lượng một sản phẩm trong giỏ
+type UpdateCartItemRequest struct {
+	Quantity int `json:"quantity" binding:"required,min=1"`
+}
+
+// Tình trạng của một dòng khi kiểm tra tồn kho
+const (
+	StockAvailable    = "available"
+	StockInsufficient = "insufficient_stock" // còn hàng nhưng ít hơn số lượng yêu cầu
+	StockOutOfStock   = "out_of_stock"
+	StockUnavailable  = "unavailable" // sản phẩm không tồn tại hoặc không còn được bán
+)
+
+// StockCheckRequest là cấu trúc request khi kiểm tra tồn kho nhiều sản phẩm cùng lúc
+type StockCheckRequest struct {
+	Items []StockCheckItem `json:"items" binding:"required,min=1,max=100,unique=ProductID,dive"`
+}
+
+// StockCheckItem là một dòng cần kiểm tra tồn kho
+type StockCheckItem struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"required,min=1"`
+}
+
+// StockCheckLine là kết quả kiểm tra tồn kho của một dòng
+type StockCheckLine struct {
+	ProductID uint   `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+	Status    string `json:"status"`
+	// AvailableQuantity là số lượng mua được ngay, không vượt quá Quantity
+	AvailableQuantity int     `json:"available_quantity"`
+	UnitPrice         float64 `json:"unit_price,omitempty"`
+}
+
+// StockCheckResponse là kết quả kiểm tra tồn kho theo thứ tự các dòng trong request
+type StockCheckResponse struct {
+	Items []StockCheckLine `json:"items"`
+	// Available cho biết mọi dòng đều đủ hàng
+	Available bool `json:"available"`
+}
+
+// CartResponse là cấu trúc response khi trả về giỏ hàng
+type CartResponse struct {
+	ID            uint               `json:"id"`
+	Token         string             `json:"cart_token,omitempty"`
+	Items         []CartItemResponse `json:"items"`
+	TotalQuantity int                `json:"total_quantity"`
+	Subtotal      float64            `json:"subtotal"`
+	// Discounts là các khuyến mãi tự động đang áp dụng; Total = Subtotal - DiscountTotal (chưa gồm
+	// phí giao hàng và thuế cộng thêm, được tính lúc checkout)
+	Discounts     []AppliedPromotion `json:"discounts"`
+	DiscountTotal float64            `json:"discount_total"`
+	Total         float64            `json:"total"`
+	// HasChanges cho biết có dòng nào đổi giá hoặc thiếu hàng so với lúc thêm vào giỏ
+	HasChanges bool `json:"has_changes"`
+	// RemovedProductIDs là các sản phẩm bị bỏ khỏi giỏ khi revalidate vì đã hết hàng
+	RemovedProductIDs []uint    `json:"removed_product_ids,omitempty"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// CartItemResponse là một dòng trong CartResponse, kèm giá hiện tại của sản phẩm
+type CartItemResponse struct {
+	ProductID uint    `json:"product_id"`
+	Name      string  `json:"name"`
+	ImageURL  string  `json:"image_url"`
+	UnitPrice float64 `json:"unit_price"`
+	Quantity  int     `json:"quantity"`
+	Stock     int     `json:"stock"`
+	LineTotal float64 `json:"line_total"`
+	// Discount là phần giảm giá khuyến mãi phân bổ cho dòng
+	Discount float64 `json:"discount"`
+
+	// Cờ báo thay đổi kể từ lúc thêm vào giỏ
+	PriceChanged      bool    `json:"price_changed"`
+	PreviousPrice     float64 `json:"previous_price,omitempty"`
+	InsufficientStock bool    `json:"insufficient_stock"`
+}
+
+// CartMergeResult mô tả kết quả gộp giỏ khách vào giỏ của user khi đăng nhập
+type CartMergeResult struct {
+	Strategy    string       `json:"strategy"`
+	MergedItems int          `json:"merged_items"`
+	Adjusted    []uint       `json:"adjusted_product_ids,omitempty"` // sản phẩm bị giảm số lượng vì không đủ tồn kho
+	Cart        CartResponse `json:"cart"`
+}
+
+// Kết quả của một dòng đơn cũ khi đặt lại
+const (
+	ReorderAdded       = "added"       // thêm đủ số lượng đã đặt
+	ReorderReduced     = "reduced"     // thêm ít hơn số lượng đã đặt vì không đủ tồn kho
+	ReorderSubstituted = "substituted" // sản phẩm không còn bán hoặc hết hàng, đã thay bằng sản phẩm cùng danh mục
+	ReorderUnavailable = "unavailable" // sản phẩm không còn bán hoặc hết hàng và không có sản phẩm thay thế
+)
+
+// ReorderRequest là cấu trúc request (tùy chọn) khi đặt lại một đơn cũ
+type ReorderRequest struct {
+	// ReplaceCart xóa giỏ hiện tại trước khi thêm hàng; mặc định cộng vào giỏ đang có
+	ReplaceCart bool `json:"replace_cart"`
+	// NoSubstitutes bỏ qua sản phẩm không còn hàng thay vì thay bằng sản phẩm cùng danh mục
+	NoSubstitutes bool `json:"no_substitutes"`
+}
+
+// ReorderSubstitute là sản phẩm được thêm vào giỏ thay cho một dòng của đơn cũ
+type ReorderSubstitute struct {
+	ProductID uint    `json:"product_id"`
+	Name      string  `json:"name"`
+	UnitPrice float64 `json:"unit_price"`
+}
+
+// ReorderLine là kết quả đặt lại một dòng của đơn cũ so với giá và tồn kho hiện tại
+type ReorderLine struct {
+	ProductID       uint   `json:"product_id"`
+	Name            string `json:"name"`
+	Outcome         string `json:"outcome"`
+	OrderedQuantity int    `json:"ordered_quantity"`
+	// Quantity là số lượng đã thêm vào giỏ (của sản phẩm thay thế nếu có)
+	Quantity     int     `json:"quantity"`
+	OrderedPrice float64 `json:"ordered_price"`
+	// CurrentPrice là giá bán hiện tại; 0 nếu sản phẩm không còn bán
+	CurrentPrice float64            `json:"current_price"`
+	PriceChanged bool               `json:"price_changed"`
+	Substitute   *ReorderSubstitute `json:"substitute,omitempty"`
+}
+
+// ReorderResult là kết quả đặt lại đơn cũ: từng dòng của đơn và giỏ hàng sau khi thêm
+type ReorderResult struct {
+	OrderID uint          `json:"order_id"`
+	Lines   []ReorderLine `json:"lines"`
+	// HasChanges cho biết có dòng đổi giá, thiếu hàng, được thay thế hoặc không thêm được
+	HasChanges bool         `json:"has_changes"`
+	Cart       CartResponse `json:"cart"`
+}
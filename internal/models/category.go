@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Category là một nút của cây danh mục dùng cho menu điều hướng. Sản phẩm vẫn gắn danh mục theo tên
+// (products.category) nên Name phải trùng giá trị đó; danh mục có sản phẩm nhưng chưa khai báo được
+// hiển thị như danh mục gốc.
+type Category struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"size:100;not null;uniqueIndex"`
+	ParentID  *uint     `json:"parent_id" gorm:"index"`
+	Position  int       `json:"position" gorm:"not null;default:0"` // thứ tự giữa các danh mục cùng cha
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CategoryRequest là cấu trúc request khi admin tạo hoặc sửa danh mục
+type CategoryRequest struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	ParentID *uint  `json:"parent_id"`
+	Position int    `json:"position"`
+}
+
+// CategoryNode là một nút của cây danh mục trả về cho storefront. ProductCount đếm sản phẩm đã đăng
+// thuộc chính danh mục, TotalCount cộng thêm sản phẩm của mọi danh mục con cháu.
+type CategoryNode struct {
+	ID           uint           `json:"id,omitempty"` // 0 với danh mục chưa khai báo
+	Name         string         `json:"name"`
+	ProductCount int64          `json:"product_count"`
+	TotalCount   int64          `json:"total_count"`
+	Children     []CategoryNode `json:"children"`
+}
+
+// Breadcrumb là một danh mục trên đường dẫn từ gốc tới danh mục của sản phẩm
+type Breadcrumb struct {
+	ID   uint   `json:"id,omitempty"` // 0 với danh mục chưa khai báo
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Kiểu giá trị của thuộc tính sản phẩm; giá trị luôn lưu dạng chuỗi trong Specs
+const (
+	AttributeTypeString  = "string"
+	AttributeTypeNumber  = "number"
+	AttributeTypeBoolean = "boolean" // "true" hoặc "false"
+)
+
+// BrandAttributeKey là khóa thông số chứa thương hiệu của sản phẩm, dùng cho gợi ý tìm kiếm
+const BrandAttributeKey = "brand"
+
+// CategoryAttribute là một thuộc tính được khai báo cho danh mục. Specs của sản phẩm chỉ được dùng các
+// khóa đã khai báo cho danh mục của sản phẩm; Options (nếu có) giới hạn các giá trị hợp lệ.
+type CategoryAttribute struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Category  string    `json:"category" gorm:"not null;size:100;uniqueIndex:idx_category_attributes_key,priority:1"`
+	Key       string    `json:"key" gorm:"column:attr_key;not null;size:50;uniqueIndex:idx_category_attributes_key,priority:2"` // KEY là từ khóa của MySQL
+	Label     string    `json:"label" gorm:"size:100"`
+	Type      string    `json:"type" gorm:"size:20;not null;default:string"`
+	Options   []string  `json:"options" gorm:"serializer:json;type:text"`
+	Required  bool      `json:"required" gorm:"not null;default:false"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CategoryAttributeRequest là cấu trúc request khi tạo hoặc sửa thuộc tính của danh mục (Admin)
+type CategoryAttributeRequest struct {
+	Category string   `json:"category" binding:"required,max=100"`
+	Key      string   `json:"key" binding:"required,max=50"`
+	Label    string   `json:"label" binding:"max=100"`
+	Type     string   `json:"type" binding:"omitempty,oneof=string number boolean"`
+	Options  []string `json:"options" binding:"omitempty,dive,required,max=100"`
+	Required bool     `json:"required"`
+}
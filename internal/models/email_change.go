@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// EmailChange là một yêu cầu đổi email của user. Email chỉ được đổi sau khi cả địa chỉ cũ và
+// địa chỉ mới đã xác nhận bằng link được gửi tới; token trong link chỉ được lưu dưới dạng hash.
+// Mỗi user có tối đa một yêu cầu đang chờ, yêu cầu mới hủy yêu cầu cũ.
+type EmailChange struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	UserID         uint       `json:"-" gorm:"not null;index"`
+	OldEmail       string     `json:"old_email" gorm:"not null"`
+	NewEmail       string     `json:"new_email" gorm:"not null"`
+	OldTokenHash   string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	NewTokenHash   string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	OldConfirmedAt *time.Time `json:"old_confirmed_at"`
+	NewConfirmedAt *time.Time `json:"new_confirmed_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	CancelledAt    *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Pending cho biết yêu cầu còn chờ xác nhận tại thời điểm now
+func (c *EmailChange) Pending(now time.Time) bool {
+	return c.CompletedAt == nil && c.CancelledAt == nil && now.Before(c.ExpiresAt)
+}
+
+// EmailChangeRequest là cấu trúc request khi user yêu cầu đổi email (cần nhập lại mật khẩu)
+type EmailChangeRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email,max=255"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ConfirmEmailChangeRequest là cấu trúc request khi xác nhận đổi email bằng token trong link
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
@@ -0,0 +1,48 @@
+package models
+
+// InventorySyncRequest là cấu trúc request của PUT /integrations/inventory: tồn kho mới của các sản phẩm theo SKU
+type InventorySyncRequest struct {
+	Items []InventorySyncItem `json:"items" binding:"required,min=1,max=1000,unique=SKU,dive"`
+}
+
+// InventorySyncItem là tồn kho thực tế của một SKU tại kho bên ngoài
+type InventorySyncItem struct {
+	SKU   string `json:"sku" binding:"required,max=64"`
+	Stock *int   `json:"stock" binding:"required,min=0"`
+}
+
+// PriceSyncRequest là cấu trúc request của PUT /integrations/prices: giá gốc mới của các sản phẩm theo SKU
+type PriceSyncRequest struct {
+	Items []PriceSyncItem `json:"items" binding:"required,min=1,max=1000,unique=SKU,dive"`
+}
+
+// PriceSyncItem là giá gốc của một SKU từ hệ thống bên ngoài; lịch đổi giá (flash sale) đang áp dụng vẫn giữ
+type PriceSyncItem struct {
+	SKU   string   `json:"sku" binding:"required,max=64"`
+	Price *float64 `json:"price" binding:"required,min=0"`
+}
+
+// Kết quả đồng bộ của một dòng
+const (
+	SyncUpdated   = "updated"
+	SyncUnchanged = "unchanged" // giá trị gửi lên trùng giá trị hiện tại
+	SyncNotFound  = "not_found" // không có sản phẩm với SKU này
+	SyncFailed    = "failed"    // lô chứa dòng bị lỗi và đã được rollback
+)
+
+// SyncRowResult là kết quả đồng bộ một dòng của request, theo thứ tự items
+type SyncRowResult struct {
+	SKU       string `json:"sku"`
+	ProductID uint   `json:"product_id,omitempty"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SyncResponse là kết quả của một request đồng bộ tồn kho hoặc giá
+type SyncResponse struct {
+	Updated   int             `json:"updated"`
+	Unchanged int             `json:"unchanged"`
+	NotFound  int             `json:"not_found"`
+	Failed    int             `json:"failed"`
+	Results   []SyncRowResult `json:"results"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+)
+
+// Trạng thái của hóa đơn. Hóa đơn đã phát hành không được sửa, chỉ có thể hủy.
+const (
+	InvoiceDraft     = "draft"
+	InvoiceIssued    = "issued"
+	InvoiceCancelled = "cancelled"
+)
+
+// Invoice là hóa đơn bán hàng. Số hóa đơn chỉ được cấp khi phát hành, tăng liên tục
+// không bỏ số trong từng ký hiệu và kỳ (năm).
+type Invoice struct {
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	Series       string `json:"series" gorm:"uniqueIndex:idx_invoices_number,priority:1"` // ký hiệu hóa đơn, ví dụ 1C26TAA
+	FiscalPeriod int    `json:"fiscal_period" gorm:"uniqueIndex:idx_invoices_number,priority:2"`
+	Sequence     *int   `json:"sequence" gorm:"uniqueIndex:idx_invoices_number,priority:3"`
+	Number       string `json:"number" gorm:"index"` // số hóa đơn dạng 8 chữ số, ví dụ 00000012
+	Status       string `json:"status" gorm:"not null;default:'draft';index;index:idx_invoices_sales,priority:1"`
+
+	UserID       *uint  `json:"user_id" gorm:"index"`
+	BuyerName    string `json:"buyer_name" gorm:"not null"`
+	BuyerEmail   string `json:"buyer_email"`
+	BuyerTaxCode string `json:"buyer_tax_code"`
+	BuyerAddress string `json:"buyer_address"`
+
+	Currency  string  `json:"currency" gorm:"not null;default:'VND'"`
+	Subtotal  float64 `json:"subtotal" gorm:"not null"`
+	TaxRate   float64 `json:"tax_rate" gorm:"not null"` // phần trăm, ví dụ 10; 0 nếu các dòng có thuế suất khác nhau
+	TaxAmount float64 `json:"tax_amount" gorm:"not null"`
+	Total     float64 `json:"total" gorm:"not null"`
+
+	// ContentHash là SHA-256 của nội dung hóa đơn tại thời điểm phát hành
+	ContentHash  string        `json:"content_hash"`
+	IssuedAt     *time.Time    `json:"issued_at" gorm:"index:idx_invoices_sales,priority:2"`
+	CancelledAt  *time.Time    `json:"cancelled_at"`
+	CancelReason string        `json:"cancel_reason"`
+	Items        []InvoiceItem `json:"items" gorm:"constraint:OnDelete:CASCADE"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// InvoiceItem là một dòng hàng trên hóa đơn; tên và đơn giá được chụp lại tại thời điểm lập
+type InvoiceItem struct {
+	ID        uint    `json:"id" gorm:"primaryKey"`
+	InvoiceID uint    `json:"invoice_id" gorm:"not null;index"`
+	ProductID uint    `json:"product_id" gorm:"index"`
+	Name      string  `json:"name" gorm:"not null"`
+	Unit      string  `json:"unit"`
+	Quantity  int     `json:"quantity" gorm:"not null"`
+	UnitPrice float64 `json:"unit_price" gorm:"not null"`
+	Amount    float64 `json:"amount" gorm:"not null"`
+	// TaxRate/TaxAmount là thuế của dòng; hóa đơn lập trước khi có thuế theo dòng không có hai
+	// trường này và dùng thuế suất của hóa đơn (xem einvoice.LineRate)
+	TaxRate   float64 `json:"tax_rate,omitempty" gorm:"not null;default:0"`
+	TaxAmount float64 `json:"tax_amount,omitempty" gorm:"not null;default:0"`
+}
+
+// InvoiceSequence giữ số hóa đơn cuối cùng đã cấp cho mỗi ký hiệu và kỳ
+type InvoiceSequence struct {
+	Series       string `gorm:"primaryKey"`
+	FiscalPeriod int    `gorm:"primaryKey"`
+	LastNumber   int    `gorm:"not null;default:0"`
+}
+
+// CreateInvoiceRequest là cấu trúc request khi lập hóa đơn nháp
+type CreateInvoiceRequest struct {
+	UserID       *uint  `json:"user_id"`
+	BuyerName    string `json:"buyer_name" binding:"required"`
+	BuyerEmail   string `json:"buyer_email" binding:"omitempty,email"`
+	BuyerTaxCode string `json:"buyer_tax_code"`
+	BuyerAddress string `json:"buyer_address"`
+	// TaxRate áp một thuế suất cho mọi dòng; bỏ trống thì thuế suất lấy theo quy tắc thuế của danh mục
+	TaxRate *float64                   `json:"tax_rate" binding:"omitempty,min=0,max=100"`
+	Items   []CreateInvoiceItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// CreateInvoiceItemRequest là một dòng hàng trong request lập hóa đơn
+type CreateInvoiceItemRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"required,min=1"`
+}
+
+// CancelInvoiceRequest là cấu trúc request khi hủy hóa đơn đã phát hành
+type CancelInvoiceRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// InvoiceQueryParams là tham số lọc và phân trang danh sách hóa đơn
+type InvoiceQueryParams struct {
+	Status       string `form:"status"`
+	Series       string `form:"series"`
+	FiscalPeriod int    `form:"fiscal_period"`
+	UserID       uint   `form:"user_id"`
+	Page         int    `form:"page"`
+	Limit        int    `form:"limit" binding:"max=100"`
+}
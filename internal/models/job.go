@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// Trạng thái của một job nền
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// Job là một công việc nền được lưu trong bảng jobs và xử lý bởi worker
+type Job struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Type        string     `json:"type" gorm:"not null;index"`
+	Payload     JSON       `json:"payload" gorm:"not null"`
+	Status      string     `json:"status" gorm:"not null;default:'pending';index:idx_jobs_due,priority:1"`
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"`
+	MaxAttempts int        `json:"max_attempts" gorm:"not null;default:5"`
+	RunAt       time.Time  `json:"run_at" gorm:"index:idx_jobs_due,priority:2"`
+	LockedAt    *time.Time `json:"locked_at"`
+	LastError   string     `json:"last_error"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"index"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// JobQueryParams là cấu trúc cho các tham số lọc danh sách job
+type JobQueryParams struct {
+	Status string `form:"status"`
+	Type   string `form:"type"`
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit" binding:"max=100"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// JSON là văn bản JSON lưu trong cột kiểu jsonb trên Postgres, json trên MySQL và text trên SQLite
+type JSON string
+
+func (JSON) GormDataType() string {
+	return "json"
+}
+
+func (JSON) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return "jsonb"
+	case "mysql":
+		return "json"
+	default:
+		return "text"
+	}
+}
+
+// Specs là thông số kỹ thuật của sản phẩm (khóa thuộc tính -> giá trị), lưu dạng JSON object trong cột
+// cùng kiểu với JSON để lọc bằng truy vấn chứa (jsonb @> trên Postgres)
+type Specs map[string]string
+
+func (Specs) GormDataType() string {
+	return "json"
+}
+
+func (Specs) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return JSON("").GormDBDataType(db, field)
+}
+
+// Value ghi map rỗng (hoặc nil) thành "{}" để mọi dòng đều là JSON object
+func (s Specs) Value() (driver.Value, error) {
+	if s == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(map[string]string(s))
+	return string(data), err
+}
+
+func (s *Specs) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported specs value %T", value)
+	}
+	return json.Unmarshal(data, (*map[string]string)(s))
+}
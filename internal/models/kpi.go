@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// DailyKPI là số liệu tổng hợp theo ngày cho dashboard admin, được tính sẵn bởi scheduler
+type DailyKPI struct {
+	Day               time.Time `json:"day" gorm:"type:date;primaryKey"`
+	Orders            int64     `json:"orders" gorm:"not null;default:0"`
+	Revenue           float64   `json:"revenue" gorm:"not null;default:0"`
+	AverageOrderValue float64   `json:"average_order_value" gorm:"not null;default:0"`
+	NewCustomers      int64     `json:"new_customers" gorm:"not null;default:0"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// KPISummary là tổng của các ngày trong khoảng truy vấn
+type KPISummary struct {
+	From              string     `json:"from"`
+	To                string     `json:"to"`
+	Orders            int64      `json:"orders"`
+	Revenue           float64    `json:"revenue"`
+	AverageOrderValue float64    `json:"average_order_value"`
+	NewCustomers      int64      `json:"new_customers"`
+	Days              []DailyKPI `json:"days"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Lý do một lần đăng nhập bị coi là đáng ngờ
+const (
+	LoginReasonNewDevice  = "new_device"  // thiết bị (fingerprint) chưa từng đăng nhập thành công
+	LoginReasonNewCountry = "new_country" // quốc gia chưa từng đăng nhập thành công
+)
+
+// LoginEvent là một lần đăng nhập thành công của user cùng thiết bị và vị trí. Fingerprint là hash của
+// device_fingerprint client gửi lên, hoặc của User-Agent khi client không gửi. Country là mã quốc gia do
+// proxy/CDN gắn vào request (LOGIN_COUNTRY_HEADER), rỗng khi không biết. Bảng được dọn theo chính sách
+// retention login_events.
+type LoginEvent struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	UserID      uint   `json:"-" gorm:"not null;index:idx_login_events_user_fingerprint;index:idx_login_events_user_country"`
+	Fingerprint string `json:"-" gorm:"size:64;not null;index:idx_login_events_user_fingerprint"`
+	UserAgent   string `json:"user_agent" gorm:"size:512"`
+	IP          string `json:"ip" gorm:"size:45"`
+	Country     string `json:"country,omitempty" gorm:"size:2;index:idx_login_events_user_country"`
+	// Reasons là các lý do đáng ngờ (LoginReason*) cách nhau bởi dấu phẩy, rỗng với lần đăng nhập bình thường
+	Reasons string `json:"reasons,omitempty" gorm:"size:100"`
+	// ConfirmedByEmail cho biết lần đăng nhập đáng ngờ đã được user xác nhận bằng link gửi qua email
+	ConfirmedByEmail bool      `json:"confirmed_by_email" gorm:"not null;default:false"`
+	CreatedAt        time.Time `json:"created_at" gorm:"index"`
+}
+
+// LoginChallenge là một lần đăng nhập đáng ngờ đang chờ user xác nhận bằng link gửi qua email; token chỉ
+// được phát sau khi xác nhận. Token trong link chỉ được lưu dưới dạng hash và dùng được một lần.
+type LoginChallenge struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"-" gorm:"not null;index"`
+	TokenHash   string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	Fingerprint string     `json:"-" gorm:"size:64;not null"`
+	UserAgent   string     `json:"user_agent" gorm:"size:512"`
+	IP          string     `json:"ip" gorm:"size:45"`
+	Country     string     `json:"country,omitempty" gorm:"size:2"`
+	Reasons     string     `json:"reasons" gorm:"size:100"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Pending cho biết lần đăng nhập còn chờ xác nhận tại thời điểm now
+func (c *LoginChallenge) Pending(now time.Time) bool {
+	return c.ConfirmedAt == nil && now.Before(c.ExpiresAt)
+}
+
+// ConfirmLoginRequest là cấu trúc request khi xác nhận lần đăng nhập đáng ngờ bằng token trong link; cart_token
+// và auth_mode có ý nghĩa như khi đăng nhập
+type ConfirmLoginRequest struct {
+	Token     string `json:"token" binding:"required"`
+	CartToken string `json:"cart_token"`
+	AuthMode  string `json:"auth_mode" binding:"omitempty,oneof=token cookie"`
+}
@@ -0,0 +1,22 @@
+package models
+
+// Trạng thái kiểm duyệt nội dung do user gửi (câu hỏi, câu trả lời, đánh giá); chỉ nội dung đã duyệt
+// được hiển thị công khai
+const (
+	ModerationPending  = "pending"
+	ModerationApproved = "approved"
+	ModerationRejected = "rejected"
+)
+
+// ModerateRequest là cấu trúc request khi admin duyệt hoặc từ chối một nội dung
+type ModerateRequest struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected"`
+}
+
+// ModerationQueryParams là tham số lọc và phân trang hàng đợi kiểm duyệt (Admin)
+type ModerationQueryParams struct {
+	Status    string `form:"status" binding:"omitempty,oneof=pending approved rejected"`
+	ProductID uint   `form:"product_id"`
+	Page      int    `form:"page"`
+	Limit     int    `form:"limit" binding:"max=100"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Trạng thái đăng ký nhận bản tin
+const (
+	// NewsletterPending là địa chỉ đã đăng ký nhưng chưa bấm link xác nhận; chưa nhận bản tin
+	NewsletterPending      = "pending"
+	NewsletterConfirmed    = "confirmed"
+	NewsletterUnsubscribed = "unsubscribed"
+)
+
+// NewsletterSubscriber là một địa chỉ email đăng ký nhận bản tin theo cơ chế double opt-in: địa chỉ chỉ được
+// xuất cho hệ thống gửi bản tin sau khi chủ địa chỉ bấm link xác nhận. Token xác nhận chỉ được lưu dưới dạng
+// hash; UnsubscribeToken được lưu nguyên bản để đưa link hủy đăng ký vào mỗi bản tin.
+type NewsletterSubscriber struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	Email            string     `json:"email" gorm:"size:255;not null;uniqueIndex"`
+	Status           string     `json:"status" gorm:"size:20;not null;index"`
+	ConfirmTokenHash string     `json:"-" gorm:"size:64;index"`
+	ConfirmExpiresAt *time.Time `json:"-"`
+	ConfirmSentAt    *time.Time `json:"-"`
+	UnsubscribeToken string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	ConfirmedAt      *time.Time `json:"confirmed_at,omitempty"`
+	UnsubscribedAt   *time.Time `json:"unsubscribed_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// NewsletterSubscribeRequest là cấu trúc request khi đăng ký nhận bản tin
+type NewsletterSubscribeRequest struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+}
+
+// NewsletterTokenRequest là cấu trúc request khi xác nhận hoặc hủy đăng ký bằng token trong link
+type NewsletterTokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+)
+
+// Nhà cung cấp push notification
+const (
+	PushProviderFCM  = "fcm"
+	PushProviderAPNs = "apns"
+)
+
+// DeviceToken là token push của một thiết bị di động đã đăng ký cho user
+type DeviceToken struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"not null;index"`
+	Token      string    `json:"-" gorm:"not null;uniqueIndex"`
+	Platform   string    `json:"platform" gorm:"not null"` // android, ios
+	Provider   string    `json:"provider" gorm:"not null"` // fcm, apns
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// NotificationPreference lưu lựa chọn kênh nhận thông báo của user cho một loại sự kiện.
+// Không có dòng nào nghĩa là mọi kênh đều bật.
+type NotificationPreference struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_notification_pref_user_event"`
+	Event     string    `json:"event" gorm:"not null;uniqueIndex:idx_notification_pref_user_event"`
+	Email     bool      `json:"email" gorm:"not null;default:true"`
+	Push      bool      `json:"push" gorm:"not null;default:true"`
+	Webhook   bool      `json:"webhook" gorm:"not null;default:true"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RegisterDeviceRequest là cấu trúc request khi đăng ký thiết bị nhận push
+type RegisterDeviceRequest struct {
+	Token    string `json:"token" binding:"required,min=16,max=4096"`
+	Platform string `json:"platform" binding:"required,oneof=android ios"`
+	// Provider mặc định: fcm cho android, apns cho ios (app iOS dùng FCM có thể gửi "fcm")
+	Provider string `json:"provider" binding:"omitempty,oneof=fcm apns"`
+}
+
+// NotificationPreferenceUpdate đổi lựa chọn kênh cho một loại sự kiện; kênh không gửi (null) được giữ nguyên
+type NotificationPreferenceUpdate struct {
+	Event   string `json:"event" binding:"required"`
+	Email   *bool  `json:"email"`
+	Push    *bool  `json:"push"`
+	Webhook *bool  `json:"webhook"`
+}
+
+// UpdateNotificationPreferencesRequest là cấu trúc request khi đổi lựa chọn kênh nhận thông báo
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceUpdate `json:"preferences" binding:"required,min=1,unique=Event,dive"`
+}
@@ -0,0 +1,233 @@
+package models
+
+import (
+	"time"
+)
+
+// Trạng thái của đơn hàng
+const (
+	OrderStatusPendingPayment = "pending_payment" // đã đặt, chờ thanh toán
+	OrderStatusPaid           = "paid"
+	OrderStatusFulfilled      = "fulfilled" // đã đóng gói và giao cho đơn vị vận chuyển
+	OrderStatusDelivered      = "delivered"
+	OrderStatusCancelled      = "cancelled"
+	OrderStatusRefunded       = "refunded" // đã hoàn toàn bộ số tiền
+)
+
+// orderTransitions là các bước chuyển trạng thái hợp lệ của đơn hàng:
+// pending_payment → paid → fulfilled → delivered; đơn chưa thanh toán có thể bị hủy,
+// đơn đã thu tiền chuyển sang refunded khi được hoàn đủ
+var orderTransitions = map[string][]string{
+	OrderStatusPendingPayment: {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:           {OrderStatusFulfilled, OrderStatusRefunded},
+	OrderStatusFulfilled:      {OrderStatusDelivered, OrderStatusRefunded},
+	OrderStatusDelivered:      {OrderStatusRefunded},
+}
+
+// CanTransitionOrder kiểm tra đơn có được chuyển từ trạng thái from sang to hay không
+func CanTransitionOrder(from, to string) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanTransition kiểm tra đơn có được chuyển sang trạng thái to hay không. Ngoài các bước của
+// CanTransitionOrder, đơn COD được giao đi khi chưa thanh toán (tiền thu lúc nhận hàng).
+func (o *Order) CanTransition(to string) bool {
+	if o.PaymentType == OrderPaymentCOD && o.Status == OrderStatusPendingPayment && to == OrderStatusFulfilled {
+		return true
+	}
+	return CanTransitionOrder(o.Status, to)
+}
+
+// Cách khách thanh toán đơn hàng, chọn khi checkout
+const (
+	OrderPaymentOnline       = "online"        // qua cổng thanh toán (Stripe, VNPay, MoMo)
+	OrderPaymentCOD          = "cod"           // trả tiền mặt khi nhận hàng
+	OrderPaymentBankTransfer = "bank_transfer" // chuyển khoản, admin đối soát thủ công
+)
+
+// Order là đơn hàng được tạo từ giỏ hàng khi checkout. Tồn kho được giữ cho đơn từ lúc đặt
+// và được trả lại khi đơn bị hủy.
+type Order struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	UserID      uint   `json:"user_id" gorm:"not null;index"`
+	Status      string `json:"status" gorm:"not null;default:'pending_payment';index"`
+	PaymentType string `json:"payment_type" gorm:"not null;default:'online'"`
+	Currency    string `json:"currency" gorm:"not null;default:'VND'"`
+	// Subtotal là tổng thành tiền theo giá niêm yết, DiscountAmount là tổng giảm giá từ khuyến mãi.
+	// Thuế tính trên thành tiền sau giảm giá. Nếu PricesIncludeTax thì TaxAmount đã nằm trong
+	// Subtotal; nếu không, Total = Subtotal - DiscountAmount + TaxAmount + ShippingFee.
+	Subtotal         float64     `json:"subtotal" gorm:"not null"`
+	DiscountAmount   float64     `json:"discount_amount" gorm:"not null;default:0"`
+	TaxAmount        float64     `json:"tax_amount" gorm:"not null;default:0"`
+	PricesIncludeTax bool        `json:"prices_include_tax" gorm:"not null;default:false"`
+	Total            float64     `json:"total" gorm:"not null"`
+	Note             string      `json:"note"`
+	Items            []OrderItem `json:"items" gorm:"constraint:OnDelete:CASCADE"`
+	// Discounts là các khuyến mãi đã áp dụng lúc đặt
+	Discounts []OrderDiscount `json:"discounts,omitempty" gorm:"constraint:OnDelete:CASCADE"`
+	// ShippingAddress là bản chụp địa chỉ giao hàng lúc đặt; ShippingAddressID trỏ về sổ địa chỉ
+	// và có thể không còn tồn tại nếu user đã xóa địa chỉ
+	ShippingAddressID *uint          `json:"shipping_address_id"`
+	ShippingAddress   AddressDetails `json:"shipping_address" gorm:"embedded;embeddedPrefix:shipping_"`
+	// ShippingProvider/ShippingService là cách giao hàng khách chọn; ShippingFee đã gồm trong Total
+	ShippingProvider string  `json:"shipping_provider"`
+	ShippingService  string  `json:"shipping_service"`
+	ShippingFee      float64 `json:"shipping_fee" gorm:"not null;default:0"`
+	// Carrier và TrackingNumber được admin nhập khi giao đơn cho đơn vị vận chuyển
+	Carrier        string     `json:"carrier,omitempty"`
+	TrackingNumber string     `json:"tracking_number,omitempty"`
+	PaidAt         *time.Time `json:"paid_at"`
+	FulfilledAt    *time.Time `json:"fulfilled_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CancelledAt    *time.Time `json:"cancelled_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// OrderStatusHistory ghi lại một lần đổi trạng thái của đơn. FromStatus rỗng là lúc đơn được tạo;
+// ActorID là user/admin thực hiện, nil khi do hệ thống (ví dụ webhook của cổng thanh toán).
+type OrderStatusHistory struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	OrderID    uint      `json:"order_id" gorm:"not null;index:idx_order_status_histories_order,priority:1"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status" gorm:"not null"`
+	ActorID    *uint     `json:"actor_id"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index:idx_order_status_histories_order,priority:2"`
+}
+
+// OrderItem là một dòng hàng của đơn; tên và đơn giá được chụp lại tại thời điểm đặt
+type OrderItem struct {
+	ID        uint    `json:"id" gorm:"primaryKey"`
+	OrderID   uint    `json:"order_id" gorm:"not null;index"`
+	ProductID uint    `json:"product_id" gorm:"index"`
+	Name      string  `json:"name" gorm:"not null"`
+	Quantity  int     `json:"quantity" gorm:"not null"`
+	UnitPrice float64 `json:"unit_price" gorm:"not null"`
+	Amount    float64 `json:"amount" gorm:"not null"`
+	// DiscountAmount là phần giảm giá khuyến mãi phân bổ cho dòng, chưa trừ trong Amount
+	DiscountAmount float64 `json:"discount_amount" gorm:"not null;default:0"`
+	TaxRate        float64 `json:"tax_rate" gorm:"not null;default:0"` // phần trăm
+	TaxAmount      float64 `json:"tax_amount" gorm:"not null;default:0"`
+}
+
+// CheckoutRequest là cấu trúc request khi đặt hàng từ giỏ của user
+type CheckoutRequest struct {
+	// AddressID là địa chỉ giao hàng trong sổ địa chỉ của user
+	AddressID uint `json:"address_id" binding:"required"`
+	// ShippingOption là ID option từ POST /checkout/shipping-quote; rỗng thì chọn option rẻ nhất
+	ShippingOption string `json:"shipping_option" binding:"max=50"`
+	// PaymentType mặc định là online
+	PaymentType string `json:"payment_type" binding:"omitempty,oneof=online cod bank_transfer"`
+	Note        string `json:"note" binding:"max=500"`
+}
+
+// OrderTransitionRequest là cấu trúc request khi admin chuyển trạng thái đơn (giao hàng, đã nhận, hủy)
+type OrderTransitionRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+// FulfillOrderRequest là cấu trúc request khi admin giao đơn cho đơn vị vận chuyển
+type FulfillOrderRequest struct {
+	Carrier        string `json:"carrier" binding:"max=50"`
+	TrackingNumber string `json:"tracking_number" binding:"max=100"`
+	Note           string `json:"note" binding:"max=500"`
+}
+
+// MarkPaidRequest là cấu trúc request khi admin xác nhận đã thu tiền (COD, chuyển khoản)
+type MarkPaidRequest struct {
+	// Reference là mã đối soát, ví dụ mã giao dịch chuyển khoản hoặc mã phiếu thu COD
+	Reference string `json:"reference" binding:"max=100"`
+	Note      string `json:"note" binding:"max=500"`
+}
+
+// OrderQueryParams là tham số lọc và phân trang danh sách đơn hàng của user
+type OrderQueryParams struct {
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit" binding:"max=100"`
+	Status string `form:"status" binding:"omitempty,oneof=pending_payment paid fulfilled delivered cancelled refunded"`
+}
+
+// AdminOrderQueryParams là tham số lọc, sắp xếp và phân trang danh sách đơn hàng của admin
+type AdminOrderQueryParams struct {
+	Page  int `form:"page"`
+	Limit int `form:"limit" binding:"max=100"`
+
+	Status string `form:"status" binding:"omitempty,oneof=pending_payment paid fulfilled delivered cancelled refunded"`
+	UserID uint   `form:"user_id"`
+	// Customer tìm khách theo username, email hoặc họ tên (không phân biệt hoa thường)
+	Customer string `form:"customer"`
+
+	// Lọc theo ngày đặt (YYYY-MM-DD, bao gồm cả hai đầu)
+	StartDate time.Time `form:"start_date" time_format:"2006-01-02"`
+	EndDate   time.Time `form:"end_date" time_format:"2006-01-02"`
+
+	// Lọc theo tổng tiền của đơn (bao gồm cả hai đầu)
+	MinTotal float64 `form:"min_total" binding:"min=0"`
+	MaxTotal float64 `form:"max_total" binding:"min=0"`
+
+	SortBy string `form:"sort_by" binding:"omitempty,oneof=created_at total"`
+	Order  string `form:"order" binding:"omitempty,oneof=asc desc"`
+}
+
+// OrderCustomer là thông tin khách đặt đơn hiển thị cho admin
+type OrderCustomer struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+}
+
+// AdminOrder là đơn hàng trong API quản trị, kèm khách đặt đơn (nil nếu tài khoản không còn tồn tại)
+type AdminOrder struct {
+	Order
+	Customer *OrderCustomer `json:"customer"`
+}
+
+// OrderShipment là thông tin giao hàng của đơn: cách giao khách chọn và vận đơn admin nhập khi giao đi
+type OrderShipment struct {
+	Provider       string         `json:"provider"`
+	Service        string         `json:"service"`
+	Fee            float64        `json:"fee"`
+	Address        AddressDetails `json:"address"`
+	Carrier        string         `json:"carrier,omitempty"`
+	TrackingNumber string         `json:"tracking_number,omitempty"`
+	FulfilledAt    *time.Time     `json:"fulfilled_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+}
+
+// AdminOrderDetail là chi tiết đơn hàng cho admin: đơn, khách, các giao dịch thanh toán (kèm hoàn tiền)
+// và thông tin giao hàng
+type AdminOrderDetail struct {
+	AdminOrder
+	Payments []Payment     `json:"payments"`
+	Shipment OrderShipment `json:"shipment"`
+}
+
+// BulkOrderStatusRequest là cấu trúc request khi admin chuyển trạng thái nhiều đơn cùng lúc
+type BulkOrderStatusRequest struct {
+	OrderIDs []uint `json:"order_ids" binding:"required,min=1,max=100,dive,gt=0"`
+	Status   string `json:"status" binding:"required,oneof=fulfilled delivered cancelled"`
+	Note     string `json:"note" binding:"max=500"`
+}
+
+// BulkOrderStatusResult là kết quả chuyển trạng thái của một đơn trong request hàng loạt.
+// Status là trạng thái của đơn sau thao tác; Error là lý do khi đơn không được chuyển.
+type BulkOrderStatusResult struct {
+	OrderID uint   `json:"order_id"`
+	Updated bool   `json:"updated"`
+	Status  string `json:"status,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkOrderStatusResponse là kết quả chuyển trạng thái hàng loạt, theo thứ tự order_ids của request
+type BulkOrderStatusResponse struct {
+	Updated int                     `json:"updated"`
+	Failed  int                     `json:"failed"`
+	Results []BulkOrderStatusResult `json:"results"`
+}
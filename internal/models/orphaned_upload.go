@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// OrphanedUpload là file trong thư mục upload không còn được tham chiếu, được ghi lại lần đầu phát hiện để
+// chỉ bị xóa khi đã mồ côi quá thời gian ân hạn (UPLOAD_GC_GRACE_PERIOD)
+type OrphanedUpload struct {
+	// Path là đường dẫn tương đối trong thư mục upload, phân tách bằng '/' (ví dụ thumbs/12_1700000000.jpg)
+	Path        string    `json:"path" gorm:"primaryKey;size:255"`
+	Size        int64     `json:"size"`
+	FirstSeenAt time.Time `json:"first_seen_at" gorm:"not null"`
+}
+
+// UploadGCFile là một file mồ côi trong báo cáo dọn upload
+type UploadGCFile struct {
+	Path        string    `json:"path"`
+	Size        int64     `json:"size"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	DeleteAfter time.Time `json:"delete_after"`
+	// Due là true nếu file đã quá thời gian ân hạn (bị xóa ở lần chạy này, hoặc sẽ bị xóa nếu là dry run)
+	Due bool `json:"due"`
+}
+
+// UploadGCReport là kết quả một lần dọn upload. Với dry run không file nào bị xóa và không có gì được ghi
+// lại; Deleted và FreedBytes là số file và dung lượng sẽ được xóa.
+type UploadGCReport struct {
+	DryRun      bool   `json:"dry_run"`
+	GracePeriod string `json:"grace_period"`
+	Scanned     int    `json:"scanned"`
+	Referenced  int    `json:"referenced"`
+	Orphaned    int    `json:"orphaned"`
+	Deleted     int    `json:"deleted"`
+	FreedBytes  int64  `json:"freed_bytes"`
+	// Files là các file mồ côi (tối đa MaxUploadGCReportFiles), file đến hạn xóa trước
+	Files []UploadGCFile `json:"files"`
+}
+
+// MaxUploadGCReportFiles giới hạn số file được liệt kê trong báo cáo dọn upload
+const MaxUploadGCReportFiles = 500
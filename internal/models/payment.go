@@ -0,0 +1,125 @@
+package models
+
+import (
+	"time"
+)
+
+// PaymentCustomer ánh xạ user sang customer tại nhà cung cấp thanh toán
+type PaymentCustomer struct {
+	UserID     uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Provider   string    `json:"provider" gorm:"primaryKey;size:20"`
+	CustomerID string    `json:"-" gorm:"not null;size:100"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// PaymentMethod là phương thức thanh toán đã lưu của user. Chỉ lưu ID token hóa của provider
+// và thông tin hiển thị (hãng thẻ, 4 số cuối, hạn dùng), không lưu số thẻ.
+// Mỗi user có tối đa một phương thức mặc định, dùng khi thanh toán đơn hàng và gia hạn định kỳ.
+type PaymentMethod struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	UserID           uint      `json:"-" gorm:"not null;index;uniqueIndex:idx_payment_methods_default,where:is_default"`
+	Provider         string    `json:"provider" gorm:"not null;size:20"`
+	ProviderMethodID string    `json:"-" gorm:"not null;uniqueIndex;size:100"`
+	Type             string    `json:"type" gorm:"size:20"`
+	Brand            string    `json:"brand" gorm:"size:20"`
+	Last4            string    `json:"last4" gorm:"size:4"`
+	ExpMonth         int       `json:"exp_month"`
+	ExpYear          int       `json:"exp_year"`
+	IsDefault        bool      `json:"is_default" gorm:"not null;default:false"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// AddPaymentMethodRequest là cấu trúc request khi lưu phương thức thanh toán.
+// Token là ID phương thức do SDK phía client của provider tạo (ví dụ pm_... của Stripe).
+type AddPaymentMethodRequest struct {
+	Token      string `json:"token" binding:"required,max=100"`
+	SetDefault bool   `json:"set_default"`
+}
+
+// Trạng thái của một giao dịch thanh toán
+const (
+	PaymentStatusPending           = "pending"
+	PaymentStatusSucceeded         = "succeeded"
+	PaymentStatusFailed            = "failed"
+	PaymentStatusPartiallyRefunded = "partially_refunded"
+	PaymentStatusRefunded          = "refunded"
+)
+
+// Provider của Payment được admin ghi nhận thủ công (không qua cổng thanh toán)
+const (
+	PaymentProviderCOD          = "cod"
+	PaymentProviderBankTransfer = "bank_transfer"
+	PaymentProviderManual       = "manual" // đơn thanh toán online nhưng được đối soát thủ công
+)
+
+// IsManualPaymentProvider kiểm tra Payment có được ghi nhận thủ công hay không;
+// hoàn tiền của các Payment này cũng được thực hiện ngoài hệ thống và chỉ được ghi lại
+func IsManualPaymentProvider(provider string) bool {
+	switch provider {
+	case PaymentProviderCOD, PaymentProviderBankTransfer, PaymentProviderManual:
+		return true
+	}
+	return false
+}
+
+// Payment là một lần thu tiền cho đơn hàng qua cổng thanh toán. Một đơn có thể có nhiều
+// Payment (ví dụ khách thử lại sau khi thẻ bị từ chối) nhưng chỉ một giao dịch thành công.
+type Payment struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	OrderID        uint       `json:"order_id" gorm:"not null;index"`
+	Provider       string     `json:"provider" gorm:"not null;size:20;uniqueIndex:idx_payments_reference,priority:1"`
+	Reference      *string    `json:"reference" gorm:"size:100;uniqueIndex:idx_payments_reference,priority:2"` // mã đối chiếu với gateway (payment intent của Stripe, vnp_TxnRef của VNPay, orderId của MoMo)
+	TransactionID  string     `json:"transaction_id,omitempty" gorm:"size:100"`                                // mã giao dịch gateway báo về (VNPay, MoMo), cần khi hoàn tiền
+	Amount         float64    `json:"amount" gorm:"not null"`
+	Currency       string     `json:"currency" gorm:"not null"`
+	Status         string     `json:"status" gorm:"not null;default:'pending'"`
+	RefundedAmount float64    `json:"refunded_amount" gorm:"not null;default:0"`
+	FailureReason  string     `json:"failure_reason,omitempty"`
+	PaidAt         *time.Time `json:"paid_at"`
+	RecordedBy     *uint      `json:"recorded_by,omitempty"` // admin ghi nhận Payment thủ công
+	Note           string     `json:"note,omitempty"`
+	Refunds        []Refund   `json:"refunds,omitempty" gorm:"constraint:OnDelete:CASCADE"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Refund là một lần hoàn tiền (toàn bộ hoặc một phần) của Payment
+type Refund struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	PaymentID uint      `json:"payment_id" gorm:"not null;index"`
+	Reference string    `json:"reference" gorm:"size:100"`
+	Amount    float64   `json:"amount" gorm:"not null"`
+	Reason    string    `json:"reason"`
+	Status    string    `json:"status" gorm:"not null"`
+	AdminID   *uint     `json:"admin_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PayOrderRequest là cấu trúc request khi thanh toán đơn hàng. PaymentMethodID (tùy chọn) là
+// phương thức đã lưu; nếu bỏ trống, client hoàn tất thanh toán bằng client_secret trả về.
+type PayOrderRequest struct {
+	Provider        string `json:"provider" binding:"required"`
+	PaymentMethodID uint   `json:"payment_method_id"`
+}
+
+// PaymentReturnResponse là kết quả khi khách được cổng thanh toán chuyển về sau khi thanh toán
+type PaymentReturnResponse struct {
+	Payment     *Payment `json:"payment"`
+	OrderID     uint     `json:"order_id"`
+	OrderStatus string   `json:"order_status"`
+}
+
+// PaymentIntentResponse là kết quả tạo giao dịch thanh toán cho đơn hàng
+type PaymentIntentResponse struct {
+	Payment      *Payment `json:"payment"`
+	Status       string   `json:"status"`
+	ClientSecret string   `json:"client_secret,omitempty"`
+	RedirectURL  string   `json:"redirect_url,omitempty"`
+}
+
+// RefundRequest là cấu trúc request khi hoàn tiền đơn hàng; bỏ trống amount để hoàn phần còn lại
+type RefundRequest struct {
+	Amount float64 `json:"amount" binding:"omitempty,gt=0"`
+	Reason string  `json:"reason" binding:"max=500"`
+}
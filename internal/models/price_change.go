@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Trạng thái của lịch đổi giá
+const (
+	PriceChangeScheduled = "scheduled"
+	PriceChangeActive    = "active"
+	PriceChangeEnded     = "ended"
+	PriceChangeCancelled = "cancelled"
+)
+
+// ScheduledPriceChange là lịch đổi giá có thời hạn (flash sale) của sản phẩm: trong [StartsAt, EndsAt)
+// sản phẩm được bán với Price thay cho giá gốc. Tác vụ price_change_apply áp dụng lịch khi tới StartsAt
+// (ghi products.sale_price) và hoàn lại giá gốc khi tới EndsAt. Các lịch chưa kết thúc của một sản phẩm
+// không được chồng thời gian lên nhau.
+type ScheduledPriceChange struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	ProductID  uint       `json:"product_id" gorm:"not null;index"`
+	Product    Product    `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Price      float64    `json:"price" gorm:"not null"`
+	StartsAt   time.Time  `json:"starts_at" gorm:"not null;index"`
+	EndsAt     time.Time  `json:"ends_at" gorm:"not null;index"`
+	Status     string     `json:"status" gorm:"size:20;not null;default:scheduled;index"`
+	CreatedBy  uint       `json:"-"`
+	AppliedAt  *time.Time `json:"applied_at,omitempty"`
+	RevertedAt *time.Time `json:"reverted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CreatePriceChangeRequest là cấu trúc request khi admin lên lịch đổi giá cho sản phẩm
+type CreatePriceChangeRequest struct {
+	Price    float64   `json:"price" binding:"required,gt=0"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required,gtfield=StartsAt"`
+}
@@ -4,48 +4,139 @@ import (
 	"time"
 )
 
+// Trạng thái vòng đời của sản phẩm. Chỉ sản phẩm published được hiển thị trong danh sách công khai và
+// được bán; draft chỉ admin thấy (có thể hẹn giờ đăng bằng PublishAt), archived là hàng ngừng kinh doanh
+// vẫn giữ lại trang chi tiết cho đơn hàng và đánh giá cũ.
+const (
+	ProductStatusDraft     = "draft"
+	ProductStatusPublished = "published"
+	ProductStatusArchived  = "archived"
+)
+
 type Product struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	Name        string    `json:"name" gorm:"not null;unique"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price" gorm:"not null"`
-	Stock       int       `json:"stock" gorm:"not null"`
-	ImageURL    string    `json:"image_url"`
-	Category    string    `json:"category"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID   uint   `json:"id" gorm:"primaryKey;index:idx_products_created,priority:2;index:idx_products_category_created,priority:3"`
+	Name string `json:"name" gorm:"not null;unique"`
+	// SKU là mã hàng do admin đặt (không bắt buộc, duy nhất khi có)
+	SKU         *string `json:"sku,omitempty" gorm:"size:64;uniqueIndex"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price" gorm:"not null;index"`
+	// SalePrice là giá của lịch đổi giá đang áp dụng (ScheduledPriceChange), nil khi bán theo giá gốc
+	SalePrice *float64 `json:"-"`
+	Stock     int      `json:"stock" gorm:"not null;index"`
+	ImageURL  string   `json:"image_url"`
+	Category  string   `json:"category" gorm:"index:idx_products_category_created,priority:1"`
+	// Specs là thông số kỹ thuật theo các thuộc tính khai báo cho danh mục (CategoryAttribute)
+	Specs  Specs  `json:"specs"`
+	Status string `json:"status" gorm:"size:20;not null;default:published;index"`
+	// PublishAt là thời điểm tác vụ product_publish tự đăng sản phẩm draft
+	PublishAt *time.Time `json:"publish_at,omitempty" gorm:"index"`
+	// WeightGrams là khối lượng đóng gói (gram), dùng để tính phí vận chuyển
+	WeightGrams int `json:"weight_grams" gorm:"not null;default:0"`
+	// Version tăng sau mỗi lần ghi, dùng cho optimistic locking (ETag / If-Match)
+	Version int `json:"version" gorm:"not null;default:1"`
+	// (created_at, id) phục vụ thứ tự mặc định của danh sách, kể cả khi lọc theo danh mục
+	CreatedAt time.Time `json:"created_at" gorm:"index:idx_products_created,priority:1;index:idx_products_category_created,priority:2"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EffectivePrice là giá bán hiện tại: giá của lịch đổi giá đang áp dụng nếu có, ngược lại là giá gốc
+func (p Product) EffectivePrice() float64 {
+	if p.SalePrice != nil {
+		return *p.SalePrice
+	}
+	return p.Price
 }
 
 // ProductResponse là cấu trúc response khi trả về thông tin sản phẩm
 type ProductResponse struct {
-	ID          uint      `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	Stock       int       `json:"stock"`
-	ImageURL    string    `json:"image_url"`
-	Category    string    `json:"category"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	SKU         *string    `json:"sku,omitempty"`
+	Description string     `json:"description"`
+	Price       float64    `json:"price"`
+	Stock       int        `json:"stock"`
+	ImageURL    string     `json:"image_url"`
+	Category    string     `json:"category"`
+	Specs       Specs      `json:"specs,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	PublishAt   *time.Time `json:"publish_at,omitempty"`
+	WeightGrams int        `json:"weight_grams,omitempty"`
+	Version     int        `json:"version,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	// EffectivePrice là giá bán hiện tại (giá flash sale đang áp dụng hoặc giá gốc)
+	EffectivePrice float64 `json:"effective_price"`
+
+	// Dữ liệu SEO, chỉ có trong API chi tiết sản phẩm
+	Slug         string       `json:"slug,omitempty"`
+	CanonicalURL string       `json:"canonical_url,omitempty"`
+	Breadcrumbs  []Breadcrumb `json:"breadcrumbs,omitempty"`
+	// Included chứa các quan hệ được mở rộng bằng ?include= (reviews, category)
+	Included map[string]interface{} `json:"included,omitempty"`
+
+	// Các trường tổng hợp từ read model, chỉ có trong API danh sách
+	RatingAvg   float64 `json:"rating_avg,omitempty"`
+	RatingCount int64   `json:"rating_count,omitempty"`
 }
 
 // CreateProductRequest là cấu trúc request khi tạo sản phẩm mới
 type CreateProductRequest struct {
 	Name        string  `json:"name" binding:"required"`
+	SKU         string  `json:"sku" binding:"max=64"`
 	Description string  `json:"description"`
 	Price       float64 `json:"price" binding:"required,min=0"`
 	Stock       int     `json:"stock" binding:"required,min=0"`
-	ImageURL    string  `json:"image_url"`
+	ImageURL    string  `json:"image_url" binding:"max=500"`
 	Category    string  `json:"category"`
+	Specs       Specs   `json:"specs"`
+	WeightGrams int     `json:"weight_grams" binding:"min=0"`
+	// Status mặc định là published; PublishAt (trong tương lai) tạo sản phẩm draft được hẹn giờ đăng
+	Status    string     `json:"status" binding:"omitempty,oneof=draft published archived"`
+	PublishAt *time.Time `json:"publish_at"`
 }
 
-// UpdateProductRequest là cấu trúc request khi cập nhật sản phẩm
+// UpdateProductRequest là cấu trúc request khi cập nhật sản phẩm (PUT/PATCH).
+// Các trường là con trỏ: trường không gửi (hoặc null) được giữ nguyên, còn giá trị 0 hoặc
+// chuỗi rỗng được ghi thật, ví dụ đặt tồn kho về 0 hoặc xóa ảnh bằng "image_url": "".
+// Specs nil được giữ nguyên, object rỗng {} xóa mọi thông số; SKU "" xóa mã hàng. Đổi Status hủy lịch đăng trừ khi gửi kèm PublishAt.
+// Version là version client đã đọc; bắt buộc nếu không gửi header If-Match.
 type UpdateProductRequest struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"min=0"`
-	Stock       int     `json:"stock" binding:"min=0"`
-	ImageURL    string  `json:"image_url"`
-	Category    string  `json:"category"`
+	Version     *int       `json:"version,omitempty" binding:"omitempty,min=1"`
+	Name        *string    `json:"name" binding:"omitempty,min=1"`
+	SKU         *string    `json:"sku" binding:"omitempty,max=64"`
+	Description *string    `json:"description"`
+	Price       *float64   `json:"price" binding:"omitempty,min=0"`
+	Stock       *int       `json:"stock" binding:"omitempty,min=0"`
+	ImageURL    *string    `json:"image_url" binding:"omitempty,max=500"`
+	Category    *string    `json:"category"`
+	Specs       Specs      `json:"specs"`
+	WeightGrams *int       `json:"weight_grams" binding:"omitempty,min=0"`
+	Status      *string    `json:"status" binding:"omitempty,oneof=draft published archived"`
+	PublishAt   *time.Time `json:"publish_at"`
+}
+
+// DuplicateProductRequest là cấu trúc request khi nhân bản sản phẩm; CopyImages sao chép file ảnh trong
+// storage thay vì dùng chung ảnh với sản phẩm gốc
+type DuplicateProductRequest struct {
+	CopyImages bool `json:"copy_images"`
+}
+
+// SuggestQueryParams là tham số của API gợi ý tìm kiếm; Limit là số gợi ý tối đa cho mỗi nhóm
+type SuggestQueryParams struct {
+	Q     string `form:"q" binding:"required,min=2,max=100"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=10"`
+}
+
+// ProductSuggestion là một sản phẩm trong gợi ý tìm kiếm
+type ProductSuggestion struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// SearchSuggestions là kết quả gợi ý tìm kiếm: tên sản phẩm, danh mục và thương hiệu khớp với từ khóa
+type SearchSuggestions struct {
+	Products   []ProductSuggestion `json:"products"`
+	Categories []string            `json:"categories"`
+	Brands     []string            `json:"brands"`
 }
 
 // ProductQueryParams là cấu trúc cho các tham số tìm kiếm và phân trang
@@ -53,6 +144,11 @@ type ProductQueryParams struct {
 	// Tìm kiếm cơ bản
 	Search   string `form:"search"`
 	Category string `form:"category"`
+	// SearchTerms là Search cùng các cách viết đồng nghĩa lấy từ từ điển search_synonyms
+	SearchTerms []string `form:"-"`
+	// SimilarityThreshold > 0 chuyển sang tìm gần đúng: tên sản phẩm có word_similarity với Search từ
+	// ngưỡng này trở lên, gần nhất trước (chỉ Postgres có pg_trgm)
+	SimilarityThreshold float64 `form:"-"`
 
 	// Tìm kiếm theo giá
 	MinPrice float64 `form:"min_price"`
@@ -61,6 +157,12 @@ type ProductQueryParams struct {
 	// Tìm kiếm theo tồn kho
 	InStock bool `form:"in_stock"`
 
+	// Status lọc theo trạng thái; danh sách công khai luôn chỉ lấy sản phẩm published
+	Status string `form:"status" binding:"omitempty,oneof=draft published archived"`
+
+	// Attrs lọc theo thông số kỹ thuật (?attr[ram]=16GB), mọi cặp đều phải khớp
+	Attrs map[string]string `form:"-"`
+
 	// Tìm kiếm theo thời gian
 	StartDate time.Time `form:"start_date"`
 	EndDate   time.Time `form:"end_date"`
@@ -72,4 +174,23 @@ type ProductQueryParams struct {
 	// Phân trang
 	Page  int `form:"page"`
 	Limit int `form:"limit" binding:"max=100"`
+
+	// Count là cách tính tổng số sản phẩm: exact (mặc định), estimate hoặc none
+	Count string `form:"count" binding:"omitempty,oneof=exact estimate none"`
+}
+
+// Các chế độ đếm tổng số dòng của danh sách
+const (
+	// CountExact đếm chính xác số dòng khớp bộ lọc
+	CountExact = "exact"
+	// CountEstimate dùng thống kê của Postgres (reltuples) khi không có bộ lọc, ngược lại đếm chính xác
+	CountEstimate = "estimate"
+	// CountNone không đếm, chỉ cho biết còn trang sau hay không
+	CountNone = "none"
+)
+
+// Filtered cho biết truy vấn có điều kiện lọc nào không
+func (q *ProductQueryParams) Filtered() bool {
+	return q.Search != "" || q.Category != "" || q.MinPrice > 0 || q.MaxPrice > 0 || q.InStock ||
+		!q.StartDate.IsZero() || !q.EndDate.IsZero() || len(q.Attrs) > 0
 }
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Loại theo dõi sản phẩm
+const (
+	// ProductAlertBackInStock báo khi sản phẩm hết hàng có hàng trở lại
+	ProductAlertBackInStock = "back_in_stock"
+	// ProductAlertPriceDrop báo khi giá bán giảm xuống bằng hoặc dưới TargetPrice
+	ProductAlertPriceDrop = "price_drop"
+)
+
+// MaxProductAlertsPerUser giới hạn số sản phẩm một user theo dõi cùng lúc
+const MaxProductAlertsPerUser = 100
+
+// ProductAlert là đăng ký nhận thông báo của user cho một sản phẩm. Alert chỉ báo một lần: khi điều kiện
+// thỏa, TriggeredAt được ghi và alert không còn được xét; alert hết hạn sau ExpiresAt và bị tác vụ
+// product_alert_sweep xóa. Mỗi user có tối đa một alert cho mỗi (sản phẩm, loại).
+type ProductAlert struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	UserID    uint   `json:"user_id" gorm:"not null;uniqueIndex:idx_product_alerts_user_product_type"`
+	ProductID uint   `json:"product_id" gorm:"not null;uniqueIndex:idx_product_alerts_user_product_type;index"`
+	Type      string `json:"type" gorm:"size:20;not null;uniqueIndex:idx_product_alerts_user_product_type"`
+	// TargetPrice là ngưỡng giá của alert price_drop
+	TargetPrice *float64   `json:"target_price,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null;index"`
+	TriggeredAt *time.Time `json:"triggered_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	Product *Product `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+}
+
+// CreateProductAlertRequest là cấu trúc request khi theo dõi sản phẩm
+type CreateProductAlertRequest struct {
+	Type        string   `json:"type" binding:"required,oneof=back_in_stock price_drop"`
+	TargetPrice *float64 `json:"target_price" binding:"required_if=Type price_drop,omitempty,gt=0"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Tên các feed sản phẩm cho kênh quảng cáo mua sắm
+const (
+	// ProductFeedGoogle là feed RSS 2.0 theo đặc tả dữ liệu sản phẩm của Google Merchant Center
+	ProductFeedGoogle = "google"
+	// ProductFeedFacebook là feed CSV cho danh mục sản phẩm (catalog) của Facebook/Meta Commerce Manager
+	ProductFeedFacebook = "facebook"
+)
+
+// ProductFeed là một feed sản phẩm đã dựng sẵn, được tác vụ định kỳ product_feed_generate tạo lại từ các
+// sản phẩm đã đăng
+type ProductFeed struct {
+	Name        string    `gorm:"primaryKey;size:50"`
+	ContentType string    `gorm:"size:100;not null"`
+	Content     []byte    `gorm:"not null"`
+	ItemCount   int       `gorm:"not null;default:0"`
+	GeneratedAt time.Time `gorm:"not null"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// ProductListing là read model phi chuẩn hóa dùng cho API danh sách sản phẩm.
+// Bảng được dựng lại từ products (và các bảng liên quan) mỗi khi sản phẩm thay đổi,
+// để GetProducts chỉ cần một truy vấn đơn giản trên các cột đã đánh index.
+type ProductListing struct {
+	ProductID      uint      `json:"product_id" gorm:"primaryKey;autoIncrement:false;index:idx_product_listings_created,priority:2;index:idx_product_listings_category_created,priority:3"`
+	Name           string    `json:"name" gorm:"not null"`
+	Description    string    `json:"description"`
+	Price          float64   `json:"price" gorm:"not null"`
+	EffectivePrice float64   `json:"effective_price" gorm:"not null;index"`
+	Stock          int       `json:"stock" gorm:"not null;index"`
+	ImageURL       string    `json:"image_url"`
+	Category       string    `json:"category" gorm:"index:idx_product_listings_category_created,priority:1"`
+	CategoryPath   string    `json:"category_path"`
+	Specs          Specs     `json:"specs"`
+	Status         string    `json:"status" gorm:"size:20;not null;default:published;index"`
+	RatingAvg      float64   `json:"rating_avg"`
+	RatingCount    int64     `json:"rating_count"`
+	CreatedAt      time.Time `json:"created_at" gorm:"index:idx_product_listings_created,priority:1;index:idx_product_listings_category_created,priority:2"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	RefreshedAt    time.Time `json:"refreshed_at"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Loại media của sản phẩm
+const (
+	ProductMediaImage = "image"
+	ProductMediaVideo = "video"
+)
+
+// Nguồn của media: file upload lên server hoặc video nhúng từ YouTube/Vimeo
+const (
+	MediaSourceUpload  = "upload"
+	MediaSourceYouTube = "youtube"
+	MediaSourceVimeo   = "vimeo"
+)
+
+// Trạng thái xử lý của video; chỉ video ready được trả về cho khách
+const (
+	MediaStatusProcessing = "processing"
+	MediaStatusReady      = "ready"
+	MediaStatusFailed     = "failed"
+)
+
+// MaxProductVideos là số video tối đa của một sản phẩm
+const MaxProductVideos = 10
+
+// ProductMedia là một video của sản phẩm. Video upload được job product.media kiểm tra và chuyển mã sang
+// MP4 (H.264/AAC) kèm ảnh poster lấy từ một khung hình; video YouTube/Vimeo được kiểm tra qua oEmbed và
+// dùng thumbnail của nền tảng làm poster. Ảnh chính của sản phẩm (Product.ImageURL) không được lưu ở đây
+// mà được đưa vào đầu danh sách media với ID 0.
+type ProductMedia struct {
+	ID        uint    `json:"id,omitempty" gorm:"primaryKey"`
+	ProductID uint    `json:"product_id" gorm:"not null;index"`
+	Product   Product `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Type      string  `json:"type" gorm:"size:20;not null"`
+	Source    string  `json:"source" gorm:"size:20;not null"`
+	// URL là đường dẫn file (/static/uploads/videos/...) hoặc URL trang xem video trên YouTube/Vimeo
+	URL             string    `json:"url" gorm:"size:500;not null"`
+	EmbedURL        string    `json:"embed_url,omitempty" gorm:"size:500"`
+	PosterURL       string    `json:"poster_url,omitempty" gorm:"size:500"`
+	Title           string    `json:"title,omitempty" gorm:"size:255"`
+	DurationSeconds float64   `json:"duration_seconds,omitempty"`
+	Width           int       `json:"width,omitempty"`
+	Height          int       `json:"height,omitempty"`
+	Status          string    `json:"status" gorm:"size:20;not null;default:processing;index"`
+	Error           string    `json:"error,omitempty" gorm:"size:500"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// AddExternalVideoRequest là cấu trúc request khi admin gắn video YouTube/Vimeo vào sản phẩm
+type AddExternalVideoRequest struct {
+	URL   string `json:"url" binding:"required,url,max=500"`
+	Title string `json:"title" binding:"max=255"`
+}
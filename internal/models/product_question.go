@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Vai trò của người trả lời câu hỏi
+const (
+	AnswerAuthorAdmin         = "admin"
+	AnswerAuthorVerifiedBuyer = "verified_buyer" // user đã mua sản phẩm (đơn đã thanh toán)
+)
+
+// ProductQuestion là câu hỏi của user về một sản phẩm. Câu hỏi của khách chờ admin duyệt trước khi
+// hiển thị; câu hỏi do admin đăng được duyệt ngay.
+type ProductQuestion struct {
+	ID          uint            `json:"id" gorm:"primaryKey"`
+	ProductID   uint            `json:"product_id" gorm:"not null;index"`
+	Product     Product         `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	UserID      uint            `json:"-" gorm:"not null;index"`
+	Body        string          `json:"body" gorm:"type:text;not null"`
+	Status      string          `json:"status" gorm:"size:20;not null;default:pending;index"`
+	ModeratedBy *uint           `json:"-"`
+	ModeratedAt *time.Time      `json:"moderated_at,omitempty"`
+	Answers     []ProductAnswer `json:"answers" gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// ProductAnswer là câu trả lời của admin hoặc của user đã mua sản phẩm. Câu trả lời của admin được
+// duyệt ngay, của người mua chờ admin duyệt.
+type ProductAnswer struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	QuestionID  uint       `json:"question_id" gorm:"not null;index"`
+	UserID      uint       `json:"-" gorm:"not null;index"`
+	AuthorRole  string     `json:"author_role" gorm:"size:20;not null"`
+	Body        string     `json:"body" gorm:"type:text;not null"`
+	Status      string     `json:"status" gorm:"size:20;not null;default:pending;index"`
+	ModeratedBy *uint      `json:"-"`
+	ModeratedAt *time.Time `json:"moderated_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// CreateQuestionRequest là cấu trúc request khi user đặt câu hỏi về sản phẩm
+type CreateQuestionRequest struct {
+	Body string `json:"body" binding:"required,min=5,max=1000"`
+}
+
+// CreateAnswerRequest là cấu trúc request khi trả lời một câu hỏi
+type CreateAnswerRequest struct {
+	Body string `json:"body" binding:"required,min=2,max=2000"`
+}
+
+// QuestionQueryParams là tham số phân trang danh sách câu hỏi đã duyệt của sản phẩm
+type QuestionQueryParams struct {
+	Page  int `form:"page"`
+	Limit int `form:"limit" binding:"max=50"`
+}
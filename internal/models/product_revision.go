@@ -0,0 +1,85 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RoleEditor là vai trò biên tập viên danh mục: chỉ gửi thay đổi sản phẩm dưới dạng revision chờ admin duyệt
+const RoleEditor = "editor"
+
+// Trạng thái revision sản phẩm
+const (
+	RevisionStatusPending   = "pending"
+	RevisionStatusApproved  = "approved" // thay đổi đã được áp dụng vào sản phẩm
+	RevisionStatusRejected  = "rejected"
+	RevisionStatusCancelled = "cancelled" // người gửi rút lại
+)
+
+// ProductRevision là thay đổi sản phẩm chờ duyệt. Changes là UpdateProductRequest đã gửi (chỉ các trường có
+// trong request); Original là giá trị của các trường đó lúc gửi, dùng để dựng diff và phát hiện xung đột khi
+// sản phẩm bị sửa trước lúc duyệt.
+type ProductRevision struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	ProductID   uint       `json:"product_id" gorm:"not null;index"`
+	Product     *Product   `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	BaseVersion int        `json:"base_version" gorm:"not null"`
+	Changes     JSON       `json:"-" gorm:"not null"`
+	Original    JSON       `json:"-" gorm:"not null"`
+	Note        string     `json:"note,omitempty" gorm:"type:text"`
+	Status      string     `json:"status" gorm:"size:20;not null;default:pending;index"`
+	SubmittedBy uint       `json:"submitted_by" gorm:"not null;index"`
+	ReviewedBy  *uint      `json:"reviewed_by,omitempty"`
+	ReviewNote  string     `json:"review_note,omitempty" gorm:"type:text"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	// AppliedVersion là version của sản phẩm sau khi revision được áp dụng
+	AppliedVersion *int      `json:"applied_version,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ProductFieldChange là một dòng diff của revision: From là giá trị lúc gửi, To là giá trị đề xuất và Current
+// là giá trị hiện tại của sản phẩm; Conflict nghĩa là trường đã bị sửa sau khi gửi (Current khác From)
+type ProductFieldChange struct {
+	Field    string          `json:"field"`
+	From     json.RawMessage `json:"from"`
+	To       json.RawMessage `json:"to"`
+	Current  json.RawMessage `json:"current"`
+	Conflict bool            `json:"conflict"`
+}
+
+// ProductRevisionView là revision kèm tên sản phẩm và diff so với sản phẩm hiện tại. Stale nghĩa là sản
+// phẩm đã đổi version từ lúc gửi; revision vẫn duyệt được nếu không trường nào Conflict.
+type ProductRevisionView struct {
+	ProductRevision
+	ProductName string               `json:"product_name"`
+	Stale       bool                 `json:"stale"`
+	Diff        []ProductFieldChange `json:"diff"`
+}
+
+// SubmitProductRevisionRequest là cấu trúc request khi gửi thay đổi sản phẩm chờ duyệt: các trường sản phẩm
+// giống PUT/PATCH /products/:id (version bắt buộc nếu không gửi header If-Match) cùng ghi chú cho người duyệt
+type SubmitProductRevisionRequest struct {
+	UpdateProductRequest
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// ApproveProductRevisionRequest là cấu trúc request khi admin duyệt revision
+type ApproveProductRevisionRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// RejectProductRevisionRequest là cấu trúc request khi admin từ chối revision
+type RejectProductRevisionRequest struct {
+	Note string `json:"note" binding:"required,max=1000"`
+}
+
+// ProductRevisionQueryParams là tham số lọc và phân trang danh sách revision
+type ProductRevisionQueryParams struct {
+	Status    string `form:"status" binding:"omitempty,oneof=pending approved rejected cancelled"`
+	ProductID uint   `form:"product_id"`
+	// SubmittedBy lọc theo người gửi; biên tập viên luôn chỉ thấy revision của mình
+	SubmittedBy uint `form:"submitted_by"`
+	Page        int  `form:"page"`
+	Limit       int  `form:"limit" binding:"max=100"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Thao tác tạo ra một bản lưu lịch sử sản phẩm
+const (
+	SnapshotCreated   = "created"
+	SnapshotUpdated   = "updated"
+	SnapshotPublished = "published" // tác vụ product_publish đăng sản phẩm đã hẹn giờ
+	SnapshotRestored  = "restored"
+)
+
+// ProductSnapshot là trạng thái đầy đủ của sản phẩm sau một lần ghi, lưu tự động mỗi khi sản phẩm được tạo
+// hoặc sửa qua ProductService; Version là version của sản phẩm sau lần ghi đó. AuthorID rỗng với thay đổi
+// của tác vụ nền.
+type ProductSnapshot struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ProductID uint      `json:"product_id" gorm:"not null;uniqueIndex:idx_product_snapshots_version,priority:1"`
+	Product   *Product  `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Version   int       `json:"version" gorm:"not null;uniqueIndex:idx_product_snapshots_version,priority:2"`
+	Action    string    `json:"action" gorm:"size:20;not null"`
+	AuthorID  *uint     `json:"author_id,omitempty"`
+	Data      JSON      `json:"-" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ProductSnapshotView là bản lưu kèm nội dung sản phẩm và các trường đã đổi so với bản lưu liền trước
+// (rỗng với bản lưu cũ nhất còn giữ)
+type ProductSnapshotView struct {
+	ProductSnapshot
+	Snapshot      json.RawMessage `json:"snapshot"`
+	ChangedFields []string        `json:"changed_fields"`
+}
+
+// RestoreProductSnapshotRequest là cấu trúc request khi khôi phục sản phẩm về một bản lưu; Version là
+// version hiện tại client đã đọc, bắt buộc nếu không gửi header If-Match
+type RestoreProductSnapshotRequest struct {
+	Version *int `json:"version,omitempty" binding:"omitempty,min=1"`
+}
+
+// ProductSnapshotQueryParams là tham số phân trang lịch sử sản phẩm
+type ProductSnapshotQueryParams struct {
+	Page  int `form:"page"`
+	Limit int `form:"limit" binding:"max=100"`
+}
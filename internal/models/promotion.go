@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// Các loại khuyến mãi tự động
+const (
+	PromotionBuyXGetY        = "buy_x_get_y"      // mua BuyQuantity được tặng GetQuantity (giảm Percent, mặc định 100%)
+	PromotionCategoryPercent = "category_percent" // giảm Percent cho sản phẩm thuộc Category
+	PromotionCartThreshold   = "cart_threshold"   // giỏ đạt MinSubtotal được giảm Percent hoặc AmountOff
+)
+
+// Promotion là một quy tắc khuyến mãi được áp dụng tự động khi tính giá giỏ hàng, không cần mã.
+// StartsAt/EndsAt (tùy chọn) giới hạn thời gian hiệu lực. Khuyến mãi Stackable được cộng dồn theo
+// Priority giảm dần; khuyến mãi không Stackable chỉ được áp dụng riêng lẻ và chỉ khi có lợi hơn cho
+// khách so với tổng các khuyến mãi cộng dồn.
+type Promotion struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Name        string     `json:"name" gorm:"not null"`
+	Description string     `json:"description"`
+	Type        string     `json:"type" gorm:"size:30;not null"`
+	Active      bool       `json:"active" gorm:"not null;index"`
+	StartsAt    *time.Time `json:"starts_at,omitempty"`
+	EndsAt      *time.Time `json:"ends_at,omitempty"`
+	Priority    int        `json:"priority" gorm:"not null;default:0"`
+	Stackable   bool       `json:"stackable" gorm:"not null"`
+	// Phạm vi sản phẩm: Category và/hoặc ProductID; bỏ trống cả hai là mọi sản phẩm (trừ category_percent)
+	Category  string `json:"category,omitempty" gorm:"size:100"`
+	ProductID *uint  `json:"product_id,omitempty"`
+	// Tham số theo loại khuyến mãi
+	BuyQuantity int       `json:"buy_quantity,omitempty"`
+	GetQuantity int       `json:"get_quantity,omitempty"`
+	Percent     float64   `json:"percent,omitempty"`
+	MinSubtotal float64   `json:"min_subtotal,omitempty"`
+	AmountOff   float64   `json:"amount_off,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ActiveAt cho biết khuyến mãi có hiệu lực tại thời điểm t
+func (p *Promotion) ActiveAt(t time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && t.Before(*p.StartsAt) {
+		return false
+	}
+	return p.EndsAt == nil || t.Before(*p.EndsAt)
+}
+
+// PromotionRequest là cấu trúc request khi tạo/sửa khuyến mãi; các tham số bắt buộc tùy theo type
+type PromotionRequest struct {
+	Name        string     `json:"name" binding:"required,max=200"`
+	Description string     `json:"description" binding:"max=1000"`
+	Type        string     `json:"type" binding:"required,oneof=buy_x_get_y category_percent cart_threshold"`
+	Active      *bool      `json:"active"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	Priority    int        `json:"priority"`
+	Stackable   *bool      `json:"stackable"`
+	Category    string     `json:"category" binding:"max=100"`
+	ProductID   *uint      `json:"product_id"`
+	BuyQuantity int        `json:"buy_quantity" binding:"min=0"`
+	GetQuantity int        `json:"get_quantity" binding:"min=0"`
+	Percent     float64    `json:"percent" binding:"min=0,max=100"`
+	MinSubtotal float64    `json:"min_subtotal" binding:"min=0"`
+	AmountOff   float64    `json:"amount_off" binding:"min=0"`
+}
+
+// PromotionQueryParams là tham số lọc danh sách khuyến mãi (Admin)
+type PromotionQueryParams struct {
+	Active *bool  `form:"active"`
+	Type   string `form:"type" binding:"omitempty,oneof=buy_x_get_y category_percent cart_threshold"`
+}
+
+// AppliedPromotion là một khuyến mãi đã áp dụng cho giỏ hàng cùng số tiền được giảm
+type AppliedPromotion struct {
+	PromotionID uint    `json:"promotion_id"`
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Amount      float64 `json:"amount"`
+}
+
+// OrderDiscount lưu khuyến mãi đã áp dụng cho đơn hàng lúc đặt; Name được chụp lại để đơn cũ
+// vẫn hiển thị đúng khi khuyến mãi bị sửa hoặc xóa
+type OrderDiscount struct {
+	ID          uint    `json:"id" gorm:"primaryKey"`
+	OrderID     uint    `json:"order_id" gorm:"not null;index"`
+	PromotionID uint    `json:"promotion_id" gorm:"not null;index"`
+	Name        string  `json:"name" gorm:"not null"`
+	Type        string  `json:"type" gorm:"size:30;not null"`
+	Amount      float64 `json:"amount" gorm:"not null"`
+}
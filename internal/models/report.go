@@ -0,0 +1,64 @@
+package models
+
+// Cách nhóm thời gian của báo cáo doanh số
+const (
+	ReportGroupDay   = "day"
+	ReportGroupWeek  = "week"
+	ReportGroupMonth = "month"
+)
+
+// SalesReportQuery là các tham số của GET /admin/reports/sales (from/to được đọc riêng)
+type SalesReportQuery struct {
+	GroupBy string `form:"group_by" binding:"omitempty,oneof=day week month"`
+	// Limit giới hạn số dòng trong bảng theo sản phẩm (mặc định 50)
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=1000"`
+	Format string `form:"format" binding:"omitempty,oneof=json csv"`
+	// Section chọn bảng xuất ra CSV: periods (mặc định), categories hoặc products
+	Section string `form:"section" binding:"omitempty,oneof=periods categories products"`
+}
+
+// SalesTotals là số liệu doanh số của một nhóm (danh mục hoặc sản phẩm); NetSales là doanh thu chưa thuế
+type SalesTotals struct {
+	Invoices int64   `json:"invoices"`
+	Units    int64   `json:"units"`
+	NetSales float64 `json:"net_sales"`
+}
+
+// InvoiceTotals là doanh số kèm thuế, tính được khi nhóm theo hóa đơn (theo kỳ hoặc cả khoảng ngày).
+// GrossSales là tổng thanh toán gồm thuế.
+type InvoiceTotals struct {
+	SalesTotals
+	Tax        float64 `json:"tax"`
+	GrossSales float64 `json:"gross_sales"`
+}
+
+// SalesPeriod là doanh số của một ngày/tuần/tháng; PeriodStart là ngày đầu kỳ (tuần bắt đầu từ thứ Hai)
+type SalesPeriod struct {
+	PeriodStart string `json:"period_start"`
+	InvoiceTotals
+}
+
+// CategorySales là doanh số theo danh mục sản phẩm
+type CategorySales struct {
+	Category string `json:"category"`
+	SalesTotals
+}
+
+// ProductSales là doanh số theo sản phẩm
+type ProductSales struct {
+	ProductID uint   `json:"product_id"`
+	Name      string `json:"name"`
+	Category  string `json:"category"`
+	SalesTotals
+}
+
+// SalesReport là kết quả của báo cáo doanh số
+type SalesReport struct {
+	From       string          `json:"from"`
+	To         string          `json:"to"`
+	GroupBy    string          `json:"group_by"`
+	Totals     InvoiceTotals   `json:"totals"`
+	Periods    []SalesPeriod   `json:"periods"`
+	Categories []CategorySales `json:"categories"`
+	Products   []ProductSales  `json:"products"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// Trạng thái yêu cầu trả hàng
+const (
+	ReturnStatusRequested = "requested" // khách đã gửi yêu cầu, chờ admin xử lý
+	ReturnStatusApproved  = "approved"  // admin đã duyệt: hàng được nhập lại kho và tiền được hoàn
+	ReturnStatusRejected  = "rejected"
+	ReturnStatusCancelled = "cancelled" // khách rút lại yêu cầu
+)
+
+// MaxReturnPhotos là số ảnh tối đa đính kèm một yêu cầu trả hàng
+const MaxReturnPhotos = 5
+
+// OrderReturn là yêu cầu trả hàng của khách cho một phần hoặc toàn bộ đơn đã giao.
+// Amount là số tiền hoàn dự kiến tính từ giá (kèm thuế) lúc đặt hàng, không gồm phí vận chuyển;
+// RefundedAmount là số tiền admin thực hoàn khi duyệt.
+type OrderReturn struct {
+	ID             uint               `json:"id" gorm:"primaryKey"`
+	OrderID        uint               `json:"order_id" gorm:"not null;index"`
+	UserID         uint               `json:"user_id" gorm:"not null;index"`
+	Status         string             `json:"status" gorm:"size:20;not null;default:requested;index"`
+	Reason         string             `json:"reason" gorm:"type:text;not null"`
+	Amount         float64            `json:"amount" gorm:"not null"`
+	RefundedAmount float64            `json:"refunded_amount" gorm:"not null;default:0"`
+	RefundID       *uint              `json:"refund_id,omitempty"`
+	AdminNote      string             `json:"admin_note,omitempty"`
+	ResolvedBy     *uint              `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time         `json:"resolved_at,omitempty"`
+	Items          []OrderReturnItem  `json:"items" gorm:"foreignKey:ReturnID;constraint:OnDelete:CASCADE"`
+	Photos         []OrderReturnPhoto `json:"photos" gorm:"foreignKey:ReturnID;constraint:OnDelete:CASCADE"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+// OrderReturnItem là một dòng hàng được trả; Name được chụp lại từ dòng của đơn
+type OrderReturnItem struct {
+	ID          uint    `json:"id" gorm:"primaryKey"`
+	ReturnID    uint    `json:"return_id" gorm:"not null;index"`
+	OrderItemID uint    `json:"order_item_id" gorm:"not null;index"`
+	ProductID   uint    `json:"product_id" gorm:"not null"`
+	Name        string  `json:"name" gorm:"not null"`
+	Quantity    int     `json:"quantity" gorm:"not null"`
+	Amount      float64 `json:"amount" gorm:"not null"`
+}
+
+// OrderReturnPhoto là ảnh khách đính kèm yêu cầu trả hàng
+type OrderReturnPhoto struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ReturnID  uint      `json:"return_id" gorm:"not null;index"`
+	URL       string    `json:"url" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateReturnRequest là cấu trúc request khi khách yêu cầu trả hàng
+type CreateReturnRequest struct {
+	Reason string              `json:"reason" binding:"required,max=1000"`
+	Items  []ReturnItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// ReturnItemRequest là một dòng của đơn cùng số lượng muốn trả
+type ReturnItemRequest struct {
+	OrderItemID uint `json:"order_item_id" binding:"required"`
+	Quantity    int  `json:"quantity" binding:"required,min=1"`
+}
+
+// ApproveReturnRequest là cấu trúc request khi admin duyệt trả hàng; bỏ trống amount để hoàn
+// đúng số tiền dự kiến của yêu cầu
+type ApproveReturnRequest struct {
+	Amount float64 `json:"amount" binding:"omitempty,gt=0"`
+	Note   string  `json:"note" binding:"max=500"`
+}
+
+// RejectReturnRequest là cấu trúc request khi admin từ chối trả hàng
+type RejectReturnRequest struct {
+	Note string `json:"note" binding:"required,max=500"`
+}
+
+// ReturnQueryParams là tham số lọc và phân trang danh sách yêu cầu trả hàng (Admin)
+type ReturnQueryParams struct {
+	Status  string `form:"status" binding:"omitempty,oneof=requested approved rejected cancelled"`
+	OrderID uint   `form:"order_id"`
+	Page    int    `form:"page"`
+	Limit   int    `form:"limit" binding:"max=100"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// ProductReview là đánh giá của user về một sản phẩm (mỗi user một đánh giá cho mỗi sản phẩm).
+// Đánh giá chờ admin duyệt trước khi hiển thị và được tính vào rating_avg/rating_count của
+// product_listings. VerifiedPurchase được gắn tự động khi user có đơn đã giao chứa sản phẩm.
+type ProductReview struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	ProductID        uint       `json:"product_id" gorm:"not null;index;uniqueIndex:idx_product_reviews_user_product,priority:2"`
+	Product          Product    `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	UserID           uint       `json:"-" gorm:"not null;uniqueIndex:idx_product_reviews_user_product,priority:1"`
+	Rating           int        `json:"rating" gorm:"not null"`
+	Title            string     `json:"title" gorm:"size:200"`
+	Body             string     `json:"body" gorm:"type:text"`
+	VerifiedPurchase bool       `json:"verified_purchase" gorm:"not null;default:false"`
+	HelpfulCount     int        `json:"helpful_count" gorm:"not null;default:0"`
+	UnhelpfulCount   int        `json:"unhelpful_count" gorm:"not null;default:0"`
+	ReportCount      int        `json:"report_count" gorm:"not null;default:0;index"` // số báo cáo chưa xử lý
+	Status           string     `json:"status" gorm:"size:20;not null;default:pending;index"`
+	ModeratedBy      *uint      `json:"-"`
+	ModeratedAt      *time.Time `json:"moderated_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// Trạng thái báo cáo vi phạm đánh giá
+const (
+	ReviewReportOpen     = "open"
+	ReviewReportResolved = "resolved" // admin đã kiểm duyệt lại đánh giá
+)
+
+// ReviewVote là phiếu hữu ích/không hữu ích của user cho một đánh giá (mỗi user một phiếu)
+type ReviewVote struct {
+	ReviewID  uint          `json:"review_id" gorm:"primaryKey"`
+	Review    ProductReview `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	UserID    uint          `json:"-" gorm:"primaryKey;index"`
+	Helpful   bool          `json:"helpful" gorm:"not null"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// ReviewReport là báo cáo vi phạm của user về một đánh giá; báo cáo được xử lý khi admin kiểm duyệt
+// lại đánh giá
+type ReviewReport struct {
+	ID         uint          `json:"id" gorm:"primaryKey"`
+	ReviewID   uint          `json:"review_id" gorm:"not null;uniqueIndex:idx_review_reports_review_user,priority:1"`
+	Review     ProductReview `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	UserID     uint          `json:"-" gorm:"not null;uniqueIndex:idx_review_reports_review_user,priority:2;index"`
+	Reason     string        `json:"reason" gorm:"size:500;not null"`
+	Status     string        `json:"status" gorm:"size:20;not null;default:open;index"`
+	ResolvedAt *time.Time    `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// CreateReviewRequest là cấu trúc request khi user đánh giá sản phẩm
+type CreateReviewRequest struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Title  string `json:"title" binding:"max=200"`
+	Body   string `json:"body" binding:"max=5000"`
+}
+
+// ReviewQueryParams là tham số lọc và phân trang danh sách đánh giá đã duyệt của sản phẩm
+type ReviewQueryParams struct {
+	Rating   int  `form:"rating" binding:"omitempty,min=1,max=5"`
+	Verified bool `form:"verified"`
+	Page     int  `form:"page"`
+	Limit    int  `form:"limit" binding:"max=50"`
+}
+
+// VoteReviewRequest là cấu trúc request khi user bình chọn một đánh giá
+type VoteReviewRequest struct {
+	Helpful *bool `json:"helpful" binding:"required"`
+}
+
+// ReportReviewRequest là cấu trúc request khi user báo cáo vi phạm một đánh giá
+type ReportReviewRequest struct {
+	Reason string `json:"reason" binding:"required,min=5,max=500"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RevokedToken là một JWT đã bị thu hồi trước khi hết hạn (đăng xuất), nhận diện theo jti.
+// Dòng chỉ cần giữ tới ExpiresAt của token; sau đó token tự hết hiệu lực và dòng được dọn bởi retention.
+type RevokedToken struct {
+	JTI       string    `json:"jti" gorm:"primaryKey;size:64"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// Trạng thái lần chạy gần nhất của một tác vụ định kỳ
+const (
+	TaskStatusNever     = "never"
+	TaskStatusRunning   = "running"
+	TaskStatusSucceeded = "succeeded"
+	TaskStatusFailed    = "failed"
+)
+
+// ScheduledTask lưu lịch và kết quả lần chạy gần nhất của một tác vụ định kỳ.
+// Dòng này cũng là khóa phân tán: replica nào chiếm được locked_until mới được chạy.
+type ScheduledTask struct {
+	Name           string     `json:"name" gorm:"primaryKey"`
+	Schedule       string     `json:"schedule" gorm:"not null"`
+	NextRunAt      time.Time  `json:"next_run_at"`
+	LockedUntil    *time.Time `json:"locked_until"`
+	LockedBy       string     `json:"locked_by"`
+	LastRunAt      *time.Time `json:"last_run_at"`
+	LastStatus     string     `json:"last_status" gorm:"not null;default:'never'"`
+	LastResult     string     `json:"last_result"`
+	LastError      string     `json:"last_error"`
+	LastDurationMs int64      `json:"last_duration_ms"`
+	LastRunBy      string     `json:"last_run_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
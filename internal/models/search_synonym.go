@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// SearchSynonym là một mục của từ điển đồng nghĩa cho tìm kiếm sản phẩm: từ khóa (hoặc một từ trong từ khóa)
+// bằng Term cũng được tìm theo từng từ trong Synonyms. Mở rộng chỉ theo một chiều; cần hai chiều thì thêm
+// mục ngược lại. Term và Synonyms được lưu chữ thường.
+type SearchSynonym struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Term      string    `json:"term" gorm:"size:100;not null;uniqueIndex"`
+	Synonyms  []string  `json:"synonyms" gorm:"serializer:json;type:text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SearchSynonymRequest là cấu trúc request khi admin tạo hoặc thay thế một mục đồng nghĩa
+type SearchSynonymRequest struct {
+	Term     string   `json:"term" binding:"required,max=100"`
+	Synonyms []string `json:"synonyms" binding:"required,min=1,max=20,dive,required,max=100"`
+}
@@ -0,0 +1,27 @@
+package models
+
+// ShippingOption là một cách giao hàng kèm phí cho một giỏ hàng và địa chỉ.
+// ID có dạng provider:service, được gửi lại khi checkout để chọn cách giao.
+type ShippingOption struct {
+	ID       string  `json:"id"`
+	Provider string  `json:"provider"`
+	Service  string  `json:"service"`
+	Name     string  `json:"name"`
+	Fee      float64 `json:"fee"`
+	Currency string  `json:"currency"`
+	// EstimatedDays là số ngày giao dự kiến; 0 nếu đơn vị vận chuyển không cung cấp
+	EstimatedDays int `json:"estimated_days,omitempty"`
+}
+
+// ShippingQuoteRequest là cấu trúc request của POST /checkout/shipping-quote
+type ShippingQuoteRequest struct {
+	AddressID uint `json:"address_id" binding:"required"`
+}
+
+// ShippingQuote là các cách giao hàng khả dụng cho giỏ hàng hiện tại tới một địa chỉ, rẻ nhất trước
+type ShippingQuote struct {
+	AddressID   uint             `json:"address_id"`
+	WeightGrams int              `json:"weight_grams"`
+	Subtotal    float64          `json:"subtotal"`
+	Options     []ShippingOption `json:"options"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Sitemap là một file sitemap XML đã dựng sẵn, được tác vụ định kỳ sitemap_generate tạo lại. Name là
+// "index" cho sitemap index hoặc tên sitemap con ("products-1", "categories").
+type Sitemap struct {
+	Name        string    `gorm:"primaryKey;size:50"`
+	Content     []byte    `gorm:"not null"`
+	URLCount    int       `gorm:"not null;default:0"`
+	GeneratedAt time.Time `gorm:"not null"`
+}
+
+// SitemapEntry là một URL trong sitemap kèm thời điểm nội dung thay đổi gần nhất
+type SitemapEntry struct {
+	Path      string
+	UpdatedAt time.Time
+}
@@ -0,0 +1,104 @@
+package models
+
+import "time"
+
+// Trạng thái phiếu hỗ trợ
+const (
+	SupportTicketOpen     = "open"     // chờ shop trả lời
+	SupportTicketAnswered = "answered" // shop đã trả lời, chờ khách phản hồi
+	SupportTicketResolved = "resolved" // đã xử lý xong; khách trả lời thì phiếu được mở lại
+	SupportTicketClosed   = "closed"   // đã đóng, không nhận thêm tin nhắn
+)
+
+// supportTicketTransitions là các bước chuyển trạng thái hợp lệ của phiếu hỗ trợ: shop trả lời chuyển
+// open → answered, khách trả lời chuyển answered/resolved → open; phiếu chưa đóng có thể được đánh dấu
+// resolved hoặc đóng, và chỉ admin mở lại được phiếu đã đóng
+var supportTicketTransitions = map[string][]string{
+	SupportTicketOpen:     {SupportTicketAnswered, SupportTicketResolved, SupportTicketClosed},
+	SupportTicketAnswered: {SupportTicketOpen, SupportTicketResolved, SupportTicketClosed},
+	SupportTicketResolved: {SupportTicketOpen, SupportTicketClosed},
+	SupportTicketClosed:   {SupportTicketOpen},
+}
+
+// CanTransitionSupportTicket kiểm tra phiếu có được chuyển từ trạng thái from sang to hay không
+func CanTransitionSupportTicket(from, to string) bool {
+	for _, next := range supportTicketTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Chủ đề của phiếu hỗ trợ
+const (
+	SupportCategoryOrder    = "order"
+	SupportCategoryPayment  = "payment"
+	SupportCategoryShipping = "shipping"
+	SupportCategoryProduct  = "product"
+	SupportCategoryAccount  = "account"
+	SupportCategoryOther    = "other"
+)
+
+// SupportTicket là phiếu hỗ trợ khách gửi qua form liên hệ. Khách chưa đăng nhập cũng gửi được (UserID
+// rỗng) và nhận trả lời qua Email; user đã đăng nhập xem và trả lời phiếu của mình qua API.
+type SupportTicket struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	UserID   *uint  `json:"user_id,omitempty" gorm:"index"`
+	Name     string `json:"name" gorm:"size:255"`
+	Email    string `json:"email" gorm:"size:255;not null;index"`
+	Category string `json:"category" gorm:"size:20;not null;default:other;index"`
+	Subject  string `json:"subject" gorm:"size:255;not null"`
+	// OrderID là đơn hàng liên quan, chỉ user đã đăng nhập gắn được và phải là đơn của user đó
+	OrderID       *uint                  `json:"order_id,omitempty"`
+	Status        string                 `json:"status" gorm:"size:20;not null;default:open;index"`
+	LastMessageAt time.Time              `json:"last_message_at" gorm:"index"`
+	ClosedAt      *time.Time             `json:"closed_at,omitempty"`
+	Messages      []SupportTicketMessage `json:"messages,omitempty" gorm:"foreignKey:TicketID;constraint:OnDelete:CASCADE"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+// SupportTicketMessage là một tin nhắn trong phiếu hỗ trợ; Staff cho biết tin nhắn do admin gửi.
+// AuthorID rỗng với tin nhắn đầu tiên của khách chưa đăng nhập.
+type SupportTicketMessage struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TicketID  uint      `json:"ticket_id" gorm:"not null;index"`
+	AuthorID  *uint     `json:"author_id,omitempty"`
+	Staff     bool      `json:"staff" gorm:"not null;default:false"`
+	Body      string    `json:"body" gorm:"type:text;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateSupportTicketRequest là cấu trúc request khi gửi phiếu hỗ trợ. Khách chưa đăng nhập phải gửi email;
+// user đã đăng nhập dùng email của tài khoản.
+type CreateSupportTicketRequest struct {
+	Name     string `json:"name" binding:"max=255"`
+	Email    string `json:"email" binding:"omitempty,email,max=255"`
+	Category string `json:"category" binding:"omitempty,oneof=order payment shipping product account other"`
+	Subject  string `json:"subject" binding:"required,max=255"`
+	Message  string `json:"message" binding:"required,max=5000"`
+	OrderID  *uint  `json:"order_id,omitempty"`
+}
+
+// ReplySupportTicketRequest là cấu trúc request khi thêm tin nhắn vào phiếu hỗ trợ
+type ReplySupportTicketRequest struct {
+	Message string `json:"message" binding:"required,max=5000"`
+}
+
+// UpdateSupportTicketStatusRequest là cấu trúc request khi admin đổi trạng thái phiếu; answered chỉ được
+// đặt khi admin trả lời
+type UpdateSupportTicketStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=open resolved closed"`
+}
+
+// SupportTicketQueryParams là tham số lọc và phân trang danh sách phiếu hỗ trợ; UserID và Email chỉ dùng
+// cho admin
+type SupportTicketQueryParams struct {
+	Status   string `form:"status" binding:"omitempty,oneof=open answered resolved closed"`
+	Category string `form:"category" binding:"omitempty,oneof=order payment shipping product account other"`
+	UserID   uint   `form:"user_id"`
+	Email    string `form:"email"`
+	Page     int    `form:"page"`
+	Limit    int    `form:"limit" binding:"max=100"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// TaxRate là một quy tắc thuế suất GTGT. Category và Province rỗng nghĩa là áp dụng cho mọi danh
+// mục/tỉnh; khi nhiều quy tắc khớp, quy tắc cụ thể nhất được dùng (danh mục + tỉnh, rồi danh mục,
+// rồi tỉnh, rồi quy tắc chung). Nếu không quy tắc nào khớp thì dùng thuế suất mặc định (INVOICE_TAX_RATE).
+type TaxRate struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null;size:100"`
+	Rate      float64   `json:"rate" gorm:"not null"` // phần trăm, ví dụ 8 hoặc 10
+	Category  string    `json:"category" gorm:"not null;default:'';size:100;uniqueIndex:idx_tax_rates_scope,priority:1"`
+	Province  string    `json:"province" gorm:"not null;default:'';size:100;uniqueIndex:idx_tax_rates_scope,priority:2"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TaxRateRequest là cấu trúc request khi tạo hoặc sửa quy tắc thuế suất (Admin)
+type TaxRateRequest struct {
+	Name     string   `json:"name" binding:"required,max=100"`
+	Rate     *float64 `json:"rate" binding:"required,min=0,max=100"`
+	Category string   `json:"category" binding:"max=100"`
+	Province string   `json:"province" binding:"max=100"`
+}
@@ -2,41 +2,112 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
+// User là tài khoản người dùng. Tài khoản bị xóa được xóa mềm (DeletedAt) và có thể khôi phục
+// trong thời gian chờ; hết thời gian chờ dữ liệu cá nhân bị ẩn danh hóa (AnonymizedAt),
+// còn bản ghi user được giữ lại để hóa đơn và các tham chiếu khác vẫn hợp lệ.
 type User struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Username  string    `json:"username" gorm:"unique;not null"`
-	Email     string    `json:"email" gorm:"unique;not null"`
-	Password  string    `json:"-" gorm:"not null"`
-	FullName  string    `json:"full_name"`
-	Role      string    `json:"role" gorm:"default:'user'"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	Username     string         `json:"username" gorm:"unique;not null"`
+	Email        string         `json:"email" gorm:"unique;not null"`
+	Password     string         `json:"-" gorm:"not null"`
+	FullName     string         `json:"full_name"`
+	Role         string         `json:"role" gorm:"default:'user'"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+	AnonymizedAt *time.Time     `json:"anonymized_at,omitempty"`
+	// TokenVersion được ghi vào JWT khi đăng nhập; tăng giá trị này làm mọi token đã cấp hết hiệu lực
+	TokenVersion int `json:"-" gorm:"not null;default:0"`
+	// Status là active hoặc invited (tài khoản được nhập chưa đặt mật khẩu, xem UserInvitation)
+	Status string `json:"status" gorm:"size:20;not null;default:active;index"`
 }
 
 // UserResponse là cấu trúc response khi trả về thông tin user
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	FullName  string    `json:"full_name"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uint       `json:"id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email"`
+	FullName  string     `json:"full_name"`
+	Role      string     `json:"role"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// PurgeAt là thời điểm dữ liệu của tài khoản đã xóa sẽ bị ẩn danh hóa
+	PurgeAt      *time.Time `json:"purge_at,omitempty"`
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty"`
+}
+
+// DeleteAccountRequest là cấu trúc request khi user tự xóa tài khoản
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// UserDataExport là toàn bộ dữ liệu cá nhân của user trả về bởi GET /users/me/export
+type UserDataExport struct {
+	ExportedAt              time.Time                `json:"exported_at"`
+	Profile                 UserResponse             `json:"profile"`
+	Devices                 []DeviceToken            `json:"devices"`
+	NotificationPreferences []NotificationPreference `json:"notification_preferences"`
+	PaymentMethods          []PaymentMethod          `json:"payment_methods"`
+	Addresses               []Address                `json:"addresses"`
+	ProductAlerts           []ProductAlert           `json:"product_alerts"`
+	EmailChanges            []EmailChange            `json:"email_changes"`
+	Cart                    []CartItem               `json:"cart"`
+	Orders                  []Order                  `json:"orders"`
+	Payments                []Payment                `json:"payments"`
+	Invoices                []Invoice                `json:"invoices"`
+	Returns                 []OrderReturn            `json:"returns"`
+	ProductQuestions        []ProductQuestion        `json:"product_questions"`
+	ProductAnswers          []ProductAnswer          `json:"product_answers"`
+	ProductReviews          []ProductReview          `json:"product_reviews"`
+	ReviewVotes             []ReviewVote             `json:"review_votes"`
+	ReviewReports           []ReviewReport           `json:"review_reports"`
+	SupportTickets          []SupportTicket          `json:"support_tickets"`
+	LoginEvents             []LoginEvent             `json:"login_events"`
+	AuditLogs               []AuditLog               `json:"audit_logs"`
 }
 
 // LoginRequest là cấu trúc request khi đăng nhập
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	// CartToken là token giỏ hàng của khách; nếu có, giỏ được gộp vào giỏ của user sau khi đăng nhập
+	// (cũng có thể gửi qua header X-Cart-Token)
+	CartToken string `json:"cart_token"`
+	// AuthMode chọn cách nhận token: token (trong body) hoặc cookie (cookie HttpOnly kèm token CSRF);
+	// mặc định theo AUTH_MODE
+	AuthMode string `json:"auth_mode" binding:"omitempty,oneof=token cookie"`
+	// DeviceFingerprint là định danh thiết bị do client tạo (ví dụ từ thư viện fingerprint trên trình duyệt),
+	// dùng để nhận ra thiết bị mới; mặc định dùng User-Agent
+	DeviceFingerprint string `json:"device_fingerprint" binding:"max=255"`
 }
 
 // RegisterRequest là cấu trúc request khi đăng ký
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"` // độ dài và độ mạnh do password.Policy kiểm tra
 	FullName string `json:"full_name" binding:"required"`
+	// CartToken là token giỏ hàng của khách, được gộp vào giỏ của tài khoản mới như khi đăng nhập
+	CartToken string `json:"cart_token"`
+}
+
+// UserStats là số liệu tổng hợp của một user cho trang chi tiết của admin
+type UserStats struct {
+	OrderCount int64 `json:"order_count"`
+	// TotalSpent là số tiền đã thu qua các payment thành công của user, trừ phần đã hoàn
+	TotalSpent  float64    `json:"total_spent"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+// AdminUserDetailResponse là thông tin user kèm số liệu tổng hợp trả về cho admin
+type AdminUserDetailResponse struct {
+	UserResponse
+	Stats UserStats `json:"stats"`
 }
 
 // UserQueryParams là cấu trúc cho các tham số tìm kiếm và phân trang user
@@ -48,11 +119,30 @@ type UserQueryParams struct {
 	// Tìm kiếm (optional - có thể mở rộng sau)
 	Search string `form:"search"`
 	Role   string `form:"role"` // admin, user
+	// Status lọc theo trạng thái tài khoản: active hoặc invited
+	Status string `form:"status" binding:"omitempty,oneof=active invited"`
+
+	// Deleted = true chỉ liệt kê các tài khoản đã xóa (để khôi phục)
+	Deleted bool `form:"deleted"`
+
+	// Lọc theo ngày tạo tài khoản (YYYY-MM-DD, bao gồm cả hai đầu)
+	StartDate time.Time `form:"start_date" time_format:"2006-01-02"`
+	EndDate   time.Time `form:"end_date" time_format:"2006-01-02"`
+
+	// IsActive lọc theo việc user có đăng nhập trong UserActiveWindow gần nhất hay không
+	IsActive *bool `form:"is_active"`
+
+	// Sắp xếp
+	SortBy string `form:"sort_by" binding:"omitempty,oneof=created_at username email"`
+	Order  string `form:"order" binding:"omitempty,oneof=asc desc"`
 }
 
+// UserActiveWindow là khoảng thời gian tính từ lần đăng nhập gần nhất để user được xem là đang hoạt động
+const UserActiveWindow = 30 * 24 * time.Hour
+
 // ChangePasswordRequest represents the request body for changing password
 type ChangePasswordRequest struct {
 	CurrentPassword    string `json:"current_password" binding:"required"`
-	NewPassword        string `json:"new_password" binding:"required,min=6"`
+	NewPassword        string `json:"new_password" binding:"required"`
 	ConfirmNewPassword string `json:"confirm_new_password" binding:"required,eqfield=NewPassword"`
 }
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Trạng thái tài khoản
+const (
+	UserStatusActive = "active"
+	// UserStatusInvited là tài khoản do admin tạo (nhập từ CSV) chưa đặt mật khẩu; chưa đăng nhập được
+	UserStatusInvited = "invited"
+)
+
+// UserInvitation là lời mời đặt mật khẩu gửi tới tài khoản được admin tạo sẵn. Token trong link chỉ được lưu
+// dưới dạng hash; mỗi user có tối đa một lời mời đang chờ, gửi lại lời mời hủy lời mời cũ.
+type UserInvitation struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	Email       string     `json:"email" gorm:"not null"`
+	TokenHash   string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	InvitedBy   uint       `json:"invited_by" gorm:"not null"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Pending cho biết lời mời còn dùng được tại thời điểm now
+func (i *UserInvitation) Pending(now time.Time) bool {
+	return i.AcceptedAt == nil && i.CancelledAt == nil && now.Before(i.ExpiresAt)
+}
+
+// AcceptInvitationRequest là cấu trúc request khi user đặt mật khẩu bằng token trong link mời
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"` // độ dài và độ mạnh do password.Policy kiểm tra
+}
+
+// Kết quả của một dòng khi nhập user từ CSV
+const (
+	UserImportCreated = "created"
+	UserImportValid   = "valid" // dry run: dòng hợp lệ, chưa tạo user
+	UserImportInvalid = "invalid"
+)
+
+// UserImportError là một lỗi của một cột trong dòng CSV; Code là mã lỗi ổn định cho client (required,
+// invalid, too_long, duplicate, taken, unsupported_role)
+type UserImportError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// UserImportRow là kết quả nhập của một dòng; Row là số dòng trong file (dòng tiêu đề là dòng 1)
+type UserImportRow struct {
+	Row      int               `json:"row"`
+	Email    string            `json:"email"`
+	Username string            `json:"username,omitempty"`
+	Status   string            `json:"status"`
+	UserID   *uint             `json:"user_id,omitempty"`
+	Errors   []UserImportError `json:"errors,omitempty"`
+}
+
+// UserImportResult là kết quả nhập file CSV: các dòng hợp lệ được tạo kể cả khi có dòng lỗi
+type UserImportResult struct {
+	DryRun  bool            `json:"dry_run"`
+	Total   int             `json:"total"`
+	Created int             `json:"created"`
+	Valid   int             `json:"valid"`
+	Invalid int             `json:"invalid"`
+	Rows    []UserImportRow `json:"rows"`
+}
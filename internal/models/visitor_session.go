@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// VisitorSession là phiên duyệt của một khách chưa đăng nhập, nhận diện bằng cookie visitor_id đã ký.
+// Dòng chỉ được tạo khi phiên cần lưu dữ liệu (giỏ hàng, sản phẩm đã xem) và bị xóa khi không có
+// hoạt động lâu hơn VISITOR_SESSION_TTL.
+type VisitorSession struct {
+	ID string `json:"id" gorm:"primaryKey;size:32"`
+	// CartToken là token giỏ của khách tạo trong phiên, dùng để gộp giỏ khi đăng nhập nếu client
+	// không gửi kèm token giỏ
+	CartToken  *string   `json:"-" gorm:"size:64"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at" gorm:"not null;index"`
+}
+
+// RecentlyViewedProduct là một sản phẩm khách đã xem trong phiên; mỗi sản phẩm một dòng, xem lại
+// chỉ cập nhật ViewedAt
+type RecentlyViewedProduct struct {
+	VisitorID string    `gorm:"primaryKey;size:32"`
+	ProductID uint      `gorm:"primaryKey"`
+	Product   Product   `gorm:"constraint:OnDelete:CASCADE"`
+	ViewedAt  time.Time `gorm:"not null;index"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+)
+
+// Trạng thái của một lần gửi webhook
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookSubscription là endpoint do admin đăng ký để nhận sự kiện
+type WebhookSubscription struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	URL         string    `json:"url" gorm:"not null"`
+	Events      string    `json:"events" gorm:"not null"`                 // danh sách sự kiện, phân tách bởi dấu phẩy
+	Secret      string    `json:"-" gorm:"not null;serializer:encrypted"` // mã hóa bằng fieldcrypt
+	Description string    `json:"description"`
+	Active      bool      `json:"active" gorm:"default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery là một lần gửi sự kiện tới một subscription (hàng đợi + nhật ký)
+type WebhookDelivery struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint       `json:"subscription_id" gorm:"not null;index"`
+	Event          string     `json:"event" gorm:"not null"`
+	Payload        JSON       `json:"payload" gorm:"not null"`
+	Status         string     `json:"status" gorm:"not null;default:'pending';index:idx_webhook_deliveries_due,priority:1"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index:idx_webhook_deliveries_due,priority:2"`
+	ResponseStatus int        `json:"response_status"`
+	LastError      string     `json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"index"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// WebhookSubscriptionResponse là cấu trúc response khi trả về subscription
+type WebhookSubscriptionResponse struct {
+	ID          uint      `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CreateWebhookRequest là cấu trúc request khi đăng ký webhook
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url"`
+	Events      []string `json:"events" binding:"required,min=1"`
+	Secret      string   `json:"secret" binding:"omitempty,min=16"`
+	Description string   `json:"description"`
+}
+
+// WebhookDeliveryQueryParams là cấu trúc cho các tham số lọc nhật ký gửi webhook
+type WebhookDeliveryQueryParams struct {
+	Status string `form:"status"`
+	Event  string `form:"event"`
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit" binding:"max=100"`
+}
@@ -0,0 +1,75 @@
+// Package netguard chặn kết nối ra ngoài tới các địa chỉ không công khai (loopback, mạng nội bộ,
+// link-local, metadata của cloud, ...) cho các URL do người dùng cung cấp
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"syscall"
+)
+
+// ErrNonPublicAddress được trả về khi host là hoặc phân giải ra địa chỉ không công khai
+var ErrNonPublicAddress = errors.New("host resolves to a non-public address")
+
+// nonPublicPrefixes là các dải địa chỉ không được kết nối tới ngoài loopback, private và link-local
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),  // CGNAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // dành riêng và broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64 có thể trỏ tới IPv4 nội bộ
+	netip.MustParsePrefix("64:ff9b:1::/48"), // NAT64 cục bộ
+	netip.MustParsePrefix("2002::/16"),      // 6to4
+}
+
+// PublicAddr cho biết addr là địa chỉ unicast công khai
+func PublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() || addr.IsMulticast() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Control trả về hàm dùng cho net.Dialer.Control, từ chối kết nối tới địa chỉ không công khai bằng lỗi
+// blocked. Hàm chạy sau khi DNS đã được phân giải, nên host đổi bản ghi DNS sau lần kiểm tra bằng
+// CheckHost (DNS rebinding) hay chuyển hướng sang host nội bộ cũng bị chặn.
+func Control(blocked error) func(network, address string, c syscall.RawConn) error {
+	return func(_, address string, _ syscall.RawConn) error {
+		addrPort, err := netip.ParseAddrPort(address)
+		if err != nil || !PublicAddr(addrPort.Addr()) {
+			return blocked
+		}
+		return nil
+	}
+}
+
+// CheckHost trả về ErrNonPublicAddress nếu host là địa chỉ IP không công khai hoặc phân giải ra ít nhất một
+// địa chỉ như vậy
+func CheckHost(ctx context.Context, host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !PublicAddr(addr) {
+			return ErrNonPublicAddress
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !PublicAddr(addr) {
+			return ErrNonPublicAddress
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false}, // metadata của cloud
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false}, // IPv4 nằm trong IPv6
+		{"64:ff9b::a00:1", false},   // NAT64 tới 10.0.0.1
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := PublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("PublicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestCheckHostLiteral(t *testing.T) {
+	ctx := context.Background()
+	if err := CheckHost(ctx, "127.0.0.1"); !errors.Is(err, ErrNonPublicAddress) {
+		t.Errorf("CheckHost(127.0.0.1) = %v, want ErrNonPublicAddress", err)
+	}
+	if err := CheckHost(ctx, "::1"); !errors.Is(err, ErrNonPublicAddress) {
+		t.Errorf("CheckHost(::1) = %v, want ErrNonPublicAddress", err)
+	}
+	if err := CheckHost(ctx, "93.184.216.34"); err != nil {
+		t.Errorf("CheckHost(93.184.216.34) = %v", err)
+	}
+}
+
+func TestControl(t *testing.T) {
+	blocked := errors.New("blocked")
+	control := Control(blocked)
+	if err := control("tcp4", "10.0.0.5:443", nil); err != blocked {
+		t.Errorf("control(10.0.0.5:443) = %v, want blocked", err)
+	}
+	if err := control("tcp4", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("control(93.184.216.34:443) = %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mức độ nghiêm trọng của cảnh báo
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Các loại cảnh báo gửi tới kênh admin
+const (
+	AlertPaymentFailed    = "payment_failed"
+	AlertOutOfStock       = "out_of_stock"
+	AlertServerErrors     = "server_errors"
+	AlertSecurity         = "security"
+	AlertDatabaseFailover = "database_failover"
+	AlertSupportTicket    = "support_ticket"
+)
+
+// TypeAlert là loại job gửi cảnh báo tới các kênh admin
+const TypeAlert = "notification.alert"
+
+// Alert là cảnh báo vận hành gửi tới kênh chat của admin (Telegram, Slack)
+type Alert struct {
+	Kind     string            `json:"kind"`
+	Severity string            `json:"severity"`
+	Title    string            `json:"title"`
+	Message  string            `json:"message"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	At       time.Time         `json:"at"`
+}
+
+// AlertSink gửi cảnh báo tới một kênh chat
+type AlertSink interface {
+	Name() string
+	SendAlert(ctx context.Context, a Alert) error
+}
+
+// NewAlertSinksFromEnv cấu hình Telegram (ALERT_TELEGRAM_BOT_TOKEN, ALERT_TELEGRAM_CHAT_ID)
+// và Slack (ALERT_SLACK_WEBHOOK_URL)
+func NewAlertSinksFromEnv() []AlertSink {
+	var sinks []AlertSink
+	if token, chatID := os.Getenv("ALERT_TELEGRAM_BOT_TOKEN"), os.Getenv("ALERT_TELEGRAM_CHAT_ID"); token != "" && chatID != "" {
+		sinks = append(sinks, &TelegramSink{BotToken: token, ChatID: chatID})
+	}
+	if url := os.Getenv("ALERT_SLACK_WEBHOOK_URL"); url != "" {
+		sinks = append(sinks, &SlackSink{WebhookURL: url})
+	}
+	return sinks
+}
+
+// Alert đưa cảnh báo vào hàng đợi để gửi tới các kênh admin. An toàn khi d là nil.
+func (d *Dispatcher) Alert(ctx context.Context, a Alert) {
+	if d == nil {
+		return
+	}
+	if a.At.IsZero() {
+		a.At = time.Now()
+	}
+	if a.Severity == "" {
+		a.Severity = SeverityWarning
+	}
+	d.jobs.EnqueueLogged(ctx, TypeAlert, a)
+}
+
+// DeliverAlert gửi cảnh báo tới tất cả kênh admin đã cấu hình
+func (d *Dispatcher) DeliverAlert(ctx context.Context, a Alert) error {
+	var errs []error
+	for _, sink := range d.alertSinks {
+		if err := sink.SendAlert(ctx, a); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// formatAlert dựng nội dung văn bản dùng chung cho các kênh chat
+func formatAlert(a Alert) string {
+	var sb strings.Builder
+	icon := "⚠️"
+	if a.Severity == SeverityCritical {
+		icon = "🚨"
+	}
+	fmt.Fprintf(&sb, "%s [%s] %s\n%s", icon, strings.ToUpper(a.Severity), a.Title, a.Message)
+
+	keys := make([]string, 0, len(a.Fields))
+	for k := range a.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "\n• %s: %s", k, a.Fields[k])
+	}
+	fmt.Fprintf(&sb, "\n%s", a.At.Format(time.RFC3339))
+	return sb.String()
+}
+
+func postJSON(ctx context.Context, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := pushHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, snippet)
+	}
+	return nil
+}
+
+// TelegramSink gửi cảnh báo qua Telegram Bot API
+type TelegramSink struct {
+	BotToken string
+	ChatID   string
+}
+
+func (t *TelegramSink) Name() string { return "telegram" }
+
+func (t *TelegramSink) SendAlert(ctx context.Context, a Alert) error {
+	return postJSON(ctx, "https://api.telegram.org/bot"+t.BotToken+"/sendMessage", map[string]interface{}{
+		"chat_id":                  t.ChatID,
+		"text":                     formatAlert(a),
+		"disable_web_page_preview": true,
+	})
+}
+
+// SlackSink gửi cảnh báo qua Slack Incoming Webhook
+type SlackSink struct {
+	WebhookURL string
+}
+
+func (s *SlackSink) Name() string { return "slack" }
+
+func (s *SlackSink) SendAlert(ctx context.Context, a Alert) error {
+	return postJSON(ctx, s.WebhookURL, map[string]string{"text": formatAlert(a)})
+}
+
+// Alarm đếm số lần xảy ra trong một cửa sổ trượt và báo khi vượt ngưỡng,
+// sau đó im lặng trong khoảng cooldown để không gửi cảnh báo dồn dập
+type Alarm struct {
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	hits      []time.Time
+	lastFired time.Time
+}
+
+// NewAlarm tạo alarm báo khi có ít nhất threshold lần trong window
+func NewAlarm(threshold int, window, cooldown time.Duration) *Alarm {
+	return &Alarm{threshold: threshold, window: window, cooldown: cooldown}
+}
+
+// Hit ghi nhận một lần xảy ra; trả về số lần trong cửa sổ và true nếu cần gửi cảnh báo
+func (a *Alarm) Hit() (int, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-a.window)
+	i := 0
+	for i < len(a.hits) && a.hits[i].Before(cutoff) {
+		i++
+	}
+	a.hits = append(a.hits[i:], now)
+
+	count := len(a.hits)
+	if a.threshold <= 0 || count < a.threshold || now.Sub(a.lastFired) < a.cooldown {
+		return count, false
+	}
+	a.lastFired = now
+	return count, true
+}
@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// EmailChannel gửi thông báo dạng email văn bản thuần tới địa chỉ email của user qua SMTP
+type EmailChannel struct {
+	users *repository.UserRepository
+	addr  string
+	auth  smtp.Auth
+	from  mail.Address
+}
+
+// NewEmailChannelFromEnv cấu hình kênh email từ SMTP_HOST, SMTP_PORT (mặc định 587), SMTP_USERNAME,
+// SMTP_PASSWORD và SMTP_FROM. Trả về nil nếu SMTP_HOST chưa được cấu hình.
+func NewEmailChannelFromEnv(db *gorm.DB) (*EmailChannel, error) {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil, nil
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	from, err := mail.ParseAddress(os.Getenv("SMTP_FROM"))
+	if err != nil {
+		return nil, fmt.Errorf("SMTP_FROM: %w", err)
+	}
+	ch := &EmailChannel{
+		users: repository.NewUserRepository(db),
+		addr:  net.JoinHostPort(host, port),
+		from:  *from,
+	}
+	if username := os.Getenv("SMTP_USERNAME"); username != "" {
+		ch.auth = smtp.PlainAuth("", username, os.Getenv("SMTP_PASSWORD"), host)
+	}
+	return ch, nil
+}
+
+func (e *EmailChannel) Name() string { return ChannelEmail }
+
+// Send gửi email với tiêu đề là Title và nội dung là Body của thông báo tới n.Email nếu có, ngược lại
+// tới email của user. User đã xóa tài khoản không còn nhận email; thông báo không gắn với user (UserID bằng 0)
+// chỉ gửi tới n.Email.
+func (e *EmailChannel) Send(ctx context.Context, n Notification) error {
+	to := mail.Address{Address: n.Email}
+	if n.UserID != 0 {
+		user, err := e.users.GetByID(ctx, n.UserID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNoRecipient
+		}
+		if err != nil {
+			return err
+		}
+		to.Name = user.FullName
+		if to.Address == "" {
+			to.Address = user.Email
+		}
+	}
+	if to.Address == "" {
+		return ErrNoRecipient
+	}
+
+	msg, err := buildEmail(e.from, to, n.Title, n.Body)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return smtp.SendMail(e.addr, e.auth, e.from.Address, []string{to.Address}, msg)
+}
+
+// Verify kết nối tới máy chủ SMTP, bật STARTTLS nếu máy chủ hỗ trợ và đăng nhập nếu có SMTP_USERNAME,
+// rồi đóng kết nối mà không gửi thư; dùng cho kiểm tra khi khởi động
+func (e *EmailChannel) Verify(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", e.addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	host, _, _ := net.SplitHostPort(e.addr)
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+	if e.auth != nil {
+		if err := client.Auth(e.auth); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+	return client.Quit()
+}
+
+// buildEmail dựng email text/plain UTF-8; tiêu đề được mã hóa theo RFC 2047, nội dung theo quoted-printable
+func buildEmail(from, to mail.Address, subject, body string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+)
+
+// RegisterLoginSubscribers gửi email cảnh báo cho user khi tài khoản được đăng nhập từ thiết bị hoặc quốc gia
+// mới. Khi lần đăng nhập phải được xác nhận, email chứa link xác nhận đã thay cho cảnh báo nên không gửi thêm.
+func RegisterLoginSubscribers(bus *events.Bus, d *Dispatcher) {
+	events.On(bus, func(ctx context.Context, e events.SuspiciousLogin) {
+		if e.ConfirmationRequired {
+			return
+		}
+		device := e.UserAgent
+		if device == "" {
+			device = "an unknown device"
+		}
+		where := e.IP
+		if e.Country != "" {
+			where += ", " + e.Country
+		}
+		d.Notify(ctx, Notification{
+			UserID: e.User.ID,
+			Event:  EventLoginAlert,
+			Title:  "New sign-in to your account",
+			Body: fmt.Sprintf("Your account was signed in to from %s (%s) on %s.\n\n"+
+				"If this was you, you can ignore this email. If not, change your password and sign out of all devices.",
+				device, where, e.At.Format(time.RFC1123)),
+			Data:  map[string]string{"ip": e.IP, "country": e.Country, "reasons": strings.Join(e.Reasons, ",")},
+			Email: e.User.Email,
+		})
+	})
+}
@@ -0,0 +1,169 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/NgTruong624/project_backend/internal/jobs"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Các loại sự kiện thông báo tới user; dùng làm khóa cho NotificationPreference
+const (
+	EventOrderStatus = "order.status_changed"
+	EventBackInStock = "product.back_in_stock"
+	EventPriceDrop   = "product.price_drop"
+	EventTest        = "notification.test"
+	EventEmailChange = "account.email_change"
+	EventInvitation  = "account.invitation"
+	EventNewsletter  = "newsletter.subscription"
+	EventSupport     = "support.ticket"
+	EventLoginAlert  = "account.login_alert"
+)
+
+// PreferenceEvents là các loại sự kiện user tự chọn kênh nhận qua /users/me/notifications/preferences.
+// Email giao dịch (EventEmailChange, EventInvitation, EventNewsletter, EventSupport, EventLoginAlert) và thông báo thử luôn được gửi nên không nằm trong danh sách.
+var PreferenceEvents = []string{EventOrderStatus, EventBackInStock, EventPriceDrop}
+
+// IsPreferenceEvent cho biết user có thể chọn kênh nhận cho loại sự kiện event hay không
+func IsPreferenceEvent(event string) bool {
+	for _, e := range PreferenceEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Kênh gửi thông báo
+const (
+	ChannelPush    = "push"
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
+
+// TypeSend là loại job gửi thông báo qua các kênh
+const TypeSend = "notification.send"
+
+// Notification là một thông báo gửi tới một user
+type Notification struct {
+	UserID uint              `json:"user_id"`
+	Event  string            `json:"event"`
+	Title  string            `json:"title"`
+	Body   string            `json:"body"`
+	Data   map[string]string `json:"data,omitempty"`
+	// Email gửi thông báo tới địa chỉ này thay vì email của user (ví dụ xác nhận địa chỉ email mới).
+	// Thông báo như vậy là email giao dịch: chỉ gửi qua kênh email và không phụ thuộc lựa chọn của user.
+	// UserID bằng 0 khi người nhận không có tài khoản (ví dụ địa chỉ đăng ký nhận bản tin).
+	Email string `json:"email,omitempty"`
+}
+
+// Channel gửi thông báo qua một kênh (push, email, ...)
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, n Notification) error
+}
+
+// ErrNoRecipient được trả về khi user không có địa chỉ nhận trên kênh (ví dụ chưa đăng ký thiết bị)
+var ErrNoRecipient = errors.New("no recipient for channel")
+
+// Dispatcher là điểm vào của notification subsystem: Notify đưa thông báo vào hàng đợi
+// job, worker gọi Deliver để gửi qua các kênh mà user chưa tắt
+type Dispatcher struct {
+	repo       *repository.NotificationRepository
+	jobs       *jobs.Client
+	channels   []Channel
+	alertSinks []AlertSink
+}
+
+// NewDispatcher tạo dispatcher với các kênh đã cấu hình
+func NewDispatcher(db *gorm.DB, jobClient *jobs.Client, channels ...Channel) *Dispatcher {
+	return &Dispatcher{
+		repo:     repository.NewNotificationRepository(db),
+		jobs:     jobClient,
+		channels: channels,
+	}
+}
+
+// SetAlertSinks cấu hình các kênh chat nhận cảnh báo cho admin
+func (d *Dispatcher) SetAlertSinks(sinks ...AlertSink) {
+	d.alertSinks = sinks
+}
+
+// Notify đưa thông báo vào hàng đợi; lỗi chỉ được ghi log. An toàn khi d là nil.
+func (d *Dispatcher) Notify(ctx context.Context, n Notification) {
+	if d == nil {
+		return
+	}
+	d.jobs.EnqueueLogged(ctx, TypeSend, n)
+}
+
+// Deliver gửi thông báo qua từng kênh theo lựa chọn của user
+func (d *Dispatcher) Deliver(ctx context.Context, n Notification) error {
+	if n.Email != "" {
+		for _, ch := range d.channels {
+			if ch.Name() == ChannelEmail {
+				return ch.Send(ctx, n)
+			}
+		}
+		log.Printf("Notify: email channel is not configured, dropping %s notification for user %d", n.Event, n.UserID)
+		return nil
+	}
+
+	pref, err := d.repo.GetPreference(ctx, n.UserID, n.Event)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, ch := range d.channels {
+		if !channelEnabled(pref, ch.Name()) {
+			continue
+		}
+		if err := ch.Send(ctx, n); err != nil && !errors.Is(err, ErrNoRecipient) {
+			errs = append(errs, fmt.Errorf("%s: %w", ch.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func channelEnabled(pref *models.NotificationPreference, channel string) bool {
+	switch channel {
+	case ChannelPush:
+		return pref.Push
+	case ChannelEmail:
+		return pref.Email
+	case ChannelWebhook:
+		return pref.Webhook
+	}
+	return true
+}
+
+// RegisterJobHandlers đăng ký handler gửi thông báo cho worker
+func RegisterJobHandlers(w *jobs.Worker, d *Dispatcher) {
+	w.Handle(TypeSend, func(ctx context.Context, payload json.RawMessage) error {
+		var n Notification
+		if err := json.Unmarshal(payload, &n); err != nil {
+			return err
+		}
+		return d.Deliver(ctx, n)
+	})
+	w.Handle(TypeAlert, func(ctx context.Context, payload json.RawMessage) error {
+		var a Alert
+		if err := json.Unmarshal(payload, &a); err != nil {
+			return err
+		}
+		return d.DeliverAlert(ctx, a)
+	})
+	if len(d.channels) == 0 {
+		log.Println("Notify: no notification channels configured, notifications will be dropped")
+	}
+	if len(d.alertSinks) == 0 {
+		log.Println("Notify: no alert channels configured, admin alerts will be dropped")
+	}
+}
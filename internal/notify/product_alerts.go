@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// RegisterProductAlertSubscribers báo cho user theo dõi sản phẩm khi sản phẩm hết hàng có hàng trở lại
+// hoặc giá bán giảm tới ngưỡng của họ. Mỗi alert chỉ báo một lần (được đánh dấu đã báo trước khi đưa
+// thông báo vào hàng đợi) và thông báo đi qua các kênh email/push theo lựa chọn của user.
+func RegisterProductAlertSubscribers(bus *events.Bus, d *Dispatcher, db *gorm.DB) {
+	alerts := repository.NewProductAlertRepository(db)
+
+	events.On(bus, func(ctx context.Context, e events.StockChanged) {
+		if e.OldStock > 0 || e.NewStock <= 0 {
+			return
+		}
+		triggerProductAlerts(ctx, alerts, d, e.ProductID, models.ProductAlertBackInStock, e.ChangedAt, func(models.ProductAlert) (Notification, bool) {
+			return Notification{
+				Event: EventBackInStock,
+				Title: "Back in stock",
+				Body:  fmt.Sprintf("%s is back in stock.", e.Name),
+			}, true
+		})
+	})
+
+	events.On(bus, func(ctx context.Context, e events.PriceChanged) {
+		if e.NewPrice >= e.OldPrice {
+			return
+		}
+		triggerProductAlerts(ctx, alerts, d, e.ProductID, models.ProductAlertPriceDrop, e.ChangedAt, func(alert models.ProductAlert) (Notification, bool) {
+			if alert.TargetPrice == nil || e.NewPrice > *alert.TargetPrice {
+				return Notification{}, false
+			}
+			return Notification{
+				Event: EventPriceDrop,
+				Title: "Price drop",
+				Body:  fmt.Sprintf("%s is now %s.", e.Name, formatAmount(e.NewPrice, "VND")),
+				Data:  map[string]string{"price": strconv.FormatFloat(e.NewPrice, 'f', -1, 64)},
+			}, true
+		})
+	})
+}
+
+// triggerProductAlerts báo các alert đang chờ của sản phẩm mà build chấp nhận; lỗi chỉ được ghi log để
+// không ảnh hưởng thao tác đã phát sự kiện
+func triggerProductAlerts(ctx context.Context, alerts *repository.ProductAlertRepository, d *Dispatcher, productID uint, alertType string, at time.Time,
+	build func(models.ProductAlert) (Notification, bool)) {
+	due, err := alerts.ListDue(ctx, productID, alertType, at)
+	if err != nil {
+		log.Printf("Notify: failed to load %s alerts for product %d: %v", alertType, productID, err)
+		return
+	}
+	for _, alert := range due {
+		n, ok := build(alert)
+		if !ok {
+			continue
+		}
+		marked, err := alerts.MarkTriggered(ctx, alert.ID, at)
+		if err != nil {
+			log.Printf("Notify: failed to mark product alert %d as triggered: %v", alert.ID, err)
+			continue
+		}
+		if !marked {
+			continue
+		}
+		n.UserID = alert.UserID
+		if n.Data == nil {
+			n.Data = map[string]string{}
+		}
+		n.Data["product_id"] = strconv.FormatUint(uint64(productID), 10)
+		n.Data["alert_id"] = strconv.FormatUint(uint64(alert.ID), 10)
+		d.Notify(ctx, n)
+	}
+}
@@ -0,0 +1,330 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidToken được provider trả về khi token thiết bị không còn hợp lệ (app đã gỡ, token hết hạn)
+var ErrInvalidToken = errors.New("device token is no longer valid")
+
+// PushProvider gửi push tới một token thiết bị qua FCM hoặc APNs
+type PushProvider interface {
+	Send(ctx context.Context, token string, n Notification) error
+}
+
+// PushChannel gửi thông báo tới mọi thiết bị đã đăng ký của user
+type PushChannel struct {
+	repo      *repository.NotificationRepository
+	providers map[string]PushProvider
+}
+
+// NewPushChannel tạo kênh push với các provider theo tên (fcm, apns)
+func NewPushChannel(db *gorm.DB, providers map[string]PushProvider) *PushChannel {
+	return &PushChannel{repo: repository.NewNotificationRepository(db), providers: providers}
+}
+
+// NewPushChannelFromEnv cấu hình FCM (FCM_CREDENTIALS_FILE) và APNs (APNS_KEY_FILE, APNS_KEY_ID,
+// APNS_TEAM_ID, APNS_TOPIC). Trả về nil nếu không provider nào được cấu hình.
+func NewPushChannelFromEnv(db *gorm.DB) (*PushChannel, error) {
+	providers := map[string]PushProvider{}
+	if path := os.Getenv("FCM_CREDENTIALS_FILE"); path != "" {
+		fcm, err := NewFCMProvider(path)
+		if err != nil {
+			return nil, fmt.Errorf("fcm: %w", err)
+		}
+		providers["fcm"] = fcm
+	}
+	if path := os.Getenv("APNS_KEY_FILE"); path != "" {
+		apns, err := NewAPNsProvider(path, os.Getenv("APNS_KEY_ID"), os.Getenv("APNS_TEAM_ID"),
+			os.Getenv("APNS_TOPIC"), os.Getenv("APNS_PRODUCTION") == "true")
+		if err != nil {
+			return nil, fmt.Errorf("apns: %w", err)
+		}
+		providers["apns"] = apns
+	}
+	if len(providers) == 0 {
+		return nil, nil
+	}
+	return NewPushChannel(db, providers), nil
+}
+
+func (p *PushChannel) Name() string { return ChannelPush }
+
+// Send gửi tới tất cả thiết bị của user; token không hợp lệ bị xóa
+func (p *PushChannel) Send(ctx context.Context, n Notification) error {
+	devices, err := p.repo.ListDevices(ctx, n.UserID)
+	if err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		return ErrNoRecipient
+	}
+
+	var errs []error
+	for _, device := range devices {
+		provider, ok := p.providers[device.Provider]
+		if !ok {
+			continue
+		}
+		err := provider.Send(ctx, device.Token, n)
+		if errors.Is(err, ErrInvalidToken) {
+			if err := p.repo.DeleteDeviceByToken(ctx, device.Token); err != nil {
+				log.Printf("Notify: failed to remove invalid device token %d: %v", device.ID, err)
+			}
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("device %d: %w", device.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var pushHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// --- FCM (HTTP v1 API) ---
+
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// FCMProvider gửi push qua Firebase Cloud Messaging bằng service account
+type FCMProvider struct {
+	projectID   string
+	clientEmail string
+	tokenURI    string
+	key         *rsa.PrivateKey
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMProvider đọc file JSON service account của Firebase
+func NewFCMProvider(credentialsFile string) (*FCMProvider, error) {
+	raw, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	var creds struct {
+		ProjectID   string `json:"project_id"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(raw, &creds); err != nil {
+		return nil, err
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(creds.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+	if creds.TokenURI == "" {
+		creds.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &FCMProvider{projectID: creds.ProjectID, clientEmail: creds.ClientEmail, tokenURI: creds.TokenURI, key: key}, nil
+}
+
+// token lấy OAuth2 access token (có cache) bằng JWT bearer grant
+func (f *FCMProvider) token(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.accessToken != "" && time.Now().Before(f.expiresAt) {
+		return f.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   f.clientEmail,
+		"scope": fcmScope,
+		"aud":   f.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(f.key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := pushHTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, body)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	f.accessToken = out.AccessToken
+	f.expiresAt = now.Add(time.Duration(out.ExpiresIn)*time.Second - time.Minute)
+	return f.accessToken, nil
+}
+
+func (f *FCMProvider) Send(ctx context.Context, token string, n Notification) error {
+	accessToken, err := f.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token":        token,
+			"notification": map[string]string{"title": n.Title, "body": n.Body},
+			"data":         n.Data,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("https://fcm.googleapis.com/v1/projects/%s/messages:send", f.projectID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := pushHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode == http.StatusNotFound || bytes.Contains(snippet, []byte("UNREGISTERED")) {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("fcm returned status %d: %s", resp.StatusCode, snippet)
+}
+
+// --- APNs (token-based authentication) ---
+
+// APNsProvider gửi push qua Apple Push Notification service bằng khóa .p8
+type APNsProvider struct {
+	keyID  string
+	teamID string
+	topic  string
+	host   string
+	key    *ecdsa.PrivateKey
+
+	mu       sync.Mutex
+	jwt      string
+	issuedAt time.Time
+}
+
+// NewAPNsProvider đọc khóa .p8 và cấu hình bundle ID (topic) của app
+func NewAPNsProvider(keyFile, keyID, teamID, topic string, production bool) (*APNsProvider, error) {
+	if keyID == "" || teamID == "" || topic == "" {
+		return nil, errors.New("APNS_KEY_ID, APNS_TEAM_ID and APNS_TOPIC are required")
+	}
+	raw, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(raw)
+	if err != nil {
+		return nil, err
+	}
+	host := "https://api.sandbox.push.apple.com"
+	if production {
+		host = "https://api.push.apple.com"
+	}
+	return &APNsProvider{keyID: keyID, teamID: teamID, topic: topic, host: host, key: key}, nil
+}
+
+// providerToken trả về JWT xác thực; Apple yêu cầu làm mới trong khoảng 20-60 phút
+func (a *APNsProvider) providerToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.jwt != "" && time.Since(a.issuedAt) < 40*time.Minute {
+		return a.jwt, nil
+	}
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"iss": a.teamID, "iat": now.Unix()})
+	token.Header["kid"] = a.keyID
+	signed, err := token.SignedString(a.key)
+	if err != nil {
+		return "", err
+	}
+	a.jwt, a.issuedAt = signed, now
+	return signed, nil
+}
+
+func (a *APNsProvider) Send(ctx context.Context, token string, n Notification) error {
+	authToken, err := a.providerToken()
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{"title": n.Title, "body": n.Body},
+			"sound": "default",
+		},
+	}
+	for k, v := range n.Data {
+		payload[k] = v
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.host+"/3/device/"+url.PathEscape(token), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+authToken)
+	req.Header.Set("apns-topic", a.topic)
+	req.Header.Set("apns-push-type", "alert")
+
+	resp, err := pushHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode == http.StatusGone || bytes.Contains(snippet, []byte("BadDeviceToken")) {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("apns returned status %d: %s", resp.StatusCode, snippet)
+}
@@ -0,0 +1,111 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+)
+
+// RegisterAlertSubscribers chuyển các sự kiện domain nghiêm trọng trên bus thành cảnh báo cho admin
+func RegisterAlertSubscribers(bus *events.Bus, d *Dispatcher) {
+	events.On(bus, func(ctx context.Context, e events.StockChanged) {
+		if e.NewStock <= 0 && e.OldStock > 0 {
+			d.Alert(ctx, Alert{
+				Kind:     AlertOutOfStock,
+				Severity: SeverityWarning,
+				Title:    "Product out of stock",
+				Message:  fmt.Sprintf("%s is now out of stock", e.Name),
+				Fields:   map[string]string{"product_id": strconv.FormatUint(uint64(e.ProductID), 10)},
+				At:       e.ChangedAt,
+			})
+		}
+	})
+
+	// Cảnh báo khi số lần đăng nhập thất bại tăng đột biến (dò mật khẩu, credential stuffing)
+	failedLogins := NewAlarm(20, 5*time.Minute, 30*time.Minute)
+	events.On(bus, func(ctx context.Context, e events.LoginFailed) {
+		if count, fire := failedLogins.Hit(); fire {
+			d.Alert(ctx, Alert{
+				Kind:     AlertSecurity,
+				Severity: SeverityCritical,
+				Title:    "Spike in failed logins",
+				Message:  fmt.Sprintf("%d failed login attempts in the last 5 minutes", count),
+				Fields:   map[string]string{"last_username": e.Username, "last_ip": e.IP},
+				At:       e.At,
+			})
+		}
+	})
+
+	events.On(bus, func(ctx context.Context, e events.DatabaseFailover) {
+		d.Alert(ctx, Alert{
+			Kind:     AlertDatabaseFailover,
+			Severity: SeverityCritical,
+			Title:    "Database failover",
+			Message:  e.Reason,
+			Fields:   map[string]string{"from": e.From, "to": e.To},
+			At:       e.At,
+		})
+	})
+}
+
+// RegisterOrderSubscribers báo cho khách các bước của đơn hàng (đặt hàng, thanh toán, giao cho
+// đơn vị vận chuyển, đã giao, hủy, hoàn tiền) qua các kênh push/email; thông báo được gửi từ hàng đợi job
+func RegisterOrderSubscribers(bus *events.Bus, d *Dispatcher) {
+	events.On(bus, func(ctx context.Context, e events.OrderPlaced) {
+		d.Notify(ctx, orderNotification(e.Order, "Order confirmation",
+			fmt.Sprintf("Thank you for your order #%d. Total: %s.", e.Order.ID, formatAmount(e.Order.Total, e.Order.Currency))))
+	})
+
+	events.On(bus, func(ctx context.Context, e events.OrderStatusChanged) {
+		order := e.Order
+		var title, body string
+		switch e.To {
+		case models.OrderStatusPaid:
+			title = "Payment received"
+			body = fmt.Sprintf("We have received the payment of %s for order #%d.", formatAmount(order.Total, order.Currency), order.ID)
+		case models.OrderStatusFulfilled:
+			title = "Your order has shipped"
+			body = fmt.Sprintf("Order #%d has been handed to the carrier.", order.ID)
+			if order.Carrier != "" {
+				body += fmt.Sprintf(" Carrier: %s.", order.Carrier)
+			}
+			if order.TrackingNumber != "" {
+				body += fmt.Sprintf(" Tracking number: %s.", order.TrackingNumber)
+			}
+		case models.OrderStatusDelivered:
+			title = "Your order has been delivered"
+			body = fmt.Sprintf("Order #%d has been delivered. Thank you for shopping with us.", order.ID)
+		case models.OrderStatusCancelled:
+			title = "Order cancelled"
+			body = fmt.Sprintf("Order #%d has been cancelled.", order.ID)
+		case models.OrderStatusRefunded:
+			title = "Order refunded"
+			body = fmt.Sprintf("Order #%d has been refunded.", order.ID)
+		default:
+			return
+		}
+		d.Notify(ctx, orderNotification(order, title, body))
+	})
+}
+
+func orderNotification(order models.Order, title, body string) Notification {
+	return Notification{
+		UserID: order.UserID,
+		Event:  EventOrderStatus,
+		Title:  title,
+		Body:   body,
+		Data: map[string]string{
+			"order_id": strconv.FormatUint(uint64(order.ID), 10),
+			"status":   order.Status,
+		},
+	}
+}
+
+// formatAmount định dạng số tiền kèm đơn vị tiền tệ, ví dụ "150000 VND"
+func formatAmount(amount float64, currency string) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64) + " " + currency
+}
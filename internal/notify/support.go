@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+)
+
+// RegisterSupportSubscribers gửi email cho khách khi phiếu hỗ trợ được tạo, được admin trả lời hoặc được
+// admin đánh dấu xong/đóng, và báo cho admin qua kênh cảnh báo khi có phiếu mới hoặc khách trả lời. Email tới
+// địa chỉ trong phiếu vì khách chưa đăng nhập không có tài khoản.
+func RegisterSupportSubscribers(bus *events.Bus, d *Dispatcher) {
+	events.On(bus, func(ctx context.Context, e events.SupportTicketCreated) {
+		t := e.Ticket
+		d.Notify(ctx, supportNotification(t, fmt.Sprintf("We received your request #%d", t.ID),
+			fmt.Sprintf("Thank you for contacting us. Your request \"%s\" has been received and we will reply to this address as soon as possible.", t.Subject)))
+		d.Alert(ctx, supportAlert(t, "New support ticket"))
+	})
+
+	events.On(bus, func(ctx context.Context, e events.SupportTicketReplied) {
+		t := e.Ticket
+		if !e.Message.Staff {
+			d.Alert(ctx, supportAlert(t, "Customer replied to a support ticket"))
+			return
+		}
+		d.Notify(ctx, supportNotification(t, fmt.Sprintf("New reply to your request #%d", t.ID),
+			fmt.Sprintf("Re: %s\n\n%s", t.Subject, e.Message.Body)))
+	})
+
+	events.On(bus, func(ctx context.Context, e events.SupportTicketStatusChanged) {
+		if !e.ByStaff {
+			return
+		}
+		t := e.Ticket
+		switch e.To {
+		case models.SupportTicketResolved:
+			d.Notify(ctx, supportNotification(t, fmt.Sprintf("Your request #%d has been resolved", t.ID),
+				fmt.Sprintf("We have marked your request \"%s\" as resolved. Reply to the ticket if you still need help.", t.Subject)))
+		case models.SupportTicketClosed:
+			d.Notify(ctx, supportNotification(t, fmt.Sprintf("Your request #%d has been closed", t.ID),
+				fmt.Sprintf("Your request \"%s\" has been closed. Contact us again if you need further help.", t.Subject)))
+		}
+	})
+}
+
+func supportNotification(t models.SupportTicket, title, body string) Notification {
+	n := Notification{
+		Event: EventSupport,
+		Title: title,
+		Body:  body,
+		Data: map[string]string{
+			"ticket_id": strconv.FormatUint(uint64(t.ID), 10),
+			"status":    t.Status,
+		},
+		Email: t.Email,
+	}
+	if t.UserID != nil {
+		n.UserID = *t.UserID
+	}
+	return n
+}
+
+func supportAlert(t models.SupportTicket, title string) Alert {
+	return Alert{
+		Kind:     AlertSupportTicket,
+		Severity: SeverityWarning,
+		Title:    title,
+		Message:  fmt.Sprintf("#%d %s", t.ID, t.Subject),
+		Fields:   map[string]string{"ticket_id": strconv.FormatUint(uint64(t.ID), 10), "email": t.Email, "category": t.Category},
+		At:       t.LastMessageAt,
+	}
+}
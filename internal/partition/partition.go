@@ -0,0 +1,216 @@
+package partition
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/database"
+	"gorm.io/gorm"
+)
+
+// Table mô tả một bảng Postgres được phân vùng theo tháng trên một cột thời gian
+type Table struct {
+	Name   string // tên bảng cha
+	Column string // cột thời gian dùng làm khóa phân vùng
+	Ahead  int    // số tháng tạo trước phân vùng (tính cả tháng hiện tại)
+}
+
+// TableStats chứa trạng thái quản lý phân vùng của một bảng
+type TableStats struct {
+	LastRunAt  time.Time `json:"last_run_at"`
+	Partitions []string  `json:"partitions"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+var partitionSuffix = regexp.MustCompile(`_y(\d{4})m(\d{2})$`)
+
+// CreatePartitionedTable tạo bảng cha phân vùng theo RANGE(column) cùng phân vùng
+// DEFAULT để không mất dữ liệu ngoài các khoảng đã tạo.
+// Lưu ý: khóa chính của bảng phân vùng phải bao gồm cột phân vùng.
+func CreatePartitionedTable(db *gorm.DB, name, definition, column string) error {
+	if err := db.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (%s) PARTITION BY RANGE (%s)", name, definition, column,
+	)).Error; err != nil {
+		return err
+	}
+	return db.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s_default PARTITION OF %s DEFAULT", name, name,
+	)).Error
+}
+
+// IsPartitioned kiểm tra bảng có phải bảng phân vùng hay không; luôn false với database khác Postgres
+func IsPartitioned(db *gorm.DB, name string) (bool, error) {
+	if !database.IsPostgres(db) {
+		return false, nil
+	}
+	var count int64
+	err := db.Raw(
+		"SELECT COUNT(*) FROM pg_partitioned_table pt JOIN pg_class c ON c.oid = pt.partrelid WHERE c.relname = ?",
+		name,
+	).Scan(&count).Error
+	return count > 0, err
+}
+
+// PartitionName trả về tên phân vùng tháng chứa thời điểm t
+func PartitionName(table string, t time.Time) string {
+	return fmt.Sprintf("%s_y%04dm%02d", table, t.Year(), int(t.Month()))
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// EnsurePartitions tạo các phân vùng tháng từ tháng hiện tại tới t.Ahead tháng sau
+func EnsurePartitions(db *gorm.DB, t Table, now time.Time) ([]string, error) {
+	ahead := t.Ahead
+	if ahead <= 0 {
+		ahead = 3
+	}
+	var created []string
+	start := monthStart(now.UTC())
+	for i := 0; i < ahead; i++ {
+		from := start.AddDate(0, i, 0)
+		to := from.AddDate(0, 1, 0)
+		name := PartitionName(t.Name, from)
+		err := db.Exec(fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')",
+			name, t.Name, from.Format(time.RFC3339), to.Format(time.RFC3339),
+		)).Error
+		if err != nil {
+			return created, fmt.Errorf("create partition %s: %w", name, err)
+		}
+		created = append(created, name)
+	}
+	return created, nil
+}
+
+// ListPartitions liệt kê các phân vùng con của bảng
+func ListPartitions(db *gorm.DB, table string) ([]string, error) {
+	var names []string
+	err := db.Raw(`
+		SELECT child.relname
+		FROM pg_inherits i
+		JOIN pg_class parent ON parent.oid = i.inhparent
+		JOIN pg_class child ON child.oid = i.inhrelid
+		WHERE parent.relname = ?
+		ORDER BY child.relname`, table,
+	).Scan(&names).Error
+	return names, err
+}
+
+// DropPartitionsBefore xóa các phân vùng tháng nằm hoàn toàn trước mốc cutoff và
+// trả về tổng số dòng đã bị loại bỏ. Phân vùng DEFAULT không bao giờ bị xóa.
+func DropPartitionsBefore(db *gorm.DB, table string, cutoff time.Time) (int64, error) {
+	names, err := ListPartitions(db, table)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, name := range names {
+		m := partitionSuffix.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		from, err := time.Parse("2006-01", m[1]+"-"+m[2])
+		if err != nil {
+			continue
+		}
+		if from.AddDate(0, 1, 0).After(cutoff) {
+			continue
+		}
+
+		var rows int64
+		if err := db.Raw(fmt.Sprintf("SELECT COUNT(*) FROM %s", name)).Scan(&rows).Error; err != nil {
+			return total, err
+		}
+		if err := db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", name)).Error; err != nil {
+			return total, err
+		}
+		total += rows
+	}
+	return total, nil
+}
+
+// Manager định kỳ tạo trước phân vùng cho các bảng đã đăng ký
+type Manager struct {
+	db       *gorm.DB
+	tables   []Table
+	interval time.Duration
+
+	mu    sync.RWMutex
+	stats map[string]*TableStats
+}
+
+// NewManager tạo manager mới
+func NewManager(db *gorm.DB, tables []Table, interval time.Duration) *Manager {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	m := &Manager{
+		db:       db,
+		tables:   tables,
+		interval: interval,
+		stats:    make(map[string]*TableStats),
+	}
+	for _, t := range tables {
+		m.stats[t.Name] = &TableStats{}
+	}
+	return m
+}
+
+// Start chạy EnsureAll ngay lập tức và sau đó theo chu kỳ cho tới khi ctx bị hủy
+func (m *Manager) Start(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	go func() {
+		defer ticker.Stop()
+		m.EnsureAll()
+		for {
+			select {
+			case <-ticker.C:
+				m.EnsureAll()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// EnsureAll tạo phân vùng cho tất cả bảng đã đăng ký
+func (m *Manager) EnsureAll() {
+	for _, t := range m.tables {
+		partitions, err := EnsurePartitions(m.db, t, time.Now())
+		if err != nil {
+			log.Printf("Partition: failed to ensure partitions for %s: %v", t.Name, err)
+		}
+
+		m.mu.Lock()
+		s := m.stats[t.Name]
+		s.LastRunAt = time.Now()
+		s.Partitions = partitions
+		s.LastError = ""
+		if err != nil {
+			s.LastError = err.Error()
+		}
+		m.mu.Unlock()
+	}
+}
+
+// GetStats trả về trạng thái phân vùng của các bảng
+func (m *Manager) GetStats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tables := make(map[string]TableStats, len(m.stats))
+	for name, s := range m.stats {
+		tables[name] = *s
+	}
+	return map[string]interface{}{
+		"interval": m.interval.String(),
+		"tables":   tables,
+	}
+}
@@ -0,0 +1,61 @@
+package password
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// hibpRangeURL là API range của Pwned Passwords; chỉ 5 ký tự đầu của hash SHA-1 được gửi đi
+const hibpRangeURL = "https://api.pwnedpasswords.com/range/"
+
+// breachChecker kiểm tra mật khẩu có trong cơ sở dữ liệu Pwned Passwords theo mô hình k-anonymity:
+// gửi tiền tố hash, so khớp hậu tố trong danh sách trả về ngay tại server
+type breachChecker struct {
+	url    string
+	client *http.Client
+}
+
+func newBreachChecker(url string) *breachChecker {
+	if !strings.HasSuffix(url, "/") {
+		url += "/"
+	}
+	return &breachChecker{url: url, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// Breached cho biết password đã xuất hiện trong ít nhất một vụ lộ dữ liệu
+func (b *breachChecker) Breached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.url+prefix, nil)
+	if err != nil {
+		return false, err
+	}
+	// Padding làm kích thước response không tiết lộ tiền tố được hỏi
+	req.Header.Set("Add-Padding", "true")
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("pwned passwords: unexpected status %d", resp.StatusCode)
+	}
+
+	// Mỗi dòng có dạng HẬU_TỐ:SỐ_LẦN; dòng padding có số lần là 0
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if ok && strings.EqualFold(line, suffix) {
+			return count != "0", nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,18 @@
+package password
+
+// commonPasswords là các mật khẩu phổ biến nhất trong các vụ lộ dữ liệu (so sánh không phân biệt
+// hoa thường). Danh sách bổ sung có thể nạp từ PASSWORD_DENYLIST_FILE.
+var commonPasswords = []string{
+	"123456", "123456789", "12345678", "12345", "1234567", "1234567890", "123123", "111111",
+	"000000", "654321", "666666", "121212", "112233", "123321", "1q2w3e4r", "1q2w3e", "1qaz2wsx",
+	"qwerty", "qwerty123", "qwertyuiop", "asdfgh", "asdfghjkl", "zxcvbnm", "abc123", "abcd1234",
+	"password", "password1", "password123", "passw0rd", "p@ssw0rd", "admin", "admin123", "administrator",
+	"root", "toor", "letmein", "welcome", "welcome1", "login", "changeme", "secret", "default",
+	"iloveyou", "princess", "sunshine", "monkey", "dragon", "football", "baseball", "superman",
+	"batman", "master", "shadow", "michael", "jennifer", "charlie", "starwars", "freedom", "whatever",
+	"trustno1", "hello123", "qazwsx", "killer", "pokemon", "computer", "internet", "samsung",
+	"iphone", "google", "facebook", "zaq12wsx", "987654321", "88888888", "11111111", "00000000",
+	"12341234", "123qwe", "qwe123", "aa123456", "a123456", "a12345678", "123456a", "123456789a",
+	"matkhau", "matkhau123", "anhyeuem", "emyeuanh", "yeuem", "iloveyou123", "vietnam", "saigon",
+	"hanoi", "shop123", "shopping", "customer", "user123", "test", "test123", "guest",
+}
@@ -0,0 +1,119 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrMismatch được trả về khi mật khẩu không khớp với hash
+	ErrMismatch = errors.New("password does not match")
+	// ErrUnknownHash được trả về khi hash không thuộc thuật toán nào được hỗ trợ
+	ErrUnknownHash = errors.New("unknown password hash format")
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// Hasher băm mật khẩu theo cấu hình config.PasswordHash
+type Hasher struct {
+	config config.PasswordHash
+}
+
+func NewHasher(cfg config.PasswordHash) *Hasher {
+	return &Hasher{config: cfg}
+}
+
+// Hash băm mật khẩu theo thuật toán đang cấu hình
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.config.Algorithm == config.PasswordHashArgon2id {
+		salt := make([]byte, argon2SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		p := argon2Params{memory: h.config.Argon2Memory, iterations: h.config.Argon2Iterations, parallelism: h.config.Argon2Parallelism}
+		key := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, argon2KeyLength)
+		return p.encode(salt, key), nil
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.config.BcryptCost)
+	return string(hashed), err
+}
+
+// NeedsRehash cho biết hash được tạo bằng thuật toán hoặc tham số khác cấu hình hiện tại
+func (h *Hasher) NeedsRehash(hash string) bool {
+	if p, _, _, err := decodeArgon2(hash); err == nil {
+		return h.config.Algorithm != config.PasswordHashArgon2id ||
+			p.memory != h.config.Argon2Memory || p.iterations != h.config.Argon2Iterations || p.parallelism != h.config.Argon2Parallelism
+	}
+	if cost, err := bcrypt.Cost([]byte(hash)); err == nil {
+		return h.config.Algorithm != config.PasswordHashBcrypt || cost != h.config.BcryptCost
+	}
+	return true
+}
+
+// Compare kiểm tra mật khẩu với hash bcrypt hoặc Argon2id (nhận diện theo định dạng hash),
+// trả về ErrMismatch nếu không khớp
+func Compare(hash, password string) error {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		p, salt, key, err := decodeArgon2(hash)
+		if err != nil {
+			return err
+		}
+		other := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, uint32(len(key)))
+		if subtle.ConstantTimeCompare(key, other) != 1 {
+			return ErrMismatch
+		}
+		return nil
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	switch {
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return ErrMismatch
+	case err != nil:
+		return fmt.Errorf("%w: %v", ErrUnknownHash, err)
+	}
+	return nil
+}
+
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// encode dựng hash theo định dạng PHC: $argon2id$v=19$m=<KiB>,t=<lần lặp>,p=<luồng>$<salt>$<key>
+func (p argon2Params) encode(salt, key []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.memory, p.iterations, p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+func decodeArgon2(hash string) (p argon2Params, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, ErrUnknownHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("%w: unsupported argon2 version", ErrUnknownHash)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return p, nil, nil, fmt.Errorf("%w: %v", ErrUnknownHash, err)
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return p, nil, nil, fmt.Errorf("%w: %v", ErrUnknownHash, err)
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(key) == 0 {
+		return p, nil, nil, fmt.Errorf("%w: invalid key", ErrUnknownHash)
+	}
+	return p, salt, key, nil
+}
@@ -0,0 +1,221 @@
+// Package password kiểm tra mật khẩu mới theo chính sách: độ dài, các loại ký tự bắt buộc,
+// danh sách mật khẩu phổ biến và (tùy chọn) cơ sở dữ liệu mật khẩu đã bị lộ của Have I Been Pwned.
+package password
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/i18n"
+)
+
+// MaxLength là độ dài tối đa (byte) của mật khẩu; bcrypt bỏ qua phần sau 72 byte
+const MaxLength = 72
+
+// Các loại ký tự có thể bắt buộc trong PASSWORD_REQUIRED_CLASSES
+const (
+	ClassLower  = "lower"
+	ClassUpper  = "upper"
+	ClassLetter = "letter"
+	ClassDigit  = "digit"
+	ClassSymbol = "symbol"
+)
+
+// Mã vi phạm trả về cho client
+const (
+	CodeTooShort      = "too_short"
+	CodeTooLong       = "too_long"
+	CodeMissingClass  = "missing_class"
+	CodeCommon        = "common"
+	CodeContainsInput = "contains_user_info"
+	CodeBreached      = "breached"
+)
+
+// Config là cấu hình chính sách mật khẩu
+type Config struct {
+	MinLength int
+	// RequiredClasses là các loại ký tự mật khẩu phải có (ClassLower, ClassDigit, ...)
+	RequiredClasses []string
+	// DenylistFile là file bổ sung danh sách mật khẩu bị cấm, mỗi dòng một mật khẩu
+	DenylistFile string
+	// BreachCheck bật kiểm tra mật khẩu đã bị lộ qua API k-anonymity của Have I Been Pwned
+	BreachCheck bool
+	// BreachAPIURL là địa chỉ API range của HIBP (đổi được để dùng mirror nội bộ)
+	BreachAPIURL string
+}
+
+// ConfigFromEnv đọc PASSWORD_MIN_LENGTH (mặc định 8), PASSWORD_REQUIRED_CLASSES (danh sách phân cách
+// bằng dấu phẩy, mặc định letter,digit), PASSWORD_DENYLIST_FILE, PASSWORD_BREACH_CHECK (true/false)
+// và PASSWORD_BREACH_API_URL
+func ConfigFromEnv() Config {
+	cfg := Config{
+		MinLength:    config.Int("PASSWORD_MIN_LENGTH", 8),
+		DenylistFile: os.Getenv("PASSWORD_DENYLIST_FILE"),
+		BreachCheck:  os.Getenv("PASSWORD_BREACH_CHECK") == "true",
+		BreachAPIURL: config.String("PASSWORD_BREACH_API_URL", hibpRangeURL),
+	}
+	for _, class := range strings.Split(config.String("PASSWORD_REQUIRED_CLASSES", ClassLetter+","+ClassDigit), ",") {
+		switch class = strings.TrimSpace(class); class {
+		case "":
+		case ClassLower, ClassUpper, ClassLetter, ClassDigit, ClassSymbol:
+			cfg.RequiredClasses = append(cfg.RequiredClasses, class)
+		default:
+			log.Printf("Warning: unknown character class %q in PASSWORD_REQUIRED_CLASSES, ignoring", class)
+		}
+	}
+	return cfg
+}
+
+// Violation là một điều kiện mật khẩu không đáp ứng
+type Violation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Class là loại ký tự còn thiếu (chỉ với CodeMissingClass)
+	Class string `json:"class,omitempty"`
+	// Min là độ dài tối thiểu (chỉ với CodeTooShort)
+	Min int `json:"min,omitempty"`
+
+	format string
+	args   []interface{}
+}
+
+func newViolation(code, format string, args ...interface{}) Violation {
+	return Violation{Code: code, Message: fmt.Sprintf(format, args...), format: format, args: args}
+}
+
+// Localize dịch thông báo của các vi phạm sang ngôn ngữ lang
+func Localize(lang string, violations []Violation) []Violation {
+	out := make([]Violation, len(violations))
+	for i, v := range violations {
+		out[i] = v
+		out[i].Message = i18n.T(lang, v.format, v.args...)
+	}
+	return out
+}
+
+// Policy kiểm tra mật khẩu theo Config
+type Policy struct {
+	config   Config
+	denylist map[string]struct{}
+	breaches *breachChecker
+}
+
+// NewPolicy tạo policy; file denylist không đọc được chỉ được ghi log cảnh báo
+func NewPolicy(cfg Config) *Policy {
+	p := &Policy{config: cfg, denylist: make(map[string]struct{}, len(commonPasswords))}
+	for _, pw := range commonPasswords {
+		p.denylist[strings.ToLower(pw)] = struct{}{}
+	}
+	if cfg.DenylistFile != "" {
+		if err := p.loadDenylist(cfg.DenylistFile); err != nil {
+			log.Printf("Warning: failed to load PASSWORD_DENYLIST_FILE %q: %v", cfg.DenylistFile, err)
+		}
+	}
+	if cfg.BreachCheck {
+		p.breaches = newBreachChecker(cfg.BreachAPIURL)
+	}
+	return p
+}
+
+func (p *Policy) loadDenylist(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			p.denylist[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	return scanner.Err()
+}
+
+// Check trả về các điều kiện mà password không đáp ứng (rỗng nếu hợp lệ). userInputs là thông tin
+// của chính user (username, email, họ tên); mật khẩu chứa các giá trị này bị từ chối. Lỗi khi gọi
+// API kiểm tra mật khẩu bị lộ chỉ được ghi log để sự cố của dịch vụ ngoài không chặn đăng ký.
+func (p *Policy) Check(ctx context.Context, password string, userInputs ...string) []Violation {
+	var violations []Violation
+	if n := utf8.RuneCountInString(password); n < p.config.MinLength {
+		v := newViolation(CodeTooShort, "Password must be at least %d characters long.", p.config.MinLength)
+		v.Min = p.config.MinLength
+		violations = append(violations, v)
+	}
+	if len(password) > MaxLength {
+		violations = append(violations, newViolation(CodeTooLong, "Password must be at most %d bytes long.", MaxLength))
+	}
+	for _, class := range p.config.RequiredClasses {
+		if !hasClass(password, class) {
+			violations = append(violations, missingClass(class))
+		}
+	}
+
+	lower := strings.ToLower(password)
+	if _, ok := p.denylist[lower]; ok {
+		violations = append(violations, newViolation(CodeCommon, "Password is too common."))
+	}
+	for _, input := range userInputs {
+		// Chỉ xét phần trước @ của email; giá trị quá ngắn dễ trùng ngẫu nhiên nên bỏ qua
+		input, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(input)), "@")
+		if utf8.RuneCountInString(input) >= 3 && strings.Contains(lower, input) {
+			violations = append(violations, newViolation(CodeContainsInput, "Password must not contain your username, email or name."))
+			break
+		}
+	}
+
+	// Chỉ gọi API khi mật khẩu đã qua các điều kiện cục bộ
+	if len(violations) == 0 && p.breaches != nil {
+		breached, err := p.breaches.Breached(ctx, password)
+		if err != nil {
+			log.Printf("Password: breach check failed: %v", err)
+		} else if breached {
+			violations = append(violations, newViolation(CodeBreached, "Password has appeared in a data breach, please choose another one."))
+		}
+	}
+	return violations
+}
+
+func hasClass(password, class string) bool {
+	var match func(rune) bool
+	switch class {
+	case ClassLower:
+		match = unicode.IsLower
+	case ClassUpper:
+		match = unicode.IsUpper
+	case ClassLetter:
+		match = unicode.IsLetter
+	case ClassDigit:
+		match = unicode.IsDigit
+	case ClassSymbol:
+		match = func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r) }
+	default:
+		return true
+	}
+	return strings.IndexFunc(password, match) >= 0
+}
+
+func missingClass(class string) Violation {
+	var v Violation
+	switch class {
+	case ClassLower:
+		v = newViolation(CodeMissingClass, "Password must contain a lowercase letter.")
+	case ClassUpper:
+		v = newViolation(CodeMissingClass, "Password must contain an uppercase letter.")
+	case ClassLetter:
+		v = newViolation(CodeMissingClass, "Password must contain a letter.")
+	case ClassDigit:
+		v = newViolation(CodeMissingClass, "Password must contain a digit.")
+	default:
+		v = newViolation(CodeMissingClass, "Password must contain a symbol.")
+	}
+	v.Class = class
+	return v
+}
@@ -0,0 +1,222 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Địa chỉ API của MoMo theo chế độ
+var momoEndpoints = map[string]string{
+	ModeSandbox:    "https://test-payment.momo.vn",
+	ModeProduction: "https://payment.momo.vn",
+}
+
+var momoHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// MoMoGateway thu tiền qua ví MoMo (captureWallet): khách được chuyển tới payUrl của MoMo, kết quả
+// được MoMo gửi tới IPN URL (POST JSON) và kèm theo khi chuyển khách về redirect URL (query string).
+type MoMoGateway struct {
+	partnerCode string
+	accessKey   string
+	secretKey   string
+	redirectURL string
+	ipnURL      string
+	baseURL     string
+}
+
+// NewMoMoGateway tạo gateway MoMo với thông tin tích hợp của merchant; mode chọn sandbox hoặc production
+func NewMoMoGateway(partnerCode, accessKey, secretKey, redirectURL, ipnURL, mode string) *MoMoGateway {
+	baseURL, ok := momoEndpoints[mode]
+	if !ok {
+		baseURL = momoEndpoints[ModeSandbox]
+	}
+	return &MoMoGateway{
+		partnerCode: partnerCode,
+		accessKey:   accessKey,
+		secretKey:   secretKey,
+		redirectURL: redirectURL,
+		ipnURL:      ipnURL,
+		baseURL:     baseURL,
+	}
+}
+
+// momoFromEnv cấu hình MoMo từ MOMO_PARTNER_CODE, MOMO_ACCESS_KEY, MOMO_SECRET_KEY, MOMO_REDIRECT_URL
+// và MOMO_IPN_URL; nil nếu thiếu
+func momoFromEnv(mode string) *MoMoGateway {
+	values := []string{
+		os.Getenv("MOMO_PARTNER_CODE"), os.Getenv("MOMO_ACCESS_KEY"), os.Getenv("MOMO_SECRET_KEY"),
+		os.Getenv("MOMO_REDIRECT_URL"), os.Getenv("MOMO_IPN_URL"),
+	}
+	for _, v := range values {
+		if v == "" {
+			return nil
+		}
+	}
+	return NewMoMoGateway(values[0], values[1], values[2], values[3], values[4], mode)
+}
+
+func (m *MoMoGateway) Name() string { return "momo" }
+
+// CreatePayment tạo giao dịch ví MoMo; mã tham chiếu (orderId phía MoMo) là ID của Payment
+func (m *MoMoGateway) CreatePayment(ctx context.Context, req PaymentRequest) (*Intent, error) {
+	if !strings.EqualFold(req.Currency, "VND") {
+		return nil, fmt.Errorf("%w: momo only accepts VND", ErrUnsupportedCurrency)
+	}
+	reference := strconv.FormatUint(uint64(req.PaymentID), 10)
+	amount := int64(math.Round(req.Amount))
+	body := map[string]interface{}{
+		"partnerCode": m.partnerCode,
+		"requestId":   reference,
+		"amount":      amount,
+		"orderId":     reference,
+		"orderInfo":   req.Description,
+		"redirectUrl": m.redirectURL,
+		"ipnUrl":      m.ipnURL,
+		"requestType": "captureWallet",
+		"extraData":   "",
+		"lang":        "vi",
+	}
+	body["signature"] = m.sign(fmt.Sprintf(
+		"accessKey=%s&amount=%d&extraData=&ipnUrl=%s&orderId=%s&orderInfo=%s&partnerCode=%s&redirectUrl=%s&requestId=%s&requestType=captureWallet",
+		m.accessKey, amount, m.ipnURL, reference, req.Description, m.partnerCode, m.redirectURL, reference))
+
+	var out struct {
+		ResultCode int    `json:"resultCode"`
+		Message    string `json:"message"`
+		PayURL     string `json:"payUrl"`
+	}
+	if err := postJSON(ctx, momoHTTPClient, m.baseURL+"/v2/gateway/api/create", body, &out); err != nil {
+		return nil, err
+	}
+	if out.ResultCode != 0 {
+		return nil, fmt.Errorf("momo create payment failed with code %d: %s", out.ResultCode, out.Message)
+	}
+	return &Intent{Reference: reference, Status: "redirect", RedirectURL: out.PayURL}, nil
+}
+
+// momoResult là các trường kết quả thanh toán MoMo gửi qua IPN và redirect URL
+type momoResult struct {
+	PartnerCode  string      `json:"partnerCode"`
+	OrderID      string      `json:"orderId"`
+	RequestID    string      `json:"requestId"`
+	Amount       json.Number `json:"amount"`
+	OrderInfo    string      `json:"orderInfo"`
+	OrderType    string      `json:"orderType"`
+	TransID      json.Number `json:"transId"`
+	ResultCode   json.Number `json:"resultCode"`
+	Message      string      `json:"message"`
+	PayType      string      `json:"payType"`
+	ResponseTime json.Number `json:"responseTime"`
+	ExtraData    string      `json:"extraData"`
+	Signature    string      `json:"signature"`
+}
+
+// ParseWebhook xác thực chữ ký của IPN (body JSON) hoặc của redirect URL (query string)
+func (m *MoMoGateway) ParseWebhook(r *http.Request, body []byte) (*WebhookEvent, error) {
+	var res momoResult
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &res); err != nil {
+			return nil, fmt.Errorf("invalid momo payload: %w", err)
+		}
+	} else {
+		q := r.URL.Query()
+		res = momoResult{
+			PartnerCode: q.Get("partnerCode"), OrderID: q.Get("orderId"), RequestID: q.Get("requestId"),
+			Amount: json.Number(q.Get("amount")), OrderInfo: q.Get("orderInfo"), OrderType: q.Get("orderType"),
+			TransID: json.Number(q.Get("transId")), ResultCode: json.Number(q.Get("resultCode")),
+			Message: q.Get("message"), PayType: q.Get("payType"), ResponseTime: json.Number(q.Get("responseTime")),
+			ExtraData: q.Get("extraData"), Signature: q.Get("signature"),
+		}
+	}
+
+	raw := fmt.Sprintf(
+		"accessKey=%s&amount=%s&extraData=%s&message=%s&orderId=%s&orderInfo=%s&orderType=%s&partnerCode=%s&payType=%s&requestId=%s&responseTime=%s&resultCode=%s&transId=%s",
+		m.accessKey, res.Amount, res.ExtraData, res.Message, res.OrderID, res.OrderInfo, res.OrderType,
+		res.PartnerCode, res.PayType, res.RequestID, res.ResponseTime, res.ResultCode, res.TransID)
+	if res.Signature == "" || !hmac.Equal([]byte(res.Signature), []byte(m.sign(raw))) || res.PartnerCode != m.partnerCode {
+		return nil, ErrInvalidSignature
+	}
+
+	amount, _ := res.Amount.Float64()
+	event := &WebhookEvent{
+		ID:            res.TransID.String(),
+		Reference:     res.OrderID,
+		TransactionID: res.TransID.String(),
+		Amount:        amount,
+	}
+	switch res.ResultCode.String() {
+	case "0":
+		event.Type = WebhookPaymentSucceeded
+	case "9000":
+		// Giao dịch mới được ủy quyền (chưa trừ tiền); chờ kết quả cuối cùng
+	default:
+		event.Type = WebhookPaymentFailed
+		event.FailureReason = fmt.Sprintf("momo result code %s: %s", res.ResultCode, res.Message)
+	}
+	return event, nil
+}
+
+// WebhookResponse trả lời IPN theo yêu cầu của MoMo: HTTP 204 khi đã nhận, mã lỗi để MoMo gửi lại
+func (m *MoMoGateway) WebhookResponse(err error) (int, interface{}) {
+	switch {
+	case err == nil, errors.Is(err, ErrAlreadyProcessed), errors.Is(err, ErrPaymentNotFound):
+		return http.StatusNoContent, nil
+	case errors.Is(err, ErrInvalidSignature), errors.Is(err, ErrAmountMismatch):
+		return http.StatusBadRequest, nil
+	default:
+		return http.StatusInternalServerError, nil
+	}
+}
+
+// Refund gọi API hoàn tiền của MoMo; cần transId của giao dịch gốc
+func (m *MoMoGateway) Refund(ctx context.Context, req RefundRequest) (*RefundResult, error) {
+	transID, err := strconv.ParseInt(req.TransactionID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("momo refund requires the original transaction id: %w", err)
+	}
+	refundID := fmt.Sprintf("%s-refund-%d", req.Reference, time.Now().UnixNano()%1_000_000)
+	amount := int64(math.Round(req.Amount))
+	body := map[string]interface{}{
+		"partnerCode": m.partnerCode,
+		"orderId":     refundID,
+		"requestId":   refundID,
+		"amount":      amount,
+		"transId":     transID,
+		"lang":        "vi",
+		"description": req.Reason,
+	}
+	body["signature"] = m.sign(fmt.Sprintf(
+		"accessKey=%s&amount=%d&description=%s&orderId=%s&partnerCode=%s&requestId=%s&transId=%d",
+		m.accessKey, amount, req.Reason, refundID, m.partnerCode, refundID, transID))
+
+	var out struct {
+		ResultCode int         `json:"resultCode"`
+		Message    string      `json:"message"`
+		TransID    json.Number `json:"transId"`
+	}
+	if err := postJSON(ctx, momoHTTPClient, m.baseURL+"/v2/gateway/api/refund", body, &out); err != nil {
+		return nil, err
+	}
+	if out.ResultCode != 0 {
+		return nil, fmt.Errorf("momo refund failed with code %d: %s", out.ResultCode, out.Message)
+	}
+	return &RefundResult{Reference: out.TransID.String(), Status: "succeeded"}, nil
+}
+
+// sign tính HMAC-SHA256 (hex) của chuỗi dữ liệu bằng secret key
+func (m *MoMoGateway) sign(data string) string {
+	mac := hmac.New(sha256.New, []byte(m.secretKey))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
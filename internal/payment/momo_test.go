@@ -0,0 +1,78 @@
+package payment
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// momoIPN là IPN thanh toán thành công, ký bằng secret key "K951B6PE1waDMi640xX08PD3vg6EkVlz" và access key
+// "F8BBA842ECF85" (HMAC-SHA256 tính độc lập)
+const momoIPN = `{"partnerCode":"MOMO","orderId":"42","requestId":"42","amount":100000,` +
+	`"orderInfo":"Thanh toan don hang 42","orderType":"momo_wallet","transId":4088878653,"resultCode":0,` +
+	`"message":"Successful.","payType":"qr","responseTime":1760000000000,"extraData":"",` +
+	`"signature":"5db6df4790de54e662d4f480af17c73c5d9c5f5bd9454c04a780428d2a3f1e54"}`
+
+func newTestMoMo(secretKey string) *MoMoGateway {
+	return NewMoMoGateway("MOMO", "F8BBA842ECF85", secretKey, "https://shop.example/return", "https://shop.example/ipn", ModeSandbox)
+}
+
+func TestMoMoWebhook(t *testing.T) {
+	want := WebhookEvent{ID: "4088878653", Type: WebhookPaymentSucceeded, Reference: "42", TransactionID: "4088878653", Amount: 100000}
+	gateway := newTestMoMo("K951B6PE1waDMi640xX08PD3vg6EkVlz")
+
+	event, err := gateway.ParseWebhook(httptest.NewRequest(http.MethodPost, "/webhooks/momo", nil), []byte(momoIPN))
+	if err != nil {
+		t.Fatalf("ParseWebhook(IPN): %v", err)
+	}
+	if *event != want {
+		t.Errorf("IPN event = %+v, want %+v", *event, want)
+	}
+
+	// Redirect URL mang cùng các trường trên query string
+	query := url.Values{
+		"partnerCode": {"MOMO"}, "orderId": {"42"}, "requestId": {"42"}, "amount": {"100000"},
+		"orderInfo": {"Thanh toan don hang 42"}, "orderType": {"momo_wallet"}, "transId": {"4088878653"},
+		"resultCode": {"0"}, "message": {"Successful."}, "payType": {"qr"}, "responseTime": {"1760000000000"},
+		"extraData": {""}, "signature": {"5db6df4790de54e662d4f480af17c73c5d9c5f5bd9454c04a780428d2a3f1e54"},
+	}
+	event, err = gateway.ParseWebhook(httptest.NewRequest(http.MethodGet, "/webhooks/momo?"+query.Encode(), nil), nil)
+	if err != nil {
+		t.Fatalf("ParseWebhook(redirect): %v", err)
+	}
+	if *event != want {
+		t.Errorf("redirect event = %+v, want %+v", *event, want)
+	}
+}
+
+func TestMoMoWebhookBadSignature(t *testing.T) {
+	gateway := newTestMoMo("K951B6PE1waDMi640xX08PD3vg6EkVlz")
+	tests := map[string]string{
+		"tampered amount": strings.Replace(momoIPN, `"amount":100000`, `"amount":1000`, 1),
+		"tampered result": strings.Replace(momoIPN, `"resultCode":0`, `"resultCode":1006`, 1),
+		"tampered order":  strings.Replace(momoIPN, `"orderId":"42"`, `"orderId":"43"`, 1),
+		"missing":         strings.Replace(momoIPN, `"signature":"5db6df4790de54e662d4f480af17c73c5d9c5f5bd9454c04a780428d2a3f1e54"`, `"signature":""`, 1),
+	}
+	for name, body := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/momo", nil)
+		if _, err := gateway.ParseWebhook(req, []byte(body)); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: err = %v, want ErrInvalidSignature", name, err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/momo", nil)
+	if _, err := newTestMoMo("other-secret").ParseWebhook(req, []byte(momoIPN)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("other secret: err = %v, want ErrInvalidSignature", err)
+	}
+	// Chữ ký đúng nhưng của merchant khác
+	other := NewMoMoGateway("OTHER", "F8BBA842ECF85", "K951B6PE1waDMi640xX08PD3vg6EkVlz", "", "", ModeSandbox)
+	if _, err := other.ParseWebhook(req, []byte(momoIPN)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("other partner: err = %v, want ErrInvalidSignature", err)
+	}
+	if status, _ := gateway.WebhookResponse(ErrInvalidSignature); status != http.StatusBadRequest {
+		t.Errorf("WebhookResponse status = %d, want 400", status)
+	}
+}
@@ -0,0 +1,204 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+var (
+	// ErrNotConfigured được trả về khi chưa cấu hình nhà cung cấp thanh toán
+	ErrNotConfigured = errors.New("payment provider is not configured")
+	// ErrInvalidMethod được provider trả về khi token phương thức thanh toán không hợp lệ hoặc đã hết hạn
+	ErrInvalidMethod = errors.New("payment method token is invalid")
+	// ErrDeclined được trả về khi ngân hàng hoặc gateway từ chối giao dịch
+	ErrDeclined = errors.New("payment was declined")
+	// ErrInvalidSignature được trả về khi chữ ký của webhook/callback không hợp lệ
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrUnsupportedCurrency được trả về khi gateway không thu được loại tiền của đơn hàng
+	ErrUnsupportedCurrency = errors.New("currency is not supported by the payment provider")
+
+	// Các lỗi khi áp dụng webhook, dùng để gateway trả lời theo định dạng riêng (WebhookResponder)
+	ErrPaymentNotFound  = errors.New("payment not found")
+	ErrAmountMismatch   = errors.New("paid amount does not match the payment")
+	ErrAlreadyProcessed = errors.New("payment has already been processed")
+)
+
+// Chế độ kết nối tới các cổng thanh toán nội địa (VNPay, MoMo)
+const (
+	ModeSandbox    = "sandbox"
+	ModeProduction = "production"
+)
+
+// ModeFromEnv đọc PAYMENT_MODE; mặc định là sandbox để môi trường chưa cấu hình không thu tiền thật
+func ModeFromEnv() string {
+	if os.Getenv("PAYMENT_MODE") == ModeProduction {
+		return ModeProduction
+	}
+	return ModeSandbox
+}
+
+// Customer là thông tin user gửi lên provider khi tạo customer
+type Customer struct {
+	UserID   uint
+	Email    string
+	FullName string
+}
+
+// Method là thông tin hiển thị của một phương thức thanh toán đã token hóa.
+// Số thẻ đầy đủ không bao giờ đi qua server: client token hóa thẻ bằng SDK của provider
+// rồi chỉ gửi ID phương thức (ví dụ pm_...) lên API.
+type Method struct {
+	ID       string
+	Type     string
+	Brand    string
+	Last4    string
+	ExpMonth int
+	ExpYear  int
+}
+
+// Provider lưu phương thức thanh toán của khách tại nhà cung cấp thanh toán
+type Provider interface {
+	Name() string
+	// CreateCustomer tạo customer tại provider, trả về ID customer
+	CreateCustomer(ctx context.Context, customer Customer) (string, error)
+	// AttachMethod gắn phương thức đã token hóa vào customer và trả về thông tin hiển thị
+	AttachMethod(ctx context.Context, customerID, methodID string) (*Method, error)
+	// DetachMethod gỡ phương thức khỏi customer
+	DetachMethod(ctx context.Context, methodID string) error
+	// SetDefaultMethod đặt phương thức mặc định của customer (dùng cho thanh toán định kỳ)
+	SetDefaultMethod(ctx context.Context, customerID, methodID string) error
+	// DeleteCustomer xóa customer cùng các phương thức đã lưu (khi ẩn danh hóa tài khoản)
+	DeleteCustomer(ctx context.Context, customerID string) error
+}
+
+// Kết quả của một webhook thanh toán
+const (
+	WebhookPaymentSucceeded = "payment.succeeded"
+	WebhookPaymentFailed    = "payment.failed"
+)
+
+// PaymentRequest là yêu cầu thu tiền cho một đơn hàng
+type PaymentRequest struct {
+	// PaymentID là ID bản ghi Payment; gateway không tự sinh mã giao dịch dùng nó làm mã tham chiếu
+	PaymentID   uint
+	OrderID     uint
+	Amount      float64
+	Currency    string
+	Description string
+	// CreatedAt là thời điểm tạo Payment; VNPay cần lại giá trị này khi hoàn tiền
+	CreatedAt time.Time
+	// ClientIP là IP của khách, bắt buộc với VNPay
+	ClientIP string
+	// IdempotencyKey bảo đảm gửi lại cùng yêu cầu không tạo giao dịch thứ hai
+	IdempotencyKey string
+	// CustomerID và MethodID (tùy chọn) thanh toán ngay bằng phương thức đã lưu
+	CustomerID string
+	MethodID   string
+}
+
+// Intent là giao dịch đã tạo tại gateway. Client hoàn tất thanh toán bằng ClientSecret (SDK của
+// gateway) hoặc bằng cách chuyển tới RedirectURL.
+type Intent struct {
+	Reference string
+	Status    string
+	// Paid là true khi giao dịch đã thu tiền ngay (ví dụ thẻ đã lưu không cần xác thực thêm)
+	Paid         bool
+	ClientSecret string
+	RedirectURL  string
+}
+
+// RefundRequest là yêu cầu hoàn tiền cho một giao dịch đã thu
+type RefundRequest struct {
+	Reference     string
+	TransactionID string    // mã giao dịch phía gateway nhận từ webhook (VNPay, MoMo)
+	CreatedAt     time.Time // thời điểm tạo giao dịch gốc
+	Amount        float64   // theo đơn vị tiền tệ, không phải đơn vị nhỏ nhất
+	Currency      string
+	Partial       bool // hoàn một phần số tiền đã thu
+	Reason        string
+	RequestedBy   string
+	ClientIP      string
+}
+
+// RefundResult là kết quả hoàn tiền tại gateway
+type RefundResult struct {
+	Reference string
+	Status    string
+}
+
+// WebhookEvent là thông báo kết quả thanh toán từ gateway đã được xác thực chữ ký.
+// Type rỗng nghĩa là sự kiện không liên quan và được bỏ qua.
+type WebhookEvent struct {
+	ID            string
+	Type          string
+	Reference     string
+	TransactionID string
+	// Amount là số tiền gateway báo đã thu (0 nếu gateway không gửi), dùng để đối chiếu với Payment
+	Amount        float64
+	FailureReason string
+}
+
+// Gateway thu tiền và hoàn tiền cho đơn hàng qua một cổng thanh toán
+type Gateway interface {
+	Name() string
+	// CreatePayment tạo giao dịch thu tiền cho đơn hàng
+	CreatePayment(ctx context.Context, req PaymentRequest) (*Intent, error)
+	// Refund hoàn tiền (toàn bộ hoặc một phần) giao dịch đã thu
+	Refund(ctx context.Context, req RefundRequest) (*RefundResult, error)
+	// ParseWebhook xác thực chữ ký và đọc kết quả thanh toán từ webhook (IPN) hoặc từ request
+	// khách được chuyển về sau khi thanh toán (return URL) của gateway
+	ParseWebhook(r *http.Request, body []byte) (*WebhookEvent, error)
+}
+
+// WebhookResponder được gateway cài đặt khi cần trả lời webhook theo định dạng riêng.
+// err là kết quả xử lý webhook (nil, ErrInvalidSignature, ErrPaymentNotFound, ...).
+type WebhookResponder interface {
+	WebhookResponse(err error) (status int, body interface{})
+}
+
+// Gateways là các cổng thanh toán đã cấu hình, theo tên
+type Gateways map[string]Gateway
+
+// Get lấy gateway theo tên; trả về ErrNotConfigured nếu gateway chưa được cấu hình
+func (g Gateways) Get(name string) (Gateway, error) {
+	if gateway, ok := g[name]; ok {
+		return gateway, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotConfigured, name)
+}
+
+// stripeFromEnv cấu hình Stripe từ STRIPE_SECRET_KEY và STRIPE_WEBHOOK_SECRET; nil nếu chưa cấu hình
+func stripeFromEnv() *StripeProvider {
+	if key := os.Getenv("STRIPE_SECRET_KEY"); key != "" {
+		return NewStripeProvider(key, os.Getenv("STRIPE_WEBHOOK_SECRET"))
+	}
+	return nil
+}
+
+// ProviderFromEnv cấu hình provider lưu phương thức thanh toán; trả về nil nếu chưa cấu hình
+func ProviderFromEnv() Provider {
+	if stripe := stripeFromEnv(); stripe != nil {
+		return stripe
+	}
+	return nil
+}
+
+// GatewaysFromEnv cấu hình các cổng thanh toán có đủ biến môi trường
+func GatewaysFromEnv() Gateways {
+	gateways := Gateways{}
+	if stripe := stripeFromEnv(); stripe != nil {
+		gateways[stripe.Name()] = stripe
+	}
+	mode := ModeFromEnv()
+	if vnpay := vnpayFromEnv(mode); vnpay != nil {
+		gateways[vnpay.Name()] = vnpay
+	}
+	if momo := momoFromEnv(mode); momo != nil {
+		gateways[momo.Name()] = momo
+	}
+	return gateways
+}
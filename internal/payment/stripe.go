@@ -0,0 +1,283 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const stripeAPIBase = "https://api.stripe.com/v1"
+
+var stripeHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// stripeSignatureTolerance là độ lệch tối đa giữa thời điểm ký webhook và hiện tại
+const stripeSignatureTolerance = 5 * time.Minute
+
+// zeroDecimalCurrencies là các loại tiền Stripe tính theo đơn vị nguyên (không nhân 100)
+var zeroDecimalCurrencies = map[string]bool{
+	"bif": true, "clp": true, "djf": true, "gnf": true, "jpy": true, "kmf": true, "krw": true, "mga": true,
+	"pyg": true, "rwf": true, "ugx": true, "vnd": true, "vuv": true, "xaf": true, "xof": true, "xpf": true,
+}
+
+// StripeProvider gọi Stripe REST API bằng secret key
+type StripeProvider struct {
+	secretKey     string
+	webhookSecret string
+	baseURL       string
+}
+
+// NewStripeProvider tạo provider Stripe với secret key (sk_live_... hoặc sk_test_...) và secret
+// ký webhook (whsec_...); webhook bị từ chối nếu chưa cấu hình webhookSecret
+func NewStripeProvider(secretKey, webhookSecret string) *StripeProvider {
+	return &StripeProvider{secretKey: secretKey, webhookSecret: webhookSecret, baseURL: stripeAPIBase}
+}
+
+func (s *StripeProvider) Name() string { return "stripe" }
+
+type stripePaymentMethod struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Card *struct {
+		Brand    string `json:"brand"`
+		Last4    string `json:"last4"`
+		ExpMonth int    `json:"exp_month"`
+		ExpYear  int    `json:"exp_year"`
+	} `json:"card"`
+}
+
+// stripeError là phần "error" trong body lỗi của Stripe
+type stripeError struct {
+	Error struct {
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (s *StripeProvider) CreateCustomer(ctx context.Context, customer Customer) (string, error) {
+	form := url.Values{
+		"email":             {customer.Email},
+		"metadata[user_id]": {strconv.FormatUint(uint64(customer.UserID), 10)},
+	}
+	if customer.FullName != "" {
+		form.Set("name", customer.FullName)
+	}
+	var out struct {
+		ID string `json:"id"`
+	}
+	if err := s.post(ctx, "/customers", form, &out); err != nil {
+		return "", err
+	}
+	return out.ID, nil
+}
+
+func (s *StripeProvider) AttachMethod(ctx context.Context, customerID, methodID string) (*Method, error) {
+	var pm stripePaymentMethod
+	if err := s.post(ctx, "/payment_methods/"+url.PathEscape(methodID)+"/attach", url.Values{"customer": {customerID}}, &pm); err != nil {
+		return nil, err
+	}
+	method := &Method{ID: pm.ID, Type: pm.Type}
+	if pm.Card != nil {
+		method.Brand = pm.Card.Brand
+		method.Last4 = pm.Card.Last4
+		method.ExpMonth = pm.Card.ExpMonth
+		method.ExpYear = pm.Card.ExpYear
+	}
+	return method, nil
+}
+
+func (s *StripeProvider) DetachMethod(ctx context.Context, methodID string) error {
+	return s.post(ctx, "/payment_methods/"+url.PathEscape(methodID)+"/detach", url.Values{}, nil)
+}
+
+func (s *StripeProvider) SetDefaultMethod(ctx context.Context, customerID, methodID string) error {
+	form := url.Values{"invoice_settings[default_payment_method]": {methodID}}
+	return s.post(ctx, "/customers/"+url.PathEscape(customerID), form, nil)
+}
+
+func (s *StripeProvider) DeleteCustomer(ctx context.Context, customerID string) error {
+	return s.do(ctx, http.MethodDelete, "/customers/"+url.PathEscape(customerID), nil, nil)
+}
+
+// stripeAmount đổi số tiền sang đơn vị nhỏ nhất mà Stripe dùng
+func stripeAmount(amount float64, currency string) int64 {
+	if zeroDecimalCurrencies[strings.ToLower(currency)] {
+		return int64(math.Round(amount))
+	}
+	return int64(math.Round(amount * 100))
+}
+
+type stripePaymentIntent struct {
+	ID               string `json:"id"`
+	Status           string `json:"status"`
+	ClientSecret     string `json:"client_secret"`
+	LastPaymentError *struct {
+		Message string `json:"message"`
+	} `json:"last_payment_error"`
+}
+
+func (s *StripeProvider) CreatePayment(ctx context.Context, req PaymentRequest) (*Intent, error) {
+	form := url.Values{
+		"amount":             {strconv.FormatInt(stripeAmount(req.Amount, req.Currency), 10)},
+		"currency":           {strings.ToLower(req.Currency)},
+		"metadata[order_id]": {strconv.FormatUint(uint64(req.OrderID), 10)},
+	}
+	if req.Description != "" {
+		form.Set("description", req.Description)
+	}
+	if req.MethodID != "" {
+		// Thanh toán ngay bằng phương thức đã lưu; nếu ngân hàng yêu cầu xác thực (3DS),
+		// intent ở trạng thái requires_action và client hoàn tất bằng client_secret
+		form.Set("customer", req.CustomerID)
+		form.Set("payment_method", req.MethodID)
+		form.Set("confirm", "true")
+		form.Set("automatic_payment_methods[enabled]", "true")
+		form.Set("automatic_payment_methods[allow_redirects]", "never")
+	} else {
+		form.Set("automatic_payment_methods[enabled]", "true")
+	}
+
+	var pi stripePaymentIntent
+	if err := s.doKey(ctx, http.MethodPost, "/payment_intents", form, req.IdempotencyKey, &pi); err != nil {
+		return nil, err
+	}
+	return &Intent{Reference: pi.ID, Status: pi.Status, Paid: pi.Status == "succeeded", ClientSecret: pi.ClientSecret}, nil
+}
+
+func (s *StripeProvider) Refund(ctx context.Context, req RefundRequest) (*RefundResult, error) {
+	form := url.Values{
+		"payment_intent": {req.Reference},
+		"amount":         {strconv.FormatInt(stripeAmount(req.Amount, req.Currency), 10)},
+	}
+	if req.Reason != "" {
+		form.Set("metadata[reason]", req.Reason)
+	}
+	var out struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	if err := s.post(ctx, "/refunds", form, &out); err != nil {
+		return nil, err
+	}
+	return &RefundResult{Reference: out.ID, Status: out.Status}, nil
+}
+
+// ParseWebhook xác thực header Stripe-Signature (HMAC-SHA256 của "timestamp.body") và đọc
+// các sự kiện payment_intent.succeeded / payment_intent.payment_failed
+func (s *StripeProvider) ParseWebhook(r *http.Request, body []byte) (*WebhookEvent, error) {
+	if s.webhookSecret == "" {
+		return nil, fmt.Errorf("%w: STRIPE_WEBHOOK_SECRET is not set", ErrNotConfigured)
+	}
+	if err := s.verifySignature(r.Header.Get("Stripe-Signature"), body, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var event struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Object stripePaymentIntent `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid stripe event: %w", err)
+	}
+
+	result := &WebhookEvent{ID: event.ID, Reference: event.Data.Object.ID}
+	switch event.Type {
+	case "payment_intent.succeeded":
+		result.Type = WebhookPaymentSucceeded
+	case "payment_intent.payment_failed":
+		result.Type = WebhookPaymentFailed
+		if e := event.Data.Object.LastPaymentError; e != nil {
+			result.FailureReason = e.Message
+		}
+	}
+	return result, nil
+}
+
+func (s *StripeProvider) verifySignature(header string, body []byte, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > stripeSignatureTolerance || age < -stripeSignatureTolerance {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.webhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func (s *StripeProvider) post(ctx context.Context, path string, form url.Values, out interface{}) error {
+	return s.do(ctx, http.MethodPost, path, form, out)
+}
+
+func (s *StripeProvider) do(ctx context.Context, method, path string, form url.Values, out interface{}) error {
+	return s.doKey(ctx, method, path, form, "", out)
+}
+
+// doKey gửi request form-encoded tới Stripe (kèm Idempotency-Key nếu có) và decode body JSON vào out (nếu khác nil)
+func (s *StripeProvider) doKey(ctx context.Context, method, path string, form url.Values, idempotencyKey string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.secretKey, "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := stripeHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var se stripeError
+		if json.Unmarshal(body, &se) == nil && se.Error.Type == "card_error" {
+			return fmt.Errorf("%w: %s", ErrDeclined, se.Error.Message)
+		}
+		if se.Error.Type == "invalid_request_error" &&
+			(se.Error.Code == "resource_missing" || strings.Contains(se.Error.Code, "payment_method")) {
+			return fmt.Errorf("%w: %s", ErrInvalidMethod, se.Error.Message)
+		}
+		return fmt.Errorf("stripe %s failed with status %d: %s", path, resp.StatusCode, body)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
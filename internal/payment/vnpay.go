@@ -0,0 +1,246 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Địa chỉ cổng thanh toán và API giao dịch (hoàn tiền) của VNPay theo chế độ
+var vnpayEndpoints = map[string]struct{ pay, api string }{
+	ModeSandbox: {
+		pay: "https://sandbox.vnpayment.vn/paymentv2/vpcpay.html",
+		api: "https://sandbox.vnpayment.vn/merchant_webapi/api/transaction",
+	},
+	ModeProduction: {
+		pay: "https://pay.vnpay.vn/vpcpay.html",
+		api: "https://merchant.vnpay.vn/merchant_webapi/api/transaction",
+	},
+}
+
+const vnpayVersion = "2.1.0"
+
+// vnpayLocation là múi giờ VNPay dùng cho các trường thời gian (GMT+7)
+var vnpayLocation = time.FixedZone("ICT", 7*60*60)
+
+var vnpayHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// VNPayGateway thu tiền qua cổng VNPay: khách được chuyển tới trang thanh toán của VNPay, kết quả
+// được VNPay gọi về IPN URL (cấu hình trong trang quản trị merchant) và return URL.
+type VNPayGateway struct {
+	tmnCode    string
+	hashSecret string
+	returnURL  string
+	payURL     string
+	apiURL     string
+}
+
+// NewVNPayGateway tạo gateway VNPay với mã website (vnp_TmnCode), chuỗi bí mật tạo checksum và
+// return URL; mode chọn môi trường sandbox hoặc production
+func NewVNPayGateway(tmnCode, hashSecret, returnURL, mode string) *VNPayGateway {
+	endpoints, ok := vnpayEndpoints[mode]
+	if !ok {
+		endpoints = vnpayEndpoints[ModeSandbox]
+	}
+	return &VNPayGateway{
+		tmnCode:    tmnCode,
+		hashSecret: hashSecret,
+		returnURL:  returnURL,
+		payURL:     endpoints.pay,
+		apiURL:     endpoints.api,
+	}
+}
+
+// vnpayFromEnv cấu hình VNPay từ VNPAY_TMN_CODE, VNPAY_HASH_SECRET và VNPAY_RETURN_URL; nil nếu thiếu
+func vnpayFromEnv(mode string) *VNPayGateway {
+	tmnCode, secret, returnURL := os.Getenv("VNPAY_TMN_CODE"), os.Getenv("VNPAY_HASH_SECRET"), os.Getenv("VNPAY_RETURN_URL")
+	if tmnCode == "" || secret == "" || returnURL == "" {
+		return nil
+	}
+	return NewVNPayGateway(tmnCode, secret, returnURL, mode)
+}
+
+func (v *VNPayGateway) Name() string { return "vnpay" }
+
+// CreatePayment tạo URL thanh toán có chữ ký; mã tham chiếu (vnp_TxnRef) là ID của Payment
+func (v *VNPayGateway) CreatePayment(ctx context.Context, req PaymentRequest) (*Intent, error) {
+	if !strings.EqualFold(req.Currency, "VND") {
+		return nil, fmt.Errorf("%w: vnpay only accepts VND", ErrUnsupportedCurrency)
+	}
+	created := req.CreatedAt
+	if created.IsZero() {
+		created = time.Now()
+	}
+	reference := strconv.FormatUint(uint64(req.PaymentID), 10)
+	params := url.Values{
+		"vnp_Version":    {vnpayVersion},
+		"vnp_Command":    {"pay"},
+		"vnp_TmnCode":    {v.tmnCode},
+		"vnp_Amount":     {strconv.FormatInt(int64(math.Round(req.Amount))*100, 10)},
+		"vnp_CurrCode":   {"VND"},
+		"vnp_TxnRef":     {reference},
+		"vnp_OrderInfo":  {req.Description},
+		"vnp_OrderType":  {"other"},
+		"vnp_Locale":     {"vn"},
+		"vnp_ReturnUrl":  {v.returnURL},
+		"vnp_IpAddr":     {req.ClientIP},
+		"vnp_CreateDate": {created.In(vnpayLocation).Format("20060102150405")},
+		"vnp_ExpireDate": {created.Add(15 * time.Minute).In(vnpayLocation).Format("20060102150405")},
+	}
+	query := vnpayQuery(params)
+	redirect := v.payURL + "?" + query + "&vnp_SecureHash=" + v.sign(query)
+	return &Intent{Reference: reference, Status: "redirect", RedirectURL: redirect}, nil
+}
+
+// ParseWebhook xác thực vnp_SecureHash của IPN hoặc return URL (cùng bộ tham số trên query string)
+func (v *VNPayGateway) ParseWebhook(r *http.Request, _ []byte) (*WebhookEvent, error) {
+	params := r.URL.Query()
+	hash := params.Get("vnp_SecureHash")
+	params.Del("vnp_SecureHash")
+	params.Del("vnp_SecureHashType")
+	if hash == "" || !hmac.Equal([]byte(strings.ToLower(hash)), []byte(v.sign(vnpayQuery(params)))) {
+		return nil, ErrInvalidSignature
+	}
+
+	amount, _ := strconv.ParseInt(params.Get("vnp_Amount"), 10, 64)
+	event := &WebhookEvent{
+		ID:            params.Get("vnp_TransactionNo"),
+		Reference:     params.Get("vnp_TxnRef"),
+		TransactionID: params.Get("vnp_TransactionNo"),
+		Amount:        float64(amount) / 100,
+	}
+	if params.Get("vnp_ResponseCode") == "00" && params.Get("vnp_TransactionStatus") == "00" {
+		event.Type = WebhookPaymentSucceeded
+	} else {
+		event.Type = WebhookPaymentFailed
+		event.FailureReason = "vnpay response code " + params.Get("vnp_ResponseCode")
+	}
+	return event, nil
+}
+
+// WebhookResponse trả lời IPN theo định dạng VNPay yêu cầu: luôn HTTP 200 kèm RspCode
+func (v *VNPayGateway) WebhookResponse(err error) (int, interface{}) {
+	code, message := "00", "Confirm Success"
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrInvalidSignature):
+		code, message = "97", "Invalid Checksum"
+	case errors.Is(err, ErrPaymentNotFound):
+		code, message = "01", "Order not found"
+	case errors.Is(err, ErrAlreadyProcessed):
+		code, message = "02", "Order already confirmed"
+	case errors.Is(err, ErrAmountMismatch):
+		code, message = "04", "Invalid amount"
+	default:
+		code, message = "99", "Unknown error"
+	}
+	return http.StatusOK, map[string]string{"RspCode": code, "Message": message}
+}
+
+// Refund gọi API hoàn tiền của VNPay (vnp_Command=refund); cần mã giao dịch VNPay và thời điểm
+// tạo giao dịch gốc
+func (v *VNPayGateway) Refund(ctx context.Context, req RefundRequest) (*RefundResult, error) {
+	transactionType := "02" // hoàn toàn phần
+	if req.Partial {
+		transactionType = "03"
+	}
+	now := time.Now().In(vnpayLocation)
+	requestID := fmt.Sprintf("%s%d", req.Reference, now.UnixNano()%1_000_000)
+	orderInfo := req.Reason
+	if orderInfo == "" {
+		orderInfo = "Refund " + req.Reference
+	}
+	body := map[string]string{
+		"vnp_RequestId":       requestID,
+		"vnp_Version":         vnpayVersion,
+		"vnp_Command":         "refund",
+		"vnp_TmnCode":         v.tmnCode,
+		"vnp_TransactionType": transactionType,
+		"vnp_TxnRef":          req.Reference,
+		"vnp_Amount":          strconv.FormatInt(int64(math.Round(req.Amount))*100, 10),
+		"vnp_TransactionNo":   req.TransactionID,
+		"vnp_TransactionDate": req.CreatedAt.In(vnpayLocation).Format("20060102150405"),
+		"vnp_CreateBy":        req.RequestedBy,
+		"vnp_CreateDate":      now.Format("20060102150405"),
+		"vnp_IpAddr":          req.ClientIP,
+		"vnp_OrderInfo":       orderInfo,
+	}
+	body["vnp_SecureHash"] = v.sign(strings.Join([]string{
+		body["vnp_RequestId"], body["vnp_Version"], body["vnp_Command"], body["vnp_TmnCode"],
+		body["vnp_TransactionType"], body["vnp_TxnRef"], body["vnp_Amount"], body["vnp_TransactionNo"],
+		body["vnp_TransactionDate"], body["vnp_CreateBy"], body["vnp_CreateDate"], body["vnp_IpAddr"],
+		body["vnp_OrderInfo"],
+	}, "|"))
+
+	var out struct {
+		ResponseCode  string `json:"vnp_ResponseCode"`
+		Message       string `json:"vnp_Message"`
+		TransactionNo string `json:"vnp_TransactionNo"`
+	}
+	if err := postJSON(ctx, vnpayHTTPClient, v.apiURL, body, &out); err != nil {
+		return nil, err
+	}
+	if out.ResponseCode != "00" {
+		return nil, fmt.Errorf("vnpay refund failed with code %s: %s", out.ResponseCode, out.Message)
+	}
+	return &RefundResult{Reference: requestID, Status: "succeeded"}, nil
+}
+
+// sign tính HMAC-SHA512 (hex) của data bằng hash secret
+func (v *VNPayGateway) sign(data string) string {
+	mac := hmac.New(sha512.New, []byte(v.hashSecret))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// vnpayQuery dựng chuỗi dữ liệu ký của VNPay: tham số sắp xếp theo tên, giá trị được URL-encode
+// (khoảng trắng thành +), bỏ qua tham số rỗng
+func vnpayQuery(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		if params.Get(k) != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(params.Get(k)))
+	}
+	return strings.Join(parts, "&")
+}
+
+// postJSON gửi body dạng JSON và decode response JSON vào out
+func postJSON(ctx context.Context, client *http.Client, endpoint string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s failed with status %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
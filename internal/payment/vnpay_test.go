@@ -0,0 +1,74 @@
+package payment
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// vnpayReturn là query string của IPN đã được ký bằng secret "VNPAYSECRET" (HMAC-SHA512 tính độc lập)
+const vnpayReturn = "vnp_Amount=10000000&vnp_BankCode=NCB&vnp_OrderInfo=Thanh+toan+don+hang+42&vnp_ResponseCode=00" +
+	"&vnp_TmnCode=DEMO0001&vnp_TransactionNo=14012345&vnp_TransactionStatus=00&vnp_TxnRef=42"
+
+const vnpayReturnHash = "2a7065767fab70d8d77e44b7075120c87fa57d1479fd4cab501639f2be3aef35" +
+	"54151445f0bb2e9e62812361299b60b3db88775362cd0578459133f39d5c26ee"
+
+func parseVNPay(t *testing.T, query string) (*WebhookEvent, error) {
+	t.Helper()
+	gateway := NewVNPayGateway("DEMO0001", "VNPAYSECRET", "https://shop.example/return", ModeSandbox)
+	return gateway.ParseWebhook(httptest.NewRequest("GET", "/webhooks/vnpay?"+query, nil), nil)
+}
+
+func TestVNPaySign(t *testing.T) {
+	gateway := NewVNPayGateway("DEMO0001", "VNPAYSECRET", "", ModeSandbox)
+	params, err := url.ParseQuery(vnpayReturn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params.Set("vnp_CardType", "") // tham số rỗng không được ký
+	if got := vnpayQuery(params); got != vnpayReturn {
+		t.Errorf("vnpayQuery = %q, want %q", got, vnpayReturn)
+	}
+	if got := gateway.sign(vnpayReturn); got != vnpayReturnHash {
+		t.Errorf("sign = %s, want %s", got, vnpayReturnHash)
+	}
+}
+
+func TestVNPayWebhook(t *testing.T) {
+	// Thứ tự tham số và chữ hoa trong hash không ảnh hưởng tới việc xác thực
+	event, err := parseVNPay(t, "vnp_SecureHash="+strings.ToUpper(vnpayReturnHash)+"&vnp_SecureHashType=HmacSHA512&"+vnpayReturn)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	want := WebhookEvent{ID: "14012345", Type: WebhookPaymentSucceeded, Reference: "42", TransactionID: "14012345", Amount: 100000}
+	if *event != want {
+		t.Errorf("event = %+v, want %+v", *event, want)
+	}
+}
+
+func TestVNPayWebhookBadSignature(t *testing.T) {
+	tests := map[string]string{
+		"missing hash":    vnpayReturn,
+		"tampered amount": strings.Replace(vnpayReturn, "vnp_Amount=10000000", "vnp_Amount=100", 1) + "&vnp_SecureHash=" + vnpayReturnHash,
+		"added parameter": vnpayReturn + "&vnp_PayDate=20251010101010&vnp_SecureHash=" + vnpayReturnHash,
+		"wrong hash":      vnpayReturn + "&vnp_SecureHash=" + strings.Repeat("0", len(vnpayReturnHash)),
+		"truncated hash":  vnpayReturn + "&vnp_SecureHash=" + vnpayReturnHash[:64],
+		"tampered result": strings.Replace(vnpayReturn, "vnp_ResponseCode=00", "vnp_ResponseCode=24", 1) + "&vnp_SecureHash=" + vnpayReturnHash,
+	}
+	for name, query := range tests {
+		if _, err := parseVNPay(t, query); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: err = %v, want ErrInvalidSignature", name, err)
+		}
+	}
+
+	gateway := NewVNPayGateway("DEMO0001", "OTHERSECRET", "", ModeSandbox)
+	req := httptest.NewRequest("GET", "/webhooks/vnpay?"+vnpayReturn+"&vnp_SecureHash="+vnpayReturnHash, nil)
+	if _, err := gateway.ParseWebhook(req, nil); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("other secret: err = %v, want ErrInvalidSignature", err)
+	}
+	if status, body := gateway.WebhookResponse(ErrInvalidSignature); status != 200 || body.(map[string]string)["RspCode"] != "97" {
+		t.Errorf("WebhookResponse = %d, %v", status, body)
+	}
+}
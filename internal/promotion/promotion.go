@@ -0,0 +1,177 @@
+// Package promotion tính giảm giá tự động cho giỏ hàng theo các quy tắc khuyến mãi
+package promotion
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+)
+
+// Line là một dòng của giỏ hàng cần tính khuyến mãi
+type Line struct {
+	ProductID uint
+	Category  string
+	UnitPrice float64
+	Quantity  int
+}
+
+func (l Line) amount() float64 {
+	return l.UnitPrice * float64(l.Quantity)
+}
+
+// Result là kết quả tính khuyến mãi. LineDiscounts có cùng thứ tự với các dòng đầu vào;
+// tổng LineDiscounts bằng Total (giảm giá theo giỏ được phân bổ vào các dòng theo tỷ lệ thành tiền).
+type Result struct {
+	Applied       []models.AppliedPromotion
+	LineDiscounts []float64
+	Total         float64
+}
+
+// Evaluate áp dụng các khuyến mãi đang hiệu lực tại now cho các dòng hàng. Các khuyến mãi cộng dồn
+// (Stackable) được áp dụng lần lượt theo Priority giảm dần, mỗi khuyến mãi tính trên phần tiền còn
+// lại sau các khuyến mãi trước; mỗi khuyến mãi không cộng dồn được tính riêng. Kết quả là phương án
+// giảm nhiều nhất cho khách; số tiền giảm của một dòng không vượt quá thành tiền của dòng đó.
+func Evaluate(promotions []models.Promotion, lines []Line, now time.Time) Result {
+	active := make([]models.Promotion, 0, len(promotions))
+	for _, p := range promotions {
+		if p.ActiveAt(now) {
+			active = append(active, p)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		if active[i].Priority != active[j].Priority {
+			return active[i].Priority > active[j].Priority
+		}
+		return active[i].ID < active[j].ID
+	})
+
+	var stackable []models.Promotion
+	for _, p := range active {
+		if p.Stackable {
+			stackable = append(stackable, p)
+		}
+	}
+	best := apply(stackable, lines)
+	for _, p := range active {
+		if p.Stackable {
+			continue
+		}
+		if r := apply([]models.Promotion{p}, lines); r.Total > best.Total {
+			best = r
+		}
+	}
+	return best
+}
+
+// apply áp dụng lần lượt các khuyến mãi lên các dòng
+func apply(promotions []models.Promotion, lines []Line) Result {
+	result := Result{LineDiscounts: make([]float64, len(lines))}
+	for _, p := range promotions {
+		discounts := discount(&p, lines, result.LineDiscounts)
+		var amount float64
+		for i, d := range discounts {
+			result.LineDiscounts[i] += d
+			amount += d
+		}
+		if amount <= 0 {
+			continue
+		}
+		amount = round(amount)
+		result.Applied = append(result.Applied, models.AppliedPromotion{
+			PromotionID: p.ID, Name: p.Name, Type: p.Type, Amount: amount,
+		})
+		result.Total += amount
+	}
+	result.Total = round(result.Total)
+	return result
+}
+
+// discount tính số tiền giảm thêm của từng dòng theo khuyến mãi p; applied là phần đã giảm trước đó
+func discount(p *models.Promotion, lines []Line, applied []float64) []float64 {
+	out := make([]float64, len(lines))
+	remaining := func(i int) float64 {
+		return math.Max(lines[i].amount()-applied[i], 0)
+	}
+
+	switch p.Type {
+	case models.PromotionBuyXGetY:
+		if p.BuyQuantity <= 0 || p.GetQuantity <= 0 {
+			return out
+		}
+		percent := p.Percent
+		if percent == 0 {
+			percent = 100
+		}
+		for i, line := range lines {
+			if !inScope(p, line) {
+				continue
+			}
+			free := line.Quantity / (p.BuyQuantity + p.GetQuantity) * p.GetQuantity
+			out[i] = math.Min(round(float64(free)*line.UnitPrice*percent/100), remaining(i))
+		}
+
+	case models.PromotionCategoryPercent:
+		if p.Category == "" && p.ProductID == nil {
+			return out
+		}
+		for i, line := range lines {
+			if inScope(p, line) {
+				out[i] = round(remaining(i) * p.Percent / 100)
+			}
+		}
+
+	case models.PromotionCartThreshold:
+		var base float64
+		for i, line := range lines {
+			if inScope(p, line) {
+				base += remaining(i)
+			}
+		}
+		if base <= 0 || base < p.MinSubtotal {
+			return out
+		}
+		total := p.AmountOff
+		if p.Percent > 0 {
+			total = base * p.Percent / 100
+		}
+		total = round(math.Min(total, base))
+		// Phân bổ theo tỷ lệ thành tiền còn lại; dòng cuối nhận phần lẻ để tổng khớp
+		last := -1
+		for i, line := range lines {
+			if inScope(p, line) && remaining(i) > 0 {
+				last = i
+			}
+		}
+		var allocated float64
+		for i, line := range lines {
+			if !inScope(p, line) || remaining(i) <= 0 {
+				continue
+			}
+			if i == last {
+				out[i] = round(total - allocated)
+				break
+			}
+			out[i] = round(total * remaining(i) / base)
+			allocated += out[i]
+		}
+	}
+	return out
+}
+
+// inScope cho biết dòng hàng thuộc phạm vi sản phẩm của khuyến mãi
+func inScope(p *models.Promotion, line Line) bool {
+	if p.ProductID != nil && *p.ProductID != line.ProductID {
+		return false
+	}
+	if p.Category != "" && !strings.EqualFold(strings.TrimSpace(p.Category), strings.TrimSpace(line.Category)) {
+		return false
+	}
+	return true
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
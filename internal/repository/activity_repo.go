@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// activityFeed gộp các nguồn hoạt động của một user thành một bảng chung. Audit log dùng phần
+// đầu của action làm nhóm; mỗi hóa đơn có một mục khi phát hành và một mục nữa khi bị hủy.
+const activityFeed = `
+	SELECT split_part(action, '.', 1) AS category, action, COALESCE(entity_type, '') AS entity_type,
+		COALESCE(entity_id, '') AS entity_id, COALESCE(metadata, '{}')::text AS details,
+		COALESCE(ip_address, '') AS ip_address, created_at AS occurred_at
+	FROM audit_logs
+	WHERE user_id = @user
+	UNION ALL
+	SELECT 'order', 'order.issued', 'invoice', id::text,
+		json_build_object('series', series, 'number', number, 'total', total, 'currency', currency)::text,
+		'', issued_at
+	FROM invoices
+	WHERE user_id = @user AND issued_at IS NOT NULL
+	UNION ALL
+	SELECT 'order', 'order.cancelled', 'invoice', id::text,
+		json_build_object('series', series, 'number', number, 'reason', cancel_reason)::text,
+		'', cancelled_at
+	FROM invoices
+	WHERE user_id = @user AND cancelled_at IS NOT NULL
+	UNION ALL
+	SELECT 'user', 'user.registered', 'user', id::text, '{}', '', created_at
+	FROM users
+	WHERE id = @user`
+
+type ActivityRepository struct {
+	db *gorm.DB
+}
+
+func NewActivityRepository(db *gorm.DB) *ActivityRepository {
+	return &ActivityRepository{db: db}
+}
+
+// ListByUser lấy lịch sử hoạt động của user, mới nhất trước, có phân trang và lọc theo nhóm
+func (r *ActivityRepository) ListByUser(ctx context.Context, user *models.User, query *models.ActivityQueryParams) ([]models.ActivityEntry, int64, error) {
+	filter := ""
+	if query.Type != "" {
+		filter = "WHERE category = @type"
+	}
+	args := map[string]interface{}{
+		"user":   user.ID,
+		"type":   query.Type,
+		"limit":  query.Limit,
+		"offset": (query.Page - 1) * query.Limit,
+	}
+
+	var total int64
+	if err := Conn(ctx, r.db).Raw(`SELECT COUNT(*) FROM (`+activityFeed+`) feed `+filter, args).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	entries := []models.ActivityEntry{}
+	err := Conn(ctx, r.db).Raw(`SELECT * FROM (`+activityFeed+`) feed `+filter+`
+		ORDER BY occurred_at DESC, action
+		LIMIT @limit OFFSET @offset`, args).Scan(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range entries {
+		entries[i].Details = json.RawMessage(entries[i].RawDetails)
+	}
+	return entries, total, nil
+}
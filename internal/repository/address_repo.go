@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type AddressRepository struct {
+	db *gorm.DB
+}
+
+func NewAddressRepository(db *gorm.DB) *AddressRepository {
+	return &AddressRepository{db: db}
+}
+
+// List lấy sổ địa chỉ của user, địa chỉ mặc định đứng đầu
+func (r *AddressRepository) List(ctx context.Context, userID uint) ([]models.Address, error) {
+	var addresses []models.Address
+	err := Conn(ctx, r.db).Where("user_id = ?", userID).Order("is_default DESC, created_at DESC").Find(&addresses).Error
+	return addresses, err
+}
+
+// Get lấy một địa chỉ của user
+func (r *AddressRepository) Get(ctx context.Context, userID, id uint) (*models.Address, error) {
+	var address models.Address
+	if err := Conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).First(&address).Error; err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// Count đếm số địa chỉ của user
+func (r *AddressRepository) Count(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.Address{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// Create lưu địa chỉ mới
+func (r *AddressRepository) Create(ctx context.Context, address *models.Address) error {
+	return Conn(ctx, r.db).Create(address).Error
+}
+
+// UpdateDetails ghi đè thông tin giao hàng của địa chỉ. Cập nhật bằng struct (không phải map) để các cột
+// mã hóa đi qua serializer của fieldcrypt.
+func (r *AddressRepository) UpdateDetails(ctx context.Context, address *models.Address) error {
+	return Conn(ctx, r.db).Model(&models.Address{}).Where("id = ?", address.ID).
+		Select("recipient_name", "phone", "province", "district", "ward", "street").
+		Updates(&models.Address{AddressDetails: address.AddressDetails}).Error
+}
+
+// Delete xóa địa chỉ
+func (r *AddressRepository) Delete(ctx context.Context, id uint) error {
+	return Conn(ctx, r.db).Delete(&models.Address{}, id).Error
+}
+
+// SetDefault đặt địa chỉ id làm mặc định và bỏ mặc định các địa chỉ khác của user
+func (r *AddressRepository) SetDefault(ctx context.Context, userID, id uint) error {
+	if err := Conn(ctx, r.db).Model(&models.Address{}).Where("user_id = ? AND is_default AND id <> ?", userID, id).
+		Update("is_default", false).Error; err != nil {
+		return err
+	}
+	return Conn(ctx, r.db).Model(&models.Address{}).Where("id = ? AND user_id = ?", id, userID).
+		Update("is_default", true).Error
+}
+
+// Latest lấy địa chỉ được thêm gần nhất của user (dùng để chọn mặc định mới)
+func (r *AddressRepository) Latest(ctx context.Context, userID uint) (*models.Address, error) {
+	var address models.Address
+	if err := Conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at DESC").First(&address).Error; err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// DeleteUserData xóa sổ địa chỉ của user khi tài khoản bị ẩn danh hóa
+func (r *AddressRepository) DeleteUserData(ctx context.Context, userID uint) error {
+	return Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&models.Address{}).Error
+}
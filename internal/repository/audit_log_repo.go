@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/database"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/partition"
+	"gorm.io/gorm"
+)
+
+// AuditLogPartition là cấu hình phân vùng theo tháng của bảng audit_logs
+var AuditLogPartition = partition.Table{Name: "audit_logs", Column: "created_at", Ahead: 3}
+
+// MigrateAuditLogs tạo bảng audit_logs dạng phân vùng (AutoMigrate không hỗ trợ PARTITION BY)
+// và index theo user cho lịch sử hoạt động. Database khác Postgres dùng bảng thường.
+func MigrateAuditLogs(db *gorm.DB) error {
+	if !database.IsPostgres(db) {
+		return db.AutoMigrate(&auditLogTable{})
+	}
+	err := partition.CreatePartitionedTable(db, AuditLogPartition.Name, `
+		id BIGSERIAL,
+		user_id BIGINT,
+		action TEXT NOT NULL,
+		entity_type TEXT,
+		entity_id TEXT,
+		metadata JSONB,
+		ip_address TEXT,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (id, created_at)`, AuditLogPartition.Column)
+	if err != nil {
+		return err
+	}
+	return db.Exec(`CREATE INDEX IF NOT EXISTS idx_audit_logs_user ON audit_logs (user_id, created_at DESC)`).Error
+}
+
+// auditLogTable là cấu trúc bảng audit_logs không phân vùng, khóa chính chỉ gồm id
+type auditLogTable struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     *uint  `gorm:"index:idx_audit_logs_user,priority:1"`
+	Action     string `gorm:"not null"`
+	EntityType string
+	EntityID   string
+	Metadata   models.JSON
+	IPAddress  string
+	CreatedAt  time.Time `gorm:"not null;index:idx_audit_logs_user,priority:2"`
+}
+
+func (auditLogTable) TableName() string { return "audit_logs" }
+
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// Create ghi một audit log mới
+func (r *AuditLogRepository) Create(ctx context.Context, entry *models.AuditLog) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	if entry.Metadata == "" {
+		entry.Metadata = "{}"
+	}
+	return Conn(ctx, r.db).Create(entry).Error
+}
+
+// GetAll lấy audit log theo bộ lọc. Truy vấn luôn bị giới hạn theo khoảng thời gian
+// (mặc định 30 ngày gần nhất) để Postgres chỉ quét các phân vùng liên quan.
+func (r *AuditLogRepository) GetAll(ctx context.Context, query *models.AuditLogQueryParams) ([]models.AuditLog, int64, error) {
+	var logs []models.AuditLog
+	var total int64
+
+	endDate := query.EndDate
+	if endDate.IsZero() {
+		endDate = time.Now()
+	}
+	startDate := query.StartDate
+	if startDate.IsZero() {
+		startDate = endDate.AddDate(0, 0, -30)
+	}
+
+	dbQuery := Conn(ctx, r.db).Model(&models.AuditLog{}).
+		Where("created_at >= ? AND created_at <= ?", startDate, endDate)
+
+	if query.UserID > 0 {
+		dbQuery = dbQuery.Where("user_id = ?", query.UserID)
+	}
+	if query.Action != "" {
+		dbQuery = dbQuery.Where("action = ?", query.Action)
+	}
+	if query.EntityType != "" {
+		dbQuery = dbQuery.Where("entity_type = ?", query.EntityType)
+	}
+	if query.EntityID != "" {
+		dbQuery = dbQuery.Where("entity_id = ?", query.EntityID)
+	}
+
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.Limit
+	if err := dbQuery.Order("created_at DESC").Offset(offset).Limit(query.Limit).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// ListByUser lấy mọi audit log do user thực hiện
+func (r *AuditLogRepository) ListByUser(ctx context.Context, userID uint) ([]models.AuditLog, error) {
+	var logs []models.AuditLog
+	err := Conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at").Find(&logs).Error
+	return logs, err
+}
+
+// ScrubUser xóa địa chỉ IP khỏi audit log của user; user_id được giữ để lịch sử thao tác vẫn liền mạch
+func (r *AuditLogRepository) ScrubUser(ctx context.Context, userID uint) error {
+	return Conn(ctx, r.db).Model(&models.AuditLog{}).Where("user_id = ? AND ip_address <> ''", userID).
+		Update("ip_address", "").Error
+}
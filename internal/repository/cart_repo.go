@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CartRepository struct {
+	db *gorm.DB
+}
+
+func NewCartRepository(db *gorm.DB) *CartRepository {
+	return &CartRepository{db: db}
+}
+
+// GetByUserID lấy giỏ của user
+func (r *CartRepository) GetByUserID(ctx context.Context, userID uint) (*models.Cart, error) {
+	var cart models.Cart
+	if err := Conn(ctx, r.db).Where("user_id = ?", userID).First(&cart).Error; err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// GetByToken lấy giỏ của khách theo cart token
+func (r *CartRepository) GetByToken(ctx context.Context, token string) (*models.Cart, error) {
+	var cart models.Cart
+	if err := Conn(ctx, r.db).Where("token = ? AND user_id IS NULL", token).First(&cart).Error; err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// GetOrCreateForUser lấy giỏ của user, tạo mới nếu chưa có
+func (r *CartRepository) GetOrCreateForUser(ctx context.Context, userID uint) (*models.Cart, error) {
+	cart := models.Cart{UserID: &userID}
+	if err := Conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&cart).Error; err != nil {
+		return nil, err
+	}
+	return r.GetByUserID(ctx, userID)
+}
+
+// Create tạo giỏ mới
+func (r *CartRepository) Create(ctx context.Context, cart *models.Cart) error {
+	return Conn(ctx, r.db).Create(cart).Error
+}
+
+// Lock khóa dòng giỏ tới hết transaction để các thao tác gộp/sửa không chen nhau
+func (r *CartRepository) Lock(ctx context.Context, cartID uint) error {
+	var cart models.Cart
+	return Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&cart, cartID).Error
+}
+
+// GetItems lấy các dòng trong giỏ kèm thông tin sản phẩm
+func (r *CartRepository) GetItems(ctx context.Context, cartID uint) ([]models.CartItem, error) {
+	var items []models.CartItem
+	err := Conn(ctx, r.db).Preload("Product").Where("cart_id = ?", cartID).Order("id").Find(&items).Error
+	return items, err
+}
+
+// GetItem lấy một dòng trong giỏ theo sản phẩm
+func (r *CartRepository) GetItem(ctx context.Context, cartID, productID uint) (*models.CartItem, error) {
+	var item models.CartItem
+	if err := Conn(ctx, r.db).Where("cart_id = ? AND product_id = ?", cartID, productID).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// SetItemQuantity đặt số lượng và giá ghi nhận của một sản phẩm trong giỏ, thêm dòng mới nếu chưa có
+func (r *CartRepository) SetItemQuantity(ctx context.Context, cartID, productID uint, quantity int, price float64) error {
+	item := models.CartItem{CartID: cartID, ProductID: productID, Quantity: quantity, Price: price}
+	return Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cart_id"}, {Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quantity", "price", "updated_at"}),
+	}).Create(&item).Error
+}
+
+// DeleteItem xóa một sản phẩm khỏi giỏ
+func (r *CartRepository) DeleteItem(ctx context.Context, cartID, productID uint) (int64, error) {
+	result := Conn(ctx, r.db).Where("cart_id = ? AND product_id = ?", cartID, productID).Delete(&models.CartItem{})
+	return result.RowsAffected, result.Error
+}
+
+// ClearItems xóa mọi sản phẩm trong giỏ
+func (r *CartRepository) ClearItems(ctx context.Context, cartID uint) error {
+	return Conn(ctx, r.db).Where("cart_id = ?", cartID).Delete(&models.CartItem{}).Error
+}
+
+// Delete xóa giỏ cùng các dòng của nó
+func (r *CartRepository) Delete(ctx context.Context, cartID uint) error {
+	return Conn(ctx, r.db).Delete(&models.Cart{}, cartID).Error
+}
+
+// DeleteIdle xóa các giỏ không có hoạt động từ trước cutoff
+func (r *CartRepository) DeleteIdle(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := Conn(ctx, r.db).Where("updated_at < ?", cutoff).Delete(&models.Cart{})
+	return result.RowsAffected, result.Error
+}
+
+// Touch cập nhật thời điểm hoạt động gần nhất của giỏ
+func (r *CartRepository) Touch(ctx context.Context, cartID uint) error {
+	return Conn(ctx, r.db).Model(&models.Cart{}).Where("id = ?", cartID).Update("updated_at", gorm.Expr("CURRENT_TIMESTAMP")).Error
+}
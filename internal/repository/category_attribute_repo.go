@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type CategoryAttributeRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryAttributeRepository(db *gorm.DB) *CategoryAttributeRepository {
+	return &CategoryAttributeRepository{db: db}
+}
+
+// List lấy các thuộc tính đã khai báo; category rỗng lấy của mọi danh mục
+func (r *CategoryAttributeRepository) List(ctx context.Context, category string) ([]models.CategoryAttribute, error) {
+	var attributes []models.CategoryAttribute
+	dbQuery := Conn(ctx, r.db)
+	if category != "" {
+		dbQuery = dbQuery.Where("category = ?", category)
+	}
+	err := dbQuery.Order("category, attr_key, id").Find(&attributes).Error
+	return attributes, err
+}
+
+// GetByID lấy một thuộc tính
+func (r *CategoryAttributeRepository) GetByID(ctx context.Context, id uint) (*models.CategoryAttribute, error) {
+	var attribute models.CategoryAttribute
+	if err := Conn(ctx, r.db).First(&attribute, id).Error; err != nil {
+		return nil, err
+	}
+	return &attribute, nil
+}
+
+// Create lưu thuộc tính mới
+func (r *CategoryAttributeRepository) Create(ctx context.Context, attribute *models.CategoryAttribute) error {
+	return Conn(ctx, r.db).Create(attribute).Error
+}
+
+// Save ghi đè thuộc tính
+func (r *CategoryAttributeRepository) Save(ctx context.Context, attribute *models.CategoryAttribute) error {
+	return Conn(ctx, r.db).Save(attribute).Error
+}
+
+// Delete xóa thuộc tính
+func (r *CategoryAttributeRepository) Delete(ctx context.Context, id uint) (int64, error) {
+	result := Conn(ctx, r.db).Delete(&models.CategoryAttribute{}, id)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type CategoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+// List lấy mọi danh mục đã khai báo theo thứ tự hiển thị
+func (r *CategoryRepository) List(ctx context.Context) ([]models.Category, error) {
+	var categories []models.Category
+	err := Conn(ctx, r.db).Order("position, name").Find(&categories).Error
+	return categories, err
+}
+
+// GetByID lấy danh mục theo ID
+func (r *CategoryRepository) GetByID(ctx context.Context, id uint) (*models.Category, error) {
+	var category models.Category
+	if err := Conn(ctx, r.db).First(&category, id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// NameExists kiểm tra tên đã thuộc danh mục khác (loại trừ danh mục có ID = excludeID)
+func (r *CategoryRepository) NameExists(ctx context.Context, name string, excludeID uint) (bool, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.Category{}).Where("name = ? AND id <> ?", name, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+// Create lưu danh mục mới
+func (r *CategoryRepository) Create(ctx context.Context, category *models.Category) error {
+	return Conn(ctx, r.db).Create(category).Error
+}
+
+// Save ghi đè danh mục
+func (r *CategoryRepository) Save(ctx context.Context, category *models.Category) error {
+	return Conn(ctx, r.db).Save(category).Error
+}
+
+// Reparent chuyển các danh mục con của parentID sang newParentID (nil là lên gốc)
+func (r *CategoryRepository) Reparent(ctx context.Context, parentID uint, newParentID *uint) error {
+	return Conn(ctx, r.db).Model(&models.Category{}).Where("parent_id = ?", parentID).Update("parent_id", newParentID).Error
+}
+
+// Delete xóa danh mục; trả về false nếu không có danh mục nào bị xóa
+func (r *CategoryRepository) Delete(ctx context.Context, id uint) (bool, error) {
+	result := Conn(ctx, r.db).Delete(&models.Category{}, id)
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type EmailChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailChangeRepository(db *gorm.DB) *EmailChangeRepository {
+	return &EmailChangeRepository{db: db}
+}
+
+// Create lưu yêu cầu đổi email mới
+func (r *EmailChangeRepository) Create(ctx context.Context, change *models.EmailChange) error {
+	return Conn(ctx, r.db).Create(change).Error
+}
+
+// Get lấy yêu cầu đổi email theo ID
+func (r *EmailChangeRepository) Get(ctx context.Context, id uint) (*models.EmailChange, error) {
+	var change models.EmailChange
+	if err := Conn(ctx, r.db).First(&change, id).Error; err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// GetByTokenHash lấy yêu cầu có token xác nhận (của địa chỉ cũ hoặc địa chỉ mới) trùng hash
+func (r *EmailChangeRepository) GetByTokenHash(ctx context.Context, hash string) (*models.EmailChange, error) {
+	var change models.EmailChange
+	err := Conn(ctx, r.db).Where("old_token_hash = ? OR new_token_hash = ?", hash, hash).First(&change).Error
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// ListByUser lấy các yêu cầu đổi email của user, mới nhất trước
+func (r *EmailChangeRepository) ListByUser(ctx context.Context, userID uint) ([]models.EmailChange, error) {
+	var changes []models.EmailChange
+	err := Conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&changes).Error
+	return changes, err
+}
+
+// CancelPending hủy các yêu cầu chưa hoàn tất của user
+func (r *EmailChangeRepository) CancelPending(ctx context.Context, userID uint, at time.Time) error {
+	return Conn(ctx, r.db).Model(&models.EmailChange{}).
+		Where("user_id = ? AND completed_at IS NULL AND cancelled_at IS NULL", userID).
+		Update("cancelled_at", at).Error
+}
+
+// Confirm ghi thời điểm xác nhận vào cột column (old_confirmed_at hoặc new_confirmed_at) nếu chưa có.
+// Câu UPDATE khóa dòng nên hai lần xác nhận đồng thời trong transaction được thực hiện lần lượt.
+func (r *EmailChangeRepository) Confirm(ctx context.Context, id uint, column string, at time.Time) error {
+	return Conn(ctx, r.db).Model(&models.EmailChange{}).
+		Where("id = ?", id).
+		Update(column, gorm.Expr("COALESCE("+column+", ?)", at)).Error
+}
+
+// MarkCompleted đánh dấu yêu cầu đã hoàn tất, trả về false nếu yêu cầu đã hoàn tất hoặc bị hủy trước đó
+func (r *EmailChangeRepository) MarkCompleted(ctx context.Context, id uint, at time.Time) (bool, error) {
+	result := Conn(ctx, r.db).Model(&models.EmailChange{}).
+		Where("id = ? AND completed_at IS NULL AND cancelled_at IS NULL", id).
+		Update("completed_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteUserData xóa các yêu cầu đổi email của user
+func (r *EmailChangeRepository) DeleteUserData(ctx context.Context, userID uint) error {
+	return Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&models.EmailChange{}).Error
+}
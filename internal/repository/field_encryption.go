@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/fieldcrypt"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// reencryptBatchSize là số dòng được mã hóa lại mỗi lần
+const reencryptBatchSize = 500
+
+// encryptedTable là một bảng có cột được mã hóa bằng fieldcrypt (serializer:encrypted)
+type encryptedTable struct {
+	model   interface{}
+	columns []string
+}
+
+// encryptedTables liệt kê mọi cột mã hóa; cột mới đánh dấu serializer:encrypted phải được thêm ở đây để
+// được mã hóa lại khi xoay khóa
+var encryptedTables = []encryptedTable{
+	{&models.Address{}, []string{"recipient_name", "phone", "street"}},
+	{&models.Order{}, []string{"shipping_recipient_name", "shipping_phone", "shipping_street"}},
+	{&models.WebhookSubscription{}, []string{"secret"}},
+}
+
+// ReencryptFields mã hóa lại bằng khóa chính các giá trị còn là văn bản thường (ghi trước khi bật mã
+// hóa) hoặc được mã hóa bằng khóa cũ, theo lô reencryptBatchSize dòng. Trả về số dòng đã ghi lại.
+// Không làm gì khi chưa cấu hình khóa. updated_at không bị thay đổi.
+func ReencryptFields(ctx context.Context, db *gorm.DB) (int64, error) {
+	keyring := fieldcrypt.Current()
+	if !keyring.Enabled() {
+		return 0, nil
+	}
+	pattern := keyring.CurrentPrefix() + "%"
+
+	var total int64
+	for _, table := range encryptedTables {
+		conditions := make([]string, len(table.columns))
+		args := make([]interface{}, len(table.columns))
+		for i, column := range table.columns {
+			conditions[i] = "(" + column + " <> '' AND " + column + " NOT LIKE ?)"
+			args[i] = pattern
+		}
+		stale := strings.Join(conditions, " OR ")
+
+		var lastID uint
+		for {
+			var ids []uint
+			if err := Conn(ctx, db).Model(table.model).Where("id > ?", lastID).Where(stale, args...).
+				Order("id").Limit(reencryptBatchSize).Pluck("id", &ids).Error; err != nil {
+				return total, err
+			}
+			if len(ids) == 0 {
+				break
+			}
+			lastID = ids[len(ids)-1]
+
+			rows := reflect.New(reflect.SliceOf(reflect.TypeOf(table.model).Elem()))
+			if err := Conn(ctx, db).Where("id IN ?", ids).Find(rows.Interface()).Error; err != nil {
+				return total, err
+			}
+			for i := 0; i < rows.Elem().Len(); i++ {
+				row := rows.Elem().Index(i).Addr().Interface()
+				if err := Conn(ctx, db).Model(row).Select(table.columns).UpdateColumns(row).Error; err != nil {
+					return total, err
+				}
+				total++
+			}
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/NgTruong624/project_backend/internal/fieldcrypt"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func configureKeys(t *testing.T, spec string) {
+	t.Helper()
+	k, err := fieldcrypt.ParseKeys(spec)
+	if err != nil {
+		t.Fatalf("ParseKeys: %v", err)
+	}
+	fieldcrypt.Configure(k)
+}
+
+func TestReencryptFields(t *testing.T) {
+	ctx := context.Background()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for _, table := range encryptedTables {
+		if err := db.AutoMigrate(table.model); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+	}
+	previous := fieldcrypt.Current()
+	t.Cleanup(func() { fieldcrypt.Configure(previous) })
+
+	// Địa chỉ ghi trước khi bật mã hóa
+	configureKeys(t, "")
+	address := models.Address{UserID: 1, AddressDetails: models.AddressDetails{
+		RecipientName: "Nguyễn Văn A", Phone: "0901234567", Province: "Hồ Chí Minh", Street: "12 Nguyễn Huệ",
+	}}
+	if err := db.Create(&address).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	raw := func() (phone, province string) {
+		t.Helper()
+		row := db.Table("addresses").Select("phone", "province").Where("id = ?", address.ID).Row()
+		if err := row.Scan(&phone, &province); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		return phone, province
+	}
+	reencrypt := func(want int64) {
+		t.Helper()
+		n, err := ReencryptFields(ctx, db)
+		if err != nil || n != want {
+			t.Fatalf("ReencryptFields = %d, %v; want %d", n, err, want)
+		}
+	}
+	check := func(prefix string) {
+		t.Helper()
+		phone, province := raw()
+		if !strings.HasPrefix(phone, prefix) || province != "Hồ Chí Minh" {
+			t.Errorf("stored phone = %q, province = %q; want phone encrypted with %q", phone, province, prefix)
+		}
+		var got models.Address
+		if err := db.First(&got, address.ID).Error; err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if got.AddressDetails != address.AddressDetails {
+			t.Errorf("read back %+v, want %+v", got.AddressDetails, address.AddressDetails)
+		}
+	}
+
+	configureKeys(t, "k1:AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=")
+	reencrypt(1)
+	check("enc:v1:k1:")
+	reencrypt(0)
+
+	configureKeys(t, "k2:ICEiIyQlJicoKSorLC0uLzAxMjM0NTY3ODk6Ozw9Pj8=,k1:AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=")
+	reencrypt(1)
+	check("enc:v1:k2:")
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/database"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MigrateInvoices tạo trigger bảo đảm hóa đơn đã phát hành không thể sửa hoặc xóa ở mức database.
+// Hóa đơn đã phát hành chỉ được phép chuyển sang trạng thái cancelled (kèm lý do, thời điểm hủy);
+// dòng hàng chỉ được thay đổi khi hóa đơn còn là bản nháp. Chỉ có trên Postgres; với database khác
+// quy tắc này chỉ được bảo đảm bởi InvoiceService.
+func MigrateInvoices(db *gorm.DB) error {
+	if !database.IsPostgres(db) {
+		return nil
+	}
+	return db.Exec(`
+		CREATE OR REPLACE FUNCTION invoices_immutable() RETURNS trigger AS $$
+		BEGIN
+			IF OLD.status = 'draft' THEN
+				IF TG_OP = 'DELETE' THEN
+					RETURN OLD;
+				END IF;
+				RETURN NEW;
+			END IF;
+			IF TG_OP = 'UPDATE' AND OLD.status = 'issued' AND NEW.status = 'cancelled'
+				AND (to_jsonb(NEW) - ARRAY['status', 'cancelled_at', 'cancel_reason', 'updated_at'])
+					= (to_jsonb(OLD) - ARRAY['status', 'cancelled_at', 'cancel_reason', 'updated_at']) THEN
+				RETURN NEW;
+			END IF;
+			RAISE EXCEPTION 'invoice % is % and cannot be modified', OLD.id, OLD.status
+				USING ERRCODE = 'integrity_constraint_violation';
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS trg_invoices_immutable ON invoices;
+		CREATE TRIGGER trg_invoices_immutable
+			BEFORE UPDATE OR DELETE ON invoices
+			FOR EACH ROW EXECUTE FUNCTION invoices_immutable();
+
+		CREATE OR REPLACE FUNCTION invoice_items_immutable() RETURNS trigger AS $$
+		DECLARE
+			invoice_status TEXT;
+		BEGIN
+			SELECT status INTO invoice_status FROM invoices
+			WHERE id = CASE WHEN TG_OP = 'DELETE' THEN OLD.invoice_id ELSE NEW.invoice_id END;
+			IF invoice_status IS NOT NULL AND invoice_status <> 'draft' THEN
+				RAISE EXCEPTION 'items of % invoice cannot be modified', invoice_status
+					USING ERRCODE = 'integrity_constraint_violation';
+			END IF;
+			IF TG_OP = 'DELETE' THEN
+				RETURN OLD;
+			END IF;
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS trg_invoice_items_immutable ON invoice_items;
+		CREATE TRIGGER trg_invoice_items_immutable
+			BEFORE INSERT OR UPDATE OR DELETE ON invoice_items
+			FOR EACH ROW EXECUTE FUNCTION invoice_items_immutable();
+	`).Error
+}
+
+type InvoiceRepository struct {
+	db *gorm.DB
+}
+
+func NewInvoiceRepository(db *gorm.DB) *InvoiceRepository {
+	return &InvoiceRepository{db: db}
+}
+
+// Create lưu hóa đơn nháp cùng các dòng hàng
+func (r *InvoiceRepository) Create(ctx context.Context, invoice *models.Invoice) error {
+	return Conn(ctx, r.db).Create(invoice).Error
+}
+
+// GetByID lấy hóa đơn kèm các dòng hàng
+func (r *InvoiceRepository) GetByID(ctx context.Context, id uint) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := Conn(ctx, r.db).Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).First(&invoice, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// GetForUpdate lấy hóa đơn và khóa dòng cho tới hết transaction
+func (r *InvoiceRepository) GetForUpdate(ctx context.Context, id uint) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&invoice, id).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := Conn(ctx, r.db).Where("invoice_id = ?", id).Order("id").Find(&invoice.Items).Error; err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// NextNumber cấp số tiếp theo cho ký hiệu và kỳ. Phải được gọi trong transaction:
+// dòng sequence bị khóa tới khi commit nên các lần phát hành đồng thời được xếp hàng,
+// và nếu transaction rollback thì số cũng không bị tiêu hao (không có khoảng trống).
+func (r *InvoiceRepository) NextNumber(ctx context.Context, series string, period int) (int, error) {
+	seq := models.InvoiceSequence{Series: series, FiscalPeriod: period}
+	if err := Conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&seq).Error; err != nil {
+		return 0, err
+	}
+
+	// UPDATE khóa dòng trên mọi dialect; đọc lại trong cùng transaction thay cho RETURNING (MySQL không hỗ trợ)
+	if err := Conn(ctx, r.db).Model(&models.InvoiceSequence{}).Where("series = ? AND fiscal_period = ?", series, period).
+		Update("last_number", gorm.Expr("last_number + 1")).Error; err != nil {
+		return 0, err
+	}
+	var next int
+	err := Conn(ctx, r.db).Model(&models.InvoiceSequence{}).Where("series = ? AND fiscal_period = ?", series, period).
+		Pluck("last_number", &next).Error
+	return next, err
+}
+
+// MarkIssued lưu số hóa đơn, thời điểm phát hành và mã băm nội dung
+func (r *InvoiceRepository) MarkIssued(ctx context.Context, invoice *models.Invoice) error {
+	return Conn(ctx, r.db).Model(&models.Invoice{}).Where("id = ? AND status = ?", invoice.ID, models.InvoiceDraft).
+		Updates(map[string]interface{}{
+			"series":        invoice.Series,
+			"fiscal_period": invoice.FiscalPeriod,
+			"sequence":      invoice.Sequence,
+			"number":        invoice.Number,
+			"status":        models.InvoiceIssued,
+			"content_hash":  invoice.ContentHash,
+			"issued_at":     invoice.IssuedAt,
+		}).Error
+}
+
+// MarkCancelled hủy hóa đơn đã phát hành; số hóa đơn vẫn được giữ để dãy số không bị đứt
+func (r *InvoiceRepository) MarkCancelled(ctx context.Context, invoice *models.Invoice) error {
+	return Conn(ctx, r.db).Model(&models.Invoice{}).Where("id = ? AND status = ?", invoice.ID, models.InvoiceIssued).
+		Updates(map[string]interface{}{
+			"status":        models.InvoiceCancelled,
+			"cancelled_at":  invoice.CancelledAt,
+			"cancel_reason": invoice.CancelReason,
+		}).Error
+}
+
+// DeleteDraft xóa hóa đơn nháp (chưa được cấp số)
+func (r *InvoiceRepository) DeleteDraft(ctx context.Context, id uint) (int64, error) {
+	result := Conn(ctx, r.db).Where("id = ? AND status = ?", id, models.InvoiceDraft).Delete(&models.Invoice{})
+	return result.RowsAffected, result.Error
+}
+
+// GetAll lấy danh sách hóa đơn với bộ lọc và phân trang
+func (r *InvoiceRepository) GetAll(ctx context.Context, query *models.InvoiceQueryParams) ([]models.Invoice, int64, error) {
+	var invoices []models.Invoice
+	var total int64
+
+	dbQuery := Conn(ctx, r.db).Model(&models.Invoice{})
+	if query.Status != "" {
+		dbQuery = dbQuery.Where("status = ?", query.Status)
+	}
+	if query.Series != "" {
+		dbQuery = dbQuery.Where("series = ?", query.Series)
+	}
+	if query.FiscalPeriod != 0 {
+		dbQuery = dbQuery.Where("fiscal_period = ?", query.FiscalPeriod)
+	}
+	if query.UserID != 0 {
+		dbQuery = dbQuery.Where("user_id = ?", query.UserID)
+	}
+
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.Limit
+	err := dbQuery.Order("created_at DESC").Offset(offset).Limit(query.Limit).Find(&invoices).Error
+	return invoices, total, err
+}
+
+// ListByUser lấy mọi hóa đơn của user kèm các dòng hàng
+func (r *InvoiceRepository) ListByUser(ctx context.Context, userID uint) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := Conn(ctx, r.db).Preload("Items").Where("user_id = ?", userID).Order("created_at").Find(&invoices).Error
+	return invoices, err
+}
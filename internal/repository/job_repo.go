@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type JobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// Create đưa job mới vào hàng đợi
+func (r *JobRepository) Create(ctx context.Context, job *models.Job) error {
+	return Conn(ctx, r.db).Create(job).Error
+}
+
+// GetByID lấy job theo ID
+func (r *JobRepository) GetByID(ctx context.Context, id uint) (*models.Job, error) {
+	var job models.Job
+	if err := Conn(ctx, r.db).First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Claim chuyển tối đa limit job đã tới hạn sang trạng thái running và trả về chúng.
+// Job running bị khóa lâu hơn lockTimeout (worker chết giữa chừng) được nhận lại.
+// SKIP LOCKED cho phép nhiều worker chạy song song mà không nhận trùng job.
+func (r *JobRepository) Claim(ctx context.Context, types []string, limit int, lockTimeout time.Duration) ([]models.Job, error) {
+	var jobs []models.Job
+	now := time.Now()
+	err := Conn(ctx, r.db).Raw(`
+		UPDATE jobs SET status = ?, locked_at = ?, attempts = attempts + 1, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM jobs
+			WHERE type IN ? AND (
+				(status = ? AND run_at <= ?) OR
+				(status = ? AND locked_at < ?)
+			)
+			ORDER BY run_at ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.JobRunning, now, now,
+		types,
+		models.JobPending, now,
+		models.JobRunning, now.Add(-lockTimeout),
+		limit,
+	).Scan(&jobs).Error
+	return jobs, err
+}
+
+// Complete đánh dấu job chạy thành công
+func (r *JobRepository) Complete(ctx context.Context, id uint) error {
+	now := time.Now()
+	return Conn(ctx, r.db).Model(&models.Job{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       models.JobSucceeded,
+		"locked_at":    nil,
+		"last_error":   "",
+		"completed_at": now,
+	}).Error
+}
+
+// Fail ghi nhận lỗi của job: lên lịch chạy lại tại retryAt, hoặc chuyển sang failed nếu retryAt là nil
+func (r *JobRepository) Fail(ctx context.Context, id uint, jobErr string, retryAt *time.Time) error {
+	updates := map[string]interface{}{
+		"locked_at":  nil,
+		"last_error": jobErr,
+	}
+	if retryAt != nil {
+		updates["status"] = models.JobPending
+		updates["run_at"] = *retryAt
+	} else {
+		updates["status"] = models.JobFailed
+	}
+	return Conn(ctx, r.db).Model(&models.Job{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// Retry đưa job trở lại hàng đợi để chạy ngay với số lần thử được đặt lại
+func (r *JobRepository) Retry(ctx context.Context, id uint) error {
+	return Conn(ctx, r.db).Model(&models.Job{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":    models.JobPending,
+		"attempts":  0,
+		"run_at":    time.Now(),
+		"locked_at": nil,
+	}).Error
+}
+
+// CountByStatus đếm số job theo trạng thái
+func (r *JobRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := Conn(ctx, r.db).Model(&models.Job{}).Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// GetAll lấy danh sách job với bộ lọc và phân trang
+func (r *JobRepository) GetAll(ctx context.Context, query *models.JobQueryParams) ([]models.Job, int64, error) {
+	var jobs []models.Job
+	var total int64
+
+	dbQuery := Conn(ctx, r.db).Model(&models.Job{})
+	if query.Status != "" {
+		dbQuery = dbQuery.Where("status = ?", query.Status)
+	}
+	if query.Type != "" {
+		dbQuery = dbQuery.Where("type = ?", query.Type)
+	}
+
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.Limit
+	if err := dbQuery.Order("created_at DESC").Offset(offset).Limit(query.Limit).Find(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
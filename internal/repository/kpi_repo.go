@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// KPITimeZone là múi giờ dùng để chia ngày khi tổng hợp KPI
+const KPITimeZone = "Asia/Ho_Chi_Minh"
+
+// KPILocation là KPITimeZone dạng *time.Location; dùng múi giờ cố định UTC+7 nếu máy không có tzdata
+var KPILocation = func() *time.Location {
+	if loc, err := time.LoadLocation(KPITimeZone); err == nil {
+		return loc
+	}
+	return time.FixedZone("ICT", 7*60*60)
+}()
+
+type KPIRepository struct {
+	db *gorm.DB
+}
+
+func NewKPIRepository(db *gorm.DB) *KPIRepository {
+	return &KPIRepository{db: db}
+}
+
+// Rollup tính lại KPI cho các ngày trong [from, to] (theo ngày, tính cả hai đầu) và ghi đè vào
+// daily_kpis. Doanh thu lấy từ hóa đơn đã phát hành (hóa đơn bị hủy không được tính);
+// khách hàng mới là user (không phải admin) đăng ký trong ngày.
+func (r *KPIRepository) Rollup(ctx context.Context, from, to time.Time) (int64, error) {
+	fromDay := from.Format("2006-01-02")
+	toDay := to.Format("2006-01-02")
+	result := Conn(ctx, r.db).Exec(`
+		INSERT INTO daily_kpis (day, orders, revenue, average_order_value, new_customers, updated_at)
+		SELECT d.day,
+			COALESCE(i.orders, 0),
+			COALESCE(i.revenue, 0),
+			CASE WHEN COALESCE(i.orders, 0) > 0 THEN ROUND((i.revenue / i.orders)::numeric, 2) ELSE 0 END,
+			COALESCE(u.new_customers, 0),
+			NOW()
+		FROM (SELECT gs::date AS day FROM generate_series(?::date, ?::date, INTERVAL '1 day') AS gs) d
+		LEFT JOIN (
+			SELECT (issued_at AT TIME ZONE '`+KPITimeZone+`')::date AS day, COUNT(*) AS orders, SUM(total) AS revenue
+			FROM invoices
+			WHERE status = 'issued'
+				AND issued_at >= (?::date)::timestamp AT TIME ZONE '`+KPITimeZone+`'
+				AND issued_at < (?::date + 1)::timestamp AT TIME ZONE '`+KPITimeZone+`'
+			GROUP BY 1
+		) i ON i.day = d.day
+		LEFT JOIN (
+			SELECT (created_at AT TIME ZONE '`+KPITimeZone+`')::date AS day, COUNT(*) AS new_customers
+			FROM users
+			WHERE role <> 'admin'
+				AND created_at >= (?::date)::timestamp AT TIME ZONE '`+KPITimeZone+`'
+				AND created_at < (?::date + 1)::timestamp AT TIME ZONE '`+KPITimeZone+`'
+			GROUP BY 1
+		) u ON u.day = d.day
+		ON CONFLICT (day) DO UPDATE SET
+			orders = EXCLUDED.orders,
+			revenue = EXCLUDED.revenue,
+			average_order_value = EXCLUDED.average_order_value,
+			new_customers = EXCLUDED.new_customers,
+			updated_at = EXCLUDED.updated_at`,
+		fromDay, toDay, fromDay, toDay, fromDay, toDay)
+	return result.RowsAffected, result.Error
+}
+
+// LastDay trả về ngày gần nhất đã được tổng hợp; ok = false nếu bảng còn trống
+func (r *KPIRepository) LastDay(ctx context.Context) (day time.Time, ok bool, err error) {
+	var kpi models.DailyKPI
+	err = Conn(ctx, r.db).Order("day DESC").Limit(1).Find(&kpi).Error
+	if err != nil || kpi.Day.IsZero() {
+		return time.Time{}, false, err
+	}
+	return kpi.Day, true, nil
+}
+
+// GetRange lấy KPI đã tổng hợp của các ngày trong [from, to], sắp xếp theo ngày
+func (r *KPIRepository) GetRange(ctx context.Context, from, to time.Time) ([]models.DailyKPI, error) {
+	var days []models.DailyKPI
+	err := Conn(ctx, r.db).Where("day BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("day ASC").Find(&days).Error
+	return days, err
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/database"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/partition"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginEventPartition là cấu hình phân vùng theo tháng của bảng login_events
+var LoginEventPartition = partition.Table{Name: "login_events", Column: "created_at", Ahead: 3}
+
+// MigrateLoginEvents tạo bảng login_events dạng phân vùng trên Postgres, cùng các index của models.LoginEvent.
+// Database khác Postgres dùng bảng thường.
+func MigrateLoginEvents(db *gorm.DB) error {
+	if !database.IsPostgres(db) {
+		return db.AutoMigrate(&models.LoginEvent{})
+	}
+	err := partition.CreatePartitionedTable(db, LoginEventPartition.Name, `
+		id BIGSERIAL,
+		user_id BIGINT NOT NULL,
+		fingerprint VARCHAR(64) NOT NULL,
+		user_agent VARCHAR(512),
+		ip VARCHAR(45),
+		country VARCHAR(2),
+		reasons VARCHAR(100),
+		confirmed_by_email BOOLEAN NOT NULL DEFAULT FALSE,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (id, created_at)`, LoginEventPartition.Column)
+	if err != nil {
+		return err
+	}
+	for _, index := range []string{
+		`CREATE INDEX IF NOT EXISTS idx_login_events_user_fingerprint ON login_events (user_id, fingerprint)`,
+		`CREATE INDEX IF NOT EXISTS idx_login_events_user_country ON login_events (user_id, country)`,
+		`CREATE INDEX IF NOT EXISTS idx_login_events_created_at ON login_events (created_at)`,
+	} {
+		if err := db.Exec(index).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type LoginEventRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginEventRepository(db *gorm.DB) *LoginEventRepository {
+	return &LoginEventRepository{db: db}
+}
+
+// Create lưu một lần đăng nhập thành công
+func (r *LoginEventRepository) Create(ctx context.Context, event *models.LoginEvent) error {
+	return Conn(ctx, r.db).Create(event).Error
+}
+
+// HasAny cho biết user đã có lần đăng nhập nào được ghi lại hay chưa
+func (r *LoginEventRepository) HasAny(ctx context.Context, userID uint) (bool, error) {
+	return r.exists(Conn(ctx, r.db).Where("user_id = ?", userID))
+}
+
+// HasFingerprint cho biết user đã từng đăng nhập thành công từ thiết bị có fingerprint này
+func (r *LoginEventRepository) HasFingerprint(ctx context.Context, userID uint, fingerprint string) (bool, error) {
+	return r.exists(Conn(ctx, r.db).Where("user_id = ? AND fingerprint = ?", userID, fingerprint))
+}
+
+// HasCountry cho biết user đã từng đăng nhập thành công từ quốc gia country; country rỗng cho biết user có
+// lần đăng nhập nào đã biết quốc gia hay chưa
+func (r *LoginEventRepository) HasCountry(ctx context.Context, userID uint, country string) (bool, error) {
+	if country == "" {
+		return r.exists(Conn(ctx, r.db).Where("user_id = ? AND country <> ''", userID))
+	}
+	return r.exists(Conn(ctx, r.db).Where("user_id = ? AND country = ?", userID, country))
+}
+
+func (r *LoginEventRepository) exists(query *gorm.DB) (bool, error) {
+	var ids []uint
+	err := query.Model(&models.LoginEvent{}).Limit(1).Pluck("id", &ids).Error
+	return len(ids) > 0, err
+}
+
+// ListByUser lấy tối đa limit lần đăng nhập gần nhất của user (limit bằng 0 là tất cả), mới nhất trước
+func (r *LoginEventRepository) ListByUser(ctx context.Context, userID uint, limit int) ([]models.LoginEvent, error) {
+	var loginEvents []models.LoginEvent
+	query := Conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&loginEvents).Error
+	return loginEvents, err
+}
+
+// CreateChallenge lưu lần đăng nhập đáng ngờ đang chờ xác nhận
+func (r *LoginEventRepository) CreateChallenge(ctx context.Context, challenge *models.LoginChallenge) error {
+	return Conn(ctx, r.db).Create(challenge).Error
+}
+
+// GetChallengeForUpdate lấy lần đăng nhập chờ xác nhận theo hash của token và khóa dòng cho tới hết
+// transaction
+func (r *LoginEventRepository) GetChallengeForUpdate(ctx context.Context, tokenHash string) (*models.LoginChallenge, error) {
+	var challenge models.LoginChallenge
+	err := Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", tokenHash).First(&challenge).Error
+	if err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// ConfirmChallenge ghi thời điểm xác nhận của lần đăng nhập chờ xác nhận
+func (r *LoginEventRepository) ConfirmChallenge(ctx context.Context, id uint, at time.Time) error {
+	return Conn(ctx, r.db).Model(&models.LoginChallenge{}).Where("id = ?", id).Update("confirmed_at", at).Error
+}
+
+// DeleteUserData xóa lịch sử đăng nhập và các lần đăng nhập chờ xác nhận của user
+func (r *LoginEventRepository) DeleteUserData(ctx context.Context, userID uint) error {
+	if err := Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&models.LoginChallenge{}).Error; err != nil {
+		return err
+	}
+	return Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&models.LoginEvent{}).Error
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type NewsletterRepository struct {
+	db *gorm.DB
+}
+
+func NewNewsletterRepository(db *gorm.DB) *NewsletterRepository {
+	return &NewsletterRepository{db: db}
+}
+
+// Create lưu địa chỉ đăng ký mới
+func (r *NewsletterRepository) Create(ctx context.Context, subscriber *models.NewsletterSubscriber) error {
+	return Conn(ctx, r.db).Create(subscriber).Error
+}
+
+// Save cập nhật toàn bộ các trường của địa chỉ đăng ký
+func (r *NewsletterRepository) Save(ctx context.Context, subscriber *models.NewsletterSubscriber) error {
+	return Conn(ctx, r.db).Save(subscriber).Error
+}
+
+// GetByEmail lấy địa chỉ đăng ký theo email (đã chuẩn hóa chữ thường)
+func (r *NewsletterRepository) GetByEmail(ctx context.Context, email string) (*models.NewsletterSubscriber, error) {
+	return r.first(ctx, "email = ?", email)
+}
+
+// GetByConfirmTokenHash lấy địa chỉ đăng ký có token xác nhận trùng hash
+func (r *NewsletterRepository) GetByConfirmTokenHash(ctx context.Context, hash string) (*models.NewsletterSubscriber, error) {
+	return r.first(ctx, "confirm_token_hash = ?", hash)
+}
+
+// GetByUnsubscribeToken lấy địa chỉ đăng ký theo token hủy đăng ký
+func (r *NewsletterRepository) GetByUnsubscribeToken(ctx context.Context, token string) (*models.NewsletterSubscriber, error) {
+	return r.first(ctx, "unsubscribe_token = ?", token)
+}
+
+func (r *NewsletterRepository) first(ctx context.Context, query string, arg interface{}) (*models.NewsletterSubscriber, error) {
+	var subscriber models.NewsletterSubscriber
+	if err := Conn(ctx, r.db).Where(query, arg).First(&subscriber).Error; err != nil {
+		return nil, err
+	}
+	return &subscriber, nil
+}
+
+// EachConfirmed gọi fn lần lượt với từng lô (tối đa batchSize) địa chỉ đã xác nhận theo thứ tự ID, để xuất
+// danh sách lớn mà không nạp hết vào bộ nhớ
+func (r *NewsletterRepository) EachConfirmed(ctx context.Context, batchSize int, fn func([]models.NewsletterSubscriber) error) error {
+	var batch []models.NewsletterSubscriber
+	result := Conn(ctx, r.db).Where("status = ?", models.NewsletterConfirmed).Order("id").
+		FindInBatches(&batch, batchSize, func(*gorm.DB, int) error {
+			return fn(batch)
+		})
+	return result.Error
+}
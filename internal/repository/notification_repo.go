@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// UpsertDevice đăng ký token thiết bị; token đã tồn tại được chuyển sang user hiện tại
+func (r *NotificationRepository) UpsertDevice(ctx context.Context, device *models.DeviceToken) error {
+	device.LastSeenAt = time.Now()
+	return Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "provider", "last_seen_at", "updated_at"}),
+	}).Create(device).Error
+}
+
+// ListDevices lấy các thiết bị của user
+func (r *NotificationRepository) ListDevices(ctx context.Context, userID uint) ([]models.DeviceToken, error) {
+	var devices []models.DeviceToken
+	err := Conn(ctx, r.db).Where("user_id = ?", userID).Order("last_seen_at DESC").Find(&devices).Error
+	return devices, err
+}
+
+// DeleteDevice xóa thiết bị của user, trả về false nếu không tìm thấy
+func (r *NotificationRepository) DeleteDevice(ctx context.Context, userID, id uint) (bool, error) {
+	result := Conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).Delete(&models.DeviceToken{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteDeviceByToken xóa token mà nhà cung cấp push báo là không còn hợp lệ
+func (r *NotificationRepository) DeleteDeviceByToken(ctx context.Context, token string) error {
+	return Conn(ctx, r.db).Where("token = ?", token).Delete(&models.DeviceToken{}).Error
+}
+
+// GetPreference lấy lựa chọn kênh của user cho một sự kiện; trả về giá trị mặc định (mọi kênh bật) nếu chưa có
+func (r *NotificationRepository) GetPreference(ctx context.Context, userID uint, event string) (*models.NotificationPreference, error) {
+	pref := models.NotificationPreference{UserID: userID, Event: event, Email: true, Push: true, Webhook: true}
+	err := Conn(ctx, r.db).Where("user_id = ? AND event = ?", userID, event).Limit(1).Find(&pref).Error
+	return &pref, err
+}
+
+// ListPreferences lấy mọi lựa chọn kênh thông báo đã lưu của user
+func (r *NotificationRepository) ListPreferences(ctx context.Context, userID uint) ([]models.NotificationPreference, error) {
+	var prefs []models.NotificationPreference
+	err := Conn(ctx, r.db).Where("user_id = ?", userID).Order("event").Find(&prefs).Error
+	return prefs, err
+}
+
+// SavePreferences lưu lựa chọn kênh của user cho các sự kiện, ghi đè lựa chọn đã có
+func (r *NotificationRepository) SavePreferences(ctx context.Context, prefs []models.NotificationPreference) error {
+	return Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "event"}},
+		DoUpdates: clause.AssignmentColumns([]string{"email", "push", "webhook", "updated_at"}),
+	}).Create(&prefs).Error
+}
+
+// DeleteUserData xóa thiết bị và lựa chọn kênh thông báo của user
+func (r *NotificationRepository) DeleteUserData(ctx context.Context, userID uint) error {
+	if err := Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&models.DeviceToken{}).Error; err != nil {
+		return err
+	}
+	return Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&models.NotificationPreference{}).Error
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/database"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrderRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderRepository(db *gorm.DB) *OrderRepository {
+	return &OrderRepository{db: db}
+}
+
+// Create lưu đơn hàng cùng các dòng hàng
+func (r *OrderRepository) Create(ctx context.Context, order *models.Order) error {
+	return Conn(ctx, r.db).Create(order).Error
+}
+
+// GetByID lấy đơn hàng kèm các dòng hàng và khuyến mãi đã áp dụng
+func (r *OrderRepository) GetByID(ctx context.Context, id uint) (*models.Order, error) {
+	var order models.Order
+	err := Conn(ctx, r.db).Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Discounts", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetAll lấy một trang đơn hàng kèm các dòng hàng theo bộ lọc của admin; mặc định đơn mới nhất trước
+func (r *OrderRepository) GetAll(ctx context.Context, query *models.AdminOrderQueryParams) ([]models.Order, int64, error) {
+	var orders []models.Order
+	var total int64
+
+	dbQuery := Conn(ctx, r.db).Model(&models.Order{})
+	if query.Status != "" {
+		dbQuery = dbQuery.Where("status = ?", query.Status)
+	}
+	if query.UserID != 0 {
+		dbQuery = dbQuery.Where("user_id = ?", query.UserID)
+	}
+	// Tìm cả khách đã xóa tài khoản vì đơn của họ vẫn còn
+	if query.Customer != "" {
+		pattern := "%" + query.Customer + "%"
+		dbQuery = dbQuery.Where("user_id IN (?)", Conn(ctx, r.db).Unscoped().Model(&models.User{}).Select("id").
+			Where(database.ILike(r.db, "username", "email", "full_name"), pattern, pattern, pattern))
+	}
+	if !query.StartDate.IsZero() {
+		dbQuery = dbQuery.Where("created_at >= ?", query.StartDate)
+	}
+	if !query.EndDate.IsZero() {
+		dbQuery = dbQuery.Where("created_at <= ?", query.EndDate)
+	}
+	if query.MinTotal > 0 {
+		dbQuery = dbQuery.Where("total >= ?", query.MinTotal)
+	}
+	if query.MaxTotal > 0 {
+		dbQuery = dbQuery.Where("total <= ?", query.MaxTotal)
+	}
+
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Chỉ sắp xếp theo các cột trong danh sách cho phép; id giữ thứ tự ổn định giữa các trang
+	sortField, order := "created_at", "DESC"
+	if query.SortBy == "total" {
+		sortField = "total"
+	}
+	if query.Order == "asc" {
+		order = "ASC"
+	}
+	offset := (query.Page - 1) * query.Limit
+	err := dbQuery.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Order(sortField + " " + order).Order("id " + order).
+		Offset(offset).Limit(query.Limit).Find(&orders).Error
+	return orders, total, err
+}
+
+// ListByUser lấy một trang đơn hàng của user kèm các dòng hàng, mới nhất trước
+func (r *OrderRepository) ListByUser(ctx context.Context, userID uint, query *models.OrderQueryParams) ([]models.Order, int64, error) {
+	var orders []models.Order
+	var total int64
+
+	dbQuery := Conn(ctx, r.db).Model(&models.Order{}).Where("user_id = ?", userID)
+	if query.Status != "" {
+		dbQuery = dbQuery.Where("status = ?", query.Status)
+	}
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.Limit
+	err := dbQuery.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Order("created_at DESC").Order("id DESC").Offset(offset).Limit(query.Limit).Find(&orders).Error
+	return orders, total, err
+}
+
+// ListAllByUser lấy mọi đơn hàng của user kèm các dòng hàng và khuyến mãi đã áp dụng, cũ nhất trước
+func (r *OrderRepository) ListAllByUser(ctx context.Context, userID uint) ([]models.Order, error) {
+	orders := []models.Order{}
+	err := Conn(ctx, r.db).Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Discounts", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("user_id = ?", userID).Order("id").Find(&orders).Error
+	return orders, err
+}
+
+// GetForUpdate lấy đơn hàng kèm các dòng hàng và khóa dòng cho tới hết transaction
+func (r *OrderRepository) GetForUpdate(ctx context.Context, id uint) (*models.Order, error) {
+	var order models.Order
+	if err := Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error; err != nil {
+		return nil, err
+	}
+	if err := Conn(ctx, r.db).Where("order_id = ?", id).Order("id").Find(&order.Items).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// UpdateStatus chuyển đơn từ trạng thái from sang to cùng các cột khác trong fields.
+// Trả về số dòng được cập nhật; 0 nghĩa là đơn không còn ở trạng thái from.
+func (r *OrderRepository) UpdateStatus(ctx context.Context, id uint, from, to string, fields map[string]interface{}) (int64, error) {
+	updates := map[string]interface{}{"status": to}
+	for k, v := range fields {
+		updates[k] = v
+	}
+	result := Conn(ctx, r.db).Model(&models.Order{}).Where("id = ? AND status = ?", id, from).Updates(updates)
+	return result.RowsAffected, result.Error
+}
+
+// Update cập nhật các cột của đơn mà không đổi trạng thái
+func (r *OrderRepository) Update(ctx context.Context, id uint, fields map[string]interface{}) error {
+	return Conn(ctx, r.db).Model(&models.Order{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// ScrubShippingAddresses xóa tên, số điện thoại và số nhà người nhận khỏi các đơn của user khi tài
+// khoản bị ẩn danh hóa; tỉnh/huyện/xã được giữ cho thống kê
+func (r *OrderRepository) ScrubShippingAddresses(ctx context.Context, userID uint) error {
+	return Conn(ctx, r.db).Model(&models.Order{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+		"shipping_recipient_name": "",
+		"shipping_phone":          "",
+		"shipping_street":         "",
+	}).Error
+}
+
+// AddHistory ghi một lần đổi trạng thái của đơn
+func (r *OrderRepository) AddHistory(ctx context.Context, entry *models.OrderStatusHistory) error {
+	return Conn(ctx, r.db).Create(entry).Error
+}
+
+// ListHistory lấy lịch sử trạng thái của đơn theo thứ tự thời gian
+func (r *OrderRepository) ListHistory(ctx context.Context, orderID uint) ([]models.OrderStatusHistory, error) {
+	var history []models.OrderStatusHistory
+	err := Conn(ctx, r.db).Where("order_id = ?", orderID).Order("created_at, id").Find(&history).Error
+	return history, err
+}
+
+// HasPurchased cho biết user có đơn đã thanh toán (kể cả đã giao) chứa sản phẩm hay không
+func (r *OrderRepository) HasPurchased(ctx context.Context, userID, productID uint) (bool, error) {
+	var count int64
+	err := Conn(ctx, r.db).Table("order_items oi").
+		Joins("JOIN orders o ON o.id = oi.order_id").
+		Where("o.user_id = ? AND oi.product_id = ? AND o.status IN ?", userID, productID,
+			[]string{models.OrderStatusPaid, models.OrderStatusFulfilled, models.OrderStatusDelivered}).
+		Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+// HasDelivered cho biết user có đơn đã giao chứa sản phẩm hay không
+func (r *OrderRepository) HasDelivered(ctx context.Context, userID, productID uint) (bool, error) {
+	var count int64
+	err := Conn(ctx, r.db).Table("order_items oi").
+		Joins("JOIN orders o ON o.id = oi.order_id").
+		Where("o.user_id = ? AND oi.product_id = ? AND o.status = ?", userID, productID, models.OrderStatusDelivered).
+		Limit(1).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrphanedUploadRepository struct {
+	db *gorm.DB
+}
+
+func NewOrphanedUploadRepository(db *gorm.DB) *OrphanedUploadRepository {
+	return &OrphanedUploadRepository{db: db}
+}
+
+// List lấy mọi file mồ côi đang được theo dõi
+func (r *OrphanedUploadRepository) List(ctx context.Context) ([]models.OrphanedUpload, error) {
+	var uploads []models.OrphanedUpload
+	err := Conn(ctx, r.db).Find(&uploads).Error
+	return uploads, err
+}
+
+// Add ghi nhận các file mồ côi mới phát hiện; file đã được theo dõi giữ nguyên thời điểm phát hiện đầu tiên
+func (r *OrphanedUploadRepository) Add(ctx context.Context, uploads []models.OrphanedUpload) error {
+	if len(uploads) == 0 {
+		return nil
+	}
+	return Conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(uploads, 500).Error
+}
+
+// Delete bỏ theo dõi các file (đã bị xóa hoặc được tham chiếu lại)
+func (r *OrphanedUploadRepository) Delete(ctx context.Context, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	return Conn(ctx, r.db).Where("path IN ?", paths).Delete(&models.OrphanedUpload{}).Error
+}
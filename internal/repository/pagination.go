@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"github.com/NgTruong624/project_backend/internal/database"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Total là tổng số dòng của một trang danh sách theo chế độ đếm của truy vấn
+type Total struct {
+	// Count là tổng chính xác, hoặc ước lượng khi Estimated; bằng 0 với chế độ none
+	Count     int64
+	Mode      string
+	Estimated bool
+	// HasMore cho biết còn dòng sau trang hiện tại
+	HasMore bool
+}
+
+// counted là một dòng kèm tổng số dòng khớp bộ lọc lấy bằng COUNT(*) OVER ()
+type counted[T any] struct {
+	Row        T `gorm:"embedded"`
+	TotalCount int64
+}
+
+// findPage lấy một trang của ordered (đã lọc và sắp xếp) theo chế độ đếm mode.
+// filtered là cùng truy vấn trước khi sắp xếp, dùng khi cần một truy vấn COUNT riêng;
+// unfiltered cho biết truy vấn không có điều kiện lọc nên có thể dùng ước lượng của bảng.
+//   - exact: trang và tổng trong một truy vấn bằng COUNT(*) OVER ()
+//   - estimate: reltuples của bảng trên Postgres khi không lọc, ngược lại như exact
+//   - none: lấy limit+1 dòng để biết còn trang sau, không đếm
+func findPage[T any](filtered, ordered *gorm.DB, table, mode string, unfiltered bool, offset, limit int) ([]T, Total, error) {
+	if mode == "" {
+		mode = models.CountExact
+	}
+	total := Total{Mode: mode}
+
+	exact := mode != models.CountNone
+	if mode == models.CountEstimate && unfiltered {
+		estimate, ok, err := estimateRows(filtered, table)
+		if err != nil {
+			return nil, Total{}, err
+		}
+		if ok {
+			total.Count, total.Estimated = estimate, true
+			exact = false
+		}
+	}
+
+	if !exact {
+		var rows []T
+		if err := ordered.Offset(offset).Limit(limit + 1).Find(&rows).Error; err != nil {
+			return nil, Total{}, err
+		}
+		if len(rows) > limit {
+			rows, total.HasMore = rows[:limit], true
+		}
+		return rows, total, nil
+	}
+
+	var rows []counted[T]
+	if err := ordered.Select(table + ".*, COUNT(*) OVER () AS total_count").Offset(offset).Limit(limit).Find(&rows).Error; err != nil {
+		return nil, Total{}, err
+	}
+	items := make([]T, len(rows))
+	for i, row := range rows {
+		items[i] = row.Row
+		total.Count = row.TotalCount
+	}
+	// Trang rỗng ở offset > 0 (vượt quá trang cuối) không mang tổng nên cần thêm một truy vấn COUNT
+	if len(rows) == 0 && offset > 0 {
+		if err := filtered.Count(&total.Count).Error; err != nil {
+			return nil, Total{}, err
+		}
+	}
+	total.HasMore = int64(offset+len(items)) < total.Count
+	return items, total, nil
+}
+
+// estimateRows đọc số dòng ước lượng của bảng từ thống kê của Postgres (cập nhật bởi ANALYZE/autovacuum).
+// ok là false khi không phải Postgres hoặc bảng chưa từng được ANALYZE.
+func estimateRows(db *gorm.DB, table string) (int64, bool, error) {
+	if !database.IsPostgres(db) {
+		return 0, false, nil
+	}
+	var estimate float64
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Raw("SELECT reltuples FROM pg_class WHERE oid = to_regclass(?)", table).Scan(&estimate).Error
+	if err != nil {
+		return 0, false, err
+	}
+	// reltuples là -1 (Postgres 14+) hoặc 0 khi bảng chưa được ANALYZE
+	if estimate <= 0 {
+		return 0, false, nil
+	}
+	return int64(estimate), true, nil
+}
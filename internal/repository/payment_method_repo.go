@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentMethodRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentMethodRepository(db *gorm.DB) *PaymentMethodRepository {
+	return &PaymentMethodRepository{db: db}
+}
+
+// GetCustomer lấy customer của user tại provider
+func (r *PaymentMethodRepository) GetCustomer(ctx context.Context, userID uint, provider string) (*models.PaymentCustomer, error) {
+	var customer models.PaymentCustomer
+	if err := Conn(ctx, r.db).Where("user_id = ? AND provider = ?", userID, provider).First(&customer).Error; err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// CreateCustomer lưu customer mới; nếu request khác đã tạo trước thì giữ bản ghi cũ
+func (r *PaymentMethodRepository) CreateCustomer(ctx context.Context, customer *models.PaymentCustomer) error {
+	return Conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(customer).Error
+}
+
+// List lấy các phương thức thanh toán của user, phương thức mặc định đứng đầu
+func (r *PaymentMethodRepository) List(ctx context.Context, userID uint) ([]models.PaymentMethod, error) {
+	var methods []models.PaymentMethod
+	err := Conn(ctx, r.db).Where("user_id = ?", userID).Order("is_default DESC, created_at DESC").Find(&methods).Error
+	return methods, err
+}
+
+// Get lấy một phương thức thanh toán của user
+func (r *PaymentMethodRepository) Get(ctx context.Context, userID, id uint) (*models.PaymentMethod, error) {
+	var method models.PaymentMethod
+	if err := Conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).First(&method).Error; err != nil {
+		return nil, err
+	}
+	return &method, nil
+}
+
+// GetDefault lấy phương thức mặc định của user
+func (r *PaymentMethodRepository) GetDefault(ctx context.Context, userID uint) (*models.PaymentMethod, error) {
+	var method models.PaymentMethod
+	if err := Conn(ctx, r.db).Where("user_id = ? AND is_default", userID).First(&method).Error; err != nil {
+		return nil, err
+	}
+	return &method, nil
+}
+
+// Count đếm số phương thức thanh toán của user
+func (r *PaymentMethodRepository) Count(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.PaymentMethod{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// Create lưu phương thức thanh toán mới
+func (r *PaymentMethodRepository) Create(ctx context.Context, method *models.PaymentMethod) error {
+	return Conn(ctx, r.db).Create(method).Error
+}
+
+// Delete xóa phương thức thanh toán
+func (r *PaymentMethodRepository) Delete(ctx context.Context, id uint) error {
+	return Conn(ctx, r.db).Delete(&models.PaymentMethod{}, id).Error
+}
+
+// SetDefault đặt phương thức id làm mặc định và bỏ mặc định các phương thức khác của user
+func (r *PaymentMethodRepository) SetDefault(ctx context.Context, userID, id uint) error {
+	if err := Conn(ctx, r.db).Model(&models.PaymentMethod{}).Where("user_id = ? AND is_default AND id <> ?", userID, id).
+		Update("is_default", false).Error; err != nil {
+		return err
+	}
+	return Conn(ctx, r.db).Model(&models.PaymentMethod{}).Where("id = ? AND user_id = ?", id, userID).
+		Update("is_default", true).Error
+}
+
+// Latest lấy phương thức được thêm gần nhất của user (dùng để chọn mặc định mới)
+func (r *PaymentMethodRepository) Latest(ctx context.Context, userID uint) (*models.PaymentMethod, error) {
+	var method models.PaymentMethod
+	if err := Conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at DESC").First(&method).Error; err != nil {
+		return nil, err
+	}
+	return &method, nil
+}
+
+// ListCustomers lấy các customer của user tại mọi provider
+func (r *PaymentMethodRepository) ListCustomers(ctx context.Context, userID uint) ([]models.PaymentCustomer, error) {
+	var customers []models.PaymentCustomer
+	err := Conn(ctx, r.db).Where("user_id = ?", userID).Find(&customers).Error
+	return customers, err
+}
+
+// DeleteUserData xóa mọi phương thức thanh toán và customer của user
+func (r *PaymentMethodRepository) DeleteUserData(ctx context.Context, userID uint) error {
+	if err := Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&models.PaymentMethod{}).Error; err != nil {
+		return err
+	}
+	return Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&models.PaymentCustomer{}).Error
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+// Create lưu giao dịch thanh toán mới
+func (r *PaymentRepository) Create(ctx context.Context, payment *models.Payment) error {
+	return Conn(ctx, r.db).Create(payment).Error
+}
+
+// Save ghi lại toàn bộ giao dịch thanh toán
+func (r *PaymentRepository) Save(ctx context.Context, payment *models.Payment) error {
+	return Conn(ctx, r.db).Omit("Refunds").Save(payment).Error
+}
+
+// GetByReference lấy giao dịch theo mã tham chiếu gửi tới gateway
+func (r *PaymentRepository) GetByReference(ctx context.Context, provider, reference string) (*models.Payment, error) {
+	var payment models.Payment
+	if err := Conn(ctx, r.db).Where("provider = ? AND reference = ?", provider, reference).First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// GetByReferenceForUpdate lấy giao dịch theo mã của gateway và khóa dòng cho tới hết transaction
+func (r *PaymentRepository) GetByReferenceForUpdate(ctx context.Context, provider, reference string) (*models.Payment, error) {
+	var payment models.Payment
+	err := Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("provider = ? AND reference = ?", provider, reference).First(&payment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// ListByOrder lấy các giao dịch của đơn hàng kèm các lần hoàn tiền, cũ nhất trước
+func (r *PaymentRepository) ListByOrder(ctx context.Context, orderID uint) ([]models.Payment, error) {
+	payments := []models.Payment{}
+	err := Conn(ctx, r.db).Preload("Refunds", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("order_id = ?", orderID).Order("id").Find(&payments).Error
+	return payments, err
+}
+
+// ListByUser lấy các giao dịch trên mọi đơn hàng của user kèm các lần hoàn tiền, cũ nhất trước
+func (r *PaymentRepository) ListByUser(ctx context.Context, userID uint) ([]models.Payment, error) {
+	payments := []models.Payment{}
+	err := Conn(ctx, r.db).Preload("Refunds", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("order_id IN (?)", Conn(ctx, r.db).Model(&models.Order{}).Select("id").Where("user_id = ?", userID)).
+		Order("id").Find(&payments).Error
+	return payments, err
+}
+
+// GetCapturedForUpdate lấy giao dịch đã thu tiền thành công (còn có thể hoàn) của đơn và khóa dòng
+func (r *PaymentRepository) GetCapturedForUpdate(ctx context.Context, orderID uint) (*models.Payment, error) {
+	var payment models.Payment
+	err := Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status IN ?", orderID, []string{models.PaymentStatusSucceeded, models.PaymentStatusPartiallyRefunded}).
+		Order("id DESC").First(&payment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// CreateRefund lưu một lần hoàn tiền
+func (r *PaymentRepository) CreateRefund(ctx context.Context, refund *models.Refund) error {
+	return Conn(ctx, r.db).Create(refund).Error
+}
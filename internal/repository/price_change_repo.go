@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type PriceChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceChangeRepository(db *gorm.DB) *PriceChangeRepository {
+	return &PriceChangeRepository{db: db}
+}
+
+// Create lưu lịch đổi giá mới
+func (r *PriceChangeRepository) Create(ctx context.Context, change *models.ScheduledPriceChange) error {
+	return Conn(ctx, r.db).Create(change).Error
+}
+
+// Get lấy lịch đổi giá của sản phẩm
+func (r *PriceChangeRepository) Get(ctx context.Context, productID, id uint) (*models.ScheduledPriceChange, error) {
+	var change models.ScheduledPriceChange
+	if err := Conn(ctx, r.db).Where("product_id = ?", productID).First(&change, id).Error; err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// ListByProduct lấy mọi lịch đổi giá của sản phẩm, mới bắt đầu gần nhất trước
+func (r *PriceChangeRepository) ListByProduct(ctx context.Context, productID uint) ([]models.ScheduledPriceChange, error) {
+	var changes []models.ScheduledPriceChange
+	err := Conn(ctx, r.db).Where("product_id = ?", productID).Order("starts_at DESC").Find(&changes).Error
+	return changes, err
+}
+
+// Overlaps kiểm tra sản phẩm có lịch đổi giá chưa kết thúc nào chồng lên khoảng [startsAt, endsAt)
+func (r *PriceChangeRepository) Overlaps(ctx context.Context, productID uint, startsAt, endsAt time.Time) (bool, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.ScheduledPriceChange{}).
+		Where("product_id = ? AND status IN ?", productID, []string{models.PriceChangeScheduled, models.PriceChangeActive}).
+		Where("starts_at < ? AND ends_at > ?", endsAt, startsAt).Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+// DueToRevert lấy các lịch đang áp dụng đã tới thời điểm kết thúc
+func (r *PriceChangeRepository) DueToRevert(ctx context.Context, now time.Time) ([]models.ScheduledPriceChange, error) {
+	var changes []models.ScheduledPriceChange
+	err := Conn(ctx, r.db).Where("status = ? AND ends_at <= ?", models.PriceChangeActive, now).
+		Order("ends_at").Find(&changes).Error
+	return changes, err
+}
+
+// DueToApply lấy các lịch chưa áp dụng đã tới thời điểm bắt đầu
+func (r *PriceChangeRepository) DueToApply(ctx context.Context, now time.Time) ([]models.ScheduledPriceChange, error) {
+	var changes []models.ScheduledPriceChange
+	err := Conn(ctx, r.db).Where("status = ? AND starts_at <= ?", models.PriceChangeScheduled, now).
+		Order("starts_at").Find(&changes).Error
+	return changes, err
+}
+
+// Transition đổi trạng thái của lịch nếu lịch còn ở trạng thái from; trả về false nếu lịch đã bị đổi trạng
+// thái bởi request khác
+func (r *PriceChangeRepository) Transition(ctx context.Context, id uint, from string, updates map[string]interface{}) (bool, error) {
+	result := Conn(ctx, r.db).Model(&models.ScheduledPriceChange{}).Where("id = ? AND status = ?", id, from).Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
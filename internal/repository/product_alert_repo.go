@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductAlertRepository struct {
+	db *gorm.DB
+}
+
+func NewProductAlertRepository(db *gorm.DB) *ProductAlertRepository {
+	return &ProductAlertRepository{db: db}
+}
+
+// Upsert tạo alert; nếu user đã theo dõi sản phẩm với cùng loại thì đặt lại ngưỡng giá, hạn và bật lại alert đã báo
+func (r *ProductAlertRepository) Upsert(ctx context.Context, alert *models.ProductAlert) error {
+	return Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "product_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"target_price", "expires_at", "triggered_at", "updated_at"}),
+	}).Create(alert).Error
+}
+
+// Get lấy alert theo user, sản phẩm và loại
+func (r *ProductAlertRepository) Get(ctx context.Context, userID, productID uint, alertType string) (*models.ProductAlert, error) {
+	var alert models.ProductAlert
+	err := Conn(ctx, r.db).Where("user_id = ? AND product_id = ? AND type = ?", userID, productID, alertType).First(&alert).Error
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// CountActive đếm số alert chưa báo và chưa hết hạn của user
+func (r *ProductAlertRepository) CountActive(ctx context.Context, userID uint, now time.Time) (int64, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.ProductAlert{}).
+		Where("user_id = ? AND triggered_at IS NULL AND expires_at > ?", userID, now).Count(&count).Error
+	return count, err
+}
+
+// ListByUser lấy các alert hết hạn sau now của user kèm sản phẩm, mới nhất trước; now zero lấy mọi alert
+func (r *ProductAlertRepository) ListByUser(ctx context.Context, userID uint, now time.Time) ([]models.ProductAlert, error) {
+	var alerts []models.ProductAlert
+	err := Conn(ctx, r.db).Preload("Product").Where("user_id = ? AND expires_at > ?", userID, now).
+		Order("created_at DESC, id DESC").Find(&alerts).Error
+	return alerts, err
+}
+
+// Delete xóa alert của user, trả về false nếu không tìm thấy
+func (r *ProductAlertRepository) Delete(ctx context.Context, userID, id uint) (bool, error) {
+	result := Conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).Delete(&models.ProductAlert{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListDue lấy các alert chưa báo, chưa hết hạn của sản phẩm theo loại
+func (r *ProductAlertRepository) ListDue(ctx context.Context, productID uint, alertType string, now time.Time) ([]models.ProductAlert, error) {
+	var alerts []models.ProductAlert
+	err := Conn(ctx, r.db).Where("product_id = ? AND type = ? AND triggered_at IS NULL AND expires_at > ?", productID, alertType, now).
+		Order("id").Find(&alerts).Error
+	return alerts, err
+}
+
+// MarkTriggered ghi thời điểm báo cho alert chưa báo; trả về false nếu alert đã được báo (bởi sự kiện khác)
+// hoặc đã bị xóa
+func (r *ProductAlertRepository) MarkTriggered(ctx context.Context, id uint, at time.Time) (bool, error) {
+	result := Conn(ctx, r.db).Model(&models.ProductAlert{}).Where("id = ? AND triggered_at IS NULL", id).Update("triggered_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteExpired xóa các alert hết hạn trước thời điểm before
+func (r *ProductAlertRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := Conn(ctx, r.db).Where("expires_at <= ?", before).Delete(&models.ProductAlert{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteByUser xóa mọi alert của user
+func (r *ProductAlertRepository) DeleteByUser(ctx context.Context, userID uint) error {
+	return Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&models.ProductAlert{}).Error
+}
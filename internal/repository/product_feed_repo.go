@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductFeedRepository struct {
+	db *gorm.DB
+}
+
+func NewProductFeedRepository(db *gorm.DB) *ProductFeedRepository {
+	return &ProductFeedRepository{db: db}
+}
+
+// Get lấy feed đã dựng theo tên
+func (r *ProductFeedRepository) Get(ctx context.Context, name string) (*models.ProductFeed, error) {
+	var feed models.ProductFeed
+	if err := Conn(ctx, r.db).Where("name = ?", name).First(&feed).Error; err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// Save lưu feed, thay bản đã dựng trước đó cùng tên
+func (r *ProductFeedRepository) Save(ctx context.Context, feed *models.ProductFeed) error {
+	return Conn(ctx, r.db).Clauses(clause.OnConflict{UpdateAll: true}).Create(feed).Error
+}
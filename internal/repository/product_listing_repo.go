@@ -0,0 +1,484 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/database"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductListingRepository struct {
+	db *gorm.DB
+}
+
+func NewProductListingRepository(db *gorm.DB) *ProductListingRepository {
+	return &ProductListingRepository{db: db}
+}
+
+// productListingColumns là các cột được ghi đè khi dựng lại một dòng read model đã có
+var productListingColumns = []string{
+	"name", "description", "price", "effective_price", "stock", "image_url", "category", "category_path", "specs", "status",
+	"rating_avg", "rating_count", "created_at", "updated_at", "refreshed_at",
+}
+
+// productListingProjection dựng dòng read model từ bảng products (effective_price là giá flash sale đang
+// áp dụng nếu có, rating từ các đánh giá đã duyệt trong product_reviews); where lọc sản phẩm cần dựng lại.
+// Upsert dùng ON CONFLICT trên Postgres/SQLite và ON DUPLICATE KEY UPDATE trên MySQL.
+func productListingProjection(db *gorm.DB, where string) string {
+	updates := make([]string, len(productListingColumns))
+	for i, column := range productListingColumns {
+		if db.Dialector.Name() == database.DriverMySQL {
+			updates[i] = fmt.Sprintf("%s = VALUES(%s)", column, column)
+		} else {
+			updates[i] = fmt.Sprintf("%s = EXCLUDED.%s", column, column)
+		}
+	}
+	conflict := "ON CONFLICT (product_id) DO UPDATE SET"
+	if db.Dialector.Name() == database.DriverMySQL {
+		conflict = "ON DUPLICATE KEY UPDATE"
+	}
+	// SQLite cần WHERE trong INSERT ... SELECT ... ON CONFLICT để phân biệt với mệnh đề JOIN
+	return `
+INSERT INTO product_listings (
+	product_id, ` + strings.Join(productListingColumns, ", ") + `
+)
+SELECT
+	p.id, p.name, p.description, p.price, COALESCE(p.sale_price, p.price), p.stock, p.image_url,
+	p.category, p.category, p.specs, p.status,
+	COALESCE((SELECT AVG(r.rating) FROM product_reviews r WHERE r.product_id = p.id AND r.status = 'approved'), 0),
+	(SELECT COUNT(*) FROM product_reviews r WHERE r.product_id = p.id AND r.status = 'approved'),
+	p.created_at, p.updated_at, CURRENT_TIMESTAMP
+FROM products p
+WHERE ` + where + `
+` + conflict + `
+	` + strings.Join(updates, ",\n\t")
+}
+
+// Refresh dựng lại read model cho các sản phẩm theo ID
+func (r *ProductListingRepository) Refresh(ctx context.Context, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := Conn(ctx, r.db).Exec(productListingProjection(r.db, "p.id IN ?"), ids).Error; err != nil {
+		return err
+	}
+	return r.RefreshCategoryPaths(ctx, ids...)
+}
+
+// RefreshAll dựng lại toàn bộ read model và xóa các dòng không còn sản phẩm tương ứng
+func (r *ProductListingRepository) RefreshAll(ctx context.Context) error {
+	if err := Conn(ctx, r.db).Exec(productListingProjection(r.db, "1 = 1")).Error; err != nil {
+		return err
+	}
+	if err := r.RefreshCategoryPaths(ctx); err != nil {
+		return err
+	}
+	return r.DeleteOrphans(ctx)
+}
+
+// RefreshCategoryPaths ghi lại category_path (tên các danh mục từ gốc tới danh mục của sản phẩm, nối bằng
+// CategoryPathSeparator) cho các sản phẩm theo ID, hoặc mọi sản phẩm nếu không truyền ID. Danh mục chưa
+// khai báo hoặc là danh mục gốc có đường dẫn là chính tên của nó.
+func (r *ProductListingRepository) RefreshCategoryPaths(ctx context.Context, ids ...uint) error {
+	categories, err := NewCategoryRepository(r.db).List(ctx)
+	if err != nil {
+		return err
+	}
+	paths := CategoryPaths(categories)
+
+	expr, args := "category", []interface{}{}
+	if len(paths) > 0 {
+		var cases strings.Builder
+		cases.WriteString("CASE category")
+		for _, name := range slices.Sorted(maps.Keys(paths)) {
+			cases.WriteString(" WHEN ? THEN ?")
+			args = append(args, name, paths[name])
+		}
+		cases.WriteString(" ELSE category END")
+		expr = cases.String()
+	}
+	query := Conn(ctx, r.db).Model(&models.ProductListing{})
+	if len(ids) > 0 {
+		query = query.Where("product_id IN ?", ids)
+	} else {
+		query = query.Where("1 = 1")
+	}
+	return query.UpdateColumn("category_path", gorm.Expr(expr, args...)).Error
+}
+
+// CategoryPathSeparator nối tên các danh mục trong category_path
+const CategoryPathSeparator = " > "
+
+// CategoryPaths trả về đường dẫn từ gốc của mọi danh mục có danh mục cha, theo tên danh mục
+func CategoryPaths(categories []models.Category) map[string]string {
+	byID := make(map[uint]models.Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+	paths := make(map[string]string)
+	for _, c := range categories {
+		if c.ParentID == nil {
+			continue
+		}
+		names := []string{c.Name}
+		// seen chặn vòng lặp nếu dữ liệu cha-con bị sửa tay thành chu trình
+		seen := map[uint]bool{c.ID: true}
+		for id := c.ParentID; id != nil && !seen[*id]; {
+			parent, ok := byID[*id]
+			if !ok {
+				break
+			}
+			seen[parent.ID] = true
+			names = append(names, parent.Name)
+			id = parent.ParentID
+		}
+		slices.Reverse(names)
+		paths[c.Name] = strings.Join(names, CategoryPathSeparator)
+	}
+	return paths
+}
+
+// DeleteOrphans xóa các dòng read model của sản phẩm đã bị xóa
+func (r *ProductListingRepository) DeleteOrphans(ctx context.Context) error {
+	return Conn(ctx, r.db).Exec("DELETE FROM product_listings WHERE NOT EXISTS (SELECT 1 FROM products p WHERE p.id = product_listings.product_id)").Error
+}
+
+// Remove xóa các dòng read model của sản phẩm theo ID
+func (r *ProductListingRepository) Remove(ctx context.Context, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return Conn(ctx, r.db).Where("product_id IN ?", ids).Delete(&models.ProductListing{}).Error
+}
+
+// GetByIDs lấy các dòng read model theo ID sản phẩm
+func (r *ProductListingRepository) GetByIDs(ctx context.Context, ids []uint) ([]models.ProductListing, error) {
+	var listings []models.ProductListing
+	if len(ids) == 0 {
+		return listings, nil
+	}
+	err := database.ReadReplica(Conn(ctx, r.db)).Where("product_id IN ?", ids).Find(&listings).Error
+	return listings, err
+}
+
+// GetAll lấy danh sách sản phẩm từ read model với các tùy chọn lọc giống ProductRepository.GetAll
+func (r *ProductListingRepository) GetAll(ctx context.Context, query *models.ProductQueryParams) ([]models.ProductListing, Total, error) {
+	dbQuery := database.ReadReplica(Conn(ctx, r.db)).Model(&models.ProductListing{})
+
+	if query.Search != "" {
+		condition, args := searchCondition(r.db, query)
+		dbQuery = dbQuery.Where(condition, args...)
+	}
+	if query.Category != "" {
+		dbQuery = dbQuery.Where("category = ?", query.Category)
+	}
+	if query.MinPrice > 0 {
+		dbQuery = dbQuery.Where("effective_price >= ?", query.MinPrice)
+	}
+	if query.MaxPrice > 0 {
+		dbQuery = dbQuery.Where("effective_price <= ?", query.MaxPrice)
+	}
+	if query.InStock {
+		dbQuery = dbQuery.Where("stock > 0")
+	}
+	if !query.StartDate.IsZero() {
+		dbQuery = dbQuery.Where("created_at >= ?", query.StartDate)
+	}
+	if !query.EndDate.IsZero() {
+		dbQuery = dbQuery.Where("created_at <= ?", query.EndDate)
+	}
+	if query.Status != "" {
+		dbQuery = dbQuery.Where("status = ?", query.Status)
+	}
+	if len(query.Attrs) > 0 {
+		condition, args := database.JSONContains(r.db, "specs", query.Attrs)
+		dbQuery = dbQuery.Where(condition, args...)
+	}
+
+	filtered := dbQuery.Session(&gorm.Session{})
+	dbQuery = filtered
+
+	if query.SortBy != "" {
+		validSortFields := map[string]string{
+			"name": "name", "price": "effective_price", "stock": "stock",
+			"created_at": "created_at", "category": "category", "rating": "rating_avg",
+		}
+		if sortField, ok := validSortFields[query.SortBy]; ok {
+			order := "ASC"
+			if query.Order == "desc" {
+				order = "DESC"
+			}
+			dbQuery = dbQuery.Order(sortField + " " + order)
+		}
+	} else if query.Search != "" && fuzzySearch(r.db, query) {
+		dbQuery = dbQuery.Clauses(similarityOrder(query)).Order("product_id DESC")
+	} else {
+		dbQuery = dbQuery.Order("created_at DESC").Order("product_id DESC")
+	}
+
+	offset := (query.Page - 1) * query.Limit
+	return findPage[models.ProductListing](filtered, dbQuery, "product_listings", query.Count, !query.Filtered(), offset, query.Limit)
+}
+
+// errUnresolvedListingUpdate được trả về khi một thao tác ghi lên products không xác định được sản phẩm
+// bị ảnh hưởng; mọi thao tác ghi phải theo khóa chính (giá trị model hoặc điều kiện id) để read model
+// chỉ phải dựng lại đúng các dòng đó
+var errUnresolvedListingUpdate = errors.New("refresh product listing: write on products must be keyed by id")
+
+// RegisterProductListingProjector đăng ký callback GORM để cập nhật read model
+// ngay sau mỗi thao tác ghi lên bảng products, trong cùng transaction với thao tác đó.
+// Thay đổi của các bảng khác (đánh giá, cây danh mục) được cập nhật bởi service tương ứng.
+func RegisterProductListingProjector(db *gorm.DB) error {
+	project := func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.Schema == nil || tx.Statement.Schema.Table != "products" {
+			return
+		}
+		ids, ok := affectedProductIDs(tx)
+		if !ok {
+			tx.AddError(errUnresolvedListingUpdate)
+			return
+		}
+		repo := NewProductListingRepository(listingConn(tx))
+		if err := repo.Refresh(tx.Statement.Context, ids...); err != nil {
+			tx.AddError(fmt.Errorf("refresh product listing: %w", err))
+		}
+	}
+
+	remove := func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.Schema == nil || tx.Statement.Schema.Table != "products" {
+			return
+		}
+		repo := NewProductListingRepository(listingConn(tx))
+		var err error
+		if ids, ok := affectedProductIDs(tx); ok {
+			err = repo.Remove(tx.Statement.Context, ids...)
+		} else {
+			// Xóa theo điều kiện: dọn mọi dòng không còn sản phẩm
+			err = repo.DeleteOrphans(tx.Statement.Context)
+		}
+		if err != nil {
+			tx.AddError(fmt.Errorf("refresh product listing: %w", err))
+		}
+	}
+
+	if err := db.Callback().Create().After("gorm:create").Register("listing:project_create", project); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("listing:project_update", project); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("listing:project_delete", remove)
+}
+
+// listingConn trả về kết nối của tx với statement mới; Initialized tạo statement ngay để các lần
+// WithContext sau đó không sao chép SQL và điều kiện của thao tác đang chạy
+func listingConn(tx *gorm.DB) *gorm.DB {
+	return tx.Session(&gorm.Session{NewDB: true, Initialized: true})
+}
+
+// affectedProductIDs lấy ID sản phẩm từ giá trị model của statement, hoặc từ các điều kiện theo khóa chính
+// trong mệnh đề WHERE (Where("id = ?", id), Where("id IN ?", ids), Delete(&Product{}, id), ...).
+// Trả về false nếu không xác định được, ví dụ khi chỉ lọc theo cột khác hoặc điều kiện có OR.
+func affectedProductIDs(tx *gorm.DB) ([]uint, bool) {
+	field := tx.Statement.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return nil, false
+	}
+
+	var ids []uint
+	collect := func(v reflect.Value) {
+		if value, zero := field.ValueOf(tx.Statement.Context, v); !zero {
+			if id, ok := value.(uint); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	rv := tx.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			collect(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		collect(rv)
+	}
+	if len(ids) > 0 {
+		return ids, true
+	}
+	return whereProductIDs(tx.Statement, field.DBName)
+}
+
+// whereProductIDs lấy ID từ điều kiện theo khóa chính column trong mệnh đề WHERE. Các điều kiện được nối
+// bằng AND nên chỉ cần một điều kiện theo khóa chính là đủ giới hạn tập dòng bị ảnh hưởng.
+func whereProductIDs(stmt *gorm.Statement, column string) ([]uint, bool) {
+	c, ok := stmt.Clauses["WHERE"]
+	if !ok {
+		return nil, false
+	}
+	where, ok := c.Expression.(clause.Where)
+	if !ok {
+		return nil, false
+	}
+	for _, expr := range where.Exprs {
+		if _, ok := expr.(clause.OrConditions); ok {
+			return nil, false
+		}
+	}
+
+	isKey := func(col interface{}) bool {
+		switch col := col.(type) {
+		case clause.Column:
+			return col.Name == clause.PrimaryKey || (col.Name == column && (col.Table == "" || col.Table == clause.CurrentTable || col.Table == stmt.Table))
+		case string:
+			return col == column || col == stmt.Table+"."+column
+		}
+		return false
+	}
+	for _, expr := range where.Exprs {
+		var values []interface{}
+		switch e := expr.(type) {
+		case clause.Eq:
+			if !isKey(e.Column) {
+				continue
+			}
+			values = []interface{}{e.Value}
+		case clause.IN:
+			if !isKey(e.Column) {
+				continue
+			}
+			values = e.Values
+		case clause.Expr:
+			if len(e.Vars) != 1 || !keyCondition(e.SQL, stmt.Table, column) {
+				continue
+			}
+			values = e.Vars
+		default:
+			continue
+		}
+		if ids, ok := uintValues(values); ok {
+			return ids, true
+		}
+	}
+	return nil, false
+}
+
+// keyCondition cho biết sql có dạng "id = ?" hoặc "id IN ?" (có thể kèm tên bảng và dấu quote)
+func keyCondition(sql, table, column string) bool {
+	sql = strings.ToLower(strings.Join(strings.Fields(strings.NewReplacer("`", "", `"`, "").Replace(sql)), " "))
+	sql = strings.TrimPrefix(sql, table+".")
+	switch strings.TrimPrefix(sql, column+" ") {
+	case "= ?", "in ?", "in (?)":
+		return strings.HasPrefix(sql, column+" ")
+	}
+	return false
+}
+
+// uintValues chuyển các giá trị số nguyên (hoặc slice số nguyên) thành ID; trả về false nếu có giá trị khác
+func uintValues(values []interface{}) ([]uint, bool) {
+	var ids []uint
+	for _, value := range values {
+		rv := reflect.Indirect(reflect.ValueOf(value))
+		items := []reflect.Value{rv}
+		if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			items = items[:0]
+			for i := 0; i < rv.Len(); i++ {
+				items = append(items, reflect.Indirect(rv.Index(i)))
+			}
+		}
+		for _, item := range items {
+			switch {
+			case item.CanUint():
+				ids = append(ids, uint(item.Uint()))
+			case item.CanInt() && item.Int() >= 0:
+				ids = append(ids, uint(item.Int()))
+			default:
+				return nil, false
+			}
+		}
+	}
+	return ids, true
+}
+
+// EnsureProductListings tạo dữ liệu ban đầu cho read model nếu bảng còn trống
+func EnsureProductListings(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&models.ProductListing{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	start := time.Now()
+	if err := NewProductListingRepository(db).RefreshAll(context.Background()); err != nil {
+		return err
+	}
+	log.Printf("Backfilled product_listings in %s", time.Since(start))
+	return nil
+}
+
+// Suggest lấy gợi ý tìm kiếm từ các sản phẩm đã đăng: tối đa limit tên sản phẩm, danh mục và thương hiệu
+// (thông số brand) khớp với q. Mỗi nhóm ưu tiên giá trị khớp tiền tố, sau đó mới tới giá trị chứa q.
+func (r *ProductListingRepository) Suggest(ctx context.Context, q string, limit int) (*models.SearchSuggestions, error) {
+	// Session để mỗi truy vấn bên dưới bắt đầu từ điều kiện trống
+	db := database.ReadReplica(Conn(ctx, r.db)).Session(&gorm.Session{})
+	published := func() *gorm.DB {
+		return db.Model(&models.ProductListing{}).Where("status = ?", models.ProductStatusPublished)
+	}
+	prefix, contains := strings.ToLower(q)+"%", "%"+q+"%"
+
+	suggestions := &models.SearchSuggestions{Products: []models.ProductSuggestion{}}
+	err := published().Select("product_id AS id, name").Where("LOWER(name) LIKE ?", prefix).
+		Order("rating_count DESC, name").Limit(limit).Find(&suggestions.Products).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(suggestions.Products) < limit {
+		var more []models.ProductSuggestion
+		err := published().Select("product_id AS id, name").
+			Where(database.ILike(r.db, "name"), contains).Where("LOWER(name) NOT LIKE ?", prefix).
+			Order("rating_count DESC, name").Limit(limit - len(suggestions.Products)).Find(&more).Error
+		if err != nil {
+			return nil, err
+		}
+		suggestions.Products = append(suggestions.Products, more...)
+	}
+
+	if suggestions.Categories, err = suggestValues(published, r.db, "category", prefix, contains, limit); err != nil {
+		return nil, err
+	}
+	brand := database.JSONField(r.db, "specs", models.BrandAttributeKey)
+	if suggestions.Brands, err = suggestValues(published, r.db, brand, prefix, contains, limit); err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// suggestValues lấy tối đa limit giá trị khác nhau của biểu thức expr khớp tiền tố, bổ sung bằng các giá trị
+// chứa chuỗi cần tìm nếu chưa đủ
+func suggestValues(scope func() *gorm.DB, db *gorm.DB, expr, prefix, contains string, limit int) ([]string, error) {
+	values := []string{}
+	if err := scope().Where("LOWER("+expr+") LIKE ?", prefix).
+		Distinct(expr).Order(expr).Limit(limit).Pluck(expr, &values).Error; err != nil {
+		return nil, err
+	}
+	if len(values) < limit {
+		var more []string
+		if err := scope().Where(database.ILike(db, expr), contains).Where("LOWER("+expr+") NOT LIKE ?", prefix).
+			Distinct(expr).Order(expr).Limit(limit-len(values)).Pluck(expr, &more).Error; err != nil {
+			return nil, err
+		}
+		values = append(values, more...)
+	}
+	return values, nil
+}
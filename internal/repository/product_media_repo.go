@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type ProductMediaRepository struct {
+	db *gorm.DB
+}
+
+func NewProductMediaRepository(db *gorm.DB) *ProductMediaRepository {
+	return &ProductMediaRepository{db: db}
+}
+
+// Create lưu media mới
+func (r *ProductMediaRepository) Create(ctx context.Context, media *models.ProductMedia) error {
+	return Conn(ctx, r.db).Create(media).Error
+}
+
+// GetByID lấy media theo ID
+func (r *ProductMediaRepository) GetByID(ctx context.Context, id uint) (*models.ProductMedia, error) {
+	var media models.ProductMedia
+	if err := Conn(ctx, r.db).First(&media, id).Error; err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+// Get lấy media của sản phẩm
+func (r *ProductMediaRepository) Get(ctx context.Context, productID, id uint) (*models.ProductMedia, error) {
+	var media models.ProductMedia
+	if err := Conn(ctx, r.db).Where("product_id = ?", productID).First(&media, id).Error; err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+// ListByProduct lấy media của sản phẩm theo thứ tự thêm; readyOnly chỉ lấy media đã xử lý xong
+func (r *ProductMediaRepository) ListByProduct(ctx context.Context, productID uint, readyOnly bool) ([]models.ProductMedia, error) {
+	var media []models.ProductMedia
+	query := Conn(ctx, r.db).Where("product_id = ?", productID)
+	if readyOnly {
+		query = query.Where("status = ?", models.MediaStatusReady)
+	}
+	err := query.Order("id").Find(&media).Error
+	return media, err
+}
+
+// ListReadyByProducts lấy media đã xử lý xong của nhiều sản phẩm bằng một truy vấn
+func (r *ProductMediaRepository) ListReadyByProducts(ctx context.Context, productIDs []uint) ([]models.ProductMedia, error) {
+	var media []models.ProductMedia
+	err := Conn(ctx, r.db).Where("product_id IN ? AND status = ?", productIDs, models.MediaStatusReady).
+		Order("product_id, id").Find(&media).Error
+	return media, err
+}
+
+// CountByProduct đếm media của sản phẩm, kể cả media đang xử lý hoặc lỗi
+func (r *ProductMediaRepository) CountByProduct(ctx context.Context, productID uint) (int64, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.ProductMedia{}).Where("product_id = ?", productID).Count(&count).Error
+	return count, err
+}
+
+// ListUploadURLs lấy đường dẫn file video và poster của các video upload lên server
+func (r *ProductMediaRepository) ListUploadURLs(ctx context.Context) ([]string, error) {
+	var media []models.ProductMedia
+	if err := Conn(ctx, r.db).Select("url", "poster_url").Where("source = ?", models.MediaSourceUpload).
+		Find(&media).Error; err != nil {
+		return nil, err
+	}
+	urls := make([]string, 0, 2*len(media))
+	for _, m := range media {
+		urls = append(urls, m.URL)
+		if m.PosterURL != "" {
+			urls = append(urls, m.PosterURL)
+		}
+	}
+	return urls, nil
+}
+
+// SaveResult lưu kết quả xử lý của media; media đã bị xóa trong lúc xử lý được bỏ qua
+func (r *ProductMediaRepository) SaveResult(ctx context.Context, media *models.ProductMedia) error {
+	return Conn(ctx, r.db).Model(media).
+		Select("url", "embed_url", "poster_url", "title", "duration_seconds", "width", "height", "status", "error").
+		Updates(media).Error
+}
+
+// Delete xóa media
+func (r *ProductMediaRepository) Delete(ctx context.Context, id uint) error {
+	return Conn(ctx, r.db).Delete(&models.ProductMedia{}, id).Error
+}
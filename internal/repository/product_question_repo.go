@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type ProductQuestionRepository struct {
+	db *gorm.DB
+}
+
+func NewProductQuestionRepository(db *gorm.DB) *ProductQuestionRepository {
+	return &ProductQuestionRepository{db: db}
+}
+
+// CreateQuestion lưu câu hỏi mới
+func (r *ProductQuestionRepository) CreateQuestion(ctx context.Context, question *models.ProductQuestion) error {
+	return Conn(ctx, r.db).Create(question).Error
+}
+
+// GetQuestion lấy câu hỏi của sản phẩm kèm mọi câu trả lời
+func (r *ProductQuestionRepository) GetQuestion(ctx context.Context, productID, id uint) (*models.ProductQuestion, error) {
+	var question models.ProductQuestion
+	err := Conn(ctx, r.db).Preload("Answers", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Where("product_id = ?", productID).First(&question, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+// ListApproved lấy các câu hỏi đã duyệt của sản phẩm kèm câu trả lời đã duyệt, mới nhất trước
+func (r *ProductQuestionRepository) ListApproved(ctx context.Context, productID uint, page, limit int) ([]models.ProductQuestion, int64, error) {
+	var questions []models.ProductQuestion
+	var total int64
+
+	dbQuery := Conn(ctx, r.db).Model(&models.ProductQuestion{}).
+		Where("product_id = ? AND status = ?", productID, models.ModerationApproved)
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := dbQuery.Preload("Answers", func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ?", models.ModerationApproved).Order("created_at")
+	}).Order("created_at DESC").Offset((page - 1) * limit).Limit(limit).Find(&questions).Error
+	return questions, total, err
+}
+
+// ListQuestions lấy câu hỏi theo bộ lọc kiểm duyệt kèm mọi câu trả lời, cũ nhất trước (Admin)
+func (r *ProductQuestionRepository) ListQuestions(ctx context.Context, query *models.ModerationQueryParams) ([]models.ProductQuestion, int64, error) {
+	var questions []models.ProductQuestion
+	var total int64
+
+	dbQuery := Conn(ctx, r.db).Model(&models.ProductQuestion{})
+	if query.Status != "" {
+		dbQuery = dbQuery.Where("status = ?", query.Status)
+	}
+	if query.ProductID != 0 {
+		dbQuery = dbQuery.Where("product_id = ?", query.ProductID)
+	}
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := dbQuery.Preload("Answers", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Order("created_at").Offset((query.Page - 1) * query.Limit).Limit(query.Limit).Find(&questions).Error
+	return questions, total, err
+}
+
+// ListAnswers lấy câu trả lời theo bộ lọc kiểm duyệt, cũ nhất trước (Admin)
+func (r *ProductQuestionRepository) ListAnswers(ctx context.Context, query *models.ModerationQueryParams) ([]models.ProductAnswer, int64, error) {
+	var answers []models.ProductAnswer
+	var total int64
+
+	dbQuery := Conn(ctx, r.db).Model(&models.ProductAnswer{})
+	if query.Status != "" {
+		dbQuery = dbQuery.Where("product_answers.status = ?", query.Status)
+	}
+	if query.ProductID != 0 {
+		dbQuery = dbQuery.Joins("JOIN product_questions ON product_questions.id = product_answers.question_id").
+			Where("product_questions.product_id = ?", query.ProductID)
+	}
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := dbQuery.Order("product_answers.created_at").Offset((query.Page - 1) * query.Limit).Limit(query.Limit).Find(&answers).Error
+	return answers, total, err
+}
+
+// CreateAnswer lưu câu trả lời mới
+func (r *ProductQuestionRepository) CreateAnswer(ctx context.Context, answer *models.ProductAnswer) error {
+	return Conn(ctx, r.db).Create(answer).Error
+}
+
+// GetAnswer lấy câu trả lời của câu hỏi
+func (r *ProductQuestionRepository) GetAnswer(ctx context.Context, questionID, id uint) (*models.ProductAnswer, error) {
+	var answer models.ProductAnswer
+	if err := Conn(ctx, r.db).Where("question_id = ?", questionID).First(&answer, id).Error; err != nil {
+		return nil, err
+	}
+	return &answer, nil
+}
+
+// ModerateQuestion đặt trạng thái kiểm duyệt của câu hỏi
+func (r *ProductQuestionRepository) ModerateQuestion(ctx context.Context, id uint, status string, adminID uint, now time.Time) error {
+	return Conn(ctx, r.db).Model(&models.ProductQuestion{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status, "moderated_by": adminID, "moderated_at": now}).Error
+}
+
+// ModerateAnswer đặt trạng thái kiểm duyệt của câu trả lời
+func (r *ProductQuestionRepository) ModerateAnswer(ctx context.Context, id uint, status string, adminID uint, now time.Time) error {
+	return Conn(ctx, r.db).Model(&models.ProductAnswer{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status, "moderated_by": adminID, "moderated_at": now}).Error
+}
+
+// ListQuestionsByUser lấy mọi câu hỏi user đã đặt
+func (r *ProductQuestionRepository) ListQuestionsByUser(ctx context.Context, userID uint) ([]models.ProductQuestion, error) {
+	var questions []models.ProductQuestion
+	err := Conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at").Find(&questions).Error
+	return questions, err
+}
+
+// ListAnswersByUser lấy mọi câu trả lời user đã viết
+func (r *ProductQuestionRepository) ListAnswersByUser(ctx context.Context, userID uint) ([]models.ProductAnswer, error) {
+	var answers []models.ProductAnswer
+	err := Conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at").Find(&answers).Error
+	return answers, err
+}
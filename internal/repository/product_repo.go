@@ -1,8 +1,15 @@
 package repository
 
 import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/database"
 	"github.com/NgTruong624/project_backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepository struct {
@@ -13,44 +20,123 @@ func NewProductRepository(db *gorm.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
+// trigramColumns là các cột được tìm kiếm bằng ILIKE '%...%' trong danh sách sản phẩm. Điều kiện OR
+// trên các cột chỉ dùng được index khi cột nào cũng có index trigram.
+var trigramColumns = map[string][]string{
+	"products":         {"name", "description", "category"},
+	"product_listings": {"name", "description", "category"},
+}
+
+// MigrateProductIndexes tạo các index mà AutoMigrate không khai báo được cho bộ lọc danh sách sản phẩm
+// và xóa các index đơn cột đã được thay bằng index ghép trong tag model.
+// Index trigram (pg_trgm) cho tìm kiếm và index GIN cho lọc thông số chỉ có trên Postgres; với database khác
+// LIKE '%...%' và lọc thông số vẫn quét bảng.
+func MigrateProductIndexes(db *gorm.DB) error {
+	for _, name := range []string{"idx_product_listings_created_at", "idx_product_listings_category"} {
+		if db.Migrator().HasIndex(&models.ProductListing{}, name) {
+			if err := db.Migrator().DropIndex(&models.ProductListing{}, name); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !database.IsPostgres(db) {
+		return nil
+	}
+	// Index GIN cho lọc thông số kỹ thuật bằng specs @> '{...}'
+	for _, table := range []string{"products", "product_listings"} {
+		if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_" + table + "_specs ON " + table + " USING gin (specs jsonb_path_ops)").Error; err != nil {
+			return err
+		}
+	}
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		// Extension cần quyền tạo trên database; thiếu index trigram chỉ làm tìm kiếm chậm hơn
+		log.Printf("Warning: pg_trgm is not available, product search will not use indexes: %v", err)
+		return nil
+	}
+	for table, columns := range trigramColumns {
+		for _, column := range columns {
+			if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_" + table + "_" + column + "_trgm ON " + table + " USING gin (" + column + " gin_trgm_ops)").Error; err != nil {
+				return err
+			}
+		}
+	}
+	return migrateSuggestIndexes(db)
+}
+
+// migrateSuggestIndexes tạo index cho gợi ý tìm kiếm trên product_listings: index text_pattern_ops trên
+// LOWER(...) cho khớp tiền tố và index trigram cho khớp chuỗi con của thương hiệu (tên và danh mục đã có
+// index trigram ở trên)
+func migrateSuggestIndexes(db *gorm.DB) error {
+	brand := database.JSONField(db, "specs", models.BrandAttributeKey)
+	statements := []string{
+		"CREATE INDEX IF NOT EXISTS idx_product_listings_name_prefix ON product_listings (LOWER(name) text_pattern_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_product_listings_category_prefix ON product_listings (LOWER(category) text_pattern_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_product_listings_brand_prefix ON product_listings (LOWER(" + brand + ") text_pattern_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_product_listings_brand_trgm ON product_listings USING gin (" + brand + " gin_trgm_ops)",
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Create tạo sản phẩm mới
-func (r *ProductRepository) Create(product *models.Product) error {
-	return r.db.Create(product).Error
+func (r *ProductRepository) Create(ctx context.Context, product *models.Product) error {
+	return Conn(ctx, r.db).Create(product).Error
 }
 
 // GetByID lấy sản phẩm theo ID
-func (r *ProductRepository) GetByID(id uint) (*models.Product, error) {
+func (r *ProductRepository) GetByID(ctx context.Context, id uint) (*models.Product, error) {
 	var product models.Product
-	err := r.db.First(&product, id).Error
+	err := Conn(ctx, r.db).First(&product, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
-// GetAll lấy danh sách sản phẩm với các tùy chọn
-func (r *ProductRepository) GetAll(query *models.ProductQueryParams) ([]models.Product, int64, error) {
+// GetForUpdate lấy sản phẩm theo ID và khóa dòng cho tới hết transaction
+func (r *ProductRepository) GetForUpdate(ctx context.Context, id uint) (*models.Product, error) {
+	var product models.Product
+	if err := Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, id).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// GetByIDs lấy các sản phẩm theo danh sách ID; ID không tồn tại bị bỏ qua
+func (r *ProductRepository) GetByIDs(ctx context.Context, ids []uint) ([]models.Product, error) {
 	var products []models.Product
-	var total int64
+	err := Conn(ctx, r.db).Where("id IN ?", ids).Find(&products).Error
+	return products, err
+}
+
+// GetBySKUsForUpdate lấy các sản phẩm theo SKU và khóa dòng cho tới hết transaction; SKU không tồn tại bị bỏ qua
+func (r *ProductRepository) GetBySKUsForUpdate(ctx context.Context, skus []string) ([]models.Product, error) {
+	var products []models.Product
+	err := Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).Where("sku IN ?", skus).Order("id").Find(&products).Error
+	return products, err
+}
 
-	dbQuery := r.db.Model(&models.Product{})
+// GetAll lấy danh sách sản phẩm với các tùy chọn
+func (r *ProductRepository) GetAll(ctx context.Context, query *models.ProductQueryParams) ([]models.Product, Total, error) {
+	dbQuery := database.ReadReplica(Conn(ctx, r.db)).Model(&models.Product{})
 
 	if query.Search != "" {
-		dbQuery = dbQuery.Where(
-			"name ILIKE ? OR description ILIKE ? OR category ILIKE ?",
-			"%"+query.Search+"%",
-			"%"+query.Search+"%",
-			"%"+query.Search+"%",
-		)
+		condition, args := searchCondition(r.db, query)
+		dbQuery = dbQuery.Where(condition, args...)
 	}
 	if query.Category != "" {
 		dbQuery = dbQuery.Where("category = ?", query.Category)
 	}
 	if query.MinPrice > 0 {
-		dbQuery = dbQuery.Where("price >= ?", query.MinPrice)
+		dbQuery = dbQuery.Where("COALESCE(sale_price, price) >= ?", query.MinPrice)
 	}
 	if query.MaxPrice > 0 {
-		dbQuery = dbQuery.Where("price <= ?", query.MaxPrice)
+		dbQuery = dbQuery.Where("COALESCE(sale_price, price) <= ?", query.MaxPrice)
 	}
 	if query.InStock {
 		dbQuery = dbQuery.Where("stock > 0")
@@ -61,14 +147,21 @@ func (r *ProductRepository) GetAll(query *models.ProductQueryParams) ([]models.P
 	if !query.EndDate.IsZero() {
 		dbQuery = dbQuery.Where("created_at <= ?", query.EndDate)
 	}
-
-	if err := dbQuery.Count(&total).Error; err != nil {
-		return nil, 0, err
+	if query.Status != "" {
+		dbQuery = dbQuery.Where("status = ?", query.Status)
+	}
+	if len(query.Attrs) > 0 {
+		condition, args := database.JSONContains(r.db, "specs", query.Attrs)
+		dbQuery = dbQuery.Where(condition, args...)
 	}
 
+	// Session tách điều kiện lọc để dùng lại cho truy vấn COUNT khi cần
+	filtered := dbQuery.Session(&gorm.Session{})
+	dbQuery = filtered
+
 	if query.SortBy != "" {
 		validSortFields := map[string]string{
-			"name": "name", "price": "price", "stock": "stock",
+			"name": "name", "price": "COALESCE(sale_price, price)", "stock": "stock",
 			"created_at": "created_at", "category": "category",
 		}
 		if sortField, ok := validSortFields[query.SortBy]; ok {
@@ -78,31 +171,55 @@ func (r *ProductRepository) GetAll(query *models.ProductQueryParams) ([]models.P
 			}
 			dbQuery = dbQuery.Order(sortField + " " + order)
 		}
+	} else if query.Search != "" && fuzzySearch(r.db, query) {
+		dbQuery = dbQuery.Clauses(similarityOrder(query)).Order("id DESC")
 	} else {
-		dbQuery = dbQuery.Order("created_at DESC")
+		// Khớp index (created_at, id); id giữ thứ tự ổn định giữa các trang khi trùng created_at
+		dbQuery = dbQuery.Order("created_at DESC").Order("id DESC")
 	}
 
+	// Lấy trang và tổng số dòng theo chế độ đếm query.Count
 	offset := (query.Page - 1) * query.Limit
-	if err := dbQuery.Offset(offset).Limit(query.Limit).Find(&products).Error; err != nil {
-		return nil, 0, err
+	return findPage[models.Product](filtered, dbQuery, "products", query.Count, !query.Filtered(), offset, query.Limit)
+}
+
+// Update ghi toàn bộ sản phẩm nếu version chưa đổi kể từ lúc đọc và tăng version lên 1.
+// Trả về số dòng được cập nhật; 0 nghĩa là sản phẩm đã bị sửa đồng thời (hoặc đã bị xóa).
+func (r *ProductRepository) Update(ctx context.Context, product *models.Product) (int64, error) {
+	version := product.Version
+	product.Version++
+	result := Conn(ctx, r.db).Model(product).Where("version = ?", version).
+		Select("*").Omit("id", "created_at").Updates(product)
+	if result.Error != nil || result.RowsAffected == 0 {
+		product.Version = version
 	}
-	return products, total, nil
+	return result.RowsAffected, result.Error
 }
 
-// Update cập nhật sản phẩm
-func (r *ProductRepository) Update(product *models.Product) error {
-	return r.db.Save(product).Error
+// GetDueForPublish lấy các sản phẩm draft đã tới thời điểm hẹn đăng
+func (r *ProductRepository) GetDueForPublish(ctx context.Context, now time.Time) ([]models.Product, error) {
+	var products []models.Product
+	err := Conn(ctx, r.db).Where("status = ? AND publish_at <= ?", models.ProductStatusDraft, now).
+		Order("publish_at").Find(&products).Error
+	return products, err
 }
 
 // Delete xóa sản phẩm
-func (r *ProductRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Product{}, id).Error
+func (r *ProductRepository) Delete(ctx context.Context, id uint) error {
+	return Conn(ctx, r.db).Delete(&models.Product{}, id).Error
+}
+
+// CheckIfSKUExists kiểm tra SKU đã được sản phẩm khác dùng (loại trừ sản phẩm có ID = excludeID)
+func (r *ProductRepository) CheckIfSKUExists(ctx context.Context, sku string, excludeID uint) (bool, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.Product{}).Where("sku = ? AND id <> ?", sku, excludeID).Count(&count).Error
+	return count > 0, err
 }
 
 // CheckIfNameExists kiểm tra tên sản phẩm đã tồn tại (loại trừ sản phẩm có ID = excludeID)
-func (r *ProductRepository) CheckIfNameExists(name string, excludeID uint) (bool, error) {
+func (r *ProductRepository) CheckIfNameExists(ctx context.Context, name string, excludeID uint) (bool, error) {
 	var count int64
-	query := r.db.Model(&models.Product{}).Where("name = ?", name)
+	query := Conn(ctx, r.db).Model(&models.Product{}).Where("name = ?", name)
 	if excludeID > 0 { // Nếu excludeID > 0 (tức là đang update), thì loại trừ ID này
 		query = query.Where("id <> ?", excludeID)
 	}
@@ -115,27 +232,159 @@ func (r *ProductRepository) CheckIfNameExists(name string, excludeID uint) (bool
 
 // --- Các hàm khác giữ nguyên ---
 // GetByCategory lấy sản phẩm theo danh mục
-func (r *ProductRepository) GetByCategory(category string) ([]models.Product, error) {
+func (r *ProductRepository) GetByCategory(ctx context.Context, category string) ([]models.Product, error) {
 	var products []models.Product
-	err := r.db.Where("category = ?", category).Find(&products).Error
+	err := Conn(ctx, r.db).Where("category = ?", category).Find(&products).Error
 	return products, err
 }
 
+// searchCondition trả về điều kiện tìm kiếm của query cùng tham số: tên, mô tả hoặc danh mục chứa Search
+// hay một cách viết đồng nghĩa trong SearchTerms; ở chế độ gần đúng là word_similarity của tên với Search
+// từ SimilarityThreshold trở lên
+func searchCondition(db *gorm.DB, query *models.ProductQueryParams) (string, []interface{}) {
+	if fuzzySearch(db, query) {
+		return "word_similarity(?, name) >= ?", []interface{}{query.Search, query.SimilarityThreshold}
+	}
+	terms := query.SearchTerms
+	if len(terms) == 0 {
+		terms = []string{query.Search}
+	}
+	like := database.ILike(db, "name", "description", "category")
+	conditions := make([]string, len(terms))
+	args := make([]interface{}, 0, 3*len(terms))
+	for i, term := range terms {
+		conditions[i] = "(" + like + ")"
+		args = append(args, "%"+term+"%", "%"+term+"%", "%"+term+"%")
+	}
+	return strings.Join(conditions, " OR "), args
+}
+
+// fuzzySearch cho biết query tìm gần đúng; word_similarity cần pg_trgm nên chỉ dùng được trên Postgres
+func fuzzySearch(db *gorm.DB, query *models.ProductQueryParams) bool {
+	return query.SimilarityThreshold > 0 && database.IsPostgres(db)
+}
+
+// similarityOrder sắp xếp kết quả tìm gần đúng theo độ tương đồng của tên, gần nhất trước
+func similarityOrder(query *models.ProductQueryParams) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.Expr{SQL: "word_similarity(?, name) DESC", Vars: []interface{}{query.Search}}}
+}
+
 // SearchByName tìm kiếm sản phẩm theo tên
-func (r *ProductRepository) SearchByName(name string) ([]models.Product, error) {
+func (r *ProductRepository) SearchByName(ctx context.Context, name string) ([]models.Product, error) {
 	var products []models.Product
-	err := r.db.Where("name ILIKE ?", "%"+name+"%").Find(&products).Error
+	err := database.ReadReplica(Conn(ctx, r.db)).Where(database.ILike(r.db, "name"), "%"+name+"%").Find(&products).Error
 	return products, err
 }
 
 // UpdateStock cập nhật số lượng tồn kho
-func (r *ProductRepository) UpdateStock(id uint, stock int) error {
-	return r.db.Model(&models.Product{}).Where("id = ?", id).Update("stock", stock).Error
+func (r *ProductRepository) UpdateStock(ctx context.Context, id uint, stock int) error {
+	return Conn(ctx, r.db).Model(&models.Product{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"stock": stock, "version": gorm.Expr("version + 1")}).Error
+}
+
+// UpdatePrice cập nhật giá gốc
+func (r *ProductRepository) UpdatePrice(ctx context.Context, id uint, price float64) error {
+	return Conn(ctx, r.db).Model(&models.Product{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"price": price, "version": gorm.Expr("version + 1")}).Error
+}
+
+// SetSalePrice đặt giá bán của lịch đổi giá đang áp dụng (nil là trở về giá gốc)
+func (r *ProductRepository) SetSalePrice(ctx context.Context, id uint, price *float64) error {
+	return Conn(ctx, r.db).Model(&models.Product{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"sale_price": price, "version": gorm.Expr("version + 1")}).Error
+}
+
+// ReserveStock trừ quantity khỏi tồn kho nếu còn đủ hàng; trả về false nếu không đủ hàng
+func (r *ProductRepository) ReserveStock(ctx context.Context, id uint, quantity int) (bool, error) {
+	result := Conn(ctx, r.db).Model(&models.Product{ID: id}).Where("stock >= ?", quantity).
+		Updates(map[string]interface{}{"stock": gorm.Expr("stock - ?", quantity), "version": gorm.Expr("version + 1")})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReleaseStock cộng lại quantity vào tồn kho (khi đơn hàng bị hủy)
+func (r *ProductRepository) ReleaseStock(ctx context.Context, id uint, quantity int) error {
+	return Conn(ctx, r.db).Model(&models.Product{ID: id}).
+		Updates(map[string]interface{}{"stock": gorm.Expr("stock + ?", quantity), "version": gorm.Expr("version + 1")}).Error
+}
+
+// FindSubstitute lấy sản phẩm đã đăng còn hàng cùng danh mục có giá bán gần price nhất, bỏ qua các
+// sản phẩm trong exclude; trả về gorm.ErrRecordNotFound nếu không có sản phẩm nào phù hợp
+func (r *ProductRepository) FindSubstitute(ctx context.Context, category string, price float64, exclude []uint) (*models.Product, error) {
+	var product models.Product
+	dbQuery := Conn(ctx, r.db).Where("category = ? AND status = ? AND stock > 0", category, models.ProductStatusPublished)
+	if len(exclude) > 0 {
+		dbQuery = dbQuery.Where("id NOT IN ?", exclude)
+	}
+	err := dbQuery.Clauses(clause.OrderBy{Expression: clause.Expr{SQL: "ABS(COALESCE(sale_price, price) - ?)", Vars: []interface{}{price}}}).
+		Order("id").First(&product).Error
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// ListImageURLs lấy image_url của mọi sản phẩm có ảnh, kể cả sản phẩm draft và archived
+func (r *ProductRepository) ListImageURLs(ctx context.Context) ([]string, error) {
+	var urls []string
+	err := Conn(ctx, r.db).Model(&models.Product{}).Where("image_url <> ''").Pluck("image_url", &urls).Error
+	return urls, err
 }
 
 // GetLowStock lấy danh sách sản phẩm có số lượng tồn kho thấp
-func (r *ProductRepository) GetLowStock(threshold int) ([]models.Product, error) {
+func (r *ProductRepository) GetLowStock(ctx context.Context, threshold int) ([]models.Product, error) {
+	var products []models.Product
+	err := Conn(ctx, r.db).Where("stock <= ?", threshold).Find(&products).Error
+	return products, err
+}
+
+// CategoryCount chứa tên danh mục và số sản phẩm thuộc danh mục đó
+type CategoryCount struct {
+	Category string `json:"category"`
+	Count    int64  `json:"count"`
+}
+
+// GetCategoryCounts lấy danh sách danh mục kèm số lượng sản phẩm đã đăng
+func (r *ProductRepository) GetCategoryCounts(ctx context.Context) ([]CategoryCount, error) {
+	var counts []CategoryCount
+	err := Conn(ctx, r.db).Model(&models.Product{}).
+		Select("category, COUNT(*) AS count").
+		Where("category <> '' AND status = ?", models.ProductStatusPublished).
+		Group("category").
+		Order("category ASC").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// ListPublishedAfter lấy tối đa limit sản phẩm đã đăng có ID lớn hơn afterID theo thứ tự ID, chỉ gồm
+// các cột id, name, category và updated_at (dùng để dựng sitemap)
+func (r *ProductRepository) ListPublishedAfter(ctx context.Context, afterID uint, limit int) ([]models.Product, error) {
+	var products []models.Product
+	err := Conn(ctx, r.db).Select("id, name, category, updated_at").
+		Where("id > ? AND status = ?", afterID, models.ProductStatusPublished).
+		Order("id").Limit(limit).Find(&products).Error
+	return products, err
+}
+
+// ListPublishedPageAfter giống ListPublishedAfter nhưng lấy đầy đủ các cột (dùng để dựng feed sản phẩm)
+func (r *ProductRepository) ListPublishedPageAfter(ctx context.Context, afterID uint, limit int) ([]models.Product, error) {
 	var products []models.Product
-	err := r.db.Where("stock <= ?", threshold).Find(&products).Error
+	err := Conn(ctx, r.db).Where("id > ? AND status = ?", afterID, models.ProductStatusPublished).
+		Order("id").Limit(limit).Find(&products).Error
+	return products, err
+}
+
+// GetByCategories lấy tối đa limit sản phẩm đã đăng mới nhất của mỗi danh mục trong categories bằng một
+// truy vấn (ROW_NUMBER theo từng danh mục), mới nhất trước
+func (r *ProductRepository) GetByCategories(ctx context.Context, categories []string, limit int) ([]models.Product, error) {
+	var products []models.Product
+	if len(categories) == 0 {
+		return products, nil
+	}
+	db := Conn(ctx, r.db)
+	ranked := db.Model(&models.Product{}).
+		Select("products.*, ROW_NUMBER() OVER (PARTITION BY category ORDER BY created_at DESC, id DESC) AS category_rank").
+		Where("category IN ? AND status = ?", categories, models.ProductStatusPublished)
+	err := db.Table("(?) AS ranked", ranked).Where("category_rank <= ?", limit).
+		Order("created_at DESC, id DESC").Find(&products).Error
 	return products, err
 }
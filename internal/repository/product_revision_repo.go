@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductRevisionRepository struct {
+	db *gorm.DB
+}
+
+func NewProductRevisionRepository(db *gorm.DB) *ProductRevisionRepository {
+	return &ProductRevisionRepository{db: db}
+}
+
+// Create lưu revision mới
+func (r *ProductRevisionRepository) Create(ctx context.Context, revision *models.ProductRevision) error {
+	return Conn(ctx, r.db).Create(revision).Error
+}
+
+// GetByID lấy revision kèm sản phẩm hiện tại
+func (r *ProductRevisionRepository) GetByID(ctx context.Context, id uint) (*models.ProductRevision, error) {
+	var revision models.ProductRevision
+	if err := Conn(ctx, r.db).Preload("Product").First(&revision, id).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// GetForUpdate lấy revision và khóa dòng cho tới hết transaction
+func (r *ProductRevisionRepository) GetForUpdate(ctx context.Context, id uint) (*models.ProductRevision, error) {
+	var revision models.ProductRevision
+	if err := Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&revision, id).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// GetAll lấy danh sách revision kèm sản phẩm với bộ lọc và phân trang, mới nhất trước
+func (r *ProductRevisionRepository) GetAll(ctx context.Context, query *models.ProductRevisionQueryParams) ([]models.ProductRevision, int64, error) {
+	var revisions []models.ProductRevision
+	var total int64
+
+	dbQuery := Conn(ctx, r.db).Model(&models.ProductRevision{})
+	if query.Status != "" {
+		dbQuery = dbQuery.Where("status = ?", query.Status)
+	}
+	if query.ProductID != 0 {
+		dbQuery = dbQuery.Where("product_id = ?", query.ProductID)
+	}
+	if query.SubmittedBy != 0 {
+		dbQuery = dbQuery.Where("submitted_by = ?", query.SubmittedBy)
+	}
+
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.Limit
+	err := dbQuery.Preload("Product").Order("created_at DESC, id DESC").Offset(offset).Limit(query.Limit).Find(&revisions).Error
+	return revisions, total, err
+}
+
+// UpdateStatus chuyển revision từ trạng thái from sang to cùng các cột khác trong fields.
+// Trả về số dòng được cập nhật; 0 nghĩa là revision không còn ở trạng thái from.
+func (r *ProductRevisionRepository) UpdateStatus(ctx context.Context, id uint, from, to string, fields map[string]interface{}) (int64, error) {
+	updates := map[string]interface{}{"status": to}
+	for k, v := range fields {
+		updates[k] = v
+	}
+	result := Conn(ctx, r.db).Model(&models.ProductRevision{}).Where("id = ? AND status = ?", id, from).Updates(updates)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type ProductSnapshotRepository struct {
+	db *gorm.DB
+}
+
+func NewProductSnapshotRepository(db *gorm.DB) *ProductSnapshotRepository {
+	return &ProductSnapshotRepository{db: db}
+}
+
+// Create lưu bản lưu lịch sử sản phẩm
+func (r *ProductSnapshotRepository) Create(ctx context.Context, snapshot *models.ProductSnapshot) error {
+	return Conn(ctx, r.db).Create(snapshot).Error
+}
+
+// Get lấy bản lưu theo version của sản phẩm
+func (r *ProductSnapshotRepository) Get(ctx context.Context, productID uint, version int) (*models.ProductSnapshot, error) {
+	var snapshot models.ProductSnapshot
+	if err := Conn(ctx, r.db).Where("product_id = ? AND version = ?", productID, version).First(&snapshot).Error; err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// ListByProduct lấy tối đa limit bản lưu của sản phẩm bỏ qua offset bản mới nhất, mới nhất trước, cùng tổng
+// số bản lưu
+func (r *ProductSnapshotRepository) ListByProduct(ctx context.Context, productID uint, offset, limit int) ([]models.ProductSnapshot, int64, error) {
+	var snapshots []models.ProductSnapshot
+	var total int64
+	dbQuery := Conn(ctx, r.db).Model(&models.ProductSnapshot{}).Where("product_id = ?", productID)
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := dbQuery.Order("version DESC").Offset(offset).Limit(limit).Find(&snapshots).Error
+	return snapshots, total, err
+}
+
+// Prune chỉ giữ keep bản lưu mới nhất của sản phẩm; trả về số bản lưu đã xóa
+func (r *ProductSnapshotRepository) Prune(ctx context.Context, productID uint, keep int) (int64, error) {
+	var oldest []int
+	err := Conn(ctx, r.db).Model(&models.ProductSnapshot{}).Where("product_id = ?", productID).
+		Order("version DESC").Offset(keep-1).Limit(1).Pluck("version", &oldest).Error
+	if err != nil || len(oldest) == 0 {
+		return 0, err
+	}
+	result := Conn(ctx, r.db).Where("product_id = ? AND version < ?", productID, oldest[0]).Delete(&models.ProductSnapshot{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type PromotionRepository struct {
+	db *gorm.DB
+}
+
+func NewPromotionRepository(db *gorm.DB) *PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+// List lấy khuyến mãi theo bộ lọc, ưu tiên cao trước
+func (r *PromotionRepository) List(ctx context.Context, query *models.PromotionQueryParams) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	dbQuery := Conn(ctx, r.db).Model(&models.Promotion{})
+	if query.Active != nil {
+		dbQuery = dbQuery.Where("active = ?", *query.Active)
+	}
+	if query.Type != "" {
+		dbQuery = dbQuery.Where("type = ?", query.Type)
+	}
+	err := dbQuery.Order("priority DESC, id").Find(&promotions).Error
+	return promotions, err
+}
+
+// ListActive lấy khuyến mãi đang bật và còn trong thời gian hiệu lực tại now
+func (r *PromotionRepository) ListActive(ctx context.Context, now time.Time) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	err := Conn(ctx, r.db).Where("active = ? AND (starts_at IS NULL OR starts_at <= ?) AND (ends_at IS NULL OR ends_at > ?)", true, now, now).
+		Order("priority DESC, id").Find(&promotions).Error
+	return promotions, err
+}
+
+// GetByID lấy khuyến mãi theo ID
+func (r *PromotionRepository) GetByID(ctx context.Context, id uint) (*models.Promotion, error) {
+	var promotion models.Promotion
+	if err := Conn(ctx, r.db).First(&promotion, id).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// Create lưu khuyến mãi mới
+func (r *PromotionRepository) Create(ctx context.Context, promotion *models.Promotion) error {
+	return Conn(ctx, r.db).Create(promotion).Error
+}
+
+// Save cập nhật mọi cột của khuyến mãi
+func (r *PromotionRepository) Save(ctx context.Context, promotion *models.Promotion) error {
+	return Conn(ctx, r.db).Save(promotion).Error
+}
+
+// Delete xóa khuyến mãi; trả về số dòng đã xóa
+func (r *PromotionRepository) Delete(ctx context.Context, id uint) (int64, error) {
+	result := Conn(ctx, r.db).Delete(&models.Promotion{}, id)
+	return result.RowsAffected, result.Error
+}
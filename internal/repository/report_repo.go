@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/database"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Doanh số được tính từ hóa đơn đã phát hành (hóa đơn bị hủy không được tính), chia ngày theo
+// KPITimeZone giống KPI. Mọi truy vấn đều lọc status + issued_at trước (index idx_invoices_sales)
+// và chỉ trả về dòng đã tổng hợp, không tải từng hóa đơn lên. Báo cáo đọc từ read replica nếu có.
+const salesInvoiceFilter = `i.status = 'issued'
+	AND i.issued_at >= (?::date)::timestamp AT TIME ZONE '` + KPITimeZone + `'
+	AND i.issued_at < (?::date + 1)::timestamp AT TIME ZONE '` + KPITimeZone + `'`
+
+type ReportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// SalesByPeriod tổng hợp doanh số theo ngày/tuần/tháng trong [from, to]; kỳ không có doanh số vẫn
+// có dòng với số liệu bằng 0 để biểu đồ liền mạch
+func (r *ReportRepository) SalesByPeriod(ctx context.Context, from, to time.Time, groupBy string) ([]models.SalesPeriod, error) {
+	fromDay, toDay := from.Format("2006-01-02"), to.Format("2006-01-02")
+	var periods []models.SalesPeriod
+	err := database.ReadReplica(Conn(ctx, r.db)).Raw(`
+		WITH sales AS (
+			SELECT date_trunc(?, i.issued_at AT TIME ZONE '`+KPITimeZone+`')::date AS period_start,
+				i.subtotal, i.tax_amount, i.total,
+				COALESCE((SELECT SUM(ii.quantity) FROM invoice_items ii WHERE ii.invoice_id = i.id), 0) AS units
+			FROM invoices i
+			WHERE `+salesInvoiceFilter+`
+		)
+		SELECT to_char(p.period_start, 'YYYY-MM-DD') AS period_start,
+			COUNT(s.period_start) AS invoices,
+			COALESCE(SUM(s.units), 0) AS units,
+			COALESCE(SUM(s.subtotal), 0) AS net_sales,
+			COALESCE(SUM(s.tax_amount), 0) AS tax,
+			COALESCE(SUM(s.total), 0) AS gross_sales
+		FROM (
+			SELECT gs::date AS period_start
+			FROM generate_series(date_trunc(?, ?::timestamp), ?::timestamp, ('1 ' || ?)::interval) AS gs
+		) p
+		LEFT JOIN sales s ON s.period_start = p.period_start
+		GROUP BY p.period_start
+		ORDER BY p.period_start`,
+		groupBy, fromDay, toDay, groupBy, fromDay, toDay, groupBy).Scan(&periods).Error
+	return periods, err
+}
+
+// SalesByCategory tổng hợp doanh số chưa thuế theo danh mục hiện tại của sản phẩm; sản phẩm
+// không có danh mục hoặc đã bị xóa được gộp vào "uncategorized"
+func (r *ReportRepository) SalesByCategory(ctx context.Context, from, to time.Time) ([]models.CategorySales, error) {
+	var categories []models.CategorySales
+	err := database.ReadReplica(Conn(ctx, r.db)).Raw(`
+		SELECT COALESCE(NULLIF(p.category, ''), 'uncategorized') AS category,
+			COUNT(DISTINCT i.id) AS invoices,
+			SUM(ii.quantity) AS units,
+			SUM(ii.amount) AS net_sales
+		FROM invoices i
+		JOIN invoice_items ii ON ii.invoice_id = i.id
+		LEFT JOIN products p ON p.id = ii.product_id
+		WHERE `+salesInvoiceFilter+`
+		GROUP BY 1
+		ORDER BY net_sales DESC, category`,
+		from.Format("2006-01-02"), to.Format("2006-01-02")).Scan(&categories).Error
+	return categories, err
+}
+
+// SalesByProduct tổng hợp doanh số chưa thuế theo sản phẩm, lấy limit sản phẩm bán chạy nhất.
+// Tên sản phẩm lấy theo hóa đơn gần nhất để sản phẩm đã xóa vẫn có tên.
+func (r *ReportRepository) SalesByProduct(ctx context.Context, from, to time.Time, limit int) ([]models.ProductSales, error) {
+	var products []models.ProductSales
+	err := database.ReadReplica(Conn(ctx, r.db)).Raw(`
+		SELECT ii.product_id,
+			(ARRAY_AGG(ii.name ORDER BY i.issued_at DESC))[1] AS name,
+			COALESCE(NULLIF(MAX(p.category), ''), 'uncategorized') AS category,
+			COUNT(DISTINCT i.id) AS invoices,
+			SUM(ii.quantity) AS units,
+			SUM(ii.amount) AS net_sales
+		FROM invoices i
+		JOIN invoice_items ii ON ii.invoice_id = i.id
+		LEFT JOIN products p ON p.id = ii.product_id
+		WHERE `+salesInvoiceFilter+`
+		GROUP BY ii.product_id
+		ORDER BY net_sales DESC, ii.product_id
+		LIMIT ?`,
+		from.Format("2006-01-02"), to.Format("2006-01-02"), limit).Scan(&products).Error
+	return products, err
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReturnRepository struct {
+	db *gorm.DB
+}
+
+func NewReturnRepository(db *gorm.DB) *ReturnRepository {
+	return &ReturnRepository{db: db}
+}
+
+// Create lưu yêu cầu trả hàng cùng các dòng hàng
+func (r *ReturnRepository) Create(ctx context.Context, ret *models.OrderReturn) error {
+	return Conn(ctx, r.db).Create(ret).Error
+}
+
+// GetByID lấy yêu cầu trả hàng kèm dòng hàng và ảnh
+func (r *ReturnRepository) GetByID(ctx context.Context, id uint) (*models.OrderReturn, error) {
+	var ret models.OrderReturn
+	err := Conn(ctx, r.db).Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Photos", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&ret, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// GetForUpdate lấy yêu cầu trả hàng kèm dòng hàng và khóa dòng cho tới hết transaction
+func (r *ReturnRepository) GetForUpdate(ctx context.Context, id uint) (*models.OrderReturn, error) {
+	var ret models.OrderReturn
+	if err := Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&ret, id).Error; err != nil {
+		return nil, err
+	}
+	if err := Conn(ctx, r.db).Where("return_id = ?", id).Order("id").Find(&ret.Items).Error; err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// ListByOrder lấy các yêu cầu trả hàng của đơn, mới nhất trước
+func (r *ReturnRepository) ListByOrder(ctx context.Context, orderID uint) ([]models.OrderReturn, error) {
+	var returns []models.OrderReturn
+	err := Conn(ctx, r.db).Preload("Items").Preload("Photos").
+		Where("order_id = ?", orderID).Order("created_at DESC").Find(&returns).Error
+	return returns, err
+}
+
+// ListByUser lấy mọi yêu cầu trả hàng của user kèm dòng hàng và ảnh
+func (r *ReturnRepository) ListByUser(ctx context.Context, userID uint) ([]models.OrderReturn, error) {
+	var returns []models.OrderReturn
+	err := Conn(ctx, r.db).Preload("Items").Preload("Photos").
+		Where("user_id = ?", userID).Order("created_at").Find(&returns).Error
+	return returns, err
+}
+
+// ListPhotoURLs lấy URL của mọi ảnh trả hàng
+func (r *ReturnRepository) ListPhotoURLs(ctx context.Context) ([]string, error) {
+	var urls []string
+	err := Conn(ctx, r.db).Model(&models.OrderReturnPhoto{}).Pluck("url", &urls).Error
+	return urls, err
+}
+
+// GetAll lấy danh sách yêu cầu trả hàng với bộ lọc và phân trang
+func (r *ReturnRepository) GetAll(ctx context.Context, query *models.ReturnQueryParams) ([]models.OrderReturn, int64, error) {
+	var returns []models.OrderReturn
+	var total int64
+
+	dbQuery := Conn(ctx, r.db).Model(&models.OrderReturn{})
+	if query.Status != "" {
+		dbQuery = dbQuery.Where("status = ?", query.Status)
+	}
+	if query.OrderID != 0 {
+		dbQuery = dbQuery.Where("order_id = ?", query.OrderID)
+	}
+
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.Limit
+	err := dbQuery.Preload("Items").Order("created_at DESC").Offset(offset).Limit(query.Limit).Find(&returns).Error
+	return returns, total, err
+}
+
+// ReturnedQuantities trả về số lượng đã yêu cầu trả (đang chờ hoặc đã duyệt) theo từng dòng của đơn
+func (r *ReturnRepository) ReturnedQuantities(ctx context.Context, orderID uint) (map[uint]int, error) {
+	var rows []struct {
+		OrderItemID uint
+		Quantity    int
+	}
+	err := Conn(ctx, r.db).Table("order_return_items ri").
+		Select("ri.order_item_id, SUM(ri.quantity) AS quantity").
+		Joins("JOIN order_returns rt ON rt.id = ri.return_id").
+		Where("rt.order_id = ? AND rt.status IN ?", orderID, []string{models.ReturnStatusRequested, models.ReturnStatusApproved}).
+		Group("ri.order_item_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	quantities := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		quantities[row.OrderItemID] = row.Quantity
+	}
+	return quantities, nil
+}
+
+// UpdateStatus chuyển yêu cầu từ trạng thái from sang to cùng các cột khác trong fields.
+// Trả về số dòng được cập nhật; 0 nghĩa là yêu cầu không còn ở trạng thái from.
+func (r *ReturnRepository) UpdateStatus(ctx context.Context, id uint, from, to string, fields map[string]interface{}) (int64, error) {
+	updates := map[string]interface{}{"status": to}
+	for k, v := range fields {
+		updates[k] = v
+	}
+	result := Conn(ctx, r.db).Model(&models.OrderReturn{}).Where("id = ? AND status = ?", id, from).Updates(updates)
+	return result.RowsAffected, result.Error
+}
+
+// CountPhotos đếm số ảnh của yêu cầu trả hàng
+func (r *ReturnRepository) CountPhotos(ctx context.Context, returnID uint) (int64, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.OrderReturnPhoto{}).Where("return_id = ?", returnID).Count(&count).Error
+	return count, err
+}
+
+// AddPhoto lưu ảnh đính kèm yêu cầu trả hàng
+func (r *ReturnRepository) AddPhoto(ctx context.Context, photo *models.OrderReturnPhoto) error {
+	return Conn(ctx, r.db).Create(photo).Error
+}
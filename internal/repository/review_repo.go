@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// Create lưu đánh giá mới
+func (r *ReviewRepository) Create(ctx context.Context, review *models.ProductReview) error {
+	return Conn(ctx, r.db).Create(review).Error
+}
+
+// Exists kiểm tra user đã đánh giá sản phẩm chưa
+func (r *ReviewRepository) Exists(ctx context.Context, userID, productID uint) (bool, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.ProductReview{}).
+		Where("user_id = ? AND product_id = ?", userID, productID).Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+// Get lấy đánh giá của sản phẩm
+func (r *ReviewRepository) Get(ctx context.Context, productID, id uint) (*models.ProductReview, error) {
+	var review models.ProductReview
+	if err := Conn(ctx, r.db).Where("product_id = ?", productID).First(&review, id).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// ListApproved lấy các đánh giá đã duyệt của sản phẩm theo bộ lọc, mới nhất trước
+func (r *ReviewRepository) ListApproved(ctx context.Context, productID uint, query *models.ReviewQueryParams) ([]models.ProductReview, int64, error) {
+	var reviews []models.ProductReview
+	var total int64
+
+	dbQuery := Conn(ctx, r.db).Model(&models.ProductReview{}).
+		Where("product_id = ? AND status = ?", productID, models.ModerationApproved)
+	if query.Rating != 0 {
+		dbQuery = dbQuery.Where("rating = ?", query.Rating)
+	}
+	if query.Verified {
+		dbQuery = dbQuery.Where("verified_purchase = ?", true)
+	}
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := dbQuery.Order("created_at DESC").Offset((query.Page - 1) * query.Limit).Limit(query.Limit).Find(&reviews).Error
+	return reviews, total, err
+}
+
+// ListLatestApproved lấy tối đa limit đánh giá đã duyệt mới nhất của mỗi sản phẩm trong productIDs bằng
+// một truy vấn (ROW_NUMBER theo từng sản phẩm), sắp theo sản phẩm rồi mới nhất trước
+func (r *ReviewRepository) ListLatestApproved(ctx context.Context, productIDs []uint, limit int) ([]models.ProductReview, error) {
+	db := Conn(ctx, r.db)
+	ranked := db.Model(&models.ProductReview{}).
+		Select("product_reviews.*, ROW_NUMBER() OVER (PARTITION BY product_id ORDER BY created_at DESC, id DESC) AS review_rank").
+		Where("product_id IN ? AND status = ?", productIDs, models.ModerationApproved)
+	var reviews []models.ProductReview
+	err := db.Table("(?) AS ranked", ranked).Where("review_rank <= ?", limit).
+		Order("product_id, review_rank").Find(&reviews).Error
+	return reviews, err
+}
+
+// List lấy đánh giá theo bộ lọc kiểm duyệt, cũ nhất trước (Admin)
+func (r *ReviewRepository) List(ctx context.Context, query *models.ModerationQueryParams) ([]models.ProductReview, int64, error) {
+	var reviews []models.ProductReview
+	var total int64
+
+	dbQuery := Conn(ctx, r.db).Model(&models.ProductReview{})
+	if query.Status != "" {
+		dbQuery = dbQuery.Where("status = ?", query.Status)
+	}
+	if query.ProductID != 0 {
+		dbQuery = dbQuery.Where("product_id = ?", query.ProductID)
+	}
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := dbQuery.Order("created_at").Offset((query.Page - 1) * query.Limit).Limit(query.Limit).Find(&reviews).Error
+	return reviews, total, err
+}
+
+// Moderate đặt trạng thái kiểm duyệt của đánh giá
+func (r *ReviewRepository) Moderate(ctx context.Context, id uint, status string, adminID uint, now time.Time) error {
+	return Conn(ctx, r.db).Model(&models.ProductReview{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status, "moderated_by": adminID, "moderated_at": now}).Error
+}
+
+// ListReported lấy các đánh giá còn báo cáo vi phạm chưa xử lý, nhiều báo cáo nhất trước (Admin)
+func (r *ReviewRepository) ListReported(ctx context.Context, query *models.ModerationQueryParams) ([]models.ProductReview, int64, error) {
+	var reviews []models.ProductReview
+	var total int64
+
+	dbQuery := Conn(ctx, r.db).Model(&models.ProductReview{}).Where("report_count > 0")
+	if query.Status != "" {
+		dbQuery = dbQuery.Where("status = ?", query.Status)
+	}
+	if query.ProductID != 0 {
+		dbQuery = dbQuery.Where("product_id = ?", query.ProductID)
+	}
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := dbQuery.Order("report_count DESC, created_at").Offset((query.Page - 1) * query.Limit).Limit(query.Limit).Find(&reviews).Error
+	return reviews, total, err
+}
+
+// UpsertVote lưu phiếu của user, ghi đè phiếu cũ nếu đã bình chọn
+func (r *ReviewRepository) UpsertVote(ctx context.Context, vote *models.ReviewVote) error {
+	return Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "review_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"helpful", "updated_at"}),
+	}).Create(vote).Error
+}
+
+// DeleteVote xóa phiếu của user; trả về false nếu user chưa bình chọn
+func (r *ReviewRepository) DeleteVote(ctx context.Context, reviewID, userID uint) (bool, error) {
+	result := Conn(ctx, r.db).Where("review_id = ? AND user_id = ?", reviewID, userID).Delete(&models.ReviewVote{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// RecountVotes tính lại helpful_count và unhelpful_count của đánh giá từ review_votes
+func (r *ReviewRepository) RecountVotes(ctx context.Context, reviewID uint) error {
+	count := "(SELECT COUNT(*) FROM review_votes WHERE review_votes.review_id = product_reviews.id AND review_votes.helpful = ?)"
+	return Conn(ctx, r.db).Model(&models.ProductReview{}).Where("id = ?", reviewID).UpdateColumns(map[string]interface{}{
+		"helpful_count":   gorm.Expr(count, true),
+		"unhelpful_count": gorm.Expr(count, false),
+	}).Error
+}
+
+// ReportExists kiểm tra user đã báo cáo đánh giá chưa
+func (r *ReviewRepository) ReportExists(ctx context.Context, reviewID, userID uint) (bool, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.ReviewReport{}).
+		Where("review_id = ? AND user_id = ?", reviewID, userID).Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+// CreateReport lưu báo cáo mới và tăng report_count của đánh giá
+func (r *ReviewRepository) CreateReport(ctx context.Context, report *models.ReviewReport) error {
+	db := Conn(ctx, r.db)
+	if err := db.Create(report).Error; err != nil {
+		return err
+	}
+	return db.Model(&models.ProductReview{}).Where("id = ?", report.ReviewID).
+		UpdateColumn("report_count", gorm.Expr("report_count + 1")).Error
+}
+
+// ResolveReports đánh dấu các báo cáo chưa xử lý của đánh giá là đã xử lý và đặt lại report_count
+func (r *ReviewRepository) ResolveReports(ctx context.Context, reviewID uint, now time.Time) error {
+	db := Conn(ctx, r.db)
+	err := db.Model(&models.ReviewReport{}).Where("review_id = ? AND status = ?", reviewID, models.ReviewReportOpen).
+		Updates(map[string]interface{}{"status": models.ReviewReportResolved, "resolved_at": now}).Error
+	if err != nil {
+		return err
+	}
+	return db.Model(&models.ProductReview{}).Where("id = ?", reviewID).UpdateColumn("report_count", 0).Error
+}
+
+// ListVotesByUser lấy mọi phiếu bình chọn của user
+func (r *ReviewRepository) ListVotesByUser(ctx context.Context, userID uint) ([]models.ReviewVote, error) {
+	var votes []models.ReviewVote
+	err := Conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at").Find(&votes).Error
+	return votes, err
+}
+
+// ListReportsByUser lấy mọi báo cáo vi phạm user đã gửi
+func (r *ReviewRepository) ListReportsByUser(ctx context.Context, userID uint) ([]models.ReviewReport, error) {
+	var reports []models.ReviewReport
+	err := Conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at").Find(&reports).Error
+	return reports, err
+}
+
+// ListByUser lấy mọi đánh giá user đã viết
+func (r *ReviewRepository) ListByUser(ctx context.Context, userID uint) ([]models.ProductReview, error) {
+	var reviews []models.ProductReview
+	err := Conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at").Find(&reviews).Error
+	return reviews, err
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RevokedTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRevokedTokenRepository(db *gorm.DB) *RevokedTokenRepository {
+	return &RevokedTokenRepository{db: db}
+}
+
+// Revoke thêm token vào denylist; thu hồi lại token đã có trong danh sách không có tác dụng
+func (r *RevokedTokenRepository) Revoke(ctx context.Context, token *models.RevokedToken) error {
+	return Conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+// IsRevoked kiểm tra token có jti đã bị thu hồi chưa
+func (r *RevokedTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.RevokedToken{}).Where("jti = ?", jti).Limit(1).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ScheduledTaskRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduledTaskRepository(db *gorm.DB) *ScheduledTaskRepository {
+	return &ScheduledTaskRepository{db: db}
+}
+
+// Ensure tạo dòng cho tác vụ nếu chưa có; nếu lịch thay đổi thì tính lại lần chạy kế tiếp
+func (r *ScheduledTaskRepository) Ensure(ctx context.Context, name, schedule string, nextRunAt time.Time) error {
+	task := models.ScheduledTask{Name: name, Schedule: schedule, NextRunAt: nextRunAt, LastStatus: models.TaskStatusNever}
+	return Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"schedule":    gorm.Expr("EXCLUDED.schedule"),
+			"next_run_at": gorm.Expr("CASE WHEN scheduled_tasks.schedule = EXCLUDED.schedule THEN scheduled_tasks.next_run_at ELSE EXCLUDED.next_run_at END"),
+		}),
+	}).Create(&task).Error
+}
+
+// Acquire chiếm quyền chạy tác vụ nếu đã tới hạn và không replica nào đang giữ khóa.
+// Câu UPDATE có điều kiện là nguyên tử nên chỉ một replica nhận được true.
+func (r *ScheduledTaskRepository) Acquire(ctx context.Context, name, owner string, now time.Time, lockFor time.Duration) (bool, error) {
+	result := Conn(ctx, r.db).Model(&models.ScheduledTask{}).
+		Where("name = ? AND next_run_at <= ? AND (locked_until IS NULL OR locked_until < ?)", name, now, now).
+		Updates(map[string]interface{}{
+			"locked_until": now.Add(lockFor),
+			"locked_by":    owner,
+			"last_status":  models.TaskStatusRunning,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Finish ghi kết quả lần chạy, lên lịch lần kế tiếp và nhả khóa
+func (r *ScheduledTaskRepository) Finish(ctx context.Context, name, owner string, startedAt time.Time, nextRunAt time.Time, result string, runErr error) error {
+	status, lastError := models.TaskStatusSucceeded, ""
+	if runErr != nil {
+		status, lastError = models.TaskStatusFailed, runErr.Error()
+	}
+	return Conn(ctx, r.db).Model(&models.ScheduledTask{}).Where("name = ? AND locked_by = ?", name, owner).Updates(map[string]interface{}{
+		"next_run_at":      nextRunAt,
+		"locked_until":     nil,
+		"locked_by":        "",
+		"last_run_at":      startedAt,
+		"last_status":      status,
+		"last_result":      result,
+		"last_error":       lastError,
+		"last_duration_ms": time.Since(startedAt).Milliseconds(),
+		"last_run_by":      owner,
+	}).Error
+}
+
+// Trigger đặt tác vụ chạy ở lần kiểm tra kế tiếp
+func (r *ScheduledTaskRepository) Trigger(ctx context.Context, name string) (bool, error) {
+	result := Conn(ctx, r.db).Model(&models.ScheduledTask{}).Where("name = ?", name).Update("next_run_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// List lấy tất cả tác vụ định kỳ
+func (r *ScheduledTaskRepository) List(ctx context.Context) ([]models.ScheduledTask, error) {
+	var tasks []models.ScheduledTask
+	err := Conn(ctx, r.db).Order("name ASC").Find(&tasks).Error
+	return tasks, err
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type SearchSynonymRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchSynonymRepository(db *gorm.DB) *SearchSynonymRepository {
+	return &SearchSynonymRepository{db: db}
+}
+
+// List lấy toàn bộ từ điển đồng nghĩa theo thứ tự từ khóa
+func (r *SearchSynonymRepository) List(ctx context.Context) ([]models.SearchSynonym, error) {
+	var synonyms []models.SearchSynonym
+	err := Conn(ctx, r.db).Order("term").Find(&synonyms).Error
+	return synonyms, err
+}
+
+// FindByTerms lấy các mục đồng nghĩa có từ khóa nằm trong terms
+func (r *SearchSynonymRepository) FindByTerms(ctx context.Context, terms []string) ([]models.SearchSynonym, error) {
+	var synonyms []models.SearchSynonym
+	err := Conn(ctx, r.db).Where("term IN ?", terms).Find(&synonyms).Error
+	return synonyms, err
+}
+
+// GetByID lấy mục đồng nghĩa theo ID
+func (r *SearchSynonymRepository) GetByID(ctx context.Context, id uint) (*models.SearchSynonym, error) {
+	var synonym models.SearchSynonym
+	if err := Conn(ctx, r.db).First(&synonym, id).Error; err != nil {
+		return nil, err
+	}
+	return &synonym, nil
+}
+
+// TermExists kiểm tra từ khóa đã có mục khác (loại trừ mục có ID = excludeID)
+func (r *SearchSynonymRepository) TermExists(ctx context.Context, term string, excludeID uint) (bool, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.SearchSynonym{}).Where("term = ? AND id <> ?", term, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+// Create lưu mục đồng nghĩa mới
+func (r *SearchSynonymRepository) Create(ctx context.Context, synonym *models.SearchSynonym) error {
+	return Conn(ctx, r.db).Create(synonym).Error
+}
+
+// Save ghi đè mục đồng nghĩa
+func (r *SearchSynonymRepository) Save(ctx context.Context, synonym *models.SearchSynonym) error {
+	return Conn(ctx, r.db).Save(synonym).Error
+}
+
+// Delete xóa mục đồng nghĩa; trả về false nếu không có mục nào bị xóa
+func (r *SearchSynonymRepository) Delete(ctx context.Context, id uint) (bool, error) {
+	result := Conn(ctx, r.db).Delete(&models.SearchSynonym{}, id)
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type SitemapRepository struct {
+	db *gorm.DB
+}
+
+func NewSitemapRepository(db *gorm.DB) *SitemapRepository {
+	return &SitemapRepository{db: db}
+}
+
+// Get lấy sitemap đã dựng theo tên
+func (r *SitemapRepository) Get(ctx context.Context, name string) (*models.Sitemap, error) {
+	var sitemap models.Sitemap
+	if err := Conn(ctx, r.db).Where("name = ?", name).First(&sitemap).Error; err != nil {
+		return nil, err
+	}
+	return &sitemap, nil
+}
+
+// Count đếm số sitemap đã dựng
+func (r *SitemapRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.Sitemap{}).Count(&count).Error
+	return count, err
+}
+
+// ReplaceAll xóa mọi sitemap cũ và lưu các sitemap mới
+func (r *SitemapRepository) ReplaceAll(ctx context.Context, sitemaps []models.Sitemap) error {
+	db := Conn(ctx, r.db)
+	if err := db.Where("1 = 1").Delete(&models.Sitemap{}).Error; err != nil {
+		return err
+	}
+	return db.Create(&sitemaps).Error
+}
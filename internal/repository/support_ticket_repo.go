@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SupportTicketRepository struct {
+	db *gorm.DB
+}
+
+func NewSupportTicketRepository(db *gorm.DB) *SupportTicketRepository {
+	return &SupportTicketRepository{db: db}
+}
+
+// Create lưu phiếu hỗ trợ cùng tin nhắn đầu tiên
+func (r *SupportTicketRepository) Create(ctx context.Context, ticket *models.SupportTicket) error {
+	return Conn(ctx, r.db).Create(ticket).Error
+}
+
+// GetByID lấy phiếu hỗ trợ kèm các tin nhắn theo thứ tự gửi
+func (r *SupportTicketRepository) GetByID(ctx context.Context, id uint) (*models.SupportTicket, error) {
+	var ticket models.SupportTicket
+	err := Conn(ctx, r.db).Preload("Messages", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&ticket, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// GetForUpdate lấy phiếu hỗ trợ (không kèm tin nhắn) và khóa dòng cho tới hết transaction
+func (r *SupportTicketRepository) GetForUpdate(ctx context.Context, id uint) (*models.SupportTicket, error) {
+	var ticket models.SupportTicket
+	if err := Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&ticket, id).Error; err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// GetAll lấy danh sách phiếu hỗ trợ (không kèm tin nhắn) với bộ lọc và phân trang, phiếu có tin nhắn mới
+// nhất trước
+func (r *SupportTicketRepository) GetAll(ctx context.Context, query *models.SupportTicketQueryParams) ([]models.SupportTicket, int64, error) {
+	var tickets []models.SupportTicket
+	var total int64
+
+	dbQuery := Conn(ctx, r.db).Model(&models.SupportTicket{})
+	if query.Status != "" {
+		dbQuery = dbQuery.Where("status = ?", query.Status)
+	}
+	if query.Category != "" {
+		dbQuery = dbQuery.Where("category = ?", query.Category)
+	}
+	if query.UserID != 0 {
+		dbQuery = dbQuery.Where("user_id = ?", query.UserID)
+	}
+	if query.Email != "" {
+		dbQuery = dbQuery.Where("email = ?", strings.ToLower(query.Email))
+	}
+
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.Limit
+	err := dbQuery.Order("last_message_at DESC, id DESC").Offset(offset).Limit(query.Limit).Find(&tickets).Error
+	return tickets, total, err
+}
+
+// AddMessage lưu tin nhắn mới của phiếu
+func (r *SupportTicketRepository) AddMessage(ctx context.Context, message *models.SupportTicketMessage) error {
+	return Conn(ctx, r.db).Create(message).Error
+}
+
+// Update cập nhật các cột trong fields của phiếu
+func (r *SupportTicketRepository) Update(ctx context.Context, id uint, fields map[string]interface{}) error {
+	return Conn(ctx, r.db).Model(&models.SupportTicket{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// ListByUser lấy mọi phiếu hỗ trợ của user kèm tin nhắn
+func (r *SupportTicketRepository) ListByUser(ctx context.Context, userID uint) ([]models.SupportTicket, error) {
+	var tickets []models.SupportTicket
+	err := Conn(ctx, r.db).Preload("Messages", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("user_id = ?", userID).Order("created_at").Find(&tickets).Error
+	return tickets, err
+}
+
+// DeleteUserData xóa các phiếu hỗ trợ của user cùng tin nhắn
+func (r *SupportTicketRepository) DeleteUserData(ctx context.Context, userID uint) error {
+	ids := Conn(ctx, r.db).Model(&models.SupportTicket{}).Select("id").Where("user_id = ?", userID)
+	if err := Conn(ctx, r.db).Where("ticket_id IN (?)", ids).Delete(&models.SupportTicketMessage{}).Error; err != nil {
+		return err
+	}
+	return Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&models.SupportTicket{}).Error
+}
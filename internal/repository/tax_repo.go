@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type TaxRateRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxRateRepository(db *gorm.DB) *TaxRateRepository {
+	return &TaxRateRepository{db: db}
+}
+
+// List lấy mọi quy tắc thuế suất
+func (r *TaxRateRepository) List(ctx context.Context) ([]models.TaxRate, error) {
+	var rates []models.TaxRate
+	err := Conn(ctx, r.db).Order("category, province, id").Find(&rates).Error
+	return rates, err
+}
+
+// GetByID lấy một quy tắc thuế suất
+func (r *TaxRateRepository) GetByID(ctx context.Context, id uint) (*models.TaxRate, error) {
+	var rate models.TaxRate
+	if err := Conn(ctx, r.db).First(&rate, id).Error; err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// Create lưu quy tắc thuế suất mới
+func (r *TaxRateRepository) Create(ctx context.Context, rate *models.TaxRate) error {
+	return Conn(ctx, r.db).Create(rate).Error
+}
+
+// Save ghi đè quy tắc thuế suất
+func (r *TaxRateRepository) Save(ctx context.Context, rate *models.TaxRate) error {
+	return Conn(ctx, r.db).Save(rate).Error
+}
+
+// Delete xóa quy tắc thuế suất
+func (r *TaxRateRepository) Delete(ctx context.Context, id uint) (int64, error) {
+	result := Conn(ctx, r.db).Delete(&models.TaxRate{}, id)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey là khóa context chứa transaction đang mở bởi TxManager
+type txKey struct{}
+
+// TxManager chạy các thao tác trên nhiều repository trong cùng một transaction (unit of work).
+// Transaction được truyền qua context: mọi phương thức repository nhận context đó đều chạy trong nó,
+// nên service không cần dựng lại repository với *gorm.DB của transaction.
+type TxManager struct {
+	db *gorm.DB
+}
+
+func NewTxManager(db *gorm.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTx chạy fn trong một transaction; commit nếu fn trả về nil, rollback nếu fn trả về lỗi hoặc panic.
+// Nếu ctx đã mang transaction, fn chạy trong savepoint của transaction đó.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return Conn(ctx, m.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// Conn trả về transaction mang trong ctx (nếu có), ngược lại là db gắn với ctx để truy vấn bị hủy
+// cùng request
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type UserInvitationRepository struct {
+	db *gorm.DB
+}
+
+func NewUserInvitationRepository(db *gorm.DB) *UserInvitationRepository {
+	return &UserInvitationRepository{db: db}
+}
+
+// Create lưu lời mời mới
+func (r *UserInvitationRepository) Create(ctx context.Context, invitation *models.UserInvitation) error {
+	return Conn(ctx, r.db).Create(invitation).Error
+}
+
+// GetByTokenHash lấy lời mời có token trùng hash
+func (r *UserInvitationRepository) GetByTokenHash(ctx context.Context, hash string) (*models.UserInvitation, error) {
+	var invitation models.UserInvitation
+	if err := Conn(ctx, r.db).Where("token_hash = ?", hash).First(&invitation).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// CancelPending hủy các lời mời chưa được chấp nhận của user
+func (r *UserInvitationRepository) CancelPending(ctx context.Context, userID uint, at time.Time) error {
+	return Conn(ctx, r.db).Model(&models.UserInvitation{}).
+		Where("user_id = ? AND accepted_at IS NULL AND cancelled_at IS NULL", userID).
+		Update("cancelled_at", at).Error
+}
+
+// MarkAccepted đánh dấu lời mời đã được chấp nhận, trả về false nếu lời mời đã được dùng hoặc bị hủy trước đó
+func (r *UserInvitationRepository) MarkAccepted(ctx context.Context, id uint, at time.Time) (bool, error) {
+	result := Conn(ctx, r.db).Model(&models.UserInvitation{}).
+		Where("id = ? AND accepted_at IS NULL AND cancelled_at IS NULL", id).
+		Update("accepted_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteUserData xóa các lời mời của user
+func (r *UserInvitationRepository) DeleteUserData(ctx context.Context, userID uint) error {
+	return Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&models.UserInvitation{}).Error
+}
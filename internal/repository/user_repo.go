@@ -1,6 +1,12 @@
 package repository
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/database"
+	"github.com/NgTruong624/project_backend/internal/events"
 	"github.com/NgTruong624/project_backend/internal/models"
 	"gorm.io/gorm"
 )
@@ -14,16 +20,19 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 }
 
 // GetAllUsers lấy danh sách người dùng với phân trang và tìm kiếm
-func (r *UserRepository) GetAllUsers(query *models.UserQueryParams) ([]models.User, int64, error) {
+func (r *UserRepository) GetAllUsers(ctx context.Context, query *models.UserQueryParams) ([]models.User, int64, error) {
 	var users []models.User
 	var total int64
 
-	dbQuery := r.db.Model(&models.User{})
+	dbQuery := Conn(ctx, r.db).Model(&models.User{})
+	if query.Deleted {
+		dbQuery = Conn(ctx, r.db).Unscoped().Model(&models.User{}).Where("deleted_at IS NOT NULL")
+	}
 
 	// Apply search filters (optional)
 	if query.Search != "" {
 		dbQuery = dbQuery.Where(
-			"username ILIKE ? OR email ILIKE ? OR full_name ILIKE ?",
+			database.ILike(r.db, "username", "email", "full_name"),
 			"%"+query.Search+"%",
 			"%"+query.Search+"%",
 			"%"+query.Search+"%",
@@ -34,14 +43,48 @@ func (r *UserRepository) GetAllUsers(query *models.UserQueryParams) ([]models.Us
 	if query.Role != "" {
 		dbQuery = dbQuery.Where("role = ?", query.Role)
 	}
+	if query.Status != "" {
+		dbQuery = dbQuery.Where("status = ?", query.Status)
+	}
+
+	// Apply created date range
+	if !query.StartDate.IsZero() {
+		dbQuery = dbQuery.Where("created_at >= ?", query.StartDate)
+	}
+	if !query.EndDate.IsZero() {
+		dbQuery = dbQuery.Where("created_at <= ?", query.EndDate)
+	}
+
+	// Đang hoạt động = có lần đăng nhập (ghi trong audit_logs) trong UserActiveWindow gần nhất
+	if query.IsActive != nil {
+		recentLogin := "EXISTS (SELECT 1 FROM audit_logs a WHERE a.user_id = users.id AND a.action = ? AND a.created_at >= ?)"
+		if !*query.IsActive {
+			recentLogin = "NOT " + recentLogin
+		}
+		dbQuery = dbQuery.Where(recentLogin, events.UserLoggedInEvent, time.Now().Add(-models.UserActiveWindow))
+	}
 
 	// Get total count before pagination
 	if err := dbQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	// Apply default sorting by created_at desc
-	dbQuery = dbQuery.Order("created_at DESC")
+	// Chỉ sắp xếp theo các cột trong danh sách cho phép; id giữ thứ tự ổn định giữa các trang
+	if query.SortBy != "" {
+		validSortFields := map[string]string{
+			"created_at": "created_at", "username": "username", "email": "email",
+		}
+		if sortField, ok := validSortFields[query.SortBy]; ok {
+			order := "ASC"
+			if query.Order == "desc" {
+				order = "DESC"
+			}
+			dbQuery = dbQuery.Order(sortField + " " + order).Order("id " + order)
+		}
+	} else {
+		// Apply default sorting by created_at desc
+		dbQuery = dbQuery.Order("created_at DESC").Order("id DESC")
+	}
 
 	// Apply pagination
 	offset := (query.Page - 1) * query.Limit
@@ -53,19 +96,50 @@ func (r *UserRepository) GetAllUsers(query *models.UserQueryParams) ([]models.Us
 }
 
 // GetByID lấy user theo ID (có thể cần cho các chức năng khác)
-func (r *UserRepository) GetByID(id uint) (*models.User, error) {
+func (r *UserRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
 	var user models.User
-	err := r.db.First(&user, id).Error
+	err := Conn(ctx, r.db).First(&user, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
+// GetStats tính số đơn hàng, số tiền đã chi và lần đăng nhập gần nhất của user
+func (r *UserRepository) GetStats(ctx context.Context, userID uint) (*models.UserStats, error) {
+	var stats models.UserStats
+	err := Conn(ctx, r.db).Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM orders o WHERE o.user_id = @user) AS order_count,
+			(SELECT COALESCE(SUM(p.amount - p.refunded_amount), 0)
+				FROM payments p JOIN orders o ON o.id = p.order_id
+				WHERE o.user_id = @user AND p.status IN @paid) AS total_spent`,
+		map[string]interface{}{
+			"user": userID,
+			"paid": []string{models.PaymentStatusSucceeded, models.PaymentStatusPartiallyRefunded, models.PaymentStatusRefunded},
+		}).Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Truy vấn riêng theo cột created_at để driver trả về đúng kiểu thời gian (MAX() trên SQLite trả về chuỗi)
+	var logins []time.Time
+	err = Conn(ctx, r.db).Table("audit_logs").
+		Where("user_id = ? AND action = ?", userID, events.UserLoggedInEvent).
+		Order("created_at DESC").Limit(1).Pluck("created_at", &logins).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(logins) > 0 {
+		stats.LastLoginAt = &logins[0]
+	}
+	return &stats, nil
+}
+
 // GetByUsername lấy user theo username (có thể cần cho các chức năng khác)
-func (r *UserRepository) GetByUsername(username string) (*models.User, error) {
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	var user models.User
-	err := r.db.Where("username = ?", username).First(&user).Error
+	err := Conn(ctx, r.db).Where("username = ?", username).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
@@ -73,11 +147,134 @@ func (r *UserRepository) GetByUsername(username string) (*models.User, error) {
 }
 
 // GetByEmail lấy user theo email (có thể cần cho các chức năng khác)
-func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := Conn(ctx, r.db).Where("email = ?", email).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// EmailTaken kiểm tra email (không phân biệt hoa thường) đã thuộc về user nào chưa, kể cả user đã xóa mềm
+func (r *UserRepository) EmailTaken(ctx context.Context, email string) (bool, error) {
+	var count int64
+	err := Conn(ctx, r.db).Unscoped().Model(&models.User{}).Where("LOWER(email) = LOWER(?)", email).Count(&count).Error
+	return count > 0, err
+}
+
+// UsernameTaken kiểm tra username đã thuộc về user nào chưa, kể cả user đã xóa mềm
+func (r *UserRepository) UsernameTaken(ctx context.Context, username string) (bool, error) {
+	var count int64
+	err := Conn(ctx, r.db).Unscoped().Model(&models.User{}).Where("username = ?", username).Count(&count).Error
+	return count > 0, err
+}
+
+// Create lưu user mới
+func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	return Conn(ctx, r.db).Create(user).Error
+}
+
+// Activate đặt mật khẩu cho tài khoản được mời và chuyển sang active; trả về false nếu tài khoản không còn ở
+// trạng thái invited
+func (r *UserRepository) Activate(ctx context.Context, id uint, passwordHash string) (bool, error) {
+	result := Conn(ctx, r.db).Model(&models.User{}).Where("id = ? AND status = ?", id, models.UserStatusInvited).
+		Updates(map[string]interface{}{
+			"password":      passwordHash,
+			"status":        models.UserStatusActive,
+			"token_version": gorm.Expr("token_version + 1"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ChangeEmail đổi email của user và tăng token_version để mọi phiên đăng nhập hiện có hết hiệu lực
+func (r *UserRepository) ChangeEmail(ctx context.Context, id uint, email string) error {
+	return Conn(ctx, r.db).Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"email":         email,
+		"token_version": gorm.Expr("token_version + 1"),
+	}).Error
+}
+
+// BumpTokenVersion tăng token_version để mọi token đã cấp cho user hết hiệu lực, trả về giá trị mới
+func (r *UserRepository) BumpTokenVersion(ctx context.Context, id uint) (int, error) {
+	err := Conn(ctx, r.db).Model(&models.User{}).Where("id = ?", id).
+		Update("token_version", gorm.Expr("token_version + 1")).Error
 	if err != nil {
+		return 0, err
+	}
+	return r.TokenVersion(ctx, id)
+}
+
+// TokenVersion lấy token_version hiện tại của user chưa bị xóa; dùng để kiểm tra JWT còn hiệu lực
+func (r *UserRepository) TokenVersion(ctx context.Context, id uint) (int, error) {
+	var versions []int
+	if err := Conn(ctx, r.db).Model(&models.User{}).Where("id = ?", id).Limit(1).Pluck("token_version", &versions).Error; err != nil {
+		return 0, err
+	}
+	if len(versions) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return versions[0], nil
+}
+
+// GetByIDUnscoped lấy user theo ID kể cả khi đã bị xóa mềm
+func (r *UserRepository) GetByIDUnscoped(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := Conn(ctx, r.db).Unscoped().First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetByIDsUnscoped lấy các user theo ID kể cả khi đã bị xóa mềm; ID không tồn tại bị bỏ qua
+func (r *UserRepository) GetByIDsUnscoped(ctx context.Context, ids []uint) ([]models.User, error) {
+	users := []models.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := Conn(ctx, r.db).Unscoped().Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
+// GetDeleted lấy user đã bị xóa mềm theo ID
+func (r *UserRepository) GetDeleted(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := Conn(ctx, r.db).Unscoped().Where("deleted_at IS NOT NULL").First(&user, id).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
+
+// SoftDelete xóa mềm user
+func (r *UserRepository) SoftDelete(ctx context.Context, id uint) error {
+	return Conn(ctx, r.db).Delete(&models.User{}, id).Error
+}
+
+// Restore khôi phục user đã xóa mềm nhưng chưa bị ẩn danh hóa, trả về false nếu không có user phù hợp
+func (r *UserRepository) Restore(ctx context.Context, id uint) (bool, error) {
+	result := Conn(ctx, r.db).Unscoped().Model(&models.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL AND anonymized_at IS NULL", id).
+		Update("deleted_at", nil)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListPendingAnonymization lấy ID các user đã xóa trước cutoff mà chưa được ẩn danh hóa
+func (r *UserRepository) ListPendingAnonymization(ctx context.Context, cutoff time.Time) ([]uint, error) {
+	var ids []uint
+	err := Conn(ctx, r.db).Unscoped().Model(&models.User{}).
+		Where("deleted_at < ? AND anonymized_at IS NULL", cutoff).Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Anonymize xóa thông tin định danh của user đã xóa mềm. Username và email được thay bằng
+// giá trị giữ chỗ theo ID (vẫn duy nhất), mật khẩu bị xóa nên không thể đăng nhập lại.
+func (r *UserRepository) Anonymize(ctx context.Context, id uint) error {
+	return Conn(ctx, r.db).Unscoped().Model(&models.User{}).Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"username":      fmt.Sprintf("deleted-user-%d", id),
+			"email":         fmt.Sprintf("deleted-user-%d@anonymized.invalid", id),
+			"full_name":     "",
+			"password":      "",
+			"anonymized_at": time.Now(),
+		}).Error
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VisitorSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewVisitorSessionRepository(db *gorm.DB) *VisitorSessionRepository {
+	return &VisitorSessionRepository{db: db}
+}
+
+// Touch tạo phiên nếu chưa có và cập nhật thời điểm hoạt động gần nhất
+func (r *VisitorSessionRepository) Touch(ctx context.Context, id string, now time.Time) error {
+	session := &models.VisitorSession{ID: id, CreatedAt: now, LastSeenAt: now}
+	return Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_seen_at"}),
+	}).Create(session).Error
+}
+
+// SetCartToken gắn (hoặc gỡ khi token nil) token giỏ của khách vào phiên
+func (r *VisitorSessionRepository) SetCartToken(ctx context.Context, id string, token *string) error {
+	return Conn(ctx, r.db).Model(&models.VisitorSession{}).Where("id = ?", id).Update("cart_token", token).Error
+}
+
+// CartToken lấy token giỏ đang gắn với phiên; trả về chuỗi rỗng nếu phiên không có giỏ
+func (r *VisitorSessionRepository) CartToken(ctx context.Context, id string) (string, error) {
+	var session models.VisitorSession
+	err := Conn(ctx, r.db).Select("id", "cart_token").Where("id = ?", id).Take(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && session.CartToken == nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return *session.CartToken, nil
+}
+
+// RecordView ghi nhận khách vừa xem sản phẩm và chỉ giữ keep sản phẩm xem gần nhất của phiên
+func (r *VisitorSessionRepository) RecordView(ctx context.Context, id string, productID uint, now time.Time, keep int) error {
+	db := Conn(ctx, r.db)
+	view := &models.RecentlyViewedProduct{VisitorID: id, ProductID: productID, ViewedAt: now}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "visitor_id"}, {Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"viewed_at"}),
+	}).Create(view).Error; err != nil {
+		return err
+	}
+
+	// MySQL không hỗ trợ LIMIT trong subquery IN nên lấy mốc thời gian của dòng đầu tiên bị bỏ
+	var cutoff []time.Time
+	if err := db.Model(&models.RecentlyViewedProduct{}).Where("visitor_id = ?", id).
+		Order("viewed_at DESC").Offset(keep).Limit(1).Pluck("viewed_at", &cutoff).Error; err != nil {
+		return err
+	}
+	if len(cutoff) == 0 {
+		return nil
+	}
+	return db.Where("visitor_id = ? AND viewed_at <= ?", id, cutoff[0]).Delete(&models.RecentlyViewedProduct{}).Error
+}
+
+// RecentlyViewed lấy các sản phẩm phiên đã xem, mới nhất trước
+func (r *VisitorSessionRepository) RecentlyViewed(ctx context.Context, id string, limit int) ([]models.Product, error) {
+	var products []models.Product
+	err := Conn(ctx, r.db).
+		Joins("JOIN recently_viewed_products ON recently_viewed_products.product_id = products.id").
+		Where("recently_viewed_products.visitor_id = ? AND products.status = ?", id, models.ProductStatusPublished).
+		Order("recently_viewed_products.viewed_at DESC").Limit(limit).
+		Find(&products).Error
+	return products, err
+}
+
+// DeleteIdle xóa các phiên không có hoạt động từ trước cutoff cùng sản phẩm đã xem của chúng
+func (r *VisitorSessionRepository) DeleteIdle(ctx context.Context, cutoff time.Time) (int64, error) {
+	db := Conn(ctx, r.db)
+	idle := db.Model(&models.VisitorSession{}).Select("id").Where("last_seen_at < ?", cutoff)
+	if err := db.Where("visitor_id IN (?)", idle).Delete(&models.RecentlyViewedProduct{}).Error; err != nil {
+		return 0, err
+	}
+	result := db.Where("last_seen_at < ?", cutoff).Delete(&models.VisitorSession{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// CreateSubscription tạo subscription mới
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	return Conn(ctx, r.db).Create(sub).Error
+}
+
+// GetSubscription lấy subscription theo ID
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id uint) (*models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	if err := Conn(ctx, r.db).First(&sub, id).Error; err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// ListSubscriptions lấy tất cả subscription
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	var subs []models.WebhookSubscription
+	err := Conn(ctx, r.db).Order("created_at DESC").Find(&subs).Error
+	return subs, err
+}
+
+// DeleteSubscription xóa subscription cùng các lần gửi còn đang chờ
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id uint) error {
+	return Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ? AND status = ?", id, models.WebhookDeliveryPending).
+			Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.WebhookSubscription{}, id).Error
+	})
+}
+
+// FindActiveByEvent lấy các subscription đang hoạt động có đăng ký sự kiện
+func (r *WebhookRepository) FindActiveByEvent(ctx context.Context, event string) ([]models.WebhookSubscription, error) {
+	var subs []models.WebhookSubscription
+	err := Conn(ctx, r.db).Where("active = ? AND ? = ANY(string_to_array(events, ','))", true, event).Find(&subs).Error
+	return subs, err
+}
+
+// CreateDeliveries đưa các lần gửi mới vào hàng đợi
+func (r *WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return Conn(ctx, r.db).Create(&deliveries).Error
+}
+
+// ClaimDue lấy tối đa limit lần gửi đã tới hạn và giữ chúng tới leaseUntil: attempts tăng 1 và
+// next_attempt_at được dời tới leaseUntil trong một transaction ngắn, nên worker/replica khác bỏ qua chúng
+// mà không cần giữ khóa hàng trong lúc gửi. Worker dừng giữa chừng thì lần gửi được lấy lại khi lease hết hạn.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, leaseUntil time.Time) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// SKIP LOCKED để các worker claim đồng thời lấy các lô khác nhau
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, time.Now()).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+		ids := make([]uint, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+			deliveries[i].Attempts++
+			deliveries[i].NextAttemptAt = leaseUntil
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": leaseUntil,
+		}).Error
+	})
+	return deliveries, err
+}
+
+// FinishDelivery lưu kết quả của một lần gửi đã claim. Trả về false nếu lần gửi đã được claim lại (lease hết
+// hạn trước khi gửi xong) hoặc được admin gửi lại, khi đó kết quả này bị bỏ qua.
+func (r *WebhookRepository) FinishDelivery(ctx context.Context, delivery *models.WebhookDelivery) (bool, error) {
+	result := Conn(ctx, r.db).Model(delivery).
+		Where("status = ? AND attempts = ?", models.WebhookDeliveryPending, delivery.Attempts).
+		Select("status", "next_attempt_at", "response_status", "last_error", "delivered_at").
+		Updates(delivery)
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetDelivery lấy một lần gửi theo ID
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := Conn(ctx, r.db).First(&delivery, id).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// RetryDelivery đưa một lần gửi trở lại hàng đợi để gửi ngay
+func (r *WebhookRepository) RetryDelivery(ctx context.Context, id uint) error {
+	return Conn(ctx, r.db).Model(&models.WebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          models.WebhookDeliveryPending,
+		"next_attempt_at": time.Now(),
+	}).Error
+}
+
+// ListDeliveries lấy nhật ký gửi của một subscription
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID uint, query *models.WebhookDeliveryQueryParams) ([]models.WebhookDelivery, int64, error) {
+	var deliveries []models.WebhookDelivery
+	var total int64
+
+	dbQuery := Conn(ctx, r.db).Model(&models.WebhookDelivery{}).Where("subscription_id = ?", subscriptionID)
+	if query.Status != "" {
+		dbQuery = dbQuery.Where("status = ?", query.Status)
+	}
+	if query.Event != "" {
+		dbQuery = dbQuery.Where("event = ?", query.Event)
+	}
+
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.Limit
+	if err := dbQuery.Order("created_at DESC").Offset(offset).Limit(query.Limit).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
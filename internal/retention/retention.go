@@ -0,0 +1,218 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/partition"
+	"gorm.io/gorm"
+)
+
+// Policy mô tả chính sách lưu giữ dữ liệu cho một bảng có lưu lượng ghi lớn
+type Policy struct {
+	Name       string        // tên chính sách, dùng cho biến môi trường RETENTION_<NAME>_DAYS
+	Table      string        // bảng cần dọn dẹp
+	TimeColumn string        // cột thời gian dùng để so sánh với mốc cắt
+	MaxAge     time.Duration // thời gian lưu giữ tối đa, 0 = tắt chính sách
+	BatchSize  int           // số dòng xóa mỗi lần để tránh khóa bảng lâu
+}
+
+// PolicyStats chứa thống kê về các lần dọn dẹp của một chính sách
+type PolicyStats struct {
+	Table       string    `json:"table"`
+	MaxAgeDays  int       `json:"max_age_days"`
+	LastRunAt   time.Time `json:"last_run_at"`
+	LastPurged  int64     `json:"last_purged"`
+	TotalPurged int64     `json:"total_purged"`
+	LastError   string    `json:"last_error,omitempty"`
+	Skipped     bool      `json:"skipped"`
+}
+
+// Pruner định kỳ xóa các dòng đã hết hạn lưu giữ
+type Pruner struct {
+	db       *gorm.DB
+	policies []Policy
+	interval time.Duration
+
+	mu    sync.RWMutex
+	stats map[string]*PolicyStats
+}
+
+const defaultBatchSize = 5000
+
+// DefaultPolicies trả về các chính sách mặc định cho những bảng có lưu lượng lớn
+func DefaultPolicies() []Policy {
+	return []Policy{
+		{Name: "login_events", Table: "login_events", TimeColumn: "created_at", MaxAge: 90 * 24 * time.Hour},
+		{Name: "analytics_events", Table: "analytics_events", TimeColumn: "created_at", MaxAge: 30 * 24 * time.Hour},
+		{Name: "audit_logs", Table: "audit_logs", TimeColumn: "created_at", MaxAge: 365 * 24 * time.Hour},
+		{Name: "webhook_deliveries", Table: "webhook_deliveries", TimeColumn: "created_at", MaxAge: 30 * 24 * time.Hour},
+		// Token bị thu hồi chỉ cần giữ tới khi hết hạn; sau đó chữ ký hết hiệu lực nên dòng có thể xóa
+		{Name: "revoked_tokens", Table: "revoked_tokens", TimeColumn: "expires_at", MaxAge: 24 * time.Hour},
+	}
+}
+
+// LoadPoliciesFromEnv ghi đè thời gian lưu giữ bằng biến môi trường RETENTION_<NAME>_DAYS
+func LoadPoliciesFromEnv(policies []Policy) []Policy {
+	result := make([]Policy, 0, len(policies))
+	for _, p := range policies {
+		key := "RETENTION_" + strings.ToUpper(p.Name) + "_DAYS"
+		if v := os.Getenv(key); v != "" {
+			days, err := strconv.Atoi(v)
+			if err != nil || days < 0 {
+				log.Printf("Warning: invalid %s=%q, keeping default", key, v)
+			} else {
+				p.MaxAge = time.Duration(days) * 24 * time.Hour
+			}
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// NewPruner tạo pruner mới
+func NewPruner(db *gorm.DB, policies []Policy, interval time.Duration) *Pruner {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	p := &Pruner{
+		db:       db,
+		policies: policies,
+		interval: interval,
+		stats:    make(map[string]*PolicyStats),
+	}
+	for _, policy := range policies {
+		p.stats[policy.Name] = &PolicyStats{
+			Table:      policy.Table,
+			MaxAgeDays: int(policy.MaxAge / (24 * time.Hour)),
+		}
+	}
+	return p
+}
+
+// Start chạy vòng lặp dọn dẹp định kỳ cho tới khi ctx bị hủy
+func (p *Pruner) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	go func() {
+		defer ticker.Stop()
+		p.PruneOnce(ctx)
+		for {
+			select {
+			case <-ticker.C:
+				p.PruneOnce(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// PruneOnce chạy tất cả chính sách một lần
+func (p *Pruner) PruneOnce(ctx context.Context) {
+	for _, policy := range p.policies {
+		if ctx.Err() != nil {
+			return
+		}
+		p.runPolicy(ctx, policy)
+	}
+}
+
+func (p *Pruner) runPolicy(ctx context.Context, policy Policy) {
+	var (
+		purged  int64
+		err     error
+		skipped bool
+	)
+
+	// Bỏ qua nếu chính sách bị tắt hoặc bảng chưa tồn tại
+	if policy.MaxAge <= 0 || !p.db.Migrator().HasTable(policy.Table) {
+		skipped = true
+	} else {
+		purged, err = p.prune(ctx, policy)
+		if err != nil {
+			log.Printf("Retention: failed to prune %s: %v", policy.Table, err)
+		} else if purged > 0 {
+			log.Printf("Retention: purged %d rows from %s", purged, policy.Table)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.stats[policy.Name]
+	s.LastRunAt = time.Now()
+	s.Skipped = skipped
+	s.LastPurged = purged
+	s.TotalPurged += purged
+	s.LastError = ""
+	if err != nil {
+		s.LastError = err.Error()
+	}
+}
+
+// prune xóa theo từng lô nhỏ dựa trên khóa chính để không giữ khóa lâu trên
+// bảng lớn; điều kiện theo cột thời gian cho phép Postgres loại bỏ các phân
+// vùng không liên quan khi bảng được phân vùng theo thời gian.
+func (p *Pruner) prune(ctx context.Context, policy Policy) (int64, error) {
+	batchSize := policy.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	cutoff := time.Now().Add(-policy.MaxAge)
+
+	// Với bảng phân vùng, xóa nguyên các phân vùng đã hết hạn trước (rẻ hơn DELETE),
+	// phần còn lại trong phân vùng giao với mốc cắt được xóa theo lô như bình thường
+	var total int64
+	if partitioned, err := partition.IsPartitioned(p.db, policy.Table); err != nil {
+		return 0, err
+	} else if partitioned {
+		dropped, err := partition.DropPartitionsBefore(p.db.WithContext(ctx), policy.Table, cutoff)
+		total += dropped
+		if err != nil {
+			return total, err
+		}
+	}
+
+	sql := fmt.Sprintf(
+		"DELETE FROM %[1]s WHERE id IN (SELECT id FROM %[1]s WHERE %[2]s < ? LIMIT ?)",
+		policy.Table, policy.TimeColumn,
+	)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		result := p.db.WithContext(ctx).Exec(sql, cutoff, batchSize)
+		if result.Error != nil {
+			return total, result.Error
+		}
+		total += result.RowsAffected
+		if result.RowsAffected < int64(batchSize) {
+			return total, nil
+		}
+	}
+}
+
+// GetStats trả về thống kê số dòng đã xóa theo từng chính sách
+func (p *Pruner) GetStats() map[string]interface{} {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	policies := make(map[string]PolicyStats, len(p.stats))
+	var total int64
+	for name, s := range p.stats {
+		policies[name] = *s
+		total += s.TotalPurged
+	}
+
+	return map[string]interface{}{
+		"interval":     p.interval.String(),
+		"total_purged": total,
+		"policies":     policies,
+	}
+}
@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/NgTruong624/project_backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// opsRouter là nhóm route vận hành (/admin/ops) cho các số liệu nội bộ của hệ thống.
+// Chỉ tạo được qua newOpsRouter, vốn luôn gắn JWT và quyền admin vào group, và không để lộ
+// group bên dưới, nên endpoint vận hành không thể bị đăng ký ngoài vùng được bảo vệ.
+type opsRouter struct {
+	group *gin.RouterGroup
+}
+
+func newOpsRouter(api *gin.RouterGroup, deps *Dependencies) *opsRouter {
+	return &opsRouter{group: api.Group("/admin/ops", deps.JWT.AuthMiddleware(), adminMiddleware())}
+}
+
+// Stats đăng ký GET /admin/ops/<path> trả về {"<key>": stats()}
+func (o *opsRouter) Stats(path, key string, stats func() interface{}) {
+	o.group.GET(path, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{key: stats()})
+	})
+}
+
+// registerOpsRoutes đăng ký các endpoint vận hành; endpoint mới thuộc loại này phải được thêm ở đây
+func registerOpsRoutes(api *gin.RouterGroup, deps *Dependencies) {
+	ops := newOpsRouter(api, deps)
+
+	// Thống kê giới hạn tần suất request theo client
+	ops.Stats("/rate-limits", "rate_limit_stats", func() interface{} { return middleware.GetGlobalRateLimiter().GetStats() })
+	// Thống kê dọn dẹp dữ liệu theo chính sách lưu giữ
+	ops.Stats("/retention", "retention_stats", func() interface{} { return deps.Pruner.GetStats() })
+	// Trạng thái phân vùng của các bảng sự kiện
+	ops.Stats("/partitions", "partition_stats", func() interface{} { return deps.Partitions.GetStats() })
+	// Trạng thái các node database và số lần failover
+	ops.Stats("/database", "database_stats", func() interface{} { return deps.DBFailover.GetStats() })
+	// Các câu truy vấn chậm nhất từ khi khởi động
+	ops.Stats("/slow-queries", "slow_query_stats", func() interface{} { return deps.SlowQueries.GetStats() })
+	// Kết quả so sánh shadow read giữa cài đặt mới và cũ
+	ops.Stats("/shadow-reads", "shadow_read_stats", func() interface{} { return deps.ShadowReads.GetStats() })
+}
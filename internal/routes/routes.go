@@ -2,11 +2,23 @@ package routes
 
 import (
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/NgTruong624/project_backend/internal/buildinfo"
+	"github.com/NgTruong624/project_backend/internal/captcha"
+	"github.com/NgTruong624/project_backend/internal/database"
 	"github.com/NgTruong624/project_backend/internal/handlers"
+	"github.com/NgTruong624/project_backend/internal/i18n"
 	"github.com/NgTruong624/project_backend/internal/middleware"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/notify"
+	"github.com/NgTruong624/project_backend/internal/partition"
+	"github.com/NgTruong624/project_backend/internal/retention"
+	"github.com/NgTruong624/project_backend/internal/shadow"
+	"github.com/NgTruong624/project_backend/internal/visitor"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,8 +28,8 @@ func adminMiddleware() gin.HandlerFunc {
 		role := c.GetString("role")
 		if role != "admin" {
 			c.JSON(http.StatusForbidden, gin.H{
-				"error":   "Permission denied",
-				"message": "Admin access required",
+				"error":   i18n.Localize(c, "Permission denied"),
+				"message": i18n.Localize(c, "Admin access required"),
 			})
 			c.Abort()
 			return
@@ -26,15 +38,104 @@ func adminMiddleware() gin.HandlerFunc {
 	}
 }
 
+// catalogMiddleware cho phép admin và biên tập viên danh mục (role editor)
+func catalogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		if role != "admin" && role != models.RoleEditor {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   i18n.Localize(c, "Permission denied"),
+				"message": i18n.Localize(c, "Catalog editor access required"),
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Dependencies là các handler và thành phần dùng khi đăng ký route. Handler dùng chung cho mọi
+// phiên bản API; phiên bản nào khác hành vi thì đăng ký handler riêng trong hàm register của nó.
+type Dependencies struct {
+	Auth          *handlers.AuthHandler
+	Product       *handlers.ProductHandler
+	Admin         *handlers.AdminHandler
+	JWT           *middleware.JWTMiddleware
+	Pruner        *retention.Pruner
+	GraphQL       *handlers.GraphQLHandler
+	Partitions    *partition.Manager
+	Webhook       *handlers.WebhookHandler
+	Job           *handlers.JobHandler
+	DBFailover    *database.Failover
+	Scheduler     *handlers.SchedulerHandler
+	ShadowReads   *shadow.Verifier
+	SlowQueries   *database.SlowQueryLog
+	Device        *handlers.DeviceHandler
+	Stream        *handlers.StreamHandler
+	Notifier      *notify.Dispatcher
+	Invoice       *handlers.InvoiceHandler
+	KPI           *handlers.KPIHandler
+	Cart          *handlers.CartHandler
+	PaymentMethod *handlers.PaymentMethodHandler
+	Account       *handlers.AccountHandler
+	Report        *handlers.ReportHandler
+	Order         *handlers.OrderHandler
+	Address       *handlers.AddressHandler
+	Tax           *handlers.TaxHandler
+	Return        *handlers.ReturnHandler
+	Promotion     *handlers.PromotionHandler
+	Health        *handlers.HealthHandler
+	Visitors      *visitor.Tracker
+	Question      *handlers.QuestionHandler
+	Review        *handlers.ReviewHandler
+	Attribute     *handlers.CategoryAttributeHandler
+	PriceChange   *handlers.PriceChangeHandler
+	SearchSynonym *handlers.SearchSynonymHandler
+	Category      *handlers.CategoryHandler
+	Sitemap       *handlers.SitemapHandler
+	Integration   *handlers.IntegrationHandler
+	ProductFeed   *handlers.ProductFeedHandler
+	ProductAlert  *handlers.ProductAlertHandler
+	Audit         *handlers.AuditHandler
+	RequestLogs   middleware.RequestLogStore
+	Upload        *handlers.UploadHandler
+	ProductMedia  *handlers.ProductMediaHandler
+	Newsletter    *handlers.NewsletterHandler
+	Support       *handlers.SupportTicketHandler
+
+	// ProductRevision xử lý quy trình duyệt thay đổi sản phẩm của biên tập viên
+	ProductRevision *handlers.ProductRevisionHandler
+
+	// UserInvitation nhập user từ CSV và xử lý lời mời đặt mật khẩu
+	UserInvitation *handlers.UserInvitationHandler
+
+	// Captcha chọn các form công khai phải kèm token captcha (CAPTCHA_ROUTES)
+	Captcha *captcha.Guard
+}
+
 // SetupRouter configures all the routes for the application
-func SetupRouter(
-	authHandler *handlers.AuthHandler,
-	productHandler *handlers.ProductHandler,
-	adminHandler *handlers.AdminHandler,
-	jwtMiddleware *middleware.JWTMiddleware,
-) *gin.Engine {
+func SetupRouter(deps *Dependencies) *gin.Engine {
 	router := gin.Default()
 
+	// Xác định ngôn ngữ response từ Accept-Language (hoặc ?lang=)
+	router.Use(i18n.Middleware())
+
+	// Cảnh báo admin khi lỗi 5xx tăng đột biến
+	alertThreshold := 50
+	if v, err := strconv.Atoi(os.Getenv("ALERT_5XX_THRESHOLD")); err == nil {
+		alertThreshold = v
+	}
+	router.Use(middleware.ServerErrorAlerts(deps.Notifier, alertThreshold))
+
+	// Ghi body request/response của một phần lưu lượng (REQUEST_LOG_SAMPLE_PERCENT) hoặc các route chỉ định
+	// (REQUEST_LOG_ROUTES) vào audit_logs để hỗ trợ điều tra; tắt khi không cấu hình
+	if cfg := middleware.RequestLogConfigFromEnv(); cfg.Enabled() {
+		router.Use(middleware.RequestLogger(cfg, deps.RequestLogs))
+	}
+
+	// Phiên ẩn danh của khách (cookie visitor_id); chạy trước rate limiter để khách có bucket riêng
+	router.Use(deps.Visitors.Middleware())
+
 	// Khởi tạo rate limiter
 	middleware.InitGlobalRateLimiter()
 	router.Use(middleware.RateLimitMiddleware())
@@ -53,61 +154,351 @@ func SetupRouter(
 		c.Next()
 	})
 
-	// API v1 group
-	api := router.Group("/api/v1")
+	// Kiểm tra sẵn sàng và số liệu Prometheus (pool kết nối database)
+	router.GET("/readyz", deps.Health.Ready)
+	router.GET("/metrics", deps.Health.Metrics)
+
+	// Sitemap cho công cụ tìm kiếm, dựng sẵn bởi tác vụ sitemap_generate
+	router.GET("/sitemap.xml", deps.Sitemap.GetSitemapIndex)
+	router.GET("/sitemaps/:file", deps.Sitemap.GetSitemap)
+
+	// Feed sản phẩm cho Google Merchant Center và Facebook catalog, dựng sẵn bởi tác vụ product_feed_generate;
+	// URL cố định kèm ?token= (PRODUCT_FEED_TOKENS)
+	feeds := router.Group("/feeds")
+	feeds.Use(middleware.QueryToken("token", strings.Split(os.Getenv("PRODUCT_FEED_TOKENS"), ",")))
 	{
-		// Status route
-		api.GET("/status", func(c *gin.Context) {
-			c.JSON(http.StatusOK, gin.H{"status": "ok"})
-		})
-
-		// Rate limit stats route (admin only)
-		api.GET("/rate-limit-stats", func(c *gin.Context) {
-			stats := middleware.GetGlobalRateLimiter().GetStats()
-			c.JSON(http.StatusOK, gin.H{
-				"rate_limit_stats": stats,
-			})
-		})
+		feeds.GET("/google.xml", deps.ProductFeed.GetGoogleFeed)
+		feeds.GET("/facebook.csv", deps.ProductFeed.GetFacebookFeed)
+	}
+
+	// GraphQL endpoint cho catalog (tùy chọn, bật bằng GRAPHQL_ENABLED=true)
+	if deps.GraphQL != nil {
+		router.GET("/api/graphql", deps.GraphQL.Query)
+		router.POST("/api/graphql", deps.GraphQL.Query)
+	}
+
+	// Các phiên bản API trong registry apiVersions, mỗi phiên bản dưới /api/<tên>
+	for _, version := range apiVersions {
+		api := router.Group("/api/" + version.Name)
+		api.Use(middleware.APIVersion(version.Name))
+		version.Register(api, deps)
+	}
+
+	return router
+}
+
+// registerRoutes đăng ký các route có ở mọi phiên bản API vào group api
+func registerRoutes(api *gin.RouterGroup, deps *Dependencies) {
+	// Status route
+	api.GET("/status", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	// Phiên bản, commit và thời điểm build của tiến trình đang phục vụ
+	api.GET("/version", func(c *gin.Context) {
+		c.JSON(http.StatusOK, buildinfo.Get())
+	})
+
+	// Endpoint vận hành (admin only)
+	registerOpsRoutes(api, deps)
+
+	// Auth routes (Public); đăng nhập chỉ cần captcha sau nhiều lần sai
+	api.POST("/auth/register", middleware.Captcha(deps.Captcha, captcha.RouteRegister), deps.Auth.Register)
+	api.POST("/auth/login", middleware.Captcha(deps.Captcha, captcha.RouteLogin), deps.Auth.Login)
+	// Hoàn tất đăng nhập đáng ngờ bằng token trong link đã gửi qua email (LOGIN_CONFIRM_SUSPICIOUS)
+	api.POST("/auth/login/confirm", deps.Auth.ConfirmLogin)
+
+	// Xác nhận đổi email bằng token trong link đã gửi qua email (không cần đăng nhập)
+	api.POST("/users/email-change/confirm", deps.Account.ConfirmEmailChange)
+	api.POST("/users/invitations/accept", deps.UserInvitation.AcceptInvitation)
+
+	// Đăng ký nhận bản tin (double opt-in); xác nhận và hủy đăng ký bằng token trong link gửi qua email
+	api.POST("/newsletter/subscribe", middleware.Captcha(deps.Captcha, captcha.RouteNewsletter), deps.Newsletter.Subscribe)
+	api.POST("/newsletter/confirm", deps.Newsletter.ConfirmSubscription)
+	api.POST("/newsletter/unsubscribe", deps.Newsletter.Unsubscribe)
 
-		// Auth routes (Public)
-		api.POST("/auth/register", authHandler.Register)
-		api.POST("/auth/login", authHandler.Login)
+	// Form liên hệ: khách chưa đăng nhập cần captcha, user đã đăng nhập gửi kèm access token để phiếu gắn với tài khoản
+	api.POST("/support/tickets", deps.JWT.OptionalAuthMiddleware(), middleware.Captcha(deps.Captcha, captcha.RouteSupportTicket), deps.Support.CreateTicket)
 
-		// Protected routes
-		authorized := api.Group("/")
-		authorized.Use(jwtMiddleware.AuthMiddleware())
+	// Giỏ hàng: dùng được cho cả khách (header X-Cart-Token) và user đã đăng nhập
+	cart := api.Group("/cart")
+	cart.Use(deps.JWT.OptionalAuthMiddleware())
+	{
+		cart.GET("", deps.Cart.GetCart)
+		cart.DELETE("", deps.Cart.ClearCart)
+		cart.POST("/revalidate", deps.Cart.Revalidate)
+		cart.POST("/items", deps.Cart.AddItem)
+		cart.PUT("/items/:product_id", deps.Cart.UpdateItem)
+		cart.DELETE("/items/:product_id", deps.Cart.RemoveItem)
+	}
+
+	// Protected routes
+	// Webhook kết quả thanh toán từ cổng thanh toán, xác thực bằng chữ ký của gateway
+	api.POST("/payments/webhook/:provider", deps.Order.PaymentWebhook)
+	api.GET("/payments/webhook/:provider", deps.Order.PaymentWebhook) // VNPay gọi IPN bằng GET
+	api.GET("/payments/return/:provider", deps.Order.PaymentReturn)
+
+	// Đồng bộ tồn kho và giá từ hệ thống kho bên ngoài, xác thực bằng API key (INTEGRATION_API_KEYS)
+	integrations := api.Group("/integrations")
+	integrations.Use(middleware.APIKey(strings.Split(os.Getenv("INTEGRATION_API_KEYS"), ",")))
+	{
+		integrations.PUT("/inventory", deps.Integration.SyncInventory)
+		integrations.PUT("/prices", deps.Integration.SyncPrices)
+	}
+
+	authorized := api.Group("/")
+	authorized.Use(deps.JWT.AuthMiddleware())
+	{
+		// User routes
+		authorized.PUT("/users/change-password", deps.Auth.ChangePassword)
+
+		// Đăng xuất phiên hiện tại hoặc mọi phiên của user
+		authorized.POST("/auth/logout", deps.Auth.Logout)
+		authorized.POST("/auth/logout-all", deps.Auth.LogoutAll)
+
+		// Lịch sử đăng nhập (thiết bị, IP, quốc gia) của user hiện tại
+		authorized.GET("/users/me/logins", deps.Auth.LoginHistory)
+
+		// Xóa tài khoản và xuất dữ liệu cá nhân (GDPR)
+		authorized.DELETE("/users/me", deps.Account.DeleteAccount)
+		authorized.GET("/users/me/export", deps.Account.ExportData)
+		authorized.POST("/users/me/email-change", deps.Account.RequestEmailChange)
+
+		// Thiết bị nhận push notification
+		authorized.GET("/users/me/devices", deps.Device.ListDevices)
+		authorized.POST("/users/me/devices", deps.Device.RegisterDevice)
+		authorized.DELETE("/users/me/devices/:id", deps.Device.DeleteDevice)
+		authorized.POST("/users/me/devices/test", deps.Device.SendTestNotification)
+
+		// Kênh nhận thông báo (email, push, webhook) theo từng loại sự kiện
+		authorized.GET("/users/me/notifications/preferences", deps.Device.GetNotificationPreferences)
+		authorized.PUT("/users/me/notifications/preferences", deps.Device.UpdateNotificationPreferences)
+
+		// Theo dõi sản phẩm: báo khi có hàng trở lại hoặc giảm giá
+		authorized.POST("/products/:id/alerts", deps.ProductAlert.CreateProductAlert)
+		authorized.GET("/users/me/product-alerts", deps.ProductAlert.ListProductAlerts)
+		authorized.DELETE("/users/me/product-alerts/:id", deps.ProductAlert.DeleteProductAlert)
+
+		// Phương thức thanh toán đã lưu (token hóa tại nhà cung cấp thanh toán)
+		authorized.GET("/users/me/payment-methods", deps.PaymentMethod.ListPaymentMethods)
+		authorized.POST("/users/me/payment-methods", deps.PaymentMethod.AddPaymentMethod)
+		authorized.PUT("/users/me/payment-methods/:id/default", deps.PaymentMethod.SetDefaultPaymentMethod)
+		authorized.DELETE("/users/me/payment-methods/:id", deps.PaymentMethod.DeletePaymentMethod)
+
+		// Sổ địa chỉ giao hàng
+		authorized.GET("/users/me/addresses", deps.Address.ListAddresses)
+		authorized.POST("/users/me/addresses", deps.Address.CreateAddress)
+		authorized.GET("/users/me/addresses/:id", deps.Address.GetAddress)
+		authorized.PUT("/users/me/addresses/:id", deps.Address.UpdateAddress)
+		authorized.PUT("/users/me/addresses/:id/default", deps.Address.SetDefaultAddress)
+		authorized.DELETE("/users/me/addresses/:id", deps.Address.DeleteAddress)
+
+		// Đơn hàng
+		authorized.POST("/checkout/shipping-quote", deps.Order.QuoteShipping)
+		authorized.GET("/users/me/orders", deps.Order.ListMyOrders)
+		authorized.POST("/orders", deps.Order.Checkout)
+		authorized.GET("/orders/:id", deps.Order.GetOrder)
+		authorized.GET("/orders/:id/history", deps.Order.GetOrderHistory)
+		authorized.POST("/orders/:id/cancel", deps.Order.CancelOrder)
+		authorized.POST("/orders/:id/pay", deps.Order.PayOrder)
+		authorized.POST("/orders/:id/reorder", deps.Order.Reorder)
+
+		// Trả hàng và hoàn tiền
+		authorized.POST("/orders/:id/returns", deps.Return.CreateReturn)
+		authorized.GET("/orders/:id/returns", deps.Return.ListOrderReturns)
+		authorized.GET("/returns/:id", deps.Return.GetReturn)
+		authorized.POST("/returns/:id/photos", deps.Return.UploadReturnPhoto)
+		authorized.POST("/returns/:id/cancel", deps.Return.CancelReturn)
+
+		// Phiếu hỗ trợ của user
+		authorized.GET("/users/me/support-tickets", deps.Support.ListMyTickets)
+		authorized.GET("/support/tickets/:id", deps.Support.GetMyTicket)
+		authorized.POST("/support/tickets/:id/messages", deps.Support.ReplyMyTicket)
+		authorized.POST("/support/tickets/:id/close", deps.Support.CloseMyTicket)
+
+		// Hỏi đáp về sản phẩm: câu hỏi chờ duyệt; chỉ admin và người đã mua được trả lời
+		authorized.POST("/products/:id/questions", deps.Question.AskQuestion)
+		authorized.POST("/products/:id/questions/:question_id/answers", deps.Question.AnswerQuestion)
+		authorized.POST("/products/:id/reviews", deps.Review.CreateReview)
+		authorized.PUT("/products/:id/reviews/:review_id/vote", deps.Review.VoteReview)
+		authorized.DELETE("/products/:id/reviews/:review_id/vote", deps.Review.UnvoteReview)
+		authorized.POST("/products/:id/reviews/:review_id/report", deps.Review.ReportReview)
+
+		// Thay đổi sản phẩm chờ duyệt: biên tập viên (và admin) gửi revision, admin khác duyệt hoặc từ chối
+		catalog := authorized.Group("")
+		catalog.Use(catalogMiddleware())
 		{
-			// User routes
-			authorized.PUT("/users/change-password", authHandler.ChangePassword)
-
-			// Product routes (Admin only)
-			adminProducts := authorized.Group("/products")
-			adminProducts.Use(adminMiddleware())
-			{
-				adminProducts.POST("", productHandler.CreateProduct)
-				adminProducts.PUT("/:id", productHandler.UpdateProduct)
-				adminProducts.DELETE("/:id", productHandler.DeleteProduct)
-
-				// Upload routes (Admin only)
-				uploadGroup := adminProducts.Group("/:id")
-				uploadGroup.POST("/upload", productHandler.UploadProductImage)
-			}
-
-			// Admin routes
-			admin := authorized.Group("/admin")
-			admin.Use(adminMiddleware())
-			{
-				admin.GET("/users", adminHandler.GetUsersList)
-			}
+			catalog.POST("/products/:id/revisions", deps.ProductRevision.SubmitProductRevision)
+			catalog.GET("/product-revisions", deps.ProductRevision.ListProductRevisions)
+			catalog.GET("/product-revisions/:id", deps.ProductRevision.GetProductRevision)
+			catalog.POST("/product-revisions/:id/cancel", deps.ProductRevision.CancelProductRevision)
 		}
 
-		// Public product routes
-		publicProductRoutes := api.Group("/products")
+		// Product routes (Admin only)
+		adminProducts := authorized.Group("/products")
+		adminProducts.Use(adminMiddleware())
 		{
-			publicProductRoutes.GET("", productHandler.GetProducts)
-			publicProductRoutes.GET("/:id", productHandler.GetProduct)
+			adminProducts.POST("", deps.Product.CreateProduct)
+			adminProducts.PUT("/:id", deps.Product.UpdateProduct)
+			adminProducts.PATCH("/:id", deps.Product.UpdateProduct)
+			adminProducts.DELETE("/:id", deps.Product.DeleteProduct)
+
+			// Upload routes (Admin only)
+			uploadGroup := adminProducts.Group("/:id")
+			uploadGroup.POST("/upload", deps.Product.UploadProductImage)
+			uploadGroup.POST("/media/videos", deps.ProductMedia.UploadProductVideo)
+			uploadGroup.POST("/media/external", deps.ProductMedia.AddExternalVideo)
+			uploadGroup.DELETE("/media/:media_id", deps.ProductMedia.DeleteProductMedia)
+
+			// Kiểm duyệt hỏi đáp
+			adminProducts.PUT("/:id/questions/:question_id/status", deps.Question.ModerateQuestion)
+			adminProducts.PUT("/:id/questions/:question_id/answers/:answer_id/status", deps.Question.ModerateAnswer)
+			adminProducts.PUT("/:id/reviews/:review_id/status", deps.Review.ModerateReview)
+			adminProducts.GET("/:id/price-changes", deps.PriceChange.ListPriceChanges)
+			adminProducts.POST("/:id/price-changes", deps.PriceChange.CreatePriceChange)
+			adminProducts.DELETE("/:id/price-changes/:change_id", deps.PriceChange.CancelPriceChange)
+		}
+
+		// Admin routes
+		admin := authorized.Group("/admin")
+		admin.Use(adminMiddleware())
+		{
+			admin.GET("/users", middleware.Negotiate(), deps.Admin.GetUsersList)
+			admin.GET("/users/:id", deps.Admin.GetUser)
+			admin.POST("/users/:id/restore", deps.Admin.RestoreUser)
+			admin.GET("/users/:id/activity", deps.Admin.GetUserActivity)
+			admin.POST("/users/import", deps.UserInvitation.ImportUsers)
+			admin.POST("/users/:id/invitation", deps.UserInvitation.ResendInvitation)
+
+			// Phiếu hỗ trợ: trả lời khách và đổi trạng thái
+			admin.GET("/support/tickets", deps.Support.ListTickets)
+			admin.GET("/support/tickets/:id", deps.Support.GetTicket)
+			admin.POST("/support/tickets/:id/messages", deps.Support.ReplyTicket)
+			admin.PUT("/support/tickets/:id/status", deps.Support.UpdateTicketStatus)
+
+			// Danh sách địa chỉ đã xác nhận nhận bản tin
+			admin.GET("/newsletter/subscribers/export", deps.Newsletter.ExportSubscribers)
+
+			// Audit log, gồm bản ghi request/response của request log
+			admin.GET("/audit-logs", deps.Audit.ListAuditLogs)
+
+			// Webhook subscriptions
+			admin.GET("/webhooks", deps.Webhook.ListWebhooks)
+			admin.POST("/webhooks", deps.Webhook.CreateWebhook)
+			admin.DELETE("/webhooks/:id", deps.Webhook.DeleteWebhook)
+			admin.GET("/webhooks/:id/deliveries", deps.Webhook.GetDeliveries)
+			admin.POST("/webhooks/deliveries/:id/retry", deps.Webhook.RetryDelivery)
+
+			// Hàng đợi job nền
+			admin.GET("/jobs", deps.Job.ListJobs)
+			admin.GET("/jobs/stats", deps.Job.GetJobStats)
+			admin.POST("/jobs/:id/retry", deps.Job.RetryJob)
+
+			// Tác vụ định kỳ
+			admin.GET("/scheduler", deps.Scheduler.ListTasks)
+			admin.POST("/scheduler/:name/run", deps.Scheduler.RunTask)
+
+			// Chạy thử dọn file upload mồ côi (tác vụ stale_upload_cleanup)
+			admin.GET("/uploads/gc", deps.Upload.PreviewUploadGC)
+
+			// KPI theo ngày cho dashboard (tổng hợp sẵn bởi tác vụ kpi_rollup)
+			admin.GET("/kpis", deps.KPI.GetKPIs)
+			admin.POST("/kpis/rebuild", deps.KPI.RebuildKPIs)
+
+			// Báo cáo doanh số theo kỳ, danh mục và sản phẩm (JSON hoặc CSV)
+			admin.GET("/reports/sales", deps.Report.GetSalesReport)
+
+			// Quản lý đơn hàng: danh sách, chi tiết, thanh toán và chuyển trạng thái
+			admin.GET("/orders", deps.Order.ListOrders)
+			admin.POST("/orders/bulk-status", deps.Order.BulkUpdateOrderStatus)
+			admin.GET("/orders/:id", deps.Order.AdminGetOrder)
+			admin.GET("/orders/:id/payments", deps.Order.ListOrderPayments)
+			admin.GET("/orders/:id/history", deps.Order.AdminGetOrderHistory)
+			admin.POST("/orders/:id/mark-paid", deps.Order.MarkOrderPaid)
+			admin.POST("/orders/:id/fulfill", deps.Order.FulfillOrder)
+			admin.POST("/orders/:id/deliver", deps.Order.DeliverOrder)
+			admin.POST("/orders/:id/cancel", deps.Order.AdminCancelOrder)
+			admin.POST("/orders/:id/refund", deps.Order.RefundOrder)
+
+			// Duyệt yêu cầu trả hàng
+			admin.GET("/returns", deps.Return.ListReturns)
+			admin.GET("/returns/:id", deps.Return.AdminGetReturn)
+			admin.POST("/returns/:id/approve", deps.Return.ApproveReturn)
+			admin.POST("/returns/:id/reject", deps.Return.RejectReturn)
+
+			// Hàng đợi kiểm duyệt hỏi đáp về sản phẩm
+			admin.GET("/questions", deps.Question.ListModerationQuestions)
+			admin.GET("/answers", deps.Question.ListModerationAnswers)
+			admin.GET("/products", middleware.SparseFields(), middleware.Negotiate(), deps.Product.GetProducts)
+			admin.POST("/products/:id/duplicate", deps.Product.DuplicateProduct)
+			admin.GET("/products/:id/revisions", deps.Product.GetProductHistory)
+			admin.POST("/products/:id/revisions/:rev/restore", deps.Product.RestoreProductRevision)
+			admin.POST("/product-revisions/:id/approve", deps.ProductRevision.ApproveProductRevision)
+			admin.POST("/product-revisions/:id/reject", deps.ProductRevision.RejectProductRevision)
+			admin.GET("/reviews", deps.Review.ListModerationReviews)
+			admin.GET("/reviews/reported", deps.Review.ListReportedReviews)
+
+			// Hóa đơn và xuất hóa đơn điện tử
+			admin.GET("/invoices", deps.Invoice.ListInvoices)
+			admin.POST("/invoices", deps.Invoice.CreateInvoice)
+			admin.GET("/invoices/:id", deps.Invoice.GetInvoice)
+			admin.DELETE("/invoices/:id", deps.Invoice.DeleteInvoice)
+			admin.POST("/invoices/:id/issue", deps.Invoice.IssueInvoice)
+			admin.POST("/invoices/:id/cancel", deps.Invoice.CancelInvoice)
+			admin.GET("/invoices/:id/export", deps.Invoice.ExportInvoice)
+
+			// Quy tắc thuế suất theo danh mục/tỉnh
+			admin.GET("/tax-rates", deps.Tax.ListTaxRates)
+			admin.POST("/tax-rates", deps.Tax.CreateTaxRate)
+			admin.PUT("/tax-rates/:id", deps.Tax.UpdateTaxRate)
+			admin.DELETE("/tax-rates/:id", deps.Tax.DeleteTaxRate)
+
+			// Cây danh mục
+			admin.GET("/categories", deps.Category.ListCategories)
+			admin.POST("/categories", deps.Category.CreateCategory)
+			admin.PUT("/categories/:id", deps.Category.UpdateCategory)
+			admin.DELETE("/categories/:id", deps.Category.DeleteCategory)
+
+			// Thuộc tính (thông số kỹ thuật) khai báo theo danh mục
+			admin.GET("/category-attributes", deps.Attribute.AdminListCategoryAttributes)
+			admin.POST("/category-attributes", deps.Attribute.CreateCategoryAttribute)
+			admin.PUT("/category-attributes/:id", deps.Attribute.UpdateCategoryAttribute)
+			admin.DELETE("/category-attributes/:id", deps.Attribute.DeleteCategoryAttribute)
+
+			// Từ điển đồng nghĩa cho tìm kiếm sản phẩm
+			admin.GET("/search-synonyms", deps.SearchSynonym.ListSearchSynonyms)
+			admin.POST("/search-synonyms", deps.SearchSynonym.CreateSearchSynonym)
+			admin.PUT("/search-synonyms/:id", deps.SearchSynonym.UpdateSearchSynonym)
+			admin.DELETE("/search-synonyms/:id", deps.SearchSynonym.DeleteSearchSynonym)
+
+			// Khuyến mãi tự động
+			admin.GET("/promotions", deps.Promotion.ListPromotions)
+			admin.POST("/promotions", deps.Promotion.CreatePromotion)
+			admin.GET("/promotions/:id", deps.Promotion.GetPromotion)
+			admin.PUT("/promotions/:id", deps.Promotion.UpdatePromotion)
+			admin.DELETE("/promotions/:id", deps.Promotion.DeletePromotion)
 		}
 	}
 
-	return router
+	// Public product routes
+	// Danh sách và chi tiết sản phẩm hỗ trợ ?fields=id,name,price để client lấy payload gọn
+	publicProductRoutes := api.Group("/products")
+	{
+		publicProductRoutes.GET("", middleware.SparseFields(), middleware.Negotiate(), deps.Product.GetProducts)
+		publicProductRoutes.GET("/stream", deps.Stream.ProductStream)
+		publicProductRoutes.GET("/suggest", deps.Product.SuggestProducts)
+		publicProductRoutes.POST("/stock-check", deps.Product.CheckStock)
+		publicProductRoutes.GET("/recently-viewed", middleware.SparseFields(), deps.Product.GetRecentlyViewed)
+		// Token không bắt buộc: admin xem được sản phẩm chưa đăng
+		publicProductRoutes.GET("/:id", deps.JWT.OptionalAuthMiddleware(), middleware.SparseFields(), middleware.Negotiate(), deps.Product.GetProduct)
+		publicProductRoutes.GET("/:id/media", deps.JWT.OptionalAuthMiddleware(), deps.ProductMedia.ListProductMedia)
+		publicProductRoutes.GET("/:id/questions", deps.Question.ListQuestions)
+		publicProductRoutes.GET("/:id/reviews", deps.Review.ListReviews)
+	}
+
+	// Khuyến mãi đang hiệu lực
+	api.GET("/promotions", deps.Promotion.ListActivePromotions)
+
+	// Cây danh mục cho menu điều hướng và thuộc tính lọc được của danh mục
+	api.GET("/categories/tree", deps.Category.GetCategoryTree)
+	api.GET("/categories/:category/attributes", deps.Attribute.ListCategoryAttributes)
 }
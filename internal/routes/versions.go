@@ -0,0 +1,19 @@
+package routes
+
+import "github.com/gin-gonic/gin"
+
+// apiVersion là một phiên bản API được phục vụ dưới /api/<Name>
+type apiVersion struct {
+	Name string
+	// Register đăng ký route của phiên bản vào group /api/<Name>
+	Register func(api *gin.RouterGroup, deps *Dependencies)
+}
+
+// apiVersions là registry các phiên bản API đang phục vụ. Thay đổi phá vỡ định dạng response
+// chỉ áp dụng cho phiên bản mới (utils.APIVersion), các phiên bản cũ giữ nguyên.
+//   - v1: định dạng ban đầu
+//   - v2: lỗi dạng {"status", "error": {"code", "message", "details"}}; các route khác giống v1
+var apiVersions = []apiVersion{
+	{Name: "v1", Register: registerRoutes},
+	{Name: "v2", Register: registerRoutes},
+}
@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// TaskFunc thực hiện một lần chạy tác vụ và trả về mô tả ngắn kết quả
+type TaskFunc func(ctx context.Context) (string, error)
+
+// Task là một tác vụ định kỳ đã đăng ký
+type Task struct {
+	Name     string
+	Spec     string
+	Timeout  time.Duration // thời gian giữ khóa tối đa cho một lần chạy
+	schedule *Schedule
+	run      TaskFunc
+}
+
+// Scheduler chạy các tác vụ định kỳ; khóa theo từng tác vụ trong bảng scheduled_tasks
+// đảm bảo mỗi lần chạy chỉ được thực hiện bởi một replica
+type Scheduler struct {
+	repo     *repository.ScheduledTaskRepository
+	tasks    []*Task
+	interval time.Duration
+	owner    string
+}
+
+// NewScheduler tạo scheduler mới, kiểm tra tác vụ tới hạn sau mỗi interval
+func NewScheduler(db *gorm.DB, interval time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	host, _ := os.Hostname()
+	return &Scheduler{
+		repo:     repository.NewScheduledTaskRepository(db),
+		interval: interval,
+		owner:    fmt.Sprintf("%s-%d", host, os.Getpid()),
+	}
+}
+
+// Register đăng ký tác vụ với biểu thức lịch cron
+func (s *Scheduler) Register(name, spec string, timeout time.Duration, run TaskFunc) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return fmt.Errorf("task %s: %w", name, err)
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Minute
+	}
+	s.tasks = append(s.tasks, &Task{Name: name, Spec: spec, Timeout: timeout, schedule: schedule, run: run})
+	return nil
+}
+
+// Start tạo dòng cho các tác vụ và chạy vòng kiểm tra cho tới khi ctx bị hủy
+func (s *Scheduler) Start(ctx context.Context) {
+	now := time.Now()
+	for _, task := range s.tasks {
+		if err := s.repo.Ensure(ctx, task.Name, task.Spec, task.schedule.Next(now)); err != nil {
+			log.Printf("Scheduler: failed to register task %s: %v", task.Name, err)
+		}
+	}
+
+	ticker := time.NewTicker(s.interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.RunDue(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// RunDue chạy các tác vụ đã tới hạn mà replica này chiếm được khóa
+func (s *Scheduler) RunDue(ctx context.Context) {
+	for _, task := range s.tasks {
+		acquired, err := s.repo.Acquire(ctx, task.Name, s.owner, time.Now(), task.Timeout)
+		if err != nil {
+			log.Printf("Scheduler: failed to lock task %s: %v", task.Name, err)
+			continue
+		}
+		if acquired {
+			go s.execute(ctx, task)
+		}
+	}
+}
+
+func (s *Scheduler) execute(ctx context.Context, task *Task) {
+	startedAt := time.Now()
+	runCtx, cancel := context.WithTimeout(ctx, task.Timeout)
+	defer cancel()
+
+	result, err := func() (result string, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return task.run(runCtx)
+	}()
+	if err != nil {
+		log.Printf("Scheduler: task %s failed: %v", task.Name, err)
+	}
+
+	if err := s.repo.Finish(ctx, task.Name, s.owner, startedAt, task.schedule.Next(time.Now()), result, err); err != nil {
+		log.Printf("Scheduler: failed to record run of task %s: %v", task.Name, err)
+	}
+}
+
+// Trigger yêu cầu chạy tác vụ ngay ở lần kiểm tra kế tiếp
+func (s *Scheduler) Trigger(ctx context.Context, name string) (bool, error) {
+	return s.repo.Trigger(ctx, name)
+}
+
+// List trả về trạng thái lần chạy gần nhất của tất cả tác vụ (trên mọi replica)
+func (s *Scheduler) List(ctx context.Context) ([]models.ScheduledTask, error) {
+	return s.repo.List(ctx)
+}
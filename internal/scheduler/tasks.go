@@ -0,0 +1,167 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/services"
+	"github.com/NgTruong624/project_backend/internal/visitor"
+	"github.com/NgTruong624/project_backend/internal/webhook"
+	"gorm.io/gorm"
+)
+
+// Tên các tác vụ định kỳ có sẵn
+const (
+	TaskLowStockScan       = "low_stock_scan"
+	TaskStaleUploadCleanup = "stale_upload_cleanup"
+	TaskKPIRollup          = "kpi_rollup"
+	TaskCartSweep          = "cart_sweep"
+	TaskUserAnonymize      = "user_anonymize"
+	TaskVisitorSweep       = "visitor_session_sweep"
+	TaskProductPublish     = "product_publish"
+	TaskPriceChangeApply   = "price_change_apply"
+	TaskSitemapGenerate    = "sitemap_generate"
+	TaskProductFeed        = "product_feed_generate"
+	TaskProductAlertSweep  = "product_alert_sweep"
+	TaskFieldReencrypt     = "field_reencrypt"
+)
+
+// KPIBackfillDays là số ngày được tổng hợp ở lần chạy đầu tiên khi bảng daily_kpis còn trống
+const KPIBackfillDays = 90
+
+// RegisterDefaultTasks đăng ký các tác vụ định kỳ có sẵn
+func RegisterDefaultTasks(s *Scheduler, db *gorm.DB, uploadDir string, accounts *services.AccountService, visitors *visitor.Tracker, products *services.ProductService,
+	prices *services.PriceChangeService) error {
+	if err := s.Register(TaskLowStockScan, "0 * * * *", time.Minute, func(ctx context.Context) (string, error) {
+		return lowStockScan(ctx, db)
+	}); err != nil {
+		return err
+	}
+	if err := s.Register(TaskStaleUploadCleanup, "30 3 * * *", 10*time.Minute, func(ctx context.Context) (string, error) {
+		return cleanupStaleUploads(ctx, db, uploadDir)
+	}); err != nil {
+		return err
+	}
+	if err := s.Register(TaskKPIRollup, "*/15 * * * *", 5*time.Minute, func(ctx context.Context) (string, error) {
+		return rollupKPIs(ctx, db)
+	}); err != nil {
+		return err
+	}
+	if err := s.Register(TaskCartSweep, "45 * * * *", 5*time.Minute, func(ctx context.Context) (string, error) {
+		return sweepCarts(ctx, db)
+	}); err != nil {
+		return err
+	}
+	if err := s.Register(TaskUserAnonymize, "15 4 * * *", 30*time.Minute, func(ctx context.Context) (string, error) {
+		count, err := accounts.AnonymizeExpired(ctx)
+		return fmt.Sprintf("anonymized %d deleted users", count), err
+	}); err != nil {
+		return err
+	}
+	if err := s.Register(TaskVisitorSweep, "50 * * * *", 5*time.Minute, func(ctx context.Context) (string, error) {
+		deleted, err := visitors.Sweep(ctx)
+		return fmt.Sprintf("deleted %d visitor sessions idle for more than %s", deleted, visitors.TTL()), err
+	}); err != nil {
+		return err
+	}
+	if err := s.Register(TaskProductPublish, "* * * * *", time.Minute, func(ctx context.Context) (string, error) {
+		published, err := products.PublishScheduled(ctx, time.Now())
+		return fmt.Sprintf("published %d scheduled products", published), err
+	}); err != nil {
+		return err
+	}
+	if err := s.Register(TaskPriceChangeApply, "* * * * *", time.Minute, func(ctx context.Context) (string, error) {
+		applied, ended, err := prices.ApplyDue(ctx, time.Now())
+		return fmt.Sprintf("applied %d price changes, ended %d", applied, ended), err
+	}); err != nil {
+		return err
+	}
+	if err := s.Register(TaskSitemapGenerate, "20 * * * *", 10*time.Minute, func(ctx context.Context) (string, error) {
+		urls, err := services.NewSitemapService(db).Generate(ctx, time.Now())
+		return fmt.Sprintf("generated sitemaps with %d URLs", urls), err
+	}); err != nil {
+		return err
+	}
+	if err := s.Register(TaskProductFeed, "40 * * * *", 10*time.Minute, func(ctx context.Context) (string, error) {
+		items, err := services.NewProductFeedService(db).Generate(ctx, time.Now())
+		return fmt.Sprintf("generated product feeds with %d items", items), err
+	}); err != nil {
+		return err
+	}
+	if err := s.Register(TaskProductAlertSweep, "10 4 * * *", 10*time.Minute, func(ctx context.Context) (string, error) {
+		deleted, err := services.NewProductAlertService(db).SweepExpired(ctx, time.Now())
+		return fmt.Sprintf("deleted %d expired product alerts", deleted), err
+	}); err != nil {
+		return err
+	}
+	// Mã hóa lại dữ liệu cũ sau khi bật mã hóa hoặc đổi khóa chính; có thể chạy ngay qua API scheduler
+	return s.Register(TaskFieldReencrypt, "50 4 * * *", 30*time.Minute, func(ctx context.Context) (string, error) {
+		rows, err := repository.ReencryptFields(ctx, db)
+		return fmt.Sprintf("re-encrypted %d rows", rows), err
+	})
+}
+
+// sweepCarts xóa các giỏ không có hoạt động lâu hơn thời gian hết hạn (CART_EXPIRY)
+func sweepCarts(ctx context.Context, db *gorm.DB) (string, error) {
+	expiry := services.CartExpiry()
+	deleted, err := repository.NewCartRepository(db).DeleteIdle(ctx, time.Now().Add(-expiry))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("deleted %d carts idle for more than %s", deleted, expiry), nil
+}
+
+// rollupKPIs tính lại KPI từ ngày tổng hợp gần nhất (trừ một ngày để bắt các thay đổi muộn
+// như hóa đơn bị hủy) tới hôm nay; lần đầu tổng hợp KPIBackfillDays ngày gần nhất
+func rollupKPIs(ctx context.Context, db *gorm.DB) (string, error) {
+	repo := repository.NewKPIRepository(db)
+	today := time.Now().In(repository.KPILocation)
+
+	from := today.AddDate(0, 0, -KPIBackfillDays)
+	last, ok, err := repo.LastDay(ctx)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		from = last.AddDate(0, 0, -1)
+	}
+
+	days, err := repo.Rollup(ctx, from, today)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("rolled up %d days from %s to %s", days, from.Format("2006-01-02"), today.Format("2006-01-02")), nil
+}
+
+// lowStockScan liệt kê các sản phẩm có tồn kho bằng hoặc dưới ngưỡng cảnh báo
+func lowStockScan(ctx context.Context, db *gorm.DB) (string, error) {
+	var products []models.Product
+	if err := db.WithContext(ctx).Select("id", "name", "stock").
+		Where("stock <= ?", webhook.LowStockThreshold).Order("stock ASC").Find(&products).Error; err != nil {
+		return "", err
+	}
+	if len(products) == 0 {
+		return "no products at or below low-stock threshold", nil
+	}
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, fmt.Sprintf("%d(%d)", p.ID, p.Stock))
+	}
+	return fmt.Sprintf("%d products at or below threshold %d: %s",
+		len(products), webhook.LowStockThreshold, strings.Join(ids, ", ")), nil
+}
+
+// cleanupStaleUploads xóa các file upload (ảnh sản phẩm, thumbnail, ảnh trả hàng) đã không còn được
+// tham chiếu quá thời gian ân hạn UPLOAD_GC_GRACE_PERIOD
+func cleanupStaleUploads(ctx context.Context, db *gorm.DB, uploadDir string) (string, error) {
+	report, err := services.NewUploadGCService(db, uploadDir).Run(ctx, false, time.Now())
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("removed %d unreferenced files (%d bytes), %d orphaned files pending", report.Deleted, report.FreedBytes,
+		report.Orphaned-report.Deleted), nil
+}
@@ -0,0 +1,60 @@
+package seed
+
+import (
+	"github.com/NgTruong624/project_backend/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fixtures ánh xạ tên bộ dữ liệu tới hàm dựng nó; mỗi lần gọi trả về bản sao mới
+var fixtures = map[string]func() Fixture{
+	Dev:  devFixture,
+	Demo: demoFixture,
+	Test: testFixture,
+}
+
+// baseUsers là các tài khoản có trong mọi bộ dữ liệu
+func baseUsers() []User {
+	return []User{
+		{Username: "admin", Email: "admin@example.com", Password: "admin123", FullName: "Admin User", Role: "admin"},
+		{Username: "editor", Email: "editor@example.com", Password: "admin123", FullName: "Catalog Editor", Role: "editor"},
+		{Username: "user1", Email: "user1@example.com", Password: "admin123", FullName: "Normal User", Role: "user"},
+	}
+}
+
+// baseProducts là các sản phẩm có trong mọi bộ dữ liệu
+func baseProducts() []models.Product {
+	return []models.Product{
+		{Name: "Laptop Gaming", Description: "Laptop gaming cấu hình cao", Price: 25000000, Stock: 10, Category: "Electronics", WeightGrams: 2500},
+		{Name: "Smartphone", Description: "Điện thoại thông minh mới nhất", Price: 15000000, Stock: 20, Category: "Electronics", WeightGrams: 200},
+		{Name: "Headphone", Description: "Tai nghe không dây", Price: 2000000, Stock: 50, Category: "Accessories", WeightGrams: 250},
+	}
+}
+
+func devFixture() Fixture {
+	return Fixture{Users: baseUsers(), Products: baseProducts()}
+}
+
+func testFixture() Fixture {
+	return Fixture{Users: baseUsers(), Products: baseProducts(), hashCost: bcrypt.MinCost}
+}
+
+func demoFixture() Fixture {
+	users := append(baseUsers(),
+		User{Username: "nguyenvana", Email: "nguyenvana@example.com", Password: "demo123", FullName: "Nguyễn Văn A", Role: "user"},
+		User{Username: "tranthib", Email: "tranthib@example.com", Password: "demo123", FullName: "Trần Thị B", Role: "user"},
+		User{Username: "levanc", Email: "levanc@example.com", Password: "demo123", FullName: "Lê Văn C", Role: "user"},
+	)
+	products := append(baseProducts(),
+		models.Product{Name: "Tablet 11 inch", Description: "Máy tính bảng màn hình 11 inch", Price: 9000000, Stock: 15, Category: "Electronics", WeightGrams: 500},
+		models.Product{Name: "Smartwatch", Description: "Đồng hồ thông minh theo dõi sức khỏe", Price: 4500000, Stock: 30, Category: "Electronics", WeightGrams: 80},
+		models.Product{Name: "Bluetooth Speaker", Description: "Loa bluetooth chống nước", Price: 1200000, Stock: 40, Category: "Accessories", WeightGrams: 600},
+		models.Product{Name: "USB-C Charger 65W", Description: "Sạc nhanh USB-C 65W", Price: 650000, Stock: 100, Category: "Accessories", WeightGrams: 150},
+		models.Product{Name: "Mechanical Keyboard", Description: "Bàn phím cơ switch đỏ", Price: 1800000, Stock: 25, Category: "Accessories", WeightGrams: 900},
+		models.Product{Name: "Wireless Mouse", Description: "Chuột không dây pin sạc", Price: 450000, Stock: 60, Category: "Accessories", WeightGrams: 100},
+		models.Product{Name: "Cotton T-Shirt", Description: "Áo thun cotton unisex", Price: 199000, Stock: 200, Category: "Fashion", WeightGrams: 200},
+		models.Product{Name: "Running Shoes", Description: "Giày chạy bộ siêu nhẹ", Price: 1500000, Stock: 35, Category: "Fashion", WeightGrams: 700},
+		models.Product{Name: "Coffee Maker", Description: "Máy pha cà phê tự động", Price: 3200000, Stock: 12, Category: "Home", WeightGrams: 3500},
+		models.Product{Name: "Desk Lamp", Description: "Đèn bàn LED chống cận", Price: 550000, Stock: 45, Category: "Home", WeightGrams: 800},
+	)
+	return Fixture{Users: users, Products: products}
+}
@@ -0,0 +1,115 @@
+// Package seed chứa dữ liệu mẫu (fixtures) theo môi trường và cách ghi chúng vào database,
+// dùng chung cho cmd/api (RUN_SEEDER), cmd/seeder và phần chuẩn bị dữ liệu khi kiểm thử.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Tên các bộ dữ liệu mẫu
+const (
+	Dev  = "dev"  // tài khoản admin/user và vài sản phẩm để phát triển
+	Demo = "demo" // danh mục sản phẩm đầy đủ hơn và nhiều khách hàng để trình diễn
+	Test = "test" // dữ liệu tối thiểu, cố định cho kiểm thử; mật khẩu băm với cost thấp nhất
+)
+
+// User là tài khoản mẫu; Password là mật khẩu gốc, được băm khi ghi
+type User struct {
+	Username string
+	Email    string
+	Password string
+	FullName string
+	Role     string
+}
+
+// Fixture là một bộ dữ liệu mẫu
+type Fixture struct {
+	Users    []User
+	Products []models.Product
+	// hashCost là cost bcrypt khi băm mật khẩu của Users
+	hashCost int
+}
+
+// Get trả về bộ dữ liệu mẫu theo tên
+func Get(name string) (Fixture, error) {
+	fixture, ok := fixtures[name]
+	if !ok {
+		return Fixture{}, fmt.Errorf("unknown seed fixture %q (available: %v)", name, Names())
+	}
+	return fixture(), nil
+}
+
+// Names trả về tên các bộ dữ liệu mẫu
+func Names() []string {
+	names := make([]string, 0, len(fixtures))
+	for name := range fixtures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run ghi bộ dữ liệu mẫu name vào db
+func Run(ctx context.Context, db *gorm.DB, name string) error {
+	fixture, err := Get(name)
+	if err != nil {
+		return err
+	}
+	return fixture.Apply(ctx, db)
+}
+
+// Apply ghi bộ dữ liệu vào db trong một transaction và có thể chạy lại nhiều lần:
+// user được nhận diện theo email, sản phẩm theo tên. Bản ghi đã có được cập nhật theo fixture,
+// trừ mật khẩu của user và tồn kho của sản phẩm vốn thay đổi khi dùng thử.
+func (f Fixture) Apply(ctx context.Context, db *gorm.DB) error {
+	cost := f.hashCost
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	// Các tài khoản mẫu có chung mật khẩu nên mỗi mật khẩu chỉ băm một lần
+	hashes := make(map[string]string)
+	users := make([]models.User, len(f.Users))
+	for i, u := range f.Users {
+		if _, ok := hashes[u.Password]; !ok {
+			hashed, err := bcrypt.GenerateFromPassword([]byte(u.Password), cost)
+			if err != nil {
+				return err
+			}
+			hashes[u.Password] = string(hashed)
+		}
+		users[i] = models.User{
+			Username: u.Username, Email: u.Email, Password: hashes[u.Password], FullName: u.FullName, Role: u.Role,
+		}
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(users) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "email"}},
+				DoUpdates: clause.AssignmentColumns([]string{"username", "full_name", "role", "updated_at"}),
+			}).Create(&users).Error; err != nil {
+				return fmt.Errorf("seed users: %w", err)
+			}
+		}
+		if len(f.Products) > 0 {
+			products := append([]models.Product(nil), f.Products...)
+			// Version tăng để ETag của sản phẩm đã cập nhật không còn khớp
+			updates := clause.AssignmentColumns([]string{"description", "price", "category", "image_url", "weight_grams", "updated_at"})
+			updates = append(updates, clause.Assignment{Column: clause.Column{Name: "version"}, Value: gorm.Expr("products.version + 1")})
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "name"}},
+				DoUpdates: updates,
+			}).Create(&products).Error; err != nil {
+				return fmt.Errorf("seed products: %w", err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,187 @@
+// Package selfcheck kiểm tra các phụ thuộc của tiến trình (database, thư mục lưu file, SMTP, khóa JWT) khi
+// khởi động. Mọi kiểm tra đều chạy và được gom thành một báo cáo, để lỗi cấu hình được phát hiện cùng lúc
+// ngay khi khởi động thay vì lần lượt ở các request đầu tiên.
+package selfcheck
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"gorm.io/gorm"
+)
+
+// Check là một kiểm tra phụ thuộc. Lỗi của kiểm tra Optional chỉ được báo cáo, không làm khởi động thất bại.
+type Check struct {
+	Name     string
+	Optional bool
+	Run      func(ctx context.Context) error
+}
+
+// Result là kết quả của một kiểm tra
+type Result struct {
+	Name     string
+	Optional bool
+	Duration time.Duration
+	Err      error
+}
+
+// Report là kết quả của mọi kiểm tra, theo thứ tự đăng ký
+type Report []Result
+
+// Failed cho biết có kiểm tra bắt buộc nào lỗi không
+func (r Report) Failed() bool {
+	for _, result := range r {
+		if result.Err != nil && !result.Optional {
+			return true
+		}
+	}
+	return false
+}
+
+// String là báo cáo nhiều dòng, mỗi kiểm tra một dòng dạng "  [ok] database (12ms)"
+func (r Report) String() string {
+	var b strings.Builder
+	for _, result := range r {
+		status := "ok"
+		switch {
+		case result.Err != nil && result.Optional:
+			status = "warn"
+		case result.Err != nil:
+			status = "FAIL"
+		}
+		fmt.Fprintf(&b, "  [%s] %s (%s)", status, result.Name, result.Duration.Round(time.Millisecond))
+		if result.Err != nil {
+			fmt.Fprintf(&b, ": %v", result.Err)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Run chạy đồng thời mọi kiểm tra, mỗi kiểm tra giới hạn trong timeout
+func Run(ctx context.Context, timeout time.Duration, checks ...Check) Report {
+	report := make(Report, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			start := time.Now()
+			err := run(ctx, check)
+			report[i] = Result{Name: check.Name, Optional: check.Optional, Duration: time.Since(start), Err: err}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+// run chạy kiểm tra và trả về lỗi timeout nếu kiểm tra không tôn trọng ctx
+func run(ctx context.Context, check Check) (err error) {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- check.Run(ctx)
+	}()
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Database kiểm tra database trả lời ping và mọi bảng của models đã được tạo bởi migration
+func Database(db *gorm.DB, models ...interface{}) Check {
+	return Check{Name: "database", Run: func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return fmt.Errorf("ping: %w", err)
+		}
+		migrator := db.WithContext(ctx).Migrator()
+		var missing []string
+		for _, model := range models {
+			if !migrator.HasTable(model) {
+				stmt := &gorm.Statement{DB: db}
+				if err := stmt.Parse(model); err != nil {
+					return err
+				}
+				missing = append(missing, stmt.Schema.Table)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("missing tables: %s", strings.Join(missing, ", "))
+		}
+		return nil
+	}}
+}
+
+// Writable kiểm tra thư mục dir tồn tại (tạo nếu chưa có) và tiến trình ghi, đọc, xóa được file trong đó
+func Writable(name, dir string) Check {
+	return Check{Name: name, Run: func(context.Context) error {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		f, err := os.CreateTemp(dir, ".selfcheck-*")
+		if err != nil {
+			return err
+		}
+		path := f.Name()
+		defer os.Remove(path)
+		if _, err := f.WriteString("ok"); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if string(content) != "ok" {
+			return errors.New("read back different content from " + filepath.Base(path))
+		}
+		return os.Remove(path)
+	}}
+}
+
+// Ping kiểm tra pool kết nối trả lời ping, dùng cho các pool không thuộc GORM (ví dụ read replica)
+func Ping(name string, db *sql.DB) Check {
+	return Check{Name: name, Run: func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}}
+}
+
+// RunFromEnv chạy các kiểm tra khi khởi động và ghi báo cáo vào log. STARTUP_CHECKS=false bỏ qua mọi kiểm
+// tra; STARTUP_CHECK_TIMEOUT (mặc định 10s) giới hạn thời gian của mỗi kiểm tra. Trả về lỗi nếu có kiểm
+// tra bắt buộc thất bại.
+func RunFromEnv(ctx context.Context, checks ...Check) error {
+	if os.Getenv("STARTUP_CHECKS") == "false" {
+		log.Println("Startup checks skipped (STARTUP_CHECKS=false)")
+		return nil
+	}
+	report := Run(ctx, config.Duration("STARTUP_CHECK_TIMEOUT", 10*time.Second), checks...)
+	if report.Failed() {
+		return fmt.Errorf("startup checks failed:\n%s", report)
+	}
+	log.Printf("Startup checks passed:\n%s", report)
+	return nil
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/password"
+	"github.com/NgTruong624/project_backend/internal/payment"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrInvalidPassword  = errors.New("password is incorrect")
+	ErrUserAnonymized   = errors.New("user data has already been anonymized")
+	ErrLastAdminAccount = errors.New("cannot delete the last admin account")
+)
+
+// DefaultDeletionGracePeriod là thời gian tài khoản đã xóa còn khôi phục được trước khi bị ẩn danh hóa
+const DefaultDeletionGracePeriod = 30 * 24 * time.Hour
+
+// DeletionGracePeriod đọc thời gian chờ từ USER_DELETION_GRACE_PERIOD (ví dụ 720h), mặc định DefaultDeletionGracePeriod
+func DeletionGracePeriod() time.Duration {
+	if v := os.Getenv("USER_DELETION_GRACE_PERIOD"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			return d
+		}
+	}
+	return DefaultDeletionGracePeriod
+}
+
+// AccountService xử lý xóa tài khoản, khôi phục, ẩn danh hóa và xuất dữ liệu cá nhân (GDPR)
+type AccountService struct {
+	db          *gorm.DB
+	tx          *repository.TxManager
+	users       *repository.UserRepository
+	provider    payment.Provider
+	gracePeriod time.Duration
+}
+
+func NewAccountService(db *gorm.DB, provider payment.Provider) *AccountService {
+	return &AccountService{
+		db:          db,
+		tx:          repository.NewTxManager(db),
+		users:       repository.NewUserRepository(db),
+		provider:    provider,
+		gracePeriod: DeletionGracePeriod(),
+	}
+}
+
+// Delete xóa mềm tài khoản của user sau khi xác nhận mật khẩu. Thiết bị nhận push và giỏ hàng
+// bị xóa ngay; các dữ liệu khác được giữ tới hết thời gian chờ để có thể khôi phục.
+func (s *AccountService) Delete(ctx context.Context, userID uint, currentPassword string) (*models.UserResponse, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if err := password.Compare(user.Password, currentPassword); err != nil {
+		return nil, ErrInvalidPassword
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		tx := repository.Conn(ctx, s.db)
+		if user.Role == "admin" {
+			var admins int64
+			if err := tx.Model(&models.User{}).Where("role = ?", "admin").Count(&admins).Error; err != nil {
+				return err
+			}
+			if admins <= 1 {
+				return ErrLastAdminAccount
+			}
+		}
+		if err := s.users.SoftDelete(ctx, user.ID); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.DeviceToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.Cart{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.users.GetDeleted(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := s.UserResponse(deleted)
+	return &resp, nil
+}
+
+// Restore khôi phục tài khoản đã xóa khi chưa hết thời gian chờ
+func (s *AccountService) Restore(ctx context.Context, id uint) (*models.UserResponse, error) {
+	user, err := s.users.GetDeleted(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.AnonymizedAt != nil {
+		return nil, ErrUserAnonymized
+	}
+	restored, err := s.users.Restore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, ErrUserAnonymized
+	}
+
+	user, err = s.users.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resp := s.UserResponse(user)
+	return &resp, nil
+}
+
+// AnonymizeExpired ẩn danh hóa các tài khoản đã xóa quá thời gian chờ. Hóa đơn được giữ nguyên
+// (nghĩa vụ lưu trữ kế toán) và vẫn tham chiếu tới user_id; mọi dữ liệu cá nhân khác bị xóa.
+func (s *AccountService) AnonymizeExpired(ctx context.Context) (int, error) {
+	ids, err := s.users.ListPendingAnonymization(ctx, time.Now().Add(-s.gracePeriod))
+	if err != nil {
+		return 0, err
+	}
+	var errs []error
+	anonymized := 0
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return anonymized, err
+		}
+		if err := s.anonymize(ctx, id); err != nil {
+			errs = append(errs, fmt.Errorf("user %d: %w", id, err))
+			continue
+		}
+		anonymized++
+	}
+	return anonymized, errors.Join(errs...)
+}
+
+func (s *AccountService) anonymize(ctx context.Context, userID uint) error {
+	// Xóa customer tại nhà cung cấp thanh toán trước; nếu lỗi thì để lần chạy sau thử lại
+	customers, err := repository.NewPaymentMethodRepository(s.db).ListCustomers(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, customer := range customers {
+		if s.provider == nil || customer.Provider != s.provider.Name() {
+			return fmt.Errorf("payment provider %q is not configured", customer.Provider)
+		}
+		if err := s.provider.DeleteCustomer(ctx, customer.CustomerID); err != nil && !errors.Is(err, payment.ErrInvalidMethod) {
+			return err
+		}
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := repository.NewPaymentMethodRepository(s.db).DeleteUserData(ctx, userID); err != nil {
+			return err
+		}
+		if err := repository.NewNotificationRepository(s.db).DeleteUserData(ctx, userID); err != nil {
+			return err
+		}
+		if err := repository.Conn(ctx, s.db).Where("user_id = ?", userID).Delete(&models.Cart{}).Error; err != nil {
+			return err
+		}
+		if err := repository.NewAddressRepository(s.db).DeleteUserData(ctx, userID); err != nil {
+			return err
+		}
+		if err := repository.NewProductAlertRepository(s.db).DeleteByUser(ctx, userID); err != nil {
+			return err
+		}
+		if err := repository.NewEmailChangeRepository(s.db).DeleteUserData(ctx, userID); err != nil {
+			return err
+		}
+		if err := repository.NewUserInvitationRepository(s.db).DeleteUserData(ctx, userID); err != nil {
+			return err
+		}
+		if err := repository.NewSupportTicketRepository(s.db).DeleteUserData(ctx, userID); err != nil {
+			return err
+		}
+		if err := repository.NewLoginEventRepository(s.db).DeleteUserData(ctx, userID); err != nil {
+			return err
+		}
+		if err := repository.NewOrderRepository(s.db).ScrubShippingAddresses(ctx, userID); err != nil {
+			return err
+		}
+		if err := repository.NewAuditLogRepository(s.db).ScrubUser(ctx, userID); err != nil {
+			return err
+		}
+		return s.users.Anonymize(ctx, userID)
+	})
+}
+
+// Export gom toàn bộ dữ liệu cá nhân của user
+func (s *AccountService) Export(ctx context.Context, userID uint) (*models.UserDataExport, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	export := &models.UserDataExport{ExportedAt: time.Now(), Profile: s.UserResponse(user), Cart: []models.CartItem{}}
+	notifications := repository.NewNotificationRepository(s.db)
+	if export.Devices, err = notifications.ListDevices(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.NotificationPreferences, err = notifications.ListPreferences(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.PaymentMethods, err = repository.NewPaymentMethodRepository(s.db).List(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Addresses, err = repository.NewAddressRepository(s.db).List(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.ProductAlerts, err = repository.NewProductAlertRepository(s.db).ListByUser(ctx, userID, time.Time{}); err != nil {
+		return nil, err
+	}
+	carts := repository.NewCartRepository(s.db)
+	if cart, err := carts.GetByUserID(ctx, userID); err == nil {
+		if export.Cart, err = carts.GetItems(ctx, cart.ID); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if export.Orders, err = repository.NewOrderRepository(s.db).ListAllByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Payments, err = repository.NewPaymentRepository(s.db).ListByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.EmailChanges, err = repository.NewEmailChangeRepository(s.db).ListByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Invoices, err = repository.NewInvoiceRepository(s.db).ListByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Returns, err = repository.NewReturnRepository(s.db).ListByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	questions := repository.NewProductQuestionRepository(s.db)
+	if export.ProductQuestions, err = questions.ListQuestionsByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.ProductAnswers, err = questions.ListAnswersByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	reviews := repository.NewReviewRepository(s.db)
+	if export.ProductReviews, err = reviews.ListByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.ReviewVotes, err = reviews.ListVotesByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.ReviewReports, err = reviews.ListReportsByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.SupportTickets, err = repository.NewSupportTicketRepository(s.db).ListByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.LoginEvents, err = repository.NewLoginEventRepository(s.db).ListByUser(ctx, userID, 0); err != nil {
+		return nil, err
+	}
+	if export.AuditLogs, err = repository.NewAuditLogRepository(s.db).ListByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// UserResponse dựng UserResponse, kèm thời điểm xóa và thời điểm sẽ ẩn danh hóa nếu tài khoản đã bị xóa
+func (s *AccountService) UserResponse(user *models.User) models.UserResponse {
+	resp := models.UserResponse{
+		ID:           user.ID,
+		Username:     user.Username,
+		Email:        user.Email,
+		FullName:     user.FullName,
+		Role:         user.Role,
+		Status:       user.Status,
+		CreatedAt:    user.CreatedAt,
+		AnonymizedAt: user.AnonymizedAt,
+	}
+	if user.DeletedAt.Valid {
+		deletedAt := user.DeletedAt.Time
+		resp.DeletedAt = &deletedAt
+		if user.AnonymizedAt == nil {
+			purgeAt := deletedAt.Add(s.gracePeriod)
+			resp.PurgeAt = &purgeAt
+		}
+	}
+	return resp
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAddressNotFound = errors.New("address not found")
+	ErrAddressLimit    = errors.New("address book is full")
+)
+
+// AddressService quản lý sổ địa chỉ giao hàng của user
+type AddressService struct {
+	tx   *repository.TxManager
+	repo *repository.AddressRepository
+}
+
+func NewAddressService(db *gorm.DB) *AddressService {
+	return &AddressService{
+		tx:   repository.NewTxManager(db),
+		repo: repository.NewAddressRepository(db),
+	}
+}
+
+// List lấy sổ địa chỉ của user
+func (s *AddressService) List(ctx context.Context, userID uint) ([]models.Address, error) {
+	return s.repo.List(ctx, userID)
+}
+
+// Get lấy một địa chỉ của user
+func (s *AddressService) Get(ctx context.Context, userID, id uint) (*models.Address, error) {
+	address, err := s.repo.Get(ctx, userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAddressNotFound
+	}
+	return address, err
+}
+
+// Create thêm địa chỉ vào sổ địa chỉ; địa chỉ đầu tiên luôn được đặt làm mặc định
+func (s *AddressService) Create(ctx context.Context, userID uint, req *models.AddressRequest) (*models.Address, error) {
+	address := &models.Address{UserID: userID, AddressDetails: req.Details()}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		count, err := s.repo.Count(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if count >= models.MaxAddressesPerUser {
+			return ErrAddressLimit
+		}
+		if err := s.repo.Create(ctx, address); err != nil {
+			return err
+		}
+		if req.IsDefault || count == 0 {
+			address.IsDefault = true
+			return s.repo.SetDefault(ctx, userID, address.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// Update sửa địa chỉ; các đơn đã đặt giữ bản chụp địa chỉ cũ nên không bị ảnh hưởng.
+// is_default=true đặt địa chỉ làm mặc định; false không bỏ mặc định (hãy chọn địa chỉ khác làm mặc định).
+func (s *AddressService) Update(ctx context.Context, userID, id uint, req *models.AddressRequest) (*models.Address, error) {
+	address, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	address.AddressDetails = req.Details()
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateDetails(ctx, address); err != nil {
+			return err
+		}
+		if req.IsDefault && !address.IsDefault {
+			address.IsDefault = true
+			return s.repo.SetDefault(ctx, userID, address.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// SetDefault đặt địa chỉ làm mặc định của user
+func (s *AddressService) SetDefault(ctx context.Context, userID, id uint) (*models.Address, error) {
+	address, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.repo.SetDefault(ctx, userID, id)
+	}); err != nil {
+		return nil, err
+	}
+	address.IsDefault = true
+	return address, nil
+}
+
+// Delete xóa địa chỉ; nếu đó là địa chỉ mặc định thì địa chỉ được thêm gần nhất còn lại
+// trở thành mặc định
+func (s *AddressService) Delete(ctx context.Context, userID, id uint) error {
+	address, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, address.ID); err != nil {
+			return err
+		}
+		if !address.IsDefault {
+			return nil
+		}
+		next, err := s.repo.Latest(ctx, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return s.repo.SetDefault(ctx, userID, next.ID)
+	})
+}
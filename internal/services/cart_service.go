@@ -0,0 +1,590 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/promotion"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCartNotFound      = errors.New("cart not found")
+	ErrCartItemNotFound  = errors.New("product is not in the cart")
+	ErrInsufficientStock = errors.New("insufficient stock")
+)
+
+// DefaultCartExpiry là thời gian một giỏ không có hoạt động trước khi bị coi là hết hạn
+const DefaultCartExpiry = 30 * 24 * time.Hour
+
+// CartExpiry đọc thời gian hết hạn giỏ từ CART_EXPIRY (ví dụ 720h), mặc định DefaultCartExpiry
+func CartExpiry() time.Duration {
+	if v := os.Getenv("CART_EXPIRY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultCartExpiry
+}
+
+// CartOwner xác định giỏ hàng: theo user đã đăng nhập, hoặc theo cart token của khách
+type CartOwner struct {
+	UserID uint
+	Token  string
+}
+
+// CartService quản lý giỏ hàng của user và của khách vãng lai
+type CartService struct {
+	tx            *repository.TxManager
+	repo          *repository.CartRepository
+	products      *repository.ProductRepository
+	orders        *repository.OrderRepository
+	promotions    *PromotionService
+	mergeStrategy string
+	expiry        time.Duration
+}
+
+// NewCartService tạo service với cách gộp giỏ khi đăng nhập đọc từ CART_MERGE_STRATEGY
+// (sum: cộng dồn số lượng, max: giữ số lượng lớn hơn; mặc định sum) và thời gian hết hạn từ CART_EXPIRY
+func NewCartService(db *gorm.DB) *CartService {
+	strategy := os.Getenv("CART_MERGE_STRATEGY")
+	if strategy != models.CartMergeMax {
+		strategy = models.CartMergeSum
+	}
+	return &CartService{
+		tx:            repository.NewTxManager(db),
+		repo:          repository.NewCartRepository(db),
+		products:      repository.NewProductRepository(db),
+		orders:        repository.NewOrderRepository(db),
+		promotions:    NewPromotionService(db),
+		mergeStrategy: strategy,
+		expiry:        CartExpiry(),
+	}
+}
+
+// newCartToken sinh token ngẫu nhiên cho giỏ của khách
+func newCartToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// find lấy giỏ của owner; create = true thì tạo giỏ mới khi chưa có
+func (s *CartService) find(ctx context.Context, owner CartOwner, create bool) (*models.Cart, error) {
+	if owner.UserID != 0 {
+		cart, err := s.repo.GetByUserID(ctx, owner.UserID)
+		if err == nil {
+			err = s.expireIfIdle(ctx, cart)
+		}
+		switch {
+		case err == nil:
+			return cart, nil
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, ErrCartNotFound):
+			if create {
+				return s.repo.GetOrCreateForUser(ctx, owner.UserID)
+			}
+			return nil, ErrCartNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if owner.Token != "" {
+		cart, err := s.repo.GetByToken(ctx, owner.Token)
+		if err == nil {
+			err = s.expireIfIdle(ctx, cart)
+		}
+		switch {
+		case err == nil:
+			return cart, nil
+		case !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, ErrCartNotFound):
+			return nil, err
+		}
+	}
+	if !create {
+		return nil, ErrCartNotFound
+	}
+
+	// Token không hợp lệ hoặc đã hết hạn: cấp token mới thay vì dùng lại token client gửi lên
+	token, err := newCartToken()
+	if err != nil {
+		return nil, err
+	}
+	cart := &models.Cart{Token: &token}
+	if err := s.repo.Create(ctx, cart); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// expireIfIdle xóa giỏ đã quá hạn không hoạt động và trả về ErrCartNotFound.
+// Tác vụ cart_sweep cũng dọn các giỏ này định kỳ; kiểm tra ở đây để giỏ hết hạn
+// không được dùng lại trong khoảng giữa hai lần chạy.
+func (s *CartService) expireIfIdle(ctx context.Context, cart *models.Cart) error {
+	if time.Since(cart.UpdatedAt) <= s.expiry {
+		return nil
+	}
+	if err := s.repo.Delete(ctx, cart.ID); err != nil {
+		return err
+	}
+	return ErrCartNotFound
+}
+
+// Get lấy giỏ của owner; giỏ chưa tồn tại được trả về rỗng
+func (s *CartService) Get(ctx context.Context, owner CartOwner) (*models.CartResponse, error) {
+	cart, err := s.find(ctx, owner, false)
+	if errors.Is(err, ErrCartNotFound) {
+		return &models.CartResponse{Items: []models.CartItemResponse{}, Discounts: []models.AppliedPromotion{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.response(ctx, cart)
+}
+
+// AddItem thêm sản phẩm vào giỏ (cộng vào số lượng đang có), tạo giỏ nếu chưa có
+func (s *CartService) AddItem(ctx context.Context, owner CartOwner, req *models.AddCartItemRequest) (*models.CartResponse, error) {
+	cart, err := s.find(ctx, owner, true)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity := req.Quantity
+	if item, err := s.repo.GetItem(ctx, cart.ID, req.ProductID); err == nil {
+		quantity += item.Quantity
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err := s.setQuantity(ctx, cart, req.ProductID, quantity); err != nil {
+		return nil, err
+	}
+	return s.response(ctx, cart)
+}
+
+// UpdateItem đặt lại số lượng của một sản phẩm đã có trong giỏ
+func (s *CartService) UpdateItem(ctx context.Context, owner CartOwner, productID uint, quantity int) (*models.CartResponse, error) {
+	cart, err := s.find(ctx, owner, false)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetItem(ctx, cart.ID, productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCartItemNotFound
+		}
+		return nil, err
+	}
+	if err := s.setQuantity(ctx, cart, productID, quantity); err != nil {
+		return nil, err
+	}
+	return s.response(ctx, cart)
+}
+
+// RemoveItem xóa một sản phẩm khỏi giỏ
+func (s *CartService) RemoveItem(ctx context.Context, owner CartOwner, productID uint) (*models.CartResponse, error) {
+	cart, err := s.find(ctx, owner, false)
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := s.repo.DeleteItem(ctx, cart.ID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		return nil, ErrCartItemNotFound
+	}
+	if err := s.repo.Touch(ctx, cart.ID); err != nil {
+		return nil, err
+	}
+	return s.response(ctx, cart)
+}
+
+// Clear xóa mọi sản phẩm trong giỏ
+func (s *CartService) Clear(ctx context.Context, owner CartOwner) error {
+	cart, err := s.find(ctx, owner, false)
+	if errors.Is(err, ErrCartNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.repo.ClearItems(ctx, cart.ID); err != nil {
+		return err
+	}
+	return s.repo.Touch(ctx, cart.ID)
+}
+
+func (s *CartService) setQuantity(ctx context.Context, cart *models.Cart, productID uint, quantity int) error {
+	product, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrProductNotFound
+		}
+		return err
+	}
+	if product.Status != models.ProductStatusPublished {
+		return ErrProductUnavailable
+	}
+	if quantity > product.Stock {
+		return ErrInsufficientStock
+	}
+	if err := s.repo.SetItemQuantity(ctx, cart.ID, productID, quantity, product.EffectivePrice()); err != nil {
+		return err
+	}
+	return s.repo.Touch(ctx, cart.ID)
+}
+
+// Merge gộp giỏ khách (theo token) vào giỏ của user khi đăng nhập rồi xóa giỏ khách.
+// Sản phẩm có ở cả hai giỏ được gộp theo chiến lược cấu hình; số lượng vượt tồn kho bị giảm
+// xuống bằng tồn kho. Trả về nil nếu token không ứng với giỏ khách nào.
+func (s *CartService) Merge(ctx context.Context, userID uint, token string) (*models.CartMergeResult, error) {
+	if token == "" {
+		return nil, nil
+	}
+	guest, err := s.repo.GetByToken(ctx, token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CartMergeResult{Strategy: s.mergeStrategy}
+	var cart *models.Cart
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Khóa giỏ khách trước để hai lần đăng nhập đồng thời với cùng token không gộp hai lần
+		if err := s.repo.Lock(ctx, guest.ID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		userCart, err := s.repo.GetOrCreateForUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Lock(ctx, userCart.ID); err != nil {
+			return err
+		}
+		cart = userCart
+
+		guestItems, err := s.repo.GetItems(ctx, guest.ID)
+		if err != nil {
+			return err
+		}
+		for _, item := range guestItems {
+			quantity, price := item.Quantity, item.Price
+			existing, err := s.repo.GetItem(ctx, userCart.ID, item.ProductID)
+			switch {
+			case err == nil:
+				// Giữ giá ghi nhận của giỏ user để cờ đổi giá vẫn so với giá user đã thấy
+				quantity, price = mergeQuantity(s.mergeStrategy, existing.Quantity, item.Quantity), existing.Price
+			case !errors.Is(err, gorm.ErrRecordNotFound):
+				return err
+			}
+			if quantity > item.Product.Stock {
+				quantity = item.Product.Stock
+				result.Adjusted = append(result.Adjusted, item.ProductID)
+			}
+			if quantity <= 0 {
+				if _, err := s.repo.DeleteItem(ctx, userCart.ID, item.ProductID); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := s.repo.SetItemQuantity(ctx, userCart.ID, item.ProductID, quantity, price); err != nil {
+				return err
+			}
+			result.MergedItems++
+		}
+
+		if err := s.repo.Delete(ctx, guest.ID); err != nil {
+			return err
+		}
+		return s.repo.Touch(ctx, userCart.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return nil, nil
+	}
+
+	resp, err := s.response(ctx, cart)
+	if err != nil {
+		return nil, err
+	}
+	result.Cart = *resp
+	return result, nil
+}
+
+func mergeQuantity(strategy string, current, incoming int) int {
+	if strategy == models.CartMergeMax {
+		if incoming > current {
+			return incoming
+		}
+		return current
+	}
+	return current + incoming
+}
+
+// Reorder thêm lại các dòng của một đơn cũ của user vào giỏ theo giá và tồn kho hiện tại. Số lượng
+// (cộng với số đang có trong giỏ) vượt tồn kho được giảm xuống bằng tồn kho; sản phẩm không còn bán
+// hoặc hết hàng được thay bằng sản phẩm cùng danh mục còn hàng có giá gần nhất, trừ khi
+// req.NoSubstitutes. Kết quả từng dòng được trả về cùng giỏ hàng sau khi thêm.
+func (s *CartService) Reorder(ctx context.Context, userID, orderID uint, req *models.ReorderRequest) (*models.ReorderResult, error) {
+	order, err := s.orders.GetByID(ctx, orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && order.UserID != userID) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ReorderResult{OrderID: order.ID, Lines: make([]models.ReorderLine, 0, len(order.Items))}
+	var cart *models.Cart
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		cart, err = s.repo.GetOrCreateForUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Lock(ctx, cart.ID); err != nil {
+			return err
+		}
+		if req.ReplaceCart {
+			if err := s.repo.ClearItems(ctx, cart.ID); err != nil {
+				return err
+			}
+		}
+		// Sản phẩm thay thế không được trùng với sản phẩm nào của đơn
+		exclude := make([]uint, 0, len(order.Items))
+		for _, item := range order.Items {
+			exclude = append(exclude, item.ProductID)
+		}
+
+		for _, item := range order.Items {
+			line := models.ReorderLine{
+				ProductID: item.ProductID, Name: item.Name,
+				OrderedQuantity: item.Quantity, OrderedPrice: item.UnitPrice,
+			}
+			product, err := s.products.GetByID(ctx, item.ProductID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if product != nil && product.Status == models.ProductStatusPublished {
+				line.CurrentPrice = product.EffectivePrice()
+				line.PriceChanged = line.CurrentPrice != item.UnitPrice
+			}
+
+			target := product
+			if product == nil || product.Status != models.ProductStatusPublished || product.Stock <= 0 {
+				target = nil
+				if product != nil && !req.NoSubstitutes {
+					target, err = s.products.FindSubstitute(ctx, product.Category, item.UnitPrice, exclude)
+					if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+						return err
+					}
+				}
+				if target == nil {
+					line.Outcome = models.ReorderUnavailable
+					result.Lines = append(result.Lines, line)
+					continue
+				}
+				line.Outcome = models.ReorderSubstituted
+				line.Substitute = &models.ReorderSubstitute{ProductID: target.ID, Name: target.Name, UnitPrice: target.EffectivePrice()}
+				exclude = append(exclude, target.ID)
+			}
+
+			existing := 0
+			if cartItem, err := s.repo.GetItem(ctx, cart.ID, target.ID); err == nil {
+				existing = cartItem.Quantity
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			line.Quantity = item.Quantity
+			if existing+line.Quantity > target.Stock {
+				line.Quantity = target.Stock - existing
+			}
+			if line.Outcome == "" {
+				line.Outcome = models.ReorderAdded
+				if line.Quantity < item.Quantity {
+					line.Outcome = models.ReorderReduced
+				}
+			}
+			if line.Quantity <= 0 {
+				// Giỏ đã có đủ tồn kho còn lại của sản phẩm
+				line.Quantity = 0
+				result.Lines = append(result.Lines, line)
+				continue
+			}
+			if err := s.repo.SetItemQuantity(ctx, cart.ID, target.ID, existing+line.Quantity, target.EffectivePrice()); err != nil {
+				return err
+			}
+			result.Lines = append(result.Lines, line)
+		}
+		return s.repo.Touch(ctx, cart.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range result.Lines {
+		if line.Outcome != models.ReorderAdded || line.PriceChanged {
+			result.HasChanges = true
+		}
+	}
+	resp, err := s.response(ctx, cart)
+	if err != nil {
+		return nil, err
+	}
+	result.Cart = *resp
+	return result, nil
+}
+
+// Revalidate đối chiếu giỏ với giá và tồn kho hiện tại. Response đánh dấu các dòng đổi giá
+// hoặc thiếu hàng so với trước lần revalidate; sau đó giá ghi nhận được cập nhật theo giá hiện tại,
+// số lượng vượt tồn kho được giảm xuống bằng tồn kho; dòng đã hết hàng hoặc sản phẩm không còn được bán
+// (draft/archived) bị xóa khỏi giỏ.
+func (s *CartService) Revalidate(ctx context.Context, owner CartOwner) (*models.CartResponse, error) {
+	cart, err := s.find(ctx, owner, false)
+	if errors.Is(err, ErrCartNotFound) {
+		return &models.CartResponse{Items: []models.CartItemResponse{}, Discounts: []models.AppliedPromotion{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	flagged := make(map[uint]models.CartItemResponse)
+	var removed []uint
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Lock(ctx, cart.ID); err != nil {
+			return err
+		}
+		items, err := s.repo.GetItems(ctx, cart.ID)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			flagged[item.ProductID] = s.itemResponse(item)
+
+			quantity := item.Quantity
+			if quantity > item.Product.Stock {
+				quantity = item.Product.Stock
+			}
+			if item.Product.Status != models.ProductStatusPublished {
+				quantity = 0
+			}
+			if quantity <= 0 {
+				if _, err := s.repo.DeleteItem(ctx, cart.ID, item.ProductID); err != nil {
+					return err
+				}
+				removed = append(removed, item.ProductID)
+				continue
+			}
+			if quantity != item.Quantity || item.Price != item.Product.EffectivePrice() {
+				if err := s.repo.SetItemQuantity(ctx, cart.ID, item.ProductID, quantity, item.Product.EffectivePrice()); err != nil {
+					return err
+				}
+			}
+		}
+		return s.repo.Touch(ctx, cart.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.response(ctx, cart)
+	if err != nil {
+		return nil, err
+	}
+	for i := range resp.Items {
+		before := flagged[resp.Items[i].ProductID]
+		resp.Items[i].PriceChanged = before.PriceChanged
+		resp.Items[i].PreviousPrice = before.PreviousPrice
+		resp.Items[i].InsufficientStock = before.InsufficientStock
+		if before.PriceChanged || before.InsufficientStock {
+			resp.HasChanges = true
+		}
+	}
+	resp.RemovedProductIDs = removed
+	if len(removed) > 0 {
+		resp.HasChanges = true
+	}
+	return resp, nil
+}
+
+// response dựng CartResponse với giá hiện tại của sản phẩm
+func (s *CartService) response(ctx context.Context, cart *models.Cart) (*models.CartResponse, error) {
+	items, err := s.repo.GetItems(ctx, cart.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.CartResponse{
+		ID:        cart.ID,
+		Items:     make([]models.CartItemResponse, 0, len(items)),
+		UpdatedAt: cart.UpdatedAt,
+	}
+	if cart.Token != nil {
+		resp.Token = *cart.Token
+	}
+	for _, item := range items {
+		line := s.itemResponse(item)
+		resp.Items = append(resp.Items, line)
+		resp.TotalQuantity += item.Quantity
+		resp.Subtotal += line.LineTotal
+		if line.PriceChanged || line.InsufficientStock {
+			resp.HasChanges = true
+		}
+		if item.UpdatedAt.After(resp.UpdatedAt) {
+			resp.UpdatedAt = item.UpdatedAt
+		}
+	}
+	resp.ExpiresAt = resp.UpdatedAt.Add(s.expiry)
+
+	lines := make([]promotion.Line, len(items))
+	for i, item := range items {
+		lines[i] = promotion.Line{ProductID: item.ProductID, Category: item.Product.Category, UnitPrice: item.Product.EffectivePrice(), Quantity: item.Quantity}
+	}
+	discounts, err := s.promotions.Evaluate(ctx, lines)
+	if err != nil {
+		return nil, err
+	}
+	for i := range resp.Items {
+		resp.Items[i].Discount = discounts.LineDiscounts[i]
+	}
+	resp.Discounts = discounts.Applied
+	if resp.Discounts == nil {
+		resp.Discounts = []models.AppliedPromotion{}
+	}
+	resp.DiscountTotal = discounts.Total
+	resp.Total = resp.Subtotal - resp.DiscountTotal
+	return resp, nil
+}
+
+// itemResponse dựng một dòng của CartResponse và đánh dấu đổi giá (so với giá ghi nhận) hoặc thiếu hàng
+func (s *CartService) itemResponse(item models.CartItem) models.CartItemResponse {
+	line := models.CartItemResponse{
+		ProductID:         item.ProductID,
+		Name:              item.Product.Name,
+		ImageURL:          item.Product.ImageURL,
+		UnitPrice:         item.Product.EffectivePrice(),
+		Quantity:          item.Quantity,
+		Stock:             item.Product.Stock,
+		LineTotal:         item.Product.EffectivePrice() * float64(item.Quantity),
+		InsufficientStock: item.Quantity > item.Product.Stock,
+	}
+	// Dòng tạo trước khi có cột price có giá ghi nhận bằng 0, không coi là đổi giá
+	if item.Price > 0 && item.Price != item.Product.EffectivePrice() {
+		line.PriceChanged = true
+		line.PreviousPrice = item.Price
+	}
+	return line
+}
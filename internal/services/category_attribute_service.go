@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCategoryAttributeNotFound = errors.New("category attribute not found")
+	ErrCategoryAttributeExists   = errors.New("attribute key already exists for this category")
+	ErrInvalidCategoryAttribute  = errors.New("invalid category attribute")
+	ErrInvalidSpecs              = errors.New("invalid product specs")
+	ErrUnknownAttributeFilter    = errors.New("unknown attribute filter")
+)
+
+// attributeKeyPattern giới hạn khóa thuộc tính ở chữ thường, số và gạch dưới để dùng an toàn trong
+// đường dẫn JSON và query string (?attr[ram]=...)
+var attributeKeyPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// CategoryAttributeService quản lý registry thuộc tính theo danh mục và kiểm tra thông số sản phẩm theo registry
+type CategoryAttributeService struct {
+	repo *repository.CategoryAttributeRepository
+}
+
+func NewCategoryAttributeService(db *gorm.DB) *CategoryAttributeService {
+	return &CategoryAttributeService{
+		repo: repository.NewCategoryAttributeRepository(db),
+	}
+}
+
+// List lấy các thuộc tính đã khai báo; category rỗng lấy của mọi danh mục
+func (s *CategoryAttributeService) List(ctx context.Context, category string) ([]models.CategoryAttribute, error) {
+	return s.repo.List(ctx, category)
+}
+
+// Create khai báo thuộc tính cho danh mục; mỗi khóa chỉ khai báo một lần trong một danh mục
+func (s *CategoryAttributeService) Create(ctx context.Context, req *models.CategoryAttributeRequest) (*models.CategoryAttribute, error) {
+	attribute := &models.CategoryAttribute{}
+	if err := applyAttributeRequest(attribute, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, attribute); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrCategoryAttributeExists
+		}
+		return nil, err
+	}
+	return attribute, nil
+}
+
+// Update sửa thuộc tính; thông số đã lưu của sản phẩm không bị sửa theo và chỉ được kiểm tra lại ở lần ghi sau
+func (s *CategoryAttributeService) Update(ctx context.Context, id uint, req *models.CategoryAttributeRequest) (*models.CategoryAttribute, error) {
+	attribute, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCategoryAttributeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := applyAttributeRequest(attribute, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, attribute); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrCategoryAttributeExists
+		}
+		return nil, err
+	}
+	return attribute, nil
+}
+
+// Delete xóa thuộc tính
+func (s *CategoryAttributeService) Delete(ctx context.Context, id uint) error {
+	deleted, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrCategoryAttributeNotFound
+	}
+	return nil
+}
+
+// ValidateSpecs kiểm tra specs chỉ dùng thuộc tính khai báo cho category, đúng kiểu, nằm trong Options
+// (nếu có) và đủ các thuộc tính bắt buộc
+func (s *CategoryAttributeService) ValidateSpecs(ctx context.Context, category string, specs models.Specs) error {
+	var attributes []models.CategoryAttribute
+	if category != "" {
+		var err error
+		if attributes, err = s.repo.List(ctx, category); err != nil {
+			return err
+		}
+	}
+	defined := make(map[string]models.CategoryAttribute, len(attributes))
+	for _, attribute := range attributes {
+		defined[attribute.Key] = attribute
+	}
+
+	keys := make([]string, 0, len(specs))
+	for key := range specs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		attribute, ok := defined[key]
+		if !ok {
+			return fmt.Errorf("%w: %s is not an attribute of category %q", ErrInvalidSpecs, key, category)
+		}
+		if err := checkAttributeValue(attribute, specs[key]); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidSpecs, err)
+		}
+	}
+	for _, attribute := range attributes {
+		if attribute.Required && specs[attribute.Key] == "" {
+			return fmt.Errorf("%w: %s is required", ErrInvalidSpecs, attribute.Key)
+		}
+	}
+	return nil
+}
+
+// ValidateFilter kiểm tra các khóa lọc ?attr[...] đã được khai báo cho category (hoặc cho một danh mục
+// bất kỳ khi không lọc theo danh mục)
+func (s *CategoryAttributeService) ValidateFilter(ctx context.Context, category string, attrs map[string]string) error {
+	attributes, err := s.repo.List(ctx, category)
+	if err != nil {
+		return err
+	}
+	for key := range attrs {
+		if !slices.ContainsFunc(attributes, func(a models.CategoryAttribute) bool { return a.Key == key }) {
+			return fmt.Errorf("%w: %s", ErrUnknownAttributeFilter, key)
+		}
+	}
+	return nil
+}
+
+// applyAttributeRequest kiểm tra request và ghi vào attribute
+func applyAttributeRequest(attribute *models.CategoryAttribute, req *models.CategoryAttributeRequest) error {
+	if !attributeKeyPattern.MatchString(req.Key) {
+		return fmt.Errorf("%w: key may only contain lowercase letters, digits and underscores", ErrInvalidCategoryAttribute)
+	}
+	attribute.Category, attribute.Key, attribute.Label = req.Category, req.Key, req.Label
+	attribute.Type, attribute.Options, attribute.Required = req.Type, req.Options, req.Required
+	if attribute.Type == "" {
+		attribute.Type = models.AttributeTypeString
+	}
+	for _, option := range attribute.Options {
+		if err := checkAttributeValue(models.CategoryAttribute{Key: attribute.Key, Type: attribute.Type}, option); err != nil {
+			return fmt.Errorf("%w: option %s", ErrInvalidCategoryAttribute, err)
+		}
+	}
+	return nil
+}
+
+// checkAttributeValue kiểm tra giá trị theo kiểu và danh sách Options của thuộc tính
+func checkAttributeValue(attribute models.CategoryAttribute, value string) error {
+	switch attribute.Type {
+	case models.AttributeTypeNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%s must be a number", attribute.Key)
+		}
+	case models.AttributeTypeBoolean:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s must be true or false", attribute.Key)
+		}
+	}
+	if len(attribute.Options) > 0 && !slices.Contains(attribute.Options, value) {
+		return fmt.Errorf("%s must be one of %v", attribute.Key, attribute.Options)
+	}
+	return nil
+}
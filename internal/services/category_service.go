@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryExists   = errors.New("category name already exists")
+	ErrInvalidCategory  = errors.New("invalid category")
+)
+
+// CategoryService quản lý cây danh mục và dựng cây kèm số sản phẩm cho menu storefront. Cây được cache
+// trong bộ nhớ, xóa khi danh mục hoặc sản phẩm thay đổi (qua event bus) và hết hạn sau CATEGORY_TREE_TTL
+// để các tiến trình khác (worker, instance khác) không giữ cây cũ quá lâu.
+type CategoryService struct {
+	tx       *repository.TxManager
+	repo     *repository.CategoryRepository
+	products *repository.ProductRepository
+	bus      *events.Bus
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	tree    []models.CategoryNode
+	expires time.Time
+}
+
+func NewCategoryService(db *gorm.DB, bus *events.Bus) *CategoryService {
+	s := &CategoryService{
+		tx:       repository.NewTxManager(db),
+		repo:     repository.NewCategoryRepository(db),
+		products: repository.NewProductRepository(db),
+		bus:      bus,
+		ttl:      config.Duration("CATEGORY_TREE_TTL", 10*time.Minute),
+	}
+	if bus != nil {
+		invalidate := func(context.Context, events.Event) { s.invalidate() }
+		for _, name := range []string{events.ProductCreatedEvent, events.ProductUpdatedEvent, events.ProductDeletedEvent, events.CategoryChangedEvent} {
+			bus.Subscribe(name, invalidate)
+		}
+	}
+	return s
+}
+
+// List lấy mọi danh mục đã khai báo (Admin)
+func (s *CategoryService) List(ctx context.Context) ([]models.Category, error) {
+	return s.repo.List(ctx)
+}
+
+// Create khai báo danh mục mới
+func (s *CategoryService) Create(ctx context.Context, req *models.CategoryRequest) (*models.Category, error) {
+	category := &models.Category{}
+	if err := s.apply(ctx, category, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, category); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrCategoryExists
+		}
+		return nil, err
+	}
+	s.changed(ctx, category.ID)
+	return category, nil
+}
+
+// Update sửa danh mục. Đổi tên không đổi danh mục của sản phẩm; sản phẩm còn mang tên cũ hiện thành
+// danh mục gốc chưa khai báo.
+func (s *CategoryService) Update(ctx context.Context, id uint, req *models.CategoryRequest) (*models.Category, error) {
+	category, err := s.category(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, category, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, category); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrCategoryExists
+		}
+		return nil, err
+	}
+	s.changed(ctx, category.ID)
+	return category, nil
+}
+
+// Delete xóa danh mục; danh mục con được chuyển lên danh mục cha của nó
+func (s *CategoryService) Delete(ctx context.Context, id uint) error {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		category, err := s.category(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Reparent(ctx, category.ID, category.ParentID); err != nil {
+			return err
+		}
+		_, err = s.repo.Delete(ctx, category.ID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.changed(ctx, id)
+	return nil
+}
+
+// Tree trả về cây danh mục kèm số sản phẩm đã đăng, lấy từ cache nếu còn hạn
+func (s *CategoryService) Tree(ctx context.Context) ([]models.CategoryNode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tree != nil && time.Now().Before(s.expires) {
+		return s.tree, nil
+	}
+	categories, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.products.GetCategoryCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.tree, s.expires = buildCategoryTree(categories, counts), time.Now().Add(s.ttl)
+	return s.tree, nil
+}
+
+// Node lấy nút của category trong cây (kèm các danh mục con); nil khi category không có trong cây
+func (s *CategoryService) Node(ctx context.Context, category string) (*models.CategoryNode, error) {
+	tree, err := s.Tree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var find func(nodes []models.CategoryNode) *models.CategoryNode
+	find = func(nodes []models.CategoryNode) *models.CategoryNode {
+		for i := range nodes {
+			if nodes[i].Name == category {
+				return &nodes[i]
+			}
+			if found := find(nodes[i].Children); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return find(tree), nil
+}
+
+// Breadcrumbs trả về đường dẫn danh mục từ gốc tới category (lấy từ cây đã cache). Danh mục không có
+// trong cây (chưa khai báo và chưa có sản phẩm đã đăng) chỉ gồm chính nó.
+func (s *CategoryService) Breadcrumbs(ctx context.Context, category string) ([]models.Breadcrumb, error) {
+	if category == "" {
+		return nil, nil
+	}
+	tree, err := s.Tree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var find func(nodes []models.CategoryNode, path []models.Breadcrumb) []models.Breadcrumb
+	find = func(nodes []models.CategoryNode, path []models.Breadcrumb) []models.Breadcrumb {
+		for _, node := range nodes {
+			crumbs := append(slices.Clip(path), models.Breadcrumb{ID: node.ID, Name: node.Name, Slug: utils.Slugify(node.Name)})
+			if node.Name == category {
+				return crumbs
+			}
+			if found := find(node.Children, crumbs); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	if crumbs := find(tree, nil); crumbs != nil {
+		return crumbs, nil
+	}
+	return []models.Breadcrumb{{Name: category, Slug: utils.Slugify(category)}}, nil
+}
+
+func (s *CategoryService) invalidate() {
+	s.mu.Lock()
+	s.tree = nil
+	s.mu.Unlock()
+}
+
+// changed xóa cache của service và báo cho các subscriber khác qua event bus
+func (s *CategoryService) changed(ctx context.Context, id uint) {
+	s.invalidate()
+	s.bus.Publish(ctx, events.CategoryChanged{CategoryID: id})
+}
+
+// RegisterListingCategoryPaths ghi lại category_path của read model product_listings mỗi khi cây danh mục
+// thay đổi. Lỗi chỉ được log; đường dẫn được sửa ở lần dựng lại tiếp theo của sản phẩm đó.
+func RegisterListingCategoryPaths(bus *events.Bus, db *gorm.DB) {
+	listings := repository.NewProductListingRepository(db)
+	events.On(bus, func(ctx context.Context, e events.CategoryChanged) {
+		if err := listings.RefreshCategoryPaths(context.WithoutCancel(ctx)); err != nil {
+			log.Printf("Listings: failed to refresh category paths after category %d changed: %v", e.CategoryID, err)
+		}
+	})
+}
+
+func (s *CategoryService) category(ctx context.Context, id uint) (*models.Category, error) {
+	category, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+// apply kiểm tra request và ghi vào category; danh mục cha phải tồn tại và không được là chính nó
+// hay một danh mục con cháu của nó
+func (s *CategoryService) apply(ctx context.Context, category *models.Category, req *models.CategoryRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name must not be blank", ErrInvalidCategory)
+	}
+	// Unique index chỉ báo trùng trên Postgres; kiểm tra trước để database khác cũng trả lỗi rõ ràng
+	exists, err := s.repo.NameExists(ctx, name, category.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrCategoryExists
+	}
+	if req.ParentID != nil {
+		categories, err := s.repo.List(ctx)
+		if err != nil {
+			return err
+		}
+		parents := make(map[uint]*uint, len(categories))
+		for _, c := range categories {
+			parents[c.ID] = c.ParentID
+		}
+		if _, ok := parents[*req.ParentID]; !ok {
+			return fmt.Errorf("%w: parent category %d does not exist", ErrInvalidCategory, *req.ParentID)
+		}
+		for id := req.ParentID; id != nil; id = parents[*id] {
+			if category.ID != 0 && *id == category.ID {
+				return fmt.Errorf("%w: a category cannot be moved under itself or its subcategories", ErrInvalidCategory)
+			}
+		}
+	}
+	category.Name, category.ParentID, category.Position = name, req.ParentID, req.Position
+	return nil
+}
+
+// buildCategoryTree dựng cây từ danh sách danh mục (đã theo thứ tự hiển thị) và số sản phẩm theo tên danh mục.
+// Danh mục có sản phẩm nhưng chưa khai báo được thêm vào cuối gốc theo tên.
+func buildCategoryTree(categories []models.Category, counts []repository.CategoryCount) []models.CategoryNode {
+	byName := make(map[string]int64, len(counts))
+	for _, c := range counts {
+		byName[c.Category] = c.Count
+	}
+	known := make(map[uint]bool, len(categories))
+	children := make(map[uint][]models.Category)
+	var roots []models.Category
+	for _, c := range categories {
+		known[c.ID] = true
+	}
+	for _, c := range categories {
+		if c.ParentID != nil && known[*c.ParentID] {
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+		} else {
+			roots = append(roots, c)
+		}
+	}
+
+	var build func(c models.Category) models.CategoryNode
+	build = func(c models.Category) models.CategoryNode {
+		node := models.CategoryNode{ID: c.ID, Name: c.Name, ProductCount: byName[c.Name], Children: []models.CategoryNode{}}
+		node.TotalCount = node.ProductCount
+		for _, child := range children[c.ID] {
+			childNode := build(child)
+			node.TotalCount += childNode.TotalCount
+			node.Children = append(node.Children, childNode)
+		}
+		delete(byName, c.Name)
+		return node
+	}
+	tree := make([]models.CategoryNode, 0, len(roots)+len(byName))
+	for _, c := range roots {
+		tree = append(tree, build(c))
+	}
+
+	var unregistered []string
+	for name := range byName {
+		unregistered = append(unregistered, name)
+	}
+	sort.Strings(unregistered)
+	for _, name := range unregistered {
+		count := byName[name]
+		tree = append(tree, models.CategoryNode{Name: name, ProductCount: count, TotalCount: count, Children: []models.CategoryNode{}})
+	}
+	return tree
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/notify"
+	"github.com/NgTruong624/project_backend/internal/password"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmailTaken          = errors.New("email is already in use")
+	ErrSameEmail           = errors.New("new email is the same as the current email")
+	ErrEmailChangeNotFound = errors.New("email change request not found or expired")
+)
+
+// DefaultEmailChangeTTL là thời gian link xác nhận đổi email còn hiệu lực
+const DefaultEmailChangeTTL = 24 * time.Hour
+
+// EmailChangeService xử lý đổi email: gửi link xác nhận tới cả địa chỉ cũ và địa chỉ mới, chỉ đổi
+// email khi cả hai đã xác nhận và khi đó thu hồi mọi phiên đăng nhập của user
+type EmailChangeService struct {
+	tx         *repository.TxManager
+	users      *repository.UserRepository
+	changes    *repository.EmailChangeRepository
+	notifier   *notify.Dispatcher
+	confirmURL string
+	ttl        time.Duration
+}
+
+// NewEmailChangeService tạo service; link xác nhận là EMAIL_CHANGE_CONFIRM_URL kèm ?token=,
+// thời hạn link đọc từ EMAIL_CHANGE_TTL
+func NewEmailChangeService(db *gorm.DB, notifier *notify.Dispatcher) *EmailChangeService {
+	return &EmailChangeService{
+		tx:         repository.NewTxManager(db),
+		users:      repository.NewUserRepository(db),
+		changes:    repository.NewEmailChangeRepository(db),
+		notifier:   notifier,
+		confirmURL: config.String("EMAIL_CHANGE_CONFIRM_URL", "http://localhost:3000/account/email-change/confirm"),
+		ttl:        config.Duration("EMAIL_CHANGE_TTL", DefaultEmailChangeTTL),
+	}
+}
+
+// Request tạo yêu cầu đổi email sau khi xác nhận mật khẩu, hủy yêu cầu đang chờ trước đó và gửi
+// link xác nhận tới địa chỉ hiện tại và địa chỉ mới
+func (s *EmailChangeService) Request(ctx context.Context, userID uint, newEmail, currentPassword string) (*models.EmailChange, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if err := password.Compare(user.Password, currentPassword); err != nil {
+		return nil, ErrInvalidPassword
+	}
+	newEmail = strings.TrimSpace(newEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		return nil, ErrSameEmail
+	}
+	taken, err := s.users.EmailTaken(ctx, newEmail)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrEmailTaken
+	}
+
+	oldToken, err := newLinkToken()
+	if err != nil {
+		return nil, err
+	}
+	newToken, err := newLinkToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	change := &models.EmailChange{
+		UserID:       user.ID,
+		OldEmail:     user.Email,
+		NewEmail:     newEmail,
+		OldTokenHash: hashLinkToken(oldToken),
+		NewTokenHash: hashLinkToken(newToken),
+		ExpiresAt:    now.Add(s.ttl),
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.changes.CancelPending(ctx, user.ID, now); err != nil {
+			return err
+		}
+		return s.changes.Create(ctx, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifier.Notify(ctx, s.confirmation(change, change.OldEmail, oldToken,
+		"Confirm your email change",
+		fmt.Sprintf("A request was made to change the email address of your account from %s to %s.", change.OldEmail, change.NewEmail)))
+	s.notifier.Notify(ctx, s.confirmation(change, change.NewEmail, newToken,
+		"Confirm your new email address",
+		fmt.Sprintf("A request was made to use %s as the email address of your account.", change.NewEmail)))
+	return change, nil
+}
+
+// confirmation dựng email chứa link xác nhận gửi tới một trong hai địa chỉ
+func (s *EmailChangeService) confirmation(change *models.EmailChange, to, token, title, intro string) notify.Notification {
+	link := s.confirmURL + "?token=" + url.QueryEscape(token)
+	body := intro + "\n\n" +
+		"To approve it, open the link below before " + change.ExpiresAt.Format(time.RFC1123) + ":\n" + link + "\n\n" +
+		"The email address only changes after both the current and the new address have confirmed, " +
+		"and you will then be signed out of all devices. If you did not request this, ignore this email and change your password."
+	return notify.Notification{
+		UserID: change.UserID,
+		Event:  notify.EventEmailChange,
+		Title:  title,
+		Body:   body,
+		Data:   map[string]string{"email_change_id": strconv.FormatUint(uint64(change.ID), 10)},
+		Email:  to,
+	}
+}
+
+// Confirm ghi nhận xác nhận bằng token của một trong hai địa chỉ. Khi cả hai đã xác nhận, email của
+// user được đổi và token_version tăng lên để thu hồi các phiên hiện có; completed cho biết điều đó.
+func (s *EmailChangeService) Confirm(ctx context.Context, token string) (change *models.EmailChange, completed bool, err error) {
+	hash := hashLinkToken(token)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		found, err := s.changes.GetByTokenHash(ctx, hash)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrEmailChangeNotFound
+			}
+			return err
+		}
+		now := time.Now()
+		if !found.Pending(now) {
+			return ErrEmailChangeNotFound
+		}
+		column := "new_confirmed_at"
+		if found.OldTokenHash == hash {
+			column = "old_confirmed_at"
+		}
+		if err := s.changes.Confirm(ctx, found.ID, column, now); err != nil {
+			return err
+		}
+		// Đọc lại sau khi cập nhật: lần xác nhận của địa chỉ kia có thể vừa được commit
+		if change, err = s.changes.Get(ctx, found.ID); err != nil {
+			return err
+		}
+		if change.CompletedAt != nil || change.OldConfirmedAt == nil || change.NewConfirmedAt == nil {
+			return nil
+		}
+
+		taken, err := s.users.EmailTaken(ctx, change.NewEmail)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrEmailTaken
+		}
+		ok, err := s.changes.MarkCompleted(ctx, change.ID, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrEmailChangeNotFound
+		}
+		if err := s.users.ChangeEmail(ctx, change.UserID, change.NewEmail); err != nil {
+			return err
+		}
+		change.CompletedAt = &now
+		completed = true
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return change, completed, nil
+}
+
+// newLinkToken sinh token ngẫu nhiên cho link gửi qua email (xác nhận đổi email, lời mời)
+func newLinkToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashLinkToken băm token để lưu và tra cứu; token gốc chỉ nằm trong email
+func hashLinkToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// DefaultSyncBatchSize là số dòng mặc định trong một transaction của API đồng bộ tồn kho và giá
+const DefaultSyncBatchSize = 100
+
+// InventorySyncService nhận tồn kho và giá từ hệ thống kho bên ngoài theo SKU. Các dòng được ghi theo lô,
+// mỗi lô một transaction; lô lỗi được rollback và các dòng của lô được báo failed, các lô khác vẫn được ghi.
+// Mỗi dòng chỉ làm mới dòng read model danh sách của sản phẩm đó. Sau khi lô commit, read model đã được
+// cập nhật và các sự kiện ProductUpdated, StockChanged, PriceChanged được phát như khi admin sửa sản phẩm.
+type InventorySyncService struct {
+	tx        *repository.TxManager
+	products  *repository.ProductRepository
+	bus       *events.Bus
+	batchSize int
+}
+
+// NewInventorySyncService tạo service với kích thước lô đọc từ INTEGRATION_BATCH_SIZE (mặc định DefaultSyncBatchSize)
+func NewInventorySyncService(db *gorm.DB, bus *events.Bus) *InventorySyncService {
+	batchSize := config.Int("INTEGRATION_BATCH_SIZE", DefaultSyncBatchSize)
+	if batchSize == 0 {
+		batchSize = DefaultSyncBatchSize
+	}
+	return &InventorySyncService{
+		tx:        repository.NewTxManager(db),
+		products:  repository.NewProductRepository(db),
+		bus:       bus,
+		batchSize: batchSize,
+	}
+}
+
+// SyncStock đặt tồn kho của các sản phẩm theo SKU
+func (s *InventorySyncService) SyncStock(ctx context.Context, items []models.InventorySyncItem) *models.SyncResponse {
+	skus := make([]string, len(items))
+	for i, item := range items {
+		skus[i] = item.SKU
+	}
+	return s.sync(ctx, skus, func(ctx context.Context, product *models.Product, row int) (bool, error) {
+		stock := *items[row].Stock
+		if product.Stock == stock {
+			return false, nil
+		}
+		product.Stock = stock
+		return true, s.products.UpdateStock(ctx, product.ID, stock)
+	})
+}
+
+// SyncPrices đặt giá gốc của các sản phẩm theo SKU; sản phẩm đang có lịch đổi giá vẫn bán theo giá của lịch
+func (s *InventorySyncService) SyncPrices(ctx context.Context, items []models.PriceSyncItem) *models.SyncResponse {
+	skus := make([]string, len(items))
+	for i, item := range items {
+		skus[i] = item.SKU
+	}
+	return s.sync(ctx, skus, func(ctx context.Context, product *models.Product, row int) (bool, error) {
+		price := *items[row].Price
+		if product.Price == price {
+			return false, nil
+		}
+		product.Price = price
+		return true, s.products.UpdatePrice(ctx, product.ID, price)
+	})
+}
+
+// sync ghi các dòng theo lô. apply sửa sản phẩm (đã khóa) theo dòng row của request, ghi xuống database và
+// trả về false nếu giá trị không đổi.
+func (s *InventorySyncService) sync(ctx context.Context, skus []string, apply func(ctx context.Context, product *models.Product, row int) (bool, error)) *models.SyncResponse {
+	resp := &models.SyncResponse{Results: make([]models.SyncRowResult, len(skus))}
+	for start := 0; start < len(skus); start += s.batchSize {
+		end := min(start+s.batchSize, len(skus))
+		var before, after []models.Product
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			before, after = nil, nil
+			products, err := s.products.GetBySKUsForUpdate(ctx, skus[start:end])
+			if err != nil {
+				return err
+			}
+			bySKU := make(map[string]*models.Product, len(products))
+			for i := range products {
+				bySKU[*products[i].SKU] = &products[i]
+			}
+
+			for row := start; row < end; row++ {
+				result := models.SyncRowResult{SKU: skus[row], Status: models.SyncNotFound}
+				if product, ok := bySKU[skus[row]]; ok {
+					result.ProductID = product.ID
+					old := *product
+					updated, err := apply(ctx, product, row)
+					if err != nil {
+						return err
+					}
+					result.Status = models.SyncUnchanged
+					if updated {
+						result.Status = models.SyncUpdated
+						product.Version++
+						before, after = append(before, old), append(after, *product)
+					}
+				}
+				resp.Results[row] = result
+			}
+			return nil
+		})
+		if err != nil {
+			for row := start; row < end; row++ {
+				resp.Results[row] = models.SyncRowResult{SKU: skus[row], Status: models.SyncFailed, Error: err.Error()}
+			}
+			continue
+		}
+		for i := range after {
+			publishProductUpdate(ctx, s.bus, before[i], after[i])
+		}
+	}
+
+	for _, result := range resp.Results {
+		switch result.Status {
+		case models.SyncUpdated:
+			resp.Updated++
+		case models.SyncUnchanged:
+			resp.Unchanged++
+		case models.SyncNotFound:
+			resp.NotFound++
+		case models.SyncFailed:
+			resp.Failed++
+		}
+	}
+	return resp
+}
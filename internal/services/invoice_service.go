@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/einvoice"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/tax"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvoiceNotFound  = errors.New("invoice not found")
+	ErrInvoiceNotDraft  = errors.New("invoice has already been issued")
+	ErrInvoiceNotIssued = errors.New("only issued invoices can be cancelled")
+)
+
+// InvoiceService lập, phát hành và hủy hóa đơn. Số hóa đơn chỉ được cấp lúc phát hành,
+// trong cùng transaction với việc chuyển trạng thái nên dãy số không bị bỏ trống.
+type InvoiceService struct {
+	tx       *repository.TxManager
+	repo     *repository.InvoiceRepository
+	products *repository.ProductRepository
+	taxes    *TaxService
+	config   einvoice.Config
+}
+
+func NewInvoiceService(db *gorm.DB, config einvoice.Config, taxConfig tax.Config) *InvoiceService {
+	return &InvoiceService{
+		tx:       repository.NewTxManager(db),
+		repo:     repository.NewInvoiceRepository(db),
+		products: repository.NewProductRepository(db),
+		taxes:    NewTaxService(db, taxConfig),
+		config:   config,
+	}
+}
+
+// Config trả về cấu hình phát hành (thông tin người bán dùng khi xuất hóa đơn)
+func (s *InvoiceService) Config() einvoice.Config {
+	return s.config
+}
+
+// CreateDraft lập hóa đơn nháp; tên và giá sản phẩm được chụp lại tại thời điểm lập.
+// Thuế suất từng dòng lấy theo quy tắc thuế của danh mục (hóa đơn không gắn với tỉnh nên chỉ quy
+// tắc không giới hạn tỉnh được áp dụng), trừ khi request chỉ định một thuế suất chung. Nếu giá niêm
+// yết đã gồm thuế, đơn giá trên hóa đơn là giá chưa thuế.
+func (s *InvoiceService) CreateDraft(ctx context.Context, req *models.CreateInvoiceRequest) (*models.Invoice, error) {
+	invoice := &models.Invoice{
+		Status:       models.InvoiceDraft,
+		UserID:       req.UserID,
+		BuyerName:    req.BuyerName,
+		BuyerEmail:   req.BuyerEmail,
+		BuyerTaxCode: req.BuyerTaxCode,
+		BuyerAddress: req.BuyerAddress,
+		Currency:     "VND",
+	}
+	engine, err := s.taxes.Engine(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range req.Items {
+		product, err := s.products.GetByID(ctx, line.ProductID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: %d", ErrProductNotFound, line.ProductID)
+			}
+			return nil, err
+		}
+		rate := engine.Rate(product.Category, "")
+		if req.TaxRate != nil {
+			rate = *req.TaxRate
+		}
+		unitPrice := product.Price
+		if engine.PricesIncludeTax() {
+			unitPrice, _ = tax.Split(product.Price, rate, true)
+		}
+		invoice.Items = append(invoice.Items, models.InvoiceItem{
+			ProductID: product.ID,
+			Name:      product.Name,
+			Quantity:  line.Quantity,
+			UnitPrice: unitPrice,
+			TaxRate:   rate,
+		})
+	}
+	einvoice.Totals(invoice)
+
+	if err := s.repo.Create(ctx, invoice); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// Get lấy hóa đơn kèm dòng hàng
+func (s *InvoiceService) Get(ctx context.Context, id uint) (*models.Invoice, error) {
+	invoice, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvoiceNotFound
+	}
+	return invoice, err
+}
+
+// List lấy danh sách hóa đơn
+func (s *InvoiceService) List(ctx context.Context, query *models.InvoiceQueryParams) ([]models.Invoice, int64, error) {
+	return s.repo.GetAll(ctx, query)
+}
+
+// Issue phát hành hóa đơn nháp: cấp số tiếp theo của ký hiệu trong năm, khóa nội dung bằng mã băm
+func (s *InvoiceService) Issue(ctx context.Context, id uint) (*models.Invoice, error) {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		invoice, err := s.repo.GetForUpdate(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvoiceNotFound
+			}
+			return err
+		}
+		if invoice.Status != models.InvoiceDraft {
+			return ErrInvoiceNotDraft
+		}
+
+		issuedAt := time.Now().In(einvoice.Location)
+		invoice.FiscalPeriod = issuedAt.Year()
+		invoice.Series = s.config.Series(invoice.FiscalPeriod)
+		seq, err := s.repo.NextNumber(ctx, invoice.Series, invoice.FiscalPeriod)
+		if err != nil {
+			return err
+		}
+		invoice.Sequence = &seq
+		invoice.Number = einvoice.FormatNumber(seq)
+		invoice.IssuedAt = &issuedAt
+		invoice.ContentHash = einvoice.ContentHash(invoice)
+		return s.repo.MarkIssued(ctx, invoice)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, id)
+}
+
+// Cancel hủy hóa đơn đã phát hành. Hóa đơn bị hủy vẫn giữ số và nội dung ban đầu.
+func (s *InvoiceService) Cancel(ctx context.Context, id uint, reason string) (*models.Invoice, error) {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		invoice, err := s.repo.GetForUpdate(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvoiceNotFound
+			}
+			return err
+		}
+		if invoice.Status != models.InvoiceIssued {
+			return ErrInvoiceNotIssued
+		}
+
+		now := time.Now()
+		invoice.CancelledAt = &now
+		invoice.CancelReason = reason
+		return s.repo.MarkCancelled(ctx, invoice)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, id)
+}
+
+// DeleteDraft xóa hóa đơn nháp; hóa đơn đã phát hành không thể xóa
+func (s *InvoiceService) DeleteDraft(ctx context.Context, id uint) error {
+	invoice, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if invoice.Status != models.InvoiceDraft {
+		return ErrInvoiceNotDraft
+	}
+	deleted, err := s.repo.DeleteDraft(ctx, id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrInvoiceNotDraft
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/notify"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ErrLoginChallengeInvalid: token xác nhận đăng nhập không tồn tại, đã dùng hoặc đã hết hạn
+var ErrLoginChallengeInvalid = errors.New("login confirmation link is invalid or expired")
+
+// DefaultLoginConfirmTTL là thời gian link xác nhận đăng nhập đáng ngờ còn hiệu lực
+const DefaultLoginConfirmTTL = 15 * time.Minute
+
+// loginHistoryLimit là số lần đăng nhập gần nhất trả về cho user
+const loginHistoryLimit = 50
+
+// LoginAttempt là thiết bị và vị trí của một lần đăng nhập
+type LoginAttempt struct {
+	DeviceFingerprint string // do client gửi, có thể rỗng
+	UserAgent         string
+	IP                string
+	Country           string // mã quốc gia ISO 3166-1 alpha-2, rỗng khi không biết
+}
+
+// LoginDecision là kết quả kiểm tra lần đăng nhập. ConfirmationRequired cho biết token chưa được phát và
+// user phải xác nhận bằng link gửi qua email trước ExpiresAt.
+type LoginDecision struct {
+	Reasons              []string
+	ConfirmationRequired bool
+	ExpiresAt            time.Time
+}
+
+// LoginSecurityService ghi lại thiết bị và quốc gia của mỗi lần đăng nhập thành công và phát hiện đăng nhập
+// đáng ngờ (thiết bị hoặc quốc gia mới). Lần đăng nhập đáng ngờ phát sự kiện SuspiciousLogin để email cảnh
+// báo được gửi cho user; nếu bật LOGIN_CONFIRM_SUSPICIOUS thì token chỉ được phát sau khi user xác nhận.
+type LoginSecurityService struct {
+	tx                  *repository.TxManager
+	logins              *repository.LoginEventRepository
+	users               *repository.UserRepository
+	notifier            *notify.Dispatcher
+	bus                 *events.Bus
+	countryHeader       string
+	requireConfirmation bool
+	confirmURL          string
+	ttl                 time.Duration
+}
+
+// NewLoginSecurityService tạo service; quốc gia đọc từ header LOGIN_COUNTRY_HEADER (mặc định CF-IPCountry,
+// do Cloudflare gắn), xác nhận qua email bật bằng LOGIN_CONFIRM_SUSPICIOUS=true với link là
+// LOGIN_CONFIRM_URL kèm ?token= và thời hạn LOGIN_CONFIRM_TTL
+func NewLoginSecurityService(db *gorm.DB, bus *events.Bus, notifier *notify.Dispatcher) *LoginSecurityService {
+	return &LoginSecurityService{
+		tx:                  repository.NewTxManager(db),
+		logins:              repository.NewLoginEventRepository(db),
+		users:               repository.NewUserRepository(db),
+		notifier:            notifier,
+		bus:                 bus,
+		countryHeader:       config.String("LOGIN_COUNTRY_HEADER", "CF-IPCountry"),
+		requireConfirmation: config.String("LOGIN_CONFIRM_SUSPICIOUS", "") == "true",
+		confirmURL:          config.String("LOGIN_CONFIRM_URL", "http://localhost:3000/login/confirm"),
+		ttl:                 config.Duration("LOGIN_CONFIRM_TTL", DefaultLoginConfirmTTL),
+	}
+}
+
+// Country lấy mã quốc gia của request từ header đã cấu hình; giá trị không phải mã hai chữ cái (ví dụ XX
+// khi Cloudflare không biết, T1 với Tor) được coi là không biết
+func (s *LoginSecurityService) Country(header http.Header) string {
+	if s.countryHeader == "" {
+		return ""
+	}
+	country := strings.ToUpper(strings.TrimSpace(header.Get(s.countryHeader)))
+	if len(country) != 2 || country == "XX" || country[0] < 'A' || country[0] > 'Z' || country[1] < 'A' || country[1] > 'Z' {
+		return ""
+	}
+	return country
+}
+
+// Check kiểm tra lần đăng nhập đúng mật khẩu của user. Lần đăng nhập bình thường, hoặc đáng ngờ khi không
+// bắt xác nhận, được ghi vào login_events; lần đăng nhập đáng ngờ cần xác nhận được lưu chờ và link xác nhận
+// được gửi tới email của user.
+func (s *LoginSecurityService) Check(ctx context.Context, user *models.User, attempt LoginAttempt) (*LoginDecision, error) {
+	fingerprint := loginFingerprint(attempt)
+	reasons, err := s.suspiciousReasons(ctx, user.ID, fingerprint, attempt.Country)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	decision := &LoginDecision{Reasons: reasons}
+	if len(reasons) > 0 && s.requireConfirmation {
+		token, err := newLinkToken()
+		if err != nil {
+			return nil, err
+		}
+		challenge := &models.LoginChallenge{
+			UserID:      user.ID,
+			TokenHash:   hashLinkToken(token),
+			Fingerprint: fingerprint,
+			UserAgent:   truncate(attempt.UserAgent, 512),
+			IP:          attempt.IP,
+			Country:     attempt.Country,
+			Reasons:     strings.Join(reasons, ","),
+			ExpiresAt:   now.Add(s.ttl),
+		}
+		if err := s.logins.CreateChallenge(ctx, challenge); err != nil {
+			return nil, err
+		}
+		s.notifier.Notify(ctx, s.confirmation(user, challenge, token))
+		decision.ConfirmationRequired, decision.ExpiresAt = true, challenge.ExpiresAt
+	} else {
+		err := s.logins.Create(ctx, &models.LoginEvent{
+			UserID:      user.ID,
+			Fingerprint: fingerprint,
+			UserAgent:   truncate(attempt.UserAgent, 512),
+			IP:          attempt.IP,
+			Country:     attempt.Country,
+			Reasons:     strings.Join(reasons, ","),
+			CreatedAt:   now,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(reasons) > 0 {
+		s.bus.Publish(ctx, events.SuspiciousLogin{
+			User:                 *user,
+			IP:                   attempt.IP,
+			UserAgent:            attempt.UserAgent,
+			Country:              attempt.Country,
+			Reasons:              reasons,
+			ConfirmationRequired: decision.ConfirmationRequired,
+			At:                   now,
+		})
+	}
+	return decision, nil
+}
+
+// suspiciousReasons so thiết bị và quốc gia với các lần đăng nhập trước của user. Lần đăng nhập đầu tiên
+// được ghi lại (kể cả lần đầu có quốc gia) là mốc so sánh nên không bị coi là đáng ngờ.
+func (s *LoginSecurityService) suspiciousReasons(ctx context.Context, userID uint, fingerprint, country string) ([]string, error) {
+	seen, err := s.logins.HasAny(ctx, userID)
+	if err != nil || !seen {
+		return nil, err
+	}
+	var reasons []string
+	known, err := s.logins.HasFingerprint(ctx, userID, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		reasons = append(reasons, models.LoginReasonNewDevice)
+	}
+	if country != "" {
+		located, err := s.logins.HasCountry(ctx, userID, "")
+		if err != nil {
+			return nil, err
+		}
+		if located {
+			if known, err = s.logins.HasCountry(ctx, userID, country); err != nil {
+				return nil, err
+			}
+			if !known {
+				reasons = append(reasons, models.LoginReasonNewCountry)
+			}
+		}
+	}
+	return reasons, nil
+}
+
+// confirmation dựng email chứa link xác nhận lần đăng nhập đáng ngờ
+func (s *LoginSecurityService) confirmation(user *models.User, challenge *models.LoginChallenge, token string) notify.Notification {
+	link := s.confirmURL + "?token=" + url.QueryEscape(token)
+	body := "Someone signed in to your account with your password from " + describeLogin(challenge.UserAgent, challenge.IP, challenge.Country) + ".\n\n" +
+		"If this was you, open the link below before " + challenge.ExpiresAt.Format(time.RFC1123) + " to finish signing in:\n" + link + "\n\n" +
+		"If this was not you, do not open the link and change your password: someone else knows it."
+	return notify.Notification{
+		UserID: user.ID,
+		Event:  notify.EventLoginAlert,
+		Title:  "Confirm your sign-in",
+		Body:   body,
+		Data:   map[string]string{"login_challenge_id": strconv.FormatUint(uint64(challenge.ID), 10), "reasons": challenge.Reasons},
+		Email:  user.Email,
+	}
+}
+
+// Confirm xác nhận lần đăng nhập đáng ngờ bằng token trong link: lần đăng nhập được ghi vào login_events nên
+// thiết bị và quốc gia trở thành đã biết, và trả về user để phát token. Mỗi link chỉ dùng được một lần.
+func (s *LoginSecurityService) Confirm(ctx context.Context, token string) (*models.User, error) {
+	var user *models.User
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		challenge, err := s.logins.GetChallengeForUpdate(ctx, hashLinkToken(token))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrLoginChallengeInvalid
+		}
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if !challenge.Pending(now) {
+			return ErrLoginChallengeInvalid
+		}
+		if user, err = s.users.GetByID(ctx, challenge.UserID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrLoginChallengeInvalid
+			}
+			return err
+		}
+		if err := s.logins.ConfirmChallenge(ctx, challenge.ID, now); err != nil {
+			return err
+		}
+		return s.logins.Create(ctx, &models.LoginEvent{
+			UserID:           challenge.UserID,
+			Fingerprint:      challenge.Fingerprint,
+			UserAgent:        challenge.UserAgent,
+			IP:               challenge.IP,
+			Country:          challenge.Country,
+			Reasons:          challenge.Reasons,
+			ConfirmedByEmail: true,
+			CreatedAt:        now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// History lấy các lần đăng nhập gần nhất của user, mới nhất trước
+func (s *LoginSecurityService) History(ctx context.Context, userID uint) ([]models.LoginEvent, error) {
+	return s.logins.ListByUser(ctx, userID, loginHistoryLimit)
+}
+
+// loginFingerprint băm định danh thiết bị do client gửi, hoặc User-Agent khi không có, để lưu và so sánh
+func loginFingerprint(attempt LoginAttempt) string {
+	if fp := strings.TrimSpace(attempt.DeviceFingerprint); fp != "" {
+		return hashLinkToken("device:" + fp)
+	}
+	return hashLinkToken("ua:" + strings.TrimSpace(attempt.UserAgent))
+}
+
+// describeLogin mô tả thiết bị, IP và quốc gia của lần đăng nhập cho email
+func describeLogin(userAgent, ip, country string) string {
+	if userAgent == "" {
+		userAgent = "an unknown device"
+	}
+	where := ip
+	if country != "" {
+		where = fmt.Sprintf("%s, %s", ip, country)
+	}
+	return fmt.Sprintf("%s (%s)", userAgent, where)
+}
+
+// truncate cắt s còn tối đa n byte để vừa cột
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
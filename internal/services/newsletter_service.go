@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/notify"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ErrNewsletterTokenInvalid: token xác nhận hoặc hủy đăng ký không tồn tại, đã dùng hoặc đã hết hạn
+var ErrNewsletterTokenInvalid = errors.New("newsletter link is invalid or expired")
+
+// Giá trị mặc định của thời hạn link xác nhận và khoảng cách tối thiểu giữa hai email xác nhận tới cùng địa chỉ
+const (
+	DefaultNewsletterConfirmTTL     = 48 * time.Hour
+	DefaultNewsletterResendInterval = 10 * time.Minute
+)
+
+// newsletterExportBatch là số địa chỉ đọc mỗi lần khi xuất danh sách
+const newsletterExportBatch = 1000
+
+// NewsletterService quản lý đăng ký nhận bản tin theo cơ chế double opt-in: địa chỉ mới nhận email chứa link
+// xác nhận và chỉ được xuất cho hệ thống gửi bản tin sau khi xác nhận
+type NewsletterService struct {
+	tx             *repository.TxManager
+	repo           *repository.NewsletterRepository
+	notifier       *notify.Dispatcher
+	confirmURL     string
+	unsubscribeURL string
+	ttl            time.Duration
+	resendInterval time.Duration
+}
+
+// NewNewsletterService tạo service; link xác nhận là NEWSLETTER_CONFIRM_URL và link hủy đăng ký là
+// NEWSLETTER_UNSUBSCRIBE_URL kèm ?token=. Thời hạn link xác nhận đọc từ NEWSLETTER_CONFIRM_TTL, khoảng cách
+// tối thiểu giữa hai email xác nhận tới cùng địa chỉ từ NEWSLETTER_RESEND_INTERVAL.
+func NewNewsletterService(db *gorm.DB, notifier *notify.Dispatcher) *NewsletterService {
+	return &NewsletterService{
+		tx:             repository.NewTxManager(db),
+		repo:           repository.NewNewsletterRepository(db),
+		notifier:       notifier,
+		confirmURL:     config.String("NEWSLETTER_CONFIRM_URL", "http://localhost:3000/newsletter/confirm"),
+		unsubscribeURL: config.String("NEWSLETTER_UNSUBSCRIBE_URL", "http://localhost:3000/newsletter/unsubscribe"),
+		ttl:            config.Duration("NEWSLETTER_CONFIRM_TTL", DefaultNewsletterConfirmTTL),
+		resendInterval: config.Duration("NEWSLETTER_RESEND_INTERVAL", DefaultNewsletterResendInterval),
+	}
+}
+
+// Subscribe đăng ký địa chỉ email và gửi email xác nhận. Kết quả không cho biết địa chỉ đã đăng ký hay chưa:
+// địa chỉ đã xác nhận không nhận thêm email, địa chỉ chưa xác nhận hoặc đã hủy nhận link xác nhận mới (link
+// cũ hết hiệu lực) nhưng không quá một email mỗi resendInterval, để endpoint không bị dùng để spam một địa chỉ.
+func (s *NewsletterService) Subscribe(ctx context.Context, email string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		subscriber, err := s.repo.GetByEmail(ctx, email)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			unsubscribeToken, err := newLinkToken()
+			if err != nil {
+				return err
+			}
+			subscriber = &models.NewsletterSubscriber{Email: email, Status: models.NewsletterPending, UnsubscribeToken: unsubscribeToken}
+			return s.sendConfirmation(ctx, subscriber, now, s.repo.Create)
+		}
+		if err != nil {
+			return err
+		}
+
+		if subscriber.Status == models.NewsletterConfirmed {
+			return nil
+		}
+		if subscriber.ConfirmSentAt != nil && now.Sub(*subscriber.ConfirmSentAt) < s.resendInterval {
+			return nil
+		}
+		subscriber.Status = models.NewsletterPending
+		return s.sendConfirmation(ctx, subscriber, now, s.repo.Save)
+	})
+	if err != nil && isUniqueViolation(err) {
+		// Cùng địa chỉ vừa được đăng ký bởi một request khác, request đó đã gửi email xác nhận
+		return nil
+	}
+	return err
+}
+
+// sendConfirmation gán token xác nhận mới cho subscriber, lưu bằng save và đưa email xác nhận vào hàng đợi
+// job trong cùng transaction
+func (s *NewsletterService) sendConfirmation(ctx context.Context, subscriber *models.NewsletterSubscriber, now time.Time,
+	save func(context.Context, *models.NewsletterSubscriber) error) error {
+	token, err := newLinkToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := now.Add(s.ttl)
+	subscriber.ConfirmTokenHash = hashLinkToken(token)
+	subscriber.ConfirmExpiresAt = &expiresAt
+	subscriber.ConfirmSentAt = &now
+	if err := save(ctx, subscriber); err != nil {
+		return err
+	}
+
+	s.notifier.Notify(ctx, notify.Notification{
+		Event: notify.EventNewsletter,
+		Title: "Confirm your newsletter subscription",
+		Body: "We received a request to subscribe " + subscriber.Email + " to our newsletter.\n\n" +
+			"To start receiving it, confirm your subscription by opening the link below before " + expiresAt.Format(time.RFC1123) + ":\n" +
+			s.confirmURL + "?token=" + url.QueryEscape(token) + "\n\n" +
+			"If you did not request this, ignore this email and you will not be subscribed.",
+		Email: subscriber.Email,
+	})
+	return nil
+}
+
+// Confirm xác nhận địa chỉ bằng token trong email xác nhận; mỗi link chỉ dùng được một lần
+func (s *NewsletterService) Confirm(ctx context.Context, token string) (*models.NewsletterSubscriber, error) {
+	var subscriber *models.NewsletterSubscriber
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		subscriber, err = s.repo.GetByConfirmTokenHash(ctx, hashLinkToken(token))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNewsletterTokenInvalid
+		}
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if subscriber.Status != models.NewsletterPending || subscriber.ConfirmExpiresAt == nil || !now.Before(*subscriber.ConfirmExpiresAt) {
+			return ErrNewsletterTokenInvalid
+		}
+
+		subscriber.Status = models.NewsletterConfirmed
+		subscriber.ConfirmedAt = &now
+		subscriber.UnsubscribedAt = nil
+		subscriber.ConfirmTokenHash = ""
+		subscriber.ConfirmExpiresAt = nil
+		if err := s.repo.Save(ctx, subscriber); err != nil {
+			return err
+		}
+		s.notifier.Notify(ctx, notify.Notification{
+			Event: notify.EventNewsletter,
+			Title: "You are subscribed to our newsletter",
+			Body: "Thanks for confirming, " + subscriber.Email + " will now receive our newsletter.\n\n" +
+				"You can unsubscribe at any time by opening the link below:\n" + s.UnsubscribeLink(subscriber),
+			Email: subscriber.Email,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subscriber, nil
+}
+
+// Unsubscribe hủy đăng ký bằng token trong link hủy đăng ký; link không hết hạn và gọi lại trên địa chỉ đã
+// hủy không có tác dụng
+func (s *NewsletterService) Unsubscribe(ctx context.Context, token string) (*models.NewsletterSubscriber, error) {
+	var subscriber *models.NewsletterSubscriber
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		subscriber, err = s.repo.GetByUnsubscribeToken(ctx, token)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNewsletterTokenInvalid
+		}
+		if err != nil || subscriber.Status == models.NewsletterUnsubscribed {
+			return err
+		}
+
+		now := time.Now()
+		subscriber.Status = models.NewsletterUnsubscribed
+		subscriber.UnsubscribedAt = &now
+		subscriber.ConfirmTokenHash = ""
+		subscriber.ConfirmExpiresAt = nil
+		return s.repo.Save(ctx, subscriber)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subscriber, nil
+}
+
+// UnsubscribeLink là link hủy đăng ký của địa chỉ, đưa vào cuối mỗi bản tin
+func (s *NewsletterService) UnsubscribeLink(subscriber *models.NewsletterSubscriber) string {
+	return s.unsubscribeURL + "?token=" + url.QueryEscape(subscriber.UnsubscribeToken)
+}
+
+// ExportConfirmed gọi fn lần lượt với từng lô địa chỉ đã xác nhận, theo thứ tự đăng ký
+func (s *NewsletterService) ExportConfirmed(ctx context.Context, fn func([]models.NewsletterSubscriber) error) error {
+	return s.repo.EachConfirmed(ctx, newsletterExportBatch, fn)
+}
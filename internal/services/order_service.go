@@ -0,0 +1,498 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/promotion"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/shipping"
+	"github.com/NgTruong624/project_backend/internal/tax"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOrderNotFound   = errors.New("order not found")
+	ErrEmptyCart       = errors.New("cart is empty")
+	ErrOrderNotPending = errors.New("order is not awaiting payment")
+	// ErrInvalidOrderTransition được trả về khi trạng thái hiện tại của đơn không cho phép thao tác
+	ErrInvalidOrderTransition = errors.New("order status does not allow this transition")
+	ErrOrderPaidOffline       = errors.New("order is paid offline")
+)
+
+// OrderService đặt hàng từ giỏ và quản lý vòng đời đơn hàng
+type OrderService struct {
+	tx         *repository.TxManager
+	repo       *repository.OrderRepository
+	carts      *repository.CartRepository
+	products   *repository.ProductRepository
+	addresses  *repository.AddressRepository
+	promotions *repository.PromotionRepository
+	payments   *repository.PaymentRepository
+	users      *repository.UserRepository
+	shipping   *shipping.Calculator
+	taxes      *TaxService
+	bus        *events.Bus
+}
+
+func NewOrderService(db *gorm.DB, calculator *shipping.Calculator, taxConfig tax.Config, bus *events.Bus) *OrderService {
+	return &OrderService{
+		tx:         repository.NewTxManager(db),
+		repo:       repository.NewOrderRepository(db),
+		carts:      repository.NewCartRepository(db),
+		products:   repository.NewProductRepository(db),
+		addresses:  repository.NewAddressRepository(db),
+		promotions: repository.NewPromotionRepository(db),
+		payments:   repository.NewPaymentRepository(db),
+		users:      repository.NewUserRepository(db),
+		shipping:   calculator,
+		taxes:      NewTaxService(db, taxConfig),
+		bus:        bus,
+	}
+}
+
+// QuoteShipping báo giá các cách giao giỏ hàng hiện tại của user tới một địa chỉ trong sổ địa chỉ
+func (s *OrderService) QuoteShipping(ctx context.Context, userID, addressID uint) (*models.ShippingQuote, error) {
+	address, err := s.addresses.Get(ctx, userID, addressID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAddressNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	cart, err := s.carts.GetByUserID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrEmptyCart
+	}
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.carts.GetItems(ctx, cart.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrEmptyCart
+	}
+
+	req := shippingRequest(items, address)
+	options, err := s.shipping.Quote(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ShippingQuote{AddressID: address.ID, WeightGrams: req.WeightGrams, Subtotal: req.Value, Options: options}, nil
+}
+
+// shippingRequest dựng kiện hàng cần báo giá từ các dòng trong giỏ theo giá và cân nặng hiện tại
+func shippingRequest(items []models.CartItem, address *models.Address) shipping.Request {
+	req := shipping.Request{Destination: address.AddressDetails}
+	for _, item := range items {
+		req.WeightGrams += item.Product.WeightGrams * item.Quantity
+		req.Value += item.Product.EffectivePrice() * float64(item.Quantity)
+	}
+	return req
+}
+
+// Checkout tạo đơn hàng chờ thanh toán từ giỏ của user theo giá hiện tại, giữ tồn kho cho
+// từng dòng rồi làm trống giỏ. Nếu một sản phẩm không còn đủ hàng thì không có gì thay đổi
+// và trả về ErrInsufficientStock; client nên gọi revalidate giỏ để thấy số lượng còn lại.
+func (s *OrderService) Checkout(ctx context.Context, userID uint, req *models.CheckoutRequest) (*models.Order, error) {
+	order := &models.Order{
+		UserID:      userID,
+		Status:      models.OrderStatusPendingPayment,
+		PaymentType: req.PaymentType,
+		Currency:    "VND",
+		Note:        req.Note,
+	}
+	if order.PaymentType == "" {
+		order.PaymentType = models.OrderPaymentOnline
+	}
+	var stock []events.StockChanged
+	// Đơn hàng, lịch sử trạng thái, giữ tồn kho và làm trống giỏ nằm trong cùng một transaction
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		cart, err := s.carts.GetByUserID(ctx, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrEmptyCart
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.carts.Lock(ctx, cart.ID); err != nil {
+			return err
+		}
+		items, err := s.carts.GetItems(ctx, cart.ID)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return ErrEmptyCart
+		}
+		address, err := s.addresses.Get(ctx, userID, req.AddressID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAddressNotFound
+		}
+		if err != nil {
+			return err
+		}
+		order.ShippingAddressID = &address.ID
+		order.ShippingAddress = address.AddressDetails
+
+		// Báo giá lại theo giỏ đã khóa thay vì tin phí client gửi lên
+		option, err := s.shipping.Select(ctx, shippingRequest(items, address), req.ShippingOption)
+		if err != nil {
+			return err
+		}
+		order.ShippingProvider = option.Provider
+		order.ShippingService = option.Service
+		order.ShippingFee = option.Fee
+
+		engine, err := s.taxes.Engine(ctx)
+		if err != nil {
+			return err
+		}
+		// Khuyến mãi tính trên giá niêm yết; thuế tính trên thành tiền sau giảm giá
+		now := time.Now()
+		promotions, err := s.promotions.ListActive(ctx, now)
+		if err != nil {
+			return err
+		}
+		promoLines := make([]promotion.Line, len(items))
+		for i, item := range items {
+			promoLines[i] = promotion.Line{ProductID: item.ProductID, Category: item.Product.Category, UnitPrice: item.Product.EffectivePrice(), Quantity: item.Quantity}
+		}
+		discounts := promotion.Evaluate(promotions, promoLines, now)
+		for _, applied := range discounts.Applied {
+			order.Discounts = append(order.Discounts, models.OrderDiscount{
+				PromotionID: applied.PromotionID, Name: applied.Name, Type: applied.Type, Amount: applied.Amount,
+			})
+		}
+
+		lines := make([]tax.Line, len(items))
+		for i, item := range items {
+			lines[i] = tax.Line{Category: item.Product.Category, Amount: item.Product.EffectivePrice()*float64(item.Quantity) - discounts.LineDiscounts[i]}
+		}
+		taxes := engine.Apply(lines, address.Province)
+		order.PricesIncludeTax = engine.PricesIncludeTax()
+
+		for i, item := range items {
+			if item.Product.Status != models.ProductStatusPublished {
+				return fmt.Errorf("%w: %s", ErrProductUnavailable, item.Product.Name)
+			}
+			ok, err := s.products.ReserveStock(ctx, item.ProductID, item.Quantity)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("%w: %s", ErrInsufficientStock, item.Product.Name)
+			}
+			order.Items = append(order.Items, models.OrderItem{
+				ProductID:      item.ProductID,
+				Name:           item.Product.Name,
+				Quantity:       item.Quantity,
+				UnitPrice:      item.Product.EffectivePrice(),
+				Amount:         promoLines[i].UnitPrice * float64(item.Quantity),
+				DiscountAmount: discounts.LineDiscounts[i],
+				TaxRate:        taxes[i].Rate,
+				TaxAmount:      taxes[i].Tax,
+			})
+			order.Subtotal += promoLines[i].UnitPrice * float64(item.Quantity)
+			order.TaxAmount += taxes[i].Tax
+			stock = append(stock, events.StockChanged{
+				ProductID: item.ProductID, Name: item.Product.Name,
+				OldStock: item.Product.Stock, NewStock: item.Product.Stock - item.Quantity,
+			})
+		}
+		order.DiscountAmount = discounts.Total
+		order.Total = order.Subtotal - order.DiscountAmount + order.ShippingFee
+		if !order.PricesIncludeTax {
+			order.Total += order.TaxAmount
+		}
+
+		if err := s.repo.Create(ctx, order); err != nil {
+			return err
+		}
+		if err := s.repo.AddHistory(ctx, &models.OrderStatusHistory{OrderID: order.ID, ToStatus: order.Status, ActorID: &userID, CreatedAt: order.CreatedAt}); err != nil {
+			return err
+		}
+		return s.carts.ClearItems(ctx, cart.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, e := range stock {
+		e.ChangedAt = now
+		s.bus.Publish(ctx, e)
+	}
+	s.bus.Publish(ctx, events.OrderPlaced{Order: *order})
+	return order, nil
+}
+
+// Get lấy đơn hàng; userID khác 0 thì chỉ trả về đơn của user đó
+func (s *OrderService) Get(ctx context.Context, userID, id uint) (*models.Order, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && userID != 0 && order.UserID != userID) {
+		return nil, ErrOrderNotFound
+	}
+	return order, err
+}
+
+// ListByUser lấy một trang đơn hàng của user, mới nhất trước
+func (s *OrderService) ListByUser(ctx context.Context, userID uint, query *models.OrderQueryParams) ([]models.Order, int64, error) {
+	return s.repo.ListByUser(ctx, userID, query)
+}
+
+// AdminList lấy một trang đơn hàng theo bộ lọc của admin, mỗi đơn kèm khách đặt đơn
+func (s *OrderService) AdminList(ctx context.Context, query *models.AdminOrderQueryParams) ([]models.AdminOrder, int64, error) {
+	orders, total, err := s.repo.GetAll(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	var userIDs []uint
+	for _, order := range orders {
+		if !slices.Contains(userIDs, order.UserID) {
+			userIDs = append(userIDs, order.UserID)
+		}
+	}
+	users, err := s.users.GetByIDsUnscoped(ctx, userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	customers := make(map[uint]*models.OrderCustomer, len(users))
+	for _, user := range users {
+		customers[user.ID] = orderCustomer(&user)
+	}
+	result := make([]models.AdminOrder, len(orders))
+	for i, order := range orders {
+		result[i] = models.AdminOrder{Order: order, Customer: customers[order.UserID]}
+	}
+	return result, total, nil
+}
+
+// AdminGet lấy chi tiết đơn hàng cho admin: khách, các giao dịch thanh toán kèm hoàn tiền và thông tin giao hàng
+func (s *OrderService) AdminGet(ctx context.Context, id uint) (*models.AdminOrderDetail, error) {
+	order, err := s.Get(ctx, 0, id)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.payments.ListByOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	detail := &models.AdminOrderDetail{
+		AdminOrder: models.AdminOrder{Order: *order},
+		Payments:   payments,
+		Shipment: models.OrderShipment{
+			Provider: order.ShippingProvider, Service: order.ShippingService, Fee: order.ShippingFee,
+			Address: order.ShippingAddress, Carrier: order.Carrier, TrackingNumber: order.TrackingNumber,
+			FulfilledAt: order.FulfilledAt, DeliveredAt: order.DeliveredAt,
+		},
+	}
+	user, err := s.users.GetByIDUnscoped(ctx, order.UserID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if user != nil {
+		detail.Customer = orderCustomer(user)
+	}
+	return detail, nil
+}
+
+func orderCustomer(user *models.User) *models.OrderCustomer {
+	return &models.OrderCustomer{ID: user.ID, Username: user.Username, Email: user.Email, FullName: user.FullName}
+}
+
+// BulkTransition chuyển nhiều đơn sang cùng một trạng thái (fulfilled, delivered hoặc cancelled) như các
+// thao tác trên từng đơn. Mỗi đơn được chuyển trong transaction riêng nên đơn lỗi (không tồn tại, trạng
+// thái không cho phép) không chặn các đơn còn lại; ID trùng chỉ được xử lý một lần.
+func (s *OrderService) BulkTransition(ctx context.Context, adminID uint, req *models.BulkOrderStatusRequest) *models.BulkOrderStatusResponse {
+	response := &models.BulkOrderStatusResponse{Results: []models.BulkOrderStatusResult{}}
+	var seen []uint
+	for _, id := range req.OrderIDs {
+		if slices.Contains(seen, id) {
+			continue
+		}
+		seen = append(seen, id)
+
+		var order *models.Order
+		var err error
+		switch req.Status {
+		case models.OrderStatusFulfilled:
+			order, err = s.Fulfill(ctx, adminID, id, &models.FulfillOrderRequest{Note: req.Note})
+		case models.OrderStatusDelivered:
+			order, err = s.Deliver(ctx, adminID, id, req.Note)
+		case models.OrderStatusCancelled:
+			order, err = s.AdminCancel(ctx, adminID, id, req.Note)
+		default:
+			err = fmt.Errorf("%w: cannot move orders to %s in bulk", ErrInvalidOrderTransition, req.Status)
+		}
+
+		result := models.BulkOrderStatusResult{OrderID: id}
+		if err != nil {
+			result.Error = err.Error()
+			response.Failed++
+		} else {
+			result.Updated, result.Status = true, order.Status
+			response.Updated++
+		}
+		response.Results = append(response.Results, result)
+	}
+	return response
+}
+
+// History lấy lịch sử trạng thái của đơn; userID khác 0 thì chỉ cho đơn của user đó
+func (s *OrderService) History(ctx context.Context, userID, id uint) ([]models.OrderStatusHistory, error) {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListHistory(ctx, id)
+}
+
+// Cancel hủy đơn còn chờ thanh toán của user và trả lại tồn kho đã giữ
+func (s *OrderService) Cancel(ctx context.Context, userID, id uint) (*models.Order, error) {
+	return s.cancel(ctx, userID, userID, id, "")
+}
+
+// AdminCancel hủy đơn còn chờ thanh toán (Admin), ví dụ đơn chuyển khoản quá hạn không thanh toán
+func (s *OrderService) AdminCancel(ctx context.Context, adminID, id uint, note string) (*models.Order, error) {
+	return s.cancel(ctx, 0, adminID, id, note)
+}
+
+// Fulfill chuyển đơn sang fulfilled khi đã giao cho đơn vị vận chuyển (Admin)
+func (s *OrderService) Fulfill(ctx context.Context, adminID, id uint, req *models.FulfillOrderRequest) (*models.Order, error) {
+	return s.transition(ctx, adminID, id, func(order *models.Order, now time.Time) orderTransition {
+		order.Carrier = req.Carrier
+		order.TrackingNumber = req.TrackingNumber
+		order.FulfilledAt = &now
+		return orderTransition{To: models.OrderStatusFulfilled, Note: req.Note, Fields: map[string]interface{}{
+			"carrier": req.Carrier, "tracking_number": req.TrackingNumber, "fulfilled_at": now,
+		}}
+	})
+}
+
+// Deliver chuyển đơn sang delivered khi khách đã nhận hàng (Admin)
+func (s *OrderService) Deliver(ctx context.Context, adminID, id uint, note string) (*models.Order, error) {
+	return s.transition(ctx, adminID, id, func(order *models.Order, now time.Time) orderTransition {
+		order.DeliveredAt = &now
+		return orderTransition{To: models.OrderStatusDelivered, Note: note, Fields: map[string]interface{}{"delivered_at": now}}
+	})
+}
+
+// transition khóa đơn và chuyển trạng thái theo bước do build trả về
+func (s *OrderService) transition(ctx context.Context, actorID, id uint, build func(order *models.Order, now time.Time) orderTransition) (*models.Order, error) {
+	var order *models.Order
+	var changed *events.OrderStatusChanged
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = s.repo.GetForUpdate(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOrderNotFound
+		}
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		t := build(order, now)
+		t.At = now
+		t.ActorID = &actorID
+		changed, err = transitionOrder(ctx, s.repo, order, t)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(ctx, *changed)
+	return order, nil
+}
+
+// cancel hủy đơn và trả lại tồn kho; userID bằng 0 là admin hủy đơn của bất kỳ user nào
+func (s *OrderService) cancel(ctx context.Context, userID, actorID, id uint, note string) (*models.Order, error) {
+	var order *models.Order
+	var changed *events.OrderStatusChanged
+	var stock []events.StockChanged
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = s.repo.GetForUpdate(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && userID != 0 && order.UserID != userID) {
+			return ErrOrderNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		changed, err = transitionOrder(ctx, s.repo, order, orderTransition{
+			To: models.OrderStatusCancelled, At: now, ActorID: &actorID, Note: note,
+			Fields: map[string]interface{}{"cancelled_at": now},
+		})
+		if err != nil {
+			return err
+		}
+		order.CancelledAt = &now
+
+		for _, item := range order.Items {
+			if err := s.products.ReleaseStock(ctx, item.ProductID, item.Quantity); err != nil {
+				return err
+			}
+			product, err := s.products.GetByID(ctx, item.ProductID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			stock = append(stock, events.StockChanged{
+				ProductID: product.ID, Name: product.Name,
+				OldStock: product.Stock - item.Quantity, NewStock: product.Stock, ChangedAt: now,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range stock {
+		s.bus.Publish(ctx, e)
+	}
+	s.bus.Publish(ctx, *changed)
+	return order, nil
+}
+
+// orderTransition mô tả một lần đổi trạng thái đơn; Fields là các cột cập nhật cùng trạng thái
+type orderTransition struct {
+	To      string
+	At      time.Time
+	ActorID *uint
+	Note    string
+	Fields  map[string]interface{}
+}
+
+// transitionOrder chuyển đơn (đã được khóa) sang trạng thái t.To nếu bước chuyển hợp lệ theo
+// Order.CanTransition, ghi lịch sử, cập nhật order tại chỗ và trả về sự kiện để publish sau khi commit
+func transitionOrder(ctx context.Context, repo *repository.OrderRepository, order *models.Order, t orderTransition) (*events.OrderStatusChanged, error) {
+	if !order.CanTransition(t.To) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidOrderTransition, order.Status, t.To)
+	}
+	rows, err := repo.UpdateStatus(ctx, order.ID, order.Status, t.To, t.Fields)
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, fmt.Errorf("%w: order %d is no longer %s", ErrInvalidOrderTransition, order.ID, order.Status)
+	}
+	if err := repo.AddHistory(ctx, &models.OrderStatusHistory{
+		OrderID: order.ID, FromStatus: order.Status, ToStatus: t.To, ActorID: t.ActorID, Note: t.Note, CreatedAt: t.At,
+	}); err != nil {
+		return nil, err
+	}
+	from := order.Status
+	order.Status = t.To
+	return &events.OrderStatusChanged{Order: *order, From: from, To: t.To, ChangedAt: t.At}, nil
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/payment"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPaymentMethodNotFound  = errors.New("payment method not found")
+	ErrPaymentMethodExists    = errors.New("payment method is already saved")
+	ErrNoDefaultPaymentMethod = errors.New("no default payment method")
+)
+
+// PaymentMethodService quản lý phương thức thanh toán đã lưu của user. Thẻ được token hóa
+// phía client bằng SDK của provider; server chỉ gắn token vào customer của user và lưu
+// thông tin hiển thị.
+type PaymentMethodService struct {
+	tx       *repository.TxManager
+	repo     *repository.PaymentMethodRepository
+	users    *repository.UserRepository
+	provider payment.Provider
+}
+
+// NewPaymentMethodService tạo service; provider nil nghĩa là chưa cấu hình thanh toán,
+// khi đó chỉ xem được danh sách, còn thêm/xóa trả về payment.ErrNotConfigured
+func NewPaymentMethodService(db *gorm.DB, provider payment.Provider) *PaymentMethodService {
+	return &PaymentMethodService{
+		tx:       repository.NewTxManager(db),
+		repo:     repository.NewPaymentMethodRepository(db),
+		users:    repository.NewUserRepository(db),
+		provider: provider,
+	}
+}
+
+// List lấy các phương thức thanh toán của user
+func (s *PaymentMethodService) List(ctx context.Context, userID uint) ([]models.PaymentMethod, error) {
+	return s.repo.List(ctx, userID)
+}
+
+// Default lấy phương thức mặc định của user, dùng khi thanh toán và gia hạn định kỳ
+func (s *PaymentMethodService) Default(ctx context.Context, userID uint) (*models.PaymentMethod, error) {
+	method, err := s.repo.GetDefault(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoDefaultPaymentMethod
+	}
+	return method, err
+}
+
+// Add gắn phương thức đã token hóa vào customer của user và lưu lại.
+// Phương thức đầu tiên của user luôn được đặt làm mặc định.
+func (s *PaymentMethodService) Add(ctx context.Context, userID uint, req *models.AddPaymentMethodRequest) (*models.PaymentMethod, error) {
+	if s.provider == nil {
+		return nil, payment.ErrNotConfigured
+	}
+	customerID, err := s.customerID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	attached, err := s.provider.AttachMethod(ctx, customerID, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	method := &models.PaymentMethod{
+		UserID:           userID,
+		Provider:         s.provider.Name(),
+		ProviderMethodID: attached.ID,
+		Type:             attached.Type,
+		Brand:            attached.Brand,
+		Last4:            attached.Last4,
+		ExpMonth:         attached.ExpMonth,
+		ExpYear:          attached.ExpYear,
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		count, err := s.repo.Count(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Create(ctx, method); err != nil {
+			if isUniqueViolation(err) {
+				return ErrPaymentMethodExists
+			}
+			return err
+		}
+		if req.SetDefault || count == 0 {
+			method.IsDefault = true
+			return s.repo.SetDefault(ctx, userID, method.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if method.IsDefault {
+		s.syncDefault(ctx, customerID, method)
+	}
+	return method, nil
+}
+
+// SetDefault đặt phương thức làm mặc định của user
+func (s *PaymentMethodService) SetDefault(ctx context.Context, userID, id uint) (*models.PaymentMethod, error) {
+	method, err := s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.repo.SetDefault(ctx, userID, id)
+	}); err != nil {
+		return nil, err
+	}
+	method.IsDefault = true
+
+	if customer, err := s.repo.GetCustomer(ctx, userID, method.Provider); err == nil {
+		s.syncDefault(ctx, customer.CustomerID, method)
+	}
+	return method, nil
+}
+
+// Delete gỡ phương thức khỏi provider rồi xóa; nếu đó là phương thức mặc định thì
+// phương thức được thêm gần nhất còn lại trở thành mặc định
+func (s *PaymentMethodService) Delete(ctx context.Context, userID, id uint) error {
+	if s.provider == nil {
+		return payment.ErrNotConfigured
+	}
+	method, err := s.get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	// Phương thức đã bị gỡ phía provider (hết hạn, xóa trên dashboard) vẫn được xóa khỏi danh sách
+	if err := s.provider.DetachMethod(ctx, method.ProviderMethodID); err != nil && !errors.Is(err, payment.ErrInvalidMethod) {
+		return err
+	}
+
+	var promoted *models.PaymentMethod
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, method.ID); err != nil {
+			return err
+		}
+		if !method.IsDefault {
+			return nil
+		}
+		next, err := s.repo.Latest(ctx, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		promoted = next
+		return s.repo.SetDefault(ctx, userID, next.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	if promoted != nil {
+		if customer, err := s.repo.GetCustomer(ctx, userID, promoted.Provider); err == nil {
+			s.syncDefault(ctx, customer.CustomerID, promoted)
+		}
+	}
+	return nil
+}
+
+func (s *PaymentMethodService) get(ctx context.Context, userID, id uint) (*models.PaymentMethod, error) {
+	method, err := s.repo.Get(ctx, userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPaymentMethodNotFound
+	}
+	return method, err
+}
+
+// customerID lấy customer của user tại provider, tạo mới nếu chưa có
+func (s *PaymentMethodService) customerID(ctx context.Context, userID uint) (string, error) {
+	customer, err := s.repo.GetCustomer(ctx, userID, s.provider.Name())
+	if err == nil {
+		return customer.CustomerID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	id, err := s.provider.CreateCustomer(ctx, payment.Customer{UserID: user.ID, Email: user.Email, FullName: user.FullName})
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.CreateCustomer(ctx, &models.PaymentCustomer{UserID: userID, Provider: s.provider.Name(), CustomerID: id}); err != nil {
+		return "", err
+	}
+	// Request đồng thời có thể đã lưu customer khác trước; dùng bản ghi đã lưu
+	customer, err = s.repo.GetCustomer(ctx, userID, s.provider.Name())
+	if err != nil {
+		return "", err
+	}
+	return customer.CustomerID, nil
+}
+
+// syncDefault đặt phương thức mặc định phía provider để thanh toán định kỳ dùng đúng thẻ.
+// Lỗi chỉ được ghi log vì phương thức mặc định trong database vẫn là nguồn chính.
+func (s *PaymentMethodService) syncDefault(ctx context.Context, customerID string, method *models.PaymentMethod) {
+	if s.provider == nil || method.Provider != s.provider.Name() {
+		return
+	}
+	if err := s.provider.SetDefaultMethod(ctx, customerID, method.ProviderMethodID); err != nil {
+		log.Printf("Payment: failed to sync default method %d for user %d: %v", method.ID, method.UserID, err)
+	}
+}
@@ -0,0 +1,454 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/payment"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOrderNotPaid          = errors.New("order has no captured payment")
+	ErrRefundExceedsPayment  = errors.New("refund amount exceeds the refundable amount")
+	ErrPaymentMethodProvider = errors.New("saved payment method belongs to another provider")
+	ErrPaymentReferenceTaken = errors.New("payment reference has already been recorded")
+)
+
+// refundEpsilon là sai số khi so sánh số tiền (float) lúc hoàn tiền
+const refundEpsilon = 0.005
+
+// PaymentService thu tiền cho đơn hàng qua cổng thanh toán, xử lý webhook kết quả thanh toán
+// và hoàn tiền. Mỗi lần thanh toán được ghi thành một Payment; đơn chỉ chuyển sang paid khi
+// gateway xác nhận (qua webhook có chữ ký hợp lệ), không dựa vào kết quả client tự báo.
+type PaymentService struct {
+	tx       *repository.TxManager
+	orders   *repository.OrderRepository
+	repo     *repository.PaymentRepository
+	methods  *repository.PaymentMethodRepository
+	gateways payment.Gateways
+	bus      *events.Bus
+}
+
+func NewPaymentService(db *gorm.DB, gateways payment.Gateways, bus *events.Bus) *PaymentService {
+	return &PaymentService{
+		tx:       repository.NewTxManager(db),
+		orders:   repository.NewOrderRepository(db),
+		repo:     repository.NewPaymentRepository(db),
+		methods:  repository.NewPaymentMethodRepository(db),
+		gateways: gateways,
+		bus:      bus,
+	}
+}
+
+// Pay tạo giao dịch thu tiền cho đơn chờ thanh toán của user. Nếu có PaymentMethodID thì thanh toán
+// ngay bằng phương thức đã lưu; nếu không, client hoàn tất bằng client_secret hoặc redirect_url.
+// clientIP là IP của khách (VNPay yêu cầu).
+func (s *PaymentService) Pay(ctx context.Context, userID, orderID uint, clientIP string, req *models.PayOrderRequest) (*models.PaymentIntentResponse, error) {
+	gateway, err := s.gateways.Get(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.orders.GetByID(ctx, orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && order.UserID != userID) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != models.OrderStatusPendingPayment {
+		return nil, ErrOrderNotPending
+	}
+	if order.PaymentType != models.OrderPaymentOnline {
+		return nil, ErrOrderPaidOffline
+	}
+
+	request := payment.PaymentRequest{
+		OrderID:     order.ID,
+		Amount:      order.Total,
+		Currency:    order.Currency,
+		Description: fmt.Sprintf("Order #%d", order.ID),
+		ClientIP:    clientIP,
+	}
+	if req.PaymentMethodID != 0 {
+		method, err := s.methods.Get(ctx, userID, req.PaymentMethodID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentMethodNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if method.Provider != gateway.Name() {
+			return nil, ErrPaymentMethodProvider
+		}
+		customer, err := s.methods.GetCustomer(ctx, userID, method.Provider)
+		if err != nil {
+			return nil, err
+		}
+		request.CustomerID = customer.CustomerID
+		request.MethodID = method.ProviderMethodID
+	}
+
+	record := &models.Payment{
+		OrderID:  order.ID,
+		Provider: gateway.Name(),
+		Amount:   order.Total,
+		Currency: order.Currency,
+		Status:   models.PaymentStatusPending,
+	}
+	if err := s.repo.Create(ctx, record); err != nil {
+		return nil, err
+	}
+	request.PaymentID = record.ID
+	request.CreatedAt = record.CreatedAt
+	request.IdempotencyKey = fmt.Sprintf("payment-%d", record.ID)
+
+	intent, err := gateway.CreatePayment(ctx, request)
+	if err != nil {
+		record.Status = models.PaymentStatusFailed
+		record.FailureReason = err.Error()
+		if saveErr := s.repo.Save(ctx, record); saveErr != nil {
+			log.Printf("Payment: failed to record failure of payment %d: %v", record.ID, saveErr)
+		}
+		return nil, err
+	}
+	record.Reference = &intent.Reference
+	if err := s.repo.Save(ctx, record); err != nil {
+		return nil, err
+	}
+
+	if intent.Paid {
+		// Webhook vẫn sẽ tới sau đó và được bỏ qua vì giao dịch đã được ghi nhận
+		if err := s.apply(ctx, gateway.Name(), &payment.WebhookEvent{Type: payment.WebhookPaymentSucceeded, Reference: intent.Reference}); err != nil {
+			return nil, err
+		}
+		record.Status = models.PaymentStatusSucceeded
+	}
+	return &models.PaymentIntentResponse{
+		Payment:      record,
+		Status:       intent.Status,
+		ClientSecret: intent.ClientSecret,
+		RedirectURL:  intent.RedirectURL,
+	}, nil
+}
+
+// HandleWebhook xác thực và áp dụng webhook kết quả thanh toán của gateway.
+// Webhook có thể được gửi lại nhiều lần; giao dịch đã ở trạng thái cuối không bị đổi lại và
+// payment.ErrAlreadyProcessed được trả về.
+func (s *PaymentService) HandleWebhook(ctx context.Context, provider string, r *http.Request, body []byte) error {
+	gateway, err := s.gateways.Get(provider)
+	if err != nil {
+		return err
+	}
+	event, err := gateway.ParseWebhook(r, body)
+	if err != nil {
+		return err
+	}
+	if event.Type == "" {
+		return nil
+	}
+	return s.apply(ctx, gateway.Name(), event)
+}
+
+// WebhookResponse trả về cách trả lời webhook theo định dạng riêng của gateway (nếu có)
+func (s *PaymentService) WebhookResponse(provider string, err error) (int, interface{}, bool) {
+	gateway, getErr := s.gateways.Get(provider)
+	if getErr != nil {
+		return 0, nil, false
+	}
+	responder, ok := gateway.(payment.WebhookResponder)
+	if !ok {
+		return 0, nil, false
+	}
+	status, body := responder.WebhookResponse(err)
+	return status, body, true
+}
+
+// HandleReturn xử lý khi khách được gateway chuyển về sau khi thanh toán. Kết quả có chữ ký của
+// gateway nên được áp dụng như webhook (hữu ích khi IPN chưa tới hoặc không gọi được tới server),
+// sau đó trả về trạng thái hiện tại của giao dịch và đơn hàng.
+func (s *PaymentService) HandleReturn(ctx context.Context, provider string, r *http.Request) (*models.PaymentReturnResponse, error) {
+	gateway, err := s.gateways.Get(provider)
+	if err != nil {
+		return nil, err
+	}
+	event, err := gateway.ParseWebhook(r, nil)
+	if err != nil {
+		return nil, err
+	}
+	if event.Type != "" {
+		if err := s.apply(ctx, gateway.Name(), event); err != nil && !errors.Is(err, payment.ErrAlreadyProcessed) {
+			return nil, err
+		}
+	}
+
+	record, err := s.repo.GetByReference(ctx, gateway.Name(), event.Reference)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, payment.ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.orders.GetByID(ctx, record.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PaymentReturnResponse{Payment: record, OrderID: order.ID, OrderStatus: order.Status}, nil
+}
+
+// apply ghi kết quả thanh toán vào Payment và chuyển đơn sang paid khi thu tiền thành công
+func (s *PaymentService) apply(ctx context.Context, provider string, event *payment.WebhookEvent) error {
+	var changed *events.OrderStatusChanged
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		record, err := s.repo.GetByReferenceForUpdate(ctx, provider, event.Reference)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return payment.ErrPaymentNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if event.Amount > 0 && math.Abs(event.Amount-record.Amount) > refundEpsilon {
+			return fmt.Errorf("%w: payment %d expects %.2f, gateway reported %.2f", payment.ErrAmountMismatch, record.ID, record.Amount, event.Amount)
+		}
+
+		now := time.Now()
+		switch event.Type {
+		case payment.WebhookPaymentFailed:
+			if record.Status != models.PaymentStatusPending {
+				return payment.ErrAlreadyProcessed
+			}
+			record.Status = models.PaymentStatusFailed
+			record.FailureReason = event.FailureReason
+			return s.repo.Save(ctx, record)
+
+		case payment.WebhookPaymentSucceeded:
+			// Giao dịch có thể bị báo thất bại trước rồi mới thành công (khách thử lại cùng intent)
+			if record.Status != models.PaymentStatusPending && record.Status != models.PaymentStatusFailed {
+				return payment.ErrAlreadyProcessed
+			}
+			record.Status = models.PaymentStatusSucceeded
+			record.FailureReason = ""
+			record.PaidAt = &now
+			if event.TransactionID != "" {
+				record.TransactionID = event.TransactionID
+			}
+			if err := s.repo.Save(ctx, record); err != nil {
+				return err
+			}
+
+			order, err := s.orders.GetForUpdate(ctx, record.OrderID)
+			if err != nil {
+				return err
+			}
+			if order.Status != models.OrderStatusPendingPayment {
+				// Ví dụ khách đã hủy đơn trước khi thanh toán xong: giữ nguyên đơn để admin hoàn tiền
+				log.Printf("Payment: payment %d succeeded but order %d is %s; refund required", record.ID, order.ID, order.Status)
+				return nil
+			}
+			changed, err = transitionOrder(ctx, s.orders, order, orderTransition{
+				To: models.OrderStatusPaid, At: now, Note: fmt.Sprintf("%s payment %d", provider, record.ID),
+				Fields: map[string]interface{}{"paid_at": now},
+			})
+			if err != nil {
+				return err
+			}
+			order.PaidAt = &now
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if changed != nil {
+		s.bus.Publish(ctx, *changed)
+	}
+	return nil
+}
+
+// Refund hoàn tiền giao dịch đã thu của đơn (Admin). amount bằng 0 nghĩa là hoàn phần còn lại;
+// khi đã hoàn đủ số tiền, đơn chuyển sang refunded. clientIP là IP của admin (VNPay yêu cầu).
+func (s *PaymentService) Refund(ctx context.Context, adminID uint, clientIP string, orderID uint, req *models.RefundRequest) (*models.Refund, error) {
+	var refund *models.Refund
+	var changed *events.OrderStatusChanged
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		order, err := s.orders.GetForUpdate(ctx, orderID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOrderNotFound
+		}
+		if err != nil {
+			return err
+		}
+		refund, changed, err = s.refundOrder(ctx, order, adminID, clientIP, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if changed != nil {
+		s.bus.Publish(ctx, *changed)
+	}
+	return refund, nil
+}
+
+// refundOrder hoàn tiền giao dịch đã thu của đơn trong transaction của ctx; order phải đã được khóa.
+// Sự kiện đổi trạng thái (nếu có) được trả về để publish sau khi commit.
+func (s *PaymentService) refundOrder(ctx context.Context, order *models.Order, adminID uint, clientIP string, req *models.RefundRequest) (*models.Refund, *events.OrderStatusChanged, error) {
+	record, err := s.repo.GetCapturedForUpdate(ctx, order.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrOrderNotPaid
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	remaining := record.Amount - record.RefundedAmount
+	amount := req.Amount
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount > remaining+refundEpsilon {
+		return nil, nil, fmt.Errorf("%w: at most %.2f %s", ErrRefundExceedsPayment, remaining, record.Currency)
+	}
+
+	// Tiền thu thủ công (COD, chuyển khoản) được admin trả lại ngoài hệ thống, ở đây chỉ ghi nhận
+	result := &payment.RefundResult{Status: models.PaymentStatusSucceeded}
+	if !models.IsManualPaymentProvider(record.Provider) {
+		gateway, err := s.gateways.Get(record.Provider)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Gọi gateway khi vẫn giữ khóa giao dịch để hai lần hoàn tiền đồng thời không vượt số đã thu
+		result, err = gateway.Refund(ctx, payment.RefundRequest{
+			Reference:     *record.Reference,
+			TransactionID: record.TransactionID,
+			CreatedAt:     record.CreatedAt,
+			Amount:        amount,
+			Currency:      record.Currency,
+			Partial:       record.RefundedAmount > 0 || remaining-amount >= refundEpsilon,
+			Reason:        req.Reason,
+			RequestedBy:   fmt.Sprintf("admin-%d", adminID),
+			ClientIP:      clientIP,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	refund := &models.Refund{
+		PaymentID: record.ID,
+		Reference: result.Reference,
+		Amount:    amount,
+		Reason:    req.Reason,
+		Status:    result.Status,
+		AdminID:   &adminID,
+	}
+	if err := s.repo.CreateRefund(ctx, refund); err != nil {
+		return nil, nil, err
+	}
+
+	record.RefundedAmount += amount
+	record.Status = models.PaymentStatusPartiallyRefunded
+	if record.Amount-record.RefundedAmount < refundEpsilon {
+		record.Status = models.PaymentStatusRefunded
+	}
+	if err := s.repo.Save(ctx, record); err != nil {
+		return nil, nil, err
+	}
+
+	if record.Status == models.PaymentStatusRefunded && models.CanTransitionOrder(order.Status, models.OrderStatusRefunded) {
+		changed, err := transitionOrder(ctx, s.orders, order, orderTransition{
+			To: models.OrderStatusRefunded, At: time.Now(), ActorID: &adminID, Note: req.Reason,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return refund, changed, nil
+	}
+	return refund, nil, nil
+}
+
+// MarkPaid ghi nhận đơn đã được thanh toán ngoài cổng thanh toán (COD, chuyển khoản) và chuyển
+// đơn sang paid (Admin). Payment được tạo với provider theo payment_type của đơn; đơn thanh toán
+// online được đối soát thủ công thì dùng provider manual. Đơn COD đã giao đi trước khi thu tiền
+// giữ nguyên trạng thái, chỉ được ghi nhận thời điểm thanh toán.
+func (s *PaymentService) MarkPaid(ctx context.Context, adminID, orderID uint, req *models.MarkPaidRequest) (*models.Payment, error) {
+	var record *models.Payment
+	var changed *events.OrderStatusChanged
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		order, err := s.orders.GetForUpdate(ctx, orderID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOrderNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		fields := map[string]interface{}{"paid_at": now}
+		shipped := order.Status == models.OrderStatusFulfilled || order.Status == models.OrderStatusDelivered
+		if order.PaymentType == models.OrderPaymentCOD && shipped && order.PaidAt == nil {
+			if err := s.orders.Update(ctx, order.ID, fields); err != nil {
+				return err
+			}
+		} else {
+			changed, err = transitionOrder(ctx, s.orders, order, orderTransition{
+				To: models.OrderStatusPaid, At: now, ActorID: &adminID, Note: req.Note, Fields: fields,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		order.PaidAt = &now
+
+		provider := order.PaymentType
+		if provider == models.OrderPaymentOnline {
+			provider = models.PaymentProviderManual
+		}
+		record = &models.Payment{
+			OrderID:    order.ID,
+			Provider:   provider,
+			Amount:     order.Total,
+			Currency:   order.Currency,
+			Status:     models.PaymentStatusSucceeded,
+			PaidAt:     &now,
+			RecordedBy: &adminID,
+			Note:       req.Note,
+		}
+		if req.Reference != "" {
+			record.Reference = &req.Reference
+		}
+		if err := s.repo.Create(ctx, record); err != nil {
+			if isUniqueViolation(err) {
+				return ErrPaymentReferenceTaken
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if changed != nil {
+		s.bus.Publish(ctx, *changed)
+	}
+	return record, nil
+}
+
+// ListByOrder lấy các giao dịch thanh toán của đơn kèm lịch sử hoàn tiền
+func (s *PaymentService) ListByOrder(ctx context.Context, orderID uint) ([]models.Payment, error) {
+	if _, err := s.orders.GetByID(ctx, orderID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return s.repo.ListByOrder(ctx, orderID)
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPriceChangeNotFound = errors.New("price change not found")
+	ErrPriceChangeOverlap  = errors.New("product already has a price change in this period")
+	ErrInvalidPriceChange  = errors.New("price change must end in the future")
+	ErrPriceChangeClosed   = errors.New("price change has already ended")
+)
+
+// PriceChangeService quản lý lịch đổi giá có thời hạn (flash sale). Giá của lịch đang áp dụng được ghi vào
+// products.sale_price nên listing, chi tiết sản phẩm, giỏ hàng và checkout đều tính theo cùng
+// Product.EffectivePrice.
+type PriceChangeService struct {
+	repo     *repository.PriceChangeRepository
+	products *repository.ProductRepository
+	listings *repository.ProductListingRepository
+	tx       *repository.TxManager
+	bus      *events.Bus
+}
+
+func NewPriceChangeService(db *gorm.DB, bus *events.Bus) *PriceChangeService {
+	return &PriceChangeService{
+		repo:     repository.NewPriceChangeRepository(db),
+		products: repository.NewProductRepository(db),
+		listings: repository.NewProductListingRepository(db),
+		tx:       repository.NewTxManager(db),
+		bus:      bus,
+	}
+}
+
+// List lấy các lịch đổi giá của sản phẩm
+func (s *PriceChangeService) List(ctx context.Context, productID uint) ([]models.ScheduledPriceChange, error) {
+	if _, err := s.product(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByProduct(ctx, productID)
+}
+
+// Create lên lịch đổi giá cho sản phẩm; lịch được áp dụng ở lần chạy kế tiếp của tác vụ price_change_apply
+// sau StartsAt
+func (s *PriceChangeService) Create(ctx context.Context, adminID, productID uint, req *models.CreatePriceChangeRequest) (*models.ScheduledPriceChange, error) {
+	if !req.EndsAt.After(time.Now()) {
+		return nil, ErrInvalidPriceChange
+	}
+	change := &models.ScheduledPriceChange{
+		ProductID: productID,
+		Price:     req.Price,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		Status:    models.PriceChangeScheduled,
+		CreatedBy: adminID,
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.product(ctx, productID); err != nil {
+			return err
+		}
+		overlaps, err := s.repo.Overlaps(ctx, productID, req.StartsAt, req.EndsAt)
+		if err != nil {
+			return err
+		}
+		if overlaps {
+			return ErrPriceChangeOverlap
+		}
+		return s.repo.Create(ctx, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// Cancel hủy lịch đổi giá; lịch đang áp dụng được hoàn về giá gốc ngay
+func (s *PriceChangeService) Cancel(ctx context.Context, productID, id uint) (*models.ScheduledPriceChange, error) {
+	change, err := s.repo.Get(ctx, productID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPriceChangeNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	var ok bool
+	switch change.Status {
+	case models.PriceChangeScheduled:
+		ok, err = s.repo.Transition(ctx, change.ID, change.Status, map[string]interface{}{"status": models.PriceChangeCancelled})
+	case models.PriceChangeActive:
+		change.RevertedAt = &now
+		ok, err = s.transition(ctx, change, map[string]interface{}{"status": models.PriceChangeCancelled, "reverted_at": now}, nil)
+	default:
+		return nil, ErrPriceChangeClosed
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// Tác vụ định kỳ vừa chuyển trạng thái của lịch; trạng thái chỉ đi tiến nên thử lại với trạng thái mới
+		return s.Cancel(ctx, productID, id)
+	}
+	change.Status = models.PriceChangeCancelled
+	return change, nil
+}
+
+// ApplyDue hoàn giá gốc cho các lịch đang áp dụng đã hết hạn rồi áp dụng các lịch đã tới giờ bắt đầu; trả về
+// số lịch đã áp dụng và đã kết thúc. Lịch hết hạn trước khi kịp áp dụng được kết thúc mà không đổi giá.
+func (s *PriceChangeService) ApplyDue(ctx context.Context, now time.Time) (applied, ended int, err error) {
+	expired, err := s.repo.DueToRevert(ctx, now)
+	if err != nil {
+		return 0, 0, err
+	}
+	for i := range expired {
+		ok, err := s.transition(ctx, &expired[i], map[string]interface{}{"status": models.PriceChangeEnded, "reverted_at": now}, nil)
+		if err != nil {
+			return applied, ended, err
+		}
+		if ok {
+			ended++
+		}
+	}
+
+	due, err := s.repo.DueToApply(ctx, now)
+	if err != nil {
+		return applied, ended, err
+	}
+	for i := range due {
+		change := &due[i]
+		if !change.EndsAt.After(now) {
+			ok, err := s.repo.Transition(ctx, change.ID, models.PriceChangeScheduled, map[string]interface{}{"status": models.PriceChangeEnded})
+			if err != nil {
+				return applied, ended, err
+			}
+			if ok {
+				ended++
+			}
+			continue
+		}
+		ok, err := s.transition(ctx, change, map[string]interface{}{"status": models.PriceChangeActive, "applied_at": now}, &change.Price)
+		if err != nil {
+			return applied, ended, err
+		}
+		if ok {
+			applied++
+		}
+	}
+	return applied, ended, nil
+}
+
+// transition đổi trạng thái của lịch (nếu lịch chưa bị đổi bởi request khác) và đặt giá bán của sản phẩm
+// thành salePrice trong cùng transaction, rồi dựng lại dòng listing và phát sự kiện đổi giá
+func (s *PriceChangeService) transition(ctx context.Context, change *models.ScheduledPriceChange, updates map[string]interface{}, salePrice *float64) (bool, error) {
+	var before models.Product
+	done := false
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		ok, err := s.repo.Transition(ctx, change.ID, change.Status, updates)
+		if err != nil || !ok {
+			return err
+		}
+		product, err := s.products.GetByID(ctx, change.ProductID)
+		if err != nil {
+			return err
+		}
+		if err := s.products.SetSalePrice(ctx, product.ID, salePrice); err != nil {
+			return err
+		}
+		before, done = *product, true
+		return s.listings.Refresh(ctx, product.ID)
+	})
+	if err != nil || !done {
+		return false, err
+	}
+
+	after := before
+	after.SalePrice, after.Version = salePrice, before.Version+1
+	s.bus.Publish(ctx, events.ProductUpdated{Product: after})
+	if before.EffectivePrice() != after.EffectivePrice() {
+		s.bus.Publish(ctx, events.PriceChanged{
+			ProductID: after.ID, Name: after.Name,
+			OldPrice: before.EffectivePrice(), NewPrice: after.EffectivePrice(), ChangedAt: time.Now(),
+		})
+	}
+	return true, nil
+}
+
+func (s *PriceChangeService) product(ctx context.Context, productID uint) (*models.Product, error) {
+	product, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return product, nil
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProductAlertNotFound = errors.New("product alert not found")
+	ErrProductAlertLimit    = errors.New("too many product alerts")
+	ErrProductInStock       = errors.New("product is in stock")
+	ErrAlertPriceNotBelow   = errors.New("target price must be below the current price")
+)
+
+// DefaultProductAlertTTL là thời hạn mặc định của một alert theo dõi sản phẩm
+const DefaultProductAlertTTL = 90 * 24 * time.Hour
+
+// ProductAlertTTL đọc thời hạn của alert từ PRODUCT_ALERT_TTL (mặc định DefaultProductAlertTTL)
+func ProductAlertTTL() time.Duration {
+	return config.Duration("PRODUCT_ALERT_TTL", DefaultProductAlertTTL)
+}
+
+// ProductAlertService quản lý các alert có hàng trở lại / giảm giá mà user đăng ký cho sản phẩm. Việc báo
+// được thực hiện bởi notify.RegisterProductAlertSubscribers khi tồn kho hoặc giá thay đổi.
+type ProductAlertService struct {
+	tx       *repository.TxManager
+	repo     *repository.ProductAlertRepository
+	products *repository.ProductRepository
+	ttl      time.Duration
+}
+
+func NewProductAlertService(db *gorm.DB) *ProductAlertService {
+	return &ProductAlertService{
+		tx:       repository.NewTxManager(db),
+		repo:     repository.NewProductAlertRepository(db),
+		products: repository.NewProductRepository(db),
+		ttl:      ProductAlertTTL(),
+	}
+}
+
+// List lấy các alert chưa hết hạn của user, kể cả alert đã báo
+func (s *ProductAlertService) List(ctx context.Context, userID uint) ([]models.ProductAlert, error) {
+	return s.repo.ListByUser(ctx, userID, time.Now())
+}
+
+// Subscribe theo dõi sản phẩm đã đăng. back_in_stock chỉ nhận sản phẩm đang hết hàng, price_drop cần
+// ngưỡng thấp hơn giá bán hiện tại. Theo dõi lại cùng loại đặt lại ngưỡng và hạn của alert cũ.
+func (s *ProductAlertService) Subscribe(ctx context.Context, userID, productID uint, req *models.CreateProductAlertRequest) (*models.ProductAlert, error) {
+	product, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	if product.Status != models.ProductStatusPublished {
+		return nil, ErrProductUnavailable
+	}
+
+	alert := &models.ProductAlert{UserID: userID, ProductID: productID, Type: req.Type}
+	switch req.Type {
+	case models.ProductAlertBackInStock:
+		if product.Stock > 0 {
+			return nil, ErrProductInStock
+		}
+	case models.ProductAlertPriceDrop:
+		if *req.TargetPrice >= product.EffectivePrice() {
+			return nil, ErrAlertPriceNotBelow
+		}
+		alert.TargetPrice = req.TargetPrice
+	}
+
+	now := time.Now()
+	alert.ExpiresAt = now.Add(s.ttl)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.repo.Get(ctx, userID, productID, req.Type); errors.Is(err, gorm.ErrRecordNotFound) {
+			count, err := s.repo.CountActive(ctx, userID, now)
+			if err != nil {
+				return err
+			}
+			if count >= models.MaxProductAlertsPerUser {
+				return ErrProductAlertLimit
+			}
+		} else if err != nil {
+			return err
+		}
+		if err := s.repo.Upsert(ctx, alert); err != nil {
+			return err
+		}
+		// Upsert không trả về ID của dòng đã có trên mọi database nên đọc lại
+		saved, err := s.repo.Get(ctx, userID, productID, req.Type)
+		if err != nil {
+			return err
+		}
+		*alert = *saved
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	alert.Product = product
+	return alert, nil
+}
+
+// Unsubscribe xóa alert của user
+func (s *ProductAlertService) Unsubscribe(ctx context.Context, userID, id uint) error {
+	found, err := s.repo.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrProductAlertNotFound
+	}
+	return nil
+}
+
+// SweepExpired xóa các alert đã hết hạn
+func (s *ProductAlertService) SweepExpired(ctx context.Context, now time.Time) (int64, error) {
+	return s.repo.DeleteExpired(ctx, now)
+}
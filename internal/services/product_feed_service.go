@@ -0,0 +1,199 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+var ErrProductFeedNotFound = errors.New("product feed not found")
+
+// productFeedBatchSize là số sản phẩm đọc mỗi lần khi dựng feed
+const productFeedBatchSize = 1000
+
+// productFeedCurrency là đơn vị tiền tệ của giá trong feed
+const productFeedCurrency = "VND"
+
+const googleProductNamespace = "http://base.google.com/ns/1.0"
+
+// facebookFeedColumns là các cột của feed CSV theo đặc tả catalog của Facebook
+var facebookFeedColumns = []string{"id", "title", "description", "availability", "condition", "price", "sale_price", "link", "image_link", "brand", "product_type"}
+
+type googleFeedItem struct {
+	ID           string `xml:"g:id"`
+	Title        string `xml:"g:title"`
+	Description  string `xml:"g:description"`
+	Link         string `xml:"g:link"`
+	ImageLink    string `xml:"g:image_link,omitempty"`
+	Availability string `xml:"g:availability"`
+	Condition    string `xml:"g:condition"`
+	Price        string `xml:"g:price"`
+	SalePrice    string `xml:"g:sale_price,omitempty"`
+	Brand        string `xml:"g:brand,omitempty"`
+	MPN          string `xml:"g:mpn,omitempty"`
+	ProductType  string `xml:"g:product_type,omitempty"`
+}
+
+type googleFeedChannel struct {
+	Title string           `xml:"title"`
+	Link  string           `xml:"link"`
+	Items []googleFeedItem `xml:"item"`
+}
+
+type googleFeedXML struct {
+	XMLName xml.Name          `xml:"rss"`
+	Version string            `xml:"version,attr"`
+	Xmlns   string            `xml:"xmlns:g,attr"`
+	Channel googleFeedChannel `xml:"channel"`
+}
+
+// ProductFeedService dựng feed sản phẩm cho Google Merchant Center (XML) và Facebook catalog (CSV) từ các
+// sản phẩm đã đăng. Giống sitemap, feed được dựng sẵn và lưu vào bảng product_feeds để mọi replica phục
+// vụ chung một bản.
+type ProductFeedService struct {
+	repo     *repository.ProductFeedRepository
+	products *repository.ProductRepository
+	// storefrontURL là gốc URL trang sản phẩm, mediaURL là gốc URL của ảnh có đường dẫn tương đối
+	storefrontURL string
+	mediaURL      string
+	brand         string
+}
+
+func NewProductFeedService(db *gorm.DB) *ProductFeedService {
+	storefrontURL := utils.StorefrontURL()
+	return &ProductFeedService{
+		repo:          repository.NewProductFeedRepository(db),
+		products:      repository.NewProductRepository(db),
+		storefrontURL: storefrontURL,
+		mediaURL:      strings.TrimSuffix(config.String("FEED_MEDIA_BASE_URL", storefrontURL), "/"),
+		brand:         config.String("FEED_BRAND", "BackendShop"),
+	}
+}
+
+// Get lấy feed đã dựng theo tên; dựng ngay nếu feed chưa từng được dựng
+func (s *ProductFeedService) Get(ctx context.Context, name string) (*models.ProductFeed, error) {
+	feed, err := s.repo.Get(ctx, name)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return feed, err
+	}
+	if _, err := s.Generate(ctx, time.Now()); err != nil {
+		return nil, err
+	}
+	feed, err = s.repo.Get(ctx, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProductFeedNotFound
+	}
+	return feed, err
+}
+
+// Generate dựng lại feed Google và Facebook từ mọi sản phẩm đã đăng; trả về số sản phẩm trong feed
+func (s *ProductFeedService) Generate(ctx context.Context, now time.Time) (int, error) {
+	google := googleFeedXML{
+		Version: "2.0",
+		Xmlns:   googleProductNamespace,
+		Channel: googleFeedChannel{Title: s.brand, Link: s.storefrontURL},
+	}
+	var facebook bytes.Buffer
+	w := csv.NewWriter(&facebook)
+	if err := w.Write(facebookFeedColumns); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	var afterID uint
+	for {
+		products, err := s.products.ListPublishedPageAfter(ctx, afterID, productFeedBatchSize)
+		if err != nil {
+			return 0, err
+		}
+		for _, product := range products {
+			afterID = product.ID
+			item := s.feedItem(product)
+			google.Channel.Items = append(google.Channel.Items, item)
+			if err := w.Write([]string{
+				item.ID, item.Title, item.Description, strings.ReplaceAll(item.Availability, "_", " "), item.Condition,
+				item.Price, item.SalePrice, item.Link, item.ImageLink, item.Brand, item.ProductType,
+			}); err != nil {
+				return 0, err
+			}
+			count++
+		}
+		if len(products) < productFeedBatchSize {
+			break
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return 0, err
+	}
+
+	content, err := xml.Marshal(google)
+	if err != nil {
+		return 0, err
+	}
+	feeds := []models.ProductFeed{
+		{Name: models.ProductFeedGoogle, ContentType: "application/xml; charset=utf-8", Content: append([]byte(xml.Header), content...), ItemCount: count, GeneratedAt: now},
+		{Name: models.ProductFeedFacebook, ContentType: "text/csv; charset=utf-8", Content: facebook.Bytes(), ItemCount: count, GeneratedAt: now},
+	}
+	for i := range feeds {
+		if err := s.repo.Save(ctx, &feeds[i]); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// feedItem chuyển sản phẩm thành một mục feed; giá gốc nằm ở price, giá flash sale đang áp dụng ở sale_price
+func (s *ProductFeedService) feedItem(product models.Product) googleFeedItem {
+	item := googleFeedItem{
+		ID:           strconv.FormatUint(uint64(product.ID), 10),
+		Title:        product.Name,
+		Description:  product.Description,
+		Link:         s.storefrontURL + utils.ProductPath(product.ID, product.Name),
+		ImageLink:    s.imageLink(product.ImageURL),
+		Availability: "in_stock",
+		Condition:    "new",
+		Price:        feedPrice(product.Price),
+		Brand:        s.brand,
+		ProductType:  product.Category,
+	}
+	// Cả hai kênh đều bắt buộc có mô tả
+	if item.Description == "" {
+		item.Description = product.Name
+	}
+	if product.Stock <= 0 {
+		item.Availability = "out_of_stock"
+	}
+	if product.SalePrice != nil && *product.SalePrice < product.Price {
+		item.SalePrice = feedPrice(*product.SalePrice)
+	}
+	if product.SKU != nil {
+		item.MPN = *product.SKU
+	}
+	return item
+}
+
+// imageLink trả về URL tuyệt đối của ảnh; ảnh upload (/uploads/...) được gắn gốc FEED_MEDIA_BASE_URL
+func (s *ProductFeedService) imageLink(imageURL string) string {
+	if imageURL == "" || strings.HasPrefix(imageURL, "http://") || strings.HasPrefix(imageURL, "https://") {
+		return imageURL
+	}
+	return s.mediaURL + "/" + strings.TrimPrefix(imageURL, "/")
+}
+
+// feedPrice định dạng giá theo yêu cầu của feed, ví dụ "199000.00 VND"
+func feedPrice(amount float64) string {
+	return fmt.Sprintf("%.2f %s", amount, productFeedCurrency)
+}
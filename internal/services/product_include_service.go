@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidInclude = errors.New("invalid include")
+
+// Các quan hệ của sản phẩm mở rộng được bằng ?include=
+const (
+	IncludeReviews  = "reviews"  // các đánh giá đã duyệt mới nhất
+	IncludeCategory = "category" // nút danh mục trong cây danh mục, kèm số sản phẩm và danh mục con
+	IncludeMedia    = "media"    // ảnh chính và các video đã xử lý xong
+)
+
+// ProductIncludes là danh sách field có thể mở rộng, theo thứ tự hiển thị trong thông báo lỗi
+var ProductIncludes = []string{IncludeReviews, IncludeCategory, IncludeMedia}
+
+// Giới hạn số đánh giá mở rộng cho mỗi sản phẩm (?reviews_limit=)
+const (
+	DefaultIncludedReviews = 3
+	MaxIncludedReviews     = 20
+)
+
+// IncludeOptions là các quan hệ được yêu cầu và giới hạn của từng quan hệ
+type IncludeOptions struct {
+	Names        []string
+	ReviewsLimit int
+}
+
+// Has cho biết quan hệ name có được yêu cầu không
+func (o IncludeOptions) Has(name string) bool {
+	return slices.Contains(o.Names, name)
+}
+
+// ParseIncludes đọc danh sách ?include= (phân tách bằng dấu phẩy) và giới hạn ?reviews_limit= (0 là mặc định)
+func ParseIncludes(raw string, reviewsLimit int) (IncludeOptions, error) {
+	opts := IncludeOptions{ReviewsLimit: reviewsLimit}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || slices.Contains(opts.Names, name) {
+			continue
+		}
+		if !slices.Contains(ProductIncludes, name) {
+			return opts, fmt.Errorf("%w: unknown include %q, allowed: %s", ErrInvalidInclude, name, strings.Join(ProductIncludes, ", "))
+		}
+		opts.Names = append(opts.Names, name)
+	}
+	if opts.ReviewsLimit == 0 {
+		opts.ReviewsLimit = DefaultIncludedReviews
+	}
+	if opts.ReviewsLimit < 0 || opts.ReviewsLimit > MaxIncludedReviews {
+		return opts, fmt.Errorf("%w: reviews_limit must be between 1 and %d", ErrInvalidInclude, MaxIncludedReviews)
+	}
+	return opts, nil
+}
+
+// ProductIncludeService nạp các quan hệ được yêu cầu cho nhiều sản phẩm cùng lúc theo kiểu dataloader:
+// mỗi quan hệ được nạp cho mọi sản phẩm bằng một truy vấn (danh mục lấy từ cây đã cache) thay vì một
+// truy vấn cho mỗi sản phẩm.
+type ProductIncludeService struct {
+	reviews    *repository.ReviewRepository
+	media      *repository.ProductMediaRepository
+	categories *CategoryService
+}
+
+// NewProductIncludeService tạo service dùng chung cây danh mục đã cache của categories
+func NewProductIncludeService(db *gorm.DB, categories *CategoryService) *ProductIncludeService {
+	return &ProductIncludeService{
+		reviews:    repository.NewReviewRepository(db),
+		media:      repository.NewProductMediaRepository(db),
+		categories: categories,
+	}
+}
+
+// Load trả về các quan hệ được mở rộng của từng sản phẩm, cùng thứ tự với products; nil khi không
+// yêu cầu quan hệ nào
+func (s *ProductIncludeService) Load(ctx context.Context, products []models.Product, opts IncludeOptions) ([]map[string]interface{}, error) {
+	if len(opts.Names) == 0 || len(products) == 0 {
+		return nil, nil
+	}
+	included := make([]map[string]interface{}, len(products))
+	for i := range included {
+		included[i] = make(map[string]interface{}, len(opts.Names))
+	}
+
+	if opts.Has(IncludeReviews) {
+		ids := make([]uint, len(products))
+		for i, product := range products {
+			ids[i] = product.ID
+		}
+		reviews, err := s.reviews.ListLatestApproved(ctx, ids, opts.ReviewsLimit)
+		if err != nil {
+			return nil, err
+		}
+		byProduct := make(map[uint][]models.ProductReview, len(products))
+		for _, review := range reviews {
+			byProduct[review.ProductID] = append(byProduct[review.ProductID], review)
+		}
+		for i, product := range products {
+			list := byProduct[product.ID]
+			if list == nil {
+				list = []models.ProductReview{}
+			}
+			included[i][IncludeReviews] = list
+		}
+	}
+
+	if opts.Has(IncludeMedia) {
+		ids := make([]uint, len(products))
+		for i, product := range products {
+			ids[i] = product.ID
+		}
+		videos, err := s.media.ListReadyByProducts(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		byProduct := make(map[uint][]models.ProductMedia, len(products))
+		for _, video := range videos {
+			byProduct[video.ProductID] = append(byProduct[video.ProductID], video)
+		}
+		for i := range products {
+			included[i][IncludeMedia] = productMediaItems(&products[i], byProduct[products[i].ID])
+		}
+	}
+
+	if opts.Has(IncludeCategory) {
+		for i, product := range products {
+			node, err := s.categories.Node(ctx, product.Category)
+			if err != nil {
+				return nil, err
+			}
+			included[i][IncludeCategory] = node
+		}
+	}
+	return included, nil
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProductMediaNotFound = errors.New("product media not found")
+	ErrProductMediaLimit    = errors.New("product media limit reached")
+	ErrInvalidVideoURL      = errors.New("invalid video URL")
+)
+
+var (
+	youTubeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	vimeoIDPattern   = regexp.MustCompile(`^[0-9]+$`)
+	vimeoHashPattern = regexp.MustCompile(`^[0-9a-f]+$`)
+)
+
+// ProductMediaService quản lý video của sản phẩm. Service chỉ ghi bản ghi ở trạng thái processing; việc
+// kiểm tra và chuyển mã do job product.media (đưa vào hàng đợi bởi handler) thực hiện.
+type ProductMediaService struct {
+	repo     *repository.ProductMediaRepository
+	products *repository.ProductRepository
+	tx       *repository.TxManager
+}
+
+func NewProductMediaService(db *gorm.DB) *ProductMediaService {
+	return &ProductMediaService{
+		repo:     repository.NewProductMediaRepository(db),
+		products: repository.NewProductRepository(db),
+		tx:       repository.NewTxManager(db),
+	}
+}
+
+// List lấy media của sản phẩm: ảnh chính (nếu có) đứng đầu, sau đó là các video. Khách chỉ thấy video đã
+// xử lý xong của sản phẩm đã đăng; admin thấy cả video đang xử lý hoặc lỗi.
+func (s *ProductMediaService) List(ctx context.Context, productID uint, admin bool) ([]models.ProductMedia, error) {
+	product, err := s.product(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin && product.Status != models.ProductStatusPublished {
+		return nil, ErrProductNotFound
+	}
+	videos, err := s.repo.ListByProduct(ctx, productID, !admin)
+	if err != nil {
+		return nil, err
+	}
+	return productMediaItems(product, videos), nil
+}
+
+// AddUpload thêm video upload lên server. save lưu file video và chỉ được gọi sau khi sản phẩm đã được
+// kiểm tra; nếu có lỗi, caller xóa file đã lưu (nếu có).
+func (s *ProductMediaService) AddUpload(ctx context.Context, productID uint, fileURL string, save func() error) (*models.ProductMedia, error) {
+	media := &models.ProductMedia{
+		ProductID: productID, Type: models.ProductMediaVideo, Source: models.MediaSourceUpload,
+		URL: fileURL, Status: models.MediaStatusProcessing,
+	}
+	if err := s.add(ctx, media, save); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// AddExternal gắn video YouTube hoặc Vimeo vào sản phẩm; URL được chuẩn hóa về trang xem video và dựng
+// sẵn URL nhúng
+func (s *ProductMediaService) AddExternal(ctx context.Context, productID uint, req *models.AddExternalVideoRequest) (*models.ProductMedia, error) {
+	video, err := parseVideoURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	media := &models.ProductMedia{
+		ProductID: productID, Type: models.ProductMediaVideo, Source: video.source,
+		URL: video.url, EmbedURL: video.embedURL, Title: strings.TrimSpace(req.Title), Status: models.MediaStatusProcessing,
+	}
+	if err := s.add(ctx, media, nil); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+func (s *ProductMediaService) add(ctx context.Context, media *models.ProductMedia, save func() error) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Khóa sản phẩm để các lần thêm đồng thời không vượt quá giới hạn
+		if _, err := s.products.GetForUpdate(ctx, media.ProductID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProductNotFound
+			}
+			return err
+		}
+		count, err := s.repo.CountByProduct(ctx, media.ProductID)
+		if err != nil {
+			return err
+		}
+		if count >= models.MaxProductVideos {
+			return ErrProductMediaLimit
+		}
+		if save != nil {
+			if err := save(); err != nil {
+				return err
+			}
+		}
+		return s.repo.Create(ctx, media)
+	})
+}
+
+// Delete xóa video của sản phẩm và trả về bản ghi đã xóa để caller xóa file đi kèm
+func (s *ProductMediaService) Delete(ctx context.Context, productID, id uint) (*models.ProductMedia, error) {
+	media, err := s.repo.Get(ctx, productID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductMediaNotFound
+		}
+		return nil, err
+	}
+	if err := s.repo.Delete(ctx, media.ID); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+func (s *ProductMediaService) product(ctx context.Context, productID uint) (*models.Product, error) {
+	product, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return product, nil
+}
+
+// productMediaItems đặt ảnh chính của sản phẩm (ID 0; poster là thumbnail với ảnh upload) trước các video
+func productMediaItems(product *models.Product, videos []models.ProductMedia) []models.ProductMedia {
+	items := make([]models.ProductMedia, 0, len(videos)+1)
+	if product.ImageURL != "" {
+		image := models.ProductMedia{
+			ProductID: product.ID, Type: models.ProductMediaImage, Source: models.MediaSourceUpload,
+			URL: product.ImageURL, Status: models.MediaStatusReady, CreatedAt: product.CreatedAt, UpdatedAt: product.UpdatedAt,
+		}
+		if !strings.Contains(product.ImageURL, "://") {
+			image.PosterURL = thumbnailKey(product.ImageURL)
+		}
+		items = append(items, image)
+	}
+	return append(items, videos...)
+}
+
+// externalVideo là video YouTube/Vimeo đã được nhận diện từ URL admin gửi
+type externalVideo struct {
+	source   string
+	url      string
+	embedURL string
+}
+
+// parseVideoURL nhận diện URL YouTube (watch, youtu.be, embed, shorts) và Vimeo (vimeo.com/<id>, kể cả
+// video không công khai vimeo.com/<id>/<hash>, và player.vimeo.com/video/<id>)
+func parseVideoURL(raw string) (*externalVideo, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("%w: must be an http(s) YouTube or Vimeo link", ErrInvalidVideoURL)
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+
+	var youTubeID string
+	switch host {
+	case "youtube.com", "m.youtube.com", "music.youtube.com", "youtube-nocookie.com":
+		switch {
+		case len(segments) == 1 && segments[0] == "watch":
+			youTubeID = u.Query().Get("v")
+		case len(segments) == 2 && (segments[0] == "embed" || segments[0] == "shorts" || segments[0] == "live"):
+			youTubeID = segments[1]
+		}
+	case "youtu.be":
+		if len(segments) == 1 {
+			youTubeID = segments[0]
+		}
+	case "vimeo.com", "player.vimeo.com":
+		if host == "player.vimeo.com" {
+			if len(segments) != 2 || segments[0] != "video" {
+				break
+			}
+			segments = []string{segments[1]}
+			if h := u.Query().Get("h"); h != "" {
+				segments = append(segments, h)
+			}
+		} else if len(segments) >= 3 && segments[0] == "channels" {
+			segments = segments[2:]
+		}
+		if len(segments) == 0 || len(segments) > 2 || !vimeoIDPattern.MatchString(segments[0]) {
+			break
+		}
+		video := &externalVideo{
+			source:   models.MediaSourceVimeo,
+			url:      "https://vimeo.com/" + segments[0],
+			embedURL: "https://player.vimeo.com/video/" + segments[0],
+		}
+		if len(segments) == 2 {
+			if !vimeoHashPattern.MatchString(segments[1]) {
+				break
+			}
+			video.url += "/" + segments[1]
+			video.embedURL += "?h=" + segments[1]
+		}
+		return video, nil
+	}
+	if youTubeIDPattern.MatchString(youTubeID) {
+		return &externalVideo{
+			source:   models.MediaSourceYouTube,
+			url:      "https://www.youtube.com/watch?v=" + youTubeID,
+			embedURL: "https://www.youtube.com/embed/" + youTubeID,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: only YouTube and Vimeo video links are supported", ErrInvalidVideoURL)
+}
@@ -0,0 +1,357 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRevisionNotFound   = errors.New("product revision not found")
+	ErrRevisionNotPending = errors.New("product revision has already been resolved")
+	ErrRevisionEmpty      = errors.New("product revision does not change anything")
+	// ErrRevisionSelfReview: người gửi không được tự duyệt hoặc từ chối revision của mình
+	ErrRevisionSelfReview = errors.New("product revision cannot be reviewed by its submitter")
+	// ErrRevisionConflict: trường trong revision đã bị sửa sau khi gửi
+	ErrRevisionConflict = errors.New("product revision conflicts with later changes")
+
+	// errRevisionDryRun rollback transaction kiểm tra thử thay đổi lúc gửi revision
+	errRevisionDryRun = errors.New("dry run")
+)
+
+// revisionFields là các trường sản phẩm revision được thay đổi, theo thứ tự hiển thị trong diff
+var revisionFields = []string{
+	"name", "sku", "description", "price", "stock", "image_url", "category", "specs", "weight_grams", "status", "publish_at",
+}
+
+// ProductRevisionService quản lý quy trình duyệt thay đổi sản phẩm hai bước: biên tập viên gửi thay đổi dưới
+// dạng revision chờ duyệt, admin khác duyệt (thay đổi được áp dụng qua ProductService trong cùng transaction)
+// hoặc từ chối
+type ProductRevisionService struct {
+	tx          *repository.TxManager
+	repo        *repository.ProductRevisionRepository
+	productRepo *repository.ProductRepository
+	products    *ProductService
+	bus         *events.Bus
+}
+
+func NewProductRevisionService(db *gorm.DB, bus *events.Bus) *ProductRevisionService {
+	return &ProductRevisionService{
+		tx:          repository.NewTxManager(db),
+		repo:        repository.NewProductRevisionRepository(db),
+		productRepo: repository.NewProductRepository(db),
+		products:    NewProductService(db, bus),
+		bus:         bus,
+	}
+}
+
+// Submit gửi thay đổi sản phẩm chờ duyệt. version là version client đã đọc như khi sửa trực tiếp; chỉ các
+// trường khác giá trị hiện tại được lưu. Thay đổi được kiểm tra thử (tên, SKU, thông số, ảnh, lịch đăng)
+// trong transaction bị rollback để lỗi được báo ngay lúc gửi thay vì lúc duyệt.
+func (s *ProductRevisionService) Submit(ctx context.Context, userID, productID uint, version int, req *models.SubmitProductRevisionRequest) (*models.ProductRevisionView, error) {
+	product, err := s.products.getByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Version != version {
+		return nil, ErrProductVersionConflict
+	}
+
+	changes := req.UpdateProductRequest
+	changes.Version = nil
+	proposed, err := fieldValues(&changes)
+	if err != nil {
+		return nil, err
+	}
+	current, err := productFieldValues(product)
+	if err != nil {
+		return nil, err
+	}
+	changed, original := map[string]json.RawMessage{}, map[string]json.RawMessage{}
+	for field, value := range proposed {
+		// Gửi lại status của sản phẩm đang hẹn giờ đăng vẫn là thay đổi: nó hủy lịch đăng
+		keep := field == "status" && product.PublishAt != nil && proposed["publish_at"] == nil
+		if keep || !bytes.Equal(value, current[field]) {
+			changed[field], original[field] = value, current[field]
+		}
+	}
+	if len(changed) == 0 {
+		return nil, ErrRevisionEmpty
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, _, err := s.products.update(ctx, productID, version, &changes, models.SnapshotUpdated); err != nil {
+			return err
+		}
+		return errRevisionDryRun
+	})
+	if !errors.Is(err, errRevisionDryRun) {
+		return nil, err
+	}
+
+	changesJSON, _ := json.Marshal(changed)
+	originalJSON, _ := json.Marshal(original)
+	revision := &models.ProductRevision{
+		ProductID: productID, BaseVersion: version, Changes: models.JSON(changesJSON), Original: models.JSON(originalJSON),
+		Note: strings.TrimSpace(req.Note), Status: models.RevisionStatusPending, SubmittedBy: userID,
+	}
+	if err := s.repo.Create(ctx, revision); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, revision.ID, 0)
+}
+
+// List lấy danh sách revision kèm diff so với sản phẩm hiện tại
+func (s *ProductRevisionService) List(ctx context.Context, query *models.ProductRevisionQueryParams) ([]models.ProductRevisionView, int64, error) {
+	revisions, total, err := s.repo.GetAll(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	views := make([]models.ProductRevisionView, len(revisions))
+	for i := range revisions {
+		view, err := revisionView(&revisions[i])
+		if err != nil {
+			return nil, 0, err
+		}
+		views[i] = *view
+	}
+	return views, total, nil
+}
+
+// Get lấy revision kèm diff; submittedBy khác 0 thì chỉ cho revision của người đó
+func (s *ProductRevisionService) Get(ctx context.Context, id, submittedBy uint) (*models.ProductRevisionView, error) {
+	revision, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && submittedBy != 0 && revision.SubmittedBy != submittedBy) {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return revisionView(revision)
+}
+
+// Cancel cho người gửi rút lại revision còn chờ duyệt
+func (s *ProductRevisionService) Cancel(ctx context.Context, userID, id uint) (*models.ProductRevisionView, error) {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		revision, err := s.pending(ctx, id)
+		if err != nil {
+			return err
+		}
+		if revision.SubmittedBy != userID {
+			return ErrRevisionNotFound
+		}
+		return s.setStatus(ctx, revision, models.RevisionStatusCancelled, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, id, 0)
+}
+
+// Approve áp dụng revision vào sản phẩm. Nếu sản phẩm đã bị sửa sau khi gửi, revision vẫn được áp dụng
+// khi các trường của nó chưa bị đổi; ngược lại trả về ErrRevisionConflict để admin từ chối và biên tập
+// viên gửi lại trên bản mới.
+func (s *ProductRevisionService) Approve(ctx context.Context, adminID, id uint, note string) (*models.ProductRevisionView, error) {
+	var before, after models.Product
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		revision, err := s.pending(ctx, id)
+		if err != nil {
+			return err
+		}
+		if revision.SubmittedBy == adminID {
+			return ErrRevisionSelfReview
+		}
+		product, err := s.productRepo.GetForUpdate(ctx, revision.ProductID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrProductNotFound
+		}
+		if err != nil {
+			return err
+		}
+		diff, err := revisionDiff(revision, product)
+		if err != nil {
+			return err
+		}
+		var conflicts []string
+		for _, change := range diff {
+			if change.Conflict {
+				conflicts = append(conflicts, change.Field)
+			}
+		}
+		if len(conflicts) > 0 {
+			return fmt.Errorf("%w: %s changed since the revision was submitted", ErrRevisionConflict, strings.Join(conflicts, ", "))
+		}
+
+		var changes models.UpdateProductRequest
+		if err := json.Unmarshal([]byte(revision.Changes), &changes); err != nil {
+			return err
+		}
+		b, updated, err := s.products.update(ctx, product.ID, product.Version, &changes, models.SnapshotUpdated)
+		if err != nil {
+			return err
+		}
+		before, after = b, *updated
+		return s.setStatus(ctx, revision, models.RevisionStatusApproved, map[string]interface{}{
+			"reviewed_by": adminID, "review_note": strings.TrimSpace(note), "applied_version": updated.Version,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.products.publishUpdate(ctx, before, after)
+	view, err := s.Get(ctx, id, 0)
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(ctx, events.ProductRevisionApproved{Revision: view.ProductRevision, Product: after, At: *view.ReviewedAt})
+	return view, nil
+}
+
+// Reject từ chối revision còn chờ duyệt kèm lý do cho người gửi
+func (s *ProductRevisionService) Reject(ctx context.Context, adminID, id uint, note string) (*models.ProductRevisionView, error) {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		revision, err := s.pending(ctx, id)
+		if err != nil {
+			return err
+		}
+		if revision.SubmittedBy == adminID {
+			return ErrRevisionSelfReview
+		}
+		return s.setStatus(ctx, revision, models.RevisionStatusRejected, map[string]interface{}{
+			"reviewed_by": adminID, "review_note": strings.TrimSpace(note),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	view, err := s.Get(ctx, id, 0)
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(ctx, events.ProductRevisionRejected{Revision: view.ProductRevision, At: *view.ReviewedAt})
+	return view, nil
+}
+
+// pending khóa revision và kiểm tra còn chờ duyệt
+func (s *ProductRevisionService) pending(ctx context.Context, id uint) (*models.ProductRevision, error) {
+	revision, err := s.repo.GetForUpdate(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if revision.Status != models.RevisionStatusPending {
+		return nil, fmt.Errorf("%w: revision is %s", ErrRevisionNotPending, revision.Status)
+	}
+	return revision, nil
+}
+
+// setStatus chuyển revision đang chờ sang trạng thái to; revision đã duyệt hoặc từ chối có thêm reviewed_at
+func (s *ProductRevisionService) setStatus(ctx context.Context, revision *models.ProductRevision, to string, fields map[string]interface{}) error {
+	if fields != nil {
+		fields["reviewed_at"] = time.Now()
+	}
+	rows, err := s.repo.UpdateStatus(ctx, revision.ID, revision.Status, to, fields)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrRevisionNotPending
+	}
+	return nil
+}
+
+// revisionView dựng revision kèm diff; revision cần được lấy cùng sản phẩm
+func revisionView(revision *models.ProductRevision) (*models.ProductRevisionView, error) {
+	view := &models.ProductRevisionView{ProductRevision: *revision}
+	if revision.Product == nil {
+		return view, nil
+	}
+	diff, err := revisionDiff(revision, revision.Product)
+	if err != nil {
+		return nil, err
+	}
+	view.ProductName, view.Diff = revision.Product.Name, diff
+	view.Stale = revision.Status == models.RevisionStatusPending && revision.Product.Version != revision.BaseVersion
+	view.ProductRevision.Product = nil
+	return view, nil
+}
+
+// revisionDiff so sánh từng trường của revision với giá trị lúc gửi và giá trị hiện tại của sản phẩm;
+// xung đột chỉ được đánh dấu với revision còn chờ duyệt
+func revisionDiff(revision *models.ProductRevision, product *models.Product) ([]models.ProductFieldChange, error) {
+	var changes, original map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(revision.Changes), &changes); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(revision.Original), &original); err != nil {
+		return nil, err
+	}
+	current, err := productFieldValues(product)
+	if err != nil {
+		return nil, err
+	}
+	diff := make([]models.ProductFieldChange, 0, len(changes))
+	for _, field := range revisionFields {
+		to, ok := changes[field]
+		if !ok {
+			continue
+		}
+		change := models.ProductFieldChange{Field: field, From: original[field], To: to, Current: current[field]}
+		change.Conflict = revision.Status == models.RevisionStatusPending && !bytes.Equal(change.From, change.Current)
+		diff = append(diff, change)
+	}
+	return diff, nil
+}
+
+// productFieldValues trả về giá trị JSON của các trường sản phẩm revision có thể đổi, cùng dạng với
+// UpdateProductRequest để so sánh trực tiếp; trường không có giá trị (SKU, lịch đăng) là null
+func productFieldValues(product *models.Product) (map[string]json.RawMessage, error) {
+	values, err := fieldValues(&models.UpdateProductRequest{
+		Name: &product.Name, SKU: product.SKU, Description: &product.Description, Price: &product.Price,
+		Stock: &product.Stock, ImageURL: &product.ImageURL, Category: &product.Category, Specs: product.Specs,
+		WeightGrams: &product.WeightGrams, Status: &product.Status, PublishAt: product.PublishAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range revisionFields {
+		if _, ok := values[field]; !ok {
+			values[field] = json.RawMessage("null")
+		}
+	}
+	return values, nil
+}
+
+// fieldValues trả về các trường có giá trị (khác null) của request theo tên JSON
+func fieldValues(req *models.UpdateProductRequest) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	for field, value := range values {
+		if field == "version" || string(value) == "null" {
+			delete(values, field)
+		}
+	}
+	if req.Specs == nil {
+		delete(values, "specs")
+	}
+	return values, nil
+}
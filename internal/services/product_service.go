@@ -0,0 +1,636 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/actor"
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/imageurl"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProductNotFound   = errors.New("product not found")
+	ErrProductNameExists = errors.New("product name already exists")
+	// ErrProductVersionConflict: sản phẩm đã bị sửa sau lần đọc của client
+	ErrProductVersionConflict = errors.New("product was modified by another request")
+	ErrInvalidProductSchedule = errors.New("invalid publish schedule")
+	ErrProductSKUExists       = errors.New("product SKU already exists")
+	// ErrProductUnavailable: sản phẩm draft hoặc archived, không bán được
+	ErrProductUnavailable = errors.New("product is not available for sale")
+	// ErrInvalidImageURL: image_url không phải ảnh đã upload hoặc URL https ở host được cho phép
+	ErrInvalidImageURL = errors.New("invalid image URL")
+	// ErrProductSnapshotNotFound: không có bản lưu lịch sử với version này (chưa từng có hoặc đã bị dọn)
+	ErrProductSnapshotNotFound = errors.New("product snapshot not found")
+)
+
+// ProductService chứa các thao tác ghi trên sản phẩm và phát sự kiện domain tương ứng
+type ProductService struct {
+	repo       *repository.ProductRepository
+	attributes *CategoryAttributeService
+	bus        *events.Bus
+	images     imageurl.Policy
+	tx         *repository.TxManager
+	history    *repository.ProductSnapshotRepository
+	// historyLimit là số bản lưu lịch sử giữ lại cho mỗi sản phẩm (PRODUCT_HISTORY_LIMIT)
+	historyLimit int
+	uploadDir    string
+}
+
+func NewProductService(db *gorm.DB, bus *events.Bus) *ProductService {
+	return &ProductService{
+		repo:       repository.NewProductRepository(db),
+		attributes: NewCategoryAttributeService(db),
+		bus:        bus,
+		images:     imageurl.PolicyFromEnv(),
+		tx:         repository.NewTxManager(db),
+		history:    repository.NewProductSnapshotRepository(db),
+
+		historyLimit: config.Int("PRODUCT_HISTORY_LIMIT", 50),
+		uploadDir:    "static/uploads",
+	}
+}
+
+// isUniqueViolation kiểm tra lỗi vi phạm ràng buộc UNIQUE của PostgreSQL (mã 23505)
+func isUniqueViolation(err error) bool {
+	return strings.Contains(err.Error(), "unique_violation") || strings.Contains(err.Error(), "23505")
+}
+
+// Create tạo sản phẩm mới
+func (s *ProductService) Create(ctx context.Context, req *models.CreateProductRequest) (*models.Product, error) {
+	nameExists, err := s.repo.CheckIfNameExists(ctx, req.Name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if nameExists {
+		return nil, ErrProductNameExists
+	}
+	sku, err := s.checkSKU(ctx, req.SKU, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attributes.ValidateSpecs(ctx, req.Category, req.Specs); err != nil {
+		return nil, err
+	}
+	if err := s.checkImageURL(req.ImageURL); err != nil {
+		return nil, err
+	}
+
+	status := req.Status
+	if status == "" {
+		status = models.ProductStatusPublished
+		if req.PublishAt != nil {
+			status = models.ProductStatusDraft
+		}
+	}
+
+	product := &models.Product{
+		Name:        req.Name,
+		SKU:         sku,
+		Description: req.Description,
+		Price:       req.Price,
+		Stock:       req.Stock,
+		ImageURL:    req.ImageURL,
+		Category:    req.Category,
+		Specs:       req.Specs,
+		WeightGrams: req.WeightGrams,
+	}
+	if err := applyLifecycle(product, &status, req.PublishAt, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.create(ctx, product); err != nil {
+		return nil, err
+	}
+
+	s.bus.Publish(ctx, events.ProductCreated{Product: *product})
+	return product, nil
+}
+
+// Update cập nhật sản phẩm; chỉ các trường có trong request (khác nil) được ghi.
+// version là version client đã đọc: nếu sản phẩm đã đổi version trả về ErrProductVersionConflict
+// thay vì ghi đè thay đổi của người khác.
+func (s *ProductService) Update(ctx context.Context, id uint, version int, req *models.UpdateProductRequest) (*models.Product, error) {
+	before, product, err := s.update(ctx, id, version, req, models.SnapshotUpdated)
+	if err != nil {
+		return product, err
+	}
+	s.publishUpdate(ctx, before, *product)
+	return product, nil
+}
+
+// update áp dụng và ghi thay đổi của Update mà không phát sự kiện, để caller chạy trong transaction của mình
+// và phát sự kiện sau khi commit; action là thao tác ghi vào lịch sử sản phẩm. Trả về sản phẩm trước và sau
+// khi sửa.
+func (s *ProductService) update(ctx context.Context, id uint, version int, req *models.UpdateProductRequest, action string) (models.Product, *models.Product, error) {
+	product, err := s.getByID(ctx, id)
+	if err != nil {
+		return models.Product{}, nil, err
+	}
+	if product.Version != version {
+		return models.Product{}, product, ErrProductVersionConflict
+	}
+	before := *product
+
+	if req.Name != nil && *req.Name != product.Name { // Chỉ kiểm tra nếu tên mới khác tên cũ
+		nameExists, err := s.repo.CheckIfNameExists(ctx, *req.Name, product.ID)
+		if err != nil {
+			return before, nil, err
+		}
+		if nameExists {
+			return before, nil, ErrProductNameExists
+		}
+		product.Name = *req.Name
+	}
+	if req.SKU != nil {
+		if product.SKU, err = s.checkSKU(ctx, *req.SKU, product.ID); err != nil {
+			return before, nil, err
+		}
+	}
+	if req.Description != nil {
+		product.Description = *req.Description
+	}
+	if req.Price != nil {
+		product.Price = *req.Price
+	}
+	if req.Stock != nil {
+		product.Stock = *req.Stock
+	}
+	if req.ImageURL != nil && *req.ImageURL != product.ImageURL {
+		if err := s.checkImageURL(*req.ImageURL); err != nil {
+			return before, nil, err
+		}
+		product.ImageURL = *req.ImageURL
+	}
+	if req.Category != nil {
+		product.Category = *req.Category
+	}
+	if req.Specs != nil {
+		product.Specs = req.Specs
+	}
+	if req.WeightGrams != nil {
+		product.WeightGrams = *req.WeightGrams
+	}
+	if err := applyLifecycle(product, req.Status, req.PublishAt, time.Now()); err != nil {
+		return before, nil, err
+	}
+	// Thông số được kiểm tra lại khi đổi thông số hoặc đổi danh mục
+	if req.Specs != nil || product.Category != before.Category {
+		if err := s.attributes.ValidateSpecs(ctx, product.Category, product.Specs); err != nil {
+			return before, nil, err
+		}
+	}
+
+	if err := s.save(ctx, product, action); err != nil {
+		return before, nil, err
+	}
+	return before, product, nil
+}
+
+// PublishScheduled đăng các sản phẩm draft đã tới PublishAt; trả về số sản phẩm đã đăng.
+// Sản phẩm bị admin sửa đồng thời được bỏ qua và thử lại ở lần chạy sau.
+func (s *ProductService) PublishScheduled(ctx context.Context, now time.Time) (int, error) {
+	products, err := s.repo.GetDueForPublish(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	published := 0
+	for i := range products {
+		product := &products[i]
+		before := *product
+		product.Status, product.PublishAt = models.ProductStatusPublished, nil
+		if err := s.save(ctx, product, models.SnapshotPublished); err != nil {
+			if errors.Is(err, ErrProductVersionConflict) {
+				continue
+			}
+			return published, err
+		}
+		s.publishUpdate(ctx, before, *product)
+		published++
+	}
+	return published, nil
+}
+
+// Duplicate tạo bản sao draft của sản phẩm để admin sửa thành mặt hàng tương tự: tên thêm hậu tố "(copy)",
+// SKU là placeholder "<SKU gốc>-COPY" (hoặc "P<ID gốc>-COPY") cần được thay, ảnh dùng chung với sản phẩm
+// gốc. Tồn kho bắt đầu từ 0; lịch đăng và giá flash sale không được sao chép.
+func (s *ProductService) Duplicate(ctx context.Context, id uint) (*models.Product, error) {
+	source, err := s.getByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	name, err := s.copyName(ctx, source.Name)
+	if err != nil {
+		return nil, err
+	}
+	base := fmt.Sprintf("P%d", source.ID)
+	if source.SKU != nil {
+		base = *source.SKU
+	}
+	sku, err := s.copySKU(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+
+	product := &models.Product{
+		Name:        name,
+		SKU:         &sku,
+		Description: source.Description,
+		Price:       source.Price,
+		ImageURL:    source.ImageURL,
+		Category:    source.Category,
+		Specs:       source.Specs,
+		Status:      models.ProductStatusDraft,
+		WeightGrams: source.WeightGrams,
+	}
+	if err := s.create(ctx, product); err != nil {
+		return nil, err
+	}
+	s.bus.Publish(ctx, events.ProductCreated{Product: *product})
+	return product, nil
+}
+
+// copyName trả về "<name> (copy)", hoặc "<name> (copy N)" nếu tên đó đã có
+func (s *ProductService) copyName(ctx context.Context, name string) (string, error) {
+	for n := 1; ; n++ {
+		candidate := name + " (copy)"
+		if n > 1 {
+			candidate = fmt.Sprintf("%s (copy %d)", name, n)
+		}
+		exists, err := s.repo.CheckIfNameExists(ctx, candidate, 0)
+		if err != nil || !exists {
+			return candidate, err
+		}
+	}
+}
+
+// copySKU trả về "<base>-COPY", hoặc "<base>-COPY-N" nếu SKU đó đã có; base bị cắt để vừa 64 ký tự
+func (s *ProductService) copySKU(ctx context.Context, base string) (string, error) {
+	for n := 1; ; n++ {
+		suffix := "-COPY"
+		if n > 1 {
+			suffix = fmt.Sprintf("-COPY-%d", n)
+		}
+		candidate := base
+		if len(candidate)+len(suffix) > 64 {
+			candidate = candidate[:64-len(suffix)]
+		}
+		candidate += suffix
+		exists, err := s.repo.CheckIfSKUExists(ctx, candidate, 0)
+		if err != nil || !exists {
+			return candidate, err
+		}
+	}
+}
+
+// checkSKU chuẩn hóa SKU trong request (chuỗi rỗng là không có SKU) và kiểm tra SKU chưa được sản phẩm
+// khác dùng
+func (s *ProductService) checkSKU(ctx context.Context, sku string, excludeID uint) (*string, error) {
+	sku = strings.TrimSpace(sku)
+	if sku == "" {
+		return nil, nil
+	}
+	exists, err := s.repo.CheckIfSKUExists(ctx, sku, excludeID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrProductSKUExists
+	}
+	return &sku, nil
+}
+
+// checkImageURL kiểm tra image_url client gửi trực tiếp: chỉ nhận đường dẫn ảnh đã upload hoặc URL https ở
+// host trong PRODUCT_IMAGE_HOSTS. URL ở host khác phải được handler tải về (PRODUCT_IMAGE_REHOST) trước
+// khi tới đây; chuỗi rỗng (không có ảnh) luôn hợp lệ.
+func (s *ProductService) checkImageURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	kind, err := s.images.Classify(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImageURL, err)
+	}
+	if kind == imageurl.Remote {
+		return fmt.Errorf("%w: images from other hosts must be downloaded first", ErrInvalidImageURL)
+	}
+	return nil
+}
+
+// SetImage cập nhật ảnh của sản phẩm
+func (s *ProductService) SetImage(ctx context.Context, id uint, imageURL string) (*models.Product, error) {
+	product, err := s.getByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *product
+
+	product.ImageURL = imageURL
+	if err := s.save(ctx, product, models.SnapshotUpdated); err != nil {
+		return nil, err
+	}
+
+	s.publishUpdate(ctx, before, *product)
+	return product, nil
+}
+
+// History lấy lịch sử sửa đổi của sản phẩm, mới nhất trước; mỗi bản lưu kèm các trường đã đổi so với bản lưu
+// liền trước
+func (s *ProductService) History(ctx context.Context, id uint, page, limit int) ([]models.ProductSnapshotView, int64, error) {
+	if _, err := s.getByID(ctx, id); err != nil {
+		return nil, 0, err
+	}
+	// Lấy thêm một bản lưu cũ hơn để tính trường đã đổi của bản cuối trang
+	snapshots, total, err := s.history.ListByProduct(ctx, id, (page-1)*limit, limit+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	views := make([]models.ProductSnapshotView, 0, limit)
+	for i := 0; i < len(snapshots) && i < limit; i++ {
+		view := models.ProductSnapshotView{
+			ProductSnapshot: snapshots[i], Snapshot: json.RawMessage(snapshots[i].Data), ChangedFields: []string{},
+		}
+		if i+1 < len(snapshots) {
+			if view.ChangedFields, err = snapshotChanges(&snapshots[i+1], &snapshots[i]); err != nil {
+				return nil, 0, err
+			}
+		}
+		views = append(views, view)
+	}
+	return views, total, nil
+}
+
+// Restore đưa sản phẩm về nội dung của bản lưu rev; version là version hiện tại client đã đọc. Tồn kho không
+// được khôi phục; lịch đăng chỉ được khôi phục nếu vẫn ở tương lai, và ảnh cũ đã bị xóa hoặc không còn được
+// phép thì sản phẩm giữ ảnh hiện tại. Lần khôi phục được ghi thành một bản lưu mới.
+func (s *ProductService) Restore(ctx context.Context, id uint, version, rev int) (*models.Product, error) {
+	snapshot, err := s.history.Get(ctx, id, rev)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductSnapshotNotFound
+		}
+		return nil, err
+	}
+	var old models.Product
+	if err := json.Unmarshal([]byte(snapshot.Data), &old); err != nil {
+		return nil, err
+	}
+
+	sku := ""
+	if old.SKU != nil {
+		sku = *old.SKU
+	}
+	specs := old.Specs
+	if specs == nil {
+		specs = models.Specs{}
+	}
+	req := &models.UpdateProductRequest{
+		Name:        &old.Name,
+		SKU:         &sku,
+		Description: &old.Description,
+		Price:       &old.Price,
+		Category:    &old.Category,
+		Specs:       specs,
+		WeightGrams: &old.WeightGrams,
+		Status:      &old.Status,
+	}
+	if s.restorableImage(old.ImageURL) {
+		req.ImageURL = &old.ImageURL
+	}
+	if old.Status == models.ProductStatusDraft && old.PublishAt != nil && old.PublishAt.After(time.Now()) {
+		req.PublishAt = old.PublishAt
+	}
+
+	before, product, err := s.update(ctx, id, version, req, models.SnapshotRestored)
+	if err != nil {
+		return product, err
+	}
+	s.publishUpdate(ctx, before, *product)
+	return product, nil
+}
+
+// restorableImage cho biết ảnh của bản lưu còn dùng lại được: ảnh upload phải còn file (ảnh bị thay đã bị
+// xóa), URL ngoài phải vẫn thuộc host được cho phép
+func (s *ProductService) restorableImage(u string) bool {
+	if u == "" {
+		return true
+	}
+	kind, err := s.images.Classify(u)
+	switch {
+	case err != nil || kind == imageurl.Remote:
+		return false
+	case kind == imageurl.Local:
+		_, err := os.Stat(filepath.Join(s.uploadDir, filepath.FromSlash(uploadKey(u))))
+		return err == nil
+	}
+	return true
+}
+
+// snapshotChanges trả về các trường sản phẩm khác nhau giữa hai bản lưu, theo thứ tự của revisionFields
+func snapshotChanges(older, newer *models.ProductSnapshot) ([]string, error) {
+	var a, b models.Product
+	if err := json.Unmarshal([]byte(older.Data), &a); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(newer.Data), &b); err != nil {
+		return nil, err
+	}
+	// Sản phẩm vừa tạo không có thông số được lưu là null, sau khi đọc lại từ DB là {}
+	for _, p := range []*models.Product{&a, &b} {
+		if p.Specs == nil {
+			p.Specs = models.Specs{}
+		}
+	}
+	from, err := productFieldValues(&a)
+	if err != nil {
+		return nil, err
+	}
+	to, err := productFieldValues(&b)
+	if err != nil {
+		return nil, err
+	}
+	changed := []string{}
+	for _, field := range revisionFields {
+		if !bytes.Equal(from[field], to[field]) {
+			changed = append(changed, field)
+		}
+	}
+	return changed, nil
+}
+
+// CheckStock kiểm tra tồn kho hiện tại cho từng dòng, không giữ hàng; sản phẩm không tồn tại hoặc chưa
+// đăng được báo là unavailable
+func (s *ProductService) CheckStock(ctx context.Context, items []models.StockCheckItem) (*models.StockCheckResponse, error) {
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ProductID
+	}
+	products, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	resp := &models.StockCheckResponse{Items: make([]models.StockCheckLine, len(items)), Available: true}
+	for i, item := range items {
+		line := models.StockCheckLine{ProductID: item.ProductID, Quantity: item.Quantity}
+		product, ok := byID[item.ProductID]
+		switch {
+		case !ok || product.Status != models.ProductStatusPublished:
+			line.Status = models.StockUnavailable
+		case product.Stock <= 0:
+			line.Status = models.StockOutOfStock
+		case product.Stock < item.Quantity:
+			line.Status, line.AvailableQuantity = models.StockInsufficient, product.Stock
+		default:
+			line.Status, line.AvailableQuantity = models.StockAvailable, item.Quantity
+		}
+		if ok && line.Status != models.StockUnavailable {
+			line.UnitPrice = product.EffectivePrice()
+		}
+		if line.Status != models.StockAvailable {
+			resp.Available = false
+		}
+		resp.Items[i] = line
+	}
+	return resp, nil
+}
+
+// Delete xóa sản phẩm
+func (s *ProductService) Delete(ctx context.Context, id uint) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.bus.Publish(ctx, events.ProductDeleted{ProductID: id})
+	return nil
+}
+
+// create lưu sản phẩm mới cùng bản lưu lịch sử đầu tiên
+func (s *ProductService) create(ctx context.Context, product *models.Product) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, product); err != nil {
+			if isUniqueViolation(err) {
+				return ErrProductNameExists
+			}
+			return err
+		}
+		return s.record(ctx, product, models.SnapshotCreated)
+	})
+}
+
+// save ghi sản phẩm có kiểm tra version cùng bản lưu lịch sử của lần ghi; ghi đồng thời giữa lúc đọc và lúc
+// ghi trả về ErrProductVersionConflict
+func (s *ProductService) save(ctx context.Context, product *models.Product, action string) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		updated, err := s.repo.Update(ctx, product)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return ErrProductNameExists
+			}
+			return err
+		}
+		if updated == 0 {
+			return ErrProductVersionConflict
+		}
+		return s.record(ctx, product, action)
+	})
+}
+
+// record lưu trạng thái hiện tại của sản phẩm vào lịch sử, tác giả là người thực hiện request (nếu có), rồi
+// dọn các bản lưu vượt quá historyLimit
+func (s *ProductService) record(ctx context.Context, product *models.Product, action string) error {
+	data, err := json.Marshal(product)
+	if err != nil {
+		return err
+	}
+	snapshot := &models.ProductSnapshot{
+		ProductID: product.ID,
+		Version:   product.Version,
+		Action:    action,
+		Data:      models.JSON(data),
+	}
+	if userID, ok := actor.From(ctx); ok {
+		snapshot.AuthorID = &userID
+	}
+	if err := s.history.Create(ctx, snapshot); err != nil {
+		return err
+	}
+	if s.historyLimit > 0 {
+		if _, err := s.history.Prune(ctx, product.ID, s.historyLimit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyLifecycle áp dụng trạng thái và lịch đăng được yêu cầu (nil là giữ nguyên). Đổi trạng thái hủy
+// lịch đăng cũ; chỉ sản phẩm draft được hẹn giờ đăng và thời điểm đăng phải ở tương lai.
+func applyLifecycle(product *models.Product, status *string, publishAt *time.Time, now time.Time) error {
+	if status != nil {
+		product.Status, product.PublishAt = *status, nil
+	}
+	if publishAt == nil {
+		return nil
+	}
+	if product.Status != models.ProductStatusDraft {
+		return fmt.Errorf("%w: only draft products can be scheduled", ErrInvalidProductSchedule)
+	}
+	if !publishAt.After(now) {
+		return fmt.Errorf("%w: publish_at must be in the future", ErrInvalidProductSchedule)
+	}
+	product.PublishAt = publishAt
+	return nil
+}
+
+func (s *ProductService) getByID(ctx context.Context, id uint) (*models.Product, error) {
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return product, nil
+}
+
+// publishUpdate phát ProductUpdated cùng các sự kiện thay đổi tồn kho/giá nếu có
+func (s *ProductService) publishUpdate(ctx context.Context, before, after models.Product) {
+	publishProductUpdate(ctx, s.bus, before, after)
+}
+
+// publishProductUpdate phát ProductUpdated, StockChanged khi tồn kho đổi, PriceChanged khi giá bán đổi và
+// ProductImageReplaced khi ảnh cũ bị thay
+func publishProductUpdate(ctx context.Context, bus *events.Bus, before, after models.Product) {
+	bus.Publish(ctx, events.ProductUpdated{Product: after})
+	if before.ImageURL != "" && before.ImageURL != after.ImageURL {
+		bus.Publish(ctx, events.ProductImageReplaced{
+			ProductID: after.ID, OldURL: before.ImageURL, NewURL: after.ImageURL, At: time.Now(),
+		})
+	}
+	if before.Stock != after.Stock {
+		bus.Publish(ctx, events.StockChanged{
+			ProductID: after.ID, Name: after.Name,
+			OldStock: before.Stock, NewStock: after.Stock, ChangedAt: time.Now(),
+		})
+	}
+	// Khi đang flash sale, đổi giá gốc không làm đổi giá bán
+	if before.EffectivePrice() != after.EffectivePrice() {
+		bus.Publish(ctx, events.PriceChanged{
+			ProductID: after.ID, Name: after.Name,
+			OldPrice: before.EffectivePrice(), NewPrice: after.EffectivePrice(), ChangedAt: time.Now(),
+		})
+	}
+}
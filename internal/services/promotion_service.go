@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/promotion"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPromotionNotFound = errors.New("promotion not found")
+	ErrInvalidPromotion  = errors.New("invalid promotion")
+)
+
+// PromotionService quản lý khuyến mãi tự động và tính giảm giá cho giỏ hàng, đơn hàng
+type PromotionService struct {
+	repo *repository.PromotionRepository
+}
+
+func NewPromotionService(db *gorm.DB) *PromotionService {
+	return &PromotionService{repo: repository.NewPromotionRepository(db)}
+}
+
+// Evaluate tính giảm giá cho các dòng hàng theo khuyến mãi đang hiệu lực
+func (s *PromotionService) Evaluate(ctx context.Context, lines []promotion.Line) (promotion.Result, error) {
+	now := time.Now()
+	promotions, err := s.repo.ListActive(ctx, now)
+	if err != nil {
+		return promotion.Result{}, err
+	}
+	return promotion.Evaluate(promotions, lines, now), nil
+}
+
+// List lấy danh sách khuyến mãi (Admin)
+func (s *PromotionService) List(ctx context.Context, query *models.PromotionQueryParams) ([]models.Promotion, error) {
+	return s.repo.List(ctx, query)
+}
+
+// ListActive lấy các khuyến mãi đang hiệu lực để hiển thị cho khách
+func (s *PromotionService) ListActive(ctx context.Context) ([]models.Promotion, error) {
+	return s.repo.ListActive(ctx, time.Now())
+}
+
+// Get lấy khuyến mãi theo ID
+func (s *PromotionService) Get(ctx context.Context, id uint) (*models.Promotion, error) {
+	promotion, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPromotionNotFound
+	}
+	return promotion, err
+}
+
+// Create tạo khuyến mãi; mặc định đang bật và được cộng dồn
+func (s *PromotionService) Create(ctx context.Context, req *models.PromotionRequest) (*models.Promotion, error) {
+	promotion := &models.Promotion{Active: true, Stackable: true}
+	if err := applyPromotionRequest(promotion, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, promotion); err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// Update sửa khuyến mãi; active/stackable bỏ trống thì giữ nguyên. Đơn hàng đã đặt không bị ảnh hưởng.
+func (s *PromotionService) Update(ctx context.Context, id uint, req *models.PromotionRequest) (*models.Promotion, error) {
+	promotion, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyPromotionRequest(promotion, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, promotion); err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// Delete xóa khuyến mãi
+func (s *PromotionService) Delete(ctx context.Context, id uint) error {
+	deleted, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrPromotionNotFound
+	}
+	return nil
+}
+
+// applyPromotionRequest chép request vào promotion và kiểm tra các tham số bắt buộc theo loại
+func applyPromotionRequest(p *models.Promotion, req *models.PromotionRequest) error {
+	switch req.Type {
+	case models.PromotionBuyXGetY:
+		if req.BuyQuantity <= 0 || req.GetQuantity <= 0 {
+			return fmt.Errorf("%w: buy_quantity and get_quantity are required", ErrInvalidPromotion)
+		}
+	case models.PromotionCategoryPercent:
+		if req.Percent <= 0 {
+			return fmt.Errorf("%w: percent is required", ErrInvalidPromotion)
+		}
+		if req.Category == "" && req.ProductID == nil {
+			return fmt.Errorf("%w: category or product_id is required", ErrInvalidPromotion)
+		}
+	case models.PromotionCartThreshold:
+		if (req.Percent > 0) == (req.AmountOff > 0) {
+			return fmt.Errorf("%w: exactly one of percent and amount_off is required", ErrInvalidPromotion)
+		}
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidPromotion)
+	}
+
+	p.Name, p.Description, p.Type = req.Name, req.Description, req.Type
+	p.StartsAt, p.EndsAt, p.Priority = req.StartsAt, req.EndsAt, req.Priority
+	p.Category, p.ProductID = req.Category, req.ProductID
+	p.BuyQuantity, p.GetQuantity = req.BuyQuantity, req.GetQuantity
+	p.Percent, p.MinSubtotal, p.AmountOff = req.Percent, req.MinSubtotal, req.AmountOff
+	if req.Active != nil {
+		p.Active = *req.Active
+	}
+	if req.Stackable != nil {
+		p.Stackable = *req.Stackable
+	}
+	return nil
+}
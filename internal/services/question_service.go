@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrQuestionNotFound = errors.New("question not found")
+	ErrAnswerNotFound   = errors.New("answer not found")
+	ErrNotVerifiedBuyer = errors.New("only admins and customers who bought the product can answer")
+	ErrQuestionNotOpen  = errors.New("question is not open for answers")
+)
+
+// QuestionService xử lý hỏi đáp về sản phẩm: user đặt câu hỏi, admin hoặc người đã mua sản phẩm trả
+// lời, admin kiểm duyệt. Nội dung của admin được duyệt ngay, nội dung khác chờ duyệt.
+type QuestionService struct {
+	repo     *repository.ProductQuestionRepository
+	products *repository.ProductRepository
+	orders   *repository.OrderRepository
+}
+
+func NewQuestionService(db *gorm.DB) *QuestionService {
+	return &QuestionService{
+		repo:     repository.NewProductQuestionRepository(db),
+		products: repository.NewProductRepository(db),
+		orders:   repository.NewOrderRepository(db),
+	}
+}
+
+// ListApproved lấy các câu hỏi đã duyệt của sản phẩm
+func (s *QuestionService) ListApproved(ctx context.Context, productID uint, query *models.QuestionQueryParams) ([]models.ProductQuestion, int64, error) {
+	if _, err := s.product(ctx, productID); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListApproved(ctx, productID, query.Page, query.Limit)
+}
+
+// Ask tạo câu hỏi về sản phẩm
+func (s *QuestionService) Ask(ctx context.Context, userID uint, role string, productID uint, body string) (*models.ProductQuestion, error) {
+	if _, err := s.product(ctx, productID); err != nil {
+		return nil, err
+	}
+	question := &models.ProductQuestion{
+		ProductID: productID,
+		UserID:    userID,
+		Body:      strings.TrimSpace(body),
+		Status:    models.ModerationPending,
+	}
+	if role == "admin" {
+		now := time.Now()
+		question.Status, question.ModeratedBy, question.ModeratedAt = models.ModerationApproved, &userID, &now
+	}
+	if err := s.repo.CreateQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+	question.Answers = []models.ProductAnswer{}
+	return question, nil
+}
+
+// Answer trả lời câu hỏi. Admin trả lời được mọi câu hỏi chưa bị từ chối; người đã mua sản phẩm chỉ
+// trả lời được câu hỏi đã duyệt.
+func (s *QuestionService) Answer(ctx context.Context, userID uint, role string, productID, questionID uint, body string) (*models.ProductAnswer, error) {
+	question, err := s.question(ctx, productID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	answer := &models.ProductAnswer{
+		QuestionID: question.ID,
+		UserID:     userID,
+		Body:       strings.TrimSpace(body),
+		Status:     models.ModerationPending,
+	}
+	if role == "admin" {
+		if question.Status == models.ModerationRejected {
+			return nil, ErrQuestionNotOpen
+		}
+		now := time.Now()
+		answer.AuthorRole = models.AnswerAuthorAdmin
+		answer.Status, answer.ModeratedBy, answer.ModeratedAt = models.ModerationApproved, &userID, &now
+	} else {
+		if question.Status != models.ModerationApproved {
+			return nil, ErrQuestionNotOpen
+		}
+		bought, err := s.orders.HasPurchased(ctx, userID, productID)
+		if err != nil {
+			return nil, err
+		}
+		if !bought {
+			return nil, ErrNotVerifiedBuyer
+		}
+		answer.AuthorRole = models.AnswerAuthorVerifiedBuyer
+	}
+	if err := s.repo.CreateAnswer(ctx, answer); err != nil {
+		return nil, err
+	}
+	return answer, nil
+}
+
+// ListQuestions lấy hàng đợi kiểm duyệt câu hỏi (Admin)
+func (s *QuestionService) ListQuestions(ctx context.Context, query *models.ModerationQueryParams) ([]models.ProductQuestion, int64, error) {
+	return s.repo.ListQuestions(ctx, query)
+}
+
+// ListAnswers lấy hàng đợi kiểm duyệt câu trả lời (Admin)
+func (s *QuestionService) ListAnswers(ctx context.Context, query *models.ModerationQueryParams) ([]models.ProductAnswer, int64, error) {
+	return s.repo.ListAnswers(ctx, query)
+}
+
+// ModerateQuestion duyệt hoặc từ chối câu hỏi (Admin)
+func (s *QuestionService) ModerateQuestion(ctx context.Context, adminID, productID, questionID uint, status string) (*models.ProductQuestion, error) {
+	question, err := s.question(ctx, productID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := s.repo.ModerateQuestion(ctx, question.ID, status, adminID, now); err != nil {
+		return nil, err
+	}
+	question.Status, question.ModeratedBy, question.ModeratedAt = status, &adminID, &now
+	return question, nil
+}
+
+// ModerateAnswer duyệt hoặc từ chối câu trả lời (Admin)
+func (s *QuestionService) ModerateAnswer(ctx context.Context, adminID, productID, questionID, answerID uint, status string) (*models.ProductAnswer, error) {
+	question, err := s.question(ctx, productID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	answer, err := s.repo.GetAnswer(ctx, question.ID, answerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAnswerNotFound
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if err := s.repo.ModerateAnswer(ctx, answer.ID, status, adminID, now); err != nil {
+		return nil, err
+	}
+	answer.Status, answer.ModeratedBy, answer.ModeratedAt = status, &adminID, &now
+	return answer, nil
+}
+
+func (s *QuestionService) product(ctx context.Context, productID uint) (*models.Product, error) {
+	product, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return product, nil
+}
+
+func (s *QuestionService) question(ctx context.Context, productID, questionID uint) (*models.ProductQuestion, error) {
+	question, err := s.repo.GetQuestion(ctx, productID, questionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
+	}
+	return question, nil
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/payment"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReturnNotFound    = errors.New("return request not found")
+	ErrReturnNotAllowed  = errors.New("order cannot be returned")
+	ErrReturnQuantity    = errors.New("return quantity exceeds the quantity that can be returned")
+	ErrReturnNotPending  = errors.New("return request has already been resolved")
+	ErrReturnPhotoLimit  = errors.New("return request already has the maximum number of photos")
+	ErrReturnItemInvalid = errors.New("item does not belong to the order")
+)
+
+// DefaultReturnWindow là thời gian khách được yêu cầu trả hàng kể từ khi nhận hàng
+const DefaultReturnWindow = 30 * 24 * time.Hour
+
+// ReturnWindow đọc thời hạn trả hàng từ RETURN_WINDOW (ví dụ 720h), mặc định DefaultReturnWindow
+func ReturnWindow() time.Duration {
+	if v := os.Getenv("RETURN_WINDOW"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultReturnWindow
+}
+
+// ReturnService xử lý yêu cầu trả hàng: khách tạo yêu cầu cho đơn đã giao, admin duyệt
+// (nhập lại kho và hoàn tiền qua cổng thanh toán trong cùng transaction) hoặc từ chối
+type ReturnService struct {
+	tx       *repository.TxManager
+	repo     *repository.ReturnRepository
+	orders   *repository.OrderRepository
+	products *repository.ProductRepository
+	payments *PaymentService
+	window   time.Duration
+	bus      *events.Bus
+}
+
+func NewReturnService(db *gorm.DB, gateways payment.Gateways, bus *events.Bus) *ReturnService {
+	return &ReturnService{
+		tx:       repository.NewTxManager(db),
+		repo:     repository.NewReturnRepository(db),
+		orders:   repository.NewOrderRepository(db),
+		products: repository.NewProductRepository(db),
+		payments: NewPaymentService(db, gateways, bus),
+		window:   ReturnWindow(),
+		bus:      bus,
+	}
+}
+
+// Create tạo yêu cầu trả hàng cho đơn đã giao của user trong thời hạn trả hàng. Mỗi dòng chỉ được
+// trả tối đa số lượng đã mua trừ số lượng thuộc các yêu cầu khác đang chờ hoặc đã duyệt.
+func (s *ReturnService) Create(ctx context.Context, userID, orderID uint, req *models.CreateReturnRequest) (*models.OrderReturn, error) {
+	var ret *models.OrderReturn
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Khóa đơn để hai yêu cầu đồng thời không cùng trả một dòng hàng
+		order, err := s.orders.GetForUpdate(ctx, orderID)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && order.UserID != userID) {
+			return ErrOrderNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if order.Status != models.OrderStatusDelivered || order.DeliveredAt == nil {
+			return fmt.Errorf("%w: order is %s", ErrReturnNotAllowed, order.Status)
+		}
+		if time.Since(*order.DeliveredAt) > s.window {
+			return fmt.Errorf("%w: the return window closed on %s", ErrReturnNotAllowed, order.DeliveredAt.Add(s.window).Format("2006-01-02"))
+		}
+
+		returned, err := s.repo.ReturnedQuantities(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+		items := make(map[uint]models.OrderItem, len(order.Items))
+		for _, item := range order.Items {
+			items[item.ID] = item
+		}
+
+		ret = &models.OrderReturn{
+			OrderID: order.ID,
+			UserID:  userID,
+			Status:  models.ReturnStatusRequested,
+			Reason:  req.Reason,
+		}
+		for _, line := range req.Items {
+			item, ok := items[line.OrderItemID]
+			if !ok {
+				return fmt.Errorf("%w: %d", ErrReturnItemInvalid, line.OrderItemID)
+			}
+			returned[item.ID] += line.Quantity
+			if returned[item.ID] > item.Quantity {
+				return fmt.Errorf("%w: %s (at most %d)", ErrReturnQuantity, item.Name, item.Quantity-returned[item.ID]+line.Quantity)
+			}
+			amount := returnLineAmount(order, &item, line.Quantity)
+			ret.Items = append(ret.Items, models.OrderReturnItem{
+				OrderItemID: item.ID,
+				ProductID:   item.ProductID,
+				Name:        item.Name,
+				Quantity:    line.Quantity,
+				Amount:      amount,
+			})
+			ret.Amount += amount
+		}
+		ret.Amount = math.Round(ret.Amount*100) / 100
+		return s.repo.Create(ctx, ret)
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret.Photos = []models.OrderReturnPhoto{}
+	s.bus.Publish(ctx, events.ReturnStatusChanged{Return: *ret, To: ret.Status, ChangedAt: ret.CreatedAt})
+	return ret, nil
+}
+
+// returnLineAmount tính số tiền hoàn cho quantity đơn vị của một dòng hàng theo giá lúc đặt sau
+// khuyến mãi, gồm cả thuế nếu thuế được cộng thêm vào giá
+func returnLineAmount(order *models.Order, item *models.OrderItem, quantity int) float64 {
+	total := item.Amount - item.DiscountAmount
+	if !order.PricesIncludeTax {
+		total += item.TaxAmount
+	}
+	return math.Round(total/float64(item.Quantity)*float64(quantity)*100) / 100
+}
+
+// Get lấy yêu cầu trả hàng; userID khác 0 thì chỉ trả về yêu cầu của user đó
+func (s *ReturnService) Get(ctx context.Context, userID, id uint) (*models.OrderReturn, error) {
+	ret, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && userID != 0 && ret.UserID != userID) {
+		return nil, ErrReturnNotFound
+	}
+	return ret, err
+}
+
+// ListByOrder lấy các yêu cầu trả hàng của đơn; userID khác 0 thì chỉ cho đơn của user đó
+func (s *ReturnService) ListByOrder(ctx context.Context, userID, orderID uint) ([]models.OrderReturn, error) {
+	order, err := s.orders.GetByID(ctx, orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && userID != 0 && order.UserID != userID) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListByOrder(ctx, orderID)
+}
+
+// List lấy danh sách yêu cầu trả hàng (Admin)
+func (s *ReturnService) List(ctx context.Context, query *models.ReturnQueryParams) ([]models.OrderReturn, int64, error) {
+	return s.repo.GetAll(ctx, query)
+}
+
+// AddPhoto đính kèm ảnh vào yêu cầu trả hàng còn chờ xử lý của user. save lưu file ảnh và chỉ được
+// gọi sau khi yêu cầu đã được kiểm tra; nếu có lỗi, caller xóa file đã lưu (nếu có).
+func (s *ReturnService) AddPhoto(ctx context.Context, userID, id uint, url string, save func() error) (*models.OrderReturnPhoto, error) {
+	var photo *models.OrderReturnPhoto
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		ret, err := s.repo.GetForUpdate(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && ret.UserID != userID) {
+			return ErrReturnNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if ret.Status != models.ReturnStatusRequested {
+			return ErrReturnNotPending
+		}
+		count, err := s.repo.CountPhotos(ctx, id)
+		if err != nil {
+			return err
+		}
+		if count >= models.MaxReturnPhotos {
+			return ErrReturnPhotoLimit
+		}
+		if err := save(); err != nil {
+			return err
+		}
+		photo = &models.OrderReturnPhoto{ReturnID: id, URL: url}
+		return s.repo.AddPhoto(ctx, photo)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return photo, nil
+}
+
+// Cancel rút lại yêu cầu trả hàng còn chờ xử lý của user
+func (s *ReturnService) Cancel(ctx context.Context, userID, id uint) (*models.OrderReturn, error) {
+	return s.resolve(ctx, userID, id, func(ctx context.Context, ret *models.OrderReturn, now time.Time) (string, map[string]interface{}, error) {
+		return models.ReturnStatusCancelled, map[string]interface{}{"resolved_at": now}, nil
+	})
+}
+
+// Reject từ chối yêu cầu trả hàng (Admin); note được hiển thị cho khách
+func (s *ReturnService) Reject(ctx context.Context, adminID, id uint, note string) (*models.OrderReturn, error) {
+	return s.resolve(ctx, 0, id, func(ctx context.Context, ret *models.OrderReturn, now time.Time) (string, map[string]interface{}, error) {
+		return models.ReturnStatusRejected, map[string]interface{}{
+			"admin_note": note, "resolved_by": adminID, "resolved_at": now,
+		}, nil
+	})
+}
+
+// Approve duyệt yêu cầu trả hàng (Admin): nhập lại kho các sản phẩm được trả và hoàn tiền qua
+// cổng thanh toán của đơn (COD, chuyển khoản chỉ được ghi nhận). Mọi bước nằm trong một
+// transaction nên nếu cổng thanh toán từ chối hoàn tiền, yêu cầu vẫn ở trạng thái chờ xử lý.
+func (s *ReturnService) Approve(ctx context.Context, adminID uint, clientIP string, id uint, req *models.ApproveReturnRequest) (*models.OrderReturn, error) {
+	var stock []events.StockChanged
+	var orderChanged *events.OrderStatusChanged
+	ret, err := s.resolve(ctx, 0, id, func(ctx context.Context, ret *models.OrderReturn, now time.Time) (string, map[string]interface{}, error) {
+		for _, item := range ret.Items {
+			if err := s.products.ReleaseStock(ctx, item.ProductID, item.Quantity); err != nil {
+				return "", nil, err
+			}
+			product, err := s.products.GetByID(ctx, item.ProductID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return "", nil, err
+			}
+			stock = append(stock, events.StockChanged{
+				ProductID: product.ID, Name: product.Name,
+				OldStock: product.Stock - item.Quantity, NewStock: product.Stock, ChangedAt: now,
+			})
+		}
+
+		order, err := s.orders.GetForUpdate(ctx, ret.OrderID)
+		if err != nil {
+			return "", nil, err
+		}
+		amount := req.Amount
+		if amount == 0 {
+			amount = ret.Amount
+		}
+		refund, changed, err := s.payments.refundOrder(ctx, order, adminID, clientIP, &models.RefundRequest{
+			Amount: amount,
+			Reason: fmt.Sprintf("Return #%d: %s", ret.ID, ret.Reason),
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		orderChanged = changed
+		return models.ReturnStatusApproved, map[string]interface{}{
+			"refund_id": refund.ID, "refunded_amount": refund.Amount,
+			"admin_note": req.Note, "resolved_by": adminID, "resolved_at": now,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range stock {
+		s.bus.Publish(ctx, e)
+	}
+	if orderChanged != nil {
+		s.bus.Publish(ctx, *orderChanged)
+	}
+	return ret, nil
+}
+
+// resolve khóa yêu cầu còn chờ xử lý và chuyển sang trạng thái do apply trả về cùng các cột cập nhật;
+// apply chạy trong cùng transaction (qua ctx). userID khác 0 thì chỉ cho yêu cầu của user đó.
+func (s *ReturnService) resolve(ctx context.Context, userID, id uint, apply func(ctx context.Context, ret *models.OrderReturn, now time.Time) (string, map[string]interface{}, error)) (*models.OrderReturn, error) {
+	var changed *events.ReturnStatusChanged
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		ret, err := s.repo.GetForUpdate(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && userID != 0 && ret.UserID != userID) {
+			return ErrReturnNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if ret.Status != models.ReturnStatusRequested {
+			return fmt.Errorf("%w: return is %s", ErrReturnNotPending, ret.Status)
+		}
+
+		now := time.Now()
+		to, fields, err := apply(ctx, ret, now)
+		if err != nil {
+			return err
+		}
+		rows, err := s.repo.UpdateStatus(ctx, ret.ID, ret.Status, to, fields)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrReturnNotPending
+		}
+		changed = &events.ReturnStatusChanged{From: ret.Status, To: to, ChangedAt: now}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	changed.Return = *ret
+	s.bus.Publish(ctx, *changed)
+	return ret, nil
+}
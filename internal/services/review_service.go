@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReviewNotFound  = errors.New("review not found")
+	ErrAlreadyReviewed = errors.New("product already reviewed")
+	ErrOwnReview       = errors.New("cannot vote on or report your own review")
+	ErrAlreadyReported = errors.New("review already reported")
+	ErrVoteNotFound    = errors.New("vote not found")
+)
+
+// ReviewService xử lý đánh giá sản phẩm: user đánh giá, bình chọn và báo cáo vi phạm, admin kiểm duyệt.
+// Chỉ đánh giá đã duyệt được hiển thị và tính vào rating của product_listings.
+type ReviewService struct {
+	repo     *repository.ReviewRepository
+	products *repository.ProductRepository
+	orders   *repository.OrderRepository
+	listings *repository.ProductListingRepository
+	tx       *repository.TxManager
+}
+
+func NewReviewService(db *gorm.DB) *ReviewService {
+	return &ReviewService{
+		repo:     repository.NewReviewRepository(db),
+		products: repository.NewProductRepository(db),
+		orders:   repository.NewOrderRepository(db),
+		listings: repository.NewProductListingRepository(db),
+		tx:       repository.NewTxManager(db),
+	}
+}
+
+// ListApproved lấy các đánh giá đã duyệt của sản phẩm
+func (s *ReviewService) ListApproved(ctx context.Context, productID uint, query *models.ReviewQueryParams) ([]models.ProductReview, int64, error) {
+	if err := s.checkProduct(ctx, productID); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListApproved(ctx, productID, query)
+}
+
+// Create tạo đánh giá chờ duyệt; VerifiedPurchase được gắn khi user có đơn đã giao chứa sản phẩm
+func (s *ReviewService) Create(ctx context.Context, userID, productID uint, req *models.CreateReviewRequest) (*models.ProductReview, error) {
+	if err := s.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	exists, err := s.repo.Exists(ctx, userID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrAlreadyReviewed
+	}
+	verified, err := s.orders.HasDelivered(ctx, userID, productID)
+	if err != nil {
+		return nil, err
+	}
+	review := &models.ProductReview{
+		ProductID:        productID,
+		UserID:           userID,
+		Rating:           req.Rating,
+		Title:            strings.TrimSpace(req.Title),
+		Body:             strings.TrimSpace(req.Body),
+		VerifiedPurchase: verified,
+		Status:           models.ModerationPending,
+	}
+	if err := s.repo.Create(ctx, review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// List lấy hàng đợi kiểm duyệt đánh giá (Admin)
+func (s *ReviewService) List(ctx context.Context, query *models.ModerationQueryParams) ([]models.ProductReview, int64, error) {
+	return s.repo.List(ctx, query)
+}
+
+// ListReported lấy hàng đợi đánh giá bị báo cáo vi phạm (Admin)
+func (s *ReviewService) ListReported(ctx context.Context, query *models.ModerationQueryParams) ([]models.ProductReview, int64, error) {
+	return s.repo.ListReported(ctx, query)
+}
+
+// Vote ghi phiếu hữu ích/không hữu ích của user cho đánh giá đã duyệt và trả về đánh giá với số phiếu mới
+func (s *ReviewService) Vote(ctx context.Context, userID, productID, reviewID uint, helpful bool) (*models.ProductReview, error) {
+	review, err := s.votable(ctx, userID, productID, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpsertVote(ctx, &models.ReviewVote{ReviewID: review.ID, UserID: userID, Helpful: helpful}); err != nil {
+			return err
+		}
+		return s.repo.RecountVotes(ctx, review.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, productID, review.ID)
+}
+
+// Unvote gỡ phiếu của user khỏi đánh giá
+func (s *ReviewService) Unvote(ctx context.Context, userID, productID, reviewID uint) (*models.ProductReview, error) {
+	review, err := s.votable(ctx, userID, productID, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		deleted, err := s.repo.DeleteVote(ctx, review.ID, userID)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return ErrVoteNotFound
+		}
+		return s.repo.RecountVotes(ctx, review.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, productID, review.ID)
+}
+
+// Report ghi báo cáo vi phạm của user; đánh giá vào hàng đợi báo cáo cho đến khi admin kiểm duyệt lại
+func (s *ReviewService) Report(ctx context.Context, userID, productID, reviewID uint, reason string) (*models.ReviewReport, error) {
+	review, err := s.votable(ctx, userID, productID, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := s.repo.ReportExists(ctx, review.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrAlreadyReported
+	}
+	report := &models.ReviewReport{
+		ReviewID: review.ID,
+		UserID:   userID,
+		Reason:   strings.TrimSpace(reason),
+		Status:   models.ReviewReportOpen,
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.repo.CreateReport(ctx, report)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Moderate duyệt hoặc từ chối đánh giá, xử lý các báo cáo vi phạm đang mở và dựng lại rating của sản
+// phẩm trong cùng transaction (Admin)
+func (s *ReviewService) Moderate(ctx context.Context, adminID, productID, reviewID uint, status string) (*models.ProductReview, error) {
+	review, err := s.repo.Get(ctx, productID, reviewID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	now := time.Now()
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Moderate(ctx, review.ID, status, adminID, now); err != nil {
+			return err
+		}
+		if err := s.repo.ResolveReports(ctx, review.ID, now); err != nil {
+			return err
+		}
+		return s.listings.Refresh(ctx, review.ProductID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	review.Status, review.ModeratedBy, review.ModeratedAt, review.ReportCount = status, &adminID, &now, 0
+	return review, nil
+}
+
+// votable lấy đánh giá đã duyệt mà user được bình chọn hoặc báo cáo (không phải đánh giá của chính họ)
+func (s *ReviewService) votable(ctx context.Context, userID, productID, reviewID uint) (*models.ProductReview, error) {
+	review, err := s.repo.Get(ctx, productID, reviewID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	if review.Status != models.ModerationApproved {
+		return nil, ErrReviewNotFound
+	}
+	if review.UserID == userID {
+		return nil, ErrOwnReview
+	}
+	return review, nil
+}
+
+func (s *ReviewService) checkProduct(ctx context.Context, productID uint) error {
+	if _, err := s.products.GetByID(ctx, productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrProductNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSearchSynonymNotFound = errors.New("search synonym not found")
+	ErrSearchSynonymExists   = errors.New("synonyms for this term already exist")
+	ErrInvalidSearchSynonym  = errors.New("invalid search synonym")
+)
+
+// maxSearchTerms giới hạn số cách viết của một từ khóa sau khi mở rộng để truy vấn tìm kiếm không phình ra
+const maxSearchTerms = 10
+
+// SearchSynonymService quản lý từ điển đồng nghĩa và mở rộng từ khóa tìm kiếm sản phẩm theo từ điển.
+// Từ điển nằm trong database nên admin sửa được mà không cần deploy.
+type SearchSynonymService struct {
+	repo *repository.SearchSynonymRepository
+}
+
+func NewSearchSynonymService(db *gorm.DB) *SearchSynonymService {
+	return &SearchSynonymService{
+		repo: repository.NewSearchSynonymRepository(db),
+	}
+}
+
+// List lấy toàn bộ từ điển đồng nghĩa
+func (s *SearchSynonymService) List(ctx context.Context) ([]models.SearchSynonym, error) {
+	return s.repo.List(ctx)
+}
+
+// Create thêm mục đồng nghĩa; mỗi từ khóa chỉ có một mục
+func (s *SearchSynonymService) Create(ctx context.Context, req *models.SearchSynonymRequest) (*models.SearchSynonym, error) {
+	synonym := &models.SearchSynonym{}
+	if err := s.apply(ctx, synonym, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, synonym); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrSearchSynonymExists
+		}
+		return nil, err
+	}
+	return synonym, nil
+}
+
+// Update thay thế từ khóa và danh sách đồng nghĩa của mục
+func (s *SearchSynonymService) Update(ctx context.Context, id uint, req *models.SearchSynonymRequest) (*models.SearchSynonym, error) {
+	synonym, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSearchSynonymNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, synonym, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, synonym); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrSearchSynonymExists
+		}
+		return nil, err
+	}
+	return synonym, nil
+}
+
+// Delete xóa mục đồng nghĩa
+func (s *SearchSynonymService) Delete(ctx context.Context, id uint) error {
+	deleted, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSearchSynonymNotFound
+	}
+	return nil
+}
+
+// Expand trả về từ khóa search cùng các cách viết đồng nghĩa (tối đa maxSearchTerms, search đứng đầu):
+// đồng nghĩa của cả cụm từ khóa và từ khóa có một từ được thay bằng đồng nghĩa của từ đó
+func (s *SearchSynonymService) Expand(ctx context.Context, search string) ([]string, error) {
+	phrase := normalizeSearchTerm(search)
+	if phrase == "" {
+		return []string{search}, nil
+	}
+	words := strings.Fields(phrase)
+	lookup := []string{phrase}
+	for _, word := range words {
+		if !slices.Contains(lookup, word) {
+			lookup = append(lookup, word)
+		}
+	}
+	entries, err := s.repo.FindByTerms(ctx, lookup)
+	if err != nil {
+		return nil, err
+	}
+	synonyms := make(map[string][]string, len(entries))
+	for _, entry := range entries {
+		synonyms[entry.Term] = entry.Synonyms
+	}
+
+	terms := []string{search}
+	add := func(term string) {
+		if len(terms) < maxSearchTerms && term != phrase && !slices.Contains(terms, term) {
+			terms = append(terms, term)
+		}
+	}
+	for _, synonym := range synonyms[phrase] {
+		add(synonym)
+	}
+	if len(words) > 1 {
+		for i, word := range words {
+			for _, synonym := range synonyms[word] {
+				replaced := slices.Clone(words)
+				replaced[i] = synonym
+				add(strings.Join(replaced, " "))
+			}
+		}
+	}
+	return terms, nil
+}
+
+// apply chuẩn hóa request (chữ thường, bỏ khoảng trắng thừa, bỏ trùng) và ghi vào synonym
+func (s *SearchSynonymService) apply(ctx context.Context, synonym *models.SearchSynonym, req *models.SearchSynonymRequest) error {
+	term := normalizeSearchTerm(req.Term)
+	if term == "" {
+		return fmt.Errorf("%w: term must not be blank", ErrInvalidSearchSynonym)
+	}
+	var list []string
+	for _, value := range req.Synonyms {
+		value = normalizeSearchTerm(value)
+		if value != "" && value != term && !slices.Contains(list, value) {
+			list = append(list, value)
+		}
+	}
+	if len(list) == 0 {
+		return fmt.Errorf("%w: synonyms must contain a term other than %q", ErrInvalidSearchSynonym, term)
+	}
+	// Unique index chỉ báo trùng trên Postgres; kiểm tra trước để database khác cũng trả lỗi rõ ràng
+	exists, err := s.repo.TermExists(ctx, term, synonym.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrSearchSynonymExists
+	}
+	synonym.Term, synonym.Synonyms = term, list
+	return nil
+}
+
+// normalizeSearchTerm đưa từ khóa về chữ thường và gộp khoảng trắng
+func normalizeSearchTerm(term string) string {
+	return strings.Join(strings.Fields(strings.ToLower(term)), " ")
+}
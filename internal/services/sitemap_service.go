@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+var ErrSitemapNotFound = errors.New("sitemap not found")
+
+// SitemapIndex là tên của sitemap index trong bảng sitemaps
+const SitemapIndex = "index"
+
+// SitemapMaxURLs là số URL tối đa của một file sitemap theo giao thức sitemaps.org
+const SitemapMaxURLs = 50000
+
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// sitemapBatchSize là số sản phẩm đọc mỗi lần khi dựng sitemap; SitemapMaxURLs phải chia hết cho nó
+const sitemapBatchSize = 1000
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapIndexXML struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+// SitemapService dựng sitemap XML cho sản phẩm đã đăng và danh mục có sản phẩm. Sitemap được dựng sẵn
+// và lưu vào bảng sitemaps (tác vụ định kỳ chỉ chạy trên một replica nên mọi replica đọc chung bản đã dựng).
+type SitemapService struct {
+	tx       *repository.TxManager
+	repo     *repository.SitemapRepository
+	products *repository.ProductRepository
+	// storefrontURL là gốc URL của các trang trong sitemap, baseURL là gốc URL phục vụ các file sitemap
+	storefrontURL string
+	baseURL       string
+}
+
+func NewSitemapService(db *gorm.DB) *SitemapService {
+	storefrontURL := utils.StorefrontURL()
+	return &SitemapService{
+		tx:            repository.NewTxManager(db),
+		repo:          repository.NewSitemapRepository(db),
+		products:      repository.NewProductRepository(db),
+		storefrontURL: storefrontURL,
+		baseURL:       strings.TrimSuffix(config.String("SITEMAP_BASE_URL", storefrontURL), "/"),
+	}
+}
+
+// Get lấy sitemap đã dựng theo tên; dựng ngay nếu chưa từng dựng sitemap nào
+func (s *SitemapService) Get(ctx context.Context, name string) (*models.Sitemap, error) {
+	sitemap, err := s.repo.Get(ctx, name)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return sitemap, err
+	}
+	count, err := s.repo.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrSitemapNotFound
+	}
+	if _, err := s.Generate(ctx, time.Now()); err != nil {
+		return nil, err
+	}
+	sitemap, err = s.repo.Get(ctx, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSitemapNotFound
+	}
+	return sitemap, err
+}
+
+// Generate dựng lại toàn bộ sitemap: products-N (tối đa SitemapMaxURLs sản phẩm mỗi file), categories
+// và sitemap index trỏ tới chúng; trả về tổng số URL
+func (s *SitemapService) Generate(ctx context.Context, now time.Time) (int, error) {
+	var sitemaps []models.Sitemap
+	var index []sitemapURL
+	add := func(name string, entries []models.SitemapEntry) error {
+		content, lastMod, err := s.urlSet(entries)
+		if err != nil {
+			return err
+		}
+		sitemaps = append(sitemaps, models.Sitemap{Name: name, Content: content, URLCount: len(entries), GeneratedAt: now})
+		index = append(index, sitemapURL{Loc: s.baseURL + "/sitemaps/" + name + ".xml", LastMod: lastMod})
+		return nil
+	}
+
+	total := 0
+	var entries []models.SitemapEntry
+	var afterID uint
+	// Thời điểm cập nhật gần nhất của sản phẩm đã đăng trong mỗi danh mục, gom trong lúc duyệt sản phẩm
+	categories := make(map[string]time.Time)
+	for {
+		products, err := s.products.ListPublishedAfter(ctx, afterID, sitemapBatchSize)
+		if err != nil {
+			return 0, err
+		}
+		for _, product := range products {
+			entries = append(entries, models.SitemapEntry{Path: utils.ProductPath(product.ID, product.Name), UpdatedAt: product.UpdatedAt})
+			afterID = product.ID
+			if product.Category != "" && product.UpdatedAt.After(categories[product.Category]) {
+				categories[product.Category] = product.UpdatedAt
+			}
+		}
+		last := len(products) < sitemapBatchSize
+		if len(entries) >= SitemapMaxURLs || last && len(entries) > 0 {
+			if err := add(fmt.Sprintf("products-%d", len(sitemaps)+1), entries); err != nil {
+				return 0, err
+			}
+			total += len(entries)
+			entries = nil
+		}
+		if last {
+			break
+		}
+	}
+
+	if len(categories) > 0 {
+		names := make([]string, 0, len(categories))
+		for name := range categories {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		entries = make([]models.SitemapEntry, len(names))
+		for i, name := range names {
+			entries[i] = models.SitemapEntry{Path: utils.CategoryPath(name), UpdatedAt: categories[name]}
+		}
+		if err := add("categories", entries); err != nil {
+			return 0, err
+		}
+		total += len(entries)
+	}
+
+	content, err := marshalSitemap(sitemapIndexXML{Xmlns: sitemapNamespace, Sitemaps: index})
+	if err != nil {
+		return 0, err
+	}
+	sitemaps = append(sitemaps, models.Sitemap{Name: SitemapIndex, Content: content, URLCount: len(index), GeneratedAt: now})
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.repo.ReplaceAll(ctx, sitemaps)
+	})
+	return total, err
+}
+
+// urlSet dựng <urlset> từ entries; trả về kèm lastmod lớn nhất để ghi vào sitemap index
+func (s *SitemapService) urlSet(entries []models.SitemapEntry) ([]byte, string, error) {
+	set := sitemapURLSet{Xmlns: sitemapNamespace, URLs: make([]sitemapURL, len(entries))}
+	var latest time.Time
+	for i, entry := range entries {
+		set.URLs[i] = sitemapURL{Loc: s.storefrontURL + entry.Path, LastMod: sitemapTime(entry.UpdatedAt)}
+		if entry.UpdatedAt.After(latest) {
+			latest = entry.UpdatedAt
+		}
+	}
+	content, err := marshalSitemap(set)
+	return content, sitemapTime(latest), err
+}
+
+func marshalSitemap(v interface{}) ([]byte, error) {
+	content, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), content...), nil
+}
+
+// sitemapTime định dạng thời điểm theo W3C Datetime; bỏ trống với thời điểm zero
+func sitemapTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
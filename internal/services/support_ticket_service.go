@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSupportTicketNotFound = errors.New("support ticket not found")
+	// ErrSupportTicketClosed: phiếu đã đóng không nhận thêm tin nhắn
+	ErrSupportTicketClosed = errors.New("support ticket is closed")
+	// ErrSupportTicketStatus: phiếu không chuyển được sang trạng thái yêu cầu từ trạng thái hiện tại
+	ErrSupportTicketStatus = errors.New("support ticket cannot move to this status")
+	// ErrSupportEmailRequired: khách chưa đăng nhập phải để lại email để nhận trả lời
+	ErrSupportEmailRequired = errors.New("email is required")
+	// ErrSupportOrderNotAllowed: chỉ user đã đăng nhập mới gắn được đơn hàng vào phiếu
+	ErrSupportOrderNotAllowed = errors.New("sign in to attach an order to a support ticket")
+)
+
+// SupportTicketService xử lý phiếu hỗ trợ: khách gửi phiếu qua form liên hệ, admin trả lời theo luồng tin
+// nhắn và đổi trạng thái. Thông báo cho khách và admin được gửi bởi subscriber của các sự kiện SupportTicket*.
+type SupportTicketService struct {
+	tx     *repository.TxManager
+	repo   *repository.SupportTicketRepository
+	users  *repository.UserRepository
+	orders *repository.OrderRepository
+	bus    *events.Bus
+}
+
+func NewSupportTicketService(db *gorm.DB, bus *events.Bus) *SupportTicketService {
+	return &SupportTicketService{
+		tx:     repository.NewTxManager(db),
+		repo:   repository.NewSupportTicketRepository(db),
+		users:  repository.NewUserRepository(db),
+		orders: repository.NewOrderRepository(db),
+		bus:    bus,
+	}
+}
+
+// Create tạo phiếu hỗ trợ với nội dung là tin nhắn đầu tiên. userID bằng 0 với khách chưa đăng nhập: email
+// lấy từ request; ngược lại email là email của tài khoản, tên mặc định là tên của tài khoản và đơn hàng gắn
+// kèm phải là đơn của user.
+func (s *SupportTicketService) Create(ctx context.Context, userID uint, req *models.CreateSupportTicketRequest) (*models.SupportTicket, error) {
+	ticket := &models.SupportTicket{
+		Name:     strings.TrimSpace(req.Name),
+		Email:    strings.ToLower(strings.TrimSpace(req.Email)),
+		Category: req.Category,
+		Subject:  strings.TrimSpace(req.Subject),
+		Status:   models.SupportTicketOpen,
+	}
+	if ticket.Category == "" {
+		ticket.Category = models.SupportCategoryOther
+	}
+
+	var authorID *uint
+	if userID != 0 {
+		user, err := s.users.GetByID(ctx, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		authorID = &user.ID
+		ticket.UserID = authorID
+		ticket.Email = strings.ToLower(user.Email)
+		if ticket.Name == "" {
+			ticket.Name = user.FullName
+		}
+		if req.OrderID != nil {
+			order, err := s.orders.GetByID(ctx, *req.OrderID)
+			if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && order.UserID != userID) {
+				return nil, ErrOrderNotFound
+			}
+			if err != nil {
+				return nil, err
+			}
+			ticket.OrderID = &order.ID
+		}
+	} else {
+		if ticket.Email == "" {
+			return nil, ErrSupportEmailRequired
+		}
+		if req.OrderID != nil {
+			return nil, ErrSupportOrderNotAllowed
+		}
+	}
+
+	now := time.Now()
+	ticket.LastMessageAt = now
+	ticket.Messages = []models.SupportTicketMessage{{AuthorID: authorID, Body: strings.TrimSpace(req.Message), CreatedAt: now}}
+	if err := s.repo.Create(ctx, ticket); err != nil {
+		return nil, err
+	}
+	s.bus.Publish(ctx, events.SupportTicketCreated{Ticket: *ticket})
+	return ticket, nil
+}
+
+// Get lấy phiếu kèm tin nhắn; userID khác 0 thì chỉ lấy phiếu của user đó
+func (s *SupportTicketService) Get(ctx context.Context, userID, id uint) (*models.SupportTicket, error) {
+	ticket, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !ownsTicket(ticket, userID)) {
+		return nil, ErrSupportTicketNotFound
+	}
+	return ticket, err
+}
+
+// List lấy danh sách phiếu với bộ lọc và phân trang
+func (s *SupportTicketService) List(ctx context.Context, query *models.SupportTicketQueryParams) ([]models.SupportTicket, int64, error) {
+	return s.repo.GetAll(ctx, query)
+}
+
+// Reply thêm tin nhắn vào phiếu chưa đóng. Admin (staff) trả lời chuyển phiếu đang mở sang answered; khách
+// trả lời mở lại phiếu đã answered hoặc resolved. Khách chỉ trả lời được phiếu của mình.
+func (s *SupportTicketService) Reply(ctx context.Context, authorID uint, staff bool, id uint, body string) (*models.SupportTicket, error) {
+	var message *models.SupportTicketMessage
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		ticket, err := s.lock(ctx, id, authorID, staff)
+		if err != nil {
+			return err
+		}
+		if ticket.Status == models.SupportTicketClosed {
+			return ErrSupportTicketClosed
+		}
+
+		now := time.Now()
+		message = &models.SupportTicketMessage{TicketID: ticket.ID, AuthorID: &authorID, Staff: staff, Body: strings.TrimSpace(body), CreatedAt: now}
+		if err := s.repo.AddMessage(ctx, message); err != nil {
+			return err
+		}
+		status := ticket.Status
+		switch {
+		case staff && status == models.SupportTicketOpen:
+			status = models.SupportTicketAnswered
+		case !staff && status != models.SupportTicketOpen:
+			status = models.SupportTicketOpen
+		}
+		return s.repo.Update(ctx, ticket.ID, map[string]interface{}{"status": status, "last_message_at": now})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(ctx, events.SupportTicketReplied{Ticket: *ticket, Message: *message})
+	return ticket, nil
+}
+
+// SetStatus đánh dấu phiếu resolved, đóng hoặc mở lại theo các bước của models.CanTransitionSupportTicket.
+// Khách (staff là false) chỉ đóng được phiếu của mình; answered chỉ được đặt khi admin trả lời.
+func (s *SupportTicketService) SetStatus(ctx context.Context, actorID uint, staff bool, id uint, to string) (*models.SupportTicket, error) {
+	var changed *events.SupportTicketStatusChanged
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		ticket, err := s.lock(ctx, id, actorID, staff)
+		if err != nil {
+			return err
+		}
+		allowed := to != models.SupportTicketAnswered && (staff || to == models.SupportTicketClosed)
+		if !allowed || !models.CanTransitionSupportTicket(ticket.Status, to) {
+			return fmt.Errorf("%w: ticket is %s", ErrSupportTicketStatus, ticket.Status)
+		}
+
+		now := time.Now()
+		fields := map[string]interface{}{"status": to, "closed_at": nil}
+		if to == models.SupportTicketClosed {
+			fields["closed_at"] = now
+		}
+		if err := s.repo.Update(ctx, ticket.ID, fields); err != nil {
+			return err
+		}
+		changed = &events.SupportTicketStatusChanged{From: ticket.Status, To: to, ByStaff: staff, ChangedAt: now}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	changed.Ticket = *ticket
+	s.bus.Publish(ctx, *changed)
+	return ticket, nil
+}
+
+// lock khóa phiếu cho tới hết transaction; khách (staff là false) chỉ thấy phiếu của mình
+func (s *SupportTicketService) lock(ctx context.Context, id, userID uint, staff bool) (*models.SupportTicket, error) {
+	ticket, err := s.repo.GetForUpdate(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !staff && !ownsTicket(ticket, userID)) {
+		return nil, ErrSupportTicketNotFound
+	}
+	return ticket, err
+}
+
+// ownsTicket cho biết phiếu thuộc về user; userID bằng 0 (admin) thấy mọi phiếu
+func ownsTicket(ticket *models.SupportTicket, userID uint) bool {
+	return userID == 0 || (ticket.UserID != nil && *ticket.UserID == userID)
+}
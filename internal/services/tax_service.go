@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/tax"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTaxRateNotFound = errors.New("tax rate not found")
+	ErrTaxRateExists   = errors.New("a tax rate already exists for this category and province")
+)
+
+// TaxService quản lý quy tắc thuế suất và dựng tax.Engine dùng khi tính tiền đơn hàng, hóa đơn
+type TaxService struct {
+	repo   *repository.TaxRateRepository
+	config tax.Config
+}
+
+func NewTaxService(db *gorm.DB, config tax.Config) *TaxService {
+	return &TaxService{
+		repo:   repository.NewTaxRateRepository(db),
+		config: config,
+	}
+}
+
+// Config trả về cấu hình thuế chung (thuế suất mặc định, giá đã gồm thuế hay chưa)
+func (s *TaxService) Config() tax.Config {
+	return s.config
+}
+
+// Engine dựng bộ tính thuế từ các quy tắc hiện tại
+func (s *TaxService) Engine(ctx context.Context) (*tax.Engine, error) {
+	rules, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tax.NewEngine(rules, s.config), nil
+}
+
+// List lấy mọi quy tắc thuế suất
+func (s *TaxService) List(ctx context.Context) ([]models.TaxRate, error) {
+	return s.repo.List(ctx)
+}
+
+// Create thêm quy tắc thuế suất; mỗi cặp danh mục/tỉnh chỉ có một quy tắc
+func (s *TaxService) Create(ctx context.Context, req *models.TaxRateRequest) (*models.TaxRate, error) {
+	rate := &models.TaxRate{Name: req.Name, Rate: *req.Rate, Category: req.Category, Province: req.Province}
+	if err := s.repo.Create(ctx, rate); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrTaxRateExists
+		}
+		return nil, err
+	}
+	return rate, nil
+}
+
+// Update sửa quy tắc thuế suất; chỉ áp dụng cho đơn hàng và hóa đơn lập sau đó
+func (s *TaxService) Update(ctx context.Context, id uint, req *models.TaxRateRequest) (*models.TaxRate, error) {
+	rate, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTaxRateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	rate.Name, rate.Rate, rate.Category, rate.Province = req.Name, *req.Rate, req.Category, req.Province
+	if err := s.repo.Save(ctx, rate); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrTaxRateExists
+		}
+		return nil, err
+	}
+	return rate, nil
+}
+
+// Delete xóa quy tắc thuế suất
+func (s *TaxService) Delete(ctx context.Context, id uint) error {
+	deleted, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrTaxRateNotFound
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// DefaultUploadGCGracePeriod là thời gian mặc định một file phải mồ côi liên tục trước khi bị xóa
+const DefaultUploadGCGracePeriod = 7 * 24 * time.Hour
+
+// UploadGCGracePeriod đọc thời gian ân hạn từ UPLOAD_GC_GRACE_PERIOD (mặc định DefaultUploadGCGracePeriod)
+func UploadGCGracePeriod() time.Duration {
+	return config.Duration("UPLOAD_GC_GRACE_PERIOD", DefaultUploadGCGracePeriod)
+}
+
+// UploadGCService dọn các file trong thư mục upload không còn được tham chiếu: ảnh sản phẩm (và
+// thumbnail của nó) bị thay hoặc sản phẩm bị xóa, video và poster của video đã bị xóa, ảnh trả hàng của
+// yêu cầu đã bị xóa. Một file chỉ bị xóa khi đã mồ côi liên tục quá thời gian ân hạn kể từ lần đầu phát
+// hiện, nên file vừa upload nhưng chưa được gắn vào bản ghi không bị xóa nhầm.
+type UploadGCService struct {
+	dir      string
+	grace    time.Duration
+	orphans  *repository.OrphanedUploadRepository
+	products *repository.ProductRepository
+	media    *repository.ProductMediaRepository
+	returns  *repository.ReturnRepository
+}
+
+func NewUploadGCService(db *gorm.DB, uploadDir string) *UploadGCService {
+	return &UploadGCService{
+		dir:      uploadDir,
+		grace:    UploadGCGracePeriod(),
+		orphans:  repository.NewOrphanedUploadRepository(db),
+		products: repository.NewProductRepository(db),
+		media:    repository.NewProductMediaRepository(db),
+		returns:  repository.NewReturnRepository(db),
+	}
+}
+
+// Run quét thư mục upload, ghi nhận file mồ côi mới và xóa file đã quá thời gian ân hạn. Với dryRun chỉ
+// trả về báo cáo, không xóa và không ghi gì.
+func (s *UploadGCService) Run(ctx context.Context, dryRun bool, now time.Time) (*models.UploadGCReport, error) {
+	referenced, err := s.references(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tracked, err := s.orphans.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	firstSeen := make(map[string]time.Time, len(tracked))
+	for _, orphan := range tracked {
+		firstSeen[orphan.Path] = orphan.FirstSeenAt
+	}
+
+	report := &models.UploadGCReport{DryRun: dryRun, GracePeriod: s.grace.String(), Files: []models.UploadGCFile{}}
+	var discovered []models.OrphanedUpload
+	var due []models.UploadGCFile
+	current := make(map[string]bool)
+	err = filepath.WalkDir(s.dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == s.dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if strings.HasPrefix(entry.Name(), ".") && p != s.dir {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		report.Scanned++
+		if referenced[key] {
+			report.Referenced++
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		report.Orphaned++
+		current[key] = true
+		seen, ok := firstSeen[key]
+		if !ok {
+			seen = now
+			discovered = append(discovered, models.OrphanedUpload{Path: key, Size: info.Size(), FirstSeenAt: now})
+		}
+		file := models.UploadGCFile{Path: key, Size: info.Size(), FirstSeenAt: seen, DeleteAfter: seen.Add(s.grace)}
+		file.Due = !now.Before(file.DeleteAfter)
+		if file.Due {
+			due = append(due, file)
+		}
+		report.Files = append(report.Files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// File đã bị xóa khỏi thư mục hoặc được tham chiếu lại thì bỏ theo dõi
+	var forget []string
+	for _, orphan := range tracked {
+		if !current[orphan.Path] {
+			forget = append(forget, orphan.Path)
+		}
+	}
+	for _, file := range due {
+		if !dryRun {
+			if err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(file.Path))); err != nil && !os.IsNotExist(err) {
+				continue
+			}
+			forget = append(forget, file.Path)
+		}
+		report.Deleted++
+		report.FreedBytes += file.Size
+	}
+	if !dryRun {
+		if err := s.orphans.Add(ctx, discovered); err != nil {
+			return nil, err
+		}
+		if err := s.orphans.Delete(ctx, forget); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(report.Files, func(i, j int) bool {
+		if report.Files[i].DeleteAfter.Equal(report.Files[j].DeleteAfter) {
+			return report.Files[i].Path < report.Files[j].Path
+		}
+		return report.Files[i].DeleteAfter.Before(report.Files[j].DeleteAfter)
+	})
+	if len(report.Files) > models.MaxUploadGCReportFiles {
+		report.Files = report.Files[:models.MaxUploadGCReportFiles]
+	}
+	return report, nil
+}
+
+// RemoveUnreferenced xóa ngay file của url cùng thumbnail của nó nếu không còn bản ghi nào tham chiếu (bản
+// sao sản phẩm có thể dùng chung ảnh). URL tuyệt đối và đường dẫn ngoài thư mục upload được bỏ qua.
+func (s *UploadGCService) RemoveUnreferenced(ctx context.Context, url string) error {
+	u, _, _ := strings.Cut(url, "?")
+	if url == "" || strings.Contains(u, "://") {
+		return nil
+	}
+	p := strings.TrimPrefix(path.Clean("/"+u), "/")
+	if !strings.HasPrefix(p, "static/uploads/") && !strings.HasPrefix(p, "uploads/") {
+		return nil
+	}
+	key := uploadKey(p)
+	referenced, err := s.references(ctx)
+	if err != nil {
+		return err
+	}
+	for _, k := range []string{key, thumbnailKey(key)} {
+		if referenced[k] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(k))); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterImageCleanup xóa ảnh cũ khỏi thư mục upload khi ảnh sản phẩm bị thay. Sự kiện được phát sau khi
+// thay đổi đã được lưu, nên ảnh cũ không bị xóa nếu cập nhật thất bại; lỗi xóa chỉ được log, file còn sót
+// sẽ được dọn bởi lần chạy định kỳ.
+func RegisterImageCleanup(bus *events.Bus, gc *UploadGCService) {
+	events.On(bus, func(ctx context.Context, e events.ProductImageReplaced) {
+		if err := gc.RemoveUnreferenced(context.WithoutCancel(ctx), e.OldURL); err != nil {
+			log.Printf("Uploads: failed to remove replaced image %s of product %d: %v", e.OldURL, e.ProductID, err)
+		}
+	})
+}
+
+// references trả về đường dẫn tương đối (trong thư mục upload) của mọi file đang được tham chiếu, gồm cả
+// thumbnail của ảnh sản phẩm và poster của video
+func (s *UploadGCService) references(ctx context.Context) (map[string]bool, error) {
+	productImages, err := s.products.ListImageURLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	videos, err := s.media.ListUploadURLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	returnPhotos, err := s.returns.ListPhotoURLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	referenced := make(map[string]bool, 2*len(productImages)+len(videos)+len(returnPhotos))
+	for _, u := range productImages {
+		key := uploadKey(u)
+		referenced[key] = true
+		referenced[thumbnailKey(key)] = true
+	}
+	for _, u := range videos {
+		referenced[uploadKey(u)] = true
+	}
+	for _, u := range returnPhotos {
+		referenced[uploadKey(u)] = true
+	}
+	return referenced, nil
+}
+
+// thumbnailKey là đường dẫn thumbnail (xem jobs.ThumbnailPath) của một ảnh trong thư mục upload
+func thumbnailKey(key string) string {
+	return path.Join(path.Dir(key), "thumbs", strings.TrimSuffix(path.Base(key), path.Ext(key))+".jpg")
+}
+
+// uploadKey chuyển URL của file upload (/static/uploads/a.jpg, /uploads/a.jpg hoặc URL tuyệt đối) thành
+// đường dẫn tương đối trong thư mục upload
+func uploadKey(u string) string {
+	if i := strings.Index(u, "://"); i >= 0 {
+		u = u[i+3:]
+		if slash := strings.Index(u, "/"); slash >= 0 {
+			u = u[slash:]
+		}
+	}
+	u, _, _ = strings.Cut(u, "?")
+	p := strings.TrimPrefix(path.Clean("/"+u), "/")
+	for _, prefix := range []string{"static/uploads/", "uploads/"} {
+		if rest, ok := strings.CutPrefix(p, prefix); ok {
+			return rest
+		}
+	}
+	return p
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/notify"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvitationNotFound = errors.New("invitation not found or expired")
+	// ErrUserNotInvited: tài khoản đã đặt mật khẩu, không còn gửi lời mời được
+	ErrUserNotInvited = errors.New("user has already set a password")
+	// ErrInvalidImportFile: file CSV không đọc được hoặc thiếu cột email
+	ErrInvalidImportFile = errors.New("invalid import file")
+	ErrImportTooLarge    = errors.New("import file has too many rows")
+)
+
+// DefaultInvitationTTL là thời gian link đặt mật khẩu trong lời mời còn hiệu lực
+const DefaultInvitationTTL = 7 * 24 * time.Hour
+
+// importRoles là các vai trò được gán khi nhập user; tài khoản admin chỉ được cấp thủ công
+var importRoles = map[string]bool{"user": true, models.RoleEditor: true}
+
+// UserInvitationService nhập user từ CSV ở trạng thái invited và gửi link đặt mật khẩu; tài khoản chỉ
+// đăng nhập được sau khi user đặt mật khẩu qua link
+type UserInvitationService struct {
+	tx          *repository.TxManager
+	users       *repository.UserRepository
+	invitations *repository.UserInvitationRepository
+	notifier    *notify.Dispatcher
+	acceptURL   string
+	ttl         time.Duration
+	maxRows     int
+}
+
+// NewUserInvitationService tạo service; link đặt mật khẩu là USER_INVITE_URL kèm ?token=, thời hạn link đọc
+// từ USER_INVITE_TTL và số dòng tối đa của một file từ USER_IMPORT_MAX_ROWS
+func NewUserInvitationService(db *gorm.DB, notifier *notify.Dispatcher) *UserInvitationService {
+	return &UserInvitationService{
+		tx:          repository.NewTxManager(db),
+		users:       repository.NewUserRepository(db),
+		invitations: repository.NewUserInvitationRepository(db),
+		notifier:    notifier,
+		acceptURL:   config.String("USER_INVITE_URL", "http://localhost:3000/account/invitation"),
+		ttl:         config.Duration("USER_INVITE_TTL", DefaultInvitationTTL),
+		maxRows:     config.Int("USER_IMPORT_MAX_ROWS", 1000),
+	}
+}
+
+// importRecord là một dòng dữ liệu của file CSV
+type importRecord struct {
+	line                            int
+	email, username, fullName, role string
+}
+
+// Import đọc file CSV (dòng tiêu đề có cột email, tùy chọn username, full_name và role) và kiểm tra từng
+// dòng. Các dòng hợp lệ được tạo thành user invited và nhận email mời, kể cả khi file có dòng lỗi; với dryRun
+// chỉ kiểm tra mà không tạo gì. Lỗi của cả file (không đọc được, quá nhiều dòng) trả về ErrInvalidImportFile
+// hoặc ErrImportTooLarge.
+func (s *UserInvitationService) Import(ctx context.Context, adminID uint, r io.Reader, dryRun bool) (*models.UserImportResult, error) {
+	records, err := readImportCSV(r, s.maxRows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.UserImportResult{DryRun: dryRun, Total: len(records), Rows: make([]models.UserImportRow, 0, len(records))}
+	emails, usernames := map[string]int{}, map[string]int{}
+	for _, rec := range records {
+		row := models.UserImportRow{Row: rec.line, Email: rec.email}
+		if row.Errors, err = s.validate(ctx, &rec, emails, usernames); err != nil {
+			return nil, err
+		}
+		row.Username = rec.username
+		switch {
+		case len(row.Errors) > 0:
+			row.Status = models.UserImportInvalid
+			result.Invalid++
+		case dryRun:
+			row.Status = models.UserImportValid
+			result.Valid++
+		default:
+			user := &models.User{
+				Username: rec.username,
+				Email:    rec.email,
+				FullName: rec.fullName,
+				Role:     rec.role,
+				Status:   models.UserStatusInvited,
+			}
+			err := s.invite(ctx, adminID, user)
+			if errors.Is(err, ErrEmailTaken) {
+				// Email hoặc username vừa được đăng ký sau lúc kiểm tra
+				row.Status = models.UserImportInvalid
+				row.Errors = []models.UserImportError{{Field: "email", Code: "taken", Message: "email or username is already in use"}}
+				result.Invalid++
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			row.Status, row.UserID = models.UserImportCreated, &user.ID
+			result.Created++
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, nil
+}
+
+// readImportCSV đọc dòng tiêu đề (không phân biệt hoa thường, bỏ BOM của Excel) và các dòng dữ liệu; dòng
+// trống bị bỏ qua
+func readImportCSV(r io.Reader, maxRows int) ([]importRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidImportFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, fmt.Errorf("%w: missing email column", ErrInvalidImportFile)
+	}
+
+	var records []importRecord
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+		if strings.TrimSpace(strings.Join(fields, "")) == "" {
+			continue
+		}
+		if len(records) == maxRows {
+			return nil, fmt.Errorf("%w: at most %d rows are allowed", ErrImportTooLarge, maxRows)
+		}
+		get := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(fields) {
+				return ""
+			}
+			return strings.TrimSpace(fields[i])
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, importRecord{
+			line: line, email: get("email"), username: get("username"), fullName: get("full_name"), role: get("role"),
+		})
+	}
+}
+
+// validate chuẩn hóa và kiểm tra một dòng: username mặc định là email, role mặc định là user. emails và
+// usernames ghi các giá trị đã gặp ở dòng trước (theo số dòng) để báo trùng trong cùng file.
+func (s *UserInvitationService) validate(ctx context.Context, rec *importRecord, emails, usernames map[string]int) ([]models.UserImportError, error) {
+	var errs []models.UserImportError
+	fail := func(field, code, message string) {
+		errs = append(errs, models.UserImportError{Field: field, Code: code, Message: message})
+	}
+
+	emailOK := false
+	switch addr, err := mail.ParseAddress(rec.email); {
+	case rec.email == "":
+		fail("email", "required", "email is required")
+	case err != nil || addr.Address != rec.email:
+		fail("email", "invalid", "email is not a valid address")
+	case len(rec.email) > 255:
+		fail("email", "too_long", "email must be at most 255 characters")
+	default:
+		emailOK = true
+	}
+	if rec.username == "" {
+		rec.username = rec.email
+	}
+	usernameOK := rec.username != ""
+	if len(rec.username) > 255 {
+		fail("username", "too_long", "username must be at most 255 characters")
+		usernameOK = false
+	}
+	if len(rec.fullName) > 255 {
+		fail("full_name", "too_long", "full_name must be at most 255 characters")
+	}
+	rec.role = strings.ToLower(rec.role)
+	if rec.role == "" {
+		rec.role = "user"
+	}
+	if !importRoles[rec.role] {
+		fail("role", "unsupported_role", "role must be user or editor")
+	}
+
+	if emailOK {
+		key := strings.ToLower(rec.email)
+		if line, seen := emails[key]; seen {
+			fail("email", "duplicate", fmt.Sprintf("email already appears on row %d", line))
+		} else {
+			emails[key] = rec.line
+			taken, err := s.users.EmailTaken(ctx, rec.email)
+			if err != nil {
+				return nil, err
+			}
+			if taken {
+				fail("email", "taken", "email is already in use")
+			}
+		}
+	}
+	if usernameOK {
+		if line, seen := usernames[rec.username]; seen {
+			fail("username", "duplicate", fmt.Sprintf("username already appears on row %d", line))
+		} else {
+			usernames[rec.username] = rec.line
+			taken, err := s.users.UsernameTaken(ctx, rec.username)
+			if err != nil {
+				return nil, err
+			}
+			if taken {
+				fail("username", "taken", "username is already in use")
+			}
+		}
+	}
+	return errs, nil
+}
+
+// Resend gửi lại lời mời tới tài khoản còn ở trạng thái invited; link cũ hết hiệu lực
+func (s *UserInvitationService) Resend(ctx context.Context, adminID, userID uint) (*models.UserInvitation, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.Status != models.UserStatusInvited {
+		return nil, ErrUserNotInvited
+	}
+	var invitation *models.UserInvitation
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.invitations.CancelPending(ctx, user.ID, time.Now()); err != nil {
+			return err
+		}
+		invitation, err = s.createInvitation(ctx, adminID, user)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// invite tạo user cùng lời mời trong một transaction; email hoặc username bị trùng khi lưu trả về ErrEmailTaken
+func (s *UserInvitationService) invite(ctx context.Context, adminID uint, user *models.User) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.users.Create(ctx, user); err != nil {
+			if isUniqueViolation(err) {
+				return ErrEmailTaken
+			}
+			return err
+		}
+		_, err := s.createInvitation(ctx, adminID, user)
+		return err
+	})
+}
+
+// createInvitation lưu lời mời mới và đưa email mời vào hàng đợi job trong cùng transaction, nên email không
+// được gửi nếu transaction bị rollback
+func (s *UserInvitationService) createInvitation(ctx context.Context, adminID uint, user *models.User) (*models.UserInvitation, error) {
+	token, err := newLinkToken()
+	if err != nil {
+		return nil, err
+	}
+	invitation := &models.UserInvitation{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: hashLinkToken(token),
+		InvitedBy: adminID,
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+	if err := s.invitations.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	link := s.acceptURL + "?token=" + url.QueryEscape(token)
+	s.notifier.Notify(ctx, notify.Notification{
+		UserID: user.ID,
+		Event:  notify.EventInvitation,
+		Title:  "Set up your account",
+		Body: "An account has been created for you with the username " + user.Username + ".\n\n" +
+			"To start using it, choose a password by opening the link below before " + invitation.ExpiresAt.Format(time.RFC1123) + ":\n" +
+			link + "\n\nIf you were not expecting this email, you can ignore it.",
+		Data:  map[string]string{"invitation_id": strconv.FormatUint(uint64(invitation.ID), 10)},
+		Email: user.Email,
+	})
+	return invitation, nil
+}
+
+// Lookup lấy tài khoản của lời mời còn hiệu lực, để kiểm tra mật khẩu mới trước khi Accept
+func (s *UserInvitationService) Lookup(ctx context.Context, token string) (*models.User, error) {
+	invitation, err := s.pending(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.users.GetByID(ctx, invitation.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvitationNotFound
+	}
+	return user, err
+}
+
+// Accept đặt mật khẩu (đã băm) cho tài khoản của lời mời và chuyển tài khoản sang active; lời mời chỉ dùng
+// được một lần
+func (s *UserInvitationService) Accept(ctx context.Context, token, passwordHash string) (*models.User, error) {
+	var user *models.User
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		invitation, err := s.pending(ctx, token)
+		if err != nil {
+			return err
+		}
+		accepted, err := s.invitations.MarkAccepted(ctx, invitation.ID, time.Now())
+		if err != nil {
+			return err
+		}
+		activated := false
+		if accepted {
+			if activated, err = s.users.Activate(ctx, invitation.UserID, passwordHash); err != nil {
+				return err
+			}
+		}
+		if !activated {
+			return ErrInvitationNotFound
+		}
+		user, err = s.users.GetByID(ctx, invitation.UserID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// pending lấy lời mời còn hiệu lực theo token
+func (s *UserInvitationService) pending(ctx context.Context, token string) (*models.UserInvitation, error) {
+	invitation, err := s.invitations.GetByTokenHash(ctx, hashLinkToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !invitation.Pending(time.Now()) {
+		return nil, ErrInvitationNotFound
+	}
+	return invitation, nil
+}
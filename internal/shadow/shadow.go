@@ -0,0 +1,120 @@
+package shadow
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const defaultTimeout = 5 * time.Second
+
+// Stats chứa thống kê so sánh của một phép đọc
+type Stats struct {
+	Sampled        int64     `json:"sampled"`
+	Matched        int64     `json:"matched"`
+	Mismatched     int64     `json:"mismatched"`
+	Errors         int64     `json:"errors"`
+	LastMismatch   string    `json:"last_mismatch,omitempty"`
+	LastMismatchAt time.Time `json:"last_mismatch_at,omitempty"`
+	LastError      string    `json:"last_error,omitempty"`
+}
+
+// Verifier chạy song song cài đặt cũ (legacy) của các phương thức repository đã được
+// viết lại trên một phần lưu lượng, so sánh kết quả và ghi log khi khác nhau.
+// Kết quả trả cho client luôn là của cài đặt chính; phép đọc shadow chạy nền.
+type Verifier struct {
+	sampleRate float64
+	timeout    time.Duration
+
+	mu    sync.RWMutex
+	stats map[string]*Stats
+}
+
+// NewVerifier tạo verifier với tỉ lệ lấy mẫu trong [0, 1]; 0 = tắt
+func NewVerifier(sampleRate float64) *Verifier {
+	if sampleRate < 0 {
+		sampleRate = 0
+	}
+	if sampleRate > 1 {
+		sampleRate = 1
+	}
+	return &Verifier{sampleRate: sampleRate, timeout: defaultTimeout, stats: make(map[string]*Stats)}
+}
+
+// NewVerifierFromEnv đọc tỉ lệ lấy mẫu từ SHADOW_READ_SAMPLE_RATE (mặc định 0 = tắt)
+func NewVerifierFromEnv() *Verifier {
+	rate := 0.0
+	if v := os.Getenv("SHADOW_READ_SAMPLE_RATE"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			rate = f
+		} else {
+			log.Printf("Warning: invalid SHADOW_READ_SAMPLE_RATE=%q, shadow reads disabled", v)
+		}
+	}
+	return NewVerifier(rate)
+}
+
+// Sampled quyết định request hiện tại có được so sánh hay không. An toàn khi v là nil.
+func (v *Verifier) Sampled() bool {
+	return v != nil && v.sampleRate > 0 && rand.Float64() < v.sampleRate
+}
+
+// Compare chạy legacy ở nền và so sánh kết quả với primary bằng hàm diff.
+// diff trả về chuỗi rỗng nếu hai kết quả tương đương, ngược lại là mô tả khác biệt.
+func Compare[T any](v *Verifier, name string, primary T, legacy func(ctx context.Context) (T, error), diff func(primary, legacy T) string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), v.timeout)
+		defer cancel()
+
+		result, err := legacy(ctx)
+		if err != nil {
+			v.record(name, "", err)
+			return
+		}
+		v.record(name, diff(primary, result), nil)
+	}()
+}
+
+func (v *Verifier) record(name, mismatch string, err error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	s, ok := v.stats[name]
+	if !ok {
+		s = &Stats{}
+		v.stats[name] = s
+	}
+	s.Sampled++
+	switch {
+	case err != nil:
+		s.Errors++
+		s.LastError = err.Error()
+		log.Printf("Shadow read %s: legacy implementation failed: %v", name, err)
+	case mismatch != "":
+		s.Mismatched++
+		s.LastMismatch = mismatch
+		s.LastMismatchAt = time.Now()
+		log.Printf("Shadow read %s: mismatch: %s", name, mismatch)
+	default:
+		s.Matched++
+	}
+}
+
+// GetStats trả về thống kê so sánh theo tên phép đọc
+func (v *Verifier) GetStats() map[string]interface{} {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	reads := make(map[string]Stats, len(v.stats))
+	for name, s := range v.stats {
+		reads[name] = *s
+	}
+	return map[string]interface{}{
+		"sample_rate": v.sampleRate,
+		"reads":       reads,
+	}
+}
@@ -0,0 +1,216 @@
+package shipping
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+)
+
+const (
+	ghnSandboxURL    = "https://dev-online-gateway.ghn.vn/shiip/public-api"
+	ghnProductionURL = "https://online-gateway.ghn.vn/shiip/public-api"
+
+	// Loại dịch vụ của GHN: hàng nhẹ (tới 20kg) và hàng nặng
+	ghnServiceLight    = 2
+	ghnServiceHeavy    = 5
+	ghnLightMaxWeightG = 20000
+)
+
+// GHN báo giá qua API của Giao Hàng Nhanh. GHN định danh địa chỉ bằng mã quận/huyện và
+// phường/xã riêng nên tên trong sổ địa chỉ được đối chiếu với master data của GHN (có cache).
+type GHN struct {
+	token          string
+	shopID         int
+	fromDistrictID int
+	baseURL        string
+	client         *http.Client
+
+	mu        sync.Mutex
+	provinces []ghnPlace
+	districts map[int][]ghnPlace
+	wards     map[int][]ghnPlace
+}
+
+// ghnPlace là một tỉnh, quận/huyện hoặc phường/xã trong master data của GHN
+type ghnPlace struct {
+	ID    int
+	Code  string
+	Names []string
+}
+
+func NewGHN(token string, shopID, fromDistrictID int, mode string) *GHN {
+	baseURL := ghnSandboxURL
+	if mode == ModeProduction {
+		baseURL = ghnProductionURL
+	}
+	return &GHN{
+		token:          token,
+		shopID:         shopID,
+		fromDistrictID: fromDistrictID,
+		baseURL:        baseURL,
+		client:         &http.Client{Timeout: 10 * time.Second},
+		districts:      make(map[int][]ghnPlace),
+		wards:          make(map[int][]ghnPlace),
+	}
+}
+
+func (p *GHN) Name() string { return "ghn" }
+
+func (p *GHN) Quote(ctx context.Context, req Request) ([]models.ShippingOption, error) {
+	districtID, wardCode, err := p.resolve(ctx, req.Destination)
+	if err != nil {
+		return nil, err
+	}
+	serviceType := ghnServiceLight
+	if req.WeightGrams > ghnLightMaxWeightG {
+		serviceType = ghnServiceHeavy
+	}
+	weight := req.WeightGrams
+	if weight <= 0 {
+		weight = 1
+	}
+
+	var out struct {
+		Data struct {
+			Total float64 `json:"total"`
+		} `json:"data"`
+	}
+	err = p.do(ctx, http.MethodPost, "/v2/shipping-order/fee", map[string]interface{}{
+		"service_type_id":  serviceType,
+		"from_district_id": p.fromDistrictID,
+		"to_district_id":   districtID,
+		"to_ward_code":     wardCode,
+		"weight":           weight,
+		"insurance_value":  int64(req.Value),
+	}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return []models.ShippingOption{{Service: "standard", Name: "GHN standard delivery", Fee: out.Data.Total}}, nil
+}
+
+// resolve tìm mã quận/huyện và phường/xã của GHN cho địa chỉ
+func (p *GHN) resolve(ctx context.Context, addr models.AddressDetails) (int, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.provinces == nil {
+		var out struct {
+			Data []struct {
+				ProvinceID    int      `json:"ProvinceID"`
+				ProvinceName  string   `json:"ProvinceName"`
+				NameExtension []string `json:"NameExtension"`
+			} `json:"data"`
+		}
+		if err := p.do(ctx, http.MethodGet, "/master-data/province", nil, &out); err != nil {
+			return 0, "", err
+		}
+		for _, d := range out.Data {
+			p.provinces = append(p.provinces, ghnPlace{ID: d.ProvinceID, Names: append(d.NameExtension, d.ProvinceName)})
+		}
+	}
+	province, ok := matchPlace(p.provinces, addr.Province)
+	if !ok {
+		return 0, "", fmt.Errorf("%w: province %q", ErrAddressNotSupported, addr.Province)
+	}
+
+	districts, ok := p.districts[province.ID]
+	if !ok {
+		var out struct {
+			Data []struct {
+				DistrictID    int      `json:"DistrictID"`
+				DistrictName  string   `json:"DistrictName"`
+				NameExtension []string `json:"NameExtension"`
+			} `json:"data"`
+		}
+		if err := p.do(ctx, http.MethodGet, "/master-data/district?province_id="+strconv.Itoa(province.ID), nil, &out); err != nil {
+			return 0, "", err
+		}
+		for _, d := range out.Data {
+			districts = append(districts, ghnPlace{ID: d.DistrictID, Names: append(d.NameExtension, d.DistrictName)})
+		}
+		p.districts[province.ID] = districts
+	}
+	district, ok := matchPlace(districts, addr.District)
+	if !ok {
+		return 0, "", fmt.Errorf("%w: district %q", ErrAddressNotSupported, addr.District)
+	}
+
+	wards, ok := p.wards[district.ID]
+	if !ok {
+		var out struct {
+			Data []struct {
+				WardCode      string   `json:"WardCode"`
+				WardName      string   `json:"WardName"`
+				NameExtension []string `json:"NameExtension"`
+			} `json:"data"`
+		}
+		if err := p.do(ctx, http.MethodGet, "/master-data/ward?district_id="+strconv.Itoa(district.ID), nil, &out); err != nil {
+			return 0, "", err
+		}
+		for _, d := range out.Data {
+			wards = append(wards, ghnPlace{Code: d.WardCode, Names: append(d.NameExtension, d.WardName)})
+		}
+		p.wards[district.ID] = wards
+	}
+	ward, ok := matchPlace(wards, addr.Ward)
+	if !ok {
+		return 0, "", fmt.Errorf("%w: ward %q", ErrAddressNotSupported, addr.Ward)
+	}
+	return district.ID, ward.Code, nil
+}
+
+func (p *GHN) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Token", p.token)
+	req.Header.Set("ShopId", strconv.Itoa(p.shopID))
+	req.Header.Set("Content-Type", "application/json")
+	return doJSON(p.client, req, out)
+}
+
+// matchPlace tìm địa danh theo tên, không phân biệt hoa thường và bỏ qua tiền tố hành chính
+// (Tỉnh, Thành phố, Quận, Huyện, Phường, Xã...)
+func matchPlace(places []ghnPlace, name string) (ghnPlace, bool) {
+	want := placeKey(name)
+	if want == "" {
+		return ghnPlace{}, false
+	}
+	for _, place := range places {
+		for _, n := range place.Names {
+			if placeKey(n) == want {
+				return place, true
+			}
+		}
+	}
+	return ghnPlace{}, false
+}
+
+var placePrefixes = []string{"thành phố ", "tp. ", "tp.", "tp ", "tỉnh ", "quận ", "huyện ", "thị xã ", "phường ", "xã ", "thị trấn "}
+
+func placeKey(name string) string {
+	key := strings.Join(strings.Fields(strings.ToLower(name)), " ")
+	for _, prefix := range placePrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return strings.TrimSpace(strings.TrimPrefix(key, prefix))
+		}
+	}
+	return key
+}
@@ -0,0 +1,80 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+)
+
+const (
+	ghtkSandboxURL    = "https://services-staging.ghtklab.com"
+	ghtkProductionURL = "https://services.giaohangtietkiem.vn"
+)
+
+// GHTK báo giá qua API của Giao Hàng Tiết Kiệm; GHTK nhận tên tỉnh/huyện/xã trực tiếp
+type GHTK struct {
+	token        string
+	pickProvince string
+	pickDistrict string
+	baseURL      string
+	client       *http.Client
+}
+
+func NewGHTK(token, pickProvince, pickDistrict, mode string) *GHTK {
+	baseURL := ghtkSandboxURL
+	if mode == ModeProduction {
+		baseURL = ghtkProductionURL
+	}
+	return &GHTK{
+		token:        token,
+		pickProvince: pickProvince,
+		pickDistrict: pickDistrict,
+		baseURL:      baseURL,
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *GHTK) Name() string { return "ghtk" }
+
+func (p *GHTK) Quote(ctx context.Context, req Request) ([]models.ShippingOption, error) {
+	q := query(map[string]string{
+		"pick_province":  p.pickProvince,
+		"pick_district":  p.pickDistrict,
+		"province":       req.Destination.Province,
+		"district":       req.Destination.District,
+		"ward":           req.Destination.Ward,
+		"address":        req.Destination.Street,
+		"weight":         strconv.Itoa(req.WeightGrams),
+		"value":          strconv.FormatInt(int64(req.Value), 10),
+		"transport":      "road",
+		"deliver_option": "none",
+	})
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/services/shipment/fee?"+q, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Token", p.token)
+
+	var out struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+		Fee     struct {
+			Fee      float64 `json:"fee"`
+			Delivery bool    `json:"delivery"`
+		} `json:"fee"`
+	}
+	if err := doJSON(p.client, httpReq, &out); err != nil {
+		return nil, err
+	}
+	if !out.Success {
+		return nil, errors.New(out.Message)
+	}
+	if !out.Fee.Delivery {
+		return nil, ErrAddressNotSupported
+	}
+	return []models.ShippingOption{{Service: "road", Name: "GHTK road delivery", Fee: out.Fee.Fee}}, nil
+}
@@ -0,0 +1,181 @@
+package shipping
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+)
+
+var (
+	// ErrUnavailable được trả về khi không có cách giao hàng nào cho địa chỉ (hoặc mọi provider đều lỗi)
+	ErrUnavailable = errors.New("no shipping option is available for this address")
+	// ErrUnknownOption được trả về khi option khách chọn không có trong báo giá hiện tại
+	ErrUnknownOption = errors.New("shipping option is not available")
+	// ErrAddressNotSupported được provider trả về khi không nhận ra tỉnh/huyện/xã của địa chỉ
+	ErrAddressNotSupported = errors.New("address is not supported by the carrier")
+)
+
+// Chế độ kết nối tới API của đơn vị vận chuyển (GHN, GHTK)
+const (
+	ModeSandbox    = "sandbox"
+	ModeProduction = "production"
+)
+
+// ModeFromEnv đọc SHIPPING_MODE; mặc định là sandbox
+func ModeFromEnv() string {
+	if os.Getenv("SHIPPING_MODE") == ModeProduction {
+		return ModeProduction
+	}
+	return ModeSandbox
+}
+
+// Request là thông tin một kiện hàng cần báo giá
+type Request struct {
+	Destination models.AddressDetails
+	WeightGrams int
+	// Value là giá trị hàng (chưa gồm phí vận chuyển), dùng cho phí bảo hiểm và miễn phí vận chuyển
+	Value float64
+}
+
+// Provider báo giá vận chuyển cho một kiện hàng. Provider không phục vụ được địa chỉ thì trả về
+// danh sách rỗng hoặc ErrAddressNotSupported.
+type Provider interface {
+	Name() string
+	Quote(ctx context.Context, req Request) ([]models.ShippingOption, error)
+}
+
+// Calculator gom báo giá của các provider đã cấu hình
+type Calculator struct {
+	providers []Provider
+}
+
+func NewCalculator(providers ...Provider) *Calculator {
+	return &Calculator{providers: providers}
+}
+
+// Quote lấy báo giá từ mọi provider, rẻ nhất trước. Provider lỗi được bỏ qua (ghi log) để một đơn
+// vị vận chuyển gặp sự cố không chặn checkout; nếu không còn option nào thì trả về ErrUnavailable.
+func (c *Calculator) Quote(ctx context.Context, req Request) ([]models.ShippingOption, error) {
+	var options []models.ShippingOption
+	var errs []error
+	for _, p := range c.providers {
+		quoted, err := p.Quote(ctx, req)
+		if err != nil {
+			if !errors.Is(err, ErrAddressNotSupported) {
+				log.Printf("Shipping: %s quote failed: %v", p.Name(), err)
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+			continue
+		}
+		for _, o := range quoted {
+			o.Provider = p.Name()
+			o.ID = o.Provider + ":" + o.Service
+			if o.Currency == "" {
+				o.Currency = "VND"
+			}
+			options = append(options, o)
+		}
+	}
+	if len(options) == 0 {
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("%w: %v", ErrUnavailable, errors.Join(errs...))
+		}
+		return nil, ErrUnavailable
+	}
+	sort.SliceStable(options, func(i, j int) bool { return options[i].Fee < options[j].Fee })
+	return options, nil
+}
+
+// Select báo giá rồi chọn option có ID là id; id rỗng chọn option rẻ nhất
+func (c *Calculator) Select(ctx context.Context, req Request, id string) (*models.ShippingOption, error) {
+	options, err := c.Quote(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return &options[0], nil
+	}
+	for i := range options {
+		if options[i].ID == id {
+			return &options[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownOption, id)
+}
+
+// FromEnv tạo Calculator từ biến môi trường:
+//   - SHIPPING_FLAT_RATE (phí cố định, mặc định 0 = miễn phí) và SHIPPING_FREE_OVER
+//   - SHIPPING_WEIGHT_TABLE, ví dụ "500:18000,1000:22000,3000:30000", và SHIPPING_WEIGHT_EXTRA_PER_KG
+//   - GHN_TOKEN, GHN_SHOP_ID, GHN_FROM_DISTRICT_ID
+//   - GHTK_TOKEN, GHTK_PICK_PROVINCE, GHTK_PICK_DISTRICT
+//
+// Phí cố định chỉ được dùng khi không cấu hình bảng cân nặng; nếu không cấu hình gì, giao hàng miễn phí.
+func FromEnv() *Calculator {
+	var providers []Provider
+	if table := os.Getenv("SHIPPING_WEIGHT_TABLE"); table != "" {
+		brackets, err := ParseWeightBrackets(table)
+		if err != nil {
+			log.Printf("Shipping: ignoring SHIPPING_WEIGHT_TABLE: %v", err)
+		} else {
+			providers = append(providers, NewWeightTable(brackets, envFloat("SHIPPING_WEIGHT_EXTRA_PER_KG"), envFloat("SHIPPING_FREE_OVER")))
+		}
+	}
+	if len(providers) == 0 {
+		providers = append(providers, NewFlatRate(envFloat("SHIPPING_FLAT_RATE"), envFloat("SHIPPING_FREE_OVER")))
+	}
+
+	mode := ModeFromEnv()
+	if token := os.Getenv("GHN_TOKEN"); token != "" {
+		shopID, _ := strconv.Atoi(os.Getenv("GHN_SHOP_ID"))
+		district, _ := strconv.Atoi(os.Getenv("GHN_FROM_DISTRICT_ID"))
+		providers = append(providers, NewGHN(token, shopID, district, mode))
+	}
+	if token := os.Getenv("GHTK_TOKEN"); token != "" {
+		providers = append(providers, NewGHTK(token, os.Getenv("GHTK_PICK_PROVINCE"), os.Getenv("GHTK_PICK_DISTRICT"), mode))
+	}
+	return NewCalculator(providers...)
+}
+
+func envFloat(key string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv(key)), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// doJSON gửi request và đọc JSON response; status ngoài 2xx được trả về dưới dạng lỗi kèm message
+func doJSON(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return fmt.Errorf("%s %s failed with status %d: %s", req.Method, req.URL.Path, resp.StatusCode, e.Message)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// query dựng query string từ các cặp key/value
+func query(pairs map[string]string) string {
+	v := url.Values{}
+	for k, val := range pairs {
+		v.Set(k, val)
+	}
+	return v.Encode()
+}
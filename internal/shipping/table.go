@@ -0,0 +1,107 @@
+package shipping
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+)
+
+// FlatRate tính một mức phí cố định cho mọi đơn; đơn có giá trị từ FreeOver trở lên được miễn phí
+type FlatRate struct {
+	Fee      float64
+	FreeOver float64
+}
+
+func NewFlatRate(fee, freeOver float64) *FlatRate {
+	return &FlatRate{Fee: fee, FreeOver: freeOver}
+}
+
+func (p *FlatRate) Name() string { return "flat_rate" }
+
+func (p *FlatRate) Quote(_ context.Context, req Request) ([]models.ShippingOption, error) {
+	return []models.ShippingOption{{
+		Service: "standard",
+		Name:    "Standard delivery",
+		Fee:     freeOver(p.Fee, p.FreeOver, req.Value),
+	}}, nil
+}
+
+// WeightBracket là một bậc của bảng phí theo cân nặng: kiện tới MaxGrams gram có phí Fee
+type WeightBracket struct {
+	MaxGrams int
+	Fee      float64
+}
+
+// WeightTable tính phí theo bậc cân nặng; kiện nặng hơn bậc cuối cùng bị cộng ExtraPerKg cho mỗi
+// kg (làm tròn lên) vượt quá
+type WeightTable struct {
+	Brackets   []WeightBracket
+	ExtraPerKg float64
+	FreeOver   float64
+}
+
+func NewWeightTable(brackets []WeightBracket, extraPerKg, freeOver float64) *WeightTable {
+	sorted := append([]WeightBracket(nil), brackets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].MaxGrams < sorted[j].MaxGrams })
+	return &WeightTable{Brackets: sorted, ExtraPerKg: extraPerKg, FreeOver: freeOver}
+}
+
+func (p *WeightTable) Name() string { return "weight_table" }
+
+func (p *WeightTable) Quote(_ context.Context, req Request) ([]models.ShippingOption, error) {
+	if len(p.Brackets) == 0 {
+		return nil, nil
+	}
+	var fee float64
+	last := p.Brackets[len(p.Brackets)-1]
+	if req.WeightGrams > last.MaxGrams {
+		extraKg := math.Ceil(float64(req.WeightGrams-last.MaxGrams) / 1000)
+		fee = last.Fee + extraKg*p.ExtraPerKg
+	} else {
+		for _, b := range p.Brackets {
+			if req.WeightGrams <= b.MaxGrams {
+				fee = b.Fee
+				break
+			}
+		}
+	}
+	return []models.ShippingOption{{
+		Service: "standard",
+		Name:    "Standard delivery",
+		Fee:     freeOver(fee, p.FreeOver, req.Value),
+	}}, nil
+}
+
+// ParseWeightBrackets đọc bảng phí dạng "maxGrams:fee,maxGrams:fee", ví dụ "500:18000,1000:22000"
+func ParseWeightBrackets(s string) ([]WeightBracket, error) {
+	var brackets []WeightBracket
+	for _, part := range strings.Split(s, ",") {
+		grams, fee, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid bracket %q, expected maxGrams:fee", part)
+		}
+		g, err := strconv.Atoi(strings.TrimSpace(grams))
+		if err != nil || g <= 0 {
+			return nil, fmt.Errorf("invalid weight in bracket %q", part)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(fee), 64)
+		if err != nil || f < 0 {
+			return nil, fmt.Errorf("invalid fee in bracket %q", part)
+		}
+		brackets = append(brackets, WeightBracket{MaxGrams: g, Fee: f})
+	}
+	return brackets, nil
+}
+
+// freeOver trả về 0 khi đơn đủ điều kiện miễn phí vận chuyển
+func freeOver(fee, threshold, value float64) float64 {
+	if threshold > 0 && value >= threshold {
+		return 0
+	}
+	return fee
+}
@@ -0,0 +1,93 @@
+package sse
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+)
+
+const clientBuffer = 32
+
+// Message là một sự kiện gửi tới client qua Server-Sent Events
+type Message struct {
+	ID        uint64      `json:"-"`
+	Event     string      `json:"-"`
+	ProductID uint        `json:"product_id"`
+	Data      interface{} `json:"data"`
+	At        time.Time   `json:"at"`
+}
+
+// Broker phát các thay đổi tồn kho/giá tới các client đang kết nối SSE trong tiến trình này
+type Broker struct {
+	seq     atomic.Uint64
+	dropped atomic.Int64
+
+	mu      sync.RWMutex
+	clients map[chan Message]struct{}
+}
+
+// NewBroker tạo broker mới và đăng ký nhận sự kiện thay đổi tồn kho/giá trên bus
+func NewBroker(bus *events.Bus) *Broker {
+	b := &Broker{clients: make(map[chan Message]struct{})}
+
+	events.On(bus, func(_ context.Context, e events.StockChanged) {
+		b.Broadcast("stock", e.ProductID, map[string]interface{}{
+			"stock":          e.NewStock,
+			"previous_stock": e.OldStock,
+			"in_stock":       e.NewStock > 0,
+		}, e.ChangedAt)
+	})
+	events.On(bus, func(_ context.Context, e events.PriceChanged) {
+		b.Broadcast("price", e.ProductID, map[string]interface{}{
+			"price":          e.NewPrice,
+			"previous_price": e.OldPrice,
+		}, e.ChangedAt)
+	})
+	events.On(bus, func(_ context.Context, e events.ProductDeleted) {
+		b.Broadcast("deleted", e.ProductID, nil, time.Now())
+	})
+	return b
+}
+
+// Subscribe đăng ký một client; gọi hàm trả về để hủy đăng ký
+func (b *Broker) Subscribe() (<-chan Message, func()) {
+	ch := make(chan Message, clientBuffer)
+	b.mu.Lock()
+	b.clients[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.clients, ch)
+		b.mu.Unlock()
+	}
+}
+
+// Broadcast gửi message tới mọi client; client đọc chậm (buffer đầy) sẽ bị bỏ qua message này
+func (b *Broker) Broadcast(event string, productID uint, data interface{}, at time.Time) {
+	msg := Message{ID: b.seq.Add(1), Event: event, ProductID: productID, Data: data, At: at}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.clients {
+		select {
+		case ch <- msg:
+		default:
+			b.dropped.Add(1)
+		}
+	}
+}
+
+// GetStats trả về số client đang kết nối và số message bị bỏ
+func (b *Broker) GetStats() map[string]interface{} {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return map[string]interface{}{
+		"clients": len(b.clients),
+		"sent":    b.seq.Load(),
+		"dropped": b.dropped.Load(),
+	}
+}
@@ -0,0 +1,107 @@
+package tax
+
+import (
+	"math"
+	"os"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+)
+
+// Config là cấu hình tính thuế chung
+type Config struct {
+	// DefaultRate là thuế suất (phần trăm) khi không có quy tắc nào khớp
+	DefaultRate float64
+	// PricesIncludeTax cho biết giá niêm yết của sản phẩm đã gồm thuế GTGT hay chưa
+	PricesIncludeTax bool
+}
+
+// ConfigFromEnv đọc PRICES_INCLUDE_TAX (true/false, mặc định false); defaultRate thường là
+// thuế suất mặc định của hóa đơn (INVOICE_TAX_RATE)
+func ConfigFromEnv(defaultRate float64) Config {
+	return Config{
+		DefaultRate:      defaultRate,
+		PricesIncludeTax: os.Getenv("PRICES_INCLUDE_TAX") == "true",
+	}
+}
+
+// Line là một dòng hàng cần tính thuế; Amount là thành tiền theo giá niêm yết
+type Line struct {
+	Category string
+	Amount   float64
+}
+
+// LineTax là kết quả tính thuế của một dòng. Net là thành tiền chưa thuế; khi giá đã gồm thuế,
+// Net + Tax bằng thành tiền niêm yết.
+type LineTax struct {
+	Rate float64
+	Net  float64
+	Tax  float64
+}
+
+// Engine chọn thuế suất theo quy tắc và tính thuế cho các dòng hàng
+type Engine struct {
+	rules  []models.TaxRate
+	config Config
+}
+
+func NewEngine(rules []models.TaxRate, config Config) *Engine {
+	return &Engine{rules: rules, config: config}
+}
+
+// PricesIncludeTax cho biết giá niêm yết đã gồm thuế hay chưa
+func (e *Engine) PricesIncludeTax() bool {
+	return e.config.PricesIncludeTax
+}
+
+// Rate trả về thuế suất của danh mục tại tỉnh/thành province theo quy tắc cụ thể nhất khớp
+func (e *Engine) Rate(category, province string) float64 {
+	best, bestScore := -1.0, -1
+	for _, rule := range e.rules {
+		score := 0
+		if rule.Category != "" {
+			if !sameName(rule.Category, category) {
+				continue
+			}
+			score += 2
+		}
+		if rule.Province != "" {
+			if !sameName(rule.Province, province) {
+				continue
+			}
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = rule.Rate, score
+		}
+	}
+	if bestScore < 0 {
+		return e.config.DefaultRate
+	}
+	return best
+}
+
+// Apply tính thuế cho từng dòng giao tới province (rỗng nếu không xác định được tỉnh)
+func (e *Engine) Apply(lines []Line, province string) []LineTax {
+	result := make([]LineTax, len(lines))
+	for i, line := range lines {
+		rate := e.Rate(line.Category, province)
+		net, tax := Split(line.Amount, rate, e.config.PricesIncludeTax)
+		result[i] = LineTax{Rate: rate, Net: net, Tax: tax}
+	}
+	return result
+}
+
+// Split tách thành tiền amount thành phần chưa thuế và tiền thuế theo thuế suất rate (phần trăm),
+// làm tròn tới đồng. inclusive cho biết amount đã gồm thuế hay chưa.
+func Split(amount, rate float64, inclusive bool) (net, tax float64) {
+	if inclusive {
+		net = math.Round(amount / (1 + rate/100))
+		return net, amount - net
+	}
+	return amount, math.Round(amount * rate / 100)
+}
+
+func sameName(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
@@ -0,0 +1,219 @@
+// Package token cấp và kiểm tra JWT đăng nhập. Mọi claim (iss, aud, iat, exp, jti, loại token và
+// thông tin user) đều được kiểm tra khi parse; token thiếu hoặc sai bất kỳ claim nào bị từ chối.
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TypeAccess là loại token dùng để gọi API
+const TypeAccess = "access"
+
+// Cách giao token cho client sau khi đăng nhập: trong body (client tự gửi header Authorization)
+// hoặc trong cookie HttpOnly kèm bảo vệ CSRF cho client trình duyệt
+const (
+	ModeToken  = "token"
+	ModeCookie = "cookie"
+)
+
+var (
+	// ErrInvalidToken được trả về khi token sai chữ ký, hết hạn hoặc sai iss/aud/iat
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrInvalidClaims được trả về khi token hợp lệ về chữ ký nhưng thiếu hoặc sai claim của ứng dụng
+	ErrInvalidClaims = errors.New("invalid token claims")
+)
+
+// Claims là nội dung của JWT đăng nhập
+type Claims struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	// Version là token_version của user lúc cấp token, dùng để thu hồi phiên
+	Version int    `json:"ver"`
+	Type    string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// Config là cấu hình ký và kiểm tra JWT
+type Config struct {
+	Secret   string
+	Issuer   string
+	Audience string
+	TTL      time.Duration
+
+	// Mode là cách giao token mặc định khi request đăng nhập không chỉ định auth_mode
+	Mode           string
+	CookieDomain   string
+	CookieSameSite http.SameSite
+	CookieSecure   bool
+}
+
+// ConfigFromEnv đọc JWT_ISSUER (mặc định project_backend), JWT_AUDIENCE (mặc định project_backend-api)
+// và JWT_TTL (mặc định 24h); secret là JWT_SECRET đã được kiểm tra khi khởi động. Chế độ cookie đọc
+// AUTH_MODE (token hoặc cookie, mặc định token), AUTH_COOKIE_DOMAIN, AUTH_COOKIE_SAMESITE (lax, strict
+// hoặc none, mặc định lax) và AUTH_COOKIE_SECURE (mặc định true).
+func ConfigFromEnv(secret string) Config {
+	cfg := Config{
+		Secret:         secret,
+		Issuer:         config.String("JWT_ISSUER", "project_backend"),
+		Audience:       config.String("JWT_AUDIENCE", "project_backend-api"),
+		TTL:            config.Duration("JWT_TTL", 24*time.Hour),
+		Mode:           config.String("AUTH_MODE", ModeToken),
+		CookieDomain:   os.Getenv("AUTH_COOKIE_DOMAIN"),
+		CookieSameSite: http.SameSiteLaxMode,
+		CookieSecure:   os.Getenv("AUTH_COOKIE_SECURE") != "false",
+	}
+	if cfg.Mode != ModeToken && cfg.Mode != ModeCookie {
+		log.Printf("Warning: invalid AUTH_MODE=%q, using %s", cfg.Mode, ModeToken)
+		cfg.Mode = ModeToken
+	}
+	switch v := strings.ToLower(os.Getenv("AUTH_COOKIE_SAMESITE")); v {
+	case "", "lax":
+	case "strict":
+		cfg.CookieSameSite = http.SameSiteStrictMode
+	case "none":
+		// Trình duyệt chỉ nhận cookie SameSite=None khi có Secure
+		cfg.CookieSameSite, cfg.CookieSecure = http.SameSiteNoneMode, true
+	default:
+		log.Printf("Warning: invalid AUTH_COOKIE_SAMESITE=%q, using lax", v)
+	}
+	return cfg
+}
+
+// Manager cấp và kiểm tra JWT theo Config
+type Manager struct {
+	config Config
+	parser *jwt.Parser
+}
+
+func NewManager(cfg Config) *Manager {
+	return &Manager{
+		config: cfg,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+			jwt.WithIssuer(cfg.Issuer),
+			jwt.WithAudience(cfg.Audience),
+			jwt.WithIssuedAt(),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(30*time.Second),
+		),
+	}
+}
+
+// Issue cấp access token cho user
+func (m *Manager) Issue(user *models.User) (string, *Claims, error) {
+	jti, err := newID()
+	if err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	claims := &Claims{
+		UserID:   user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+		Version:  user.TokenVersion,
+		Type:     TypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    m.config.Issuer,
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			Audience:  jwt.ClaimStrings{m.config.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.config.TTL)),
+		},
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(m.config.Secret))
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
+}
+
+// Parse kiểm tra chữ ký và mọi claim của access token
+func (m *Manager) Parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := m.parser.ParseWithClaims(tokenString, claims, func(*jwt.Token) (interface{}, error) {
+		return []byte(m.config.Secret), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	switch {
+	case claims.Type != TypeAccess:
+		return nil, fmt.Errorf("%w: unexpected token type %q", ErrInvalidClaims, claims.Type)
+	case claims.ID == "":
+		return nil, fmt.Errorf("%w: missing jti", ErrInvalidClaims)
+	case claims.IssuedAt == nil:
+		return nil, fmt.Errorf("%w: missing iat", ErrInvalidClaims)
+	case claims.UserID == 0 || claims.Subject != strconv.FormatUint(uint64(claims.UserID), 10):
+		return nil, fmt.Errorf("%w: user_id does not match sub", ErrInvalidClaims)
+	case claims.Username == "" || claims.Role == "":
+		return nil, fmt.Errorf("%w: missing username or role", ErrInvalidClaims)
+	}
+	return claims, nil
+}
+
+// MinSecretLength là độ dài tối thiểu của JWT_SECRET (256 bit cho HS256)
+const MinSecretLength = 32
+
+// SelfTest kiểm tra secret đủ dài và cấp rồi parse được một token với issuer, audience và TTL đang cấu hình
+func (m *Manager) SelfTest() error {
+	if len(m.config.Secret) < MinSecretLength {
+		return fmt.Errorf("JWT_SECRET must be at least %d characters, got %d", MinSecretLength, len(m.config.Secret))
+	}
+	if m.config.TTL <= 0 {
+		return fmt.Errorf("JWT_TTL must be positive, got %s", m.config.TTL)
+	}
+	signed, _, err := m.Issue(&models.User{ID: 1, Username: "selfcheck", Role: "user"})
+	if err != nil {
+		return fmt.Errorf("issue: %w", err)
+	}
+	if _, err := m.Parse(signed); err != nil {
+		return fmt.Errorf("parse: %w", err)
+	}
+	return nil
+}
+
+// Config trả về cấu hình của manager
+func (m *Manager) Config() Config {
+	return m.config
+}
+
+// CSRFToken là token chống CSRF gắn với phiên đăng nhập bằng cookie: HMAC của jti nên không thể
+// dựng được khi không biết secret, và hết hiệu lực cùng access token
+func (m *Manager) CSRFToken(claims *Claims) string {
+	mac := hmac.New(sha256.New, []byte("csrf:"+m.config.Secret))
+	mac.Write([]byte(claims.ID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyCSRF kiểm tra giá trị header CSRF của request với phiên của claims
+func (m *Manager) VerifyCSRF(claims *Claims, value string) bool {
+	return value != "" && hmac.Equal([]byte(value), []byte(m.CSRFToken(claims)))
+}
+
+// newID sinh jti ngẫu nhiên 128 bit
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
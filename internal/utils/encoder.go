@@ -0,0 +1,356 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FormatKey là khóa trong gin.Context chứa media type của response đã thương lượng từ header Accept, do
+// middleware.Negotiate ghi cho các route hỗ trợ nhiều định dạng
+const FormatKey = "response_format"
+
+// Các media type có encoder sẵn
+const (
+	MIMEJSON = "application/json"
+	MIMEXML  = "application/xml"
+	MIMECSV  = "text/csv"
+)
+
+// Encoder ghi body của response (Response, PaginatedResponse...) theo một định dạng
+type Encoder interface {
+	// ContentType là giá trị header Content-Type của response
+	ContentType() string
+	Encode(w io.Writer, body interface{}) error
+}
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]Encoder{
+		MIMEJSON:    jsonEncoder{},
+		MIMEXML:     xmlEncoder{},
+		MIMECSV:     csvEncoder{},
+		MIMEJSONAPI: jsonAPIEncoder{},
+	}
+	// mediaTypes giữ thứ tự đăng ký
+	mediaTypes = []string{MIMEJSON, MIMEXML, MIMECSV, MIMEJSONAPI}
+)
+
+// requestEncoder là Encoder cần thông tin của request (route, URL) để ghi body, ví dụ JSON:API dựng
+// type của resource và links phân trang; Render dùng EncodeRequest thay cho Encode
+type requestEncoder interface {
+	EncodeRequest(c *gin.Context, w io.Writer, body interface{}) error
+}
+
+// RegisterEncoder thêm (hoặc thay) encoder cho media type
+func RegisterEncoder(mediaType string, encoder Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	if _, ok := encoders[mediaType]; !ok {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	encoders[mediaType] = encoder
+}
+
+// MediaTypes trả về các media type có encoder; DefaultMediaType đứng đầu nên được chọn khi Accept rỗng hoặc */*
+func MediaTypes() []string {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	preferred := DefaultMediaType()
+	types := []string{preferred}
+	for _, mediaType := range mediaTypes {
+		if mediaType != preferred {
+			types = append(types, mediaType)
+		}
+	}
+	return types
+}
+
+// Render ghi body theo định dạng đã thương lượng của request (FormatKey); mặc định là JSON như c.JSON
+func Render(c *gin.Context, status int, body interface{}) {
+	encodersMu.RLock()
+	encoder, ok := encoders[c.GetString(FormatKey)]
+	encodersMu.RUnlock()
+	if !ok {
+		c.JSON(status, body)
+		return
+	}
+
+	var buf bytes.Buffer
+	var err error
+	if re, ok := encoder.(requestEncoder); ok {
+		err = re.EncodeRequest(c, &buf, body)
+	} else {
+		err = encoder.Encode(&buf, body)
+	}
+	if err != nil {
+		c.JSON(status, body)
+		return
+	}
+	c.Data(status, encoder.ContentType(), buf.Bytes())
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) ContentType() string { return "application/json; charset=utf-8" }
+
+func (jsonEncoder) Encode(w io.Writer, body interface{}) error {
+	return json.NewEncoder(w).Encode(body)
+}
+
+// xmlEncoder ghi toàn bộ envelope dưới phần tử <response>, mỗi field JSON là một phần tử cùng tên (theo
+// thứ tự field), mỗi phần tử của mảng là <item>. Field có tên không hợp lệ trong XML (ví dụ key của
+// specs có dấu cách) được ghi là <field name="...">.
+type xmlEncoder struct{}
+
+func (xmlEncoder) ContentType() string { return "application/xml; charset=utf-8" }
+
+func (xmlEncoder) Encode(w io.Writer, body interface{}) error {
+	value, err := orderedValueOf(body)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if err := writeXML(enc, "response", value); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+func writeXML(enc *xml.Encoder, name string, value interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if !isXMLName(name) {
+		start = xml.StartElement{Name: xml.Name{Local: "field"}, Attr: []xml.Attr{{Name: xml.Name{Local: "name"}, Value: name}}}
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case *orderedObject:
+		for _, key := range v.keys {
+			if err := writeXML(enc, key, v.values[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := writeXML(enc, "item", item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		if err := enc.EncodeToken(xml.CharData(scalarString(v))); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// isXMLName kiểm tra name dùng được làm tên phần tử XML (chỉ chữ ASCII, số, '_', '-', '.')
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		letter := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_'
+		if i == 0 && !letter {
+			return false
+		}
+		if !letter && !(r >= '0' && r <= '9') && r != '-' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// csvEncoder ghi data của response thành bảng: mỗi object trong data là một dòng, cột là các field theo
+// thứ tự xuất hiện, field lồng nhau (specs, mảng...) được ghi dạng JSON. Message và meta (phân trang)
+// không có trong CSV; danh sách rỗng cho body rỗng, response lỗi ghi chính envelope thành một dòng.
+type csvEncoder struct{}
+
+func (csvEncoder) ContentType() string { return "text/csv; charset=utf-8" }
+
+func (csvEncoder) Encode(w io.Writer, body interface{}) error {
+	value, err := orderedValueOf(body)
+	if err != nil {
+		return err
+	}
+	envelope, ok := value.(*orderedObject)
+	if !ok {
+		return errors.New("csv: response is not an object")
+	}
+
+	var rows []*orderedObject
+	if _, failed := envelope.values["error"]; failed {
+		rows = []*orderedObject{envelope}
+	}
+	switch data := envelope.values["data"].(type) {
+	case []interface{}:
+		for _, item := range data {
+			row, ok := item.(*orderedObject)
+			if !ok {
+				return errors.New("csv: data is not a list of objects")
+			}
+			rows = append(rows, row)
+		}
+	case *orderedObject:
+		rows = []*orderedObject{data}
+	case nil:
+	default:
+		return errors.New("csv: data is not an object")
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+	var columns []string
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		for _, key := range row.keys {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, column := range columns {
+			record[i] = csvCell(row.values[column])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvCell(value interface{}) string {
+	switch value.(type) {
+	case *orderedObject, []interface{}:
+		raw, _ := json.Marshal(value)
+		return string(raw)
+	}
+	return scalarString(value)
+}
+
+func scalarString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	}
+	return ""
+}
+
+// orderedObject là object JSON giữ thứ tự field (map của Go không giữ thứ tự)
+type orderedObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedObject() *orderedObject {
+	return &orderedObject{values: make(map[string]interface{})}
+}
+
+// set gán giá trị cho key, key mới được thêm vào cuối
+func (o *orderedObject) set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// orderedValueOf chuyển body sang dạng JSON tổng quát (orderedObject, []interface{}, string, json.Number,
+// bool, nil) để mọi encoder dùng đúng tên field và thứ tự như response JSON
+func orderedValueOf(body interface{}) (interface{}, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return decodeOrdered(dec)
+}
+
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		object := newOrderedObject()
+		for dec.More() {
+			keyToken, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyToken.(string)
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			object.set(key, value)
+		}
+		_, err := dec.Token() // '}'
+		return object, err
+	case json.Delim('['):
+		items := []interface{}{}
+		for dec.More() {
+			item, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err := dec.Token() // ']'
+		return items, err
+	}
+	return token, nil
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FieldsKey là khóa trong gin.Context chứa danh sách field client yêu cầu (?fields=id,name), do
+// middleware.SparseFields ghi cho các route hỗ trợ sparse fieldset
+const FieldsKey = "fields"
+
+// selectFields giữ lại các field cấp cao nhất được yêu cầu trong data (một object hoặc mảng object).
+// Field không tồn tại bị bỏ qua; data giữ nguyên khi request không yêu cầu field nào hoặc data không
+// phải object.
+func selectFields(c *gin.Context, data interface{}) interface{} {
+	fields := c.GetStringSlice(FieldsKey)
+	if len(fields) == 0 || data == nil {
+		return data
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return data
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber() // giữ nguyên số lớn và số thập phân
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return data
+	}
+
+	keep := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		keep[field] = true
+	}
+	pick := func(item interface{}) interface{} {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return item
+		}
+		for key := range object {
+			if !keep[key] {
+				delete(object, key)
+			}
+		}
+		return object
+	}
+	if items, ok := value.([]interface{}); ok {
+		for i := range items {
+			items[i] = pick(items[i])
+		}
+		return items
+	}
+	return pick(value)
+}
@@ -0,0 +1,286 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// MIMEJSONAPI là media type của tài liệu JSON:API (https://jsonapi.org)
+const MIMEJSONAPI = "application/vnd.api+json"
+
+// ResponseFormatJSONAPI là giá trị RESPONSE_FORMAT để trả JSON:API mặc định trên các route thương lượng định dạng
+const ResponseFormatJSONAPI = "jsonapi"
+
+// DefaultMediaType là định dạng của route thương lượng định dạng khi header Accept không chọn định dạng
+// nào: JSON:API nếu RESPONSE_FORMAT=jsonapi, ngược lại là JSON
+func DefaultMediaType() string {
+	if strings.EqualFold(config.String("RESPONSE_FORMAT", "json"), ResponseFormatJSONAPI) {
+		return MIMEJSONAPI
+	}
+	return MIMEJSON
+}
+
+// jsonAPIEncoder ghi envelope thành tài liệu JSON:API: mỗi object trong data là một resource
+// {type, id, attributes, relationships}, các quan hệ mở rộng bằng ?include= nằm trong included của tài
+// liệu, message và meta của envelope chuyển vào meta, phân trang có thêm links first/last/prev/next.
+// Type là đoạn tĩnh cuối của route (/products/:id -> products); type của quan hệ là tên quan hệ ở số
+// nhiều (category -> categories). Response lỗi được ghi thành errors.
+type jsonAPIEncoder struct{}
+
+func (jsonAPIEncoder) ContentType() string { return MIMEJSONAPI }
+
+// Encode ghi tài liệu không có type và links vì không biết request; Render dùng EncodeRequest
+func (e jsonAPIEncoder) Encode(w io.Writer, body interface{}) error {
+	return e.encode(w, body, "", nil)
+}
+
+func (e jsonAPIEncoder) EncodeRequest(c *gin.Context, w io.Writer, body interface{}) error {
+	return e.encode(w, body, routeResourceType(c.FullPath()), c.Request.URL)
+}
+
+func (jsonAPIEncoder) encode(w io.Writer, body interface{}, resourceType string, requestURL *url.URL) error {
+	value, err := orderedValueOf(body)
+	if err != nil {
+		return err
+	}
+	envelope, ok := value.(*orderedObject)
+	if !ok {
+		return errors.New("jsonapi: response is not an object")
+	}
+
+	document := newOrderedObject()
+	document.set("jsonapi", map[string]string{"version": "1.1"})
+	if _, failed := envelope.values["error"]; failed {
+		document.set("errors", jsonAPIErrors(envelope))
+		return writeJSONAPI(w, document)
+	}
+
+	included := &jsonAPIIncluded{seen: make(map[string]bool)}
+	switch data := envelope.values["data"].(type) {
+	case []interface{}:
+		resources := make([]interface{}, 0, len(data))
+		for _, item := range data {
+			resources = append(resources, jsonAPIResource(resourceType, item, included))
+		}
+		document.set("data", resources)
+	case nil:
+		document.set("data", nil)
+	default:
+		document.set("data", jsonAPIResource(resourceType, data, included))
+	}
+	if len(included.resources) > 0 {
+		document.set("included", included.resources)
+	}
+
+	meta := newOrderedObject()
+	if message, ok := envelope.values["message"]; ok {
+		meta.set("message", message)
+	}
+	var pagination *orderedObject
+	if envelopeMeta, ok := envelope.values["meta"].(*orderedObject); ok {
+		pagination, _ = envelopeMeta.values["pagination"].(*orderedObject)
+		for _, key := range envelopeMeta.keys {
+			meta.set(key, envelopeMeta.values[key])
+		}
+	}
+	if len(meta.keys) > 0 {
+		document.set("meta", meta)
+	}
+	if requestURL != nil {
+		document.set("links", jsonAPILinks(requestURL, pagination))
+	}
+	return writeJSONAPI(w, document)
+}
+
+// writeJSONAPI ghi tài liệu, không escape '&' trong links để URL đọc được như trong request
+func writeJSONAPI(w io.Writer, document *orderedObject) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(document)
+}
+
+// jsonAPIIncluded gom các resource quan hệ của toàn bộ tài liệu, mỗi (type, id) một lần
+type jsonAPIIncluded struct {
+	resources []interface{}
+	seen      map[string]bool
+}
+
+func (in *jsonAPIIncluded) add(resource *orderedObject) {
+	key := scalarString(resource.values["type"]) + "/" + scalarString(resource.values["id"])
+	if in.seen[key] {
+		return
+	}
+	in.seen[key] = true
+	in.resources = append(in.resources, resource)
+}
+
+// jsonAPIResource chuyển một object của data thành resource; giá trị không phải object giữ nguyên
+func jsonAPIResource(resourceType string, item interface{}, included *jsonAPIIncluded) interface{} {
+	object, ok := item.(*orderedObject)
+	if !ok {
+		return item
+	}
+	resource := newOrderedObject()
+	if resourceType != "" {
+		resource.set("type", resourceType)
+	}
+	if id, ok := object.values["id"]; ok {
+		resource.set("id", scalarString(id))
+	}
+
+	attributes := newOrderedObject()
+	for _, key := range object.keys {
+		if key != "id" && key != "included" {
+			attributes.set(key, object.values[key])
+		}
+	}
+	resource.set("attributes", attributes)
+
+	expanded, _ := object.values["included"].(*orderedObject)
+	if expanded == nil {
+		return resource
+	}
+	relationships := newOrderedObject()
+	for _, name := range expanded.keys {
+		relatedType := pluralize(name)
+		switch related := expanded.values[name].(type) {
+		case []interface{}:
+			linkage := make([]interface{}, 0, len(related))
+			for _, entry := range related {
+				if identifier := jsonAPIRelated(relatedType, entry, included); identifier != nil {
+					linkage = append(linkage, identifier)
+				}
+			}
+			relationships.set(name, map[string]interface{}{"data": linkage})
+		default:
+			relationships.set(name, map[string]interface{}{"data": jsonAPIRelated(relatedType, related, included)})
+		}
+	}
+	resource.set("relationships", relationships)
+	return resource
+}
+
+// jsonAPIRelated thêm object quan hệ vào included và trả về resource identifier {type, id} của nó;
+// object không có id không thể tham chiếu nên bị bỏ qua
+func jsonAPIRelated(relatedType string, entry interface{}, included *jsonAPIIncluded) interface{} {
+	object, ok := entry.(*orderedObject)
+	if !ok {
+		return nil
+	}
+	if _, ok := object.values["id"]; !ok {
+		return nil
+	}
+	resource, _ := jsonAPIResource(relatedType, object, included).(*orderedObject)
+	included.add(resource)
+	return map[string]string{"type": relatedType, "id": resource.values["id"].(string)}
+}
+
+// jsonAPIErrors chuyển response lỗi (v1: message + error, v2: ErrorBody) thành danh sách error object.
+// Lỗi dạng map field -> message cho mỗi field một error object có source.pointer tới attribute đó.
+func jsonAPIErrors(envelope *orderedObject) []interface{} {
+	status := scalarString(envelope.values["status"])
+	title := envelope.values["message"]
+	code := ""
+	details := envelope.values["error"]
+	if body, ok := details.(*orderedObject); ok {
+		if _, v2 := body.values["code"]; v2 {
+			code = scalarString(body.values["code"])
+			title = body.values["message"]
+			details = body.values["details"]
+		}
+	}
+
+	newError := func() *orderedObject {
+		item := newOrderedObject()
+		item.set("status", status)
+		if code != "" {
+			item.set("code", code)
+		}
+		if title != nil {
+			item.set("title", title)
+		}
+		return item
+	}
+	if fields, ok := details.(*orderedObject); ok {
+		items := make([]interface{}, 0, len(fields.keys))
+		for _, field := range fields.keys {
+			item := newError()
+			item.set("detail", scalarString(fields.values[field]))
+			item.set("source", map[string]string{"pointer": "/data/attributes/" + field})
+			items = append(items, item)
+		}
+		return items
+	}
+	item := newError()
+	if detail := scalarString(details); detail != "" {
+		item.set("detail", detail)
+	}
+	return []interface{}{item}
+}
+
+// jsonAPILinks dựng links.self từ URL của request; với response phân trang có thêm first, last (khi biết
+// số trang), prev và next bằng cách thay tham số page (và limit) trong query
+func jsonAPILinks(requestURL *url.URL, pagination *orderedObject) *orderedObject {
+	links := newOrderedObject()
+	links.set("self", requestURL.RequestURI())
+	if pagination == nil {
+		return links
+	}
+	number := func(key string) int {
+		n, _ := strconv.Atoi(scalarString(pagination.values[key]))
+		return n
+	}
+	page, totalPages, limit := number("current_page"), number("total_pages"), number("items_per_page")
+	pageURL := func(p int) string {
+		u := *requestURL
+		query := u.Query()
+		query.Set("page", strconv.Itoa(p))
+		if limit > 0 {
+			query.Set("limit", strconv.Itoa(limit))
+		}
+		u.RawQuery = query.Encode()
+		return u.RequestURI()
+	}
+	links.set("first", pageURL(1))
+	if totalPages > 0 {
+		links.set("last", pageURL(totalPages))
+	}
+	if pagination.values["has_prev"] == true && page > 1 {
+		links.set("prev", pageURL(page-1))
+	}
+	if pagination.values["has_next"] == true {
+		links.set("next", pageURL(page+1))
+	}
+	return links
+}
+
+// routeResourceType là đoạn tĩnh cuối cùng của route, ví dụ /api/v1/admin/users -> users,
+// /api/v1/products/:id -> products
+func routeResourceType(route string) string {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segment := segments[i]; segment != "" && segment[0] != ':' && segment[0] != '*' {
+			return segment
+		}
+	}
+	return ""
+}
+
+// pluralize đổi tên quan hệ sang số nhiều theo quy tắc tiếng Anh đơn giản (category -> categories,
+// review -> reviews); tên đã ở số nhiều giữ nguyên
+func pluralize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "s") || name == "media":
+		return name
+	case strings.HasSuffix(name, "y") && !strings.HasSuffix(name, "ay") && !strings.HasSuffix(name, "ey") && !strings.HasSuffix(name, "oy"):
+		return strings.TrimSuffix(name, "y") + "ies"
+	}
+	return name + "s"
+}
@@ -0,0 +1,13 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IsSecureRequest cho biết request đến qua HTTPS (trực tiếp hoặc sau reverse proxy); dùng để đặt cờ Secure
+// cho cookie
+func IsSecureRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+}
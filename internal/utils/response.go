@@ -1,11 +1,22 @@
 package utils
 
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/i18n"
+	"github.com/gin-gonic/gin"
+)
+
 // Response là cấu trúc response chung cho tất cả API
 type Response struct {
 	Status  int         `json:"status"`
-	Message string      `json:"message"`
+	Message string      `json:"message,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   interface{} `json:"error,omitempty"`
+	Meta    interface{} `json:"meta,omitempty"`
 }
 
 // PaginatedResponse là cấu trúc response cho các API có phân trang
@@ -32,30 +43,193 @@ type Pagination struct {
 	HasPrev      bool  `json:"has_prev"`
 }
 
-// NewResponse tạo một response mới
-func NewResponse(status int, message string, data interface{}) Response {
+// NewResponse tạo một response mới, message được dịch theo ngôn ngữ của request. Trên route bật
+// sparse fieldset, data chỉ giữ các field được yêu cầu bằng ?fields=. Danh sách rỗng luôn được ghi là []
+// thay vì null (xem emptyLists).
+func NewResponse(c *gin.Context, status int, message string, data interface{}) Response {
 	return Response{
 		Status:  status,
-		Message: message,
-		Data:    data,
+		Message: i18n.Localize(c, message),
+		Data:    selectFields(c, emptyLists(data)),
+	}
+}
+
+// maxEmptyListsDepth giới hạn độ sâu emptyLists đi vào data, phòng khi data có con trỏ vòng
+const maxEmptyListsDepth = 32
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// emptyLists thay mọi slice nil trong data bằng slice rỗng cùng kiểu để JSON có [] thay vì null khi không
+// có kết quả: chính data, phần tử của slice, giá trị của map, field được export của struct (kể cả qua con
+// trỏ và interface). Phần bị thay được sao chép, dữ liệu của caller giữ nguyên. []byte và kiểu tự
+// MarshalJSON không bị đụng tới.
+func emptyLists(data interface{}) interface{} {
+	if value, changed := fillEmptyLists(reflect.ValueOf(data), 0); changed {
+		return value.Interface()
 	}
+	return data
 }
 
-// NewErrorResponse tạo một response lỗi
-func NewErrorResponse(status int, message string, err interface{}) Response {
+// fillEmptyLists trả về bản sao của v với slice nil được thay bằng slice rỗng; changed là false (và v
+// được trả về nguyên vẹn) khi v không chứa slice nil nào
+func fillEmptyLists(v reflect.Value, depth int) (reflect.Value, bool) {
+	if !v.IsValid() || depth > maxEmptyListsDepth || v.Type().Implements(jsonMarshalerType) ||
+		reflect.PointerTo(v.Type()).Implements(jsonMarshalerType) {
+		return v, false
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v, false
+		}
+		if v.IsNil() {
+			return reflect.MakeSlice(v.Type(), 0, 0), true
+		}
+		var copied reflect.Value
+		for i := 0; i < v.Len(); i++ {
+			elem, changed := fillEmptyLists(v.Index(i), depth+1)
+			if !changed {
+				continue
+			}
+			if !copied.IsValid() {
+				copied = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+				reflect.Copy(copied, v)
+			}
+			copied.Index(i).Set(elem)
+		}
+		if copied.IsValid() {
+			return copied, true
+		}
+	case reflect.Map:
+		var copied reflect.Value
+		iter := v.MapRange()
+		for iter.Next() {
+			if _, changed := fillEmptyLists(iter.Value(), depth+1); changed {
+				copied = reflect.MakeMapWithSize(v.Type(), v.Len())
+				break
+			}
+		}
+		if !copied.IsValid() {
+			return v, false
+		}
+		iter = v.MapRange()
+		for iter.Next() {
+			entry, _ := fillEmptyLists(iter.Value(), depth+1)
+			copied.SetMapIndex(iter.Key(), entry)
+		}
+		return copied, true
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v, false
+		}
+		if elem, changed := fillEmptyLists(v.Elem(), depth+1); changed {
+			copied := reflect.New(v.Type().Elem())
+			copied.Elem().Set(elem)
+			return copied, true
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, false
+		}
+		if elem, changed := fillEmptyLists(v.Elem(), depth+1); changed {
+			copied := reflect.New(v.Type()).Elem()
+			copied.Set(elem)
+			return copied, true
+		}
+	case reflect.Struct:
+		var copied reflect.Value
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+			value, changed := fillEmptyLists(v.Field(i), depth+1)
+			if !changed {
+				continue
+			}
+			if !copied.IsValid() {
+				copied = reflect.New(v.Type()).Elem()
+				copied.Set(v)
+			}
+			copied.Field(i).Set(value)
+		}
+		if copied.IsValid() {
+			return copied, true
+		}
+	}
+	return v, false
+}
+
+// ErrorBody là lỗi trong response từ API v2: Code ổn định để client xử lý theo loại lỗi,
+// Message đã được dịch, Details là chi tiết (chuỗi hoặc map field -> message)
+type ErrorBody struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// APIVersionKey là khóa trong gin.Context chứa phiên bản API của request (v1, v2)
+const APIVersionKey = "api_version"
+
+// APIVersion trả về phiên bản API của request; mặc định v1 với route ngoài các group phiên bản
+func APIVersion(c *gin.Context) string {
+	if version := c.GetString(APIVersionKey); version != "" {
+		return version
+	}
+	return "v1"
+}
+
+// NewErrorResponse tạo một response lỗi, message và các thông báo lỗi dạng chuỗi được dịch
+// theo ngôn ngữ của request. Từ API v2, message và chi tiết lỗi nằm trong error dạng ErrorBody.
+func NewErrorResponse(c *gin.Context, status int, message string, err interface{}) Response {
+	if APIVersion(c) != "v1" {
+		return Response{
+			Status: status,
+			Error: ErrorBody{
+				Code:    errorCode(status),
+				Message: i18n.Localize(c, message),
+				Details: localizeError(c, err),
+			},
+		}
+	}
 	return Response{
 		Status:  status,
-		Message: message,
-		Error:   err,
+		Message: i18n.Localize(c, message),
+		Error:   localizeError(c, err),
+	}
+}
+
+// errorCode là mã lỗi dạng snake_case từ HTTP status, ví dụ 404 -> not_found
+func errorCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return strings.ReplaceAll(strings.ToLower(text), " ", "_")
+}
+
+// localizeError dịch lỗi dạng chuỗi hoặc map field -> message; các kiểu khác giữ nguyên
+func localizeError(c *gin.Context, err interface{}) interface{} {
+	switch e := err.(type) {
+	case string:
+		return i18n.Localize(c, e)
+	case map[string]string:
+		translated := make(map[string]string, len(e))
+		for field, msg := range e {
+			translated[field] = i18n.Localize(c, msg)
+		}
+		return translated
 	}
+	return err
 }
 
-// NewPaginatedResponse tạo một response có phân trang
-func NewPaginatedResponse(status int, message string, data interface{}, currentPage, totalPages int, totalItems int64, itemsPerPage int, filters map[string]interface{}) PaginatedResponse {
+// NewPaginatedResponse tạo một response có phân trang; data được xử lý như NewResponse (lọc theo ?fields=,
+// trang rỗng là [])
+func NewPaginatedResponse(c *gin.Context, status int, message string, data interface{}, currentPage, totalPages int, totalItems int64, itemsPerPage int, filters map[string]interface{}) PaginatedResponse {
 	return PaginatedResponse{
 		Status:  status,
-		Message: message,
-		Data:    data,
+		Message: i18n.Localize(c, message),
+		Data:    selectFields(c, emptyLists(data)),
 		Meta: Meta{
 			Pagination: Pagination{
 				CurrentPage:  currentPage,
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testItem struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+type testPage struct {
+	Items   []testItem   `json:"items"`
+	Primary *testItem    `json:"primary"`
+	Extra   interface{}  `json:"extra"`
+	Raw     []byte       `json:"raw"`
+	At      time.Time    `json:"at"`
+	Hidden  []string     `json:"-"`
+	Nested  gin.H        `json:"nested"`
+	Groups  [][]testItem `json:"groups"`
+}
+
+// dataJSON trả về "data" của response NewResponse dưới dạng JSON
+func dataJSON(t *testing.T, data interface{}) string {
+	t.Helper()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	raw, err := json.Marshal(NewResponse(c, http.StatusOK, "OK", data).Data)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return string(raw)
+}
+
+func TestEmptyListsTopLevel(t *testing.T) {
+	var items []testItem
+	if got := dataJSON(t, items); got != "[]" {
+		t.Errorf("nil slice = %s, want []", got)
+	}
+	if got := dataJSON(t, []testItem{{Name: "a"}}); got != `[{"name":"a","tags":[]}]` {
+		t.Errorf("slice element = %s", got)
+	}
+	if got := dataJSON(t, nil); got != "null" {
+		t.Errorf("nil data = %s, want null", got)
+	}
+}
+
+func TestEmptyListsMapNested(t *testing.T) {
+	var rates []float64
+	data := gin.H{"rates": rates, "inner": map[string]interface{}{"ids": []uint(nil)}, "none": nil}
+	if got, want := dataJSON(t, data), `{"inner":{"ids":[]},"none":null,"rates":[]}`; got != want {
+		t.Errorf("map = %s, want %s", got, want)
+	}
+	if data["rates"].([]float64) != nil {
+		t.Error("caller's map was modified")
+	}
+}
+
+func TestEmptyListsStructNested(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	page := &testPage{
+		Items:   []testItem{{Name: "a"}, {Name: "b", Tags: []string{"x"}}},
+		Primary: &testItem{Name: "p"},
+		Extra:   testItem{Name: "e"},
+		At:      at,
+		Nested:  gin.H{"list": []string(nil)},
+	}
+	want := `{"items":[{"name":"a","tags":[]},{"name":"b","tags":["x"]}],"primary":{"name":"p","tags":[]},` +
+		`"extra":{"name":"e","tags":[]},"raw":null,"at":"2026-01-02T03:04:05Z","nested":{"list":[]},"groups":[]}`
+	if got := dataJSON(t, page); got != want {
+		t.Errorf("struct = %s\nwant     %s", got, want)
+	}
+	if page.Items[0].Tags != nil || page.Primary.Tags != nil || page.Groups != nil {
+		t.Error("caller's struct was modified")
+	}
+}
+
+func TestEmptyListsUnchanged(t *testing.T) {
+	// Không có slice nil thì data được trả về nguyên vẹn, không sao chép
+	item := &testItem{Name: "a", Tags: []string{"x"}}
+	if got := emptyLists(item); got != interface{}(item) {
+		t.Errorf("emptyLists(%v) = %v, want the same pointer", item, got)
+	}
+	// Con trỏ vòng không làm emptyLists chạy mãi
+	type node struct {
+		IDs  []uint `json:"ids"`
+		Self *node  `json:"self"`
+	}
+	n := &node{}
+	n.Self = n
+	if _, ok := emptyLists(n).(*node); !ok {
+		t.Error("emptyLists changed the type of a cyclic value")
+	}
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Slugify chuyển chuỗi thành slug cho URL: chữ thường không dấu, các ký tự khác chữ và số được thay
+// bằng một dấu gạch ngang ("Điện thoại iPhone 15" → "dien-thoai-iphone-15")
+func Slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Bỏ dấu thanh và dấu mũ đã tách ra bởi NFD
+			continue
+		case r == 'đ':
+			r = 'd'
+		}
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
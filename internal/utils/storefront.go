@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+)
+
+// StorefrontURL đọc địa chỉ gốc của frontend (STOREFRONT_URL) dùng để dựng URL canonical, không có "/" ở cuối
+func StorefrontURL() string {
+	return strings.TrimSuffix(config.String("STOREFRONT_URL", "http://localhost:3000"), "/")
+}
+
+// ProductPath trả về đường dẫn trang sản phẩm trên storefront: /products/<id>-<slug>. Đường dẫn mang ID
+// nên vẫn trỏ đúng sản phẩm khi tên (và slug) đổi.
+func ProductPath(id uint, name string) string {
+	if slug := Slugify(name); slug != "" {
+		return fmt.Sprintf("/products/%d-%s", id, slug)
+	}
+	return fmt.Sprintf("/products/%d", id)
+}
+
+// CategoryPath trả về đường dẫn trang danh mục trên storefront: /categories/<slug>
+func CategoryPath(name string) string {
+	return "/categories/" + Slugify(name)
+}
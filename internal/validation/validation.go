@@ -0,0 +1,203 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/NgTruong624/project_backend/internal/i18n"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// BodyField là khóa dùng trong map lỗi cho các lỗi không gắn với field cụ thể (body rỗng, JSON sai cú pháp)
+const BodyField = "body"
+
+func init() {
+	// Dùng tên field theo tag json/form để key trong map lỗi khớp với tên field client gửi lên
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+		v.RegisterValidation("phone", validPhone)
+	}
+}
+
+// phonePattern khớp số điện thoại Việt Nam: 0 hoặc +84 theo sau bởi 9-10 chữ số
+var phonePattern = regexp.MustCompile(`^(0|\+84)[0-9]{9,10}$`)
+
+func validPhone(fl validator.FieldLevel) bool {
+	return phonePattern.MatchString(fl.Field().String())
+}
+
+func fieldName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// Respond trả về response 400 với map {field: message} cho lỗi bind request
+func Respond(c *gin.Context, err error) {
+	message := "Validation failed"
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		message = "Invalid request data"
+	}
+	c.JSON(http.StatusBadRequest, utils.NewErrorResponse(c, http.StatusBadRequest, message, Errors(i18n.FromContext(c), err)))
+}
+
+// Errors chuyển lỗi bind (validator, JSON sai kiểu/cú pháp, body rỗng) thành map {field: message}
+// với thông báo dựng từ tag binding của field, dịch theo ngôn ngữ lang
+func Errors(lang string, err error) map[string]string {
+	result := make(map[string]string)
+	if err == nil {
+		return result
+	}
+
+	var validationErrors validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &validationErrors):
+		for _, e := range validationErrors {
+			field := fieldPath(e)
+			if _, exists := result[field]; !exists {
+				result[field] = message(lang, e)
+			}
+		}
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = BodyField
+		}
+		result[field] = i18n.T(lang, "%s must be %s.", label(lang, field), i18n.T(lang, kindName(typeErr.Type.Kind())))
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		result[BodyField] = i18n.T(lang, "Request body is not valid JSON.")
+	case errors.Is(err, io.EOF):
+		result[BodyField] = i18n.T(lang, "Request body is empty.")
+	default:
+		result[BodyField] = err.Error()
+	}
+	return result
+}
+
+// fieldPath bỏ tên struct gốc khỏi namespace, ví dụ CreateInvoiceRequest.items[0].quantity -> items[0].quantity
+func fieldPath(e validator.FieldError) string {
+	ns := e.Namespace()
+	if _, rest, ok := strings.Cut(ns, "."); ok {
+		return rest
+	}
+	return e.Field()
+}
+
+func message(lang string, e validator.FieldError) string {
+	name := label(lang, e.Field())
+	param := e.Param()
+
+	switch e.Tag() {
+	case "required":
+		return i18n.T(lang, "%s is required.", name)
+	case "email":
+		return i18n.T(lang, "%s must be a valid email address.", name)
+	case "phone":
+		return i18n.T(lang, "%s must be a valid phone number.", name)
+	case "url", "http_url":
+		return i18n.T(lang, "%s must be a valid URL.", name)
+	case "eqfield":
+		return i18n.T(lang, "%s must match %s.", name, lowerFirst(label(lang, param)))
+	case "oneof":
+		return i18n.T(lang, "%s must be one of: %s.", name, strings.ReplaceAll(param, " ", ", "))
+	case "min", "gte":
+		switch e.Kind() {
+		case reflect.String:
+			return i18n.T(lang, "%s must be at least %s characters long.", name, param)
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return i18n.T(lang, "%s must contain at least %s items.", name, param)
+		}
+		return i18n.T(lang, "%s must be at least %s.", name, param)
+	case "max", "lte":
+		switch e.Kind() {
+		case reflect.String:
+			return i18n.T(lang, "%s must be at most %s characters long.", name, param)
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return i18n.T(lang, "%s must contain at most %s items.", name, param)
+		}
+		return i18n.T(lang, "%s must be at most %s.", name, param)
+	case "gt":
+		return i18n.T(lang, "%s must be greater than %s.", name, param)
+	case "lt":
+		return i18n.T(lang, "%s must be less than %s.", name, param)
+	case "len":
+		return i18n.T(lang, "%s must be exactly %s characters long.", name, param)
+	case "unique":
+		return i18n.T(lang, "%s must not contain duplicates.", name)
+	}
+	return i18n.T(lang, "%s is invalid.", name)
+}
+
+// label dựng tên hiển thị của field từ tên json/Go (new_password, NewPassword -> New password)
+// và dịch qua catalog
+func label(lang, field string) string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	for i, r := range field {
+		switch {
+		case r == '_' || r == '-' || r == '.':
+			flush()
+		case unicode.IsUpper(r) && i > 0:
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+	if len(words) == 0 {
+		return field
+	}
+
+	text := strings.Join(words, " ")
+	return i18n.T(lang, strings.ToUpper(text[:1])+text[1:])
+}
+
+func lowerFirst(s string) string {
+	for i, r := range s {
+		return string(unicode.ToLower(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
+
+func kindName(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return "a string"
+}
@@ -0,0 +1,216 @@
+// Package visitor gắn cho mỗi khách một phiên duyệt ẩn danh bằng cookie visitor_id đã ký. Cookie được
+// kiểm tra bằng chữ ký nên không cần truy vấn database; dữ liệu của phiên (giỏ hàng, sản phẩm đã xem)
+// chỉ được lưu khi cần và bị xóa bởi tác vụ visitor_session_sweep khi phiên hết hạn.
+package visitor
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/config"
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"github.com/NgTruong624/project_backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CookieName là cookie chứa id phiên của khách, dạng <id>.<thời điểm cấp>.<chữ ký>
+const CookieName = "visitor_id"
+
+// Key trong gin.Context
+const (
+	ContextKey   = "visitor_id"
+	returningKey = "visitor_returning"
+)
+
+// refreshAfter là tuổi của cookie trước khi được cấp lại để kéo dài hạn; cấp lại mỗi request sẽ thêm
+// Set-Cookie vào mọi response
+const refreshAfter = 24 * time.Hour
+
+// Config là cấu hình phiên của khách
+type Config struct {
+	// Secret dùng để ký cookie
+	Secret string
+	// TTL là thời gian phiên không có hoạt động trước khi hết hạn
+	TTL time.Duration
+	// RecentLimit là số sản phẩm đã xem gần nhất được giữ cho mỗi phiên
+	RecentLimit int
+}
+
+// ConfigFromEnv đọc VISITOR_SESSION_TTL (mặc định 720h) và RECENTLY_VIEWED_LIMIT (mặc định 20)
+func ConfigFromEnv(secret string) Config {
+	return Config{
+		Secret:      secret,
+		TTL:         config.Duration("VISITOR_SESSION_TTL", 30*24*time.Hour),
+		RecentLimit: config.Int("RECENTLY_VIEWED_LIMIT", 20),
+	}
+}
+
+// Tracker cấp cookie phiên cho khách và lưu dữ liệu của phiên. Mọi method an toàn khi Tracker là nil
+// (tính năng bị tắt): không ghi gì và trả về kết quả rỗng.
+type Tracker struct {
+	config   Config
+	tx       *repository.TxManager
+	sessions *repository.VisitorSessionRepository
+}
+
+func NewTracker(db *gorm.DB, cfg Config) *Tracker {
+	return &Tracker{
+		config:   cfg,
+		tx:       repository.NewTxManager(db),
+		sessions: repository.NewVisitorSessionRepository(db),
+	}
+}
+
+// TTL trả về thời gian hết hạn của phiên
+func (t *Tracker) TTL() time.Duration {
+	if t == nil {
+		return 0
+	}
+	return t.config.TTL
+}
+
+// Middleware đọc cookie phiên của request dưới /api/, cấp phiên mới nếu cookie không có, sai chữ ký
+// hoặc đã hết hạn, và lưu id phiên vào context. Route ngoài API (file tĩnh, /metrics) không nhận
+// cookie để response của chúng vẫn cache được.
+func (t *Tracker) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if t == nil || !strings.HasPrefix(c.Request.URL.Path, "/api/") {
+			c.Next()
+			return
+		}
+		now := time.Now()
+		id, issuedAt, ok := t.parse(cookieValue(c))
+		returning := ok && now.Sub(issuedAt) < t.config.TTL
+		if !returning {
+			var err error
+			if id, err = newID(); err != nil {
+				c.Next()
+				return
+			}
+		}
+		if !returning || now.Sub(issuedAt) > refreshAfter {
+			c.SetSameSite(http.SameSiteLaxMode)
+			c.SetCookie(CookieName, t.sign(id, now), int(t.config.TTL.Seconds()), "/", "", utils.IsSecureRequest(c), true)
+		}
+		c.Set(ContextKey, id)
+		c.Set(returningKey, returning)
+		c.Next()
+	}
+}
+
+// FromContext trả về id phiên của request; returning cho biết client đã gửi lại cookie hợp lệ (phiên
+// vừa được cấp trong request này thì returning là false)
+func FromContext(c *gin.Context) (id string, returning bool) {
+	return c.GetString(ContextKey), c.GetBool(returningKey)
+}
+
+// RecordView ghi nhận phiên vừa xem sản phẩm
+func (t *Tracker) RecordView(ctx context.Context, id string, productID uint) error {
+	if t == nil || id == "" || t.config.RecentLimit <= 0 {
+		return nil
+	}
+	return t.tx.WithinTx(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		if err := t.sessions.Touch(ctx, id, now); err != nil {
+			return err
+		}
+		return t.sessions.RecordView(ctx, id, productID, now, t.config.RecentLimit)
+	})
+}
+
+// RecentlyViewed lấy các sản phẩm phiên đã xem gần nhất
+func (t *Tracker) RecentlyViewed(ctx context.Context, id string) ([]models.Product, error) {
+	if t == nil || id == "" || t.config.RecentLimit <= 0 {
+		return []models.Product{}, nil
+	}
+	return t.sessions.RecentlyViewed(ctx, id, t.config.RecentLimit)
+}
+
+// SetCartToken gắn giỏ của khách vừa được tạo vào phiên
+func (t *Tracker) SetCartToken(ctx context.Context, id, cartToken string) error {
+	if t == nil || id == "" {
+		return nil
+	}
+	return t.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := t.sessions.Touch(ctx, id, time.Now()); err != nil {
+			return err
+		}
+		return t.sessions.SetCartToken(ctx, id, &cartToken)
+	})
+}
+
+// TakeCartToken lấy token giỏ gắn với phiên rồi gỡ khỏi phiên; dùng khi gộp giỏ lúc đăng nhập
+func (t *Tracker) TakeCartToken(ctx context.Context, id string) (string, error) {
+	if t == nil || id == "" {
+		return "", nil
+	}
+	var cartToken string
+	err := t.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if cartToken, err = t.sessions.CartToken(ctx, id); err != nil || cartToken == "" {
+			return err
+		}
+		return t.sessions.SetCartToken(ctx, id, nil)
+	})
+	return cartToken, err
+}
+
+// Sweep xóa các phiên không có hoạt động lâu hơn TTL
+func (t *Tracker) Sweep(ctx context.Context) (int64, error) {
+	if t == nil {
+		return 0, nil
+	}
+	return t.sessions.DeleteIdle(ctx, time.Now().Add(-t.config.TTL))
+}
+
+// sign ký id và thời điểm cấp bằng HMAC-SHA256
+func (t *Tracker) sign(id string, issuedAt time.Time) string {
+	payload := id + "." + strconv.FormatInt(issuedAt.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte("visitor:"+t.config.Secret))
+	mac.Write([]byte(payload))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parse kiểm tra chữ ký của cookie và trả về id cùng thời điểm cấp
+func (t *Tracker) parse(value string) (string, time.Time, bool) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 || len(parts[0]) != 32 {
+		return "", time.Time{}, false
+	}
+	unix, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	issuedAt := time.Unix(unix, 0)
+	if !hmac.Equal([]byte(value), []byte(t.sign(parts[0], issuedAt))) {
+		return "", time.Time{}, false
+	}
+	return parts[0], issuedAt, true
+}
+
+func cookieValue(c *gin.Context) string {
+	value, err := c.Cookie(CookieName)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// newID sinh id phiên ngẫu nhiên 128 bit
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/NgTruong624/project_backend/internal/jobs"
+)
+
+// TypePublish là loại job phát một sự kiện webhook: worker tìm các subscription đăng ký sự kiện
+// và đưa chúng vào webhook_deliveries, để request đặt hàng không phải chờ bước này
+const TypePublish = "webhook.publish"
+
+// PublishPayload là payload của job webhook.publish
+type PublishPayload struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// enqueuePublish đưa sự kiện vào hàng đợi job; lỗi chỉ được ghi log. An toàn khi client là nil.
+func enqueuePublish(ctx context.Context, client *jobs.Client, event string, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Webhook: failed to encode %s: %v", event, err)
+		return
+	}
+	client.EnqueueLogged(ctx, TypePublish, PublishPayload{Event: event, Data: raw})
+}
+
+// RegisterJobHandlers đăng ký handler phát sự kiện webhook cho worker
+func RegisterJobHandlers(w *jobs.Worker, d *Dispatcher) {
+	w.Handle(TypePublish, func(ctx context.Context, payload json.RawMessage) error {
+		var p PublishPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return err
+		}
+		return d.enqueue(ctx, p.Event, p.Data)
+	})
+}
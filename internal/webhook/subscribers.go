@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/NgTruong624/project_backend/internal/events"
+	"github.com/NgTruong624/project_backend/internal/jobs"
+	"github.com/NgTruong624/project_backend/internal/models"
+)
+
+// LowStockThreshold là ngưỡng tồn kho phát sự kiện product.stock_low
+const LowStockThreshold = 5
+
+// RegisterSubscribers chuyển các sự kiện domain trên bus thành sự kiện webhook
+func RegisterSubscribers(bus *events.Bus, d *Dispatcher) {
+	events.On(bus, func(ctx context.Context, e events.ProductCreated) {
+		payload := productPayload(e.Product)
+		d.Publish(ctx, EventProductCreated, payload)
+		if e.Product.Stock <= LowStockThreshold {
+			d.Publish(ctx, EventProductStockLow, payload)
+		}
+	})
+
+	// Chỉ phát khi tồn kho vừa giảm xuống dưới ngưỡng
+	events.On(bus, func(ctx context.Context, e events.StockChanged) {
+		if e.NewStock <= LowStockThreshold && e.OldStock > LowStockThreshold {
+			d.Publish(ctx, EventProductStockLow, map[string]interface{}{
+				"id":             e.ProductID,
+				"name":           e.Name,
+				"stock":          e.NewStock,
+				"previous_stock": e.OldStock,
+			})
+		}
+	})
+
+	events.On(bus, func(ctx context.Context, e events.UserRegistered) {
+		d.Publish(ctx, EventUserRegistered, models.UserResponse{
+			ID:        e.User.ID,
+			Username:  e.User.Username,
+			Email:     e.User.Email,
+			FullName:  e.User.FullName,
+			Role:      e.User.Role,
+			Status:    e.User.Status,
+			CreatedAt: e.User.CreatedAt,
+		})
+	})
+}
+
+// OrderPayload là dữ liệu của các sự kiện webhook order.*; PreviousStatus rỗng với order.placed
+type OrderPayload struct {
+	models.Order
+	PreviousStatus string `json:"previous_status,omitempty"`
+}
+
+// RegisterOrderSubscribers chuyển các bước của đơn hàng thành sự kiện webhook order.*. Sự kiện
+// được phát qua hàng đợi job (webhook.publish) thay vì ghi webhook_deliveries ngay trong request.
+func RegisterOrderSubscribers(bus *events.Bus, client *jobs.Client) {
+	events.On(bus, func(ctx context.Context, e events.OrderPlaced) {
+		enqueuePublish(ctx, client, EventOrderPlaced, OrderPayload{Order: e.Order})
+	})
+	events.On(bus, func(ctx context.Context, e events.OrderStatusChanged) {
+		if event, ok := orderStatusEvents[e.To]; ok {
+			enqueuePublish(ctx, client, event, OrderPayload{Order: e.Order, PreviousStatus: e.From})
+		}
+	})
+}
+
+func productPayload(p models.Product) models.ProductResponse {
+	return models.ProductResponse{
+		ID:          p.ID,
+		Name:        p.Name,
+		SKU:         p.SKU,
+		Description: p.Description,
+		Price:       p.Price,
+		Stock:       p.Stock,
+		ImageURL:    p.ImageURL,
+		Category:    p.Category,
+		Specs:       p.Specs,
+		Status:      p.Status,
+		PublishAt:   p.PublishAt,
+		Version:     p.Version,
+		CreatedAt:   p.CreatedAt,
+
+		EffectivePrice: p.EffectivePrice(),
+	}
+}
@@ -0,0 +1,295 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/NgTruong624/project_backend/internal/models"
+	"github.com/NgTruong624/project_backend/internal/netguard"
+	"github.com/NgTruong624/project_backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Các sự kiện domain có thể đăng ký nhận qua webhook
+const (
+	EventProductCreated  = "product.created"
+	EventProductStockLow = "product.stock_low"
+	EventUserRegistered  = "user.registered"
+	EventOrderPlaced     = "order.placed"
+	EventOrderPaid       = "order.paid"
+	EventOrderFulfilled  = "order.fulfilled"
+	EventOrderDelivered  = "order.delivered"
+	EventOrderCancelled  = "order.cancelled"
+	EventOrderRefunded   = "order.refunded"
+)
+
+// SupportedEvents là danh sách sự kiện hợp lệ khi đăng ký webhook
+var SupportedEvents = map[string]bool{
+	EventProductCreated:  true,
+	EventProductStockLow: true,
+	EventUserRegistered:  true,
+	EventOrderPlaced:     true,
+	EventOrderPaid:       true,
+	EventOrderFulfilled:  true,
+	EventOrderDelivered:  true,
+	EventOrderCancelled:  true,
+	EventOrderRefunded:   true,
+}
+
+// orderStatusEvents ánh xạ trạng thái mới của đơn sang sự kiện webhook
+var orderStatusEvents = map[string]string{
+	models.OrderStatusPaid:      EventOrderPaid,
+	models.OrderStatusFulfilled: EventOrderFulfilled,
+	models.OrderStatusDelivered: EventOrderDelivered,
+	models.OrderStatusCancelled: EventOrderCancelled,
+	models.OrderStatusRefunded:  EventOrderRefunded,
+}
+
+const (
+	maxAttempts  = 8
+	batchSize    = 20
+	httpTimeout  = 10 * time.Second
+	maxBodyBytes = 1024
+	// leaseDuration là thời gian một lô được giữ cho worker đã claim, dài hơn thời gian gửi tuần tự
+	// batchSize lần gửi với httpTimeout mỗi lần
+	leaseDuration = 5 * time.Minute
+)
+
+// ErrBlockedAddress được trả về khi URL của subscription trỏ tới địa chỉ không công khai
+var ErrBlockedAddress = errors.New("webhook host resolves to a non-public address")
+
+// ValidateURL kiểm tra URL của subscription: http hoặc https, có host, và host không phải hoặc không phân
+// giải ra địa chỉ không công khai (loopback, mạng nội bộ, link-local, ...). Địa chỉ được kiểm tra lại lúc
+// gửi vì bản ghi DNS có thể đổi sau khi đăng ký.
+func ValidateURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return errors.New("only http and https URLs are allowed")
+	}
+	if u.Hostname() == "" {
+		return errors.New("URL must have a host")
+	}
+	if err := netguard.CheckHost(ctx, u.Hostname()); err != nil {
+		if errors.Is(err, netguard.ErrNonPublicAddress) {
+			return ErrBlockedAddress
+		}
+		return fmt.Errorf("resolve %s: %w", u.Hostname(), err)
+	}
+	return nil
+}
+
+// Envelope là payload JSON gửi tới endpoint của subscriber
+type Envelope struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Dispatcher đưa sự kiện vào hàng đợi (bảng webhook_deliveries) và gửi chúng đi
+type Dispatcher struct {
+	repo         *repository.WebhookRepository
+	client       *http.Client
+	pollInterval time.Duration
+}
+
+// NewDispatcher tạo dispatcher mới
+func NewDispatcher(db *gorm.DB, pollInterval time.Duration) *Dispatcher {
+	if pollInterval <= 0 {
+		pollInterval = 5 * time.Second
+	}
+	return &Dispatcher{
+		repo:         repository.NewWebhookRepository(db),
+		client:       newClient(),
+		pollInterval: pollInterval,
+	}
+}
+
+// newClient tạo HTTP client gửi webhook: địa chỉ IP được kiểm tra lúc kết nối (sau khi phân giải DNS, kể cả
+// khi chuyển hướng) nên host trỏ về mạng nội bộ bị chặn; proxy của môi trường không được dùng
+func newClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: netguard.Control(ErrBlockedAddress),
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ResponseHeaderTimeout: httpTimeout,
+		},
+		Timeout: httpTimeout,
+	}
+}
+
+// GenerateSecret tạo secret ngẫu nhiên cho subscription
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Sign tính chữ ký HMAC-SHA256 của "<timestamp>.<body>" với secret của subscriber
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Publish đưa sự kiện vào hàng đợi cho tất cả subscription đang đăng ký sự kiện đó.
+// Lỗi chỉ được ghi log để không làm hỏng thao tác nghiệp vụ đã thành công.
+func (d *Dispatcher) Publish(ctx context.Context, event string, data interface{}) {
+	if d == nil {
+		return
+	}
+	if err := d.enqueue(ctx, event, data); err != nil {
+		log.Printf("Webhook: failed to enqueue %s: %v", event, err)
+	}
+}
+
+func (d *Dispatcher) enqueue(ctx context.Context, event string, data interface{}) error {
+	subs, err := d.repo.FindActiveByEvent(ctx, event)
+	if err != nil || len(subs) == 0 {
+		return err
+	}
+
+	id, err := GenerateSecret()
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(Envelope{ID: id[:24], Event: event, CreatedAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	deliveries := make([]models.WebhookDelivery, 0, len(subs))
+	for _, sub := range subs {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			Event:          event,
+			Payload:        models.JSON(payload),
+			Status:         models.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+		})
+	}
+	return d.repo.CreateDeliveries(ctx, deliveries)
+}
+
+// Start chạy worker gửi webhook cho tới khi ctx bị hủy
+func (d *Dispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.pollInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := d.ProcessDue(ctx); err != nil {
+					log.Printf("Webhook: failed to process deliveries: %v", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// ProcessDue claim một lô lần gửi đã tới hạn, gửi chúng ngoài transaction và lưu kết quả từng lần gửi
+func (d *Dispatcher) ProcessDue(ctx context.Context) error {
+	deliveries, err := d.repo.ClaimDue(ctx, batchSize, time.Now().Add(leaseDuration))
+	if err != nil {
+		return err
+	}
+	for i := range deliveries {
+		d.attempt(ctx, &deliveries[i])
+	}
+	return nil
+}
+
+// attempt gửi một lần gửi đã claim (Attempts đã tính cả lần này) và lưu kết quả
+func (d *Dispatcher) attempt(ctx context.Context, delivery *models.WebhookDelivery) {
+	sub, err := d.repo.GetSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.LastError = "subscription not found"
+	} else if status, err := d.send(ctx, sub, delivery); err != nil {
+		delivery.ResponseStatus = status
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= maxAttempts {
+			delivery.Status = models.WebhookDeliveryFailed
+		} else {
+			delivery.NextAttemptAt = time.Now().Add(backoff(delivery.Attempts))
+		}
+	} else {
+		now := time.Now()
+		delivery.ResponseStatus = status
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	}
+
+	if ok, err := d.repo.FinishDelivery(ctx, delivery); err != nil {
+		log.Printf("Webhook: failed to update delivery %d: %v", delivery.ID, err)
+	} else if !ok {
+		log.Printf("Webhook: delivery %d was claimed again before its result was saved", delivery.ID)
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, sub *models.WebhookSubscription, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "BackendShop-Webhook/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", Sign(sub.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, snippet)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff trả về thời gian chờ lũy thừa: 1m, 2m, 4m, ... tối đa 6 giờ
+func backoff(attempts int) time.Duration {
+	delay := time.Minute << uint(attempts-1)
+	if delay > 6*time.Hour {
+		delay = 6 * time.Hour
+	}
+	return delay
+}